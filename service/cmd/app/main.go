@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	di "github.com/notifique/service/internal/di"
@@ -8,7 +9,7 @@ import (
 
 func main() {
 
-	r, close, err := di.InjectPgPriorityRabbitMQ(nil)
+	app, close, err := di.InjectPgPriorityRabbitMQ(nil)
 
 	if err != nil {
 		log.Fatalf("failed to create engine - %v", err)
@@ -16,5 +17,11 @@ func main() {
 
 	defer close()
 
-	r.Run() // listen and serve on 0.0.0.0:8080
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go app.Scheduler.Start(ctx)
+	go app.Relay.Start(ctx)
+
+	app.Engine.Run() // listen and serve on 0.0.0.0:8080
 }
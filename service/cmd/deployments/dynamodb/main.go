@@ -30,4 +30,20 @@ func main() {
 	}
 
 	log.Print("Tables created!")
+
+	err = ddb.BackfillNotificationListKeys(client)
+
+	if err != nil {
+		log.Fatalf("Failed to backfill the notification list keys - %v", err)
+	}
+
+	log.Print("Notification list keys backfilled!")
+
+	err = ddb.BackfillNotificationReferences(client)
+
+	if err != nil {
+		log.Fatalf("Failed to backfill the notification references - %v", err)
+	}
+
+	log.Print("Notification references backfilled!")
 }
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 
 	"github.com/notifique/service/internal/publish"
+	"github.com/notifique/service/internal/sanitizer"
+	"github.com/notifique/shared/audience"
 	"github.com/notifique/shared/clients"
 )
 
@@ -30,8 +33,20 @@ const (
 	cacheTTLInSeconds   = "CACHE_TTL_IN_SECONDS"
 	workerQueue         = "WORKER_QUEUE"
 	jwksUrl             = "JWKS_URL"
+	schedulerInterval   = "SCHEDULER_INTERVAL_IN_SECONDS"
+	outboxRelayInterval = "OUTBOX_RELAY_INTERVAL_IN_SECONDS"
+	sanitizerElements   = "SANITIZER_ALLOWED_ELEMENTS"
+	sanitizerAttributes = "SANITIZER_ALLOWED_ATTRIBUTES"
+	sanitizerStyles     = "SANITIZER_ALLOWED_STYLES"
+	sanitizerStrict     = "SANITIZER_STRICT"
+	userPoolId          = "USER_POOL_ID"
+	cognitoBaseEndpoint = "COGNITO_BASE_ENDPOINT"
+	cognitoRegion       = "COGNITO_REGION"
 )
 
+const defaultSchedulerInterval = 10 * time.Second
+const defaultOutboxRelayInterval = time.Second
+
 type EnvConfig struct{}
 
 func (cfg EnvConfig) GetPostgresUrl() (string, error) {
@@ -98,6 +113,30 @@ func (cfg EnvConfig) GetSQSClientConfig() (sqsCfg clients.SQSClientConfig) {
 	return
 }
 
+func (cfg EnvConfig) GetCognitoClientConfig() (cognitoCfg clients.CognitoClientConfig) {
+
+	if be, ok := os.LookupEnv(cognitoBaseEndpoint); ok {
+		cognitoCfg.BaseEndpoint = &be
+	}
+
+	if region, ok := os.LookupEnv(cognitoRegion); ok {
+		cognitoCfg.Region = &region
+	}
+
+	return
+}
+
+func (cfg EnvConfig) GetUserPoolId() (audience.UserPoolID, error) {
+
+	poolId, ok := os.LookupEnv(userPoolId)
+
+	if !ok {
+		return "", fmt.Errorf("user pool id env variable %s not set", userPoolId)
+	}
+
+	return audience.UserPoolID(poolId), nil
+}
+
 func (cfg EnvConfig) GetBrokerChannelSize() (int, error) {
 
 	chSizeStr, ok := os.LookupEnv(brokerCapacity)
@@ -192,6 +231,85 @@ func (cfg EnvConfig) GetJWKSURL() (string, error) {
 	return jwks, nil
 }
 
+func (cfg EnvConfig) GetSchedulerInterval() (time.Duration, error) {
+
+	interval, ok := os.LookupEnv(schedulerInterval)
+
+	if !ok {
+		return defaultSchedulerInterval, nil
+	}
+
+	intervalInt, err := strconv.Atoi(interval)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse scheduler interval to int - %w", err)
+	}
+
+	return time.Duration(intervalInt) * time.Second, nil
+}
+
+func (cfg EnvConfig) GetOutboxRelayInterval() (time.Duration, error) {
+
+	interval, ok := os.LookupEnv(outboxRelayInterval)
+
+	if !ok {
+		return defaultOutboxRelayInterval, nil
+	}
+
+	intervalInt, err := strconv.Atoi(interval)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse outbox relay interval to int - %w", err)
+	}
+
+	return time.Duration(intervalInt) * time.Second, nil
+}
+
+// lookupList returns the comma separated values of the env variable
+func lookupList(name string) []string {
+
+	value, ok := os.LookupEnv(name)
+
+	if !ok {
+		return nil
+	}
+
+	values := []string{}
+
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+func (cfg EnvConfig) GetSanitizerConfig() (sanitizer.Config, error) {
+
+	sanitizerCfg := sanitizer.Config{
+		AllowedElements:   lookupList(sanitizerElements),
+		AllowedAttributes: lookupList(sanitizerAttributes),
+		AllowedStyles:     lookupList(sanitizerStyles),
+	}
+
+	strict, ok := os.LookupEnv(sanitizerStrict)
+
+	if !ok {
+		return sanitizerCfg, nil
+	}
+
+	isStrict, err := strconv.ParseBool(strict)
+
+	if err != nil {
+		return sanitizerCfg, fmt.Errorf("failed to parse sanitizer strict mode to bool - %w", err)
+	}
+
+	sanitizerCfg.Strict = isStrict
+
+	return sanitizerCfg, nil
+}
+
 func NewEnvConfig(envFile *string) (*EnvConfig, error) {
 
 	if envFile == nil {
@@ -3,7 +3,15 @@ package controllers
 import "time"
 
 const (
-	userNotificationEvent = "userNotification"
-	NotificationStatusTTL = 15 * time.Minute
-	NotificationHashTTL   = 5 * time.Minute
+	userNotificationEvent   = "userNotification"
+	NotificationStatusTTL   = 15 * time.Minute
+	IdempotencyKeyTTL       = 24 * time.Hour
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+	// IdempotencyKeyPendingTTL bounds how long a key stays reserved if
+	// the request that reserved it never completes.
+	IdempotencyKeyPendingTTL = time.Minute
+	// maxResendRecipients is the most recipients a notification can be
+	// sent to, the same as the limit of the notification requests.
+	maxResendRecipients = 256
 )
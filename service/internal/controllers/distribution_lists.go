@@ -4,32 +4,56 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/notifique/service/internal"
 	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/recipients"
+	"github.com/notifique/shared/auth"
 	"github.com/notifique/shared/cache"
 	sdto "github.com/notifique/shared/dto"
 )
 
 type DistributionRegistry interface {
 	CreateDistributionList(ctx context.Context, distributionList dto.DistributionList) error
-	GetDistributionLists(ctx context.Context, filter sdto.PageFilter) (sdto.Page[dto.DistributionListSummary], error)
+	GetDistributionLists(ctx context.Context, filters dto.DistributionListFilters) (sdto.Page[dto.DistributionListSummary], error)
+	GetDistributionListDetails(ctx context.Context, distlistName string) (dto.DistributionListDetails, error)
+	UpdateDistributionList(ctx context.Context, distlistName string, metadata dto.DistributionListMetadata) (dto.DistributionListDetails, error)
+	RenameDistributionList(ctx context.Context, distlistName, newName string) (dto.DistributionListDetails, error)
 	DeleteDistributionList(ctx context.Context, distlistName string) error
 	GetRecipients(ctx context.Context, distlistName string, filter sdto.PageFilter) (sdto.Page[string], error)
 	AddRecipients(ctx context.Context, distlistName string, recipients []string) (*dto.DistributionListSummary, error)
 	DeleteRecipients(ctx context.Context, distlistName string, recipients []string) (*dto.DistributionListSummary, error)
+	DeleteRecipientFromLists(ctx context.Context, recipient string) ([]string, error)
+	GetSegment(ctx context.Context, distlistName string) (*sdto.Segment, error)
+	GetFlattenedRecipients(ctx context.Context, distlistName string, filter sdto.PageFilter) (sdto.Page[string], error)
+	GetLists(ctx context.Context, distlistName string, filter sdto.PageFilter) (sdto.Page[string], error)
+	AddLists(ctx context.Context, distlistName string, lists []string) (*dto.DistributionListSummary, error)
+	DeleteLists(ctx context.Context, distlistName string, lists []string) (*dto.DistributionListSummary, error)
+	ImportRecipients(ctx context.Context, distlistName string, mode dto.RecipientsImportMode, batches recipients.Batches) (*dto.DistributionListSummary, error)
+	ExportRecipients(ctx context.Context, distlistName string, write func([]string) error) error
+}
+
+// AudienceResolver evaluates the segments against the users.
+type AudienceResolver interface {
+	Preview(ctx context.Context, segment sdto.Segment, sampleSize int) (sdto.SegmentPreview, error)
 }
 
 type DistributionListController struct {
 	Registry DistributionRegistry
 	Cache    cache.Cache
+	Audience AudienceResolver
 }
 
 type recipientsHandler func(context.Context, string, []string) (*dto.DistributionListSummary, error)
 
+type recipientsPageHandler func(context.Context, string, sdto.PageFilter) (sdto.Page[string], error)
+
 func (dc *DistributionListController) CreateDistributionList(c *gin.Context) {
 	var dl dto.DistributionList
 
@@ -38,8 +62,14 @@ func (dc *DistributionListController) CreateDistributionList(c *gin.Context) {
 		return
 	}
 
+	if dl.Owner == "" {
+		dl.Owner = c.GetHeader(string(auth.UserHeader))
+	}
+
 	if err := dc.Registry.CreateDistributionList(c, dl); err != nil {
-		if errors.As(err, &internal.DistributionListAlreadyExists{}) {
+		if errors.As(err, &internal.DistributionListAlreadyExists{}) ||
+			errors.As(err, &internal.EntityNotFound{}) ||
+			errors.As(err, &internal.DistributionListCycle{}) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		} else {
@@ -60,8 +90,24 @@ func (dc *DistributionListController) CreateDistributionList(c *gin.Context) {
 	}
 }
 
+// deleteDistributionListsCache deletes every cached distribution list
+// endpoint, which includes the details and members of the lists.
+func (dc *DistributionListController) deleteDistributionListsCache(c *gin.Context) {
+
+	cacheKey, _ := internal.GetBasePath(c.Request.URL.Path, ".*/distribution-lists")
+
+	err := dc.Cache.DelWithPrefix(
+		c.Request.Context(),
+		cache.GetEndpointKeyWithPrefix(cacheKey, nil))
+
+	if err != nil {
+		err = fmt.Errorf("failed to delete cached distribution lists - %w", err)
+		slog.Error(err.Error())
+	}
+}
+
 func (dc *DistributionListController) GetDistributionLists(c *gin.Context) {
-	var filters sdto.PageFilter
+	var filters dto.DistributionListFilters
 
 	if err := c.ShouldBind(&filters); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -79,6 +125,34 @@ func (dc *DistributionListController) GetDistributionLists(c *gin.Context) {
 	c.JSON(http.StatusOK, lists)
 }
 
+// RemoveMember removes the recipient from every list it is a recipient
+// of, e.g. when the user is offboarded.
+func (dc *DistributionListController) RemoveMember(c *gin.Context) {
+	var member dto.DistributionListMemberUriParams
+
+	if err := c.ShouldBindUri(&member); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lists, err := dc.Registry.DeleteRecipientFromLists(c, member.UserId)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DistributionListMembership{
+		Member:            member.UserId,
+		DistributionLists: lists,
+	})
+
+	if len(lists) != 0 {
+		dc.deleteDistributionListsCache(c)
+	}
+}
+
 func (dc *DistributionListController) GetRecipients(c *gin.Context) {
 	var uriParams dto.DistributionListUriParams
 
@@ -87,14 +161,20 @@ func (dc *DistributionListController) GetRecipients(c *gin.Context) {
 		return
 	}
 
-	var filter sdto.PageFilter
+	var filter dto.DistributionListRecipientsFilter
 
 	if err := c.ShouldBind(&filter); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	recipients, err := dc.Registry.GetRecipients(c, uriParams.Name, filter)
+	var getRecipients recipientsPageHandler = dc.Registry.GetRecipients
+
+	if filter.Flatten {
+		getRecipients = dc.Registry.GetFlattenedRecipients
+	}
+
+	recipients, err := getRecipients(c, uriParams.Name, filter.PageFilter)
 
 	if err != nil {
 		if errors.As(err, &internal.EntityNotFound{}) {
@@ -110,6 +190,339 @@ func (dc *DistributionListController) GetRecipients(c *gin.Context) {
 	c.JSON(http.StatusOK, recipients)
 }
 
+// ImportRecipients adds, replaces or removes the recipients of the CSV
+// or NDJSON file in the file part of the multipart form. The file is
+// streamed to the registry, the rows with errors are skipped.
+func (dc *DistributionListController) ImportRecipients(c *gin.Context) {
+	var uriParams dto.DistributionListUriParams
+
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var params dto.RecipientsImportParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	form, err := c.Request.MultipartReader()
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var file *multipart.Part
+
+	for file == nil {
+		part, err := form.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if part.FormName() == "file" {
+			file = part
+		}
+	}
+
+	if file == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	reader := recipients.NewReader(file, params.Format)
+
+	summary, err := dc.Registry.ImportRecipients(c, uriParams.Name, params.Mode, reader)
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else if errors.As(err, &internal.DistributionListIsSegment{}) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, reader.Result(*summary))
+
+	cacheKey, _ := internal.GetBasePath(c.Request.URL.Path, ".*/distribution-lists")
+	err = dc.Cache.DelWithPrefix(
+		c.Request.Context(),
+		cache.GetEndpointKeyWithPrefix(cacheKey, nil))
+
+	if err != nil {
+		err = fmt.Errorf("failed to delete cached distribution lists - %w", err)
+		slog.Error(err.Error())
+	}
+}
+
+// ExportRecipients streams every recipient of the distribution list as
+// a CSV or NDJSON file. The response starts with the first batch of
+// recipients, errors after it can only be logged.
+func (dc *DistributionListController) ExportRecipients(c *gin.Context) {
+	var uriParams dto.DistributionListUriParams
+
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var params dto.RecipientsExportParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	writer := recipients.NewWriter(c.Writer, params.Format)
+	started := false
+
+	write := func(batch []string) error {
+		if !started {
+			started = true
+
+			filename := fmt.Sprintf("%s.%s", uriParams.Name, strings.ToLower(string(params.Format)))
+
+			c.Header("Content-Type", writer.ContentType())
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+			c.Status(http.StatusOK)
+		}
+
+		if err := writer.Write(batch); err != nil {
+			return fmt.Errorf("failed to write the recipients - %w", err)
+		}
+
+		c.Writer.Flush()
+
+		return nil
+	}
+
+	err := dc.Registry.ExportRecipients(c, uriParams.Name, write)
+
+	if err != nil && started {
+		slog.Error(fmt.Errorf("failed to export the recipients of %s - %w", uriParams.Name, err).Error())
+		return
+	}
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Lists without recipients export an empty file
+	if !started {
+		if err := write([]string{}); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+}
+
+// GetLists returns the lists directly included by the distribution list.
+func (dc *DistributionListController) GetLists(c *gin.Context) {
+	var uriParams dto.DistributionListUriParams
+
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var filter sdto.PageFilter
+
+	if err := c.ShouldBind(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lists, err := dc.Registry.GetLists(c, uriParams.Name, filter)
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+// GetSegment returns the segment of the distribution list, used to
+// resolve its recipients when the notifications are sent.
+func (dc *DistributionListController) GetSegment(c *gin.Context) {
+	var uriParams dto.DistributionListUriParams
+
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	segment, err := dc.Registry.GetSegment(c, uriParams.Name)
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.DistributionListSegment{Segment: segment})
+}
+
+// PreviewSegment returns the number of recipients of the segment with a
+// sample of them, without creating a distribution list.
+func (dc *DistributionListController) PreviewSegment(c *gin.Context) {
+	var req dto.SegmentPreviewReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sampleSize := internal.SegmentSampleSize
+
+	if req.SampleSize != nil {
+		sampleSize = *req.SampleSize
+	}
+
+	preview, err := dc.Audience.Preview(c, req.Segment, sampleSize)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// GetDistributionList returns the summary of the list with its
+// metadata.
+func (dc *DistributionListController) GetDistributionList(c *gin.Context) {
+	var uriParams dto.DistributionListUriParams
+
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	details, err := dc.Registry.GetDistributionListDetails(c, uriParams.Name)
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// UpdateDistributionList replaces the metadata of the list.
+func (dc *DistributionListController) UpdateDistributionList(c *gin.Context) {
+	var uriParams dto.DistributionListUriParams
+
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var metadata dto.DistributionListMetadata
+
+	if err := c.ShouldBindJSON(&metadata); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	details, err := dc.Registry.UpdateDistributionList(c, uriParams.Name, metadata)
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, details)
+
+	dc.deleteDistributionListsCache(c)
+}
+
+// RenameDistributionList changes the name of the list, the notifications
+// and schedules that reference the list are changed to the new name.
+func (dc *DistributionListController) RenameDistributionList(c *gin.Context) {
+	var uriParams dto.DistributionListUriParams
+
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var rename dto.DistributionListRename
+
+	if err := c.ShouldBindJSON(&rename); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	details, err := dc.Registry.RenameDistributionList(c, uriParams.Name, rename.Name)
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else if errors.As(err, &internal.DistributionListAlreadyExists{}) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else if errors.As(err, &internal.DistributionListRenaming{}) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, details)
+
+	dc.deleteDistributionListsCache(c)
+}
+
 func (dc *DistributionListController) DeleteDistributionList(c *gin.Context) {
 
 	var uriParams dto.DistributionListUriParams
@@ -160,6 +573,14 @@ func (dc *DistributionListController) DeleteRecipients(c *gin.Context) {
 	dc.handleRecipients(c, dc.Registry.DeleteRecipients)
 }
 
+func (dc *DistributionListController) AddLists(c *gin.Context) {
+	dc.handleLists(c, dc.Registry.AddLists)
+}
+
+func (dc *DistributionListController) DeleteLists(c *gin.Context) {
+	dc.handleLists(c, dc.Registry.DeleteLists)
+}
+
 func (dc *DistributionListController) handleRecipients(c *gin.Context, handler recipientsHandler) {
 	var uriParams dto.DistributionListUriParams
 
@@ -175,10 +596,38 @@ func (dc *DistributionListController) handleRecipients(c *gin.Context, handler r
 		return
 	}
 
-	summary, err := handler(c, uriParams.Name, recipients.Recipients)
+	dc.handleMembers(c, uriParams.Name, recipients.Recipients, handler)
+}
+
+func (dc *DistributionListController) handleLists(c *gin.Context, handler recipientsHandler) {
+	var uriParams dto.DistributionListUriParams
+
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var lists dto.DistributionListLists
+
+	if err := c.ShouldBindJSON(&lists); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dc.handleMembers(c, uriParams.Name, lists.Lists, handler)
+}
+
+// handleMembers changes the recipients or the lists included by the
+// distribution list. The recipients of the lists that include it change
+// as well, so the cache of every distribution list is deleted.
+func (dc *DistributionListController) handleMembers(c *gin.Context, name string, members []string, handler recipientsHandler) {
+
+	summary, err := handler(c, name, members)
 
 	if err != nil {
-		if errors.As(err, &internal.EntityNotFound{}) {
+		if errors.As(err, &internal.EntityNotFound{}) ||
+			errors.As(err, &internal.DistributionListIsSegment{}) ||
+			errors.As(err, &internal.DistributionListCycle{}) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		} else {
@@ -190,12 +639,13 @@ func (dc *DistributionListController) handleRecipients(c *gin.Context, handler r
 
 	c.JSON(http.StatusOK, summary)
 
+	cacheKey, _ := internal.GetBasePath(c.Request.URL.Path, ".*/distribution-lists")
 	err = dc.Cache.DelWithPrefix(
 		c.Request.Context(),
-		cache.GetEndpointKeyWithPrefix(c.Request.URL.Path, nil))
+		cache.GetEndpointKeyWithPrefix(cacheKey, nil))
 
 	if err != nil {
-		err = fmt.Errorf("failed to delete cached distribution list recipients - %w", err)
+		err = fmt.Errorf("failed to delete cached distribution lists - %w", err)
 		slog.Error(err.Error())
 	}
 }
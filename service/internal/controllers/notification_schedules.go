@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/sanitizer"
+	"github.com/notifique/shared/auth"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const defaultSchedulePreviewCount = 5
+
+type NotificationScheduleRegistry interface {
+	SaveSchedule(ctx context.Context, createdBy string, schedule dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error)
+	GetSchedules(ctx context.Context, filters sdto.PageFilter) (sdto.Page[dto.NotificationScheduleSummary], error)
+	GetSchedule(ctx context.Context, scheduleId string) (dto.NotificationScheduleResp, error)
+	UpdateSchedule(ctx context.Context, scheduleId, updatedBy string, schedule dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error)
+	DeleteSchedule(ctx context.Context, scheduleId string) error
+	PauseSchedule(ctx context.Context, scheduleId, updatedBy string) (dto.NotificationScheduleResp, error)
+	ResumeSchedule(ctx context.Context, scheduleId, updatedBy string, nextRunAt time.Time) (dto.NotificationScheduleResp, error)
+}
+
+// Schedule routes are not cached since the scheduler updates the next
+// and last run times in the background.
+type NotificationScheduleController struct {
+	Registry NotificationScheduleRegistry
+	// Used to validate the variables of template based notifications
+	Notifications NotificationRegistry
+	// Used to sanitize the raw contents of the notifications
+	Sanitizer *sanitizer.Sanitizer
+}
+
+// bindSchedule binds and validates a schedule request. If it fails, the
+// response is written and false is returned.
+func (sc *NotificationScheduleController) bindSchedule(c *gin.Context) (dto.NotificationScheduleReq, bool) {
+
+	var schedule dto.NotificationScheduleReq
+
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return schedule, false
+	}
+
+	if schedule.Notification.SendAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sendAt is not supported by notification schedules"})
+		return schedule, false
+	}
+
+	if schedule.Timezone == "" {
+		schedule.Timezone = internal.DefaultScheduleTimezone
+	}
+
+	if _, ok := sanitizeRawContents(c, sc.Sanitizer, &schedule.Notification); !ok {
+		return schedule, false
+	}
+
+	if schedule.Notification.TemplateContents == nil {
+		return schedule, true
+	}
+
+	// The version isn't pinned unless requested, so the schedule sends
+	// the latest version of the template every time it runs
+	templateVars, _, err := sc.Notifications.GetTemplateVariables(
+		c.Request.Context(),
+		schedule.Notification.TemplateContents.Id,
+		schedule.Notification.TemplateContents.Version,
+	)
+
+	if err != nil && (errors.As(err, &internal.EntityNotFound{}) ||
+		errors.As(err, &internal.TemplateArchived{})) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return schedule, false
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return schedule, false
+	}
+
+	templateContents := *schedule.Notification.TemplateContents
+	err = internal.ValidateTemplateVars(templateVars, templateContents)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return schedule, false
+	}
+
+	return schedule, true
+}
+
+func (sc *NotificationScheduleController) CreateSchedule(c *gin.Context) {
+
+	schedule, ok := sc.bindSchedule(c)
+
+	if !ok {
+		return
+	}
+
+	nextRunAt, err := internal.NextCronRun(schedule.CronExpression, schedule.Timezone, time.Now())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := c.GetHeader(string(auth.UserHeader))
+
+	resp, err := sc.Registry.SaveSchedule(c.Request.Context(), userId, schedule, nextRunAt)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (sc *NotificationScheduleController) GetSchedules(c *gin.Context) {
+
+	var filters sdto.PageFilter
+
+	if err := c.ShouldBind(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedules, err := sc.Registry.GetSchedules(c.Request.Context(), filters)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+func (sc *NotificationScheduleController) GetSchedule(c *gin.Context) {
+
+	var params dto.NotificationScheduleUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := sc.Registry.GetSchedule(c.Request.Context(), params.Id)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (sc *NotificationScheduleController) UpdateSchedule(c *gin.Context) {
+
+	var params dto.NotificationScheduleUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, ok := sc.bindSchedule(c)
+
+	if !ok {
+		return
+	}
+
+	nextRunAt, err := internal.NextCronRun(schedule.CronExpression, schedule.Timezone, time.Now())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := c.GetHeader(string(auth.UserHeader))
+
+	resp, err := sc.Registry.UpdateSchedule(c.Request.Context(), params.Id, userId, schedule, nextRunAt)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (sc *NotificationScheduleController) DeleteSchedule(c *gin.Context) {
+
+	var params dto.NotificationScheduleUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := sc.Registry.DeleteSchedule(c.Request.Context(), params.Id); err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (sc *NotificationScheduleController) PauseSchedule(c *gin.Context) {
+
+	var params dto.NotificationScheduleUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := c.GetHeader(string(auth.UserHeader))
+
+	schedule, err := sc.Registry.PauseSchedule(c.Request.Context(), params.Id, userId)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (sc *NotificationScheduleController) ResumeSchedule(c *gin.Context) {
+
+	var params dto.NotificationScheduleUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := sc.Registry.GetSchedule(c.Request.Context(), params.Id)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// Occurrences missed while paused are skipped
+	nextRunAt, err := internal.NextCronRun(schedule.CronExpression, schedule.Timezone, time.Now())
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	userId := c.GetHeader(string(auth.UserHeader))
+
+	schedule, err = sc.Registry.ResumeSchedule(c.Request.Context(), params.Id, userId, nextRunAt)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (sc *NotificationScheduleController) PreviewSchedule(c *gin.Context) {
+
+	var params dto.NotificationScheduleUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var filters dto.NotificationSchedulePreviewFilters
+
+	if err := c.ShouldBind(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	count := defaultSchedulePreviewCount
+
+	if filters.Count != nil {
+		count = *filters.Count
+	}
+
+	schedule, err := sc.Registry.GetSchedule(c.Request.Context(), params.Id)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	runs, err := internal.NextCronRuns(schedule.CronExpression, schedule.Timezone, time.Now(), count)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	preview := dto.NotificationSchedulePreviewResp{
+		NextRuns: make([]string, 0, len(runs)),
+	}
+
+	for _, r := range runs {
+		preview.NextRuns = append(preview.NextRuns, r.Format(time.RFC3339))
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
@@ -2,31 +2,38 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"path"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
 
 	"github.com/notifique/service/internal"
 	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/sanitizer"
 	"github.com/notifique/shared/auth"
 	"github.com/notifique/shared/cache"
 	sdto "github.com/notifique/shared/dto"
 )
 
 type NotificationRegistry interface {
-	SaveNotification(ctx context.Context, createdBy string, notification sdto.NotificationReq) (string, error)
+	SaveNotification(ctx context.Context, createdBy string, notification sdto.NotificationReq) (sdto.NotificationCreatedResp, error)
 	UpdateNotificationStatus(ctx context.Context, statusLog sdto.NotificationStatusLog) error
 	DeleteNotification(ctx context.Context, id string) error
 	GetNotificationStatus(ctx context.Context, notificationId string) (sdto.NotificationStatus, error)
-	GetTemplateVariables(ctx context.Context, templateId string) ([]sdto.TemplateVariable, error)
-	GetNotifications(ctx context.Context, filters sdto.PageFilter) (sdto.Page[dto.NotificationSummary], error)
+	GetTemplateVariables(ctx context.Context, templateId string, version *int) ([]sdto.TemplateVariable, int, error)
+	GetNotifications(ctx context.Context, filters dto.NotificationFilters) (sdto.Page[dto.NotificationSummary], error)
 	GetNotification(ctx context.Context, notificationId string) (dto.NotificationResp, error)
 	UpsertRecipientNotificationStatuses(ctx context.Context, notificationId string, statuses []sdto.RecipientNotificationStatus) error
 	GetRecipientNotificationStatuses(ctx context.Context, notificationId string, filters sdto.NotificationRecipientStatusFilters) (sdto.Page[sdto.RecipientNotificationStatus], error)
+	GetNotificationStatusLogs(ctx context.Context, notificationId string, filters sdto.PageFilter) (sdto.Page[dto.NotificationStatusLogResp], error)
+	ResendNotification(ctx context.Context, payload sdto.NotificationMsgPayload) error
 }
 
 type NotificationPublisher interface {
@@ -35,21 +42,87 @@ type NotificationPublisher interface {
 
 type NotificationController struct {
 	Registry  NotificationRegistry
-	Publisher NotificationPublisher
 	Cache     cache.Cache
+	Sanitizer *sanitizer.Sanitizer
+}
+
+// sanitizeRawContents sanitizes the raw contents of the notification,
+// if it has them, with the same policy as the templates. If they are
+// rejected, the response is written and false is returned.
+func sanitizeRawContents(c *gin.Context, s *sanitizer.Sanitizer, notification *sdto.NotificationReq) ([]sdto.StrippedContent, bool) {
+
+	if notification.RawContents == nil {
+		return nil, true
+	}
+
+	contents := *notification.RawContents
+
+	fs := newFieldSanitizer(s)
+	fs.text("contents.title", &contents.Title)
+	fs.text("contents.contents", &contents.Contents)
+
+	if !fs.check(c) {
+		return nil, false
+	}
+
+	notification.RawContents = &contents
+
+	return fs.stripped, true
 }
 
 const SendingNotificationMsg = "Notification is being sent"
 const SentNotificationMsg = "Notification has been sent"
+const IdempotencyKeyReusedMsg = "Idempotency key was already used with a different request"
+const IdempotencyKeyTooLongMsg = "Idempotency key is too long"
+const IdempotencyKeyInProgressMsg = "A request with the same idempotency key is in progress"
+const NotificationNotProcessedMsg = "Notification hasn't been processed yet"
+const NoFailedRecipientsMsg = "Notification has no failed recipients"
+const TooManyFailedRecipientsMsg = "Notification has too many failed recipients, resend them in batches of userIds"
+
+// idempotencyRecord is the outcome of a notification request, stored
+// so retries with the same idempotency key get the same response. The
+// record is pending while the request that reserved the key is running.
+type idempotencyRecord struct {
+	RequestHash string                       `json:"requestHash"`
+	Pending     bool                         `json:"pending,omitempty"`
+	StatusCode  int                          `json:"statusCode"`
+	Response    sdto.NotificationCreatedResp `json:"response"`
+}
 
-func notificationExists(ctx context.Context, c cache.Cache, hash string) (bool, error) {
-	_, err, ok := c.Get(ctx, cache.GetHashKey(hash))
+// reserveIdempotencyKey atomically stores a pending record for the key.
+// It returns false if the key was already reserved by another request.
+func reserveIdempotencyKey(ctx context.Context, c cache.Cache, userId, idempotencyKey, requestHash string) (bool, error) {
+	data, err := json.Marshal(idempotencyRecord{
+		RequestHash: requestHash,
+		Pending:     true,
+	})
 
 	if err != nil {
-		return false, err
+		return false, fmt.Errorf("failed to marshal idempotency record - %w", err)
 	}
 
-	return ok, nil
+	key := cache.GetIdempotencyKey(userId, idempotencyKey)
+	return c.SetNX(ctx, key, string(data), IdempotencyKeyPendingTTL)
+}
+
+func getIdempotencyRecord(ctx context.Context, c cache.Cache, userId, idempotencyKey string) (*idempotencyRecord, error) {
+	data, err, ok := c.Get(ctx, cache.GetIdempotencyKey(userId, idempotencyKey))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	var record idempotencyRecord
+
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record - %w", err)
+	}
+
+	return &record, nil
 }
 
 func getCachedNotificationStatus(ctx context.Context, c cache.Cache, notificationId string) (*sdto.NotificationStatus, error) {
@@ -67,14 +140,15 @@ func getCachedNotificationStatus(ctx context.Context, c cache.Cache, notificatio
 	return &s, nil
 }
 
-func setNotificationHash(ctx context.Context, c cache.Cache, hash string) error {
-	key := cache.GetHashKey(hash)
-	return c.Set(ctx, key, "1", NotificationHashTTL)
-}
+func setIdempotencyRecord(ctx context.Context, c cache.Cache, userId, idempotencyKey string, record idempotencyRecord) error {
+	data, err := json.Marshal(record)
 
-func deleteNotificationHash(ctx context.Context, c cache.Cache, hash string) error {
-	key := cache.GetHashKey(hash)
-	return c.Del(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record - %w", err)
+	}
+
+	key := cache.GetIdempotencyKey(userId, idempotencyKey)
+	return c.Set(ctx, key, string(data), IdempotencyKeyTTL)
 }
 
 func UpdateNotificationStatus(ctx context.Context, c cache.Cache, sl sdto.NotificationStatusLog) error {
@@ -108,41 +182,71 @@ func (nc *NotificationController) getNotificationStatus(ctx context.Context, not
 func (nc *NotificationController) CreateNotification(c *gin.Context) {
 	var notificationReq sdto.NotificationReq
 
-	if err := c.ShouldBindJSON(&notificationReq); err != nil {
+	if err := c.ShouldBindBodyWith(&notificationReq, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	body, err := io.ReadAll(c.Request.Body)
+	userId := c.GetHeader(string(auth.UserHeader))
+	idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+	requestHash := ""
 
-	if err != nil {
-		slog.Error(err.Error())
-		c.Status(http.StatusInternalServerError)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": IdempotencyKeyTooLongMsg})
 		return
 	}
 
-	hash := internal.GetMd5Hash(string(body))
+	saved := false
 
-	if exists, err := notificationExists(c.Request.Context(), nc.Cache, hash); err != nil {
-		slog.Error(err.Error())
-		c.Status(http.StatusInternalServerError)
-		return
-	} else if exists {
-		c.Status(http.StatusNoContent)
-		return
+	if idempotencyKey != "" {
+		body := c.MustGet(gin.BodyBytesKey).([]byte)
+		requestHash = internal.GetMd5Hash(string(body))
+
+		reserved, err := reserveIdempotencyKey(c.Request.Context(), nc.Cache, userId, idempotencyKey, requestHash)
+
+		if err != nil {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		if !reserved {
+			nc.replayIdempotencyRecord(c, userId, idempotencyKey, requestHash)
+			return
+		}
+
+		// The key is released if the notification isn't saved, so the
+		// request can be retried
+		defer func() {
+			if saved {
+				return
+			}
+
+			key := cache.GetIdempotencyKey(userId, idempotencyKey)
+
+			if err := nc.Cache.Del(context.TODO(), key); err != nil {
+				slog.Error("Failed to release idempotency key", "error", err.Error())
+			}
+		}()
 	}
 
-	userId := c.GetHeader(string(auth.UserHeader))
+	stripped, ok := sanitizeRawContents(c, nc.Sanitizer, &notificationReq)
+
+	if !ok {
+		return
+	}
 
 	if notificationReq.TemplateContents != nil {
-		templateId := notificationReq.TemplateContents.Id
+		templateContents := *notificationReq.TemplateContents
 
-		templateVars, err := nc.Registry.GetTemplateVariables(
+		templateVars, version, err := nc.Registry.GetTemplateVariables(
 			c.Request.Context(),
-			templateId,
+			templateContents.Id,
+			templateContents.Version,
 		)
 
-		if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		if err != nil && (errors.As(err, &internal.EntityNotFound{}) ||
+			errors.As(err, &internal.TemplateArchived{})) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		} else if err != nil {
@@ -151,16 +255,24 @@ func (nc *NotificationController) CreateNotification(c *gin.Context) {
 			return
 		}
 
-		suppliedVars := notificationReq.TemplateContents.Variables
-		err = internal.ValidateTemplateVars(templateVars, suppliedVars)
+		err = internal.ValidateTemplateVars(templateVars, templateContents)
 
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+
+		// The notification is sent with the version it was validated with
+		templateContents.Version = &version
+		notificationReq.TemplateContents = &templateContents
 	}
 
-	notificationId, err := nc.Registry.SaveNotification(c, userId, notificationReq)
+	created, err := nc.Registry.SaveNotification(c, userId, notificationReq)
+
+	if errors.As(err, &internal.TemplateArchived{}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	if err != nil {
 		slog.Error(err.Error())
@@ -168,44 +280,68 @@ func (nc *NotificationController) CreateNotification(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	saved = true
+	created.Stripped = stripped
+
+	// The notification is published by the outbox relay, or by the
+	// scheduler once due if it has a send time.
+	c.Header("Location", path.Join(c.FullPath(), created.Id))
+	c.JSON(http.StatusAccepted, created)
 
 	statusLog := sdto.NotificationStatusLog{
-		NotificationId: notificationId,
-		Status:         sdto.Created,
+		NotificationId: created.Id,
+		Status:         created.Status,
 	}
 
 	if err := UpdateNotificationStatus(context.TODO(), nc.Cache, statusLog); err != nil {
 		slog.Error(err.Error())
 	}
 
-	if err := setNotificationHash(c.Request.Context(), nc.Cache, hash); err != nil {
-		slog.Error("Failed to set notification hash",
+	if idempotencyKey == "" {
+		return
+	}
+
+	record := idempotencyRecord{
+		RequestHash: requestHash,
+		StatusCode:  http.StatusAccepted,
+		Response:    created,
+	}
+
+	err = setIdempotencyRecord(c.Request.Context(), nc.Cache, userId, idempotencyKey, record)
+
+	if err != nil {
+		slog.Error("Failed to store idempotency record",
 			"error", err.Error(),
-			"notificationId", notificationId)
+			"notificationId", created.Id)
 	}
+}
 
-	go func() {
-		ctx := context.Background()
+// replayIdempotencyRecord answers a request whose idempotency key was
+// already reserved with the stored response of the original request.
+func (nc *NotificationController) replayIdempotencyRecord(c *gin.Context, userId, idempotencyKey, requestHash string) {
 
-		notificationWithId := sdto.NotificationMsgPayload{
-			Id:              notificationId,
-			Hash:            hash,
-			NotificationReq: notificationReq,
-		}
+	record, err := getIdempotencyRecord(c.Request.Context(), nc.Cache, userId, idempotencyKey)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
 
-		if err := nc.Publisher.Publish(ctx, notificationWithId); err != nil {
-			slog.Error("Failed to publish notification",
-				"error", err.Error(),
-				"notificationId", notificationId)
+	// The record is missing if the original request failed and released
+	// the key in the meantime
+	if record == nil || (record.Pending && record.RequestHash == requestHash) {
+		c.JSON(http.StatusConflict, gin.H{"error": IdempotencyKeyInProgressMsg})
+		return
+	}
 
-			if err := deleteNotificationHash(ctx, nc.Cache, hash); err != nil {
-				slog.Error("Failed to delete notification hash",
-					"error", err.Error(),
-					"notificationId", notificationId)
-			}
-		}
-	}()
+	if record.RequestHash != requestHash {
+		c.JSON(http.StatusConflict, gin.H{"error": IdempotencyKeyReusedMsg})
+		return
+	}
+
+	c.Header("Location", path.Join(c.FullPath(), record.Response.Id))
+	c.JSON(record.StatusCode, record.Response)
 }
 
 func (nc *NotificationController) DeleteNotification(c *gin.Context) {
@@ -278,7 +414,7 @@ func (nc *NotificationController) CancelDelivery(c *gin.Context) {
 }
 
 func (nc *NotificationController) GetNotifications(c *gin.Context) {
-	var filters sdto.PageFilter
+	var filters dto.NotificationFilters
 
 	if err := c.ShouldBind(&filters); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -436,3 +572,186 @@ func (nc *NotificationController) GetStatus(c *gin.Context) {
 		Status: status,
 	})
 }
+
+func (nc *NotificationController) GetStatusHistory(c *gin.Context) {
+
+	var params dto.NotificationUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var filters sdto.PageFilter
+
+	if err := c.ShouldBind(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logs, err := nc.Registry.GetNotificationStatusLogs(c.Request.Context(), params.NotificationId, filters)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
+
+// getFailedRecipients returns the recipients whose latest status in
+// any of the channels is failed.
+func (nc *NotificationController) getFailedRecipients(ctx context.Context, notificationId string, req dto.NotificationResendReq) ([]sdto.RecipientNotificationStatus, error) {
+
+	filters := sdto.NotificationRecipientStatusFilters{
+		Channels: sdto.NotificationChannel("").ToStrSlice(req.Channels),
+		Statuses: []string{string(sdto.Failed)},
+	}
+
+	userIds := make(map[string]struct{}, len(req.UserIds))
+
+	for _, userId := range req.UserIds {
+		userIds[userId] = struct{}{}
+	}
+
+	failed := []sdto.RecipientNotificationStatus{}
+
+	for {
+		page, err := nc.Registry.GetRecipientNotificationStatuses(ctx, notificationId, filters)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recipient statuses - %w", err)
+		}
+
+		for _, status := range page.Data {
+			if _, ok := userIds[status.UserId]; len(userIds) == 0 || ok {
+				failed = append(failed, status)
+			}
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+
+		filters.NextToken = page.NextToken
+	}
+
+	return failed, nil
+}
+
+func (nc *NotificationController) ResendNotification(c *gin.Context) {
+
+	var params dto.NotificationUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.NotificationResendReq
+
+	// The body is optional, without it every failed recipient is used
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	notification, err := nc.Registry.GetNotification(ctx, params.NotificationId)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if notification.Status != sdto.Sent && notification.Status != sdto.Failed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": NotificationNotProcessedMsg})
+		return
+	}
+
+	failed, err := nc.getFailedRecipients(ctx, params.NotificationId, req)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if len(failed) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": NoFailedRecipientsMsg})
+		return
+	}
+
+	resp := dto.NotificationResendResp{
+		Recipients: []string{},
+		Channels:   []sdto.NotificationChannel{},
+	}
+
+	recipients := map[string]struct{}{}
+	channels := map[string]struct{}{}
+
+	for _, status := range failed {
+		if _, ok := recipients[status.UserId]; !ok {
+			recipients[status.UserId] = struct{}{}
+			resp.Recipients = append(resp.Recipients, status.UserId)
+		}
+
+		if _, ok := channels[status.Channel]; !ok {
+			channels[status.Channel] = struct{}{}
+			resp.Channels = append(resp.Channels, sdto.NotificationChannel(status.Channel))
+		}
+	}
+
+	if len(resp.Recipients) > maxResendRecipients {
+		c.JSON(http.StatusBadRequest, gin.H{"error": TooManyFailedRecipientsMsg})
+		return
+	}
+
+	// The worker skips the recipients that have already received the
+	// notification in a channel, so only the failed ones are sent again.
+	notificationReq := notification.NotificationReq
+	notificationReq.Recipients = resp.Recipients
+	notificationReq.Channels = resp.Channels
+	notificationReq.DistributionList = nil
+	notificationReq.SendAt = nil
+
+	payload := sdto.NotificationMsgPayload{
+		Id:              params.NotificationId,
+		Hash:            internal.GetMd5Hash(params.NotificationId + uuid.NewString()),
+		NotificationReq: notificationReq,
+	}
+
+	err = nc.Registry.ResendNotification(ctx, payload)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil && errors.As(err, &internal.InvalidNotificationStatus{}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": NotificationNotProcessedMsg})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	statusLog := sdto.NotificationStatusLog{
+		NotificationId: params.NotificationId,
+		Status:         sdto.Created,
+	}
+
+	if err := UpdateNotificationStatus(ctx, nc.Cache, statusLog); err != nil {
+		slog.Error(err.Error())
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/notifique/service/internal/sanitizer"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const UnsafeContentsMsg = "contents have HTML that isn't allowed"
+
+// fieldSanitizer sanitizes the fields of a request with the policy of
+// the deployment and collects what was stripped from them
+type fieldSanitizer struct {
+	sanitizer *sanitizer.Sanitizer
+	stripped  []sdto.StrippedContent
+	emptied   []string
+}
+
+func newFieldSanitizer(s *sanitizer.Sanitizer) *fieldSanitizer {
+	return &fieldSanitizer{sanitizer: s, stripped: []sdto.StrippedContent{}}
+}
+
+func (fs *fieldSanitizer) add(field, original, sanitized string, stripped []sdto.StrippedContent) string {
+
+	fs.stripped = append(fs.stripped, stripped...)
+
+	if strings.TrimSpace(sanitized) == "" && strings.TrimSpace(original) != "" {
+		fs.emptied = append(fs.emptied, field)
+	}
+
+	return sanitized
+}
+
+// template sanitizes the HTML of a template field
+func (fs *fieldSanitizer) template(field string, tmpl *string) {
+	sanitized, stripped := fs.sanitizer.SanitizeTemplate(field, *tmpl)
+	*tmpl = fs.add(field, *tmpl, sanitized, stripped)
+}
+
+// text sanitizes a plain text field
+func (fs *fieldSanitizer) text(field string, text *string) {
+	sanitized, stripped := fs.sanitizer.SanitizeText(field, *text)
+	*text = fs.add(field, *text, sanitized, stripped)
+}
+
+// check writes the response and returns false when the sanitized fields
+// are rejected, which they are when a field only had HTML that isn't
+// allowed, or when anything was stripped in strict mode.
+func (fs *fieldSanitizer) check(c *gin.Context) bool {
+
+	if len(fs.emptied) != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    fmt.Sprintf("%s is empty once sanitized", fs.emptied[0]),
+			"stripped": fs.stripped,
+		})
+		return false
+	}
+
+	if fs.sanitizer.Strict() && len(fs.stripped) != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    UnsafeContentsMsg,
+			"stripped": fs.stripped,
+		})
+		return false
+	}
+
+	return true
+}
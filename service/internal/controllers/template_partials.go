@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/sanitizer"
+	"github.com/notifique/shared/auth"
+	"github.com/notifique/shared/cache"
+	sdto "github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
+)
+
+type TemplatePartialRegistry interface {
+	SaveTemplatePartial(ctx context.Context, createdBy string, tpr dto.TemplatePartialReq) (sdto.TemplatePartialDetails, error)
+	GetTemplatePartials(ctx context.Context, filters dto.TemplatePartialFilters) (sdto.Page[dto.TemplatePartialInfoResp], error)
+	GetTemplatePartial(ctx context.Context, name string) (sdto.TemplatePartialDetails, error)
+	GetTemplatePartialVersion(ctx context.Context, name string, version int) (sdto.TemplatePartialDetails, error)
+	UpdateTemplatePartial(ctx context.Context, name, updatedBy string, tpr dto.TemplatePartialUpdateReq) (sdto.TemplatePartialDetails, error)
+	DeleteTemplatePartial(ctx context.Context, name string) error
+}
+
+type TemplatePartialController struct {
+	Registry  TemplatePartialRegistry
+	Cache     cache.Cache
+	Sanitizer *sanitizer.Sanitizer
+}
+
+// validatePartial sanitizes the partial, checks its syntax and that the
+// partials it includes exist.
+func (tpc *TemplatePartialController) validatePartial(c *gin.Context, partial *sdto.TemplatePartialDetails) bool {
+
+	fs := newFieldSanitizer(tpc.Sanitizer)
+	fs.template("contents", &partial.Contents)
+
+	if !fs.check(c) {
+		return false
+	}
+
+	if err := render.ValidatePartial(*partial); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	dependencies := sdto.NotificationTemplateDetails{
+		ContentsTemplate: partial.Contents,
+	}
+
+	_, err := render.ResolvePartials(dependencies, func(name string) (sdto.TemplatePartialDetails, error) {
+
+		// The partial being created or updated may include itself
+		if name == partial.Name {
+			return *partial, nil
+		}
+
+		return tpc.Registry.GetTemplatePartial(c.Request.Context(), name)
+	})
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+		return false
+	}
+
+	return true
+}
+
+// deletePartialsCache deletes the cached partials, which includes the
+// details and versions of the partials.
+func (tpc *TemplatePartialController) deletePartialsCache(c *gin.Context) {
+
+	partialsPath, _ := internal.GetBasePath(c.Request.URL.Path, ".*/partials")
+
+	err := tpc.Cache.DelWithPrefix(
+		c.Request.Context(),
+		cache.GetEndpointKeyWithPrefix(partialsPath, nil))
+
+	if err != nil {
+		err = fmt.Errorf("error deleting template partials cache: %w", err)
+		slog.Error(err.Error())
+	}
+}
+
+func (tpc *TemplatePartialController) CreateTemplatePartial(c *gin.Context) {
+
+	var tpr dto.TemplatePartialReq
+
+	if err := c.ShouldBindJSON(&tpr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partial := sdto.TemplatePartialDetails{
+		Name:     tpr.Name,
+		Kind:     tpr.Kind,
+		Contents: tpr.Contents,
+	}
+
+	if !tpc.validatePartial(c, &partial) {
+		return
+	}
+
+	tpr.Contents = partial.Contents
+
+	userId := c.GetHeader(string(auth.UserHeader))
+
+	details, err := tpc.Registry.SaveTemplatePartial(c.Request.Context(), userId, tpr)
+
+	if err != nil {
+		if errors.As(err, &internal.TemplatePartialAlreadyExists{}) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, details)
+
+	tpc.deletePartialsCache(c)
+}
+
+func (tpc *TemplatePartialController) GetTemplatePartials(c *gin.Context) {
+
+	var filters dto.TemplatePartialFilters
+
+	if err := c.ShouldBind(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partials, err := tpc.Registry.GetTemplatePartials(c.Request.Context(), filters)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, partials)
+}
+
+func (tpc *TemplatePartialController) GetTemplatePartial(c *gin.Context) {
+
+	var params dto.TemplatePartialUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partial, err := tpc.Registry.GetTemplatePartial(c.Request.Context(), params.Name)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, partial)
+}
+
+func (tpc *TemplatePartialController) GetTemplatePartialVersion(c *gin.Context) {
+
+	var params dto.TemplatePartialVersionUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partial, err := tpc.Registry.GetTemplatePartialVersion(c.Request.Context(), params.Name, params.Version)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, partial)
+}
+
+// UpdateTemplatePartial stores the partial as a new version, which is
+// used by the templates from then on.
+func (tpc *TemplatePartialController) UpdateTemplatePartial(c *gin.Context) {
+
+	var params dto.TemplatePartialUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tpr dto.TemplatePartialUpdateReq
+
+	if err := c.ShouldBindJSON(&tpr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current, err := tpc.Registry.GetTemplatePartial(c.Request.Context(), params.Name)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	current.Contents = tpr.Contents
+
+	if !tpc.validatePartial(c, &current) {
+		return
+	}
+
+	tpr.Contents = current.Contents
+
+	userId := c.GetHeader(string(auth.UserHeader))
+
+	details, err := tpc.Registry.UpdateTemplatePartial(c.Request.Context(), params.Name, userId, tpr)
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.As(err, &internal.TemplateUpdateConflict{}) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+
+	tpc.deletePartialsCache(c)
+}
+
+// DeleteTemplatePartial deletes the partial and its versions, which is
+// refused while templates use it.
+func (tpc *TemplatePartialController) DeleteTemplatePartial(c *gin.Context) {
+
+	var params dto.TemplatePartialUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := tpc.Registry.DeleteTemplatePartial(c.Request.Context(), params.Name)
+
+	if err != nil {
+		if errors.As(err, &internal.TemplatePartialInUse{}) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+
+	tpc.deletePartialsCache(c)
+}
@@ -6,57 +6,192 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/microcosm-cc/bluemonday"
-
 	"github.com/notifique/service/internal"
 	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/sanitizer"
 	"github.com/notifique/shared/auth"
 	"github.com/notifique/shared/cache"
 	sdto "github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
 )
 
 type NotificationTemplateRegistry interface {
 	SaveTemplate(ctx context.Context, createdBy string, ntr dto.NotificationTemplateReq) (dto.NotificationTemplateCreatedResp, error)
 	GetTemplates(ctx context.Context, filters dto.NotificationTemplateFilters) (sdto.Page[dto.NotificationTemplateInfoResp], error)
 	GetTemplateDetails(ctx context.Context, id string) (sdto.NotificationTemplateDetails, error)
-	DeleteTemplate(ctx context.Context, id string) error
+	ArchiveTemplate(ctx context.Context, templateId, archivedBy string) error
+	PurgeTemplate(ctx context.Context, templateId string) error
+	UpdateTemplate(ctx context.Context, templateId, updatedBy string, ntr dto.NotificationTemplateReq) (sdto.NotificationTemplateDetails, error)
+	GetTemplateVersions(ctx context.Context, templateId string, filters sdto.PageFilter) (sdto.Page[dto.NotificationTemplateVersionResp], error)
+	GetTemplateVersion(ctx context.Context, templateId string, version int) (sdto.NotificationTemplateDetails, error)
 }
 
 type NotificationTemplateController struct {
-	Registry NotificationTemplateRegistry
-	Cache    cache.Cache
+	Registry  NotificationTemplateRegistry
+	Partials  TemplatePartialRegistry
+	Cache     cache.Cache
+	Sanitizer *sanitizer.Sanitizer
+}
+
+// templateIssues are the issues of a template that don't prevent it
+// from being saved: the warnings of its lint and the HTML stripped from
+// it by the sanitization.
+type templateIssues struct {
+	warnings []sdto.TemplateLintIssue
+	stripped []sdto.StrippedContent
 }
 
-type Sanitizer func(s string) string
+// bindTemplate binds the template of the request, sanitizes it and
+// checks that it renders, returning the issues that don't prevent it
+// from being saved.
+func (ntc *NotificationTemplateController) bindTemplate(c *gin.Context) (dto.NotificationTemplateReq, templateIssues, bool) {
+
+	var ntr dto.NotificationTemplateReq
+	var issues templateIssues
+
+	if err := c.ShouldBindJSON(&ntr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return ntr, issues, false
+	}
+
+	if ntr.Format == "" {
+		ntr.Format = sdto.LegacyFormat(ntr.IsHtml)
+	}
 
-var sanitize Sanitizer = func() Sanitizer {
-	p := bluemonday.UGCPolicy()
+	ntr.IsHtml = ntr.Format != sdto.Text
 
-	return func(s string) string {
-		sanitized := p.Sanitize(s)
+	fs := newFieldSanitizer(ntc.Sanitizer)
 
-		if sanitized != "" {
-			return sanitized
+	// The titles and text contents aren't displayed as HTML, so they
+	// aren't escaped. The markdown escapes its HTML when it's rendered,
+	// the sanitizer would escape the markdown quotes and code instead.
+	contents := func(field string, tmpl *string) {
+		switch ntr.Format {
+		case sdto.Html:
+			fs.template(field, tmpl)
+		case sdto.Text:
+			fs.text(field, tmpl)
 		}
+	}
+
+	contents("contentsTemplate", &ntr.ContentsTemplate)
+	fs.text("titleTemplate", &ntr.TitleTemplate)
 
-		return s
+	for i := range ntr.Localizations {
+		l := &ntr.Localizations[i]
+		contents(fmt.Sprintf("localizations[%d].contentsTemplate", i), &l.ContentsTemplate)
+		fs.text(fmt.Sprintf("localizations[%d].titleTemplate", i), &l.TitleTemplate)
 	}
-}()
 
-func (ntc *NotificationTemplateController) CreateNotificationTemplate(c *gin.Context) {
+	if !fs.check(c) {
+		return ntr, issues, false
+	}
 
-	var ntr dto.NotificationTemplateReq
+	warnings, ok := ntc.validateTemplate(c, &ntr)
 
-	if err := c.ShouldBindJSON(&ntr); err != nil {
+	issues.warnings = warnings
+	issues.stripped = fs.stripped
+
+	return ntr, issues, ok
+}
+
+// validateTemplate checks that the template and its localizations only
+// reference declared variables and that they render with its layout
+// and partials, which are kept in the request. The unused variables
+// are returned as warnings.
+func (ntc *NotificationTemplateController) validateTemplate(c *gin.Context, ntr *dto.NotificationTemplateReq) ([]sdto.TemplateLintIssue, bool) {
+
+	template := sdto.NotificationTemplateDetails{
+		TitleTemplate:    ntr.TitleTemplate,
+		ContentsTemplate: ntr.ContentsTemplate,
+		IsHtml:           ntr.IsHtml,
+		Format:           ntr.Format,
+		Layout:           ntr.Layout,
+		DefaultLocale:    ntr.DefaultLocale,
+		Localizations:    ntr.Localizations,
+		Variables:        ntr.Variables,
+	}
+
+	partials, ok := ntc.resolvePartials(c, template)
+
+	if !ok {
+		return nil, false
+	}
+
+	errs, warnings := render.Lint(template, partials)
+
+	if len(errs) != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "template references undeclared variables",
+			"errors": errs,
+		})
+		return nil, false
+	}
+
+	if err := render.Validate(template, partials); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return nil, false
 	}
 
-	ntr.ContentsTemplate = sanitize(ntr.ContentsTemplate)
-	ntr.TitleTemplate = sanitize(ntr.TitleTemplate)
+	ntr.Partials = nil
+
+	for name := range partials {
+		ntr.Partials = append(ntr.Partials, name)
+	}
+
+	slices.Sort(ntr.Partials)
+
+	return warnings, true
+}
+
+// resolvePartials gets the layout and partials of the template, the
+// ones that don't exist are reported as a bad request.
+func (ntc *NotificationTemplateController) resolvePartials(c *gin.Context, t sdto.NotificationTemplateDetails) (render.Partials, bool) {
+
+	partials, err := render.ResolvePartials(t, func(name string) (sdto.TemplatePartialDetails, error) {
+		return ntc.Partials.GetTemplatePartial(c.Request.Context(), name)
+	})
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+
+	return partials, true
+}
+
+// deleteTemplateCache deletes the cached templates, which includes the
+// details and versions of the templates.
+func (ntc *NotificationTemplateController) deleteTemplateCache(c *gin.Context) {
+
+	templatesPath, _ := internal.GetBasePath(c.Request.URL.Path, ".*/templates")
+
+	err := ntc.Cache.DelWithPrefix(
+		c.Request.Context(),
+		cache.GetEndpointKeyWithPrefix(templatesPath, nil))
+
+	if err != nil {
+		err = fmt.Errorf("error deleting templates cache: %w", err)
+		slog.Error(err.Error())
+	}
+}
+
+func (ntc *NotificationTemplateController) CreateNotificationTemplate(c *gin.Context) {
+
+	ntr, issues, ok := ntc.bindTemplate(c)
+
+	if !ok {
+		return
+	}
 
 	userId := c.GetHeader(string(auth.UserHeader))
 
@@ -68,6 +203,9 @@ func (ntc *NotificationTemplateController) CreateNotificationTemplate(c *gin.Con
 		return
 	}
 
+	resp.Warnings = issues.warnings
+	resp.Stripped = issues.stripped
+
 	c.JSON(http.StatusCreated, resp)
 
 	err = ntc.Cache.DelWithPrefix(
@@ -121,6 +259,8 @@ func (ntc *NotificationTemplateController) GetTemplateDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, notification)
 }
 
+// DeleteTemplate archives the template, keeping the notifications that
+// were sent with it.
 func (ntc *NotificationTemplateController) DeleteTemplate(c *gin.Context) {
 
 	var params dto.NotificationTemplateUriParams
@@ -130,11 +270,14 @@ func (ntc *NotificationTemplateController) DeleteTemplate(c *gin.Context) {
 		return
 	}
 
-	err := ntc.Registry.DeleteTemplate(c.Request.Context(), params.Id)
+	userId := c.GetHeader(string(auth.UserHeader))
+
+	err := ntc.Registry.ArchiveTemplate(c.Request.Context(), params.Id, userId)
 
 	if err != nil {
 		slog.Error(err.Error())
 		c.Status(http.StatusInternalServerError)
+		return
 	}
 
 	c.Status(http.StatusNoContent)
@@ -161,3 +304,232 @@ func (ntc *NotificationTemplateController) DeleteTemplate(c *gin.Context) {
 		slog.Error(err.Error())
 	}
 }
+
+// PurgeTemplate permanently deletes an archived template that isn't
+// referenced by any notification or schedule.
+func (ntc *NotificationTemplateController) PurgeTemplate(c *gin.Context) {
+
+	var params dto.NotificationTemplateUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := ntc.Registry.PurgeTemplate(c.Request.Context(), params.Id)
+
+	if err != nil {
+		if errors.As(err, &internal.TemplateInUse{}) ||
+			errors.As(err, &internal.TemplateNotArchived{}) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+
+	ntc.deleteTemplateCache(c)
+}
+
+func (ntc *NotificationTemplateController) RenderTemplate(c *gin.Context) {
+
+	var params dto.NotificationTemplateUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.NotificationTemplateRenderReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := ntc.Registry.GetTemplateDetails(c.Request.Context(), params.Id)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	templateContents := sdto.TemplateContents{
+		Id:        params.Id,
+		Variables: req.Variables,
+	}
+
+	if err := internal.ValidateTemplateVars(template.Variables, templateContents); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partials, ok := ntc.resolvePartials(c, template)
+
+	if !ok {
+		return
+	}
+
+	rendered, err := render.Template(render.Localize(template, req.Locale), partials, req.Variables)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rendered)
+}
+
+// updateTemplate stores the template as a new version of the template,
+// returning it with the issues of the template
+func (ntc *NotificationTemplateController) updateTemplate(c *gin.Context, templateId string, ntr dto.NotificationTemplateReq, issues templateIssues) {
+
+	userId := c.GetHeader(string(auth.UserHeader))
+
+	details, err := ntc.Registry.UpdateTemplate(c.Request.Context(), templateId, userId, ntr)
+
+	if err != nil {
+		if errors.As(err, &internal.EntityNotFound{}) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.As(err, &internal.TemplateUpdateConflict{}) ||
+			errors.As(err, &internal.TemplateArchived{}) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NotificationTemplateUpdatedResp{
+		NotificationTemplateDetails: details,
+		Warnings:                    issues.warnings,
+		Stripped:                    issues.stripped,
+	})
+
+	ntc.deleteTemplateCache(c)
+}
+
+func (ntc *NotificationTemplateController) UpdateTemplate(c *gin.Context) {
+
+	var params dto.NotificationTemplateUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ntr, issues, ok := ntc.bindTemplate(c)
+
+	if !ok {
+		return
+	}
+
+	ntc.updateTemplate(c, params.Id, ntr, issues)
+}
+
+func (ntc *NotificationTemplateController) GetTemplateVersions(c *gin.Context) {
+
+	var params dto.NotificationTemplateUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var filters sdto.PageFilter
+
+	if err := c.ShouldBind(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	versions, err := ntc.Registry.GetTemplateVersions(c.Request.Context(), params.Id, filters)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+func (ntc *NotificationTemplateController) getTemplateVersion(c *gin.Context) (sdto.NotificationTemplateDetails, bool) {
+
+	var params dto.NotificationTemplateVersionUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return sdto.NotificationTemplateDetails{}, false
+	}
+
+	template, err := ntc.Registry.GetTemplateVersion(c.Request.Context(), params.Id, params.Version)
+
+	if err != nil && errors.As(err, &internal.EntityNotFound{}) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return template, false
+	} else if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return template, false
+	}
+
+	return template, true
+}
+
+func (ntc *NotificationTemplateController) GetTemplateVersion(c *gin.Context) {
+
+	template, ok := ntc.getTemplateVersion(c)
+
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// RollbackTemplate restores a previous version of the template, which
+// is stored as a new version, so the versions are never modified.
+func (ntc *NotificationTemplateController) RollbackTemplate(c *gin.Context) {
+
+	template, ok := ntc.getTemplateVersion(c)
+
+	if !ok {
+		return
+	}
+
+	ntr := dto.NotificationTemplateReq{
+		Name:             template.Name,
+		IsHtml:           template.IsHtml,
+		Format:           template.Format,
+		TitleTemplate:    template.TitleTemplate,
+		ContentsTemplate: template.ContentsTemplate,
+		Description:      template.Description,
+		Category:         template.Category,
+		Tags:             template.Tags,
+		Layout:           template.Layout,
+		DefaultLocale:    template.DefaultLocale,
+		Localizations:    template.Localizations,
+		Variables:        template.Variables,
+	}
+
+	// The version was sanitized when it was stored
+	warnings, ok := ntc.validateTemplate(c, &ntr)
+
+	if !ok {
+		return
+	}
+
+	ntc.updateTemplate(c, template.Id, ntr, templateIssues{warnings: warnings})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/notifique/service/internal"
 	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/sanitizer"
 	"github.com/notifique/shared/auth"
 	"github.com/notifique/shared/cache"
 	sdto "github.com/notifique/shared/dto"
@@ -25,6 +26,11 @@ type UserRegistry interface {
 	CreateNotifications(ctx context.Context, notifications []sdto.UserNotificationReq) ([]dto.UserNotification, error)
 }
 
+// UserDistributionListRegistry gets the distribution lists of the users.
+type UserDistributionListRegistry interface {
+	GetDistributionLists(ctx context.Context, filters dto.DistributionListFilters) (sdto.Page[dto.DistributionListSummary], error)
+}
+
 type UserNotificationBroker interface {
 	Suscribe(ctx context.Context, userId string) (<-chan dto.UserNotification, error)
 	Unsubscribe(ctx context.Context, userId string) error
@@ -32,9 +38,22 @@ type UserNotificationBroker interface {
 }
 
 type UserController struct {
-	Registry UserRegistry
-	Broker   UserNotificationBroker
-	Cache    cache.Cache
+	Registry          UserRegistry
+	DistributionLists UserDistributionListRegistry
+	Broker            UserNotificationBroker
+	Cache             cache.Cache
+	Sanitizer         *sanitizer.Sanitizer
+}
+
+// withFormat returns the notification with the contents the client can
+// display. The HTML contents are removed unless the client asks for them.
+func withFormat(un dto.UserNotification, format sdto.TemplateFormat) dto.UserNotification {
+
+	if format != sdto.Html {
+		un.HtmlContents = nil
+	}
+
+	return un
 }
 
 func (nc *UserController) GetUserNotifications(c *gin.Context) {
@@ -54,6 +73,10 @@ func (nc *UserController) GetUserNotifications(c *gin.Context) {
 		return
 	}
 
+	for i, n := range notifications.Data {
+		notifications.Data[i] = withFormat(n, filters.Format)
+	}
+
 	c.JSON(http.StatusOK, notifications)
 }
 
@@ -70,6 +93,51 @@ func (nc *UserController) GetUserConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, cfg)
 }
 
+// GetUserDistributionLists returns the lists the user is a recipient of.
+func (nc *UserController) GetUserDistributionLists(c *gin.Context) {
+	var filters dto.DistributionListFilters
+
+	if err := c.ShouldBind(&filters.PageFilter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := c.GetHeader(string(auth.UserHeader))
+	filters.Member = &userId
+
+	lists, err := nc.DistributionLists.GetDistributionLists(c, filters)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+// GetRecipientConfig returns the config of a user, so the notifications
+// sent to them can be localized.
+func (nc *UserController) GetRecipientConfig(c *gin.Context) {
+
+	var params dto.UserUriParams
+
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cfg, err := nc.Registry.GetUserConfig(c, params.UserId)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, cfg)
+}
+
 func (nc *UserController) SetReadStatus(c *gin.Context) {
 	var n dto.NotificationUriParams
 
@@ -144,6 +212,15 @@ func (nc *UserController) CreateNotifications(c *gin.Context) {
 		return
 	}
 
+	// The HTML contents are rendered from sanitized templates, so they
+	// aren't rejected in strict mode, the notifications are delivered
+	for i, n := range batch {
+		if n.HtmlContents != nil {
+			sanitized, _ := nc.Sanitizer.Sanitize("htmlContents", *n.HtmlContents)
+			batch[i].HtmlContents = &sanitized
+		}
+	}
+
 	notifications, err := nc.Registry.CreateNotifications(c, batch)
 
 	if err != nil {
@@ -180,6 +257,13 @@ func (nc *UserController) CreateNotifications(c *gin.Context) {
 
 func (nc *UserController) GetLiveUserNotifications(c *gin.Context) {
 
+	var format dto.UserNotificationFormat
+
+	if err := c.ShouldBindQuery(&format); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	userId := c.GetHeader(string(auth.UserHeader))
 	ch, err := nc.Broker.Suscribe(c, userId)
 
@@ -204,7 +288,7 @@ func (nc *UserController) GetLiveUserNotifications(c *gin.Context) {
 				slog.Info(fmt.Sprintf("user %s disconnected", userId))
 				return false
 			case un := <-ch:
-				marshalled, err := json.Marshal(un)
+				marshalled, err := json.Marshal(withFormat(un, format.Format))
 				if err != nil {
 					slog.Error(err.Error())
 					continue
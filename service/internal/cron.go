@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/robfig/cron/v3"
+)
+
+const DefaultScheduleTimezone = "UTC"
+
+// Standard five field cron expressions, plus descriptors such as
+// @daily or @weekly.
+var cronParser = cron.NewParser(
+	cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+var CronValidator validator.Func = func(fl validator.FieldLevel) bool {
+	expression, ok := fl.Field().Interface().(string)
+
+	if !ok {
+		return false
+	}
+
+	_, err := cronParser.Parse(expression)
+
+	return err == nil
+}
+
+// NextCronRuns returns the next count occurrences of the cron expression
+// after the given time, evaluated in the given timezone.
+func NextCronRuns(expression, timezone string, after time.Time, count int) ([]time.Time, error) {
+
+	schedule, err := cronParser.Parse(expression)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cron expression - %w", err)
+	}
+
+	if timezone == "" {
+		timezone = DefaultScheduleTimezone
+	}
+
+	location, err := time.LoadLocation(timezone)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone - %w", err)
+	}
+
+	runs := make([]time.Time, 0, count)
+	next := after.In(location)
+
+	for i := 0; i < count; i++ {
+		next = schedule.Next(next)
+
+		if next.IsZero() {
+			break
+		}
+
+		runs = append(runs, next)
+	}
+
+	return runs, nil
+}
+
+// NextCronRun returns the first occurrence of the cron expression after
+// the given time.
+func NextCronRun(expression, timezone string, after time.Time) (time.Time, error) {
+
+	runs, err := NextCronRuns(expression, timezone, after, 1)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(runs) == 0 {
+		return time.Time{}, fmt.Errorf("cron expression %s has no future runs", expression)
+	}
+
+	return runs[0], nil
+}
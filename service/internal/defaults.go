@@ -1,5 +1,10 @@
 package internal
 
 const (
-	PageSize = 25
+	PageSize          = 25
+	SegmentSampleSize = 10
+	// Recipients read and written at a time by the imports and exports
+	RecipientsBatchSize = 1000
+	// Errors reported by the imports of recipients
+	MaxRecipientsImportErrors = 100
 )
@@ -13,7 +13,9 @@ import (
 	"github.com/google/wire"
 	"github.com/notifique/service/internal/controllers"
 	"github.com/notifique/service/internal/middleware"
+	"github.com/notifique/service/internal/outbox"
 	"github.com/notifique/service/internal/routes"
+	"github.com/notifique/service/internal/scheduler"
 	"github.com/notifique/service/pkg/deployments"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/mock/gomock"
@@ -26,11 +28,18 @@ import (
 	tcfg "github.com/notifique/service/internal/testutils/config"
 	c "github.com/notifique/service/internal/testutils/containers"
 	mk "github.com/notifique/service/internal/testutils/mocks"
+	"github.com/notifique/shared/audience"
 	cache "github.com/notifique/shared/cache"
 	"github.com/notifique/shared/clients"
 	sc "github.com/notifique/shared/containers"
 )
 
+type App struct {
+	Engine    *gin.Engine
+	Scheduler *scheduler.Scheduler
+	Relay     *outbox.Relay
+}
+
 type PostgresMockedPubIntegrationTest struct {
 	Postgres  *sc.Postgres
 	Redis     *sc.Redis
@@ -86,6 +95,7 @@ type MockedBackend struct {
 	Publisher *mk.MockNotificationPublisher
 	Broker    *mk.MockUserNotificationBroker
 	Cache     *mk.MockCache
+	Audience  *mk.MockAudienceResolver
 	Engine    *gin.Engine
 }
 
@@ -95,12 +105,18 @@ var DynamoSet = wire.NewSet(
 	wire.Bind(new(dynamoregistry.DynamoDBAPI), new(*dynamodb.Client)),
 	wire.Bind(new(routes.Registry), new(*dynamoregistry.Registry)),
 	wire.Bind(new(controllers.NotificationRegistry), new(*dynamoregistry.Registry)),
+	wire.Bind(new(scheduler.ScheduledNotificationRegistry), new(*dynamoregistry.Registry)),
+	wire.Bind(new(scheduler.RecurringScheduleRegistry), new(*dynamoregistry.Registry)),
+	wire.Bind(new(outbox.OutboxRegistry), new(*dynamoregistry.Registry)),
 )
 
 var PostgresSet = wire.NewSet(
 	pg.NewPostgresRegistry,
 	wire.Bind(new(routes.Registry), new(*pg.Registry)),
 	wire.Bind(new(controllers.NotificationRegistry), new(*pg.Registry)),
+	wire.Bind(new(scheduler.ScheduledNotificationRegistry), new(*pg.Registry)),
+	wire.Bind(new(scheduler.RecurringScheduleRegistry), new(*pg.Registry)),
+	wire.Bind(new(outbox.OutboxRegistry), new(*pg.Registry)),
 )
 
 var SQSPublisherSet = wire.NewSet(
@@ -137,12 +153,34 @@ var RedisUserNotificationBrokerSet = wire.NewSet(
 	wire.Bind(new(controllers.UserNotificationBroker), new(*bk.Redis)),
 )
 
+var CognitoAudienceSet = wire.NewSet(
+	clients.NewCognitoClient,
+	audience.NewCognito,
+	wire.Bind(new(controllers.AudienceResolver), new(*audience.Cognito)),
+)
+
 var PrioritySet = wire.NewSet(
 	PriorityPublisherCfgSet,
 	pub.NewPriorityPublisher,
 	wire.Bind(new(controllers.NotificationPublisher), new(*pub.Priority)),
 )
 
+var SchedulerSet = wire.NewSet(
+	wire.Struct(new(scheduler.SchedulerCfg), "*"),
+	scheduler.NewScheduler,
+)
+
+var RelaySet = wire.NewSet(
+	wire.Struct(new(outbox.RelayCfg), "*"),
+	outbox.NewRelay,
+)
+
+var AppSet = wire.NewSet(
+	SchedulerSet,
+	RelaySet,
+	wire.Struct(new(App), "*"),
+)
+
 var PostgresContainerSet = wire.NewSet(
 	sc.NewPostgresContainer,
 	wire.Bind(new(clients.PostgresConfigurator), new(*sc.Postgres)),
@@ -212,16 +250,33 @@ var MockedNotificationTemplateRegistrySet = wire.NewSet(
 	wire.Bind(new(controllers.NotificationTemplateRegistry), new(*mk.MockNotificationTemplateRegistry)),
 )
 
+var MockedTemplatePartialRegistrySet = wire.NewSet(
+	mk.NewMockTemplatePartialRegistry,
+	wire.Bind(new(controllers.TemplatePartialRegistry), new(*mk.MockTemplatePartialRegistry)),
+)
+
+var MockedAudienceResolverSet = wire.NewSet(
+	mk.NewMockAudienceResolver,
+	wire.Bind(new(controllers.AudienceResolver), new(*mk.MockAudienceResolver)),
+)
+
 var MockedUserNotificationBroker = wire.NewSet(
 	mk.NewMockUserNotificationBroker,
 	wire.Bind(new(controllers.UserNotificationBroker), new(*mk.MockUserNotificationBroker)),
 )
 
+var MockedNotificationScheduleRegistrySet = wire.NewSet(
+	mk.NewMockNotificationScheduleRegistry,
+	wire.Bind(new(controllers.NotificationScheduleRegistry), new(*mk.MockNotificationScheduleRegistry)),
+)
+
 var MockedRegistrySet = wire.NewSet(
 	MockedDistributionRegistrySet,
 	MockedUserRegistrySet,
 	MockedNotificationRegistrySet,
 	MockedNotificationTemplateRegistrySet,
+	MockedTemplatePartialRegistrySet,
+	MockedNotificationScheduleRegistrySet,
 	mk.NewMockedRegistry,
 	wire.Bind(new(routes.Registry), new(*mk.MockedRegistry)),
 )
@@ -255,6 +310,10 @@ var EnvConfigSet = wire.NewSet(
 	wire.Bind(new(middleware.CacheConfigurator), new(*cfg.EnvConfig)),
 	wire.Bind(new(middleware.RateLimitConfigurator), new(*cfg.EnvConfig)),
 	wire.Bind(new(middleware.SecurityConfigurator), new(*cfg.EnvConfig)),
+	wire.Bind(new(scheduler.SchedulerConfigurator), new(*cfg.EnvConfig)),
+	wire.Bind(new(outbox.RelayConfigurator), new(*cfg.EnvConfig)),
+	wire.Bind(new(clients.CognitoConfigurator), new(*cfg.EnvConfig)),
+	wire.Bind(new(audience.CognitoConfigurator), new(*cfg.EnvConfig)),
 )
 
 var MockedCacheSet = wire.NewSet(
@@ -266,7 +325,7 @@ var EngineConfigSet = wire.NewSet(
 	wire.Struct(new(routes.EngineConfig), "*"),
 )
 
-func InjectPgPrioritySQS(envfile *string) (*gin.Engine, error) {
+func InjectPgPrioritySQS(envfile *string) (*App, error) {
 
 	wire.Build(
 		EnvConfigSet,
@@ -276,15 +335,17 @@ func InjectPgPrioritySQS(envfile *string) (*gin.Engine, error) {
 		RedisCacheSet,
 		PrioritySet,
 		RedisUserNotificationBrokerSet,
+		CognitoAudienceSet,
 		MiddlewareSet,
 		EngineConfigSet,
 		routes.NewEngine,
+		AppSet,
 	)
 
 	return nil, nil
 }
 
-func InjectPgPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error) {
+func InjectPgPriorityRabbitMQ(envfile *string) (*App, func(), error) {
 
 	wire.Build(
 		EnvConfigSet,
@@ -294,15 +355,17 @@ func InjectPgPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error) {
 		RedisCacheSet,
 		PrioritySet,
 		RedisUserNotificationBrokerSet,
+		CognitoAudienceSet,
 		MiddlewareSet,
 		EngineConfigSet,
 		routes.NewEngine,
+		AppSet,
 	)
 
 	return nil, nil, nil
 }
 
-func InjectDynamoPrioritySQS(envfile *string) (*gin.Engine, error) {
+func InjectDynamoPrioritySQS(envfile *string) (*App, error) {
 
 	wire.Build(
 		EnvConfigSet,
@@ -312,15 +375,17 @@ func InjectDynamoPrioritySQS(envfile *string) (*gin.Engine, error) {
 		RedisCacheSet,
 		PrioritySet,
 		RedisUserNotificationBrokerSet,
+		CognitoAudienceSet,
 		MiddlewareSet,
 		EngineConfigSet,
 		routes.NewEngine,
+		AppSet,
 	)
 
 	return nil, nil
 }
 
-func InjectDynamoPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error) {
+func InjectDynamoPriorityRabbitMQ(envfile *string) (*App, func(), error) {
 
 	wire.Build(
 		EnvConfigSet,
@@ -330,9 +395,11 @@ func InjectDynamoPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error)
 		RedisCacheSet,
 		PrioritySet,
 		RedisUserNotificationBrokerSet,
+		CognitoAudienceSet,
 		MiddlewareSet,
 		EngineConfigSet,
 		routes.NewEngine,
+		AppSet,
 	)
 
 	return nil, nil, nil
@@ -379,6 +446,7 @@ func InjectMockedBackend(ctx context.Context, mockController *gomock.Controller)
 		MockedRegistrySet,
 		MockedPublihserSet,
 		MockedUserNotificationBroker,
+		MockedAudienceResolverSet,
 		MockedCacheSet,
 		MockedMiddlewareSet,
 		EngineConfigSet,
@@ -17,14 +17,17 @@ import (
 	"github.com/notifique/service/internal/config"
 	"github.com/notifique/service/internal/controllers"
 	"github.com/notifique/service/internal/middleware"
+	"github.com/notifique/service/internal/outbox"
 	"github.com/notifique/service/internal/publish"
 	"github.com/notifique/service/internal/registry/dynamodb"
 	"github.com/notifique/service/internal/registry/postgres"
 	"github.com/notifique/service/internal/routes"
+	"github.com/notifique/service/internal/scheduler"
 	"github.com/notifique/service/internal/testutils/config"
 	containers2 "github.com/notifique/service/internal/testutils/containers"
 	"github.com/notifique/service/internal/testutils/mocks"
 	"github.com/notifique/service/pkg/deployments"
+	"github.com/notifique/shared/audience"
 	"github.com/notifique/shared/cache"
 	"github.com/notifique/shared/clients"
 	"github.com/notifique/shared/containers"
@@ -34,7 +37,7 @@ import (
 
 // Injectors from wire.go:
 
-func InjectPgPrioritySQS(envfile *string) (*gin.Engine, error) {
+func InjectPgPrioritySQS(envfile *string) (*App, error) {
 	envConfig, err := config.NewEnvConfig(envfile)
 	if err != nil {
 		return nil, err
@@ -67,6 +70,14 @@ func InjectPgPrioritySQS(envfile *string) (*gin.Engine, error) {
 	if err != nil {
 		return nil, err
 	}
+	cognitoidentityproviderClient, err := clients.NewCognitoClient(envConfig)
+	if err != nil {
+		return nil, err
+	}
+	cognito, err := audience.NewCognito(envConfig, cognitoidentityproviderClient)
+	if err != nil {
+		return nil, err
+	}
 	v := _wireValue
 	authMiddleware, err := middleware.NewAuthMiddleware(envConfig)
 	if err != nil {
@@ -94,8 +105,8 @@ func InjectPgPrioritySQS(envfile *string) (*gin.Engine, error) {
 		RedisClient:        client,
 		Registry:           registry,
 		Cache:              redis,
-		Publisher:          priority,
 		Broker:             brokerRedis,
+		Audience:           cognito,
 		EngineConfigurator: envConfig,
 		Authorize:          v,
 		Authenticate:       authMiddleware,
@@ -107,14 +118,37 @@ func InjectPgPrioritySQS(envfile *string) (*gin.Engine, error) {
 	if err != nil {
 		return nil, err
 	}
-	return engine, nil
+	schedulerCfg := scheduler.SchedulerCfg{
+		Registry:     registry,
+		Schedules:    registry,
+		Configurator: envConfig,
+	}
+	schedulerScheduler, err := scheduler.NewScheduler(schedulerCfg)
+	if err != nil {
+		return nil, err
+	}
+	relayCfg := outbox.RelayCfg{
+		Registry:     registry,
+		Publisher:    priority,
+		Configurator: envConfig,
+	}
+	relay, err := outbox.NewRelay(relayCfg)
+	if err != nil {
+		return nil, err
+	}
+	app := &App{
+		Engine:    engine,
+		Scheduler: schedulerScheduler,
+		Relay:     relay,
+	}
+	return app, nil
 }
 
 var (
 	_wireValue = middleware.Authorize
 )
 
-func InjectPgPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error) {
+func InjectPgPriorityRabbitMQ(envfile *string) (*App, func(), error) {
 	envConfig, err := config.NewEnvConfig(envfile)
 	if err != nil {
 		return nil, nil, err
@@ -148,6 +182,16 @@ func InjectPgPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error) {
 		cleanup()
 		return nil, nil, err
 	}
+	cognitoidentityproviderClient, err := clients.NewCognitoClient(envConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cognito, err := audience.NewCognito(envConfig, cognitoidentityproviderClient)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 	v := _wireValue
 	authMiddleware, err := middleware.NewAuthMiddleware(envConfig)
 	if err != nil {
@@ -178,8 +222,8 @@ func InjectPgPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error) {
 		RedisClient:        client,
 		Registry:           registry,
 		Cache:              redis,
-		Publisher:          priority,
 		Broker:             brokerRedis,
+		Audience:           cognito,
 		EngineConfigurator: envConfig,
 		Authorize:          v,
 		Authenticate:       authMiddleware,
@@ -192,12 +236,37 @@ func InjectPgPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error) {
 		cleanup()
 		return nil, nil, err
 	}
-	return engine, func() {
+	schedulerCfg := scheduler.SchedulerCfg{
+		Registry:     registry,
+		Schedules:    registry,
+		Configurator: envConfig,
+	}
+	schedulerScheduler, err := scheduler.NewScheduler(schedulerCfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	relayCfg := outbox.RelayCfg{
+		Registry:     registry,
+		Publisher:    priority,
+		Configurator: envConfig,
+	}
+	relay, err := outbox.NewRelay(relayCfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	app := &App{
+		Engine:    engine,
+		Scheduler: schedulerScheduler,
+		Relay:     relay,
+	}
+	return app, func() {
 		cleanup()
 	}, nil
 }
 
-func InjectDynamoPrioritySQS(envfile *string) (*gin.Engine, error) {
+func InjectDynamoPrioritySQS(envfile *string) (*App, error) {
 	envConfig, err := config.NewEnvConfig(envfile)
 	if err != nil {
 		return nil, err
@@ -231,6 +300,14 @@ func InjectDynamoPrioritySQS(envfile *string) (*gin.Engine, error) {
 	if err != nil {
 		return nil, err
 	}
+	cognitoidentityproviderClient, err := clients.NewCognitoClient(envConfig)
+	if err != nil {
+		return nil, err
+	}
+	cognito, err := audience.NewCognito(envConfig, cognitoidentityproviderClient)
+	if err != nil {
+		return nil, err
+	}
 	v := _wireValue
 	authMiddleware, err := middleware.NewAuthMiddleware(envConfig)
 	if err != nil {
@@ -258,8 +335,8 @@ func InjectDynamoPrioritySQS(envfile *string) (*gin.Engine, error) {
 		RedisClient:        client,
 		Registry:           registry,
 		Cache:              redis,
-		Publisher:          priority,
 		Broker:             brokerRedis,
+		Audience:           cognito,
 		EngineConfigurator: envConfig,
 		Authorize:          v,
 		Authenticate:       authMiddleware,
@@ -271,10 +348,33 @@ func InjectDynamoPrioritySQS(envfile *string) (*gin.Engine, error) {
 	if err != nil {
 		return nil, err
 	}
-	return engine, nil
+	schedulerCfg := scheduler.SchedulerCfg{
+		Registry:     registry,
+		Schedules:    registry,
+		Configurator: envConfig,
+	}
+	schedulerScheduler, err := scheduler.NewScheduler(schedulerCfg)
+	if err != nil {
+		return nil, err
+	}
+	relayCfg := outbox.RelayCfg{
+		Registry:     registry,
+		Publisher:    priority,
+		Configurator: envConfig,
+	}
+	relay, err := outbox.NewRelay(relayCfg)
+	if err != nil {
+		return nil, err
+	}
+	app := &App{
+		Engine:    engine,
+		Scheduler: schedulerScheduler,
+		Relay:     relay,
+	}
+	return app, nil
 }
 
-func InjectDynamoPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error) {
+func InjectDynamoPriorityRabbitMQ(envfile *string) (*App, func(), error) {
 	envConfig, err := config.NewEnvConfig(envfile)
 	if err != nil {
 		return nil, nil, err
@@ -309,6 +409,16 @@ func InjectDynamoPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error)
 		cleanup()
 		return nil, nil, err
 	}
+	cognitoidentityproviderClient, err := clients.NewCognitoClient(envConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cognito, err := audience.NewCognito(envConfig, cognitoidentityproviderClient)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 	v := _wireValue
 	authMiddleware, err := middleware.NewAuthMiddleware(envConfig)
 	if err != nil {
@@ -339,8 +449,8 @@ func InjectDynamoPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error)
 		RedisClient:        client,
 		Registry:           registry,
 		Cache:              redis,
-		Publisher:          priority,
 		Broker:             brokerRedis,
+		Audience:           cognito,
 		EngineConfigurator: envConfig,
 		Authorize:          v,
 		Authenticate:       authMiddleware,
@@ -353,7 +463,32 @@ func InjectDynamoPriorityRabbitMQ(envfile *string) (*gin.Engine, func(), error)
 		cleanup()
 		return nil, nil, err
 	}
-	return engine, func() {
+	schedulerCfg := scheduler.SchedulerCfg{
+		Registry:     registry,
+		Schedules:    registry,
+		Configurator: envConfig,
+	}
+	schedulerScheduler, err := scheduler.NewScheduler(schedulerCfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	relayCfg := outbox.RelayCfg{
+		Registry:     registry,
+		Publisher:    priority,
+		Configurator: envConfig,
+	}
+	relay, err := outbox.NewRelay(relayCfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	app := &App{
+		Engine:    engine,
+		Scheduler: schedulerScheduler,
+		Relay:     relay,
+	}
+	return app, func() {
 		cleanup()
 	}, nil
 }
@@ -500,9 +635,12 @@ func InjectMockedBackend(ctx context.Context, mockController *gomock.Controller)
 	mockUserRegistry := mocks.NewMockUserRegistry(mockController)
 	mockNotificationRegistry := mocks.NewMockNotificationRegistry(mockController)
 	mockNotificationTemplateRegistry := mocks.NewMockNotificationTemplateRegistry(mockController)
-	mockedRegistry := mocks.NewMockedRegistry(mockDistributionRegistry, mockUserRegistry, mockNotificationRegistry, mockNotificationTemplateRegistry)
+	mockTemplatePartialRegistry := mocks.NewMockTemplatePartialRegistry(mockController)
+	mockNotificationScheduleRegistry := mocks.NewMockNotificationScheduleRegistry(mockController)
+	mockedRegistry := mocks.NewMockedRegistry(mockDistributionRegistry, mockUserRegistry, mockNotificationRegistry, mockNotificationTemplateRegistry, mockTemplatePartialRegistry, mockNotificationScheduleRegistry)
 	mockNotificationPublisher := mocks.NewMockNotificationPublisher(mockController)
 	mockUserNotificationBroker := mocks.NewMockUserNotificationBroker(mockController)
+	mockAudienceResolver := mocks.NewMockAudienceResolver(mockController)
 	mockCache := mocks.NewMockCache(mockController)
 	client := _wireClientValue
 	testEngineConfigurator := config_test.NewTestVersionConfigurator()
@@ -515,8 +653,8 @@ func InjectMockedBackend(ctx context.Context, mockController *gomock.Controller)
 		RedisClient:        client,
 		Registry:           mockedRegistry,
 		Cache:              mockCache,
-		Publisher:          mockNotificationPublisher,
 		Broker:             mockUserNotificationBroker,
+		Audience:           mockAudienceResolver,
 		EngineConfigurator: testEngineConfigurator,
 		Authorize:          v,
 		Authenticate:       authMiddleware,
@@ -533,6 +671,7 @@ func InjectMockedBackend(ctx context.Context, mockController *gomock.Controller)
 		Publisher: mockNotificationPublisher,
 		Broker:    mockUserNotificationBroker,
 		Cache:     mockCache,
+		Audience:  mockAudienceResolver,
 		Engine:    engine,
 	}
 	return mockedBackend, nil
@@ -572,6 +711,12 @@ func InjectSQSPriorityDeployer(envfile *string) (*deployments.SQSPriorityDeploye
 
 // wire.go:
 
+type App struct {
+	Engine    *gin.Engine
+	Scheduler *scheduler.Scheduler
+	Relay     *outbox.Relay
+}
+
 type PostgresMockedPubIntegrationTest struct {
 	Postgres  *containers.Postgres
 	Redis     *containers.Redis
@@ -627,12 +772,13 @@ type MockedBackend struct {
 	Publisher *mocks.MockNotificationPublisher
 	Broker    *mocks.MockUserNotificationBroker
 	Cache     *mocks.MockCache
+	Audience  *mocks.MockAudienceResolver
 	Engine    *gin.Engine
 }
 
-var DynamoSet = wire.NewSet(clients.NewDynamoDBClient, dynamoregistry.NewDynamoDBRegistry, wire.Bind(new(dynamoregistry.DynamoDBAPI), new(*dynamodb.Client)), wire.Bind(new(routes.Registry), new(*dynamoregistry.Registry)), wire.Bind(new(controllers.NotificationRegistry), new(*dynamoregistry.Registry)))
+var DynamoSet = wire.NewSet(clients.NewDynamoDBClient, dynamoregistry.NewDynamoDBRegistry, wire.Bind(new(dynamoregistry.DynamoDBAPI), new(*dynamodb.Client)), wire.Bind(new(routes.Registry), new(*dynamoregistry.Registry)), wire.Bind(new(controllers.NotificationRegistry), new(*dynamoregistry.Registry)), wire.Bind(new(scheduler.ScheduledNotificationRegistry), new(*dynamoregistry.Registry)), wire.Bind(new(scheduler.RecurringScheduleRegistry), new(*dynamoregistry.Registry)), wire.Bind(new(outbox.OutboxRegistry), new(*dynamoregistry.Registry)))
 
-var PostgresSet = wire.NewSet(postgresresgistry.NewPostgresRegistry, wire.Bind(new(routes.Registry), new(*postgresresgistry.Registry)), wire.Bind(new(controllers.NotificationRegistry), new(*postgresresgistry.Registry)))
+var PostgresSet = wire.NewSet(postgresresgistry.NewPostgresRegistry, wire.Bind(new(routes.Registry), new(*postgresresgistry.Registry)), wire.Bind(new(controllers.NotificationRegistry), new(*postgresresgistry.Registry)), wire.Bind(new(scheduler.ScheduledNotificationRegistry), new(*postgresresgistry.Registry)), wire.Bind(new(scheduler.RecurringScheduleRegistry), new(*postgresresgistry.Registry)), wire.Bind(new(outbox.OutboxRegistry), new(*postgresresgistry.Registry)))
 
 var SQSPublisherSet = wire.NewSet(clients.NewSQSClient, publish.NewSQSPublisher, wire.Bind(new(publish.SQSAPI), new(*sqs.Client)), wire.Bind(new(publish.Publisher), new(*publish.SQS)))
 
@@ -646,10 +792,21 @@ var RedisCacheSet = wire.NewSet(cache.NewRedisCache, wire.Bind(new(cache.Cache),
 
 var RedisUserNotificationBrokerSet = wire.NewSet(broker.NewRedisBroker, wire.Bind(new(controllers.UserNotificationBroker), new(*broker.Redis)))
 
+var CognitoAudienceSet = wire.NewSet(clients.NewCognitoClient, audience.NewCognito, wire.Bind(new(controllers.AudienceResolver), new(*audience.Cognito)))
+
 var PrioritySet = wire.NewSet(
 	PriorityPublisherCfgSet, publish.NewPriorityPublisher, wire.Bind(new(controllers.NotificationPublisher), new(*publish.Priority)),
 )
 
+var SchedulerSet = wire.NewSet(wire.Struct(new(scheduler.SchedulerCfg), "*"), scheduler.NewScheduler)
+
+var RelaySet = wire.NewSet(wire.Struct(new(outbox.RelayCfg), "*"), outbox.NewRelay)
+
+var AppSet = wire.NewSet(
+	SchedulerSet,
+	RelaySet, wire.Struct(new(App), "*"),
+)
+
 var PostgresContainerSet = wire.NewSet(containers.NewPostgresContainer, wire.Bind(new(clients.PostgresConfigurator), new(*containers.Postgres)))
 
 var SQSPriorityContainerSet = wire.NewSet(containers2.NewSQSPriorityContainer, wire.Bind(new(clients.SQSConfigurator), new(*containers2.SQSPriority)), wire.Bind(new(publish.PriorityQueueConfigurator), new(*containers2.SQSPriority)))
@@ -676,20 +833,28 @@ var MockedNotificationRegistrySet = wire.NewSet(mocks.NewMockNotificationRegistr
 
 var MockedNotificationTemplateRegistrySet = wire.NewSet(mocks.NewMockNotificationTemplateRegistry, wire.Bind(new(controllers.NotificationTemplateRegistry), new(*mocks.MockNotificationTemplateRegistry)))
 
+var MockedTemplatePartialRegistrySet = wire.NewSet(mocks.NewMockTemplatePartialRegistry, wire.Bind(new(controllers.TemplatePartialRegistry), new(*mocks.MockTemplatePartialRegistry)))
+
+var MockedAudienceResolverSet = wire.NewSet(mocks.NewMockAudienceResolver, wire.Bind(new(controllers.AudienceResolver), new(*mocks.MockAudienceResolver)))
+
 var MockedUserNotificationBroker = wire.NewSet(mocks.NewMockUserNotificationBroker, wire.Bind(new(controllers.UserNotificationBroker), new(*mocks.MockUserNotificationBroker)))
 
+var MockedNotificationScheduleRegistrySet = wire.NewSet(mocks.NewMockNotificationScheduleRegistry, wire.Bind(new(controllers.NotificationScheduleRegistry), new(*mocks.MockNotificationScheduleRegistry)))
+
 var MockedRegistrySet = wire.NewSet(
 	MockedDistributionRegistrySet,
 	MockedUserRegistrySet,
 	MockedNotificationRegistrySet,
-	MockedNotificationTemplateRegistrySet, mocks.NewMockedRegistry, wire.Bind(new(routes.Registry), new(*mocks.MockedRegistry)),
+	MockedNotificationTemplateRegistrySet,
+	MockedTemplatePartialRegistrySet,
+	MockedNotificationScheduleRegistrySet, mocks.NewMockedRegistry, wire.Bind(new(routes.Registry), new(*mocks.MockedRegistry)),
 )
 
 var MockedMiddlewareSet = wire.NewSet(mocks.NewTestAuthMiddleware, mocks.NewTestCacheMiddleware, mocks.NewTestSecurityMiddleware, mocks.NewTestRateLimitMiddleware, wire.Value(middleware.Authorize))
 
 var TestVersionConfiguratorSet = wire.NewSet(config_test.NewTestVersionConfigurator, wire.Bind(new(routes.EngineConfigurator), new(config_test.TestEngineConfigurator)))
 
-var EnvConfigSet = wire.NewSet(config.NewEnvConfig, wire.Bind(new(clients.PostgresConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.DynamoConfigurator), new(*config.EnvConfig)), wire.Bind(new(publish.PriorityQueueConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.SQSConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.RabbitMQConfigurator), new(*config.EnvConfig)), wire.Bind(new(publish.RabbitMQPriorityConfigurator), new(*config.EnvConfig)), wire.Bind(new(publish.SQSPriorityConfigurator), new(*config.EnvConfig)), wire.Bind(new(cache.RedisConfigurator), new(*config.EnvConfig)), wire.Bind(new(broker.BrokerConfigurator), new(*config.EnvConfig)), wire.Bind(new(routes.EngineConfigurator), new(*config.EnvConfig)), wire.Bind(new(middleware.AuthConfigurator), new(*config.EnvConfig)), wire.Bind(new(middleware.CacheConfigurator), new(*config.EnvConfig)), wire.Bind(new(middleware.RateLimitConfigurator), new(*config.EnvConfig)), wire.Bind(new(middleware.SecurityConfigurator), new(*config.EnvConfig)))
+var EnvConfigSet = wire.NewSet(config.NewEnvConfig, wire.Bind(new(clients.PostgresConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.DynamoConfigurator), new(*config.EnvConfig)), wire.Bind(new(publish.PriorityQueueConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.SQSConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.RabbitMQConfigurator), new(*config.EnvConfig)), wire.Bind(new(publish.RabbitMQPriorityConfigurator), new(*config.EnvConfig)), wire.Bind(new(publish.SQSPriorityConfigurator), new(*config.EnvConfig)), wire.Bind(new(cache.RedisConfigurator), new(*config.EnvConfig)), wire.Bind(new(broker.BrokerConfigurator), new(*config.EnvConfig)), wire.Bind(new(routes.EngineConfigurator), new(*config.EnvConfig)), wire.Bind(new(middleware.AuthConfigurator), new(*config.EnvConfig)), wire.Bind(new(middleware.CacheConfigurator), new(*config.EnvConfig)), wire.Bind(new(middleware.RateLimitConfigurator), new(*config.EnvConfig)), wire.Bind(new(middleware.SecurityConfigurator), new(*config.EnvConfig)), wire.Bind(new(scheduler.SchedulerConfigurator), new(*config.EnvConfig)), wire.Bind(new(outbox.RelayConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.CognitoConfigurator), new(*config.EnvConfig)), wire.Bind(new(audience.CognitoConfigurator), new(*config.EnvConfig)))
 
 var MockedCacheSet = wire.NewSet(mocks.NewMockCache, wire.Bind(new(cache.Cache), new(*mocks.MockCache)))
 
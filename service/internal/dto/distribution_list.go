@@ -1,19 +1,143 @@
 package dto
 
+import sdto "github.com/notifique/shared/dto"
+
+// DistributionList is a new list. The Owner defaults to the user that
+// creates the list.
 type DistributionList struct {
-	Name       string   `json:"name" binding:"max=120,min=3,distributionlistname"`
-	Recipients []string `json:"recipients" binding:"max=256,unique,dive,min=1"`
+	Name        string        `json:"name" binding:"max=120,min=3,distributionlistname"`
+	Recipients  []string      `json:"recipients" binding:"excluded_with=Segment,max=256,unique,dive,min=1"`
+	Segment     *sdto.Segment `json:"segment,omitempty"`
+	Lists       []string      `json:"lists,omitempty" binding:"excluded_with=Segment,max=256,unique,dive,max=120,min=3,distributionlistname"`
+	Description string        `json:"description" binding:"max=256"`
+	Owner       string        `json:"owner" binding:"max=120"`
+	Labels      []string      `json:"labels,omitempty" binding:"omitempty,max=20,unique,dive,min=1,max=60"`
+}
+
+// DistributionListMetadata describes the list, updating it doesn't
+// change the recipients of the list.
+type DistributionListMetadata struct {
+	Description string   `json:"description" binding:"max=256"`
+	Owner       string   `json:"owner" binding:"required,max=120"`
+	Labels      []string `json:"labels" binding:"omitempty,max=20,unique,dive,min=1,max=60"`
+}
+
+// DistributionListRename is the new name of the list. The notifications
+// and schedules that reference the list are changed to the new name.
+type DistributionListRename struct {
+	Name string `json:"name" binding:"required,max=120,min=3,distributionlistname"`
 }
 
 type DistributionListSummary struct {
-	Name               string `json:"name"`
-	NumberOfRecipients int    `json:"numberOfRecipients"`
+	Name               string        `json:"name"`
+	NumberOfRecipients int           `json:"numberOfRecipients"`
+	Segment            *sdto.Segment `json:"segment,omitempty"`
+}
+
+// DistributionListDetails is the summary of the list with its metadata.
+// The lists created before the owners were stored don't have one.
+type DistributionListDetails struct {
+	DistributionListSummary
+	Description string   `json:"description"`
+	Owner       *string  `json:"owner"`
+	Labels      []string `json:"labels"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   *string  `json:"updatedAt"`
+}
+
+// DistributionListFilters filters the distribution lists. The Member
+// filter gets the lists the user is a recipient of, which doesn't include
+// the segments and the lists that only include the user through nested
+// lists.
+type DistributionListFilters struct {
+	sdto.PageFilter
+	Member *string `form:"member" binding:"omitempty,min=1"`
+}
+
+// DistributionListMemberUriParams has the recipient removed from every
+// list.
+type DistributionListMemberUriParams struct {
+	UserId string `uri:"userId" binding:"required,min=1"`
+}
+
+// DistributionListMembership has the lists a recipient was removed from.
+type DistributionListMembership struct {
+	Member            string   `json:"member"`
+	DistributionLists []string `json:"distributionLists"`
 }
 
 type DistributionListRecipients struct {
 	Recipients []string `json:"recipients" binding:"unique,max=256,min=1,dive,min=1"`
 }
 
+// DistributionListLists are the lists included by a distribution list,
+// whose recipients are also recipients of the list.
+type DistributionListLists struct {
+	Lists []string `json:"lists" binding:"unique,max=256,min=1,dive,max=120,min=3,distributionlistname"`
+}
+
+// DistributionListRecipientsFilter filters the recipients of a list.
+// The flattened recipients include the recipients of the nested lists.
+type DistributionListRecipientsFilter struct {
+	sdto.PageFilter
+	Flatten bool `form:"flatten"`
+}
+
 type DistributionListUriParams struct {
 	Name string `uri:"name" binding:"max=120,min=3"`
 }
+
+// DistributionListSegment is the segment of a distribution list, which
+// is nil for the lists with a fixed set of recipients.
+type DistributionListSegment struct {
+	Segment *sdto.Segment `json:"segment"`
+}
+
+type SegmentPreviewReq struct {
+	Segment    sdto.Segment `json:"segment"`
+	SampleSize *int         `json:"sampleSize" binding:"omitempty,min=1,max=100"`
+}
+
+type RecipientsFormat string
+type RecipientsImportMode string
+
+const (
+	RecipientsCSV    RecipientsFormat = "CSV"
+	RecipientsNDJSON RecipientsFormat = "NDJSON"
+)
+
+const (
+	RecipientsImportAdd     RecipientsImportMode = "ADD"
+	RecipientsImportReplace RecipientsImportMode = "REPLACE"
+	RecipientsImportRemove  RecipientsImportMode = "REMOVE"
+)
+
+type RecipientsImportParams struct {
+	Format RecipientsFormat     `form:"format" binding:"required,oneof=CSV NDJSON"`
+	Mode   RecipientsImportMode `form:"mode" binding:"required,oneof=ADD REPLACE REMOVE"`
+}
+
+type RecipientsExportParams struct {
+	Format RecipientsFormat `form:"format" binding:"required,oneof=CSV NDJSON"`
+}
+
+// RecipientsImportRow is a row of the recipients files. The rows of the
+// CSV files have the recipient in the first column.
+type RecipientsImportRow struct {
+	Recipient string `json:"recipient"`
+}
+
+type RecipientsImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// RecipientsImportResult is the summary of the list after the import,
+// with the number of rows read and the errors of the rows that were
+// skipped. Only the first errors are reported.
+type RecipientsImportResult struct {
+	DistributionListSummary
+	Rows      int                     `json:"rows"`
+	NumErrors int                     `json:"numErrors"`
+	Errors    []RecipientsImportError `json:"errors"`
+}
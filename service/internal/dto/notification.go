@@ -5,16 +5,40 @@ import (
 )
 
 type NotificationContentsType string
+type SortOrder string
 
 const (
 	Template NotificationContentsType = "TEMPLATE"
 	Raw      NotificationContentsType = "RAW"
+
+	Ascending  SortOrder = "ASC"
+	Descending SortOrder = "DESC"
 )
 
 type NotificationUriParams struct {
 	NotificationId string `uri:"id" binding:"required,uuid"`
 }
 
+type NotificationFilters struct {
+	sdto.PageFilter
+	Topic            *string                    `form:"topic" binding:"omitempty,max=120"`
+	Status           *sdto.NotificationStatus   `form:"status" binding:"omitempty,oneof=CREATED QUEUED FAILED SENDING SENT CANCELED SCHEDULED"`
+	Priority         *sdto.NotificationPriority `form:"priority" binding:"omitempty,oneof=HIGH MEDIUM LOW"`
+	CreatedBy        *string                    `form:"createdBy" binding:"omitempty"`
+	ContentsType     *NotificationContentsType  `form:"contentsType" binding:"omitempty,oneof=RAW TEMPLATE"`
+	TemplateId       *string                    `form:"templateId" binding:"omitempty,uuid"`
+	DistributionList *string                    `form:"distributionList" binding:"omitempty,max=120"`
+	CreatedFrom      *string                    `form:"createdFrom" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedTo        *string                    `form:"createdTo" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00,notbefore=CreatedFrom"`
+	SortOrder        *SortOrder                 `form:"sortOrder" binding:"omitempty,oneof=ASC DESC"`
+}
+
+// IsAscending tells if the notifications should be sorted from the
+// oldest to the newest. They are sorted from the newest by default.
+func (f NotificationFilters) IsAscending() bool {
+	return f.SortOrder != nil && *f.SortOrder == Ascending
+}
+
 type NotificationSummary struct {
 	Id           string                    `json:"id"`
 	Topic        string                    `json:"topic"`
@@ -32,3 +56,18 @@ type NotificationResp struct {
 	CreatedAt string                  `json:"createdAt"`
 	CreatedBy string                  `json:"createdBy"`
 }
+
+type NotificationStatusLogResp struct {
+	sdto.NotificationStatusLog
+	StatusDate string `json:"statusDate"`
+}
+
+type NotificationResendReq struct {
+	Channels []sdto.NotificationChannel `json:"channels" binding:"unique,dive,oneof=e-mail sms in-app"`
+	UserIds  []string                   `json:"userIds" binding:"unique,max=256,dive,min=1"`
+}
+
+type NotificationResendResp struct {
+	Recipients []string                   `json:"recipients"`
+	Channels   []sdto.NotificationChannel `json:"channels"`
+}
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	sdto "github.com/notifique/shared/dto"
+)
+
+type NotificationScheduleReq struct {
+	Name           string               `json:"name" binding:"required,max=120"`
+	CronExpression string               `json:"cronExpression" binding:"required,cron"`
+	Timezone       string               `json:"timezone" binding:"omitempty,timezone"`
+	Notification   sdto.NotificationReq `json:"notification"`
+}
+
+type NotificationScheduleResp struct {
+	NotificationScheduleReq
+	Id        string  `json:"id"`
+	Paused    bool    `json:"paused"`
+	NextRunAt *string `json:"nextRunAt"`
+	LastRunAt *string `json:"lastRunAt"`
+	CreatedAt string  `json:"createdAt"`
+	CreatedBy string  `json:"createdBy"`
+	UpdatedAt *string `json:"updatedAt"`
+	UpdatedBy *string `json:"updatedBy"`
+}
+
+type NotificationScheduleSummary struct {
+	Id             string  `json:"id"`
+	Name           string  `json:"name"`
+	CronExpression string  `json:"cronExpression"`
+	Timezone       string  `json:"timezone"`
+	Paused         bool    `json:"paused"`
+	NextRunAt      *string `json:"nextRunAt"`
+	LastRunAt      *string `json:"lastRunAt"`
+}
+
+type NotificationScheduleUriParams struct {
+	Id string `uri:"id" binding:"required,uuid"`
+}
+
+type NotificationSchedulePreviewFilters struct {
+	Count *int `form:"count" binding:"omitempty,min=1,max=50"`
+}
+
+type NotificationSchedulePreviewResp struct {
+	NextRuns []string `json:"nextRuns"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import (
+	sdto "github.com/notifique/shared/dto"
+)
+
+// OutboxEntry is a notification waiting to be published by the outbox
+// relay. It is written in the same transaction as the notification.
+type OutboxEntry struct {
+	Payload     sdto.NotificationMsgPayload
+	Attempts    int
+	AvailableAt string
+	LastError   *string
+}
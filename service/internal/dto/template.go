@@ -11,34 +11,107 @@ const (
 	Date     TemplateVariableType = "DATE"
 	DateTime TemplateVariableType = "DATETIME"
 	Number   TemplateVariableType = "NUMBER"
+	Boolean  TemplateVariableType = "BOOLEAN"
+	Integer  TemplateVariableType = "INTEGER"
+	Enum     TemplateVariableType = "ENUM"
+	URL      TemplateVariableType = "URL"
+	Email    TemplateVariableType = "EMAIL"
+	Currency TemplateVariableType = "CURRENCY"
 )
 
+// NotificationTemplateReq is a version of a template. The Partials are
+// the names of the layout and partials used by the template, which are
+// resolved when the template is validated. The title and contents are
+// in the DefaultLocale, the Localizations translate them to other
+// locales. The Format of the contents replaces IsHtml, which is only
+// used when the Format isn't set. The Category and Tags organize the
+// templates, which can be filtered by them.
 type NotificationTemplateReq struct {
-	Name             string                  `json:"name" binding:"required,max=120"`
-	IsHtml           bool                    `json:"isHtml" binding:"required"`
-	TitleTemplate    string                  `json:"titleTemplate" binding:"required,max=120"`
-	ContentsTemplate string                  `json:"contentsTemplate" binding:"required,max=4096"`
-	Description      string                  `json:"description" binding:"required,max=256"`
-	Variables        []sdto.TemplateVariable `json:"variables" binding:"unique_var_name,dive"`
+	Name             string                      `json:"name" binding:"required,max=120"`
+	IsHtml           bool                        `json:"isHtml"`
+	Format           sdto.TemplateFormat         `json:"format,omitempty" binding:"omitempty,oneof=TEXT HTML MARKDOWN"`
+	TitleTemplate    string                      `json:"titleTemplate" binding:"required,max=120"`
+	ContentsTemplate string                      `json:"contentsTemplate" binding:"required,max=4096"`
+	Description      string                      `json:"description" binding:"required,max=256"`
+	Category         *string                     `json:"category,omitempty" binding:"omitempty,min=1,max=60"`
+	Tags             []string                    `json:"tags,omitempty" binding:"omitempty,max=20,unique,dive,min=1,max=60"`
+	Layout           *string                     `json:"layout,omitempty" binding:"omitempty,max=120"`
+	DefaultLocale    *string                     `json:"defaultLocale,omitempty" binding:"omitempty,max=35,bcp47_language_tag"`
+	Localizations    []sdto.TemplateLocalization `json:"localizations,omitempty" binding:"omitempty,max=50,unique=Locale,dive"`
+	Variables        []sdto.TemplateVariable     `json:"variables" binding:"unique_var_name,dive"`
+	Partials         []string                    `json:"-"`
 }
 
+// NotificationTemplateCreatedResp is the created template. The
+// Warnings are the issues found by the lint of the template, such as
+// the variables that aren't used, which don't prevent its creation.
+// The Stripped are the HTML removed from the template when it was
+// sanitized.
 type NotificationTemplateCreatedResp struct {
-	Id        string `json:"id"`
-	Name      string `json:"name"`
-	CreatedAt string `json:"createdAt"`
+	Id        string                   `json:"id"`
+	Name      string                   `json:"name"`
+	CreatedAt string                   `json:"createdAt"`
+	Warnings  []sdto.TemplateLintIssue `json:"warnings,omitempty"`
+	Stripped  []sdto.StrippedContent   `json:"stripped,omitempty"`
+}
+
+// NotificationTemplateUpdatedResp is the version stored by an update or
+// a rollback, with the Warnings and Stripped of the template as in
+// NotificationTemplateCreatedResp.
+type NotificationTemplateUpdatedResp struct {
+	sdto.NotificationTemplateDetails
+	Warnings []sdto.TemplateLintIssue `json:"warnings,omitempty"`
+	Stripped []sdto.StrippedContent   `json:"stripped,omitempty"`
 }
 
+// NotificationTemplateFilters filter the templates. The templates have
+// to have every one of the Tags, and the Search is matched against
+// their name and description, ignoring the case.
 type NotificationTemplateFilters struct {
 	sdto.PageFilter
-	TemplateName *string `form:"templateName" binding:"omitempty"`
+	TemplateName *string              `form:"templateName" binding:"omitempty"`
+	Category     *string              `form:"category" binding:"omitempty,max=60"`
+	Tags         []string             `form:"tags" binding:"omitempty,max=20,dive,min=1,max=60"`
+	CreatedBy    *string              `form:"createdBy" binding:"omitempty"`
+	Format       *sdto.TemplateFormat `form:"format" binding:"omitempty,oneof=TEXT HTML MARKDOWN"`
+	CreatedFrom  *string              `form:"createdFrom" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedTo    *string              `form:"createdTo" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	UpdatedFrom  *string              `form:"updatedFrom" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	UpdatedTo    *string              `form:"updatedTo" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Search       *string              `form:"search" binding:"omitempty,min=1,max=120"`
 }
 
 type NotificationTemplateInfoResp struct {
-	Id          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Id          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Format      sdto.TemplateFormat `json:"format"`
+	Category    *string             `json:"category,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	CreatedBy   string              `json:"createdBy"`
+	CreatedAt   string              `json:"createdAt"`
+	UpdatedAt   *string             `json:"updatedAt,omitempty"`
 }
 
 type NotificationTemplateUriParams struct {
 	Id string `uri:"id" binding:"uuid"`
 }
+
+// NotificationTemplateRenderReq are the variables of the preview, which
+// is rendered in the localization that matches the Locale, if any.
+type NotificationTemplateRenderReq struct {
+	Locale    *string                         `json:"locale,omitempty" binding:"omitempty,max=35,bcp47_language_tag"`
+	Variables []sdto.TemplateVariableContents `json:"variables" binding:"unique,dive"`
+}
+
+type NotificationTemplateVersionUriParams struct {
+	Id      string `uri:"id" binding:"uuid"`
+	Version int    `uri:"version" binding:"required,min=1"`
+}
+
+type NotificationTemplateVersionResp struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	CreatedBy string `json:"createdBy"`
+	CreatedAt string `json:"createdAt"`
+}
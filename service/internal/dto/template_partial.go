@@ -0,0 +1,47 @@
+package dto
+
+import (
+	sdto "github.com/notifique/shared/dto"
+)
+
+type TemplatePartialKind string
+
+const (
+	Layout  TemplatePartialKind = "LAYOUT"
+	Partial TemplatePartialKind = "PARTIAL"
+)
+
+type TemplatePartialReq struct {
+	Name        string `json:"name" binding:"required,max=120,partialname"`
+	Kind        string `json:"kind" binding:"required,oneof=LAYOUT PARTIAL"`
+	Description string `json:"description" binding:"max=256"`
+	Contents    string `json:"contents" binding:"required,max=4096"`
+}
+
+// TemplatePartialUpdateReq is a new version of the partial. The name
+// and kind can't be changed, as the templates reference them.
+type TemplatePartialUpdateReq struct {
+	Description string `json:"description" binding:"max=256"`
+	Contents    string `json:"contents" binding:"required,max=4096"`
+}
+
+type TemplatePartialInfoResp struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+}
+
+type TemplatePartialFilters struct {
+	sdto.PageFilter
+	Kind *string `form:"kind" binding:"omitempty,oneof=LAYOUT PARTIAL"`
+}
+
+type TemplatePartialUriParams struct {
+	Name string `uri:"name" binding:"required,max=120"`
+}
+
+type TemplatePartialVersionUriParams struct {
+	Name    string `uri:"name" binding:"required,max=120"`
+	Version int    `uri:"version" binding:"required,min=1"`
+}
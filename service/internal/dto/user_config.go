@@ -5,8 +5,16 @@ type ChannelConfig struct {
 	SnoozeUntil *string `json:"snoozeUntil" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00,future"`
 }
 
+// UserConfig are the preferences of the user. The Locale, a BCP 47
+// language tag, selects the localization of the templates the user
+// receives.
 type UserConfig struct {
 	EmailConfig ChannelConfig `json:"emailConfig"`
 	SMSConfig   ChannelConfig `json:"smsConfig"`
 	InAppConfig ChannelConfig `json:"inappConfig"`
+	Locale      *string       `json:"locale" binding:"omitempty,max=35,bcp47_language_tag"`
+}
+
+type UserUriParams struct {
+	UserId string `uri:"id" binding:"required,max=120"`
 }
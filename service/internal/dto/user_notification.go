@@ -4,20 +4,32 @@ import (
 	sdto "github.com/notifique/shared/dto"
 )
 
+// UserNotificationFormat is the format of the contents the client can
+// display. The HTML contents are only sent to the clients that ask for
+// them, the plain text contents are always sent.
+type UserNotificationFormat struct {
+	Format sdto.TemplateFormat `form:"format" binding:"omitempty,oneof=TEXT HTML"`
+}
+
 type UserNotificationFilters struct {
 	sdto.PageFilter
+	UserNotificationFormat
 	UserId string
 	Topics []string `form:"topics" binding:"unique"`
 }
 
+// UserNotification is an in-app notification. The HtmlContents are the
+// sanitized HTML version of the Contents, when the notification has
+// one.
 type UserNotification struct {
-	Id        string  `json:"id"`
-	Title     string  `json:"title"`
-	Contents  string  `json:"contents"`
-	CreatedAt string  `json:"createdAt"`
-	Image     *string `json:"image"`
-	ReadAt    *string `json:"readAt,omitempty"`
-	Topic     string  `json:"topic"`
+	Id           string  `json:"id"`
+	Title        string  `json:"title"`
+	Contents     string  `json:"contents"`
+	HtmlContents *string `json:"htmlContents,omitempty"`
+	CreatedAt    string  `json:"createdAt"`
+	Image        *string `json:"image"`
+	ReadAt       *string `json:"readAt,omitempty"`
+	Topic        string  `json:"topic"`
 }
 
 type UserNotificationUriParam struct {
@@ -27,3 +27,77 @@ func (e DistributionListAlreadyExists) Error() string {
 func (e InvalidNotificationStatus) Error() string {
 	return fmt.Sprintf("notification %v has status %v", e.Id, e.Status)
 }
+
+type TemplateUpdateConflict struct {
+	Id string
+}
+
+func (e TemplateUpdateConflict) Error() string {
+	return fmt.Sprintf("template %v has been updated concurrently", e.Id)
+}
+
+type TemplateArchived struct {
+	Id string
+}
+
+func (e TemplateArchived) Error() string {
+	return fmt.Sprintf("template %v is archived", e.Id)
+}
+
+type TemplateNotArchived struct {
+	Id string
+}
+
+func (e TemplateNotArchived) Error() string {
+	return fmt.Sprintf("template %v must be archived before it's purged", e.Id)
+}
+
+type TemplateInUse struct {
+	Id string
+}
+
+func (e TemplateInUse) Error() string {
+	return fmt.Sprintf("template %v is referenced by notifications or schedules", e.Id)
+}
+
+type TemplatePartialAlreadyExists struct {
+	Name string
+}
+
+func (e TemplatePartialAlreadyExists) Error() string {
+	return fmt.Sprintf("template partial %v already exists", e.Name)
+}
+
+type TemplatePartialInUse struct {
+	Name string
+}
+
+func (e TemplatePartialInUse) Error() string {
+	return fmt.Sprintf("template partial %v is used by templates", e.Name)
+}
+
+type DistributionListIsSegment struct {
+	Name string
+}
+
+func (e DistributionListIsSegment) Error() string {
+	return fmt.Sprintf("distribution list %v is a segment, its recipients can't be changed", e.Name)
+}
+
+type DistributionListCycle struct {
+	Name string
+	List string
+}
+
+func (e DistributionListCycle) Error() string {
+	return fmt.Sprintf("distribution list %v can't include %v, it would create a cycle", e.Name, e.List)
+}
+
+type DistributionListRenaming struct {
+	Name    string
+	NewName string
+}
+
+func (e DistributionListRenaming) Error() string {
+	return fmt.Sprintf("distribution list %v is being renamed to %v", e.Name, e.NewName)
+}
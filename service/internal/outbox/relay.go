@@ -0,0 +1,207 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/controllers"
+	"github.com/notifique/service/internal/dto"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const (
+	// MaxPublishAttempts is the number of times the relay tries to
+	// publish a notification before marking it as failed.
+	MaxPublishAttempts = 10
+	// PublishLease is how long a claimed entry is hidden from other
+	// relays. If the relay dies while publishing, the entry becomes
+	// available again once the lease expires.
+	PublishLease  = time.Minute
+	maxRetryDelay = time.Hour
+)
+
+type OutboxRegistry interface {
+	GetOutboxEntries(ctx context.Context, availableBefore time.Time, maxResults int) ([]dto.OutboxEntry, error)
+	// ClaimOutboxEntry increments the attempts of an entry and hides it
+	// until leaseUntil. It returns false if the attempts of the entry
+	// changed, i.e., the entry was claimed by another relay.
+	ClaimOutboxEntry(ctx context.Context, notificationId string, attempts int, leaseUntil time.Time) (bool, error)
+	RetryOutboxEntry(ctx context.Context, notificationId string, retryAt time.Time, errorMsg string) error
+	DeleteOutboxEntry(ctx context.Context, notificationId string) error
+	GetNotificationStatus(ctx context.Context, notificationId string) (sdto.NotificationStatus, error)
+	UpdateNotificationStatus(ctx context.Context, statusLog sdto.NotificationStatusLog) error
+}
+
+type RelayConfigurator interface {
+	GetOutboxRelayInterval() (time.Duration, error)
+}
+
+type RelayCfg struct {
+	Registry     OutboxRegistry
+	Publisher    controllers.NotificationPublisher
+	Configurator RelayConfigurator
+}
+
+// Relay drains the notification outbox into the notification publisher.
+// Entries are only removed from the outbox once they are published, so
+// notifications survive restarts and broker outages. Publishing is
+// at least once, the message id is derived from the notification id so
+// the brokers can discard duplicates.
+type Relay struct {
+	registry  OutboxRegistry
+	publisher controllers.NotificationPublisher
+	interval  time.Duration
+}
+
+func (r *Relay) retryDelay(attempts int) time.Duration {
+
+	delay := r.interval
+
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+func (r *Relay) fail(ctx context.Context, notificationId string, publishErr error) error {
+
+	errMsg := publishErr.Error()
+
+	statusLog := sdto.NotificationStatusLog{
+		NotificationId: notificationId,
+		Status:         sdto.Failed,
+		ErrorMsg:       &errMsg,
+	}
+
+	if err := r.registry.UpdateNotificationStatus(ctx, statusLog); err != nil {
+		return fmt.Errorf("failed to update status of notification %s - %w", notificationId, err)
+	}
+
+	if err := r.registry.DeleteOutboxEntry(ctx, notificationId); err != nil {
+		return fmt.Errorf("failed to delete outbox entry %s - %w", notificationId, err)
+	}
+
+	return fmt.Errorf("failed to publish notification %s after %d attempts - %w",
+		notificationId, MaxPublishAttempts, publishErr)
+}
+
+func (r *Relay) relay(ctx context.Context, entry dto.OutboxEntry) error {
+
+	notificationId := entry.Payload.Id
+	leaseUntil := time.Now().Add(PublishLease)
+
+	claimed, err := r.registry.ClaimOutboxEntry(ctx, notificationId, entry.Attempts, leaseUntil)
+
+	if err != nil {
+		return fmt.Errorf("failed to claim outbox entry %s - %w", notificationId, err)
+	}
+
+	if !claimed {
+		return nil
+	}
+
+	status, err := r.registry.GetNotificationStatus(ctx, notificationId)
+
+	if err != nil && !errors.As(err, &internal.EntityNotFound{}) {
+		return fmt.Errorf("failed to get status of notification %s - %w", notificationId, err)
+	}
+
+	// The notification was deleted or canceled while waiting to be
+	// published
+	if err != nil || status != sdto.Created {
+		if err := r.registry.DeleteOutboxEntry(ctx, notificationId); err != nil {
+			return fmt.Errorf("failed to delete outbox entry %s - %w", notificationId, err)
+		}
+
+		return nil
+	}
+
+	publishErr := r.publisher.Publish(ctx, entry.Payload)
+
+	if publishErr == nil {
+		if err := r.registry.DeleteOutboxEntry(ctx, notificationId); err != nil {
+			return fmt.Errorf("failed to delete outbox entry %s - %w", notificationId, err)
+		}
+
+		return nil
+	}
+
+	attempts := entry.Attempts + 1
+
+	if attempts >= MaxPublishAttempts {
+		return r.fail(ctx, notificationId, publishErr)
+	}
+
+	retryAt := time.Now().Add(r.retryDelay(attempts))
+	err = r.registry.RetryOutboxEntry(ctx, notificationId, retryAt, publishErr.Error())
+
+	if err != nil {
+		return fmt.Errorf("failed to reschedule outbox entry %s - %w", notificationId, err)
+	}
+
+	return fmt.Errorf("failed to publish notification %s - %w", notificationId, publishErr)
+}
+
+// RelayOutbox publishes up to a page of outbox entries that are
+// available at the time of the call.
+func (r *Relay) RelayOutbox(ctx context.Context) error {
+
+	entries, err := r.registry.GetOutboxEntries(ctx, time.Now(), internal.PageSize)
+
+	if err != nil {
+		return fmt.Errorf("failed to get outbox entries - %w", err)
+	}
+
+	errorsArr := []error{}
+
+	for _, entry := range entries {
+		if err := r.relay(ctx, entry); err != nil {
+			errorsArr = append(errorsArr, err)
+		}
+	}
+
+	return errors.Join(errorsArr...)
+}
+
+// Start runs the relay until the context is canceled.
+func (r *Relay) Start(ctx context.Context) {
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.RelayOutbox(ctx); err != nil {
+				slog.Error("failed to relay the notification outbox",
+					"error", err.Error())
+			}
+		}
+	}
+}
+
+func NewRelay(cfg RelayCfg) (*Relay, error) {
+
+	interval, err := cfg.Configurator.GetOutboxRelayInterval()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("outbox relay interval should be positive")
+	}
+
+	return &Relay{
+		registry:  cfg.Registry,
+		publisher: cfg.Publisher,
+		interval:  interval,
+	}, nil
+}
@@ -3,8 +3,8 @@ package publish
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"log/slog"
 
 	c "github.com/notifique/service/internal/controllers"
 	"github.com/notifique/shared/cache"
@@ -70,42 +70,39 @@ func (p *Priority) Publish(ctx context.Context, n dto.NotificationMsgPayload) er
 		return fmt.Errorf("failed to marshall message body - %w", err)
 	}
 
-	errorsArr := []error{}
-
 	message := Message{
 		Id:       n.Hash,
 		Payload:  notificationMessage,
 		Priority: n.Priority,
 	}
 
-	publishErr := p.publisher.Publish(ctx, *queueUri, message)
-
-	status := dto.Queued
-
-	var errorMsg *string
-
-	if publishErr != nil {
-		errorsArr = append(errorsArr, publishErr)
-		status = dto.Failed
-		errStr := publishErr.Error()
-		errorMsg = &errStr
+	// The notification is only marked as failed by the caller, which
+	// might still retry the publishing.
+	if err := p.publisher.Publish(ctx, *queueUri, message); err != nil {
+		return err
 	}
 
 	statusLog := dto.NotificationStatusLog{
 		NotificationId: n.Id,
-		Status:         status,
-		ErrorMsg:       errorMsg,
+		Status:         dto.Queued,
 	}
 
-	if cacheErr := c.UpdateNotificationStatus(ctx, p.cache, statusLog); cacheErr != nil {
-		errorsArr = append(errorsArr, fmt.Errorf("failed to update cache - %w", cacheErr))
+	// The message has been published at this point, so failing to
+	// update the status is not reported as a publishing error, as it
+	// would cause the message to be published again.
+	if err := c.UpdateNotificationStatus(ctx, p.cache, statusLog); err != nil {
+		slog.Error("failed to update cache",
+			"error", err.Error(),
+			"notificationId", n.Id)
 	}
 
-	if registryErr := p.registry.UpdateNotificationStatus(ctx, statusLog); registryErr != nil {
-		errorsArr = append(errorsArr, fmt.Errorf("failed to update notification status - %w", registryErr))
+	if err := p.registry.UpdateNotificationStatus(ctx, statusLog); err != nil {
+		slog.Error("failed to update notification status",
+			"error", err.Error(),
+			"notificationId", n.Id)
 	}
 
-	return errors.Join(errorsArr...)
+	return nil
 }
 
 func NewPriorityPublisher(cfg PriorityPublisherCfg) *Priority {
@@ -0,0 +1,198 @@
+package recipients
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+)
+
+// Batches are the recipients of an import, read in batches. After the
+// last batch Next returns io.EOF.
+type Batches interface {
+	Next() ([]string, error)
+}
+
+type rowReader func() (string, error)
+
+// errSkipRow skips the rows without recipients, e.g. the headers and
+// the blank lines.
+var errSkipRow = errors.New("skip row")
+
+// rowError is an error of a single row, the import skips the row and
+// continues with the next one.
+type rowError struct {
+	err error
+}
+
+func (e rowError) Error() string {
+	return e.err.Error()
+}
+
+// Reader reads the recipients of CSV and NDJSON files without loading
+// the files in memory. The rows with errors are skipped and reported by
+// their number, which counts the header of the CSV files.
+type Reader struct {
+	readRow   rowReader
+	batchSize int
+	rows      int
+	numErrors int
+	errors    []dto.RecipientsImportError
+}
+
+func csvRowReader(r io.Reader) rowReader {
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header := true
+
+	return func() (string, error) {
+		record, err := reader.Read()
+
+		if err != nil {
+			var parseErr *csv.ParseError
+
+			if errors.As(err, &parseErr) {
+				return "", rowError{err: parseErr.Err}
+			}
+
+			return "", err
+		}
+
+		recipient := strings.TrimSpace(record[0])
+
+		// The header is optional
+		if header {
+			header = false
+
+			if strings.EqualFold(recipient, "recipient") {
+				return "", errSkipRow
+			}
+		}
+
+		return recipient, nil
+	}
+}
+
+func ndjsonRowReader(r io.Reader) rowReader {
+
+	scanner := bufio.NewScanner(r)
+
+	return func() (string, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+
+			return "", io.EOF
+		}
+
+		line := scanner.Bytes()
+
+		if len(strings.TrimSpace(string(line))) == 0 {
+			return "", errSkipRow
+		}
+
+		row := dto.RecipientsImportRow{}
+
+		if err := json.Unmarshal(line, &row); err != nil {
+			return "", rowError{err: fmt.Errorf("invalid json - %w", err)}
+		}
+
+		return strings.TrimSpace(row.Recipient), nil
+	}
+}
+
+func (r *Reader) addError(err error) {
+
+	r.numErrors++
+
+	if len(r.errors) < internal.MaxRecipientsImportErrors {
+		r.errors = append(r.errors, dto.RecipientsImportError{
+			Row:   r.rows,
+			Error: err.Error(),
+		})
+	}
+}
+
+// Next reads the next batch of recipients, the batches can be shorter
+// than the batch size when rows are skipped.
+func (r *Reader) Next() ([]string, error) {
+
+	batch := make([]string, 0, r.batchSize)
+
+	for len(batch) < r.batchSize {
+		recipient, err := r.readRow()
+
+		if err == io.EOF {
+			if len(batch) == 0 {
+				return nil, io.EOF
+			}
+
+			break
+		}
+
+		r.rows++
+
+		if err == errSkipRow {
+			continue
+		}
+
+		if errors.As(err, &rowError{}) {
+			r.addError(err)
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row %d - %w", r.rows, err)
+		}
+
+		if recipient == "" {
+			r.addError(errors.New("recipient is empty"))
+			continue
+		}
+
+		batch = append(batch, recipient)
+	}
+
+	return batch, nil
+}
+
+// Result makes the result of the import with the summary of the list.
+func (r *Reader) Result(summary dto.DistributionListSummary) dto.RecipientsImportResult {
+
+	importErrors := r.errors
+
+	if importErrors == nil {
+		importErrors = []dto.RecipientsImportError{}
+	}
+
+	return dto.RecipientsImportResult{
+		DistributionListSummary: summary,
+		Rows:                    r.rows,
+		NumErrors:               r.numErrors,
+		Errors:                  importErrors,
+	}
+}
+
+func NewReader(r io.Reader, format dto.RecipientsFormat) *Reader {
+
+	readRow := csvRowReader(r)
+
+	if format == dto.RecipientsNDJSON {
+		readRow = ndjsonRowReader(r)
+	}
+
+	return &Reader{
+		readRow:   readRow,
+		batchSize: internal.RecipientsBatchSize,
+	}
+}
@@ -0,0 +1,70 @@
+package recipients
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/notifique/service/internal/dto"
+)
+
+// Writer writes the recipients of an export as CSV or NDJSON. The CSV
+// files have a header, so they can be imported back.
+type Writer struct {
+	format dto.RecipientsFormat
+	csv    *csv.Writer
+	json   *json.Encoder
+	header bool
+}
+
+func (w *Writer) ContentType() string {
+
+	if w.format == dto.RecipientsNDJSON {
+		return "application/x-ndjson"
+	}
+
+	return "text/csv"
+}
+
+// Write writes a batch of recipients, flushing them to the underlying
+// writer.
+func (w *Writer) Write(recipients []string) error {
+
+	if w.format == dto.RecipientsNDJSON {
+		for _, r := range recipients {
+			err := w.json.Encode(dto.RecipientsImportRow{Recipient: r})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if !w.header {
+		w.header = true
+
+		if err := w.csv.Write([]string{"recipient"}); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range recipients {
+		if err := w.csv.Write([]string{r}); err != nil {
+			return err
+		}
+	}
+
+	w.csv.Flush()
+
+	return w.csv.Error()
+}
+
+func NewWriter(w io.Writer, format dto.RecipientsFormat) *Writer {
+	return &Writer{
+		format: format,
+		csv:    csv.NewWriter(w),
+		json:   json.NewEncoder(w),
+	}
+}
@@ -2,16 +2,22 @@ package dynamoregistry
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
 	"github.com/notifique/service/internal"
 	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/recipients"
 	"github.com/notifique/service/internal/registry"
 	sdto "github.com/notifique/shared/dto"
 )
@@ -22,16 +28,45 @@ const (
 	DistListRecipientHashKey = "listName"
 	DistListRecipientSortKey = "userId"
 	DistListSummaryHashKey   = "name"
+	DistListListsTable       = "DistributionListLists"
+	DistListListsHashKey     = "listName"
+	DistListListsSortKey     = "nestedList"
+	DistListListsParentIdx   = "ParentListsIdx"
+	DistListRecipientListIdx = "RecipientListsIdx"
 )
 
+// DistListRecipient is a recipient of a list, the lists of a recipient
+// are queried with the recipient lists index.
 type DistListRecipient struct {
-	DistListName string `dynamodbav:"listName"`
-	UserId       string `dynamodbav:"userId"`
+	DistListName string `dynamodbav:"listName" json:"listName"`
+	UserId       string `dynamodbav:"userId" json:"userId"`
+	// ImportId is the import that replaced the recipients of the list
+	// with the recipient, the recipients of other imports are deleted
+	// once the import is written.
+	ImportId *string `dynamodbav:"importId,omitempty" json:"-"`
 }
 
 type DistListSummary struct {
-	Name          string `dynamodbav:"name"`
-	NumRecipients int    `dynamodbav:"numOfRecipients"`
+	Name          string        `dynamodbav:"name"`
+	NumRecipients int           `dynamodbav:"numOfRecipients"`
+	Segment       *sdto.Segment `dynamodbav:"segment,omitempty"`
+	Description   string        `dynamodbav:"description"`
+	Owner         *string       `dynamodbav:"owner,omitempty"`
+	Labels        []string      `dynamodbav:"labels"`
+	CreatedAt     string        `dynamodbav:"createdAt"`
+	UpdatedAt     *string       `dynamodbav:"updatedAt,omitempty"`
+	// The rename markers link the list being renamed and its copy with
+	// the new name until the rename finishes, so a rename that fails
+	// midway can be retried.
+	RenamingTo  *string `dynamodbav:"renamingTo,omitempty"`
+	RenamedFrom *string `dynamodbav:"renamedFrom,omitempty"`
+}
+
+// DistListList is a list included by another list, the parents of the
+// lists are queried with the parent lists index.
+type DistListList struct {
+	DistListName string `dynamodbav:"listName"`
+	NestedList   string `dynamodbav:"nestedList"`
 }
 
 type DistListSummaryKey struct {
@@ -59,6 +94,27 @@ func (dl *DistListRecipient) GetKey() (DynamoKey, error) {
 	return key, nil
 }
 
+func (dl *DistListList) GetKey() (DynamoKey, error) {
+	key := make(map[string]types.AttributeValue)
+
+	name, err := attributevalue.Marshal(dl.DistListName)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to marshall dl name - %w", err)
+	}
+
+	nestedList, err := attributevalue.Marshal(dl.NestedList)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to marshall nested dl name - %w", err)
+	}
+
+	key[DistListListsHashKey] = name
+	key[DistListListsSortKey] = nestedList
+
+	return key, nil
+}
+
 func getSummaryKey(listName string) (DynamoKey, error) {
 	key := make(map[string]types.AttributeValue)
 
@@ -81,6 +137,28 @@ func (dl *DistListSummaryKey) GetKey() (DynamoKey, error) {
 	return getSummaryKey(dl.Name)
 }
 
+func (dl *DistListSummary) toDetails() dto.DistributionListDetails {
+
+	labels := dl.Labels
+
+	if labels == nil {
+		labels = []string{}
+	}
+
+	return dto.DistributionListDetails{
+		DistributionListSummary: dto.DistributionListSummary{
+			Name:               dl.Name,
+			NumberOfRecipients: dl.NumRecipients,
+			Segment:            dl.Segment,
+		},
+		Description: dl.Description,
+		Owner:       dl.Owner,
+		Labels:      labels,
+		CreatedAt:   dl.CreatedAt,
+		UpdatedAt:   dl.UpdatedAt,
+	}
+}
+
 func (r *Registry) addRecipients(ctx context.Context, recipients []DistListRecipient) (int, error) {
 
 	requestItems, err := MakeBatchWriteRequest(DistListRecipientsTable, recipients)
@@ -149,6 +227,64 @@ func (r *Registry) distListExists(ctx context.Context, listName string) (bool, e
 	return len(*resp) != 0, nil
 }
 
+// findDistListSummary gets the summary of the list. Lists that don't
+// exist return an EntityNotFound error.
+func (r *Registry) findDistListSummary(ctx context.Context, listName string) (*DistListSummary, error) {
+
+	resp, err := r.queryDistListSummary(ctx, listName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*resp) == 0 {
+		return nil, internal.EntityNotFound{
+			Id:   listName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	summary := DistListSummary{}
+
+	err = attributevalue.UnmarshalMap(*resp, &summary)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshall distribution list - %w", err)
+	}
+
+	return &summary, nil
+}
+
+// getSegment gets the segment of the list, which is nil for the lists
+// with a fixed set of recipients.
+func (r *Registry) getSegment(ctx context.Context, listName string) (*sdto.Segment, error) {
+
+	summary, err := r.findDistListSummary(ctx, listName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return summary.Segment, nil
+}
+
+// checkStaticDistList checks that the list exists and that its
+// recipients aren't defined by a segment.
+func (r *Registry) checkStaticDistList(ctx context.Context, listName string) error {
+
+	segment, err := r.getSegment(ctx, listName)
+
+	if err != nil {
+		return err
+	}
+
+	if segment != nil {
+		return internal.DistributionListIsSegment{Name: listName}
+	}
+
+	return nil
+}
+
 func (r *Registry) CreateDistributionList(ctx context.Context, dlReq dto.DistributionList) error {
 
 	exists, err := r.distListExists(ctx, dlReq.Name)
@@ -161,9 +297,27 @@ func (r *Registry) CreateDistributionList(ctx context.Context, dlReq dto.Distrib
 		return internal.DistributionListAlreadyExists{Name: dlReq.Name}
 	}
 
+	if slices.Contains(dlReq.Lists, dlReq.Name) {
+		return internal.DistributionListCycle{Name: dlReq.Name, List: dlReq.Name}
+	}
+
+	err = r.checkNestedLists(ctx, dlReq.Name, dlReq.Lists)
+
+	if err != nil {
+		return err
+	}
+
 	summary := DistListSummary{
 		Name:          dlReq.Name,
 		NumRecipients: len(dlReq.Recipients),
+		Segment:       dlReq.Segment,
+		Description:   dlReq.Description,
+		Labels:        dlReq.Labels,
+		CreatedAt:     time.Now().Format(time.RFC3339),
+	}
+
+	if dlReq.Owner != "" {
+		summary.Owner = &dlReq.Owner
 	}
 
 	marshalled, err := attributevalue.MarshalMap(summary)
@@ -190,31 +344,46 @@ func (r *Registry) CreateDistributionList(ctx context.Context, dlReq dto.Distrib
 		})
 	}
 
-	if len(recipients) == 0 {
-		return nil
+	if len(recipients) != 0 {
+		_, recipientsErr := r.addRecipients(ctx, recipients)
+
+		if recipientsErr != nil {
+			recipientsErr = fmt.Errorf("failed to add recipients to list - %w", err)
+			summaryError := r.deleteSummary(ctx, dlReq.Name)
+
+			if summaryError != nil {
+				summaryError = fmt.Errorf("failed to delete dist list summary - %w", summaryError)
+			}
+
+			return errors.Join(recipientsErr, summaryError)
+		}
 	}
 
-	_, recipientsErr := r.addRecipients(ctx, recipients)
+	listsErr := r.addNestedLists(ctx, dlReq.Name, dlReq.Lists)
 
-	if recipientsErr != nil {
-		recipientsErr = fmt.Errorf("failed to add recipients to list - %w", err)
-		summaryError := r.deleteSummary(ctx, dlReq.Name)
+	if listsErr != nil {
+		listsErr = fmt.Errorf("failed to add nested lists to list - %w", listsErr)
+		deleteErr := r.DeleteDistributionList(ctx, dlReq.Name)
 
-		if summaryError != nil {
-			summaryError = fmt.Errorf("failed to delete dist list summary - %w", summaryError)
+		if deleteErr != nil {
+			deleteErr = fmt.Errorf("failed to delete dist list - %w", deleteErr)
 		}
 
-		err = errors.Join(recipientsErr, summaryError)
+		return errors.Join(listsErr, deleteErr)
 	}
 
-	return err
+	return nil
 }
 
-func (r *Registry) GetDistributionLists(ctx context.Context, filters sdto.PageFilter) (sdto.Page[dto.DistributionListSummary], error) {
+func (r *Registry) GetDistributionLists(ctx context.Context, filters dto.DistributionListFilters) (sdto.Page[dto.DistributionListSummary], error) {
+
+	if filters.Member != nil {
+		return r.getMemberDistributionLists(ctx, *filters.Member, filters.PageFilter)
+	}
 
 	page := sdto.Page[dto.DistributionListSummary]{}
 
-	pageParams, err := makePageFilters(&DistListSummaryKey{}, filters)
+	pageParams, err := makePageFilters(&DistListSummaryKey{}, filters.PageFilter)
 
 	if err != nil {
 		return page, fmt.Errorf("failed to make page params - %w", err)
@@ -258,6 +427,7 @@ func (r *Registry) GetDistributionLists(ctx context.Context, filters sdto.PageFi
 		s := dto.DistributionListSummary{
 			Name:               summary.Name,
 			NumberOfRecipients: summary.NumRecipients,
+			Segment:            summary.Segment,
 		}
 
 		result = append(result, s)
@@ -271,406 +441,1929 @@ func (r *Registry) GetDistributionLists(ctx context.Context, filters sdto.PageFi
 	return page, nil
 }
 
-func (r *Registry) deleteSummary(ctx context.Context, listName string) error {
-	summary := DistListSummary{Name: listName}
-	key, err := summary.GetKey()
+func (r *Registry) queryRecipientLists(ctx context.Context, recipient string, pageParams DynamoPageParams) (*dynamodb.QueryOutput, error) {
+
+	keyEx := expression.Key(DistListRecipientSortKey).Equal(expression.Value(recipient))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
 
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to create expression - %w", err)
 	}
 
-	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(DistListSummaryTable),
-		Key:       key,
+	resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListRecipientsTable),
+		IndexName:                 aws.String(DistListRecipientListIdx),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Limit:                     pageParams.Limit,
+		ExclusiveStartKey:         pageParams.ExclusiveStartKey,
 	})
 
-	return err
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the lists of the recipient - %w", err)
+	}
+
+	return resp, nil
 }
 
-func (r *Registry) deleteRecipients(ctx context.Context, recipients []DistListRecipient) (int, error) {
+// getMemberDistributionLists gets a page of the lists the member is a
+// recipient of, with the summaries of the lists.
+func (r *Registry) getMemberDistributionLists(ctx context.Context, member string, filters sdto.PageFilter) (sdto.Page[dto.DistributionListSummary], error) {
 
-	if len(recipients) == 0 {
-		return 0, nil
-	}
+	page := sdto.Page[dto.DistributionListSummary]{}
 
-	deleteReq := make([]types.WriteRequest, 0, len(recipients))
+	pageParams, err := makePageFilters(&DistListRecipient{}, filters)
 
-	for _, DistListRecipient := range recipients {
-		key, _ := DistListRecipient.GetKey()
-		deleteReq = append(deleteReq, types.WriteRequest{
-			DeleteRequest: &types.DeleteRequest{
-				Key: key,
-			},
-		})
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
 	}
 
-	requestItems := map[string][]types.WriteRequest{
-		DistListRecipientsTable: deleteReq,
+	resp, err := r.queryRecipientLists(ctx, member, pageParams)
+
+	if err != nil {
+		return page, err
 	}
 
-	_, err := r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
-		RequestItems: requestItems,
-	})
+	var recipients []DistListRecipient
+	err = attributevalue.UnmarshalListOfMaps(resp.Items, &recipients)
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete recipients of dl - %w", err)
+		return page, fmt.Errorf("failed to unmarshall the lists - %w", err)
 	}
 
-	return len(recipients), nil
-}
+	var nextToken *string = nil
 
-func (r *Registry) DeleteDistributionList(ctx context.Context, listName string) error {
+	if len(resp.LastEvaluatedKey) != 0 {
+		key := DistListRecipient{}
+		encoded, err := marshalNextToken(&key, resp.LastEvaluatedKey)
 
-	keyEx := expression.Key(DistListRecipientHashKey).Equal(expression.Value(listName))
-	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+		if err != nil {
+			return page, err
+		}
 
-	if err != nil {
-		return fmt.Errorf("failed to create expression - %w", err)
+		nextToken = &encoded
 	}
 
-	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
-		TableName:                 aws.String(DistListRecipientsTable),
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		KeyConditionExpression:    expr.KeyCondition(),
-	})
+	result := make([]dto.DistributionListSummary, 0, len(recipients))
 
-	for queryPaginator.HasMorePages() {
-		resp, err := queryPaginator.NextPage(ctx)
+	for _, recipient := range recipients {
+		summary, err := r.getDistListSummary(ctx, recipient.DistListName)
 
 		if err != nil {
-			return fmt.Errorf("failed to retrieve user page - %w", err)
+			return page, fmt.Errorf("failed to get the summary of %s - %w", recipient.DistListName, err)
 		}
 
-		var recipients []DistListRecipient
-		err = attributevalue.UnmarshalListOfMaps(resp.Items, &recipients)
+		// The list was deleted after the recipients were queried
+		if summary.Name == "" {
+			continue
+		}
+
+		result = append(result, dto.DistributionListSummary{
+			Name:               summary.Name,
+			NumberOfRecipients: summary.NumRecipients,
+			Segment:            summary.Segment,
+		})
+	}
+
+	page.PrevToken = filters.NextToken
+	page.NextToken = nextToken
+	page.ResultCount = len(result)
+	page.Data = result
+
+	return page, nil
+}
+
+// DeleteRecipientFromLists removes the recipient from every list and
+// returns the names of the lists it was removed from.
+func (r *Registry) DeleteRecipientFromLists(ctx context.Context, recipient string) ([]string, error) {
+
+	recipients := []DistListRecipient{}
+	pageParams := DynamoPageParams{}
+
+	for {
+		resp, err := r.queryRecipientLists(ctx, recipient, pageParams)
 
 		if err != nil {
-			return fmt.Errorf("failed to unmarshal distribution list - %w", err)
+			return nil, err
 		}
 
-		_, err = r.deleteRecipients(ctx, recipients)
+		var page []DistListRecipient
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &page)
 
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("failed to unmarshall the lists - %w", err)
 		}
-	}
 
-	err = r.deleteSummary(ctx, listName)
-
-	if err != nil {
-		return fmt.Errorf("failed to delete summary - %w", err)
-	}
+		recipients = append(recipients, page...)
 
-	return nil
-}
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
 
-func (r *Registry) GetRecipients(ctx context.Context, distlistName string, filters sdto.PageFilter) (sdto.Page[string], error) {
+		pageParams.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
 
-	page := sdto.Page[string]{}
+	lists := make([]string, 0, len(recipients))
 
-	exists, err := r.distListExists(ctx, distlistName)
+	for _, recipient := range recipients {
+		removed, err := r.removeRecipient(ctx, recipient)
 
-	if err != nil {
-		return page, fmt.Errorf("failed to check if distribution list exists - %w", err)
-	}
+		if err != nil {
+			return nil, err
+		}
 
-	if !exists {
-		return page, internal.EntityNotFound{
-			Id:   distlistName,
-			Type: registry.DistributionListType,
+		if removed {
+			lists = append(lists, recipient.DistListName)
 		}
 	}
 
-	keyExp := expression.Key(DistListRecipientHashKey).Equal(expression.Value(distlistName))
-	projExp := expression.NamesList(expression.Name(DistListRecipientSortKey))
+	slices.Sort(lists)
 
-	builder := expression.NewBuilder()
-	expr, err := builder.WithKeyCondition(keyExp).WithProjection(projExp).Build()
+	return lists, nil
+}
 
-	if err != nil {
-		return page, fmt.Errorf("failed to build query - %w", err)
-	}
+// removeRecipient deletes the recipient and decrements the count of its
+// list in the same transaction, so the count can't drift if the removal
+// fails midway. It returns false if the recipient was already removed,
+// e.g. by a concurrent removal, which has decremented the count, or if
+// the list was deleted.
+func (r *Registry) removeRecipient(ctx context.Context, recipient DistListRecipient) (bool, error) {
 
-	pageParams, err := makePageFilters(&DistListRecipient{}, filters)
+	recipientKey, err := recipient.GetKey()
 
 	if err != nil {
-		return page, fmt.Errorf("failed to make page params - %w", err)
-	}
-
-	queryInput := dynamodb.QueryInput{
-		TableName:                 aws.String(DistListRecipientsTable),
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		KeyConditionExpression:    expr.KeyCondition(),
-		ProjectionExpression:      expr.Projection(),
-		Limit:                     pageParams.Limit,
-		ExclusiveStartKey:         pageParams.ExclusiveStartKey,
+		return false, fmt.Errorf("failed to build recipient key - %w", err)
 	}
 
-	response, err := r.client.Query(ctx, &queryInput)
+	summaryKey, err := getSummaryKey(recipient.DistListName)
 
 	if err != nil {
-		return page, fmt.Errorf("failed to get recipients - %w", err)
+		return false, fmt.Errorf("failed to build summary key - %w", err)
 	}
 
-	var recipients []DistListRecipient
-	err = attributevalue.UnmarshalListOfMaps(response.Items, &recipients)
+	condEx := expression.AttributeExists(expression.Name(DistListRecipientHashKey))
+	deleteExpr, err := expression.NewBuilder().WithCondition(condEx).Build()
 
 	if err != nil {
-		return page, fmt.Errorf("failed to unmarshall recipients - %w", err)
+		return false, fmt.Errorf("failed to build delete expression - %w", err)
 	}
 
-	var nextToken *string = nil
+	// The count of a deleted list isn't recreated
+	update := expression.Add(expression.Name("numOfRecipients"), expression.Value(-1))
+	summaryEx := expression.AttributeExists(expression.Name(DistListSummaryHashKey))
+	updateExpr, err := expression.NewBuilder().WithUpdate(update).WithCondition(summaryEx).Build()
 
-	if len(response.LastEvaluatedKey) != 0 {
-		key := DistListRecipient{}
-		encoded, err := marshalNextToken(&key, response.LastEvaluatedKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to build update expression - %w", err)
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{{
+			Delete: &types.Delete{
+				TableName:                           aws.String(DistListRecipientsTable),
+				Key:                                 recipientKey,
+				ExpressionAttributeNames:            deleteExpr.Names(),
+				ConditionExpression:                 deleteExpr.Condition(),
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+			}}, {
+			Update: &types.Update{
+				TableName:                           aws.String(DistListSummaryTable),
+				Key:                                 summaryKey,
+				ExpressionAttributeNames:            updateExpr.Names(),
+				ExpressionAttributeValues:           updateExpr.Values(),
+				UpdateExpression:                    updateExpr.Update(),
+				ConditionExpression:                 updateExpr.Condition(),
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+			}},
+		},
+	})
 
-		if err != nil {
-			return page, err
+	if err != nil {
+		// The recipient or the list doesn't exist anymore
+		target := &types.TransactionCanceledException{}
+		if errors.As(err, &target) {
+			return false, nil
 		}
-
-		nextToken = &encoded
+		return false, fmt.Errorf("failed to remove the recipient from %s - %w", recipient.DistListName, err)
 	}
 
-	result := make([]string, 0, len(recipients))
+	return true, nil
+}
 
-	for _, r := range recipients {
-		result = append(result, r.UserId)
-	}
+func (r *Registry) GetDistributionListDetails(ctx context.Context, distlistName string) (dto.DistributionListDetails, error) {
 
-	page.NextToken = nextToken
-	page.PrevToken = filters.NextToken
-	page.ResultCount = len(recipients)
+	summary, err := r.findDistListSummary(ctx, distlistName)
 
-	page.Data = result
+	if err != nil {
+		return dto.DistributionListDetails{}, err
+	}
 
-	return page, nil
+	return summary.toDetails(), nil
 }
 
-func (r *Registry) getRecipientsInDL(ctx context.Context, listName string, recipients []string) ([]DistListRecipient, error) {
+func (r *Registry) UpdateDistributionList(ctx context.Context, distlistName string, metadata dto.DistributionListMetadata) (dto.DistributionListDetails, error) {
 
-	result := make([]DistListRecipient, 0)
+	key, err := getSummaryKey(distlistName)
 
-	if len(recipients) == 0 {
-		return result, nil
+	if err != nil {
+		return dto.DistributionListDetails{}, err
 	}
 
-	listFilter := expression.Equal(expression.Name("listName"), expression.Value(listName))
-	userFilter := makeInFilter("userId", recipients)
-	filterEx := listFilter.And(*userFilter)
+	update := expression.
+		Set(expression.Name("description"), expression.Value(metadata.Description)).
+		Set(expression.Name("owner"), expression.Value(metadata.Owner)).
+		Set(expression.Name("labels"), expression.Value(metadata.Labels)).
+		Set(expression.Name("updatedAt"), expression.Value(time.Now().Format(time.RFC3339)))
 
-	expr, err := expression.NewBuilder().WithFilter(filterEx).Build()
+	condEx := expression.AttributeExists(expression.Name(DistListSummaryHashKey))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condEx).Build()
 
 	if err != nil {
-		return result, fmt.Errorf("failed to make expression - %w", err)
+		return dto.DistributionListDetails{}, fmt.Errorf("failed to make update query - %w", err)
 	}
 
-	scanPaginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
-		TableName:                 aws.String(DistListRecipientsTable),
-		ConsistentRead:            aws.Bool(true),
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		FilterExpression:          expr.Filter(),
+	resp, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                           aws.String(DistListSummaryTable),
+		Key:                                 key,
+		ExpressionAttributeNames:            expr.Names(),
+		ExpressionAttributeValues:           expr.Values(),
+		UpdateExpression:                    expr.Update(),
+		ConditionExpression:                 expr.Condition(),
+		ReturnValues:                        types.ReturnValueAllNew,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
 	})
 
-	for scanPaginator.HasMorePages() {
-		resp, err := scanPaginator.NextPage(ctx)
-
-		if err != nil {
-			return []DistListRecipient{}, fmt.Errorf("failed to retrieve recipients page - %w", err)
+	if err != nil {
+		target := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &target) {
+			return dto.DistributionListDetails{}, internal.EntityNotFound{
+				Id:   distlistName,
+				Type: registry.DistributionListType,
+			}
 		}
+		return dto.DistributionListDetails{}, fmt.Errorf("failed to update distribution list - %w", err)
+	}
 
-		var page []DistListRecipient
-		err = attributevalue.UnmarshalListOfMaps(resp.Items, &page)
-
-		if err != nil {
-			return []DistListRecipient{}, fmt.Errorf("failed to unmarshall recipients page - %w", err)
-		}
+	var summary DistListSummary
+	err = attributevalue.UnmarshalMap(resp.Attributes, &summary)
 
-		result = append(result, page...)
+	if err != nil {
+		return dto.DistributionListDetails{}, fmt.Errorf("failed to unmarshall distribution list - %w", err)
 	}
 
-	return result, nil
+	return summary.toDetails(), nil
 }
 
-func (r *Registry) updateRecipientCount(ctx context.Context, listName string, numRecipients int) (int, error) {
+// markRenaming marks the list as being renamed to the new name, which
+// fails if the list is being renamed to another name.
+func (r *Registry) markRenaming(ctx context.Context, listName, newName string) error {
 
-	summary := DistListSummary{Name: listName}
-	key, err := summary.GetKey()
+	key, err := getSummaryKey(listName)
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to build summary key")
+		return err
 	}
 
-	update := expression.Add(expression.Name("numOfRecipients"), expression.Value(numRecipients))
-	exp, err := expression.NewBuilder().WithUpdate(update).Build()
+	renamingTo := expression.Name("renamingTo")
+
+	condEx := expression.AttributeExists(expression.Name(DistListSummaryHashKey)).
+		And(expression.Or(
+			expression.AttributeNotExists(renamingTo),
+			renamingTo.Equal(expression.Value(newName))))
+
+	expr, err := expression.NewBuilder().
+		WithCondition(condEx).
+		WithUpdate(expression.Set(renamingTo, expression.Value(newName))).
+		Build()
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to build update expression - %w", err)
+		return fmt.Errorf("failed to make update query - %w", err)
 	}
 
-	resp, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName:                 aws.String(DistListSummaryTable),
 		Key:                       key,
-		ExpressionAttributeNames:  exp.Names(),
-		ExpressionAttributeValues: exp.Values(),
-		UpdateExpression:          exp.Update(),
-		ReturnValues:              types.ReturnValueUpdatedNew,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
 	})
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to update summary count - %w", err)
+		target := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &target) {
+			return r.renamingError(ctx, listName)
+		}
+		return fmt.Errorf("failed to mark the list as being renamed - %w", err)
 	}
 
-	var attrMap map[string]int
+	return nil
+}
 
-	err = attributevalue.UnmarshalMap(resp.Attributes, &attrMap)
+// renamingError tells why the list couldn't be marked as being renamed
+func (r *Registry) renamingError(ctx context.Context, listName string) error {
+
+	summary, err := r.findDistListSummary(ctx, listName)
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshall summary count update - %w", err)
+		return err
 	}
 
-	return attrMap["numOfRecipients"], nil
+	if summary.RenamingTo == nil {
+		return fmt.Errorf("failed to mark %s as being renamed", listName)
+	}
+
+	return internal.DistributionListRenaming{Name: listName, NewName: *summary.RenamingTo}
 }
 
-func (r *Registry) getNewRecipients(recipientsInDL []DistListRecipient, toCheck []string) []string {
+// putRenamedSummary stores the summary of the list with its new name,
+// which fails if a list with the name exists, unless it's the copy of
+// the list made by a rename that failed midway.
+func (r *Registry) putRenamedSummary(ctx context.Context, summary DistListSummary) error {
 
-	newRecipients := make([]string, 0)
-	recipientSet := make(map[string]struct{})
+	condEx := expression.AttributeNotExists(expression.Name(DistListSummaryHashKey)).
+		Or(expression.Name("renamedFrom").Equal(expression.Value(*summary.RenamedFrom)))
 
-	for _, r := range recipientsInDL {
-		recipientSet[r.UserId] = struct{}{}
+	expr, err := expression.NewBuilder().WithCondition(condEx).Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to make put condition - %w", err)
 	}
 
-	for _, r := range toCheck {
-		if _, ok := recipientSet[r]; !ok {
-			newRecipients = append(newRecipients, r)
+	item, err := attributevalue.MarshalMap(summary)
+
+	if err != nil {
+		return fmt.Errorf("failed to marshall distribution list summary - %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(DistListSummaryTable),
+		Item:                      item,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConditionExpression:       expr.Condition(),
+	})
+
+	if err != nil {
+		target := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &target) {
+			return internal.DistributionListAlreadyExists{Name: summary.Name}
 		}
+		return fmt.Errorf("failed to create summary - %w", err)
 	}
 
-	return newRecipients
+	return nil
 }
 
-func (r *Registry) AddRecipients(ctx context.Context, listName string, recipients []string) (*dto.DistributionListSummary, error) {
+// finishRename removes the rename marker of the list with the new name
+// once the list with the old name is deleted.
+func (r *Registry) finishRename(ctx context.Context, newName string) (dto.DistributionListDetails, error) {
 
-	exists, err := r.distListExists(ctx, listName)
+	key, err := getSummaryKey(newName)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if distribution list exists - %w", err)
+		return dto.DistributionListDetails{}, err
 	}
 
-	if !exists {
-		return nil, internal.EntityNotFound{
-			Id:   listName,
-			Type: registry.DistributionListType,
-		}
+	expr, err := expression.NewBuilder().
+		WithUpdate(expression.Remove(expression.Name("renamedFrom"))).
+		Build()
+
+	if err != nil {
+		return dto.DistributionListDetails{}, fmt.Errorf("failed to make update query - %w", err)
 	}
 
-	recipientsInDL, err := r.getRecipientsInDL(ctx, listName, recipients)
+	resp, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(DistListSummaryTable),
+		Key:                      key,
+		ExpressionAttributeNames: expr.Names(),
+		UpdateExpression:         expr.Update(),
+		ReturnValues:             types.ReturnValueAllNew,
+	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve recipients - %w", err)
+		return dto.DistributionListDetails{}, fmt.Errorf("failed to remove the rename marker - %w", err)
 	}
 
-	newRecipients := r.getNewRecipients(recipientsInDL, recipients)
-	toAdd := make([]DistListRecipient, 0, len(recipients))
+	var summary DistListSummary
+	err = attributevalue.UnmarshalMap(resp.Attributes, &summary)
 
-	for _, r := range newRecipients {
-		toAdd = append(toAdd, DistListRecipient{
-			DistListName: listName,
-			UserId:       r,
-		})
+	if err != nil {
+		return dto.DistributionListDetails{}, fmt.Errorf("failed to unmarshall distribution list - %w", err)
 	}
 
-	if len(toAdd) == 0 {
-		summary, err := r.getDistListSummary(ctx, listName)
+	return summary.toDetails(), nil
+}
+
+func (r *Registry) copyRecipients(ctx context.Context, listName, newName string) error {
+
+	keyEx := expression.Key(DistListRecipientHashKey).Equal(expression.Value(listName))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to create expression - %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListRecipientsTable),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to get dist list summary - %w", err)
+			return fmt.Errorf("failed to retrieve recipients page - %w", err)
 		}
 
-		s := dto.DistributionListSummary{
-			Name:               listName,
-			NumberOfRecipients: summary.NumRecipients,
+		var recipients []DistListRecipient
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &recipients)
+
+		if err != nil {
+			return fmt.Errorf("failed to unmarshall recipients - %w", err)
 		}
 
-		return &s, nil
+		for i := range recipients {
+			recipients[i].DistListName = newName
+		}
+
+		requests, err := MakeBatchWriteRequest(DistListRecipientsTable, recipients)
+
+		if err != nil {
+			return fmt.Errorf("failed create batch request for recipients - %w", err)
+		}
+
+		err = r.batchWrite(ctx, DistListRecipientsTable, requests[DistListRecipientsTable])
+
+		if err != nil {
+			return err
+		}
 	}
 
-	_, err = r.addRecipients(ctx, toAdd)
+	return nil
+}
+
+// renameNestedLists moves the lists included by the list, and the
+// inclusions of the list in other lists, to the new name.
+func (r *Registry) renameNestedLists(ctx context.Context, listName, newName string) error {
+
+	nested, err := r.getNestedLists(ctx, listName)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	count, err := r.updateRecipientCount(ctx, listName, len(newRecipients))
+	parents, err := r.getParentLists(ctx, listName)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to update summary count - %w", err)
+		return err
 	}
 
-	summary := dto.DistributionListSummary{
-		Name:               listName,
-		NumberOfRecipients: count,
+	renamed := make([]DistListList, 0, len(nested)+len(parents))
+
+	for _, l := range nested {
+		renamed = append(renamed, DistListList{
+			DistListName: newName,
+			NestedList:   l.NestedList,
+		})
 	}
 
-	return &summary, nil
+	for _, l := range parents {
+		renamed = append(renamed, DistListList{
+			DistListName: l.DistListName,
+			NestedList:   newName,
+		})
+	}
+
+	requests, err := MakeBatchWriteRequest(DistListListsTable, renamed)
+
+	if err != nil {
+		return fmt.Errorf("failed create batch request for nested lists - %w", err)
+	}
+
+	err = r.batchWrite(ctx, DistListListsTable, requests[DistListListsTable])
+
+	if err != nil {
+		return err
+	}
+
+	return r.deleteNestedLists(ctx, append(nested, parents...))
 }
 
-func (r *Registry) DeleteRecipients(ctx context.Context, listName string, recipients []string) (*dto.DistributionListSummary, error) {
+func (r *Registry) renameNotificationsList(ctx context.Context, listName, newName string) error {
 
-	exists, err := r.distListExists(ctx, listName)
+	keyEx := expression.Key(NotificationDistributionListIdxHashKey).Equal(expression.Value(listName))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if distribution list exists - %w", err)
+		return fmt.Errorf("failed to create expression - %w", err)
 	}
 
-	if !exists {
-		return nil, internal.EntityNotFound{
-			Id:   listName,
-			Type: registry.DistributionListType,
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(NotificationsTable),
+		IndexName:                 aws.String(NotificationDistributionListIdx),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	// The notifications are updated after they're all read, as updating
+	// them moves them out of the queried index
+	notifications := []notificationKey{}
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return fmt.Errorf("failed to retrieve notifications page - %w", err)
+		}
+
+		var page []notificationKey
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &page)
+
+		if err != nil {
+			return fmt.Errorf("failed to unmarshall notifications - %w", err)
 		}
+
+		notifications = append(notifications, page...)
 	}
 
-	toRemove, err := r.getRecipientsInDL(ctx, listName, recipients)
+	update := expression.Set(expression.Name(NotificationDistributionListIdxHashKey), expression.Value(newName))
+	updateExpr, err := expression.NewBuilder().WithUpdate(update).Build()
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve recipients - %w", err)
+		return fmt.Errorf("failed to make update query - %w", err)
 	}
 
-	if len(toRemove) == 0 {
-		summary, err := r.getDistListSummary(ctx, listName)
+	for _, n := range notifications {
+		key, err := n.GetKey()
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to get dist list summary - %w", err)
+			return err
 		}
 
-		s := dto.DistributionListSummary{
-			Name:               listName,
-			NumberOfRecipients: summary.NumRecipients,
+		_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(NotificationsTable),
+			Key:                       key,
+			ExpressionAttributeNames:  updateExpr.Names(),
+			ExpressionAttributeValues: updateExpr.Values(),
+			UpdateExpression:          updateExpr.Update(),
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to rename the list of notification %s - %w", n.Id, err)
 		}
+	}
 
-		return &s, nil
+	return nil
+}
+
+// storedNotifications are the notifications stored as JSON in an
+// attribute of the items of a table, which are indexed by the
+// distribution list of the notification.
+type storedNotifications struct {
+	table     string
+	index     string
+	hashKey   string
+	listKey   string
+	attribute string
+}
+
+var (
+	scheduledNotifications = storedNotifications{
+		table:     NotificationSchedulesTable,
+		index:     NotificationScheduleDistListIdx,
+		hashKey:   NotificationScheduleHashKey,
+		listKey:   NotificationScheduleDistListIdxKey,
+		attribute: "notification",
+	}
+	outboxNotifications = storedNotifications{
+		table:     NotificationOutboxTable,
+		index:     NotificationOutboxDistListIdx,
+		hashKey:   NotificationOutboxHashKey,
+		listKey:   NotificationOutboxDistListIdxKey,
+		attribute: "payload",
 	}
+)
 
-	_, err = r.deleteRecipients(ctx, toRemove)
+// renameStoredNotificationsList renames the list of the stored
+// notifications, keeping the rest of the JSON as it is.
+func (r *Registry) renameStoredNotificationsList(ctx context.Context, stored storedNotifications, listName, newName string) error {
+
+	keyEx := expression.Key(stored.listKey).Equal(expression.Value(listName))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
 
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to create expression - %w", err)
 	}
 
-	count, err := r.updateRecipientCount(ctx, listName, -len(toRemove))
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(stored.table),
+		IndexName:                 aws.String(stored.index),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to update summary count - %w", err)
+	// The items are updated after they're all read, as updating them
+	// moves them out of the queried index
+	items := []map[string]types.AttributeValue{}
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return fmt.Errorf("failed to query %s - %w", stored.table, err)
+		}
+
+		items = append(items, resp.Items...)
 	}
 
-	summary := dto.DistributionListSummary{
-		Name:               listName,
-		NumberOfRecipients: count,
+	newValue, _ := json.Marshal(newName)
+
+	for _, item := range items {
+		var contents string
+
+		if err := attributevalue.Unmarshal(item[stored.attribute], &contents); err != nil {
+			return fmt.Errorf("failed to unmarshall %s - %w", stored.attribute, err)
+		}
+
+		notification := map[string]json.RawMessage{}
+
+		if err := json.Unmarshal([]byte(contents), &notification); err != nil {
+			return fmt.Errorf("failed to unmarshall the stored notification - %w", err)
+		}
+
+		notification["distributionList"] = newValue
+
+		renamed, err := json.Marshal(notification)
+
+		if err != nil {
+			return fmt.Errorf("failed to marshall the stored notification - %w", err)
+		}
+
+		distList := expression.Name(stored.listKey)
+
+		update := expression.
+			Set(expression.Name(stored.attribute), expression.Value(string(renamed))).
+			Set(distList, expression.Value(newName))
+
+		// The item might have been deleted or changed since it was read
+		condEx := distList.Equal(expression.Value(listName))
+
+		updateExpr, err := expression.NewBuilder().
+			WithUpdate(update).
+			WithCondition(condEx).
+			Build()
+
+		if err != nil {
+			return fmt.Errorf("failed to make update query - %w", err)
+		}
+
+		_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(stored.table),
+			Key:                       DynamoKey{stored.hashKey: item[stored.hashKey]},
+			ConditionExpression:       updateExpr.Condition(),
+			ExpressionAttributeNames:  updateExpr.Names(),
+			ExpressionAttributeValues: updateExpr.Values(),
+			UpdateExpression:          updateExpr.Update(),
+		})
+
+		var condErr *types.ConditionalCheckFailedException
+
+		if err != nil && !errors.As(err, &condErr) {
+			return fmt.Errorf("failed to rename the list of the stored notification - %w", err)
+		}
 	}
 
-	return &summary, nil
+	return nil
+}
+
+// RenameDistributionList copies the list to the new name, changes the
+// notifications, schedules and outbox entries that reference the list
+// and deletes the list with the old name. The steps aren't atomic, the
+// lists are marked while they're renamed and every step can be done
+// again, so a rename that fails midway is finished by retrying it. The
+// list with the old name is deleted last, the recipients added to it
+// while it's renamed might not be copied.
+func (r *Registry) RenameDistributionList(ctx context.Context, distlistName, newName string) (dto.DistributionListDetails, error) {
+
+	summary, err := r.findDistListSummary(ctx, distlistName)
+
+	if err != nil {
+		return dto.DistributionListDetails{}, err
+	}
+
+	if summary.RenamingTo != nil && *summary.RenamingTo != newName {
+		return dto.DistributionListDetails{}, internal.DistributionListRenaming{
+			Name:    distlistName,
+			NewName: *summary.RenamingTo,
+		}
+	}
+
+	err = r.markRenaming(ctx, distlistName, newName)
+
+	if err != nil {
+		return dto.DistributionListDetails{}, err
+	}
+
+	updatedAt := time.Now().Format(time.RFC3339)
+
+	summary.Name = newName
+	summary.UpdatedAt = &updatedAt
+	summary.RenamingTo = nil
+	summary.RenamedFrom = &distlistName
+
+	err = r.putRenamedSummary(ctx, *summary)
+
+	if err != nil {
+		return dto.DistributionListDetails{}, err
+	}
+
+	err = r.copyRecipients(ctx, distlistName, newName)
+
+	if err != nil {
+		return dto.DistributionListDetails{}, err
+	}
+
+	err = r.renameNestedLists(ctx, distlistName, newName)
+
+	if err != nil {
+		return dto.DistributionListDetails{}, err
+	}
+
+	err = r.renameNotificationsList(ctx, distlistName, newName)
+
+	if err != nil {
+		return dto.DistributionListDetails{}, err
+	}
+
+	for _, stored := range []storedNotifications{scheduledNotifications, outboxNotifications} {
+		err = r.renameStoredNotificationsList(ctx, stored, distlistName, newName)
+
+		if err != nil {
+			return dto.DistributionListDetails{}, err
+		}
+	}
+
+	err = r.deleteAllRecipients(ctx, distlistName)
+
+	if err != nil {
+		return dto.DistributionListDetails{}, err
+	}
+
+	err = r.deleteSummary(ctx, distlistName)
+
+	if err != nil {
+		return dto.DistributionListDetails{}, fmt.Errorf("failed to delete summary - %w", err)
+	}
+
+	return r.finishRename(ctx, newName)
+}
+
+func (r *Registry) deleteSummary(ctx context.Context, listName string) error {
+	summary := DistListSummary{Name: listName}
+	key, err := summary.GetKey()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(DistListSummaryTable),
+		Key:       key,
+	})
+
+	return err
+}
+
+func (r *Registry) deleteRecipients(ctx context.Context, recipients []DistListRecipient) (int, error) {
+
+	if len(recipients) == 0 {
+		return 0, nil
+	}
+
+	deleteReq := make([]types.WriteRequest, 0, len(recipients))
+
+	for _, DistListRecipient := range recipients {
+		key, _ := DistListRecipient.GetKey()
+		deleteReq = append(deleteReq, types.WriteRequest{
+			DeleteRequest: &types.DeleteRequest{
+				Key: key,
+			},
+		})
+	}
+
+	err := r.batchWrite(ctx, DistListRecipientsTable, deleteReq)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete recipients of dl - %w", err)
+	}
+
+	return len(recipients), nil
+}
+
+func (r *Registry) deleteAllRecipients(ctx context.Context, listName string) error {
+
+	keyEx := expression.Key(DistListRecipientHashKey).Equal(expression.Value(listName))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to create expression - %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListRecipientsTable),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return fmt.Errorf("failed to retrieve user page - %w", err)
+		}
+
+		var recipients []DistListRecipient
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &recipients)
+
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal distribution list - %w", err)
+		}
+
+		_, err = r.deleteRecipients(ctx, recipients)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Registry) DeleteDistributionList(ctx context.Context, listName string) error {
+
+	err := r.deleteAllRecipients(ctx, listName)
+
+	if err != nil {
+		return err
+	}
+
+	nested, err := r.getNestedLists(ctx, listName)
+
+	if err != nil {
+		return err
+	}
+
+	parents, err := r.getParentLists(ctx, listName)
+
+	if err != nil {
+		return err
+	}
+
+	err = r.deleteNestedLists(ctx, append(nested, parents...))
+
+	if err != nil {
+		return err
+	}
+
+	err = r.deleteSummary(ctx, listName)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete summary - %w", err)
+	}
+
+	return nil
+}
+
+func (r *Registry) GetRecipients(ctx context.Context, distlistName string, filters sdto.PageFilter) (sdto.Page[string], error) {
+
+	page := sdto.Page[string]{}
+
+	exists, err := r.distListExists(ctx, distlistName)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to check if distribution list exists - %w", err)
+	}
+
+	if !exists {
+		return page, internal.EntityNotFound{
+			Id:   distlistName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	keyExp := expression.Key(DistListRecipientHashKey).Equal(expression.Value(distlistName))
+	projExp := expression.NamesList(expression.Name(DistListRecipientSortKey))
+
+	builder := expression.NewBuilder()
+	expr, err := builder.WithKeyCondition(keyExp).WithProjection(projExp).Build()
+
+	if err != nil {
+		return page, fmt.Errorf("failed to build query - %w", err)
+	}
+
+	pageParams, err := makePageFilters(&DistListRecipient{}, filters)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
+	}
+
+	queryInput := dynamodb.QueryInput{
+		TableName:                 aws.String(DistListRecipientsTable),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		Limit:                     pageParams.Limit,
+		ExclusiveStartKey:         pageParams.ExclusiveStartKey,
+	}
+
+	response, err := r.client.Query(ctx, &queryInput)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to get recipients - %w", err)
+	}
+
+	var recipients []DistListRecipient
+	err = attributevalue.UnmarshalListOfMaps(response.Items, &recipients)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to unmarshall recipients - %w", err)
+	}
+
+	var nextToken *string = nil
+
+	if len(response.LastEvaluatedKey) != 0 {
+		key := DistListRecipient{}
+		encoded, err := marshalNextToken(&key, response.LastEvaluatedKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		nextToken = &encoded
+	}
+
+	result := make([]string, 0, len(recipients))
+
+	for _, r := range recipients {
+		result = append(result, r.UserId)
+	}
+
+	page.NextToken = nextToken
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(recipients)
+
+	page.Data = result
+
+	return page, nil
+}
+
+func (r *Registry) GetSegment(ctx context.Context, distlistName string) (*sdto.Segment, error) {
+	return r.getSegment(ctx, distlistName)
+}
+
+func (r *Registry) getRecipientsInDL(ctx context.Context, listName string, recipients []string) ([]DistListRecipient, error) {
+
+	result := make([]DistListRecipient, 0)
+
+	if len(recipients) == 0 {
+		return result, nil
+	}
+
+	listFilter := expression.Equal(expression.Name("listName"), expression.Value(listName))
+	userFilter := makeInFilter("userId", recipients)
+	filterEx := listFilter.And(*userFilter)
+
+	expr, err := expression.NewBuilder().WithFilter(filterEx).Build()
+
+	if err != nil {
+		return result, fmt.Errorf("failed to make expression - %w", err)
+	}
+
+	scanPaginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName:                 aws.String(DistListRecipientsTable),
+		ConsistentRead:            aws.Bool(true),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+	})
+
+	for scanPaginator.HasMorePages() {
+		resp, err := scanPaginator.NextPage(ctx)
+
+		if err != nil {
+			return []DistListRecipient{}, fmt.Errorf("failed to retrieve recipients page - %w", err)
+		}
+
+		var page []DistListRecipient
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &page)
+
+		if err != nil {
+			return []DistListRecipient{}, fmt.Errorf("failed to unmarshall recipients page - %w", err)
+		}
+
+		result = append(result, page...)
+	}
+
+	return result, nil
+}
+
+func (r *Registry) updateRecipientCount(ctx context.Context, listName string, numRecipients int) (int, error) {
+
+	summary := DistListSummary{Name: listName}
+	key, err := summary.GetKey()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build summary key")
+	}
+
+	update := expression.Add(expression.Name("numOfRecipients"), expression.Value(numRecipients))
+	exp, err := expression.NewBuilder().WithUpdate(update).Build()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build update expression - %w", err)
+	}
+
+	resp, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(DistListSummaryTable),
+		Key:                       key,
+		ExpressionAttributeNames:  exp.Names(),
+		ExpressionAttributeValues: exp.Values(),
+		UpdateExpression:          exp.Update(),
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to update summary count - %w", err)
+	}
+
+	var attrMap map[string]int
+
+	err = attributevalue.UnmarshalMap(resp.Attributes, &attrMap)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshall summary count update - %w", err)
+	}
+
+	return attrMap["numOfRecipients"], nil
+}
+
+func (r *Registry) getNewRecipients(recipientsInDL []DistListRecipient, toCheck []string) []string {
+
+	newRecipients := make([]string, 0)
+	recipientSet := make(map[string]struct{})
+
+	for _, r := range recipientsInDL {
+		recipientSet[r.UserId] = struct{}{}
+	}
+
+	for _, r := range toCheck {
+		if _, ok := recipientSet[r]; !ok {
+			newRecipients = append(newRecipients, r)
+		}
+	}
+
+	return newRecipients
+}
+
+func (r *Registry) AddRecipients(ctx context.Context, listName string, recipients []string) (*dto.DistributionListSummary, error) {
+
+	err := r.checkStaticDistList(ctx, listName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recipientsInDL, err := r.getRecipientsInDL(ctx, listName, recipients)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve recipients - %w", err)
+	}
+
+	newRecipients := r.getNewRecipients(recipientsInDL, recipients)
+	toAdd := make([]DistListRecipient, 0, len(recipients))
+
+	for _, r := range newRecipients {
+		toAdd = append(toAdd, DistListRecipient{
+			DistListName: listName,
+			UserId:       r,
+		})
+	}
+
+	if len(toAdd) == 0 {
+		summary, err := r.getDistListSummary(ctx, listName)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dist list summary - %w", err)
+		}
+
+		s := dto.DistributionListSummary{
+			Name:               listName,
+			NumberOfRecipients: summary.NumRecipients,
+		}
+
+		return &s, nil
+	}
+
+	_, err = r.addRecipients(ctx, toAdd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := r.updateRecipientCount(ctx, listName, len(newRecipients))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update summary count - %w", err)
+	}
+
+	summary := dto.DistributionListSummary{
+		Name:               listName,
+		NumberOfRecipients: count,
+	}
+
+	return &summary, nil
+}
+
+func (r *Registry) DeleteRecipients(ctx context.Context, listName string, recipients []string) (*dto.DistributionListSummary, error) {
+
+	err := r.checkStaticDistList(ctx, listName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	toRemove, err := r.getRecipientsInDL(ctx, listName, recipients)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve recipients - %w", err)
+	}
+
+	if len(toRemove) == 0 {
+		summary, err := r.getDistListSummary(ctx, listName)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dist list summary - %w", err)
+		}
+
+		s := dto.DistributionListSummary{
+			Name:               listName,
+			NumberOfRecipients: summary.NumRecipients,
+		}
+
+		return &s, nil
+	}
+
+	_, err = r.deleteRecipients(ctx, toRemove)
+
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := r.updateRecipientCount(ctx, listName, -len(toRemove))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update summary count - %w", err)
+	}
+
+	summary := dto.DistributionListSummary{
+		Name:               listName,
+		NumberOfRecipients: count,
+	}
+
+	return &summary, nil
+}
+
+func (r *Registry) queryNestedLists(ctx context.Context, input *dynamodb.QueryInput) ([]DistListList, error) {
+
+	lists := []DistListList{}
+
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, input)
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return lists, fmt.Errorf("failed to retrieve nested lists page - %w", err)
+		}
+
+		var page []DistListList
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &page)
+
+		if err != nil {
+			return lists, fmt.Errorf("failed to unmarshal nested lists - %w", err)
+		}
+
+		lists = append(lists, page...)
+	}
+
+	return lists, nil
+}
+
+// getNestedLists gets the lists directly included by the list.
+func (r *Registry) getNestedLists(ctx context.Context, listName string) ([]DistListList, error) {
+
+	keyEx := expression.Key(DistListListsHashKey).Equal(expression.Value(listName))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expression - %w", err)
+	}
+
+	return r.queryNestedLists(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListListsTable),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+}
+
+// getParentLists gets the lists that directly include the list.
+func (r *Registry) getParentLists(ctx context.Context, listName string) ([]DistListList, error) {
+
+	keyEx := expression.Key(DistListListsSortKey).Equal(expression.Value(listName))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expression - %w", err)
+	}
+
+	return r.queryNestedLists(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListListsTable),
+		IndexName:                 aws.String(DistListListsParentIdx),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+}
+
+// getIncludedLists gets the list and every list included by it, directly
+// or through other lists.
+func (r *Registry) getIncludedLists(ctx context.Context, listName string) ([]string, error) {
+
+	included := []string{listName}
+	visited := map[string]struct{}{listName: {}}
+
+	for i := 0; i < len(included); i++ {
+		nested, err := r.getNestedLists(ctx, included[i])
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range nested {
+			if _, ok := visited[l.NestedList]; ok {
+				continue
+			}
+
+			visited[l.NestedList] = struct{}{}
+			included = append(included, l.NestedList)
+		}
+	}
+
+	return included, nil
+}
+
+// checkNestedLists checks that the lists exist and that including them
+// in the list doesn't create a cycle.
+func (r *Registry) checkNestedLists(ctx context.Context, listName string, lists []string) error {
+
+	for _, list := range lists {
+		exists, err := r.distListExists(ctx, list)
+
+		if err != nil {
+			return fmt.Errorf("failed to check for list existence - %w", err)
+		}
+
+		if !exists {
+			return internal.EntityNotFound{
+				Id:   list,
+				Type: registry.DistributionListType,
+			}
+		}
+	}
+
+	for _, list := range lists {
+		included, err := r.getIncludedLists(ctx, list)
+
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(included, listName) {
+			return internal.DistributionListCycle{Name: listName, List: list}
+		}
+	}
+
+	return nil
+}
+
+// addNestedLists includes the lists in the list. The checks and the
+// writes aren't atomic, concurrent changes of the nested lists can
+// create cycles, which the worker tolerates when expanding the lists.
+func (r *Registry) addNestedLists(ctx context.Context, listName string, lists []string) error {
+
+	if len(lists) == 0 {
+		return nil
+	}
+
+	err := r.checkNestedLists(ctx, listName, lists)
+
+	if err != nil {
+		return err
+	}
+
+	nested := make([]DistListList, 0, len(lists))
+
+	for _, l := range lists {
+		nested = append(nested, DistListList{
+			DistListName: listName,
+			NestedList:   l,
+		})
+	}
+
+	requests, err := MakeBatchWriteRequest(DistListListsTable, nested)
+
+	if err != nil {
+		return fmt.Errorf("failed create batch request for nested lists - %w", err)
+	}
+
+	return r.batchWrite(ctx, DistListListsTable, requests[DistListListsTable])
+}
+
+func (r *Registry) deleteNestedLists(ctx context.Context, lists []DistListList) error {
+
+	deleteReq := make([]types.WriteRequest, 0, len(lists))
+
+	for _, l := range lists {
+		key, err := l.GetKey()
+
+		if err != nil {
+			return err
+		}
+
+		deleteReq = append(deleteReq, types.WriteRequest{
+			DeleteRequest: &types.DeleteRequest{
+				Key: key,
+			},
+		})
+	}
+
+	return r.batchWrite(ctx, DistListListsTable, deleteReq)
+}
+
+// GetFlattenedRecipients gets the recipients of the list and of every
+// list included by it, directly or through other lists. The recipients
+// of the nested segments are resolved when the notifications are sent.
+func (r *Registry) GetFlattenedRecipients(ctx context.Context, distlistName string, filters sdto.PageFilter) (sdto.Page[string], error) {
+
+	page := sdto.Page[string]{}
+
+	exists, err := r.distListExists(ctx, distlistName)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to check if distribution list exists - %w", err)
+	}
+
+	if !exists {
+		return page, internal.EntityNotFound{
+			Id:   distlistName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	limit := internal.PageSize
+
+	if filters.MaxResults != nil {
+		limit = *filters.MaxResults
+	}
+
+	lastRecipient := ""
+
+	if filters.NextToken != nil {
+		var unmarsalledKey DistListRecipient
+		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
+
+		if err != nil {
+			return page, fmt.Errorf("failed to unmarshall token - %w", err)
+		}
+
+		if unmarsalledKey.DistListName != distlistName {
+			return page, fmt.Errorf("invalid key %s", *filters.NextToken)
+		}
+
+		lastRecipient = unmarsalledKey.UserId
+	}
+
+	included, err := r.getIncludedLists(ctx, distlistName)
+
+	if err != nil {
+		return page, err
+	}
+
+	recipients := []string{}
+
+	for _, list := range included {
+		keyEx := expression.Key(DistListRecipientHashKey).Equal(expression.Value(list))
+		expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+		if err != nil {
+			return page, fmt.Errorf("failed to create expression - %w", err)
+		}
+
+		queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+			TableName:                 aws.String(DistListRecipientsTable),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			KeyConditionExpression:    expr.KeyCondition(),
+		})
+
+		for queryPaginator.HasMorePages() {
+			resp, err := queryPaginator.NextPage(ctx)
+
+			if err != nil {
+				return page, fmt.Errorf("failed to retrieve recipients page - %w", err)
+			}
+
+			var listRecipients []DistListRecipient
+			err = attributevalue.UnmarshalListOfMaps(resp.Items, &listRecipients)
+
+			if err != nil {
+				return page, fmt.Errorf("failed to unmarshall recipients - %w", err)
+			}
+
+			for _, recipient := range listRecipients {
+				if recipient.UserId > lastRecipient {
+					recipients = append(recipients, recipient.UserId)
+				}
+			}
+		}
+	}
+
+	slices.Sort(recipients)
+	recipients = slices.Compact(recipients)
+
+	if len(recipients) > limit {
+		recipients = recipients[:limit]
+
+		key, err := registry.MarshalKey(DistListRecipient{
+			DistListName: distlistName,
+			UserId:       recipients[limit-1],
+		})
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &key
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(recipients)
+	page.Data = recipients
+
+	return page, nil
+}
+
+func (r *Registry) GetLists(ctx context.Context, distlistName string, filters sdto.PageFilter) (sdto.Page[string], error) {
+
+	page := sdto.Page[string]{}
+
+	exists, err := r.distListExists(ctx, distlistName)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to check if distribution list exists - %w", err)
+	}
+
+	if !exists {
+		return page, internal.EntityNotFound{
+			Id:   distlistName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	keyExp := expression.Key(DistListListsHashKey).Equal(expression.Value(distlistName))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyExp).Build()
+
+	if err != nil {
+		return page, fmt.Errorf("failed to build query - %w", err)
+	}
+
+	pageParams, err := makePageFilters(&DistListList{}, filters)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
+	}
+
+	response, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListListsTable),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Limit:                     pageParams.Limit,
+		ExclusiveStartKey:         pageParams.ExclusiveStartKey,
+	})
+
+	if err != nil {
+		return page, fmt.Errorf("failed to get nested lists - %w", err)
+	}
+
+	var lists []DistListList
+	err = attributevalue.UnmarshalListOfMaps(response.Items, &lists)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to unmarshall nested lists - %w", err)
+	}
+
+	var nextToken *string = nil
+
+	if len(response.LastEvaluatedKey) != 0 {
+		key := DistListList{}
+		encoded, err := marshalNextToken(&key, response.LastEvaluatedKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		nextToken = &encoded
+	}
+
+	result := make([]string, 0, len(lists))
+
+	for _, l := range lists {
+		result = append(result, l.NestedList)
+	}
+
+	page.NextToken = nextToken
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(lists)
+	page.Data = result
+
+	return page, nil
+}
+
+func (r *Registry) AddLists(ctx context.Context, listName string, lists []string) (*dto.DistributionListSummary, error) {
+
+	err := r.checkStaticDistList(ctx, listName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.addNestedLists(ctx, listName, lists)
+
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := r.getDistListSummary(ctx, listName)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dist list summary - %w", err)
+	}
+
+	return &dto.DistributionListSummary{
+		Name:               listName,
+		NumberOfRecipients: summary.NumRecipients,
+	}, nil
+}
+
+func (r *Registry) DeleteLists(ctx context.Context, listName string, lists []string) (*dto.DistributionListSummary, error) {
+
+	_, err := r.getSegment(ctx, listName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	toRemove := make([]DistListList, 0, len(lists))
+
+	for _, l := range lists {
+		toRemove = append(toRemove, DistListList{
+			DistListName: listName,
+			NestedList:   l,
+		})
+	}
+
+	err = r.deleteNestedLists(ctx, toRemove)
+
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := r.getDistListSummary(ctx, listName)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dist list summary - %w", err)
+	}
+
+	return &dto.DistributionListSummary{
+		Name:               listName,
+		NumberOfRecipients: summary.NumRecipients,
+		Segment:            summary.Segment,
+	}, nil
+}
+
+// countRecipients counts the recipients stored in the list, without
+// reading them.
+func (r *Registry) countRecipients(ctx context.Context, listName string) (int, error) {
+
+	keyEx := expression.Key(DistListRecipientHashKey).Equal(expression.Value(listName))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to create expression - %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListRecipientsTable),
+		ConsistentRead:            aws.Bool(true),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Select:                    types.SelectCount,
+	})
+
+	count := 0
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return 0, fmt.Errorf("failed to count recipients - %w", err)
+		}
+
+		count += int(resp.Count)
+	}
+
+	return count, nil
+}
+
+func (r *Registry) setRecipientCount(ctx context.Context, listName string, numRecipients int) error {
+
+	key, err := getSummaryKey(listName)
+
+	if err != nil {
+		return fmt.Errorf("failed to build summary key - %w", err)
+	}
+
+	update := expression.Set(expression.Name("numOfRecipients"), expression.Value(numRecipients))
+	exp, err := expression.NewBuilder().WithUpdate(update).Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update expression - %w", err)
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(DistListSummaryTable),
+		Key:                       key,
+		ExpressionAttributeNames:  exp.Names(),
+		ExpressionAttributeValues: exp.Values(),
+		UpdateExpression:          exp.Update(),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to update summary count - %w", err)
+	}
+
+	return nil
+}
+
+func (r *Registry) importRecipients(ctx context.Context, listName string, mode dto.RecipientsImportMode, importId string, batch []string) error {
+
+	batch = slices.Clone(batch)
+	slices.Sort(batch)
+	batch = slices.Compact(batch)
+
+	requests := make([]types.WriteRequest, 0, len(batch))
+
+	for _, userId := range batch {
+		recipient := DistListRecipient{
+			DistListName: listName,
+			UserId:       userId,
+		}
+
+		if mode == dto.RecipientsImportReplace {
+			recipient.ImportId = &importId
+		}
+
+		if mode == dto.RecipientsImportRemove {
+			key, err := recipient.GetKey()
+
+			if err != nil {
+				return err
+			}
+
+			requests = append(requests, types.WriteRequest{
+				DeleteRequest: &types.DeleteRequest{Key: key},
+			})
+
+			continue
+		}
+
+		item, err := attributevalue.MarshalMap(recipient)
+
+		if err != nil {
+			return fmt.Errorf("failed to marshall recipient - %w", err)
+		}
+
+		requests = append(requests, types.WriteRequest{
+			PutRequest: &types.PutRequest{Item: item},
+		})
+	}
+
+	return r.batchWrite(ctx, DistListRecipientsTable, requests)
+}
+
+// deleteReplacedRecipients deletes the recipients of the list that
+// weren't written by the import.
+func (r *Registry) deleteReplacedRecipients(ctx context.Context, listName, importId string) error {
+
+	importIdName := expression.Name("importId")
+
+	keyEx := expression.Key(DistListRecipientHashKey).Equal(expression.Value(listName))
+	filterEx := expression.AttributeNotExists(importIdName).
+		Or(importIdName.NotEqual(expression.Value(importId)))
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(keyEx).
+		WithFilter(filterEx).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to create expression - %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListRecipientsTable),
+		ConsistentRead:            aws.Bool(true),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return fmt.Errorf("failed to retrieve recipients page - %w", err)
+		}
+
+		var recipients []DistListRecipient
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &recipients)
+
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal recipients - %w", err)
+		}
+
+		_, err = r.deleteRecipients(ctx, recipients)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportRecipients writes the recipients batch by batch and recounts the
+// recipients of the list once they're written, an import that fails
+// midway keeps the batches written before the failure and the count
+// matches them. Replacing the recipients writes the imported ones
+// before the others are deleted, so the list is never left empty by an
+// import that fails, it has both the recipients it had and the ones
+// imported before the failure instead.
+func (r *Registry) ImportRecipients(ctx context.Context, distlistName string, mode dto.RecipientsImportMode, batches recipients.Batches) (summary *dto.DistributionListSummary, err error) {
+
+	err = r.checkStaticDistList(ctx, distlistName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		count, countErr := r.countRecipients(ctx, distlistName)
+
+		if countErr == nil {
+			countErr = r.setRecipientCount(ctx, distlistName, count)
+		}
+
+		if countErr != nil {
+			summary, err = nil, errors.Join(err, countErr)
+			return
+		}
+
+		if err == nil {
+			summary = &dto.DistributionListSummary{
+				Name:               distlistName,
+				NumberOfRecipients: count,
+			}
+		}
+	}()
+
+	importId := uuid.NewString()
+
+	for {
+		batch, err := batches.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.importRecipients(ctx, distlistName, mode, importId, batch)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if mode == dto.RecipientsImportReplace {
+		err = r.deleteReplacedRecipients(ctx, distlistName, importId)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The summary is made once the recipients are counted
+	return nil, nil
+}
+
+func (r *Registry) ExportRecipients(ctx context.Context, distlistName string, write func([]string) error) error {
+
+	exists, err := r.distListExists(ctx, distlistName)
+
+	if err != nil {
+		return fmt.Errorf("failed to check if distribution list exists - %w", err)
+	}
+
+	if !exists {
+		return internal.EntityNotFound{
+			Id:   distlistName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	keyExp := expression.Key(DistListRecipientHashKey).Equal(expression.Value(distlistName))
+	projExp := expression.NamesList(expression.Name(DistListRecipientSortKey))
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyExp).WithProjection(projExp).Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build query - %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(DistListRecipientsTable),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		Limit:                     aws.Int32(internal.RecipientsBatchSize),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return fmt.Errorf("failed to retrieve recipients page - %w", err)
+		}
+
+		var recipients []DistListRecipient
+		err = attributevalue.UnmarshalListOfMaps(resp.Items, &recipients)
+
+		if err != nil {
+			return fmt.Errorf("failed to unmarshall recipients - %w", err)
+		}
+
+		if len(recipients) == 0 {
+			continue
+		}
+
+		batch := make([]string, 0, len(recipients))
+
+		for _, r := range recipients {
+			batch = append(batch, r.UserId)
+		}
+
+		err = write(batch)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
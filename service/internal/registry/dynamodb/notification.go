@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -21,6 +22,22 @@ import (
 const (
 	NotificationsTable                        = "Notifications"
 	NotificationHashKey                       = "id"
+	NotificationStatusSendAtIdx               = "StatusSendAtIdx"
+	NotificationStatusSendAtIdxHashKey        = "status"
+	NotificationStatusSendAtIdxSortKey        = "sendAt"
+	NotificationListIdx                       = "ListCreatedAtIdx"
+	NotificationListIdxHashKey                = "listKey"
+	NotificationTopicIdx                      = "TopicCreatedAtIdx"
+	NotificationTopicIdxHashKey               = "topic"
+	NotificationStatusIdx                     = "StatusCreatedAtIdx"
+	NotificationStatusIdxHashKey              = "status"
+	NotificationCreatedByIdx                  = "CreatedByCreatedAtIdx"
+	NotificationCreatedByIdxHashKey           = "createdBy"
+	NotificationTemplateIdx                   = "TemplateCreatedAtIdx"
+	NotificationTemplateIdxHashKey            = "templateId"
+	NotificationDistributionListIdx           = "DistributionListCreatedAtIdx"
+	NotificationDistributionListIdxHashKey    = "distributionList"
+	NotificationCreatedAtIdxSortKey           = "createdAt"
 	NotificationStatusLogTable                = "NotificationStatusLogs"
 	NotificationStatusLogHashKey              = "notificationId"
 	NotificationStatusLogSortKey              = "statusDate"
@@ -30,6 +47,34 @@ const (
 	RecipientNotificationLatestStatusLogTable = "RecipientNotificationLatestStatusLogs"
 )
 
+// The notifications are spread over a fixed number of list keys so the
+// writes don't all land on the same partition of the list index. The
+// listing queries every shard and merges them by the creation date.
+const (
+	NotificationListKeyPrefix = "NOTIFICATION"
+	notificationListShards    = 8
+)
+
+// NotificationListKey returns the list shard of a notification.
+func NotificationListKey(notificationId string) string {
+	h := fnv.New32a()
+	h.Write([]byte(notificationId))
+
+	shard := h.Sum32() % notificationListShards
+
+	return fmt.Sprintf("%s#%d", NotificationListKeyPrefix, shard)
+}
+
+func notificationListKeys() []string {
+	keys := make([]string, 0, notificationListShards)
+
+	for i := range notificationListShards {
+		keys = append(keys, fmt.Sprintf("%s#%d", NotificationListKeyPrefix, i))
+	}
+
+	return keys
+}
+
 type NotificationStatusLog struct {
 	NotificationId string  `dynamodbav:"notificationId"`
 	Status         string  `dynamodbav:"status"`
@@ -47,25 +92,32 @@ type templateVariableContents struct {
 	Value string `dynamodbav:"value"`
 }
 
+type templateVariables []templateVariableContents
+
 type templateContents struct {
-	Id        string                     `dynamodbav:"id"`
-	Variables []templateVariableContents `dynamodbav:"variables"`
+	Id                 string                       `dynamodbav:"id"`
+	Version            *int                         `dynamodbav:"version,omitempty"`
+	Variables          templateVariables            `dynamodbav:"variables"`
+	RecipientVariables map[string]templateVariables `dynamodbav:"recipientVariables,omitempty"`
 }
 
 type Notification struct {
 	Id               string            `dynamodbav:"id"`
+	ListKey          string            `dynamodbav:"listKey"`
 	RawContents      *rawContents      `dynamodbav:"rawContents"`
 	TemplateContents *templateContents `dynamodbav:"templateContents"`
+	TemplateId       *string           `dynamodbav:"templateId,omitempty"`
 	CreatedBy        string            `dynamodbav:"createdBy"`
 	CreatedAt        string            `dynamodbav:"createdAt"`
 	Image            *string           `dynamodbav:"image"`
 	Topic            string            `dynamodbav:"topic"`
 	Priority         string            `dynamodbav:"priority"`
-	DistributionList *string           `dynamodbav:"distributionList"`
+	DistributionList *string           `dynamodbav:"distributionList,omitempty"`
 	Recipients       []string          `dynamodbav:"recipients"`
 	Channels         []string          `dynamodbav:"channels"`
 	Status           string            `dynamodbav:"status"`
 	ContentsType     string            `dynamodbav:"contentType"`
+	SendAt           *string           `dynamodbav:"sendAt,omitempty"`
 }
 
 type notificationSummary struct {
@@ -88,10 +140,55 @@ type recipientNotificationStatusLog struct {
 	Error          *string `dynamodbav:"errorMsg"`
 }
 
+func makeTemplateVariableContents(variables []sdto.TemplateVariableContents) templateVariables {
+	contents := make(templateVariables, 0, len(variables))
+
+	for _, v := range variables {
+		contents = append(contents, templateVariableContents{
+			Name:  v.Name,
+			Value: v.Value,
+		})
+	}
+
+	return contents
+}
+
+func (t templateVariables) toDTO() []sdto.TemplateVariableContents {
+	variables := make([]sdto.TemplateVariableContents, 0, len(t))
+
+	for _, v := range t {
+		variables = append(variables, sdto.TemplateVariableContents{
+			Name:  v.Name,
+			Value: v.Value,
+		})
+	}
+
+	return variables
+}
+
 type notificationKey struct {
 	Id string `dynamodbav:"id"`
 }
 
+// notificationIndexKey is the last evaluated key of a query on one of
+// the notification indexes, which holds the keys of the table and of
+// the queried index.
+type notificationIndexKey struct {
+	Id               string  `dynamodbav:"id" json:"id"`
+	CreatedAt        string  `dynamodbav:"createdAt" json:"createdAt"`
+	ListKey          *string `dynamodbav:"listKey,omitempty" json:"listKey,omitempty"`
+	Topic            *string `dynamodbav:"topic,omitempty" json:"topic,omitempty"`
+	Status           *string `dynamodbav:"status,omitempty" json:"status,omitempty"`
+	CreatedBy        *string `dynamodbav:"createdBy,omitempty" json:"createdBy,omitempty"`
+	TemplateId       *string `dynamodbav:"templateId,omitempty" json:"templateId,omitempty"`
+	DistributionList *string `dynamodbav:"distributionList,omitempty" json:"distributionList,omitempty"`
+}
+
+type notificationStatusLogKey struct {
+	NotificationId string `dynamodbav:"notificationId" json:"notificationId"`
+	StatusDate     string `dynamodbav:"statusDate" json:"statusDate"`
+}
+
 type recipientNotificationLatestStatusKey struct {
 	NotificationId string `dynamodbav:"notificationId"`
 	UserIdChannel  string `dynamodbav:"userId-channel"`
@@ -115,6 +212,26 @@ func (n notificationKey) GetKey() (DynamoKey, error) {
 	return Notification{Id: n.Id}.GetKey()
 }
 
+func (n notificationIndexKey) GetKey() (DynamoKey, error) {
+	key, err := attributevalue.MarshalMap(n)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make notification index key - %w", err)
+	}
+
+	return key, nil
+}
+
+func (n notificationStatusLogKey) GetKey() (DynamoKey, error) {
+	key, err := attributevalue.MarshalMap(n)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make notification status log key - %w", err)
+	}
+
+	return key, nil
+}
+
 func (n recipientNotificationLatestStatusKey) GetKey() (DynamoKey, error) {
 	key := make(DynamoKey)
 	notificationId, err := attributevalue.Marshal(n.NotificationId)
@@ -135,13 +252,56 @@ func (n recipientNotificationLatestStatusKey) GetKey() (DynamoKey, error) {
 	return key, nil
 }
 
-func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notificationReq sdto.NotificationReq) (string, error) {
+func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notificationReq sdto.NotificationReq) (sdto.NotificationCreatedResp, error) {
+
+	notification, err := r.makeNotificationItems(ctx, createdBy, notificationReq)
+
+	if err != nil {
+		return sdto.NotificationCreatedResp{}, err
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: notification.items,
+	})
+
+	if err != nil {
+		if notification.templateArchived(err, 0) {
+			return sdto.NotificationCreatedResp{}, internal.TemplateArchived{Id: *notification.templateId}
+		}
+		return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to store notification - %w", err)
+	}
+
+	return notification.created, nil
+}
+
+// notificationItems are the items written to save a notification
+type notificationItems struct {
+	items   []types.TransactWriteItem
+	created sdto.NotificationCreatedResp
+	// templateId is the template of the notification, whose condition
+	// check is the item at templateCheckIdx
+	templateId       *string
+	templateCheckIdx int
+}
+
+// templateArchived reports whether the transaction was canceled because
+// the template was archived. The offset is the number of items written
+// before the items of the notification.
+func (n notificationItems) templateArchived(err error, offset int) bool {
+	return n.templateId != nil && conditionFailedAt(err, offset+n.templateCheckIdx)
+}
+
+// makeNotificationItems makes the items of the notification, its status
+// log and, unless it's scheduled, its outbox entry, which are written in
+// the same transaction.
+func (r *Registry) makeNotificationItems(ctx context.Context, createdBy string, notificationReq sdto.NotificationReq) (notificationItems, error) {
 
 	if createdBy == "" {
-		return "", fmt.Errorf("creator id cannot be empty")
+		return notificationItems{}, fmt.Errorf("creator id cannot be empty")
 	}
 
 	id := uuid.NewString()
+	createdAt := formatRunTime(time.Now())
 
 	channels := sdto.NotificationChannel("").
 		ToStrSlice(notificationReq.Channels)
@@ -152,18 +312,52 @@ func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notif
 		contentsType = dto.Template
 	}
 
+	// The notification is sent with the latest version of the template
+	// when a version isn't requested. Archived templates can't be used.
+	if notificationReq.TemplateContents != nil {
+		templateContents := *notificationReq.TemplateContents
+
+		_, version, err := r.GetTemplateVariables(ctx, templateContents.Id, templateContents.Version)
+
+		if err != nil {
+			return notificationItems{}, fmt.Errorf("failed to get the template version - %w", err)
+		}
+
+		templateContents.Version = &version
+		notificationReq.TemplateContents = &templateContents
+	}
+
+	status := sdto.Created
+	var sendAt *string
+
+	// The send time is stored in UTC so the index can be queried with
+	// a lexicographic comparison.
+	if notificationReq.SendAt != nil {
+		sendAtTime, err := time.Parse(time.RFC3339, *notificationReq.SendAt)
+
+		if err != nil {
+			return notificationItems{}, fmt.Errorf("failed to parse send at - %w", err)
+		}
+
+		sendAtStr := sendAtTime.UTC().Format(time.RFC3339)
+		sendAt = &sendAtStr
+		status = sdto.Scheduled
+	}
+
 	notification := Notification{
 		Id:               id,
+		ListKey:          NotificationListKey(id),
 		CreatedBy:        createdBy,
-		CreatedAt:        time.Now().Format(time.RFC3339),
+		CreatedAt:        createdAt,
 		Image:            notificationReq.Image,
 		Topic:            notificationReq.Topic,
 		Priority:         string(notificationReq.Priority),
 		DistributionList: notificationReq.DistributionList,
 		Recipients:       notificationReq.Recipients,
 		Channels:         channels,
-		Status:           string(sdto.Created),
+		Status:           string(status),
 		ContentsType:     string(contentsType),
+		SendAt:           sendAt,
 	}
 
 	if notificationReq.RawContents != nil {
@@ -172,50 +366,127 @@ func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notif
 			Contents: notificationReq.RawContents.Contents,
 		}
 	} else {
-		numVariables := len(notificationReq.TemplateContents.Variables)
-		variables := make([]templateVariableContents, 0, numVariables)
+		var recipientVariables map[string]templateVariables
+
+		if len(notificationReq.TemplateContents.RecipientVariables) != 0 {
+			recipientVariables = make(map[string]templateVariables)
+		}
 
-		for _, v := range notificationReq.TemplateContents.Variables {
-			variables = append(variables, templateVariableContents{
-				Name:  v.Name,
-				Value: v.Value,
-			})
+		for userId, v := range notificationReq.TemplateContents.RecipientVariables {
+			recipientVariables[userId] = makeTemplateVariableContents(v)
 		}
 
 		notification.TemplateContents = &templateContents{
-			Id:        notificationReq.TemplateContents.Id,
-			Variables: variables,
+			Id:                 notificationReq.TemplateContents.Id,
+			Version:            notificationReq.TemplateContents.Version,
+			Variables:          makeTemplateVariableContents(notificationReq.TemplateContents.Variables),
+			RecipientVariables: recipientVariables,
 		}
+
+		notification.TemplateId = &notificationReq.TemplateContents.Id
 	}
 
 	item, err := attributevalue.MarshalMap(notification)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to marshall notification - %w", err)
+		return notificationItems{}, fmt.Errorf("failed to marshall notification - %w", err)
 	}
 
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(NotificationsTable),
-		Item:      item,
-	})
+	log := NotificationStatusLog{
+		NotificationId: id,
+		Status:         string(status),
+		StatusDate:     time.Now().Format(time.RFC3339Nano),
+	}
+
+	logItem, err := attributevalue.MarshalMap(log)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to store notification - %w", err)
+		return notificationItems{}, fmt.Errorf("failed to marshal notification status log - %w", err)
 	}
 
-	log := sdto.NotificationStatusLog{
-		NotificationId: id,
-		Status:         sdto.Created,
-		ErrorMsg:       nil,
+	transactItems := []types.TransactWriteItem{{
+		Put: &types.Put{
+			TableName: aws.String(NotificationsTable),
+			Item:      item,
+		}}, {
+		Put: &types.Put{
+			TableName: aws.String(NotificationStatusLogTable),
+			Item:      logItem,
+		}},
+	}
+
+	// Scheduled notifications are added to the outbox when the scheduler
+	// claims them
+	if status == sdto.Created {
+		outboxPut, err := makeOutboxEntryPut(sdto.NotificationMsgPayload{
+			Id:              id,
+			Hash:            internal.GetMd5Hash(id),
+			NotificationReq: notificationReq,
+		})
+
+		if err != nil {
+			return notificationItems{}, err
+		}
+
+		transactItems = append(transactItems, types.TransactWriteItem{
+			Put: outboxPut,
+		})
 	}
 
-	err = r.UpdateNotificationStatus(ctx, log)
+	saved := notificationItems{
+		items: transactItems,
+		created: sdto.NotificationCreatedResp{
+			Id:        id,
+			Status:    status,
+			CreatedAt: createdAt,
+		},
+	}
+
+	// The template could have been archived since its variables were
+	// read, and archived templates can be purged.
+	if notificationReq.TemplateContents != nil {
+		templateCheck, err := makeTemplateNotArchivedCheck(notificationReq.TemplateContents.Id)
+
+		if err != nil {
+			return notificationItems{}, err
+		}
+
+		saved.templateId = &notificationReq.TemplateContents.Id
+		saved.templateCheckIdx = len(saved.items)
+		saved.items = append(saved.items, types.TransactWriteItem{
+			ConditionCheck: templateCheck,
+		})
+	}
+
+	return saved, nil
+}
+
+func makeTemplateNotArchivedCheck(templateId string) (*types.ConditionCheck, error) {
+
+	key, err := NotificationTemplate{Id: templateId}.GetKey()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to make template key - %w", err)
+	}
+
+	cond := expression.AttributeExists(expression.Name(NotificationTemplateHashKey)).
+		And(expression.AttributeNotExists(expression.Name("archivedAt")))
+
+	expr, err := expression.NewBuilder().
+		WithCondition(cond).
+		Build()
 
 	if err != nil {
-		return id, fmt.Errorf("failed to store notification status logs - %w", err)
+		return nil, fmt.Errorf("failed to build expression - %w", err)
 	}
 
-	return id, nil
+	return &types.ConditionCheck{
+		TableName:                 aws.String(NotificationsTemplateTable),
+		Key:                       key,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}, nil
 }
 
 func (r *Registry) notificationExists(ctx context.Context, notificationId string) (bool, error) {
@@ -317,6 +588,162 @@ func (r *Registry) UpdateNotificationStatus(ctx context.Context, statusLog sdto.
 	return nil
 }
 
+func (r *Registry) ResendNotification(ctx context.Context, payload sdto.NotificationMsgPayload) error {
+
+	status, err := r.GetNotificationStatus(ctx, payload.Id)
+
+	if err != nil {
+		return err
+	}
+
+	if !registry.IsResendableStatus(status) {
+		return internal.InvalidNotificationStatus{
+			Id:     payload.Id,
+			Status: string(status),
+		}
+	}
+
+	update := expression.Set(expression.Name("status"), expression.Value(sdto.Created))
+	condEx := expression.Name("status").In(
+		expression.Value(sdto.Sent),
+		expression.Value(sdto.Failed),
+	)
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condEx).Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to make update query - %w", err)
+	}
+
+	notificationKey, err := Notification{Id: payload.Id}.GetKey()
+
+	if err != nil {
+		return err
+	}
+
+	log := NotificationStatusLog{
+		NotificationId: payload.Id,
+		Status:         string(sdto.Created),
+		StatusDate:     time.Now().Format(time.RFC3339Nano),
+	}
+
+	logItem, err := attributevalue.MarshalMap(log)
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification status log - %w", err)
+	}
+
+	outboxPut, err := makeOutboxEntryPut(payload)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{{
+			Update: &types.Update{
+				TableName:                           aws.String(NotificationsTable),
+				Key:                                 notificationKey,
+				ExpressionAttributeNames:            expr.Names(),
+				ExpressionAttributeValues:           expr.Values(),
+				UpdateExpression:                    expr.Update(),
+				ConditionExpression:                 expr.Condition(),
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+			}}, {
+			Put: &types.Put{
+				TableName: aws.String(NotificationStatusLogTable),
+				Item:      logItem,
+			}}, {
+			Put: outboxPut,
+		}},
+	})
+
+	if err != nil {
+		// The status has changed since it was checked, most likely
+		// because the notification is being resent concurrently.
+		target := &types.TransactionCanceledException{}
+		if errors.As(err, &target) {
+			return internal.InvalidNotificationStatus{
+				Id:     payload.Id,
+				Status: string(status),
+			}
+		}
+		return fmt.Errorf("failed to resend notification - %w", err)
+	}
+
+	return nil
+}
+
+// ClaimScheduledNotification moves a due notification from SCHEDULED to
+// CREATED and adds it to the outbox, so it's published by the relay. It
+// returns false if the notification isn't scheduled anymore, i.e., it was
+// canceled or claimed by another scheduler.
+func (r *Registry) ClaimScheduledNotification(ctx context.Context, payload sdto.NotificationMsgPayload) (bool, error) {
+
+	update := expression.Set(expression.Name("status"), expression.Value(sdto.Created))
+	condEx := expression.Name("status").Equal(expression.Value(sdto.Scheduled))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condEx).Build()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to make update query - %w", err)
+	}
+
+	notificationKey, err := Notification{Id: payload.Id}.GetKey()
+
+	if err != nil {
+		return false, err
+	}
+
+	log := NotificationStatusLog{
+		NotificationId: payload.Id,
+		Status:         string(sdto.Created),
+		StatusDate:     time.Now().Format(time.RFC3339Nano),
+	}
+
+	logItem, err := attributevalue.MarshalMap(log)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal notification status log - %w", err)
+	}
+
+	outboxPut, err := makeOutboxEntryPut(payload)
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{{
+			Update: &types.Update{
+				TableName:                           aws.String(NotificationsTable),
+				Key:                                 notificationKey,
+				ExpressionAttributeNames:            expr.Names(),
+				ExpressionAttributeValues:           expr.Values(),
+				UpdateExpression:                    expr.Update(),
+				ConditionExpression:                 expr.Condition(),
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+			}}, {
+			Put: &types.Put{
+				TableName: aws.String(NotificationStatusLogTable),
+				Item:      logItem,
+			}}, {
+			Put: outboxPut,
+		}},
+	})
+
+	if err != nil {
+		// The notification isn't scheduled anymore
+		target := &types.TransactionCanceledException{}
+		if errors.As(err, &target) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim notification - %w", err)
+	}
+
+	return true, nil
+}
+
 func (r *Registry) GetNotificationStatus(ctx context.Context, id string) (sdto.NotificationStatus, error) {
 
 	var status sdto.NotificationStatus
@@ -406,66 +833,220 @@ func (r *Registry) DeleteNotification(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *Registry) GetNotifications(ctx context.Context, filters sdto.PageFilter) (sdto.Page[dto.NotificationSummary], error) {
-
-	page := sdto.Page[dto.NotificationSummary]{}
+// makeNotificationsQuery picks the index that narrows the filtered
+// notifications the most. The filters that aren't part of the index
+// key are applied as filter expressions. The list shard is queried when
+// none of the filters is the hash key of an index.
+func makeNotificationsQuery(filters dto.NotificationFilters, listKey string) (string, expression.KeyConditionBuilder, *expression.ConditionBuilder, error) {
+
+	equalityFilters := []struct {
+		index   string
+		hashKey string
+		value   *string
+	}{
+		{NotificationTemplateIdx, NotificationTemplateIdxHashKey, filters.TemplateId},
+		{NotificationDistributionListIdx, NotificationDistributionListIdxHashKey, filters.DistributionList},
+		{NotificationCreatedByIdx, NotificationCreatedByIdxHashKey, filters.CreatedBy},
+		{NotificationTopicIdx, NotificationTopicIdxHashKey, filters.Topic},
+		{NotificationStatusIdx, NotificationStatusIdxHashKey, (*string)(filters.Status)},
+	}
 
-	projExp := expression.
-		ProjectionBuilder(expression.NamesList(
-			expression.Name("id"),
-			expression.Name("topic"),
-			expression.Name("createdAt"),
-			expression.Name("createdBy"),
-			expression.Name("priority"),
-			expression.Name("status"),
-			expression.Name("contentType"),
-		))
+	index := NotificationListIdx
+	keyExp := expression.
+		Key(NotificationListIdxHashKey).
+		Equal(expression.Value(listKey))
 
-	expr, err := expression.
-		NewBuilder().
-		WithProjection(projExp).
-		Build()
+	conditions := make([]expression.ConditionBuilder, 0)
+	indexSelected := false
 
-	if err != nil {
-		return page, fmt.Errorf("failed to build expression - %w", err)
-	}
+	for _, f := range equalityFilters {
+		if f.value == nil {
+			continue
+		}
 
-	pageParams, err := makePageFilters(notificationKey{}, filters)
+		if !indexSelected {
+			index = f.index
+			keyExp = expression.Key(f.hashKey).Equal(expression.Value(*f.value))
+			indexSelected = true
+			continue
+		}
 
-	if err != nil {
-		return page, fmt.Errorf("failed to make page params - %w", err)
+		conditions = append(conditions, expression.
+			Name(f.hashKey).
+			Equal(expression.Value(*f.value)))
 	}
 
-	input := &dynamodb.ScanInput{
-		TableName:                aws.String(NotificationsTable),
-		ProjectionExpression:     expr.Projection(),
-		ExpressionAttributeNames: expr.Names(),
-		Limit:                    pageParams.Limit,
-		ExclusiveStartKey:        pageParams.ExclusiveStartKey,
-	}
+	sortKey := expression.Key(NotificationCreatedAtIdxSortKey)
 
-	resp, err := r.client.Scan(ctx, input)
+	createdFrom, err := formatCreatedAtFilter(filters.CreatedFrom)
 
 	if err != nil {
-		return page, fmt.Errorf("failed to retrieve notifications - %w", err)
+		return index, keyExp, nil, err
 	}
 
-	var notificationsSummaries []notificationSummary
-	err = attributevalue.UnmarshalListOfMaps(resp.Items, &notificationsSummaries)
+	createdTo, err := formatCreatedAtFilter(filters.CreatedTo)
 
 	if err != nil {
-		return page, fmt.Errorf("failed to unmarshall the notifications list - %w", err)
+		return index, keyExp, nil, err
 	}
 
-	if len(resp.LastEvaluatedKey) != 0 {
-		key := notificationKey{}
-		encoded, err := marshalNextToken(&key, resp.LastEvaluatedKey)
+	if createdFrom != nil && createdTo != nil {
+		keyExp = keyExp.And(sortKey.Between(
+			expression.Value(*createdFrom),
+			expression.Value(*createdTo)))
+	} else if createdFrom != nil {
+		keyExp = keyExp.And(sortKey.GreaterThanEqual(
+			expression.Value(*createdFrom)))
+	} else if createdTo != nil {
+		keyExp = keyExp.And(sortKey.LessThanEqual(
+			expression.Value(*createdTo)))
+	}
 
-		if err != nil {
-			return page, fmt.Errorf("failed to encode next token - %w", err)
+	if filters.Priority != nil {
+		conditions = append(conditions, expression.
+			Name("priority").
+			Equal(expression.Value(string(*filters.Priority))))
+	}
+
+	if filters.ContentsType != nil {
+		conditions = append(conditions, expression.
+			Name("contentType").
+			Equal(expression.Value(string(*filters.ContentsType))))
+	}
+
+	if len(conditions) == 0 {
+		return index, keyExp, nil, nil
+	}
+
+	filterExp := conditions[0]
+
+	for _, c := range conditions[1:] {
+		filterExp = filterExp.And(c)
+	}
+
+	return index, keyExp, &filterExp, nil
+}
+
+// formatCreatedAtFilter converts a creation date filter to the format
+// of the stored dates, so they can be compared lexicographically.
+func formatCreatedAtFilter(createdAt *string) (*string, error) {
+
+	if createdAt == nil {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, *createdAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse creation date filter - %w", err)
+	}
+
+	formatted := formatRunTime(t)
+
+	return &formatted, nil
+}
+
+// notificationListCursor is the next token of the notifications listed
+// from the list shards. It holds where each shard left off, a nil key
+// means the shard wasn't read yet. Exhausted shards are left out.
+type notificationListCursor struct {
+	Shards map[string]*notificationIndexKey `json:"shards"`
+}
+
+// notificationListShard is a page of notifications of one list shard,
+// along with the list index key of each notification.
+type notificationListShard struct {
+	listKey          string
+	startKey         *notificationIndexKey
+	items            []map[string]types.AttributeValue
+	keys             []notificationIndexKey
+	lastEvaluatedKey DynamoKey
+}
+
+// makeNotificationListShard keeps only the list index attributes of the
+// notifications keys, so they can be used as the start key of a query.
+func makeNotificationListShard(listKey string, startKey *notificationIndexKey, resp *dynamodb.QueryOutput) (*notificationListShard, error) {
+
+	keys := make([]notificationIndexKey, 0, len(resp.Items))
+
+	for _, item := range resp.Items {
+		key := notificationIndexKey{}
+
+		if err := attributevalue.UnmarshalMap(item, &key); err != nil {
+			return nil, fmt.Errorf("failed to unmarshall notification key - %w", err)
 		}
 
-		page.NextToken = &encoded
+		keys = append(keys, notificationIndexKey{
+			Id:        key.Id,
+			CreatedAt: key.CreatedAt,
+			ListKey:   key.ListKey,
+		})
+	}
+
+	return &notificationListShard{
+		listKey:          listKey,
+		startKey:         startKey,
+		items:            resp.Items,
+		keys:             keys,
+		lastEvaluatedKey: resp.LastEvaluatedKey,
+	}, nil
+}
+
+func makeNotificationsQueryInput(filters dto.NotificationFilters, listKey string, limit *int32) (*dynamodb.QueryInput, error) {
+
+	projExp := expression.
+		ProjectionBuilder(expression.NamesList(
+			expression.Name("id"),
+			expression.Name("listKey"),
+			expression.Name("topic"),
+			expression.Name("createdAt"),
+			expression.Name("createdBy"),
+			expression.Name("priority"),
+			expression.Name("status"),
+			expression.Name("contentType"),
+		))
+
+	index, keyExp, filterExp, err := makeNotificationsQuery(filters, listKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	builder := expression.
+		NewBuilder().
+		WithKeyCondition(keyExp).
+		WithProjection(projExp)
+
+	if filterExp != nil {
+		builder = builder.WithFilter(*filterExp)
+	}
+
+	expr, err := builder.Build()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	return &dynamodb.QueryInput{
+		TableName:                 aws.String(NotificationsTable),
+		IndexName:                 aws.String(index),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward:          aws.Bool(filters.IsAscending()),
+		Limit:                     limit,
+	}, nil
+}
+
+func toNotificationSummaries(items []map[string]types.AttributeValue) ([]dto.NotificationSummary, error) {
+
+	var notificationsSummaries []notificationSummary
+	err := attributevalue.UnmarshalListOfMaps(items, &notificationsSummaries)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshall the notifications list - %w", err)
 	}
 
 	notifications := make([]dto.NotificationSummary, 0, len(notificationsSummaries))
@@ -482,6 +1063,60 @@ func (r *Registry) GetNotifications(ctx context.Context, filters sdto.PageFilter
 		})
 	}
 
+	return notifications, nil
+}
+
+func (r *Registry) GetNotifications(ctx context.Context, filters dto.NotificationFilters) (sdto.Page[dto.NotificationSummary], error) {
+
+	page := sdto.Page[dto.NotificationSummary]{}
+
+	index, _, _, err := makeNotificationsQuery(filters, "")
+
+	if err != nil {
+		return page, err
+	}
+
+	if index == NotificationListIdx {
+		return r.getListedNotifications(ctx, filters)
+	}
+
+	pageParams, err := makePageFilters(notificationIndexKey{}, filters.PageFilter)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
+	}
+
+	input, err := makeNotificationsQueryInput(filters, "", pageParams.Limit)
+
+	if err != nil {
+		return page, err
+	}
+
+	input.ExclusiveStartKey = pageParams.ExclusiveStartKey
+
+	resp, err := r.client.Query(ctx, input)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to retrieve notifications - %w", err)
+	}
+
+	notifications, err := toNotificationSummaries(resp.Items)
+
+	if err != nil {
+		return page, err
+	}
+
+	if len(resp.LastEvaluatedKey) != 0 {
+		key := notificationIndexKey{}
+		encoded, err := marshalNextToken(&key, resp.LastEvaluatedKey)
+
+		if err != nil {
+			return page, fmt.Errorf("failed to encode next token - %w", err)
+		}
+
+		page.NextToken = &encoded
+	}
+
 	page.PrevToken = filters.NextToken
 	page.ResultCount = len(notifications)
 	page.Data = notifications
@@ -489,6 +1124,165 @@ func (r *Registry) GetNotifications(ctx context.Context, filters sdto.PageFilter
 	return page, nil
 }
 
+// getListedNotifications retrieves a page of every list shard and merges
+// them by the creation date. The next token keeps the position of each
+// shard, so the shards are resumed where the merged page ended.
+func (r *Registry) getListedNotifications(ctx context.Context, filters dto.NotificationFilters) (sdto.Page[dto.NotificationSummary], error) {
+
+	page := sdto.Page[dto.NotificationSummary]{}
+
+	pageParams, err := makePageFilters(notificationIndexKey{}, sdto.PageFilter{
+		MaxResults: filters.MaxResults,
+	})
+
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
+	}
+
+	cursor := notificationListCursor{Shards: map[string]*notificationIndexKey{}}
+
+	if filters.NextToken != nil {
+		err := registry.UnmarshalKey(*filters.NextToken, &cursor)
+
+		if err != nil {
+			return page, fmt.Errorf("failed to unmarshall token - %w", err)
+		}
+	} else {
+		for _, listKey := range notificationListKeys() {
+			cursor.Shards[listKey] = nil
+		}
+	}
+
+	shards := make([]*notificationListShard, 0, len(cursor.Shards))
+
+	for _, listKey := range notificationListKeys() {
+		startKey, ok := cursor.Shards[listKey]
+
+		if !ok {
+			continue
+		}
+
+		input, err := makeNotificationsQueryInput(filters, listKey, pageParams.Limit)
+
+		if err != nil {
+			return page, err
+		}
+
+		if startKey != nil {
+			input.ExclusiveStartKey, err = startKey.GetKey()
+
+			if err != nil {
+				return page, err
+			}
+		}
+
+		resp, err := r.client.Query(ctx, input)
+
+		if err != nil {
+			return page, fmt.Errorf("failed to retrieve notifications - %w", err)
+		}
+
+		shard, err := makeNotificationListShard(listKey, startKey, resp)
+
+		if err != nil {
+			return page, err
+		}
+
+		shards = append(shards, shard)
+	}
+
+	items, consumed := mergeNotificationListShards(shards, int(*pageParams.Limit), filters.IsAscending())
+
+	next := notificationListCursor{Shards: map[string]*notificationIndexKey{}}
+
+	for i, shard := range shards {
+		n := consumed[i]
+
+		if n < len(shard.items) {
+			if n == 0 {
+				next.Shards[shard.listKey] = shard.startKey
+				continue
+			}
+
+			next.Shards[shard.listKey] = &shard.keys[n-1]
+			continue
+		}
+
+		if len(shard.lastEvaluatedKey) == 0 {
+			continue
+		}
+
+		key := notificationIndexKey{}
+
+		if err := attributevalue.UnmarshalMap(shard.lastEvaluatedKey, &key); err != nil {
+			return page, fmt.Errorf("failed to unmarshall last evaluated key - %w", err)
+		}
+
+		next.Shards[shard.listKey] = &key
+	}
+
+	if len(next.Shards) != 0 {
+		encoded, err := registry.MarshalKey(next)
+
+		if err != nil {
+			return page, fmt.Errorf("failed to encode next token - %w", err)
+		}
+
+		page.NextToken = &encoded
+	}
+
+	notifications, err := toNotificationSummaries(items)
+
+	if err != nil {
+		return page, err
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(notifications)
+	page.Data = notifications
+
+	return page, nil
+}
+
+// mergeNotificationListShards merges the sorted pages of the shards up to
+// the limit. It returns how many items of each shard were merged.
+func mergeNotificationListShards(shards []*notificationListShard, limit int, ascending bool) ([]map[string]types.AttributeValue, []int) {
+
+	consumed := make([]int, len(shards))
+	merged := make([]map[string]types.AttributeValue, 0, limit)
+
+	for len(merged) < limit {
+		next := -1
+
+		for i, shard := range shards {
+			if consumed[i] == len(shard.items) {
+				continue
+			}
+
+			if next == -1 {
+				next = i
+				continue
+			}
+
+			current := shard.keys[consumed[i]].CreatedAt
+			best := shards[next].keys[consumed[next]].CreatedAt
+
+			if (ascending && current < best) || (!ascending && current > best) {
+				next = i
+			}
+		}
+
+		if next == -1 {
+			break
+		}
+
+		merged = append(merged, shards[next].items[consumed[next]])
+		consumed[next]++
+	}
+
+	return merged, consumed
+}
+
 func (r *Registry) GetNotification(ctx context.Context, notificationId string) (dto.NotificationResp, error) {
 
 	notificationResp := dto.NotificationResp{}
@@ -532,33 +1326,88 @@ func (r *Registry) GetNotification(ctx context.Context, notificationId string) (
 		DistributionList: notification.DistributionList,
 		Recipients:       notification.Recipients,
 		Channels:         channels,
+		SendAt:           notification.SendAt,
 	}
 
+	notificationResp.Id = notification.Id
+	notificationResp.Status = sdto.NotificationStatus(notification.Status)
+	notificationResp.CreatedAt = notification.CreatedAt
+	notificationResp.CreatedBy = notification.CreatedBy
+
 	if notification.ContentsType == string(dto.Raw) {
 		notificationResp.NotificationReq.RawContents = &sdto.RawContents{
 			Title:    notification.RawContents.Title,
 			Contents: notification.RawContents.Contents,
 		}
 	} else {
-		numVariables := len(notification.TemplateContents.Variables)
-		variables := make([]sdto.TemplateVariableContents, 0, numVariables)
+		var recipientVariables map[string][]sdto.TemplateVariableContents
+
+		if len(notification.TemplateContents.RecipientVariables) != 0 {
+			recipientVariables = make(map[string][]sdto.TemplateVariableContents)
+		}
 
-		for _, v := range notification.TemplateContents.Variables {
-			variables = append(variables, sdto.TemplateVariableContents{
-				Name:  v.Name,
-				Value: v.Value,
-			})
+		for userId, v := range notification.TemplateContents.RecipientVariables {
+			recipientVariables[userId] = v.toDTO()
 		}
 
 		notificationResp.NotificationReq.TemplateContents = &sdto.TemplateContents{
-			Id:        notification.TemplateContents.Id,
-			Variables: variables,
+			Id:                 notification.TemplateContents.Id,
+			Version:            notification.TemplateContents.Version,
+			Variables:          notification.TemplateContents.Variables.toDTO(),
+			RecipientVariables: recipientVariables,
 		}
 	}
 
 	return notificationResp, nil
 }
 
+func (r *Registry) GetDueNotifications(ctx context.Context, dueBefore time.Time, maxResults int) ([]string, error) {
+
+	keyExpr := expression.KeyAnd(
+		expression.Key(NotificationStatusSendAtIdxHashKey).
+			Equal(expression.Value(sdto.Scheduled)),
+		expression.Key(NotificationStatusSendAtIdxSortKey).
+			LessThanEqual(expression.Value(dueBefore.UTC().Format(time.RFC3339))),
+	)
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(keyExpr).
+		Build()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(NotificationsTable),
+		IndexName:                 aws.String(NotificationStatusSendAtIdx),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ScanIndexForward:          aws.Bool(true),
+		Limit:                     aws.Int32(int32(maxResults)),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due notifications - %w", err)
+	}
+
+	var keys []notificationKey
+	err = attributevalue.UnmarshalListOfMaps(resp.Items, &keys)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshall due notifications - %w", err)
+	}
+
+	ids := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		ids = append(ids, k.Id)
+	}
+
+	return ids, nil
+}
+
 func makeUserIdChannel(userId, channel string) string {
 	return fmt.Sprintf("%s-%s", userId, channel)
 }
@@ -735,3 +1584,90 @@ func (r *Registry) GetRecipientNotificationStatuses(ctx context.Context, notific
 
 	return page, nil
 }
+
+func (r *Registry) GetNotificationStatusLogs(ctx context.Context, notificationId string, filters sdto.PageFilter) (sdto.Page[dto.NotificationStatusLogResp], error) {
+
+	page := sdto.Page[dto.NotificationStatusLogResp]{}
+
+	exists, err := r.notificationExists(ctx, notificationId)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to check if notification exists - %w", err)
+	}
+
+	if !exists {
+		return page, internal.EntityNotFound{
+			Id:   notificationId,
+			Type: registry.NotificationType,
+		}
+	}
+
+	keyExpr := expression.KeyEqual(
+		expression.Key(NotificationStatusLogHashKey),
+		expression.Value(notificationId))
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(keyExpr).
+		Build()
+
+	if err != nil {
+		return page, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	pageParams, err := makePageFilters(notificationStatusLogKey{}, filters)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
+	}
+
+	response, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(NotificationStatusLogTable),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ScanIndexForward:          aws.Bool(true),
+		Limit:                     pageParams.Limit,
+		ExclusiveStartKey:         pageParams.ExclusiveStartKey,
+	})
+
+	if err != nil {
+		return page, fmt.Errorf("failed to get notification status logs - %w", err)
+	}
+
+	var statusLogs []NotificationStatusLog
+	err = attributevalue.UnmarshalListOfMaps(response.Items, &statusLogs)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to unmarshall notification status logs - %w", err)
+	}
+
+	if len(response.LastEvaluatedKey) != 0 {
+		key := notificationStatusLogKey{}
+		encoded, err := marshalNextToken(&key, response.LastEvaluatedKey)
+
+		if err != nil {
+			return page, fmt.Errorf("failed to encode next token - %w", err)
+		}
+
+		page.NextToken = &encoded
+	}
+
+	logs := make([]dto.NotificationStatusLogResp, 0, len(statusLogs))
+
+	for _, l := range statusLogs {
+		logs = append(logs, dto.NotificationStatusLogResp{
+			NotificationStatusLog: sdto.NotificationStatusLog{
+				NotificationId: l.NotificationId,
+				Status:         sdto.NotificationStatus(l.Status),
+				ErrorMsg:       l.Error,
+			},
+			StatusDate: l.StatusDate,
+		})
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(logs)
+	page.Data = logs
+
+	return page, nil
+}
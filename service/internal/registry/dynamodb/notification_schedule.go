@@ -0,0 +1,546 @@
+package dynamoregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/registry"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const (
+	NotificationSchedulesTable        = "NotificationSchedules"
+	NotificationScheduleHashKey       = "id"
+	NotificationScheduleNextRunIdx    = "NextRunAtIdx"
+	NotificationScheduleNextRunIdxKey = "activeKey"
+	NotificationScheduleNextRunIdxSK  = "nextRunAt"
+	// Only the active (not paused) schedules have the synthetic key, so
+	// the index is sparse and holds just the schedules the scheduler
+	// has to look at.
+	NotificationScheduleActiveKey = "ACTIVE"
+	// The template and distribution list of the scheduled notification
+	// are kept out of the notification document so the schedules using
+	// them can be queried.
+	NotificationScheduleTemplateIdx    = "TemplateIdx"
+	NotificationScheduleTemplateIdxKey = "templateId"
+	NotificationScheduleDistListIdx    = "DistributionListIdx"
+	NotificationScheduleDistListIdxKey = "distributionList"
+)
+
+type NotificationSchedule struct {
+	Id               string  `dynamodbav:"id"`
+	Name             string  `dynamodbav:"name"`
+	CronExpression   string  `dynamodbav:"cronExpression"`
+	Timezone         string  `dynamodbav:"timezone"`
+	Notification     string  `dynamodbav:"notification"`
+	TemplateId       *string `dynamodbav:"templateId,omitempty"`
+	DistributionList *string `dynamodbav:"distributionList,omitempty"`
+	Paused           bool    `dynamodbav:"paused"`
+	ActiveKey        *string `dynamodbav:"activeKey,omitempty"`
+	NextRunAt        *string `dynamodbav:"nextRunAt,omitempty"`
+	LastRunAt        *string `dynamodbav:"lastRunAt"`
+	CreatedBy        string  `dynamodbav:"createdBy"`
+	CreatedAt        string  `dynamodbav:"createdAt"`
+	UpdatedBy        *string `dynamodbav:"updatedBy"`
+	UpdatedAt        *string `dynamodbav:"updatedAt"`
+}
+
+type notificationScheduleKey struct {
+	Id string `dynamodbav:"id" json:"id"`
+}
+
+func (ns NotificationSchedule) GetKey() (DynamoKey, error) {
+	key := make(DynamoKey)
+
+	scheduleId, err := attributevalue.Marshal(ns.Id)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make notification schedule key - %w", err)
+	}
+
+	key[NotificationScheduleHashKey] = scheduleId
+
+	return key, nil
+}
+
+func (k notificationScheduleKey) GetKey() (DynamoKey, error) {
+	return NotificationSchedule{Id: k.Id}.GetKey()
+}
+
+// scheduleTemplateId returns the template of the notification of a
+// schedule, if it uses one.
+func scheduleTemplateId(notification sdto.NotificationReq) *string {
+
+	if notification.TemplateContents == nil {
+		return nil
+	}
+
+	return &notification.TemplateContents.Id
+}
+
+// Times are stored in UTC so the index can be queried with a
+// lexicographic comparison.
+func formatRunTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (ns NotificationSchedule) toDTO() (dto.NotificationScheduleResp, error) {
+
+	schedule := dto.NotificationScheduleResp{
+		NotificationScheduleReq: dto.NotificationScheduleReq{
+			Name:           ns.Name,
+			CronExpression: ns.CronExpression,
+			Timezone:       ns.Timezone,
+		},
+		Id:        ns.Id,
+		Paused:    ns.Paused,
+		NextRunAt: ns.NextRunAt,
+		LastRunAt: ns.LastRunAt,
+		CreatedAt: ns.CreatedAt,
+		CreatedBy: ns.CreatedBy,
+		UpdatedAt: ns.UpdatedAt,
+		UpdatedBy: ns.UpdatedBy,
+	}
+
+	err := json.Unmarshal([]byte(ns.Notification), &schedule.Notification)
+
+	if err != nil {
+		return schedule, fmt.Errorf("failed to unmarshal schedule notification - %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (r *Registry) SaveSchedule(ctx context.Context, createdBy string, schedule dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+
+	id, err := uuid.NewV7()
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to generate schedule id - %w", err)
+	}
+
+	notification, err := json.Marshal(schedule.Notification)
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to marshal schedule notification - %w", err)
+	}
+
+	nextRun := formatRunTime(nextRunAt)
+
+	ns := NotificationSchedule{
+		Id:               id.String(),
+		Name:             schedule.Name,
+		CronExpression:   schedule.CronExpression,
+		Timezone:         schedule.Timezone,
+		Notification:     string(notification),
+		TemplateId:       scheduleTemplateId(schedule.Notification),
+		DistributionList: schedule.Notification.DistributionList,
+		ActiveKey:        aws.String(NotificationScheduleActiveKey),
+		NextRunAt:        &nextRun,
+		CreatedBy:        createdBy,
+		CreatedAt:        time.Now().Format(time.RFC3339),
+	}
+
+	item, err := attributevalue.MarshalMap(ns)
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to marshal schedule - %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(NotificationSchedulesTable),
+		Item:      item,
+	})
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to store schedule - %w", err)
+	}
+
+	return ns.toDTO()
+}
+
+func (r *Registry) GetSchedules(ctx context.Context, filters sdto.PageFilter) (sdto.Page[dto.NotificationScheduleSummary], error) {
+
+	page := sdto.Page[dto.NotificationScheduleSummary]{}
+
+	pageParams, err := makePageFilters(notificationScheduleKey{}, filters)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
+	}
+
+	resp, err := r.client.Scan(ctx, &dynamodb.ScanInput{
+		TableName:         aws.String(NotificationSchedulesTable),
+		Limit:             pageParams.Limit,
+		ExclusiveStartKey: pageParams.ExclusiveStartKey,
+	})
+
+	if err != nil {
+		return page, fmt.Errorf("failed to retrieve schedules - %w", err)
+	}
+
+	var schedules []NotificationSchedule
+	err = attributevalue.UnmarshalListOfMaps(resp.Items, &schedules)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to unmarshall the schedules - %w", err)
+	}
+
+	if len(resp.LastEvaluatedKey) != 0 {
+		key := notificationScheduleKey{}
+		encoded, err := marshalNextToken(&key, resp.LastEvaluatedKey)
+
+		if err != nil {
+			return page, fmt.Errorf("failed to encode next token - %w", err)
+		}
+
+		page.NextToken = &encoded
+	}
+
+	summaries := make([]dto.NotificationScheduleSummary, 0, len(schedules))
+
+	for _, s := range schedules {
+		summaries = append(summaries, dto.NotificationScheduleSummary{
+			Id:             s.Id,
+			Name:           s.Name,
+			CronExpression: s.CronExpression,
+			Timezone:       s.Timezone,
+			Paused:         s.Paused,
+			NextRunAt:      s.NextRunAt,
+			LastRunAt:      s.LastRunAt,
+		})
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(summaries)
+	page.Data = summaries
+
+	return page, nil
+}
+
+func (r *Registry) GetSchedule(ctx context.Context, scheduleId string) (dto.NotificationScheduleResp, error) {
+
+	key, err := NotificationSchedule{Id: scheduleId}.GetKey()
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, err
+	}
+
+	resp, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(NotificationSchedulesTable),
+		Key:       key,
+	})
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to retrieve schedule - %w", err)
+	}
+
+	if len(resp.Item) == 0 {
+		return dto.NotificationScheduleResp{}, internal.EntityNotFound{
+			Id:   scheduleId,
+			Type: registry.NotificationScheduleType,
+		}
+	}
+
+	var schedule NotificationSchedule
+	err = attributevalue.UnmarshalMap(resp.Item, &schedule)
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to unmarshal schedule - %w", err)
+	}
+
+	return schedule.toDTO()
+}
+
+func (r *Registry) updateSchedule(ctx context.Context, scheduleId string, update expression.UpdateBuilder) (dto.NotificationScheduleResp, error) {
+
+	condEx := expression.AttributeExists(expression.Name(NotificationScheduleHashKey))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condEx).Build()
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to make update query - %w", err)
+	}
+
+	key, err := NotificationSchedule{Id: scheduleId}.GetKey()
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, err
+	}
+
+	resp, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                           aws.String(NotificationSchedulesTable),
+		Key:                                 key,
+		ExpressionAttributeNames:            expr.Names(),
+		ExpressionAttributeValues:           expr.Values(),
+		UpdateExpression:                    expr.Update(),
+		ConditionExpression:                 expr.Condition(),
+		ReturnValues:                        types.ReturnValueAllNew,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+	})
+
+	if err != nil {
+		target := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &target) {
+			return dto.NotificationScheduleResp{}, internal.EntityNotFound{
+				Id:   scheduleId,
+				Type: registry.NotificationScheduleType,
+			}
+		}
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to update schedule - %w", err)
+	}
+
+	var schedule NotificationSchedule
+	err = attributevalue.UnmarshalMap(resp.Attributes, &schedule)
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to unmarshal schedule - %w", err)
+	}
+
+	return schedule.toDTO()
+}
+
+func (r *Registry) UpdateSchedule(ctx context.Context, scheduleId, updatedBy string, schedule dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+
+	notification, err := json.Marshal(schedule.Notification)
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to marshal schedule notification - %w", err)
+	}
+
+	update := expression.
+		Set(expression.Name("name"), expression.Value(schedule.Name)).
+		Set(expression.Name("cronExpression"), expression.Value(schedule.CronExpression)).
+		Set(expression.Name("timezone"), expression.Value(schedule.Timezone)).
+		Set(expression.Name("notification"), expression.Value(string(notification))).
+		Set(expression.Name(NotificationScheduleNextRunIdxSK), expression.Value(formatRunTime(nextRunAt))).
+		Set(expression.Name("updatedBy"), expression.Value(updatedBy)).
+		Set(expression.Name("updatedAt"), expression.Value(time.Now().Format(time.RFC3339)))
+
+	templateIdName := expression.Name(NotificationScheduleTemplateIdxKey)
+
+	if templateId := scheduleTemplateId(schedule.Notification); templateId != nil {
+		update = update.Set(templateIdName, expression.Value(*templateId))
+	} else {
+		update = update.Remove(templateIdName)
+	}
+
+	distListName := expression.Name(NotificationScheduleDistListIdxKey)
+
+	if schedule.Notification.DistributionList != nil {
+		update = update.Set(distListName, expression.Value(*schedule.Notification.DistributionList))
+	} else {
+		update = update.Remove(distListName)
+	}
+
+	return r.updateSchedule(ctx, scheduleId, update)
+}
+
+func (r *Registry) PauseSchedule(ctx context.Context, scheduleId, updatedBy string) (dto.NotificationScheduleResp, error) {
+
+	update := expression.
+		Set(expression.Name("paused"), expression.Value(true)).
+		Remove(expression.Name(NotificationScheduleNextRunIdxKey)).
+		Set(expression.Name("updatedBy"), expression.Value(updatedBy)).
+		Set(expression.Name("updatedAt"), expression.Value(time.Now().Format(time.RFC3339)))
+
+	return r.updateSchedule(ctx, scheduleId, update)
+}
+
+func (r *Registry) ResumeSchedule(ctx context.Context, scheduleId, updatedBy string, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+
+	update := expression.
+		Set(expression.Name("paused"), expression.Value(false)).
+		Set(expression.Name(NotificationScheduleNextRunIdxKey), expression.Value(NotificationScheduleActiveKey)).
+		Set(expression.Name(NotificationScheduleNextRunIdxSK), expression.Value(formatRunTime(nextRunAt))).
+		Set(expression.Name("updatedBy"), expression.Value(updatedBy)).
+		Set(expression.Name("updatedAt"), expression.Value(time.Now().Format(time.RFC3339)))
+
+	return r.updateSchedule(ctx, scheduleId, update)
+}
+
+func (r *Registry) DeleteSchedule(ctx context.Context, scheduleId string) error {
+
+	key, err := NotificationSchedule{Id: scheduleId}.GetKey()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(NotificationSchedulesTable),
+		Key:       key,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule - %w", err)
+	}
+
+	return nil
+}
+
+func (r *Registry) GetDueSchedules(ctx context.Context, dueBefore time.Time, maxResults int) ([]dto.NotificationScheduleResp, error) {
+
+	keyExpr := expression.KeyAnd(
+		expression.Key(NotificationScheduleNextRunIdxKey).
+			Equal(expression.Value(NotificationScheduleActiveKey)),
+		expression.Key(NotificationScheduleNextRunIdxSK).
+			LessThanEqual(expression.Value(formatRunTime(dueBefore))),
+	)
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(keyExpr).
+		Build()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(NotificationSchedulesTable),
+		IndexName:                 aws.String(NotificationScheduleNextRunIdx),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ScanIndexForward:          aws.Bool(true),
+		Limit:                     aws.Int32(int32(maxResults)),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due schedules - %w", err)
+	}
+
+	var items []NotificationSchedule
+	err = attributevalue.UnmarshalListOfMaps(resp.Items, &items)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshall due schedules - %w", err)
+	}
+
+	schedules := make([]dto.NotificationScheduleResp, 0, len(items))
+
+	for _, item := range items {
+		schedule, err := item.toDTO()
+
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// makeAdvanceScheduleUpdate makes the update that moves the next run of
+// an active schedule from runAt to nextRunAt, whose condition fails if
+// the schedule isn't at runAt anymore.
+func makeAdvanceScheduleUpdate(scheduleId string, runAt, nextRunAt time.Time) (*types.Update, error) {
+
+	lastRun := formatRunTime(runAt)
+
+	update := expression.
+		Set(expression.Name(NotificationScheduleNextRunIdxSK), expression.Value(formatRunTime(nextRunAt))).
+		Set(expression.Name("lastRunAt"), expression.Value(lastRun))
+
+	condEx := expression.And(
+		expression.Name(NotificationScheduleNextRunIdxSK).Equal(expression.Value(lastRun)),
+		expression.Name("paused").Equal(expression.Value(false)),
+	)
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condEx).Build()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to make update query - %w", err)
+	}
+
+	key, err := NotificationSchedule{Id: scheduleId}.GetKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Update{
+		TableName:                           aws.String(NotificationSchedulesTable),
+		Key:                                 key,
+		ExpressionAttributeNames:            expr.Names(),
+		ExpressionAttributeValues:           expr.Values(),
+		UpdateExpression:                    expr.Update(),
+		ConditionExpression:                 expr.Condition(),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+	}, nil
+}
+
+func (r *Registry) AdvanceSchedule(ctx context.Context, scheduleId string, runAt, nextRunAt time.Time) (bool, error) {
+
+	update, err := makeAdvanceScheduleUpdate(scheduleId, runAt, nextRunAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                           update.TableName,
+		Key:                                 update.Key,
+		ExpressionAttributeNames:            update.ExpressionAttributeNames,
+		ExpressionAttributeValues:           update.ExpressionAttributeValues,
+		UpdateExpression:                    update.UpdateExpression,
+		ConditionExpression:                 update.ConditionExpression,
+		ReturnValuesOnConditionCheckFailure: update.ReturnValuesOnConditionCheckFailure,
+	})
+
+	if err != nil {
+		target := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &target) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to advance schedule - %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *Registry) RunSchedule(ctx context.Context, schedule dto.NotificationScheduleResp, runAt, nextRunAt time.Time) (bool, error) {
+
+	update, err := makeAdvanceScheduleUpdate(schedule.Id, runAt, nextRunAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	notification, err := r.makeNotificationItems(ctx, schedule.CreatedBy, schedule.Notification)
+
+	if err != nil {
+		return false, err
+	}
+
+	// The update of the schedule is the first item
+	items := append([]types.TransactWriteItem{{Update: update}}, notification.items...)
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+
+	if err != nil {
+		if conditionFailedAt(err, 0) {
+			return false, nil
+		}
+		if notification.templateArchived(err, 1) {
+			return false, internal.TemplateArchived{Id: *notification.templateId}
+		}
+		return false, fmt.Errorf("failed to run schedule - %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,238 @@
+package dynamoregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/notifique/service/internal/dto"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const (
+	NotificationOutboxTable              = "NotificationOutbox"
+	NotificationOutboxHashKey            = "notificationId"
+	NotificationOutboxAvailableAtIdx     = "AvailableAtIdx"
+	NotificationOutboxAvailableAtIdxKey  = "outboxKey"
+	NotificationOutboxAvailableAtIdxSK   = "availableAt"
+	NotificationOutboxPendingKey         = "PENDING"
+	NotificationOutboxDistListIdx        = "DistributionListIdx"
+	NotificationOutboxDistListIdxKey     = "distributionList"
+	notificationOutboxAttemptsAttribute  = "attempts"
+	notificationOutboxLastErrorAttribute = "lastError"
+)
+
+type OutboxEntry struct {
+	NotificationId string  `dynamodbav:"notificationId"`
+	OutboxKey      string  `dynamodbav:"outboxKey"`
+	Payload        string  `dynamodbav:"payload"`
+	Attempts       int     `dynamodbav:"attempts"`
+	AvailableAt    string  `dynamodbav:"availableAt"`
+	LastError      *string `dynamodbav:"lastError"`
+	// DistributionList is the list of the notification, if it's sent
+	// to one, kept out of the payload so it can be indexed
+	DistributionList *string `dynamodbav:"distributionList,omitempty"`
+}
+
+func (e OutboxEntry) GetKey() (DynamoKey, error) {
+	key := make(DynamoKey)
+
+	notificationId, err := attributevalue.Marshal(e.NotificationId)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make outbox entry key - %w", err)
+	}
+
+	key[NotificationOutboxHashKey] = notificationId
+
+	return key, nil
+}
+
+func makeOutboxEntryPut(payload sdto.NotificationMsgPayload) (*types.Put, error) {
+
+	marshalled, err := json.Marshal(payload)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal outbox payload - %w", err)
+	}
+
+	entry := OutboxEntry{
+		NotificationId:   payload.Id,
+		OutboxKey:        NotificationOutboxPendingKey,
+		Payload:          string(marshalled),
+		AvailableAt:      formatRunTime(time.Now()),
+		DistributionList: payload.DistributionList,
+	}
+
+	item, err := attributevalue.MarshalMap(entry)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal outbox entry - %w", err)
+	}
+
+	return &types.Put{
+		TableName: aws.String(NotificationOutboxTable),
+		Item:      item,
+	}, nil
+}
+
+func (r *Registry) GetOutboxEntries(ctx context.Context, availableBefore time.Time, maxResults int) ([]dto.OutboxEntry, error) {
+
+	keyExpr := expression.KeyAnd(
+		expression.Key(NotificationOutboxAvailableAtIdxKey).
+			Equal(expression.Value(NotificationOutboxPendingKey)),
+		expression.Key(NotificationOutboxAvailableAtIdxSK).
+			LessThanEqual(expression.Value(formatRunTime(availableBefore))),
+	)
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(keyExpr).
+		Build()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(NotificationOutboxTable),
+		IndexName:                 aws.String(NotificationOutboxAvailableAtIdx),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ScanIndexForward:          aws.Bool(true),
+		Limit:                     aws.Int32(int32(maxResults)),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox entries - %w", err)
+	}
+
+	var items []OutboxEntry
+	err = attributevalue.UnmarshalListOfMaps(resp.Items, &items)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshall outbox entries - %w", err)
+	}
+
+	entries := make([]dto.OutboxEntry, 0, len(items))
+
+	for _, item := range items {
+		entry := dto.OutboxEntry{
+			Attempts:    item.Attempts,
+			AvailableAt: item.AvailableAt,
+			LastError:   item.LastError,
+		}
+
+		if err := json.Unmarshal([]byte(item.Payload), &entry.Payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal outbox payload - %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (r *Registry) updateOutboxEntry(ctx context.Context, notificationId string, expr expression.Expression) error {
+
+	key, err := OutboxEntry{NotificationId: notificationId}.GetKey()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                           aws.String(NotificationOutboxTable),
+		Key:                                 key,
+		ExpressionAttributeNames:            expr.Names(),
+		ExpressionAttributeValues:           expr.Values(),
+		UpdateExpression:                    expr.Update(),
+		ConditionExpression:                 expr.Condition(),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+	})
+
+	return err
+}
+
+func (r *Registry) ClaimOutboxEntry(ctx context.Context, notificationId string, attempts int, leaseUntil time.Time) (bool, error) {
+
+	update := expression.
+		Set(expression.Name(notificationOutboxAttemptsAttribute), expression.Value(attempts+1)).
+		Set(expression.Name(NotificationOutboxAvailableAtIdxSK), expression.Value(formatRunTime(leaseUntil)))
+
+	condEx := expression.Name(notificationOutboxAttemptsAttribute).
+		Equal(expression.Value(attempts))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condEx).Build()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to make update query - %w", err)
+	}
+
+	err = r.updateOutboxEntry(ctx, notificationId, expr)
+
+	if err != nil {
+		target := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &target) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim outbox entry - %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *Registry) RetryOutboxEntry(ctx context.Context, notificationId string, retryAt time.Time, errorMsg string) error {
+
+	update := expression.
+		Set(expression.Name(NotificationOutboxAvailableAtIdxSK), expression.Value(formatRunTime(retryAt))).
+		Set(expression.Name(notificationOutboxLastErrorAttribute), expression.Value(errorMsg))
+
+	condEx := expression.AttributeExists(expression.Name(NotificationOutboxHashKey))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condEx).Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to make update query - %w", err)
+	}
+
+	err = r.updateOutboxEntry(ctx, notificationId, expr)
+
+	if err != nil {
+		target := &types.ConditionalCheckFailedException{}
+		if errors.As(err, &target) {
+			return nil
+		}
+		return fmt.Errorf("failed to update outbox entry - %w", err)
+	}
+
+	return nil
+}
+
+func (r *Registry) DeleteOutboxEntry(ctx context.Context, notificationId string) error {
+
+	key, err := OutboxEntry{NotificationId: notificationId}.GetKey()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(NotificationOutboxTable),
+		Key:       key,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete outbox entry - %w", err)
+	}
+
+	return nil
+}
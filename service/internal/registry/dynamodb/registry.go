@@ -2,6 +2,7 @@ package dynamoregistry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -90,6 +91,47 @@ func MakeBatchWriteRequest[T any](table string, data []T) (BatchWriteRequest, er
 	return batchRequest, nil
 }
 
+// batchWrite sends the requests in batches of 25 items, which is the
+// limit of a batch write, retrying the items that weren't processed.
+func (r *Registry) batchWrite(ctx context.Context, table string, requests []types.WriteRequest) error {
+
+	for start := 0; start < len(requests); start += 25 {
+		end := min(start+25, len(requests))
+
+		requestItems := BatchWriteRequest{table: requests[start:end]}
+
+		for len(requestItems) != 0 {
+			resp, err := r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: requestItems,
+			})
+
+			if err != nil {
+				return fmt.Errorf("failed to write to %s - %w", table, err)
+			}
+
+			requestItems = resp.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+// conditionFailedAt reports whether the transaction was canceled because
+// the condition of its item at the index failed, as opposed to a
+// conflict with another transaction or a throttled request.
+func conditionFailedAt(err error, index int) bool {
+
+	var canceled *types.TransactionCanceledException
+
+	if !errors.As(err, &canceled) || index >= len(canceled.CancellationReasons) {
+		return false
+	}
+
+	code := canceled.CancellationReasons[index].Code
+
+	return code != nil && *code == "ConditionalCheckFailed"
+}
+
 func makeInFilter(expName string, values []string) *expression.ConditionBuilder {
 
 	if len(values) == 0 {
@@ -0,0 +1,576 @@
+package dynamoregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/registry"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const (
+	TemplatePartialsTable          = "TemplatePartials"
+	TemplatePartialHashKey         = "name"
+	TemplatePartialsNameGSI        = "TemplatePartialNameIdx"
+	TemplatePartialsNameGSIHashKey = "hashKey"
+	TemplatePartialsNameGSISortKey = "name"
+	// As with the templates, a single partition key is used so the
+	// partials are listed by name
+	TemplatePartialsSyntheticKey = "PARTIAL"
+
+	TemplatePartialVersionsTable   = "TemplatePartialVersions"
+	TemplatePartialVersionsHashKey = "partialName"
+	TemplatePartialVersionsSortKey = "version"
+)
+
+// TemplatePartial is the latest version of the partial
+type TemplatePartial struct {
+	Name        string  `dynamodbav:"name"`
+	Kind        string  `dynamodbav:"kind"`
+	Version     int     `dynamodbav:"version"`
+	Description string  `dynamodbav:"description"`
+	Contents    string  `dynamodbav:"contents"`
+	CreatedBy   string  `dynamodbav:"createdBy"`
+	CreatedAt   string  `dynamodbav:"createdAt"`
+	UpdatedAt   *string `dynamodbav:"updatedAt"`
+	UpdatedBy   *string `dynamodbav:"updatedBy"`
+	HashKey     string  `dynamodbav:"hashKey"`
+}
+
+// TemplatePartialVersion is an immutable copy of the partial, stored
+// every time the partial is created or updated.
+type TemplatePartialVersion struct {
+	PartialName string `dynamodbav:"partialName"`
+	Version     int    `dynamodbav:"version"`
+	Description string `dynamodbav:"description"`
+	Contents    string `dynamodbav:"contents"`
+	CreatedBy   string `dynamodbav:"createdBy"`
+	CreatedAt   string `dynamodbav:"createdAt"`
+}
+
+type templatePartialGSINameKey struct {
+	HashKey string `dynamodbav:"hashKey"`
+	Name    string `dynamodbav:"name"`
+}
+
+func (tp TemplatePartial) GetKey() (DynamoKey, error) {
+	key := make(DynamoKey)
+
+	name, err := attributevalue.Marshal(tp.Name)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make template partial key - %w", err)
+	}
+
+	key[TemplatePartialHashKey] = name
+
+	return key, nil
+}
+
+func (tpv TemplatePartialVersion) GetKey() (DynamoKey, error) {
+	key := make(DynamoKey)
+
+	name, err := attributevalue.Marshal(tpv.PartialName)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make template partial version key - %w", err)
+	}
+
+	version, err := attributevalue.Marshal(tpv.Version)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make template partial version key - %w", err)
+	}
+
+	key[TemplatePartialVersionsHashKey] = name
+	key[TemplatePartialVersionsSortKey] = version
+
+	return key, nil
+}
+
+func (k *templatePartialGSINameKey) GetKey() (DynamoKey, error) {
+
+	key, err := TemplatePartial{Name: k.Name}.GetKey()
+
+	if err != nil {
+		return key, err
+	}
+
+	hashKey, err := attributevalue.Marshal(k.HashKey)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make template partial key - %w", err)
+	}
+
+	key[TemplatePartialsNameGSIHashKey] = hashKey
+
+	return key, nil
+}
+
+func makeTemplatePartialNotFound(name string, version *int) internal.EntityNotFound {
+
+	if version == nil {
+		return internal.EntityNotFound{
+			Id:   name,
+			Type: registry.TemplatePartialType,
+		}
+	}
+
+	return internal.EntityNotFound{
+		Id:   fmt.Sprintf("%s (version %d)", name, *version),
+		Type: registry.TemplatePartialVersionType,
+	}
+}
+
+func toTemplatePartialDTO(tp TemplatePartial) sdto.TemplatePartialDetails {
+	return sdto.TemplatePartialDetails{
+		Name:        tp.Name,
+		Kind:        tp.Kind,
+		Version:     tp.Version,
+		Description: tp.Description,
+		Contents:    tp.Contents,
+		CreatedBy:   tp.CreatedBy,
+		CreatedAt:   tp.CreatedAt,
+		UpdatedAt:   tp.UpdatedAt,
+		UpdatedBy:   tp.UpdatedBy,
+	}
+}
+
+// writeTemplatePartial writes a new version of the partial, which
+// replaces the partial, conditioned to the partial having the previous
+// version, and stores the version.
+func (r *Registry) writeTemplatePartial(ctx context.Context, tp TemplatePartial, createdBy, createdAt string) error {
+
+	item, err := attributevalue.MarshalMap(tp)
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal template partial - %w", err)
+	}
+
+	versionItem, err := attributevalue.MarshalMap(TemplatePartialVersion{
+		PartialName: tp.Name,
+		Version:     tp.Version,
+		Description: tp.Description,
+		Contents:    tp.Contents,
+		CreatedBy:   createdBy,
+		CreatedAt:   createdAt,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal template partial version - %w", err)
+	}
+
+	cond := expression.AttributeNotExists(expression.Name(TemplatePartialHashKey))
+
+	if tp.Version > 1 {
+		cond = expression.Name("version").Equal(expression.Value(tp.Version - 1))
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{{
+			Put: &types.Put{
+				TableName:                 aws.String(TemplatePartialsTable),
+				Item:                      item,
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			}}, {
+			Put: &types.Put{
+				TableName: aws.String(TemplatePartialVersionsTable),
+				Item:      versionItem,
+			}},
+		},
+	})
+
+	return err
+}
+
+func (r *Registry) SaveTemplatePartial(ctx context.Context, createdBy string, tpr dto.TemplatePartialReq) (sdto.TemplatePartialDetails, error) {
+
+	tp := TemplatePartial{
+		Name:        tpr.Name,
+		Kind:        tpr.Kind,
+		Version:     1,
+		Description: tpr.Description,
+		Contents:    tpr.Contents,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		HashKey:     TemplatePartialsSyntheticKey,
+	}
+
+	err := r.writeTemplatePartial(ctx, tp, tp.CreatedBy, tp.CreatedAt)
+
+	var canceled *types.TransactionCanceledException
+
+	if errors.As(err, &canceled) {
+		return sdto.TemplatePartialDetails{}, internal.TemplatePartialAlreadyExists{Name: tpr.Name}
+	}
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to put template partial - %w", err)
+	}
+
+	return toTemplatePartialDTO(tp), nil
+}
+
+func (r *Registry) GetTemplatePartials(ctx context.Context, filters dto.TemplatePartialFilters) (sdto.Page[dto.TemplatePartialInfoResp], error) {
+
+	page := sdto.Page[dto.TemplatePartialInfoResp]{}
+
+	pageParams, err := makePageFilters(&templatePartialGSINameKey{}, filters.PageFilter)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
+	}
+
+	keyExp := expression.
+		Key(TemplatePartialsNameGSIHashKey).
+		Equal(expression.Value(TemplatePartialsSyntheticKey))
+
+	builder := expression.NewBuilder().WithKeyCondition(keyExp)
+
+	if filters.Kind != nil {
+		builder = builder.WithFilter(
+			expression.Name("kind").Equal(expression.Value(*filters.Kind)))
+	}
+
+	expr, err := builder.Build()
+
+	if err != nil {
+		return page, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	response, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(TemplatePartialsTable),
+		IndexName:                 aws.String(TemplatePartialsNameGSI),
+		ExclusiveStartKey:         pageParams.ExclusiveStartKey,
+		Limit:                     pageParams.Limit,
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	if err != nil {
+		return page, fmt.Errorf("failed to get template partials - %w", err)
+	}
+
+	partials := []TemplatePartial{}
+	err = attributevalue.UnmarshalListOfMaps(response.Items, &partials)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to unmarshal the template partials - %w", err)
+	}
+
+	if len(response.LastEvaluatedKey) != 0 {
+		key := templatePartialGSINameKey{}
+		encoded, err := marshalNextToken(&key, response.LastEvaluatedKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &encoded
+	}
+
+	page.Data = make([]dto.TemplatePartialInfoResp, 0, len(partials))
+
+	for _, p := range partials {
+		page.Data = append(page.Data, dto.TemplatePartialInfoResp{
+			Name:        p.Name,
+			Kind:        p.Kind,
+			Version:     p.Version,
+			Description: p.Description,
+		})
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(page.Data)
+
+	return page, nil
+}
+
+func (r *Registry) getTemplatePartial(ctx context.Context, name string) (TemplatePartial, error) {
+
+	partial := TemplatePartial{}
+
+	key, err := TemplatePartial{Name: name}.GetKey()
+
+	if err != nil {
+		return partial, fmt.Errorf("failed to create key - %w", err)
+	}
+
+	response, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(TemplatePartialsTable),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+
+	if err != nil {
+		return partial, fmt.Errorf("failed to get template partial - %w", err)
+	}
+
+	if len(response.Item) == 0 {
+		return partial, makeTemplatePartialNotFound(name, nil)
+	}
+
+	err = attributevalue.UnmarshalMap(response.Item, &partial)
+
+	if err != nil {
+		return partial, fmt.Errorf("failed to unmarshal template partial - %w", err)
+	}
+
+	return partial, nil
+}
+
+func (r *Registry) GetTemplatePartial(ctx context.Context, name string) (sdto.TemplatePartialDetails, error) {
+
+	partial, err := r.getTemplatePartial(ctx, name)
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, err
+	}
+
+	return toTemplatePartialDTO(partial), nil
+}
+
+func (r *Registry) GetTemplatePartialVersion(ctx context.Context, name string, version int) (sdto.TemplatePartialDetails, error) {
+
+	partial, err := r.getTemplatePartial(ctx, name)
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, err
+	}
+
+	key, err := TemplatePartialVersion{PartialName: name, Version: version}.GetKey()
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, err
+	}
+
+	response, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(TemplatePartialVersionsTable),
+		Key:       key,
+	})
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to get template partial version - %w", err)
+	}
+
+	if len(response.Item) == 0 {
+		return sdto.TemplatePartialDetails{}, makeTemplatePartialNotFound(name, &version)
+	}
+
+	partialVersion := TemplatePartialVersion{}
+	err = attributevalue.UnmarshalMap(response.Item, &partialVersion)
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to unmarshal template partial version - %w", err)
+	}
+
+	details := toTemplatePartialDTO(partial)
+	details.Version = partialVersion.Version
+	details.Description = partialVersion.Description
+	details.Contents = partialVersion.Contents
+	details.UpdatedAt = nil
+	details.UpdatedBy = nil
+
+	// Every version after the first one is an update of the partial
+	if partialVersion.Version > 1 {
+		details.UpdatedAt = &partialVersion.CreatedAt
+		details.UpdatedBy = &partialVersion.CreatedBy
+	}
+
+	return details, nil
+}
+
+func (r *Registry) UpdateTemplatePartial(ctx context.Context, name, updatedBy string, tpr dto.TemplatePartialUpdateReq) (sdto.TemplatePartialDetails, error) {
+
+	current, err := r.getTemplatePartial(ctx, name)
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, err
+	}
+
+	updatedAt := time.Now().Format(time.RFC3339)
+
+	tp := TemplatePartial{
+		Name:        current.Name,
+		Kind:        current.Kind,
+		Version:     current.Version + 1,
+		Description: tpr.Description,
+		Contents:    tpr.Contents,
+		CreatedBy:   current.CreatedBy,
+		CreatedAt:   current.CreatedAt,
+		UpdatedAt:   &updatedAt,
+		UpdatedBy:   &updatedBy,
+		HashKey:     TemplatePartialsSyntheticKey,
+	}
+
+	err = r.writeTemplatePartial(ctx, tp, updatedBy, updatedAt)
+
+	var canceled *types.TransactionCanceledException
+
+	if errors.As(err, &canceled) {
+		return sdto.TemplatePartialDetails{}, internal.TemplateUpdateConflict{Id: name}
+	}
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to update template partial - %w", err)
+	}
+
+	return toTemplatePartialDTO(tp), nil
+}
+
+// DeleteTemplatePartial deletes the partial and its versions, which is
+// refused while templates that aren't archived use it. Deleting a
+// partial that doesn't exist does nothing.
+func (r *Registry) DeleteTemplatePartial(ctx context.Context, name string) error {
+
+	used, err := r.isTemplatePartialUsed(ctx, name)
+
+	if err != nil {
+		return err
+	}
+
+	if used {
+		return internal.TemplatePartialInUse{Name: name}
+	}
+
+	key, err := TemplatePartial{Name: name}.GetKey()
+
+	if err != nil {
+		return fmt.Errorf("failed to make template partial key - %w", err)
+	}
+
+	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(TemplatePartialsTable),
+		Key:       key,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete template partial - %w", err)
+	}
+
+	return r.deleteTemplatePartialVersions(ctx, name)
+}
+
+// isTemplatePartialUsed checks if a template that isn't archived uses
+// the partial. The templates using it can only be found with a scan.
+func (r *Registry) isTemplatePartialUsed(ctx context.Context, name string) (bool, error) {
+
+	filterExp := expression.Contains(expression.Name("partials"), name).
+		And(expression.AttributeNotExists(expression.Name("archivedAt")))
+
+	expr, err := expression.
+		NewBuilder().
+		WithFilter(filterExp).
+		Build()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	scanPaginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName:                 aws.String(NotificationsTemplateTable),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	for scanPaginator.HasMorePages() {
+		resp, err := scanPaginator.NextPage(ctx)
+
+		if err != nil {
+			return false, fmt.Errorf("failed to scan the notification templates - %w", err)
+		}
+
+		if len(resp.Items) != 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *Registry) deleteTemplatePartialVersions(ctx context.Context, name string) error {
+
+	keyExp := expression.
+		Key(TemplatePartialVersionsHashKey).
+		Equal(expression.Value(name))
+
+	projExp := expression.NamesList(
+		expression.Name(TemplatePartialVersionsHashKey),
+		expression.Name(TemplatePartialVersionsSortKey),
+	)
+
+	expr, err := expression.
+		NewBuilder().
+		WithKeyCondition(keyExp).
+		WithProjection(projExp).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	// Pages of 25 items, which is the limit of a batch write
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(TemplatePartialVersionsTable),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     aws.Int32(25),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return fmt.Errorf("failed to query template partial versions - %w", err)
+		}
+
+		if len(resp.Items) == 0 {
+			continue
+		}
+
+		deleteReq := make([]types.WriteRequest, 0, len(resp.Items))
+
+		for _, item := range resp.Items {
+			deleteReq = append(deleteReq, types.WriteRequest{
+				DeleteRequest: &types.DeleteRequest{
+					Key: item,
+				},
+			})
+		}
+
+		_, err = r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{
+				TemplatePartialVersionsTable: deleteReq,
+			},
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to delete template partial versions - %w", err)
+		}
+	}
+
+	return nil
+}
@@ -2,13 +2,16 @@ package dynamoregistry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
 
 	"github.com/notifique/service/internal"
@@ -29,34 +32,101 @@ const (
 	// sacrificing scalability and performance by using a single partition
 	// key.
 	NotificationsTemplateSyntheticKey = "TEMPLATE"
+
+	NotificationsTemplateCategoryGSI         = "TemplateCategoryIdx"
+	NotificationsTemplateCategoryGSIHashKey  = "category"
+	NotificationsTemplateCreatedByGSI        = "TemplateCreatedByIdx"
+	NotificationsTemplateCreatedByGSIHashKey = "createdBy"
+
+	NotificationTemplateVersionsTable   = "NotificationTemplateVersions"
+	NotificationTemplateVersionsHashKey = "templateId"
+	NotificationTemplateVersionsSortKey = "version"
 )
 
 type TemplateVariable struct {
-	Name       string  `dynamodbav:"name"`
-	Type       string  `dynamodbav:"type"`
-	Required   bool    `dynamodbav:"required"`
-	Validation *string `dynamodbav:"validation"`
+	Name       string   `dynamodbav:"name"`
+	Type       string   `dynamodbav:"type"`
+	Required   bool     `dynamodbav:"required"`
+	Validation *string  `dynamodbav:"validation"`
+	Min        *float64 `dynamodbav:"min,omitempty"`
+	Max        *float64 `dynamodbav:"max,omitempty"`
+	MinLength  *int     `dynamodbav:"minLength,omitempty"`
+	MaxLength  *int     `dynamodbav:"maxLength,omitempty"`
+	Values     []string `dynamodbav:"values,omitempty"`
+}
+
+type TemplateLocalization struct {
+	Locale           string `dynamodbav:"locale"`
+	TitleTemplate    string `dynamodbav:"titleTemplate"`
+	ContentsTemplate string `dynamodbav:"contentsTemplate"`
 }
 
+// NotificationTemplate is the latest version of the template. The hash
+// key is removed when the template is archived, which removes it from
+// the name index. The SearchText is the lower case name and description
+// of the template, which are searched by the filters.
 type NotificationTemplate struct {
-	Id               string             `dynamodbav:"id"`
-	Name             string             `dynamodbav:"name"`
-	IsHtml           bool               `dynamodbav:"isHtml"`
-	TitleTemplate    string             `dynamodbav:"titleTemplate"`
-	ContentsTemplate string             `dynamodbav:"contentsTemplate"`
-	Description      string             `dynamodbav:"description"`
-	CreatedBy        string             `dynamodbav:"createdBy"`
-	CreatedAt        string             `dynamodbav:"createdAt"`
-	UpdatedAt        *string            `dynamodbav:"updatedAt"`
-	UpdatedBy        *string            `dynamodbav:"updatedBy"`
-	HashKey          string             `dynamodbav:"hashKey"`
-	Variables        []TemplateVariable `dynamodbav:"variables"`
-}
-
-type notificationTemplateGSINameKey struct {
-	Id      string `dynamodbav:"id"`
-	HashKey string `dynamodbav:"hashKey"`
-	Name    string `dynamodbav:"name"`
+	Id               string                 `dynamodbav:"id"`
+	Name             string                 `dynamodbav:"name"`
+	Version          int                    `dynamodbav:"version"`
+	IsHtml           bool                   `dynamodbav:"isHtml"`
+	Format           sdto.TemplateFormat    `dynamodbav:"format,omitempty"`
+	TitleTemplate    string                 `dynamodbav:"titleTemplate"`
+	ContentsTemplate string                 `dynamodbav:"contentsTemplate"`
+	Description      string                 `dynamodbav:"description"`
+	Category         *string                `dynamodbav:"category,omitempty"`
+	Tags             []string               `dynamodbav:"tags,omitempty"`
+	SearchText       string                 `dynamodbav:"searchText,omitempty"`
+	Layout           *string                `dynamodbav:"layout,omitempty"`
+	Partials         []string               `dynamodbav:"partials,omitempty"`
+	DefaultLocale    *string                `dynamodbav:"defaultLocale,omitempty"`
+	Localizations    []TemplateLocalization `dynamodbav:"localizations,omitempty"`
+	CreatedBy        string                 `dynamodbav:"createdBy"`
+	CreatedAt        string                 `dynamodbav:"createdAt"`
+	UpdatedAt        *string                `dynamodbav:"updatedAt"`
+	UpdatedBy        *string                `dynamodbav:"updatedBy"`
+	ArchivedAt       *string                `dynamodbav:"archivedAt,omitempty"`
+	ArchivedBy       *string                `dynamodbav:"archivedBy,omitempty"`
+	HashKey          string                 `dynamodbav:"hashKey,omitempty"`
+	Variables        []TemplateVariable     `dynamodbav:"variables"`
+}
+
+// NotificationTemplateVersion is an immutable copy of the template,
+// stored every time the template is created or updated.
+type NotificationTemplateVersion struct {
+	TemplateId       string                 `dynamodbav:"templateId"`
+	Version          int                    `dynamodbav:"version"`
+	Name             string                 `dynamodbav:"name"`
+	IsHtml           bool                   `dynamodbav:"isHtml"`
+	Format           sdto.TemplateFormat    `dynamodbav:"format,omitempty"`
+	TitleTemplate    string                 `dynamodbav:"titleTemplate"`
+	ContentsTemplate string                 `dynamodbav:"contentsTemplate"`
+	Description      string                 `dynamodbav:"description"`
+	Category         *string                `dynamodbav:"category,omitempty"`
+	Tags             []string               `dynamodbav:"tags,omitempty"`
+	Layout           *string                `dynamodbav:"layout,omitempty"`
+	Partials         []string               `dynamodbav:"partials,omitempty"`
+	DefaultLocale    *string                `dynamodbav:"defaultLocale,omitempty"`
+	Localizations    []TemplateLocalization `dynamodbav:"localizations,omitempty"`
+	CreatedBy        string                 `dynamodbav:"createdBy"`
+	CreatedAt        string                 `dynamodbav:"createdAt"`
+	Variables        []TemplateVariable     `dynamodbav:"variables"`
+}
+
+type notificationTemplateVersionKey struct {
+	TemplateId string `dynamodbav:"templateId"`
+	Version    int    `dynamodbav:"version"`
+}
+
+// notificationTemplateIndexKey is the last evaluated key of a query on
+// one of the template indexes, which holds the keys of the table and of
+// the queried index.
+type notificationTemplateIndexKey struct {
+	Id        string  `dynamodbav:"id" json:"id"`
+	Name      string  `dynamodbav:"name" json:"name"`
+	HashKey   *string `dynamodbav:"hashKey,omitempty" json:"hashKey,omitempty"`
+	Category  *string `dynamodbav:"category,omitempty" json:"category,omitempty"`
+	CreatedBy *string `dynamodbav:"createdBy,omitempty" json:"createdBy,omitempty"`
 }
 
 func (nt NotificationTemplate) GetKey() (DynamoKey, error) {
@@ -73,40 +143,268 @@ func (nt NotificationTemplate) GetKey() (DynamoKey, error) {
 	return key, nil
 }
 
-func (nt NotificationTemplate) GetGSINameKey() (DynamoKey, error) {
-	key, err := nt.GetKey()
+func (ntv NotificationTemplateVersion) GetKey() (DynamoKey, error) {
+	key := make(DynamoKey)
+
+	templateId, err := attributevalue.Marshal(ntv.TemplateId)
 
 	if err != nil {
-		return key, fmt.Errorf("failed to make notification template key - %w", err)
+		return key, fmt.Errorf("failed to make notification template version key - %w", err)
 	}
 
-	name, err := attributevalue.Marshal(nt.Name)
+	version, err := attributevalue.Marshal(ntv.Version)
 
 	if err != nil {
-		return key, fmt.Errorf("failed to make notification template key - %w", err)
+		return key, fmt.Errorf("failed to make notification template version key - %w", err)
 	}
 
-	hashKey, err := attributevalue.Marshal(nt.HashKey)
+	key[NotificationTemplateVersionsHashKey] = templateId
+	key[NotificationTemplateVersionsSortKey] = version
 
-	if err != nil {
-		return key, fmt.Errorf("failed to make notification template key - %w", err)
+	return key, nil
+}
+
+func (k *notificationTemplateVersionKey) GetKey() (DynamoKey, error) {
+
+	ntv := NotificationTemplateVersion{
+		TemplateId: k.TemplateId,
+		Version:    k.Version,
 	}
 
-	key["name"] = name
-	key["hashKey"] = hashKey
+	return ntv.GetKey()
+}
+
+func (k notificationTemplateIndexKey) GetKey() (DynamoKey, error) {
+	key, err := attributevalue.MarshalMap(k)
+
+	if err != nil {
+		return key, fmt.Errorf("failed to make notification template index key - %w", err)
+	}
 
 	return key, nil
 }
 
-func (ntsk *notificationTemplateGSINameKey) GetKey() (DynamoKey, error) {
+func makeTemplateVariables(variables []sdto.TemplateVariable) []TemplateVariable {
 
-	nt := NotificationTemplate{
-		Id:      ntsk.Id,
-		Name:    ntsk.Name,
-		HashKey: ntsk.HashKey,
+	templateVariables := make([]TemplateVariable, 0, len(variables))
+
+	for _, v := range variables {
+		templateVariables = append(templateVariables, TemplateVariable{
+			Name:       v.Name,
+			Type:       v.Type,
+			Required:   v.Required,
+			Validation: v.Validation,
+			Min:        v.Min,
+			Max:        v.Max,
+			MinLength:  v.MinLength,
+			MaxLength:  v.MaxLength,
+			Values:     v.Values,
+		})
+	}
+
+	return templateVariables
+}
+
+func toTemplateVariablesDTO(templateVariables []TemplateVariable) []sdto.TemplateVariable {
+
+	variables := make([]sdto.TemplateVariable, 0, len(templateVariables))
+
+	for _, v := range templateVariables {
+		variables = append(variables, sdto.TemplateVariable{
+			Name:       v.Name,
+			Type:       v.Type,
+			Required:   v.Required,
+			Validation: v.Validation,
+			Min:        v.Min,
+			Max:        v.Max,
+			MinLength:  v.MinLength,
+			MaxLength:  v.MaxLength,
+			Values:     v.Values,
+		})
+	}
+
+	return variables
+}
+
+func makeTemplateLocalizations(localizations []sdto.TemplateLocalization) []TemplateLocalization {
+
+	templateLocalizations := make([]TemplateLocalization, 0, len(localizations))
+
+	for _, l := range localizations {
+		templateLocalizations = append(templateLocalizations, TemplateLocalization{
+			Locale:           l.Locale,
+			TitleTemplate:    l.TitleTemplate,
+			ContentsTemplate: l.ContentsTemplate,
+		})
+	}
+
+	return templateLocalizations
+}
+
+func toTemplateLocalizationsDTO(templateLocalizations []TemplateLocalization) []sdto.TemplateLocalization {
+
+	if len(templateLocalizations) == 0 {
+		return nil
+	}
+
+	localizations := make([]sdto.TemplateLocalization, 0, len(templateLocalizations))
+
+	for _, l := range templateLocalizations {
+		localizations = append(localizations, sdto.TemplateLocalization{
+			Locale:           l.Locale,
+			TitleTemplate:    l.TitleTemplate,
+			ContentsTemplate: l.ContentsTemplate,
+		})
+	}
+
+	return localizations
+}
+
+func makeTemplateVersion(nt NotificationTemplate, createdBy, createdAt string) NotificationTemplateVersion {
+	return NotificationTemplateVersion{
+		TemplateId:       nt.Id,
+		Version:          nt.Version,
+		Name:             nt.Name,
+		IsHtml:           nt.IsHtml,
+		Format:           nt.Format,
+		TitleTemplate:    nt.TitleTemplate,
+		ContentsTemplate: nt.ContentsTemplate,
+		Description:      nt.Description,
+		Category:         nt.Category,
+		Tags:             nt.Tags,
+		Layout:           nt.Layout,
+		Partials:         nt.Partials,
+		DefaultLocale:    nt.DefaultLocale,
+		Localizations:    nt.Localizations,
+		CreatedBy:        createdBy,
+		CreatedAt:        createdAt,
+		Variables:        nt.Variables,
+	}
+}
+
+func makeTemplateNotFound(templateId string, version *int) internal.EntityNotFound {
+
+	if version == nil {
+		return internal.EntityNotFound{
+			Id:   templateId,
+			Type: registry.NotificationTemplateType,
+		}
+	}
+
+	return internal.EntityNotFound{
+		Id:   fmt.Sprintf("%s (version %d)", templateId, *version),
+		Type: registry.NotificationTemplateVersionType,
+	}
+}
+
+// makeTemplateWrite makes the writes of a new version of the template,
+// which replaces the template, conditioned to the template having the
+// previous version and not being archived, and stores the version.
+func makeTemplateWrite(nt NotificationTemplate, createdBy, createdAt string) ([]types.TransactWriteItem, error) {
+
+	item, err := attributevalue.MarshalMap(nt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification template - %w", err)
+	}
+
+	versionItem, err := attributevalue.MarshalMap(makeTemplateVersion(nt, createdBy, createdAt))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification template version - %w", err)
+	}
+
+	cond := expression.AttributeNotExists(expression.Name(NotificationTemplateHashKey))
+
+	if nt.Version > 1 {
+		cond = expression.Name("version").Equal(expression.Value(nt.Version - 1)).
+			And(expression.AttributeNotExists(expression.Name("archivedAt")))
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	return []types.TransactWriteItem{{
+		Put: &types.Put{
+			TableName:                 aws.String(NotificationsTemplateTable),
+			Item:                      item,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}}, {
+		Put: &types.Put{
+			TableName: aws.String(NotificationTemplateVersionsTable),
+			Item:      versionItem,
+		}},
+	}, nil
+}
+
+func (r *Registry) getTemplate(ctx context.Context, templateId string) (NotificationTemplate, error) {
+
+	template := NotificationTemplate{}
+
+	key, err := NotificationTemplate{Id: templateId}.GetKey()
+
+	if err != nil {
+		return template, fmt.Errorf("failed to create key - %w", err)
+	}
+
+	response, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(NotificationsTemplateTable),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+
+	if err != nil {
+		return template, fmt.Errorf("failed to get template - %w", err)
+	}
+
+	if len(response.Item) == 0 {
+		return template, makeTemplateNotFound(templateId, nil)
+	}
+
+	err = attributevalue.UnmarshalMap(response.Item, &template)
+
+	if err != nil {
+		return template, fmt.Errorf("failed to unmarshal template - %w", err)
 	}
 
-	return nt.GetGSINameKey()
+	return template, nil
+}
+
+func (r *Registry) getTemplateVersion(ctx context.Context, templateId string, version int) (NotificationTemplateVersion, error) {
+
+	templateVersion := NotificationTemplateVersion{}
+
+	key, err := NotificationTemplateVersion{TemplateId: templateId, Version: version}.GetKey()
+
+	if err != nil {
+		return templateVersion, err
+	}
+
+	response, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(NotificationTemplateVersionsTable),
+		Key:       key,
+	})
+
+	if err != nil {
+		return templateVersion, fmt.Errorf("failed to get template version - %w", err)
+	}
+
+	if len(response.Item) == 0 {
+		return templateVersion, makeTemplateNotFound(templateId, &version)
+	}
+
+	err = attributevalue.UnmarshalMap(response.Item, &templateVersion)
+
+	if err != nil {
+		return templateVersion, fmt.Errorf("failed to unmarshal template version - %w", err)
+	}
+
+	return templateVersion, nil
 }
 
 func (r *Registry) SaveTemplate(ctx context.Context, createdBy string, ntr dto.NotificationTemplateReq) (dto.NotificationTemplateCreatedResp, error) {
@@ -122,35 +420,33 @@ func (r *Registry) SaveTemplate(ctx context.Context, createdBy string, ntr dto.N
 	nt := NotificationTemplate{
 		Id:               id.String(),
 		Name:             ntr.Name,
+		Version:          1,
 		IsHtml:           ntr.IsHtml,
+		Format:           ntr.Format,
 		TitleTemplate:    ntr.TitleTemplate,
 		ContentsTemplate: ntr.ContentsTemplate,
 		Description:      ntr.Description,
+		Category:         ntr.Category,
+		Tags:             ntr.Tags,
+		SearchText:       makeTemplateSearchText(ntr),
+		Layout:           ntr.Layout,
+		Partials:         ntr.Partials,
+		DefaultLocale:    ntr.DefaultLocale,
+		Localizations:    makeTemplateLocalizations(ntr.Localizations),
 		HashKey:          NotificationsTemplateSyntheticKey,
 		CreatedBy:        createdBy,
-		CreatedAt:        time.Now().Format(time.RFC3339),
+		CreatedAt:        formatRunTime(time.Now()),
+		Variables:        makeTemplateVariables(ntr.Variables),
 	}
 
-	nt.Variables = make([]TemplateVariable, 0, len(ntr.Variables))
-
-	for _, v := range ntr.Variables {
-		nt.Variables = append(nt.Variables, TemplateVariable{
-			Name:       v.Name,
-			Type:       v.Type,
-			Required:   v.Required,
-			Validation: v.Validation,
-		})
-	}
-
-	item, err := attributevalue.MarshalMap(nt)
+	transactItems, err := makeTemplateWrite(nt, nt.CreatedBy, nt.CreatedAt)
 
 	if err != nil {
-		return resp, fmt.Errorf("failed to marshal notification template - %w", err)
+		return resp, err
 	}
 
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(NotificationsTemplateTable),
-		Item:      item,
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
 	})
 
 	if err != nil {
@@ -164,32 +460,150 @@ func (r *Registry) SaveTemplate(ctx context.Context, createdBy string, ntr dto.N
 	return resp, nil
 }
 
+// makeTemplateSearchText makes the text matched by the search filter
+func makeTemplateSearchText(ntr dto.NotificationTemplateReq) string {
+	return strings.ToLower(fmt.Sprintf("%s %s", ntr.Name, ntr.Description))
+}
+
+// makeTemplatesQuery picks the index that narrows the filtered templates
+// the most, which are sorted by name in all of them. The filters that
+// aren't part of the index key are applied as filter expressions.
+func makeTemplatesQuery(filters dto.NotificationTemplateFilters) (string, expression.KeyConditionBuilder, *expression.ConditionBuilder, error) {
+
+	index := NotificationsTemplateNameGSI
+	keyExp := expression.
+		Key(NotificationsTemplateNameGSIHashKey).
+		Equal(expression.Value(NotificationsTemplateSyntheticKey))
+
+	conditions := make([]expression.ConditionBuilder, 0)
+
+	// Only the name index leaves the archived templates out
+	notArchived := expression.AttributeExists(expression.Name(NotificationsTemplateNameGSIHashKey))
+
+	if filters.Category != nil {
+		index = NotificationsTemplateCategoryGSI
+		keyExp = expression.
+			Key(NotificationsTemplateCategoryGSIHashKey).
+			Equal(expression.Value(*filters.Category))
+		conditions = append(conditions, notArchived)
+	} else if filters.CreatedBy != nil {
+		index = NotificationsTemplateCreatedByGSI
+		keyExp = expression.
+			Key(NotificationsTemplateCreatedByGSIHashKey).
+			Equal(expression.Value(*filters.CreatedBy))
+		conditions = append(conditions, notArchived)
+	}
+
+	if filters.Category != nil && filters.CreatedBy != nil {
+		conditions = append(conditions, expression.
+			Name(NotificationsTemplateCreatedByGSIHashKey).
+			Equal(expression.Value(*filters.CreatedBy)))
+	}
+
+	if filters.TemplateName != nil {
+		keyExp = keyExp.And(expression.
+			Key(NotificationsTemplateNameGSISortKey).
+			BeginsWith(*filters.TemplateName))
+	}
+
+	for _, tag := range filters.Tags {
+		conditions = append(conditions, expression.
+			Name("tags").
+			Contains(tag))
+	}
+
+	if filters.Format != nil {
+		format := expression.Name("format").Equal(expression.Value(string(*filters.Format)))
+
+		// The templates created before the formats only have isHtml
+		if *filters.Format != sdto.Markdown {
+			legacy := expression.AttributeNotExists(expression.Name("format")).
+				And(expression.Name("isHtml").Equal(expression.Value(*filters.Format == sdto.Html)))
+			format = format.Or(legacy)
+		}
+
+		conditions = append(conditions, format)
+	}
+
+	dateFilters := []struct {
+		name string
+		from *string
+		to   *string
+	}{
+		{"createdAt", filters.CreatedFrom, filters.CreatedTo},
+		{"updatedAt", filters.UpdatedFrom, filters.UpdatedTo},
+	}
+
+	for _, f := range dateFilters {
+		from, err := formatCreatedAtFilter(f.from)
+
+		if err != nil {
+			return index, keyExp, nil, err
+		}
+
+		to, err := formatCreatedAtFilter(f.to)
+
+		if err != nil {
+			return index, keyExp, nil, err
+		}
+
+		if from != nil {
+			conditions = append(conditions, expression.
+				Name(f.name).
+				GreaterThanEqual(expression.Value(*from)))
+		}
+
+		if to != nil {
+			conditions = append(conditions, expression.
+				Name(f.name).
+				LessThanEqual(expression.Value(*to)))
+		}
+	}
+
+	if filters.Search != nil {
+		conditions = append(conditions, expression.
+			Name("searchText").
+			Contains(strings.ToLower(*filters.Search)))
+	}
+
+	if len(conditions) == 0 {
+		return index, keyExp, nil, nil
+	}
+
+	filterExp := conditions[0]
+
+	for _, c := range conditions[1:] {
+		filterExp = filterExp.And(c)
+	}
+
+	return index, keyExp, &filterExp, nil
+}
+
 func (r *Registry) GetTemplates(ctx context.Context, filters dto.NotificationTemplateFilters) (sdto.Page[dto.NotificationTemplateInfoResp], error) {
 
 	page := sdto.Page[dto.NotificationTemplateInfoResp]{}
 
-	pageParams, err := makePageFilters(&notificationTemplateGSINameKey{}, filters.PageFilter)
+	pageParams, err := makePageFilters(notificationTemplateIndexKey{}, filters.PageFilter)
 
 	if err != nil {
 		return page, fmt.Errorf("failed to make page params - %w", err)
 	}
 
-	keyExp := expression.
-		Key(NotificationsTemplateNameGSIHashKey).
-		Equal(expression.Value(NotificationsTemplateSyntheticKey))
-
-	if filters.TemplateName != nil {
-		sortKeyExp := expression.
-			Key(NotificationsTemplateNameGSISortKey).
-			BeginsWith(*filters.TemplateName)
+	index, keyExp, filterExp, err := makeTemplatesQuery(filters)
 
-		keyExp = keyExp.And(sortKeyExp)
+	if err != nil {
+		return page, err
 	}
 
-	expr, err := expression.
+	builder := expression.
 		NewBuilder().
-		WithKeyCondition(keyExp).
-		Build()
+		WithKeyCondition(keyExp)
+
+	if filterExp != nil {
+		builder = builder.WithFilter(*filterExp)
+	}
+
+	expr, err := builder.Build()
 
 	if err != nil {
 		return page, fmt.Errorf("failed to build expression - %w", err)
@@ -197,10 +611,11 @@ func (r *Registry) GetTemplates(ctx context.Context, filters dto.NotificationTem
 
 	queryInput := dynamodb.QueryInput{
 		TableName:                 aws.String(NotificationsTemplateTable),
-		IndexName:                 aws.String(NotificationsTemplateNameGSI),
+		IndexName:                 aws.String(index),
 		ExclusiveStartKey:         pageParams.ExclusiveStartKey,
 		Limit:                     pageParams.Limit,
 		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 	}
@@ -221,7 +636,7 @@ func (r *Registry) GetTemplates(ctx context.Context, filters dto.NotificationTem
 	var nextToken *string = nil
 
 	if len(response.LastEvaluatedKey) != 0 {
-		key := notificationTemplateGSINameKey{}
+		key := notificationTemplateIndexKey{}
 		encoded, err := marshalNextToken(&key, response.LastEvaluatedKey)
 
 		if err != nil {
@@ -234,10 +649,22 @@ func (r *Registry) GetTemplates(ctx context.Context, filters dto.NotificationTem
 	items := make([]dto.NotificationTemplateInfoResp, 0, len(templates))
 
 	for _, t := range templates {
+		format := t.Format
+
+		if format == "" {
+			format = sdto.LegacyFormat(t.IsHtml)
+		}
+
 		items = append(items, dto.NotificationTemplateInfoResp{
 			Id:          t.Id,
 			Name:        t.Name,
 			Description: t.Description,
+			Format:      format,
+			Category:    t.Category,
+			Tags:        t.Tags,
+			CreatedBy:   t.CreatedBy,
+			CreatedAt:   t.CreatedAt,
+			UpdatedAt:   t.UpdatedAt,
 		})
 	}
 
@@ -268,10 +695,7 @@ func (r *Registry) GetTemplateDetails(ctx context.Context, templateId string) (s
 	}
 
 	if len(response.Item) == 0 {
-		return details, internal.EntityNotFound{
-			Id:   templateId,
-			Type: registry.NotificationTemplateType,
-		}
+		return details, makeTemplateNotFound(templateId, nil)
 	}
 
 	template := NotificationTemplate{}
@@ -281,63 +705,295 @@ func (r *Registry) GetTemplateDetails(ctx context.Context, templateId string) (s
 		return details, fmt.Errorf("failed to unmarshal template - %w", err)
 	}
 
-	variables := make([]sdto.TemplateVariable, 0, len(template.Variables))
-	for _, v := range template.Variables {
-		variables = append(variables, sdto.TemplateVariable{
-			Name:       v.Name,
-			Type:       v.Type,
-			Required:   v.Required,
-			Validation: v.Validation,
-		})
-	}
-
 	details.Id = template.Id
 	details.Name = template.Name
+	details.Version = template.Version
 	details.IsHtml = template.IsHtml
+	details.Format = template.Format
+
+	// The templates created before the formats only have isHtml
+	if details.Format == "" {
+		details.Format = sdto.LegacyFormat(template.IsHtml)
+	}
+
 	details.Description = template.Description
+	details.Category = template.Category
+	details.Tags = template.Tags
 	details.TitleTemplate = template.TitleTemplate
 	details.ContentsTemplate = template.ContentsTemplate
-	details.Variables = variables
+	details.Layout = template.Layout
+	details.DefaultLocale = template.DefaultLocale
+	details.Localizations = toTemplateLocalizationsDTO(template.Localizations)
+	details.Variables = toTemplateVariablesDTO(template.Variables)
 	details.CreatedAt = template.CreatedAt
 	details.CreatedBy = template.CreatedBy
 	details.UpdatedAt = template.UpdatedAt
 	details.UpdatedBy = template.UpdatedBy
+	details.ArchivedAt = template.ArchivedAt
+	details.ArchivedBy = template.ArchivedBy
 
 	return details, nil
 }
 
-func (r *Registry) DeleteTemplate(ctx context.Context, id string) error {
+// ArchiveTemplate hides the template and prevents new notifications
+// from using it, keeping the notifications that already used it.
+// Archiving a template that doesn't exist or is already archived does
+// nothing.
+func (r *Registry) ArchiveTemplate(ctx context.Context, templateId, archivedBy string) error {
+
+	key, err := NotificationTemplate{Id: templateId}.GetKey()
+
+	if err != nil {
+		return fmt.Errorf("failed to make template key - %w", err)
+	}
+
+	archivedAt := time.Now().Format(time.RFC3339)
+
+	updateExpr := expression.
+		Set(expression.Name("archivedAt"), expression.Value(archivedAt)).
+		Set(expression.Name("archivedBy"), expression.Value(archivedBy)).
+		Remove(expression.Name(NotificationsTemplateNameGSIHashKey))
+
+	cond := expression.AttributeExists(expression.Name(NotificationTemplateHashKey)).
+		And(expression.AttributeNotExists(expression.Name("archivedAt")))
+
+	expr, err := expression.NewBuilder().
+		WithUpdate(updateExpr).
+		WithCondition(cond).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(NotificationsTemplateTable),
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	if err != nil {
+		target := &types.ConditionalCheckFailedException{}
+
+		if errors.As(err, &target) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to archive template - %w", err)
+	}
+
+	return nil
+}
+
+// PurgeTemplate permanently deletes the template and its versions,
+// which is refused while notifications or schedules reference it. The
+// template must be archived before the references are checked, as the
+// notifications are saved with a condition that their template isn't
+// archived, so no notification can start using it afterwards. The
+// references are looked up in indexes that are eventually consistent,
+// so a notification saved right before the template was archived might
+// not be found yet. Unlike Postgres, which locks the template, that gap
+// is only as long as the propagation of the indexes.
+func (r *Registry) PurgeTemplate(ctx context.Context, templateId string) error {
+
+	template, err := r.getTemplate(ctx, templateId)
+
+	// Purging a template that doesn't exist does nothing
+	if errors.As(err, &internal.EntityNotFound{}) {
+		return nil
+	}
 
-	key, err := NotificationTemplate{Id: id}.GetKey()
+	if err != nil {
+		return err
+	}
+
+	if template.ArchivedAt == nil {
+		return internal.TemplateNotArchived{Id: templateId}
+	}
+
+	referenced, err := r.isTemplateReferenced(ctx, templateId)
+
+	if err != nil {
+		return err
+	}
+
+	if referenced {
+		return internal.TemplateInUse{Id: templateId}
+	}
+
+	key, err := NotificationTemplate{Id: templateId}.GetKey()
 
 	if err != nil {
 		return fmt.Errorf("failed to make template key - %w", err)
 	}
 
+	// Only archived templates are deleted, the template could also
+	// have been purged concurrently since it was read
+	cond := expression.AttributeNotExists(expression.Name(NotificationTemplateHashKey)).
+		Or(expression.AttributeExists(expression.Name("archivedAt")))
+
+	expr, err := expression.NewBuilder().
+		WithCondition(cond).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
 	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(NotificationsTemplateTable),
-		Key:       key,
+		TableName:                 aws.String(NotificationsTemplateTable),
+		Key:                       key,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
 	})
 
 	if err != nil {
+		target := &types.ConditionalCheckFailedException{}
+
+		if errors.As(err, &target) {
+			return internal.TemplateNotArchived{Id: templateId}
+		}
+
 		return fmt.Errorf("failed to delete template - %w", err)
 	}
 
-	return nil
+	return r.deleteTemplateVersions(ctx, templateId)
 }
 
-func (r *Registry) GetTemplateVariables(ctx context.Context, templateId string) ([]sdto.TemplateVariable, error) {
+// isTemplateReferenced checks if a notification was sent or a schedule
+// sends notifications with the template.
+func (r *Registry) isTemplateReferenced(ctx context.Context, templateId string) (bool, error) {
+
+	references := []struct {
+		table   string
+		index   string
+		hashKey string
+	}{
+		{NotificationsTable, NotificationTemplateIdx, NotificationTemplateIdxHashKey},
+		{NotificationSchedulesTable, NotificationScheduleTemplateIdx, NotificationScheduleTemplateIdxKey},
+	}
+
+	for _, ref := range references {
+		keyExp := expression.
+			Key(ref.hashKey).
+			Equal(expression.Value(templateId))
 
-	variables := []sdto.TemplateVariable{}
+		expr, err := expression.
+			NewBuilder().
+			WithKeyCondition(keyExp).
+			Build()
+
+		if err != nil {
+			return false, fmt.Errorf("failed to build expression - %w", err)
+		}
+
+		resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:                 aws.String(ref.table),
+			IndexName:                 aws.String(ref.index),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			Limit:                     aws.Int32(1),
+		})
+
+		if err != nil {
+			return false, fmt.Errorf("failed to query the references of the template - %w", err)
+		}
+
+		if len(resp.Items) != 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *Registry) deleteTemplateVersions(ctx context.Context, templateId string) error {
+
+	keyExp := expression.
+		Key(NotificationTemplateVersionsHashKey).
+		Equal(expression.Value(templateId))
+
+	projExp := expression.NamesList(
+		expression.Name(NotificationTemplateVersionsHashKey),
+		expression.Name(NotificationTemplateVersionsSortKey),
+	)
+
+	expr, err := expression.
+		NewBuilder().
+		WithKeyCondition(keyExp).
+		WithProjection(projExp).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	// Pages of 25 items, which is the limit of a batch write
+	queryPaginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(NotificationTemplateVersionsTable),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     aws.Int32(25),
+	})
+
+	for queryPaginator.HasMorePages() {
+		resp, err := queryPaginator.NextPage(ctx)
+
+		if err != nil {
+			return fmt.Errorf("failed to query template versions - %w", err)
+		}
+
+		if len(resp.Items) == 0 {
+			continue
+		}
+
+		deleteReq := make([]types.WriteRequest, 0, len(resp.Items))
+
+		for _, item := range resp.Items {
+			deleteReq = append(deleteReq, types.WriteRequest{
+				DeleteRequest: &types.DeleteRequest{
+					Key: item,
+				},
+			})
+		}
+
+		_, err = r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{
+				NotificationTemplateVersionsTable: deleteReq,
+			},
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to delete template versions - %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetTemplateVariables returns the variables of the version of the
+// template, or of its latest version when the version is nil, along
+// with the version they belong to.
+func (r *Registry) GetTemplateVariables(ctx context.Context, templateId string, version *int) ([]sdto.TemplateVariable, int, error) {
 
 	key, err := NotificationTemplate{Id: templateId}.GetKey()
 
 	if err != nil {
-		return variables, fmt.Errorf("failed to make notification template key - %w", err)
+		return []sdto.TemplateVariable{}, 0, fmt.Errorf("failed to make notification template key - %w", err)
 	}
 
-	projExp := expression.
-		ProjectionBuilder(expression.NamesList(expression.Name("variables")))
+	projExp := expression.ProjectionBuilder(expression.NamesList(
+		expression.Name("variables"),
+		expression.Name("version"),
+		expression.Name("archivedAt"),
+	))
 
 	expr, err := expression.
 		NewBuilder().
@@ -345,7 +1001,7 @@ func (r *Registry) GetTemplateVariables(ctx context.Context, templateId string)
 		Build()
 
 	if err != nil {
-		return variables, fmt.Errorf("failed to build expression - %w", err)
+		return []sdto.TemplateVariable{}, 0, fmt.Errorf("failed to build expression - %w", err)
 	}
 
 	resp, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
@@ -356,36 +1012,253 @@ func (r *Registry) GetTemplateVariables(ctx context.Context, templateId string)
 	})
 
 	if err != nil {
-		return variables, fmt.Errorf("failed to retrieve the notification template variables - %w", err)
+		return []sdto.TemplateVariable{}, 0, fmt.Errorf("failed to retrieve the notification template variables - %w", err)
 	}
 
 	if len(resp.Item) == 0 {
-		return variables, internal.EntityNotFound{
-			Id:   templateId,
-			Type: registry.NotificationTemplateType,
-		}
+		return []sdto.TemplateVariable{}, 0, makeTemplateNotFound(templateId, nil)
 	}
 
 	tmp := struct {
-		Variables []TemplateVariable `dynamodbav:"variables"`
+		Variables  []TemplateVariable `dynamodbav:"variables"`
+		Version    int                `dynamodbav:"version"`
+		ArchivedAt *string            `dynamodbav:"archivedAt"`
 	}{}
 
 	err = attributevalue.UnmarshalMap(resp.Item, &tmp)
 
 	if err != nil {
-		return variables, fmt.Errorf("failed to unmarshal variables - %w", err)
+		return []sdto.TemplateVariable{}, 0, fmt.Errorf("failed to unmarshal variables - %w", err)
 	}
 
-	variables = make([]sdto.TemplateVariable, 0, len(tmp.Variables))
+	if tmp.ArchivedAt != nil {
+		return []sdto.TemplateVariable{}, tmp.Version, internal.TemplateArchived{Id: templateId}
+	}
 
-	for _, v := range tmp.Variables {
-		variables = append(variables, sdto.TemplateVariable{
-			Name:       v.Name,
-			Type:       v.Type,
-			Required:   v.Required,
-			Validation: v.Validation,
+	if version != nil && *version != tmp.Version {
+		templateVersion, err := r.getTemplateVersion(ctx, templateId, *version)
+
+		if err != nil {
+			return []sdto.TemplateVariable{}, *version, err
+		}
+
+		return toTemplateVariablesDTO(templateVersion.Variables), templateVersion.Version, nil
+	}
+
+	return toTemplateVariablesDTO(tmp.Variables), tmp.Version, nil
+}
+
+func (r *Registry) UpdateTemplate(ctx context.Context, templateId, updatedBy string, ntr dto.NotificationTemplateReq) (sdto.NotificationTemplateDetails, error) {
+
+	current, err := r.getTemplate(ctx, templateId)
+
+	if err != nil {
+		return sdto.NotificationTemplateDetails{}, err
+	}
+
+	if current.ArchivedAt != nil {
+		return sdto.NotificationTemplateDetails{}, internal.TemplateArchived{Id: templateId}
+	}
+
+	updatedAt := formatRunTime(time.Now())
+
+	nt := NotificationTemplate{
+		Id:               current.Id,
+		Name:             ntr.Name,
+		Version:          current.Version + 1,
+		IsHtml:           ntr.IsHtml,
+		Format:           ntr.Format,
+		TitleTemplate:    ntr.TitleTemplate,
+		ContentsTemplate: ntr.ContentsTemplate,
+		Description:      ntr.Description,
+		Category:         ntr.Category,
+		Tags:             ntr.Tags,
+		SearchText:       makeTemplateSearchText(ntr),
+		Layout:           ntr.Layout,
+		Partials:         ntr.Partials,
+		DefaultLocale:    ntr.DefaultLocale,
+		Localizations:    makeTemplateLocalizations(ntr.Localizations),
+		HashKey:          NotificationsTemplateSyntheticKey,
+		CreatedBy:        current.CreatedBy,
+		CreatedAt:        current.CreatedAt,
+		UpdatedAt:        &updatedAt,
+		UpdatedBy:        &updatedBy,
+		Variables:        makeTemplateVariables(ntr.Variables),
+	}
+
+	transactItems, err := makeTemplateWrite(nt, updatedBy, updatedAt)
+
+	if err != nil {
+		return sdto.NotificationTemplateDetails{}, err
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	})
+
+	var canceled *types.TransactionCanceledException
+
+	if errors.As(err, &canceled) {
+		return sdto.NotificationTemplateDetails{}, internal.TemplateUpdateConflict{Id: templateId}
+	}
+
+	if err != nil {
+		return sdto.NotificationTemplateDetails{}, fmt.Errorf("failed to update notification template - %w", err)
+	}
+
+	return sdto.NotificationTemplateDetails{
+		Id:               nt.Id,
+		Name:             nt.Name,
+		Version:          nt.Version,
+		IsHtml:           nt.IsHtml,
+		Format:           nt.Format,
+		Description:      nt.Description,
+		Category:         nt.Category,
+		Tags:             nt.Tags,
+		TitleTemplate:    nt.TitleTemplate,
+		ContentsTemplate: nt.ContentsTemplate,
+		Layout:           nt.Layout,
+		DefaultLocale:    nt.DefaultLocale,
+		Localizations:    toTemplateLocalizationsDTO(nt.Localizations),
+		CreatedAt:        nt.CreatedAt,
+		CreatedBy:        nt.CreatedBy,
+		UpdatedAt:        nt.UpdatedAt,
+		UpdatedBy:        nt.UpdatedBy,
+		Variables:        toTemplateVariablesDTO(nt.Variables),
+	}, nil
+}
+
+func (r *Registry) GetTemplateVersions(ctx context.Context, templateId string, filters sdto.PageFilter) (sdto.Page[dto.NotificationTemplateVersionResp], error) {
+
+	page := sdto.Page[dto.NotificationTemplateVersionResp]{}
+
+	if _, err := r.getTemplate(ctx, templateId); err != nil {
+		return page, err
+	}
+
+	pageParams, err := makePageFilters(&notificationTemplateVersionKey{}, filters)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to make page params - %w", err)
+	}
+
+	keyExp := expression.
+		Key(NotificationTemplateVersionsHashKey).
+		Equal(expression.Value(templateId))
+
+	expr, err := expression.
+		NewBuilder().
+		WithKeyCondition(keyExp).
+		Build()
+
+	if err != nil {
+		return page, fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	response, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(NotificationTemplateVersionsTable),
+		ExclusiveStartKey:         pageParams.ExclusiveStartKey,
+		Limit:                     pageParams.Limit,
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward:          aws.Bool(false),
+	})
+
+	if err != nil {
+		return page, fmt.Errorf("failed to get template versions - %w", err)
+	}
+
+	versions := []NotificationTemplateVersion{}
+	err = attributevalue.UnmarshalListOfMaps(response.Items, &versions)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to unmarshal the template versions - %w", err)
+	}
+
+	if len(response.LastEvaluatedKey) != 0 {
+		key := notificationTemplateVersionKey{}
+		encoded, err := marshalNextToken(&key, response.LastEvaluatedKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &encoded
+	}
+
+	page.Data = make([]dto.NotificationTemplateVersionResp, 0, len(versions))
+
+	for _, v := range versions {
+		page.Data = append(page.Data, dto.NotificationTemplateVersionResp{
+			Version:   v.Version,
+			Name:      v.Name,
+			CreatedBy: v.CreatedBy,
+			CreatedAt: v.CreatedAt,
 		})
 	}
 
-	return variables, nil
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(page.Data)
+
+	return page, nil
+}
+
+func (r *Registry) GetTemplateVersion(ctx context.Context, templateId string, version int) (sdto.NotificationTemplateDetails, error) {
+
+	details := sdto.NotificationTemplateDetails{}
+
+	templateVersion, err := r.getTemplateVersion(ctx, templateId, version)
+
+	if err != nil {
+		return details, err
+	}
+
+	details.Id = templateVersion.TemplateId
+	details.Name = templateVersion.Name
+	details.Version = templateVersion.Version
+	details.IsHtml = templateVersion.IsHtml
+	details.Format = templateVersion.Format
+
+	// The templates created before the formats only have isHtml
+	if details.Format == "" {
+		details.Format = sdto.LegacyFormat(templateVersion.IsHtml)
+	}
+
+	details.Description = templateVersion.Description
+	details.Category = templateVersion.Category
+	details.Tags = templateVersion.Tags
+	details.TitleTemplate = templateVersion.TitleTemplate
+	details.ContentsTemplate = templateVersion.ContentsTemplate
+	details.Layout = templateVersion.Layout
+	details.DefaultLocale = templateVersion.DefaultLocale
+	details.Localizations = toTemplateLocalizationsDTO(templateVersion.Localizations)
+	details.Variables = toTemplateVariablesDTO(templateVersion.Variables)
+	details.CreatedAt = templateVersion.CreatedAt
+	details.CreatedBy = templateVersion.CreatedBy
+
+	// Every version after the first one is an update of the template
+	if templateVersion.Version > 1 {
+		first, err := r.getTemplateVersion(ctx, templateId, 1)
+
+		if err != nil {
+			return details, err
+		}
+
+		details.CreatedAt = first.CreatedAt
+		details.CreatedBy = first.CreatedBy
+		details.UpdatedAt = &templateVersion.CreatedAt
+		details.UpdatedBy = &templateVersion.CreatedBy
+	}
+
+	template, err := r.getTemplate(ctx, templateId)
+
+	if err != nil {
+		return details, err
+	}
+
+	details.ArchivedAt = template.ArchivedAt
+	details.ArchivedBy = template.ArchivedBy
+
+	return details, nil
 }
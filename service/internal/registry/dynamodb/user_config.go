@@ -20,6 +20,7 @@ const (
 	UserConfigInAppKey    = "inAppConfig"
 	UserConfigSnoozeUntil = "snoozeUntil"
 	UserConfigOptIn       = "optIn"
+	UserConfigLocale      = "locale"
 )
 
 type ChannelConfig struct {
@@ -32,6 +33,7 @@ type UserConfig struct {
 	EmailConfig ChannelConfig `dynamodbav:"emailConfig"`
 	SMSConfig   ChannelConfig `dynamodbav:"smsConfig"`
 	InAppConfig ChannelConfig `dynamodbav:"inAppConfig"`
+	Locale      *string       `dynamodbav:"locale,omitempty"`
 }
 
 func (cfg *UserConfig) GetKey() (DynamoKey, error) {
@@ -136,6 +138,7 @@ func (r *Registry) GetUserConfig(ctx context.Context, userId string) (dto.UserCo
 			OptIn:       config.InAppConfig.OptIn,
 			SnoozeUntil: config.InAppConfig.SnoozeUntil,
 		},
+		Locale: config.Locale,
 	}
 
 	return cfg, nil
@@ -174,6 +177,12 @@ func (r *Registry) UpdateUserConfig(ctx context.Context, userId string, config d
 	update.Set(inAppFmt(UserConfigOptIn), expression.Value(config.InAppConfig.OptIn))
 	update.Set(inAppFmt(UserConfigSnoozeUntil), expression.Value(config.InAppConfig.SnoozeUntil))
 
+	if config.Locale != nil {
+		update.Set(expression.Name(UserConfigLocale), expression.Value(*config.Locale))
+	} else {
+		update.Remove(expression.Name(UserConfigLocale))
+	}
+
 	expr, err := expression.NewBuilder().WithUpdate(update).Build()
 
 	if err != nil {
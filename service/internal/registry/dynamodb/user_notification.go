@@ -27,14 +27,15 @@ const (
 )
 
 type UserNotification struct {
-	Id        string  `dynamodbav:"id"`
-	UserId    string  `dynamodbav:"userId"`
-	Title     string  `dynamodbav:"title"`
-	Contents  string  `dynamodbav:"contents"`
-	CreatedAt string  `dynamodbav:"createdAt"`
-	Image     *string `dynamodbav:"image"`
-	ReadAt    *string `dynamodbav:"readAt"`
-	Topic     string  `dynamodbav:"topic"`
+	Id           string  `dynamodbav:"id"`
+	UserId       string  `dynamodbav:"userId"`
+	Title        string  `dynamodbav:"title"`
+	Contents     string  `dynamodbav:"contents"`
+	HtmlContents *string `dynamodbav:"htmlContents,omitempty"`
+	CreatedAt    string  `dynamodbav:"createdAt"`
+	Image        *string `dynamodbav:"image"`
+	ReadAt       *string `dynamodbav:"readAt"`
+	Topic        string  `dynamodbav:"topic"`
 }
 
 type userNotificationKey struct {
@@ -153,13 +154,14 @@ func (r *Registry) GetUserNotifications(ctx context.Context, filters dto.UserNot
 
 	for _, notification := range notifications {
 		un := dto.UserNotification{
-			Id:        notification.Id,
-			Title:     notification.Title,
-			Contents:  notification.Contents,
-			CreatedAt: notification.CreatedAt,
-			Image:     notification.Image,
-			ReadAt:    notification.ReadAt,
-			Topic:     notification.Topic,
+			Id:           notification.Id,
+			Title:        notification.Title,
+			Contents:     notification.Contents,
+			HtmlContents: notification.HtmlContents,
+			CreatedAt:    notification.CreatedAt,
+			Image:        notification.Image,
+			ReadAt:       notification.ReadAt,
+			Topic:        notification.Topic,
 		}
 
 		result = append(result, un)
@@ -235,26 +237,28 @@ func (r *Registry) CreateNotifications(ctx context.Context, notifications []sdto
 		}
 
 		item := UserNotification{
-			Id:        id.String(),
-			UserId:    n.UserId,
-			Title:     n.Title,
-			Contents:  n.Contents,
-			CreatedAt: time.Now().Format(time.RFC3339Nano),
-			Image:     n.Image,
-			ReadAt:    nil,
-			Topic:     n.Topic,
+			Id:           id.String(),
+			UserId:       n.UserId,
+			Title:        n.Title,
+			Contents:     n.Contents,
+			HtmlContents: n.HtmlContents,
+			CreatedAt:    time.Now().Format(time.RFC3339Nano),
+			Image:        n.Image,
+			ReadAt:       nil,
+			Topic:        n.Topic,
 		}
 
 		items = append(items, item)
 
 		userNotification := dto.UserNotification{
-			Id:        item.Id,
-			Title:     item.Title,
-			Contents:  item.Contents,
-			CreatedAt: item.CreatedAt,
-			Image:     item.Image,
-			ReadAt:    item.ReadAt,
-			Topic:     item.Topic,
+			Id:           item.Id,
+			Title:        item.Title,
+			Contents:     item.Contents,
+			HtmlContents: item.HtmlContents,
+			CreatedAt:    item.CreatedAt,
+			Image:        item.Image,
+			ReadAt:       item.ReadAt,
+			Topic:        item.Topic,
 		}
 
 		userNotifications = append(userNotifications, userNotification)
@@ -1,7 +1,11 @@
 package registry
 
 const (
-	NotificationType         = "Notification"
-	NotificationTemplateType = "NotificationTemplate"
-	DistributionListType     = "Distribution List"
+	NotificationType                = "Notification"
+	NotificationTemplateType        = "NotificationTemplate"
+	NotificationTemplateVersionType = "Notification Template Version"
+	DistributionListType            = "Distribution List"
+	NotificationScheduleType        = "Notification Schedule"
+	TemplatePartialType             = "Template Partial"
+	TemplatePartialVersionType      = "Template Partial Version"
 )
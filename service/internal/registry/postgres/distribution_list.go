@@ -2,13 +2,18 @@ package postgresresgistry
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/notifique/service/internal"
 	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/recipients"
 	"github.com/notifique/service/internal/registry"
 	sdto "github.com/notifique/shared/dto"
 )
@@ -25,19 +30,79 @@ type recipient struct {
 type distributionListSummary struct {
 	Name               string `db:"name"`
 	NumberOfRecipients int    `db:"num_recipients"`
+	Segment            []byte `db:"segment"`
 }
 
 type distributionListKey struct {
 	Name string `json:"name"`
 }
 
+type nestedDistributionList struct {
+	Name string `db:"name" json:"name"`
+	List string `db:"list" json:"list"`
+}
+
+type nestedList struct {
+	List string `db:"list"`
+}
+
+// recipientsCopySource copies the batches of an import, without loading
+// every recipient in memory.
+type recipientsCopySource struct {
+	batches recipients.Batches
+	batch   []string
+	idx     int
+	err     error
+}
+
+func (s *recipientsCopySource) Next() bool {
+
+	s.idx++
+
+	for s.idx >= len(s.batch) {
+		batch, err := s.batches.Next()
+
+		if err == io.EOF {
+			return false
+		}
+
+		if err != nil {
+			s.err = err
+			return false
+		}
+
+		s.batch = batch
+		s.idx = 0
+	}
+
+	return true
+}
+
+func (s *recipientsCopySource) Values() ([]any, error) {
+	return []any{s.batch[s.idx]}, nil
+}
+
+func (s *recipientsCopySource) Err() error {
+	return s.err
+}
+
 const InsertDistributionList = `
 INSERT INTO distribution_lists (
 	"name",
-	num_recipients
+	num_recipients,
+	segment,
+	"description",
+	"owner",
+	labels,
+	created_at
 ) VALUES (
 	@name,
-	@numRecipients
+	@numRecipients,
+	@segment,
+	@description,
+	NULLIF(@owner::VARCHAR, ''),
+	COALESCE(@labels::VARCHAR[], '{}'),
+	@createdAt
 );
 `
 
@@ -65,9 +130,20 @@ GROUP BY
 	"name";
 `
 
+const GetDistributionListSegment = `
+SELECT
+	segment
+FROM
+	distribution_lists
+WHERE
+	"name" = @name;
+`
+
 const GetDistributionLists = `
 SELECT
-	*
+	"name",
+	num_recipients,
+	segment
 FROM
 	distribution_lists
 %s
@@ -91,6 +167,24 @@ WHERE
 	"name" = @name;
 `
 
+const DeleteRecipientFromDistributionLists = `
+DELETE FROM
+	distribution_list_recipients
+WHERE
+	recipient = @recipient
+RETURNING
+	"name";
+`
+
+const DecrementRecipientsCount = `
+UPDATE
+	distribution_lists
+SET
+	num_recipients = num_recipients - 1
+WHERE
+	"name" = ANY (@names);
+`
+
 const DeleteDistributionListRecipients = `
 DELETE FROM
 	distribution_list_recipients
@@ -112,6 +206,245 @@ LIMIT
 	@limit;
 `
 
+const GetDistributionListSummary = `
+SELECT
+	"name",
+	num_recipients,
+	segment
+FROM
+	distribution_lists
+WHERE
+	"name" = @name;
+`
+
+const GetDistributionListDetails = `
+SELECT
+	"name",
+	num_recipients,
+	segment,
+	"description",
+	"owner",
+	labels,
+	created_at,
+	updated_at
+FROM
+	distribution_lists
+WHERE
+	"name" = @name;
+`
+
+const UpdateDistributionListMetadata = `
+UPDATE
+	distribution_lists
+SET
+	"description" = @description,
+	"owner" = @owner,
+	labels = COALESCE(@labels::VARCHAR[], '{}'),
+	updated_at = @updatedAt
+WHERE
+	"name" = @name
+RETURNING
+	"name",
+	num_recipients,
+	segment,
+	"description",
+	"owner",
+	labels,
+	created_at,
+	updated_at;
+`
+
+// RenameDistributionList changes the name of the list, the recipients,
+// nested lists and notifications follow it through their foreign keys
+const RenameDistributionList = `
+UPDATE
+	distribution_lists
+SET
+	"name" = @newName,
+	updated_at = @updatedAt
+WHERE
+	"name" = @name
+RETURNING
+	"name",
+	num_recipients,
+	segment,
+	"description",
+	"owner",
+	labels,
+	created_at,
+	updated_at;
+`
+
+const RenameScheduledDistributionList = `
+UPDATE
+	notification_schedules
+SET
+	notification = jsonb_set(notification, '{distributionList}', to_jsonb(@newName::VARCHAR))
+WHERE
+	notification->>'distributionList' = @name;
+`
+
+const RenameOutboxDistributionList = `
+UPDATE
+	notification_outbox
+SET
+	payload = jsonb_set(payload, '{distributionList}', to_jsonb(@newName::VARCHAR))
+WHERE
+	payload->>'distributionList' = @name;
+`
+
+const GetExistingDistributionLists = `
+SELECT
+	"name"
+FROM
+	distribution_lists
+WHERE
+	"name" = ANY (@names);
+`
+
+const InsertNestedDistributionList = `
+INSERT INTO distribution_list_lists(
+	"name",
+	list
+) VALUES (
+	@name,
+	@list
+) ON CONFLICT
+	("name", list)
+  DO NOTHING;
+`
+
+const DeleteNestedDistributionLists = `
+DELETE FROM
+	distribution_list_lists
+WHERE
+	"name" = @name AND
+	list = ANY (@lists);
+`
+
+// LockNestedDistributionLists serializes the changes of the nested
+// lists, so concurrent changes can't create a cycle
+const LockNestedDistributionLists = `
+LOCK TABLE distribution_list_lists IN SHARE ROW EXCLUSIVE MODE;
+`
+
+const IncludesDistributionList = `
+WITH RECURSIVE nested AS (
+	SELECT
+		@list::VARCHAR AS "name"
+	UNION
+	SELECT
+		l.list
+	FROM
+		distribution_list_lists l
+	INNER JOIN
+		nested n ON l."name" = n."name"
+)
+SELECT EXISTS (
+	SELECT 1 FROM nested WHERE "name" = @name
+);
+`
+
+const GetNestedDistributionLists = `
+SELECT
+	list
+FROM
+	distribution_list_lists
+WHERE
+	%s
+ORDER BY
+	list
+LIMIT
+	@limit;
+`
+
+const GetFlattenedDistributionListRecipients = `
+WITH RECURSIVE nested AS (
+	SELECT
+		@name::VARCHAR AS "name"
+	UNION
+	SELECT
+		l.list
+	FROM
+		distribution_list_lists l
+	INNER JOIN
+		nested n ON l."name" = n."name"
+)
+SELECT DISTINCT
+	r.recipient
+FROM
+	distribution_list_recipients r
+INNER JOIN
+	nested n ON r."name" = n."name"
+%s
+ORDER BY
+	r.recipient
+LIMIT
+	@limit;
+`
+
+const CreateImportedRecipientsTable = `
+CREATE TEMPORARY TABLE imported_recipients (
+	recipient VARCHAR NOT NULL
+) ON COMMIT DROP;
+`
+
+const InsertImportedRecipients = `
+INSERT INTO distribution_list_recipients(
+	"name",
+	recipient
+) SELECT DISTINCT
+	@name::VARCHAR,
+	recipient
+FROM
+	imported_recipients
+ON CONFLICT
+	("name", recipient)
+DO NOTHING;
+`
+
+const DeleteImportedRecipients = `
+DELETE FROM
+	distribution_list_recipients r
+USING
+	imported_recipients i
+WHERE
+	r."name" = @name AND
+	r.recipient = i.recipient;
+`
+
+const GetAllDistributionListRecipients = `
+SELECT
+	recipient
+FROM
+	distribution_list_recipients
+WHERE
+	"name" = @name
+ORDER BY
+	recipient;
+`
+
+// RecountRecipients sets the number of recipients of the list with the
+// recipients it has, used after the bulk changes of the recipients
+const RecountRecipients = `
+UPDATE
+	distribution_lists
+SET
+	num_recipients = (
+		SELECT
+			COUNT(*)
+		FROM
+			distribution_list_recipients
+		WHERE
+			"name" = @name
+	)
+WHERE
+	"name" = @name
+RETURNING
+	"name",
+	num_recipients;
+`
+
 const UpdateRecipientsCount = `
 UPDATE
 	distribution_lists
@@ -143,95 +476,850 @@ func getDistributionListSummary(ctx context.Context, listName string, rQuerier R
 	return &summary, nil
 }
 
-func (ps *Registry) CreateDistributionList(ctx context.Context, distributionList dto.DistributionList) error {
+func marshalSegment(segment *sdto.Segment) (*string, error) {
+
+	if segment == nil {
+		return nil, nil
+	}
+
+	marshalled, err := json.Marshal(segment)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal segment - %w", err)
+	}
+
+	str := string(marshalled)
+
+	return &str, nil
+}
+
+func unmarshalSegment(marshalled []byte) (*sdto.Segment, error) {
+
+	if marshalled == nil {
+		return nil, nil
+	}
+
+	segment := sdto.Segment{}
+
+	if err := json.Unmarshal(marshalled, &segment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal segment - %w", err)
+	}
+
+	return &segment, nil
+}
+
+// getDistributionListSegment gets the segment of the list, which is nil
+// for the lists with a fixed set of recipients. Lists that don't exist
+// return an EntityNotFound error.
+func getDistributionListSegment(ctx context.Context, listName string, rQuerier RowQuerier) (*sdto.Segment, error) {
+
+	args := pgx.NamedArgs{"name": listName}
+
+	var segment []byte
+
+	err := rQuerier.QueryRow(ctx, GetDistributionListSegment, args).Scan(&segment)
+
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return nil, internal.EntityNotFound{
+			Id:   listName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distribution list segment - %w", err)
+	}
+
+	return unmarshalSegment(segment)
+}
+
+func scanDistributionListDetails(row pgx.Row) (dto.DistributionListDetails, error) {
+
+	details := dto.DistributionListDetails{}
+
+	var segment []byte
+	var createdAt time.Time
+	var updatedAt *time.Time
+
+	err := row.Scan(
+		&details.Name,
+		&details.NumberOfRecipients,
+		&segment,
+		&details.Description,
+		&details.Owner,
+		&details.Labels,
+		&createdAt,
+		&updatedAt,
+	)
+
+	if err != nil {
+		return details, err
+	}
+
+	details.Segment, err = unmarshalSegment(segment)
+
+	if err != nil {
+		return details, err
+	}
+
+	details.CreatedAt = createdAt.Format(time.RFC3339)
+
+	if updatedAt != nil {
+		updatedAtStr := updatedAt.Format(time.RFC3339)
+		details.UpdatedAt = &updatedAtStr
+	}
 
-	list, err := getDistributionListSummary(ctx, distributionList.Name, ps.conn)
+	return details, nil
+}
+
+// getStaticDistributionList checks that the list exists and that its
+// recipients aren't defined by a segment.
+func getStaticDistributionList(ctx context.Context, listName string, rQuerier RowQuerier) error {
+
+	segment, err := getDistributionListSegment(ctx, listName, rQuerier)
 
 	if err != nil {
 		return err
 	}
 
-	if list != nil {
-		return internal.DistributionListAlreadyExists{
-			Name: list.Name,
+	if segment != nil {
+		return internal.DistributionListIsSegment{Name: listName}
+	}
+
+	return nil
+}
+
+// getStoredDistributionListSummary gets the summary stored with the
+// list. Lists that don't exist return an EntityNotFound error.
+func getStoredDistributionListSummary(ctx context.Context, listName string, rQuerier RowQuerier) (*dto.DistributionListSummary, error) {
+
+	args := pgx.NamedArgs{"name": listName}
+
+	var summary dto.DistributionListSummary
+	var segment []byte
+
+	err := rQuerier.QueryRow(ctx, GetDistributionListSummary, args).Scan(
+		&summary.Name,
+		&summary.NumberOfRecipients,
+		&segment,
+	)
+
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return nil, internal.EntityNotFound{
+			Id:   listName,
+			Type: registry.DistributionListType,
 		}
 	}
 
-	tx, err := ps.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distribution list summary - %w", err)
+	}
+
+	summary.Segment, err = unmarshalSegment(segment)
 
 	if err != nil {
-		return fmt.Errorf("failed to start transaction - %w", err)
+		return nil, err
 	}
 
-	args := pgx.NamedArgs{
-		"name":          distributionList.Name,
-		"numRecipients": len(distributionList.Recipients),
+	return &summary, nil
+}
+
+// checkNestedLists checks that the lists exist and that including them
+// in the list doesn't create a cycle. The lists should be locked by the
+// transaction.
+func checkNestedLists(ctx context.Context, listName string, lists []string, tx pgx.Tx) error {
+
+	rows, err := tx.Query(ctx, GetExistingDistributionLists, pgx.NamedArgs{"names": lists})
+
+	if err != nil {
+		return fmt.Errorf("failed to query the lists - %w", err)
+	}
+
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		return fmt.Errorf("failed to collect the lists - %w", err)
+	}
+
+	for _, list := range lists {
+		if !slices.Contains(existing, list) {
+			return internal.EntityNotFound{
+				Id:   list,
+				Type: registry.DistributionListType,
+			}
+		}
+	}
+
+	for _, list := range lists {
+		args := pgx.NamedArgs{"name": listName, "list": list}
+
+		var cycle bool
+
+		err := tx.QueryRow(ctx, IncludesDistributionList, args).Scan(&cycle)
+
+		if err != nil {
+			return fmt.Errorf("failed to check the nested lists of %s - %w", list, err)
+		}
+
+		if cycle {
+			return internal.DistributionListCycle{Name: listName, List: list}
+		}
+	}
+
+	return nil
+}
+
+func insertNestedLists(ctx context.Context, listName string, lists []string, tx pgx.Tx) error {
+
+	if len(lists) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, LockNestedDistributionLists)
+
+	if err != nil {
+		return fmt.Errorf("failed to lock the nested lists - %w", err)
+	}
+
+	err = checkNestedLists(ctx, listName, lists, tx)
+
+	if err != nil {
+		return err
+	}
+
+	listsArgs := make([]pgx.NamedArgs, 0, len(lists))
+
+	for _, list := range lists {
+		listsArgs = append(listsArgs, pgx.NamedArgs{
+			"name": listName,
+			"list": list,
+		})
+	}
+
+	err = batchInsert(ctx, InsertNestedDistributionList, listsArgs, tx)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert the nested lists - %w", err)
+	}
+
+	return nil
+}
+
+func (ps *Registry) CreateDistributionList(ctx context.Context, distributionList dto.DistributionList) error {
+
+	_, err := getDistributionListSegment(ctx, distributionList.Name, ps.conn)
+
+	if err == nil {
+		return internal.DistributionListAlreadyExists{
+			Name: distributionList.Name,
+		}
+	}
+
+	if !errors.As(err, &internal.EntityNotFound{}) {
+		return err
+	}
+
+	segment, err := marshalSegment(distributionList.Segment)
+
+	if err != nil {
+		return err
+	}
+
+	tx, err := ps.conn.Begin(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	args := pgx.NamedArgs{
+		"name":          distributionList.Name,
+		"numRecipients": len(distributionList.Recipients),
+		"segment":       segment,
+		"description":   distributionList.Description,
+		"owner":         distributionList.Owner,
+		"labels":        distributionList.Labels,
+		"createdAt":     time.Now().Format(time.RFC3339Nano),
+	}
+
+	_, err = tx.Exec(ctx, InsertDistributionList, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to create distribution list - %w", err)
+	}
+
+	recipientsArgs := make([]pgx.NamedArgs, 0, len(distributionList.Recipients))
+
+	for _, recipient := range distributionList.Recipients {
+		recipientsArgs = append(recipientsArgs, pgx.NamedArgs{
+			"name":      distributionList.Name,
+			"recipient": recipient,
+		})
+	}
+
+	err = batchInsert(
+		ctx,
+		InsertDistributionListRecipient,
+		recipientsArgs,
+		tx,
+	)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to insert distribution list recipients - %w", err)
+	}
+
+	err = insertNestedLists(ctx, distributionList.Name, distributionList.Lists, tx)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return fmt.Errorf("commit failed - %w", err)
+	}
+
+	return nil
+}
+
+func (ps *Registry) GetDistributionLists(ctx context.Context, filters dto.DistributionListFilters) (sdto.Page[dto.DistributionListSummary], error) {
+
+	page := sdto.Page[dto.DistributionListSummary]{}
+
+	args := pgx.NamedArgs{"limit": internal.PageSize}
+
+	whereFilters := []string{}
+
+	if filters.MaxResults != nil {
+		limit := *filters.MaxResults
+		args["limit"] = limit
+	}
+
+	if filters.NextToken != nil {
+		whereFilters = append(whereFilters, `("name") > (@name)`)
+
+		var unmarsalledKey distributionListKey
+		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		args["name"] = unmarsalledKey.Name
+	}
+
+	// Uses the recipient index of distribution_list_recipients
+	if filters.Member != nil {
+		whereFilters = append(whereFilters, `"name" IN (
+			SELECT "name" FROM distribution_list_recipients WHERE recipient = @member)`)
+		args["member"] = *filters.Member
+	}
+
+	whereStmt := ""
+
+	if len(whereFilters) != 0 {
+		whereStmt = fmt.Sprintf("WHERE %s", strings.Join(whereFilters, " AND "))
+	}
+
+	query := fmt.Sprintf(GetDistributionLists, whereStmt)
+	rows, err := ps.conn.Query(ctx, query, args)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to query rows - %w", err)
+	}
+
+	defer rows.Close()
+
+	summaries, err := pgx.CollectRows(rows, pgx.RowToStructByName[distributionListSummary])
+
+	if err != nil {
+		return page, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	numSummaries := len(summaries)
+
+	if numSummaries == args["limit"] {
+		lastSummary := summaries[numSummaries-1]
+
+		lastSummaryKey := distributionListKey{
+			Name: lastSummary.Name,
+		}
+
+		key, err := registry.MarshalKey(lastSummaryKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &key
+	}
+
+	dtoSummaries := make([]dto.DistributionListSummary, 0, len(summaries))
+
+	for _, summary := range summaries {
+		segment, err := unmarshalSegment(summary.Segment)
+
+		if err != nil {
+			return page, err
+		}
+
+		s := dto.DistributionListSummary{
+			Name:               summary.Name,
+			NumberOfRecipients: summary.NumberOfRecipients,
+			Segment:            segment,
+		}
+
+		dtoSummaries = append(dtoSummaries, s)
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(dtoSummaries)
+	page.Data = dtoSummaries
+
+	return page, nil
+}
+
+func (ps *Registry) GetDistributionListDetails(ctx context.Context, distlistName string) (dto.DistributionListDetails, error) {
+
+	args := pgx.NamedArgs{"name": distlistName}
+
+	details, err := scanDistributionListDetails(ps.conn.QueryRow(ctx, GetDistributionListDetails, args))
+
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return details, internal.EntityNotFound{
+			Id:   distlistName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	if err != nil {
+		return details, fmt.Errorf("failed to get distribution list - %w", err)
+	}
+
+	return details, nil
+}
+
+func (ps *Registry) UpdateDistributionList(ctx context.Context, distlistName string, metadata dto.DistributionListMetadata) (dto.DistributionListDetails, error) {
+
+	args := pgx.NamedArgs{
+		"name":        distlistName,
+		"description": metadata.Description,
+		"owner":       metadata.Owner,
+		"labels":      metadata.Labels,
+		"updatedAt":   time.Now().Format(time.RFC3339Nano),
+	}
+
+	details, err := scanDistributionListDetails(ps.conn.QueryRow(ctx, UpdateDistributionListMetadata, args))
+
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return details, internal.EntityNotFound{
+			Id:   distlistName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	if err != nil {
+		return details, fmt.Errorf("failed to update distribution list - %w", err)
+	}
+
+	return details, nil
+}
+
+// RenameDistributionList renames the list in a single transaction. The
+// schedules and the notifications waiting in the outbox store the name
+// of the list with the notification, which is changed to the new name.
+func (ps *Registry) RenameDistributionList(ctx context.Context, distlistName, newName string) (dto.DistributionListDetails, error) {
+
+	details := dto.DistributionListDetails{}
+
+	tx, err := ps.conn.Begin(ctx)
+
+	if err != nil {
+		return details, fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	_, err = getDistributionListSegment(ctx, newName, tx)
+
+	if err == nil {
+		tx.Rollback(ctx)
+		return details, internal.DistributionListAlreadyExists{Name: newName}
+	}
+
+	if !errors.As(err, &internal.EntityNotFound{}) {
+		tx.Rollback(ctx)
+		return details, err
+	}
+
+	args := pgx.NamedArgs{
+		"name":      distlistName,
+		"newName":   newName,
+		"updatedAt": time.Now().Format(time.RFC3339Nano),
+	}
+
+	details, err = scanDistributionListDetails(tx.QueryRow(ctx, RenameDistributionList, args))
+
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		tx.Rollback(ctx)
+		return details, internal.EntityNotFound{
+			Id:   distlistName,
+			Type: registry.DistributionListType,
+		}
+	}
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return details, fmt.Errorf("failed to rename distribution list - %w", err)
+	}
+
+	_, err = tx.Exec(ctx, RenameScheduledDistributionList, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return details, fmt.Errorf("failed to rename the list of the schedules - %w", err)
+	}
+
+	_, err = tx.Exec(ctx, RenameOutboxDistributionList, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return details, fmt.Errorf("failed to rename the list of the outbox - %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return details, fmt.Errorf("commit failed - %w", err)
+	}
+
+	return details, nil
+}
+
+func (ps *Registry) DeleteDistributionList(ctx context.Context, distlistName string) error {
+
+	tx, err := ps.conn.Begin(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	args := pgx.NamedArgs{"name": distlistName}
+
+	// Should also delete its recipients
+	_, err = tx.Exec(ctx, DeleteDistributionList, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to delete distribution list - %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return fmt.Errorf("commit failed - %w", err)
+	}
+
+	return nil
+}
+
+func (ps *Registry) GetRecipients(ctx context.Context, distlistName string, filters sdto.PageFilter) (sdto.Page[string], error) {
+
+	page := sdto.Page[string]{}
+
+	_, err := getDistributionListSegment(ctx, distlistName, ps.conn)
+
+	if err != nil {
+		return page, err
+	}
+
+	args := pgx.NamedArgs{"limit": internal.PageSize}
+	whereFilters := make([]string, 0)
+
+	if filters.MaxResults != nil {
+		limit := *filters.MaxResults
+		args["limit"] = limit
+	}
+
+	if filters.NextToken != nil {
+		filter := `("name", recipient) > (@name, @recipient)`
+		whereFilters = append(whereFilters, filter)
+
+		var unmarsalledKey distributionList
+		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		if unmarsalledKey.Name != distlistName {
+			return page, fmt.Errorf("invalid key %s", *filters.NextToken)
+		}
+
+		args["name"] = unmarsalledKey.Name
+		args["recipient"] = unmarsalledKey.Recipient
+	} else {
+		filter := "name = @name"
+		whereFilters = append(whereFilters, filter)
+		args["name"] = distlistName
+	}
+
+	whereStmt := strings.Join(whereFilters, "AND")
+	query := fmt.Sprintf(GetDistributionListRecipients, whereStmt)
+
+	rows, err := ps.conn.Query(ctx, query, args)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to query rows - %w", err)
+	}
+
+	defer rows.Close()
+
+	recipients, err := pgx.CollectRows(rows, pgx.RowToStructByName[recipient])
+
+	if err != nil {
+		return page, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	numRecipients := len(recipients)
+
+	if numRecipients == args["limit"] {
+		lastRecipient := recipients[numRecipients-1]
+
+		dl := distributionList{
+			Name:      distlistName,
+			Recipient: lastRecipient.Recipient,
+		}
+
+		key, err := registry.MarshalKey(dl)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &key
+	}
+
+	recipientsNames := make([]string, 0, len(recipients))
+
+	for _, r := range recipients {
+		recipientsNames = append(recipientsNames, r.Recipient)
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(recipients)
+	page.Data = recipientsNames
+
+	return page, nil
+}
+
+func (ps *Registry) GetSegment(ctx context.Context, distlistName string) (*sdto.Segment, error) {
+	return getDistributionListSegment(ctx, distlistName, ps.conn)
+}
+
+func (ps *Registry) AddRecipients(ctx context.Context, distlistName string, recipients []string) (*dto.DistributionListSummary, error) {
+
+	err := getStaticDistributionList(ctx, distlistName, ps.conn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ps.conn.Begin(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	recipientsArgs := make([]pgx.NamedArgs, 0, len(recipients))
+
+	for _, recipient := range recipients {
+		recipientsArgs = append(recipientsArgs, pgx.NamedArgs{
+			"name":      distlistName,
+			"recipient": recipient,
+		})
+	}
+
+	err = batchInsert(
+		ctx,
+		InsertDistributionListRecipient,
+		recipientsArgs,
+		tx,
+	)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed add recipients - %w", err)
+	}
+
+	summary, err := getDistributionListSummary(ctx, distlistName, tx)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to get summary - %w", err)
+	}
+
+	countArgs := pgx.NamedArgs{
+		"numRecipients": summary.NumberOfRecipients,
+		"name":          distlistName,
+	}
+
+	_, err = tx.Exec(ctx, UpdateRecipientsCount, countArgs)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to update recipients count - %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("commit failed - %w", err)
+	}
+
+	return summary, nil
+}
+
+func (ps *Registry) DeleteRecipients(ctx context.Context, distlistName string, recipients []string) (*dto.DistributionListSummary, error) {
+
+	err := getStaticDistributionList(ctx, distlistName, ps.conn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ps.conn.Begin(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	args := pgx.NamedArgs{
+		"name":       distlistName,
+		"recipients": recipients,
+	}
+
+	_, err = tx.Exec(ctx, DeleteDistributionListRecipients, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed delete recipients - %w", err)
+	}
+
+	summary, err := getDistributionListSummary(ctx, distlistName, tx)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to get summary - %w", err)
+	}
+
+	countArgs := pgx.NamedArgs{
+		"numRecipients": summary.NumberOfRecipients,
+		"name":          distlistName,
+	}
+
+	_, err = tx.Exec(ctx, UpdateRecipientsCount, countArgs)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to update recipients count - %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit delete recipients - %w", err)
+	}
+
+	return summary, nil
+}
+
+// DeleteRecipientFromLists removes the recipient from every list and
+// returns the names of the lists it was removed from.
+func (ps *Registry) DeleteRecipientFromLists(ctx context.Context, recipient string) ([]string, error) {
+
+	tx, err := ps.conn.Begin(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction - %w", err)
 	}
 
-	_, err = tx.Exec(ctx, InsertDistributionList, args)
+	args := pgx.NamedArgs{"recipient": recipient}
+	rows, err := tx.Query(ctx, DeleteRecipientFromDistributionLists, args)
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return fmt.Errorf("failed to create distribution list - %w", err)
+		return nil, fmt.Errorf("failed to delete the recipient - %w", err)
 	}
 
-	recipientsArgs := make([]pgx.NamedArgs, 0, len(distributionList.Recipients))
+	lists, err := pgx.CollectRows(rows, pgx.RowTo[string])
 
-	for _, recipient := range distributionList.Recipients {
-		recipientsArgs = append(recipientsArgs, pgx.NamedArgs{
-			"name":      distributionList.Name,
-			"recipient": recipient,
-		})
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to collect the lists - %w", err)
 	}
 
-	err = batchInsert(
-		ctx,
-		InsertDistributionListRecipient,
-		recipientsArgs,
-		tx,
-	)
+	_, err = tx.Exec(ctx, DecrementRecipientsCount, pgx.NamedArgs{"names": lists})
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return fmt.Errorf("failed to insert distribution list recipients - %w", err)
+		return nil, fmt.Errorf("failed to update recipients count - %w", err)
 	}
 
 	err = tx.Commit(ctx)
 
 	if err != nil {
-		return fmt.Errorf("commit failed - %w", err)
+		return nil, fmt.Errorf("failed to commit delete recipient - %w", err)
 	}
 
-	return nil
+	slices.Sort(lists)
+
+	return lists, nil
 }
 
-func (ps *Registry) GetDistributionLists(ctx context.Context, filters sdto.PageFilter) (sdto.Page[dto.DistributionListSummary], error) {
+// GetFlattenedRecipients gets the recipients of the list and of every
+// list included by it, directly or through other lists. The recipients
+// of the nested segments are resolved when the notifications are sent.
+func (ps *Registry) GetFlattenedRecipients(ctx context.Context, distlistName string, filters sdto.PageFilter) (sdto.Page[string], error) {
 
-	page := sdto.Page[dto.DistributionListSummary]{}
+	page := sdto.Page[string]{}
 
-	args := pgx.NamedArgs{"limit": internal.PageSize}
+	_, err := getDistributionListSegment(ctx, distlistName, ps.conn)
+
+	if err != nil {
+		return page, err
+	}
+
+	args := pgx.NamedArgs{
+		"name":  distlistName,
+		"limit": internal.PageSize,
+	}
 
 	nextTokenFilter := ""
 
 	if filters.MaxResults != nil {
-		limit := *filters.MaxResults
-		args["limit"] = limit
+		args["limit"] = *filters.MaxResults
 	}
 
 	if filters.NextToken != nil {
-		nextTokenFilter = `WHERE ("name") > (@name)`
+		nextTokenFilter = "WHERE r.recipient > @recipient"
 
-		var unmarsalledKey distributionListKey
+		var unmarsalledKey distributionList
 		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
 
 		if err != nil {
 			return page, err
 		}
 
-		args["name"] = unmarsalledKey.Name
+		if unmarsalledKey.Name != distlistName {
+			return page, fmt.Errorf("invalid key %s", *filters.NextToken)
+		}
+
+		args["recipient"] = unmarsalledKey.Recipient
 	}
 
-	query := fmt.Sprintf(GetDistributionLists, nextTokenFilter)
+	query := fmt.Sprintf(GetFlattenedDistributionListRecipients, nextTokenFilter)
 	rows, err := ps.conn.Query(ctx, query, args)
 
 	if err != nil {
@@ -240,22 +1328,19 @@ func (ps *Registry) GetDistributionLists(ctx context.Context, filters sdto.PageF
 
 	defer rows.Close()
 
-	summaries, err := pgx.CollectRows(rows, pgx.RowToStructByName[distributionListSummary])
+	recipients, err := pgx.CollectRows(rows, pgx.RowToStructByName[recipient])
 
 	if err != nil {
 		return page, fmt.Errorf("failed to collect rows - %w", err)
 	}
 
-	numSummaries := len(summaries)
-
-	if numSummaries == args["limit"] {
-		lastSummary := summaries[numSummaries-1]
-
-		lastSummaryKey := distributionListKey{
-			Name: lastSummary.Name,
-		}
+	numRecipients := len(recipients)
 
-		key, err := registry.MarshalKey(lastSummaryKey)
+	if numRecipients == args["limit"] {
+		key, err := registry.MarshalKey(distributionList{
+			Name:      distlistName,
+			Recipient: recipients[numRecipients-1].Recipient,
+		})
 
 		if err != nil {
 			return page, err
@@ -264,81 +1349,44 @@ func (ps *Registry) GetDistributionLists(ctx context.Context, filters sdto.PageF
 		page.NextToken = &key
 	}
 
-	dtoSummaries := make([]dto.DistributionListSummary, 0, len(summaries))
-
-	for _, summary := range summaries {
-		s := dto.DistributionListSummary{
-			Name:               summary.Name,
-			NumberOfRecipients: summary.NumberOfRecipients,
-		}
+	recipientsNames := make([]string, 0, len(recipients))
 
-		dtoSummaries = append(dtoSummaries, s)
+	for _, r := range recipients {
+		recipientsNames = append(recipientsNames, r.Recipient)
 	}
 
 	page.PrevToken = filters.NextToken
-	page.ResultCount = len(dtoSummaries)
-	page.Data = dtoSummaries
+	page.ResultCount = len(recipients)
+	page.Data = recipientsNames
 
 	return page, nil
 }
 
-func (ps *Registry) DeleteDistributionList(ctx context.Context, distlistName string) error {
-
-	tx, err := ps.conn.Begin(ctx)
-
-	if err != nil {
-		return fmt.Errorf("failed to start transaction - %w", err)
-	}
-
-	args := pgx.NamedArgs{"name": distlistName}
-
-	// Should also delete its recipients
-	_, err = tx.Exec(ctx, DeleteDistributionList, args)
-
-	if err != nil {
-		tx.Rollback(ctx)
-		return fmt.Errorf("failed to delete distribution list - %w", err)
-	}
-
-	err = tx.Commit(ctx)
-
-	if err != nil {
-		return fmt.Errorf("commit failed - %w", err)
-	}
-
-	return nil
-}
-
-func (ps *Registry) GetRecipients(ctx context.Context, distlistName string, filters sdto.PageFilter) (sdto.Page[string], error) {
+func (ps *Registry) GetLists(ctx context.Context, distlistName string, filters sdto.PageFilter) (sdto.Page[string], error) {
 
 	page := sdto.Page[string]{}
 
-	summary, err := getDistributionListSummary(ctx, distlistName, ps.conn)
+	_, err := getDistributionListSegment(ctx, distlistName, ps.conn)
 
 	if err != nil {
-		return page, fmt.Errorf("failed to get summary - %w", err)
+		return page, err
 	}
 
-	if summary == nil {
-		return page, internal.EntityNotFound{
-			Id:   distlistName,
-			Type: registry.DistributionListType,
-		}
+	args := pgx.NamedArgs{
+		"name":  distlistName,
+		"limit": internal.PageSize,
 	}
 
-	args := pgx.NamedArgs{"limit": internal.PageSize}
-	whereFilters := make([]string, 0)
+	whereFilters := []string{`"name" = @name`}
 
 	if filters.MaxResults != nil {
-		limit := *filters.MaxResults
-		args["limit"] = limit
+		args["limit"] = *filters.MaxResults
 	}
 
 	if filters.NextToken != nil {
-		filter := `("name", recipient) > (@name, @recipient)`
-		whereFilters = append(whereFilters, filter)
+		whereFilters = append(whereFilters, "list > @list")
 
-		var unmarsalledKey distributionList
+		var unmarsalledKey nestedDistributionList
 		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
 
 		if err != nil {
@@ -349,16 +1397,11 @@ func (ps *Registry) GetRecipients(ctx context.Context, distlistName string, filt
 			return page, fmt.Errorf("invalid key %s", *filters.NextToken)
 		}
 
-		args["name"] = unmarsalledKey.Name
-		args["recipient"] = unmarsalledKey.Recipient
-	} else {
-		filter := "name = @name"
-		whereFilters = append(whereFilters, filter)
-		args["name"] = distlistName
+		args["list"] = unmarsalledKey.List
 	}
 
-	whereStmt := strings.Join(whereFilters, "AND")
-	query := fmt.Sprintf(GetDistributionListRecipients, whereStmt)
+	whereStmt := strings.Join(whereFilters, " AND ")
+	query := fmt.Sprintf(GetNestedDistributionLists, whereStmt)
 
 	rows, err := ps.conn.Query(ctx, query, args)
 
@@ -368,23 +1411,19 @@ func (ps *Registry) GetRecipients(ctx context.Context, distlistName string, filt
 
 	defer rows.Close()
 
-	recipients, err := pgx.CollectRows(rows, pgx.RowToStructByName[recipient])
+	lists, err := pgx.CollectRows(rows, pgx.RowToStructByName[nestedList])
 
 	if err != nil {
 		return page, fmt.Errorf("failed to collect rows - %w", err)
 	}
 
-	numRecipients := len(recipients)
-
-	if numRecipients == args["limit"] {
-		lastRecipient := recipients[numRecipients-1]
-
-		dl := distributionList{
-			Name:      distlistName,
-			Recipient: lastRecipient.Recipient,
-		}
+	numLists := len(lists)
 
-		key, err := registry.MarshalKey(dl)
+	if numLists == args["limit"] {
+		key, err := registry.MarshalKey(nestedDistributionList{
+			Name: distlistName,
+			List: lists[numLists-1].List,
+		})
 
 		if err != nil {
 			return page, err
@@ -393,33 +1432,20 @@ func (ps *Registry) GetRecipients(ctx context.Context, distlistName string, filt
 		page.NextToken = &key
 	}
 
-	recipientsNames := make([]string, 0, len(recipients))
+	listNames := make([]string, 0, len(lists))
 
-	for _, r := range recipients {
-		recipientsNames = append(recipientsNames, r.Recipient)
+	for _, l := range lists {
+		listNames = append(listNames, l.List)
 	}
 
 	page.PrevToken = filters.NextToken
-	page.ResultCount = len(recipients)
-	page.Data = recipientsNames
+	page.ResultCount = len(lists)
+	page.Data = listNames
 
 	return page, nil
 }
 
-func (ps *Registry) AddRecipients(ctx context.Context, distlistName string, recipients []string) (*dto.DistributionListSummary, error) {
-
-	exists, err := getDistributionListSummary(ctx, distlistName, ps.conn)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get summary - %w", err)
-	}
-
-	if exists == nil {
-		return nil, internal.EntityNotFound{
-			Id:   distlistName,
-			Type: registry.DistributionListType,
-		}
-	}
+func (ps *Registry) AddLists(ctx context.Context, distlistName string, lists []string) (*dto.DistributionListSummary, error) {
 
 	tx, err := ps.conn.Begin(ctx)
 
@@ -427,44 +1453,25 @@ func (ps *Registry) AddRecipients(ctx context.Context, distlistName string, reci
 		return nil, fmt.Errorf("failed to start transaction - %w", err)
 	}
 
-	recipientsArgs := make([]pgx.NamedArgs, 0, len(recipients))
-
-	for _, recipient := range recipients {
-		recipientsArgs = append(recipientsArgs, pgx.NamedArgs{
-			"name":      distlistName,
-			"recipient": recipient,
-		})
-	}
-
-	err = batchInsert(
-		ctx,
-		InsertDistributionListRecipient,
-		recipientsArgs,
-		tx,
-	)
+	err = getStaticDistributionList(ctx, distlistName, tx)
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return nil, fmt.Errorf("failed add recipients - %w", err)
+		return nil, err
 	}
 
-	summary, err := getDistributionListSummary(ctx, distlistName, tx)
+	err = insertNestedLists(ctx, distlistName, lists, tx)
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return nil, fmt.Errorf("failed to get summary - %w", err)
+		return nil, err
 	}
 
-	countArgs := pgx.NamedArgs{
-		"numRecipients": summary.NumberOfRecipients,
-		"name":          distlistName,
-	}
-
-	_, err = tx.Exec(ctx, UpdateRecipientsCount, countArgs)
+	summary, err := getStoredDistributionListSummary(ctx, distlistName, tx)
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return nil, fmt.Errorf("failed to update recipients count - %w", err)
+		return nil, err
 	}
 
 	err = tx.Commit(ctx)
@@ -476,52 +1483,95 @@ func (ps *Registry) AddRecipients(ctx context.Context, distlistName string, reci
 	return summary, nil
 }
 
-func (ps *Registry) DeleteRecipients(ctx context.Context, distlistName string, recipients []string) (*dto.DistributionListSummary, error) {
+func (ps *Registry) DeleteLists(ctx context.Context, distlistName string, lists []string) (*dto.DistributionListSummary, error) {
 
-	exists, err := getDistributionListSummary(ctx, distlistName, ps.conn)
+	summary, err := getStoredDistributionListSummary(ctx, distlistName, ps.conn)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get summary - %w", err)
+		return nil, err
 	}
 
-	if exists == nil {
-		return nil, internal.EntityNotFound{
-			Id:   distlistName,
-			Type: registry.DistributionListType,
-		}
+	args := pgx.NamedArgs{
+		"name":  distlistName,
+		"lists": lists,
+	}
+
+	_, err = ps.conn.Exec(ctx, DeleteNestedDistributionLists, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete the nested lists - %w", err)
 	}
 
+	return summary, nil
+}
+
+// ImportRecipients copies the recipients to a temporary table, which are
+// then added to or removed from the list. Replacing the recipients
+// deletes the ones the list had in the same transaction.
+func (ps *Registry) ImportRecipients(ctx context.Context, distlistName string, mode dto.RecipientsImportMode, batches recipients.Batches) (*dto.DistributionListSummary, error) {
+
 	tx, err := ps.conn.Begin(ctx)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to start transaction - %w", err)
 	}
 
-	args := pgx.NamedArgs{
-		"name":       distlistName,
-		"recipients": recipients,
+	err = getStaticDistributionList(ctx, distlistName, tx)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, err
 	}
 
-	_, err = tx.Exec(ctx, DeleteDistributionListRecipients, args)
+	args := pgx.NamedArgs{"name": distlistName}
+
+	if mode == dto.RecipientsImportReplace {
+		_, err = tx.Exec(ctx, DeleteAllRecipientsOfDistributionList, args)
+
+		if err != nil {
+			tx.Rollback(ctx)
+			return nil, fmt.Errorf("failed to delete the recipients - %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, CreateImportedRecipientsTable)
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return nil, fmt.Errorf("failed delete recipients - %w", err)
+		return nil, fmt.Errorf("failed to create the imported recipients table - %w", err)
 	}
 
-	summary, err := getDistributionListSummary(ctx, distlistName, tx)
+	_, err = tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"imported_recipients"},
+		[]string{"recipient"},
+		&recipientsCopySource{batches: batches},
+	)
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return nil, fmt.Errorf("failed to get summary - %w", err)
+		return nil, fmt.Errorf("failed to copy the recipients - %w", err)
 	}
 
-	countArgs := pgx.NamedArgs{
-		"numRecipients": summary.NumberOfRecipients,
-		"name":          distlistName,
+	query := InsertImportedRecipients
+
+	if mode == dto.RecipientsImportRemove {
+		query = DeleteImportedRecipients
 	}
 
-	_, err = tx.Exec(ctx, UpdateRecipientsCount, countArgs)
+	_, err = tx.Exec(ctx, query, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to import the recipients - %w", err)
+	}
+
+	summary := dto.DistributionListSummary{}
+
+	err = tx.QueryRow(ctx, RecountRecipients, args).Scan(
+		&summary.Name,
+		&summary.NumberOfRecipients,
+	)
 
 	if err != nil {
 		tx.Rollback(ctx)
@@ -531,8 +1581,57 @@ func (ps *Registry) DeleteRecipients(ctx context.Context, distlistName string, r
 	err = tx.Commit(ctx)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to commit delete recipients - %w", err)
+		return nil, fmt.Errorf("commit failed - %w", err)
 	}
 
-	return summary, nil
+	return &summary, nil
+}
+
+func (ps *Registry) ExportRecipients(ctx context.Context, distlistName string, write func([]string) error) error {
+
+	_, err := getDistributionListSegment(ctx, distlistName, ps.conn)
+
+	if err != nil {
+		return err
+	}
+
+	args := pgx.NamedArgs{"name": distlistName}
+
+	rows, err := ps.conn.Query(ctx, GetAllDistributionListRecipients, args)
+
+	if err != nil {
+		return fmt.Errorf("failed to query rows - %w", err)
+	}
+
+	defer rows.Close()
+
+	batch := make([]string, 0, internal.RecipientsBatchSize)
+
+	for rows.Next() {
+		var recipient string
+
+		if err := rows.Scan(&recipient); err != nil {
+			return fmt.Errorf("failed to scan recipient - %w", err)
+		}
+
+		batch = append(batch, recipient)
+
+		if len(batch) == internal.RecipientsBatchSize {
+			if err := write(batch); err != nil {
+				return err
+			}
+
+			batch = batch[:0]
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read rows - %w", err)
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	return write(batch)
 }
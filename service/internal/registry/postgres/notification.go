@@ -21,25 +21,29 @@ INSERT INTO notifications (
 	title,
 	contents,
 	template_id,
+	template_version,
 	image_url,
 	topic,
 	priority,
 	distribution_list,
 	created_at,
 	created_by,
-	status
+	status,
+	send_at
 ) VALUES (
 	@id,
 	@title,
 	@contents,
 	@templateId,
+	@templateVersion,
 	@imageUrl,
 	@topic,
 	@priority,
 	@distributionList,
 	@createdAt,
 	@createdBy,
-	@status
+	@status,
+	@sendAt
 );
 `
 
@@ -55,6 +59,20 @@ INSERT INTO notification_template_variable_contents(
 );
 `
 
+const insertNotificationRecipientTemplateVariableContents = `
+INSERT INTO notification_recipient_template_variable_contents(
+	notification_id,
+	user_id,
+	name,
+	value
+) VALUES (
+	@notificationId,
+	@userId,
+	@name,
+	@value
+);
+`
+
 const InsertNotificationRecipients = `
 INSERT INTO notification_recipients (
 	notification_id,
@@ -98,6 +116,26 @@ WHERE
 	id = @notificationId;
 `
 
+const claimScheduledNotification = `
+UPDATE
+	notifications
+SET
+	status = 'CREATED'
+WHERE
+	id = @notificationId
+	AND status = 'SCHEDULED';
+`
+
+const lockNotificationStatus = `
+SELECT
+	status
+FROM
+	notifications
+WHERE
+	id = $1
+FOR UPDATE;
+`
+
 const deleteTemplate = `
 DELETE FROM
 	notifications
@@ -127,7 +165,7 @@ FROM
 	notifications
 %s
 ORDER BY
-	id DESC
+	id %s
 LIMIT
 	@limit;
 `
@@ -138,6 +176,7 @@ SELECT
 	title,
 	contents,
 	template_id,
+	template_version,
 	image_url,
 	topic,
 	priority,
@@ -145,6 +184,7 @@ SELECT
 	created_at,
 	created_by,
 	status,
+	send_at,
 	ARRAY_AGG(distinct channel) AS channels,
 	ARRAY_AGG(distinct recipient) AS recipients,
 	ARRAY_AGG(
@@ -167,6 +207,33 @@ GROUP BY
 	n.id;
 `
 
+const getNotificationRecipientVariables = `
+SELECT
+	user_id,
+	name,
+	value
+FROM
+	notification_recipient_template_variable_contents
+WHERE
+	notification_id = $1
+ORDER BY
+	user_id;
+`
+
+const getDueNotifications = `
+SELECT
+	id
+FROM
+	notifications
+WHERE
+	"status" = 'SCHEDULED'
+	AND send_at <= @dueBefore
+ORDER BY
+	send_at ASC
+LIMIT
+	@limit;
+`
+
 const insertRecipientNotificationStatusLog = `
 INSERT INTO recipient_notification_status_log (
 	notification_id,
@@ -214,10 +281,34 @@ LIMIT
 	@limit;
 `
 
+const getNotificationStatusLogs = `
+SELECT
+	status_date,
+	status,
+	error_message
+FROM
+	notification_status_log
+WHERE
+	%s
+ORDER BY
+	status_date,
+	status
+LIMIT
+	@limit;
+`
+
 type notificationKey struct {
 	Id string `db:"id"`
 }
 
+// notificationStatusLogKey is the position of a page of status logs.
+// The status breaks the ties between logs created at the same time.
+type notificationStatusLogKey struct {
+	NotificationId string `json:"notificationId"`
+	StatusDate     string `json:"statusDate"`
+	Status         string `json:"status"`
+}
+
 type recipientNotificationStatusKey struct {
 	UserId         string `json:"userId"`
 	NotificationId string `json:"notificationId"`
@@ -241,6 +332,18 @@ type recipientNotificationStatus struct {
 	ErrorMessage *string `db:"error_message"`
 }
 
+type recipientTemplateVariable struct {
+	UserId string `db:"user_id"`
+	Name   string `db:"name"`
+	Value  string `db:"value"`
+}
+
+type notificationStatusLog struct {
+	StatusDate   time.Time `db:"status_date"`
+	Status       string    `db:"status"`
+	ErrorMessage *string   `db:"error_message"`
+}
+
 func (r *Registry) createStatusLog(ctx context.Context, tx pgx.Tx, statusLog sdto.NotificationStatusLog) error {
 
 	args := pgx.NamedArgs{
@@ -304,35 +407,94 @@ func (r *Registry) UpdateNotificationStatus(ctx context.Context, statusLog sdto.
 	return nil
 }
 
-func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notificationReq sdto.NotificationReq) (string, error) {
+func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notificationReq sdto.NotificationReq) (sdto.NotificationCreatedResp, error) {
 
 	tx, err := r.conn.Begin(ctx)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to start transaction - %w", err)
+		return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to start transaction - %w", err)
 	}
 
-	id, err := uuid.NewV7()
+	created, err := r.insertNotification(ctx, tx, createdBy, notificationReq)
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return "", fmt.Errorf("failed to generate notification id - %w", err)
+		return sdto.NotificationCreatedResp{}, err
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to commit notification insert - %w", err)
+	}
+
+	return created, nil
+}
+
+// insertNotification inserts the notification in the transaction, along
+// with its status log and, unless it's scheduled, its outbox entry.
+func (r *Registry) insertNotification(ctx context.Context, tx pgx.Tx, createdBy string, notificationReq sdto.NotificationReq) (sdto.NotificationCreatedResp, error) {
+
+	id, err := uuid.NewV7()
+
+	if err != nil {
+		return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to generate notification id - %w", err)
 	}
 
 	notificationId := id.String()
+	createdAt := time.Now().Format(time.RFC3339Nano)
+	status := sdto.Created
+
+	if notificationReq.SendAt != nil {
+		status = sdto.Scheduled
+	}
+
+	if notificationReq.TemplateContents != nil {
+		templateContents := *notificationReq.TemplateContents
+
+		// Locks the template so it can't be archived while the
+		// notification is being saved
+		var version int
+		var archivedAt *time.Time
+
+		err := tx.QueryRow(ctx, lockActiveTemplateVersion, templateContents.Id).
+			Scan(&version, &archivedAt)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sdto.NotificationCreatedResp{}, makeTemplateNotFound(templateContents.Id, nil)
+		}
+
+		if err != nil {
+			return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to get the template version - %w", err)
+		}
+
+		if archivedAt != nil {
+			return sdto.NotificationCreatedResp{}, internal.TemplateArchived{Id: templateContents.Id}
+		}
+
+		// The notification is sent with the latest version of the
+		// template when a version isn't requested
+		if templateContents.Version == nil {
+			templateContents.Version = &version
+		}
+
+		notificationReq.TemplateContents = &templateContents
+	}
 
 	notificationArgs := pgx.NamedArgs{
 		"id":               notificationId,
 		"title":            nil,
 		"contents":         nil,
 		"templateId":       nil,
+		"templateVersion":  nil,
 		"imageUrl":         notificationReq.Image,
 		"topic":            notificationReq.Topic,
 		"priority":         notificationReq.Priority,
 		"distributionList": notificationReq.DistributionList,
-		"createdAt":        time.Now().Format(time.RFC3339Nano),
+		"createdAt":        createdAt,
 		"createdBy":        createdBy,
-		"status":           sdto.Created,
+		"status":           status,
+		"sendAt":           notificationReq.SendAt,
 	}
 
 	if notificationReq.RawContents != nil {
@@ -340,13 +502,13 @@ func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notif
 		notificationArgs["contents"] = notificationReq.RawContents.Contents
 	} else {
 		notificationArgs["templateId"] = notificationReq.TemplateContents.Id
+		notificationArgs["templateVersion"] = notificationReq.TemplateContents.Version
 	}
 
 	_, err = tx.Exec(ctx, InsertNotification, notificationArgs)
 
 	if err != nil {
-		tx.Rollback(ctx)
-		return "", fmt.Errorf("failed to insert notification - %w", err)
+		return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to insert notification - %w", err)
 	}
 
 	if notificationReq.TemplateContents != nil {
@@ -369,8 +531,31 @@ func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notif
 		)
 
 		if err != nil {
-			tx.Rollback(ctx)
-			return "", fmt.Errorf("failed to insert notification template variables - %w", err)
+			return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to insert notification template variables - %w", err)
+		}
+
+		recipientVariables := []pgx.NamedArgs{}
+
+		for userId, userVariables := range notificationReq.TemplateContents.RecipientVariables {
+			for _, v := range userVariables {
+				recipientVariables = append(recipientVariables, pgx.NamedArgs{
+					"notificationId": notificationId,
+					"userId":         userId,
+					"name":           v.Name,
+					"value":          v.Value,
+				})
+			}
+		}
+
+		err = batchInsert(
+			ctx,
+			insertNotificationRecipientTemplateVariableContents,
+			recipientVariables,
+			tx,
+		)
+
+		if err != nil {
+			return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to insert notification recipient template variables - %w", err)
 		}
 	}
 
@@ -391,8 +576,7 @@ func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notif
 	)
 
 	if err != nil {
-		tx.Rollback(ctx)
-		return "", err
+		return sdto.NotificationCreatedResp{}, err
 	}
 
 	channelsArgs := make([]pgx.NamedArgs, 0, len(notificationReq.Channels))
@@ -412,30 +596,111 @@ func (r *Registry) SaveNotification(ctx context.Context, createdBy string, notif
 	)
 
 	if err != nil {
-		tx.Rollback(ctx)
-		return "", err
+		return sdto.NotificationCreatedResp{}, err
 	}
 
 	statusLog := sdto.NotificationStatusLog{
 		NotificationId: notificationId,
-		Status:         sdto.Created,
+		Status:         status,
 		ErrorMsg:       nil,
 	}
 
 	err = r.createStatusLog(ctx, tx, statusLog)
 
+	if err != nil {
+		return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to create notification status logs - %w", err)
+	}
+
+	// Scheduled notifications are added to the outbox when the scheduler
+	// claims them
+	if status == sdto.Created {
+		payload := sdto.NotificationMsgPayload{
+			Id:              notificationId,
+			Hash:            internal.GetMd5Hash(notificationId),
+			NotificationReq: notificationReq,
+		}
+
+		err = r.createOutboxEntry(ctx, tx, payload)
+
+		if err != nil {
+			return sdto.NotificationCreatedResp{}, fmt.Errorf("failed to create outbox entry - %w", err)
+		}
+	}
+
+	return sdto.NotificationCreatedResp{
+		Id:        notificationId,
+		Status:    status,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func (r *Registry) ResendNotification(ctx context.Context, payload sdto.NotificationMsgPayload) error {
+
+	tx, err := r.conn.Begin(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	var status sdto.NotificationStatus
+
+	// Locks the notification so it can't be resent twice concurrently
+	err = tx.QueryRow(ctx, lockNotificationStatus, payload.Id).Scan(&status)
+
+	if err == pgx.ErrNoRows {
+		tx.Rollback(ctx)
+		return internal.EntityNotFound{Id: payload.Id, Type: registry.NotificationType}
+	} else if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to query the notification status - %w", err)
+	}
+
+	if !registry.IsResendableStatus(status) {
+		tx.Rollback(ctx)
+		return internal.InvalidNotificationStatus{
+			Id:     payload.Id,
+			Status: string(status),
+		}
+	}
+
+	args := pgx.NamedArgs{
+		"notificationId": payload.Id,
+		"status":         sdto.Created,
+	}
+
+	_, err = tx.Exec(ctx, UpdateNotificationStatus, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to update notification status - %w", err)
+	}
+
+	statusLog := sdto.NotificationStatusLog{
+		NotificationId: payload.Id,
+		Status:         sdto.Created,
+	}
+
+	err = r.createStatusLog(ctx, tx, statusLog)
+
 	if err != nil {
 		tx.Rollback(ctx)
-		return "", fmt.Errorf("failed to create notification status logs - %w", err)
+		return fmt.Errorf("failed to insert notification status logs - %w", err)
+	}
+
+	err = r.createOutboxEntry(ctx, tx, payload)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to create outbox entry - %w", err)
 	}
 
 	err = tx.Commit(ctx)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to commit notification insert - %w", err)
+		return fmt.Errorf("failed to commit notification resend - %w", err)
 	}
 
-	return notificationId, nil
+	return nil
 }
 
 func (r *Registry) GetNotificationStatus(ctx context.Context, id string) (sdto.NotificationStatus, error) {
@@ -495,19 +760,28 @@ func (r *Registry) DeleteNotification(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *Registry) GetNotifications(ctx context.Context, filters sdto.PageFilter) (sdto.Page[dto.NotificationSummary], error) {
+func (r *Registry) GetNotifications(ctx context.Context, filters dto.NotificationFilters) (sdto.Page[dto.NotificationSummary], error) {
 
 	var page sdto.Page[dto.NotificationSummary]
 
 	args := pgx.NamedArgs{"limit": internal.PageSize}
-	whereFilter := ""
+	whereFilters := make([]string, 0)
+
+	// Ids are UUIDv7, so sorting by them sorts by the creation time
+	order := "DESC"
+	nextTokenFilter := "id < @id"
+
+	if filters.IsAscending() {
+		order = "ASC"
+		nextTokenFilter = "id > @id"
+	}
 
 	if filters.MaxResults != nil {
 		args["limit"] = *filters.MaxResults
 	}
 
 	if filters.NextToken != nil {
-		whereFilter = "id < @id"
+		whereFilters = append(whereFilters, nextTokenFilter)
 
 		var unmarsalledKey notificationKey
 		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
@@ -519,13 +793,59 @@ func (r *Registry) GetNotifications(ctx context.Context, filters sdto.PageFilter
 		args["id"] = unmarsalledKey.Id
 	}
 
-	whereStmt := ""
+	if filters.Topic != nil {
+		whereFilters = append(whereFilters, "topic = @topic")
+		args["topic"] = *filters.Topic
+	}
 
-	if whereFilter != "" {
-		whereStmt = fmt.Sprintf("WHERE %s", whereFilter)
+	if filters.Status != nil {
+		whereFilters = append(whereFilters, `"status" = @status`)
+		args["status"] = *filters.Status
 	}
 
-	query := fmt.Sprintf(getNotificationSummaries, whereStmt)
+	if filters.Priority != nil {
+		whereFilters = append(whereFilters, `"priority" = @priority`)
+		args["priority"] = *filters.Priority
+	}
+
+	if filters.CreatedBy != nil {
+		whereFilters = append(whereFilters, "created_by = @createdBy")
+		args["createdBy"] = *filters.CreatedBy
+	}
+
+	if filters.ContentsType != nil && *filters.ContentsType == dto.Raw {
+		whereFilters = append(whereFilters, "template_id IS NULL")
+	} else if filters.ContentsType != nil {
+		whereFilters = append(whereFilters, "template_id IS NOT NULL")
+	}
+
+	if filters.TemplateId != nil {
+		whereFilters = append(whereFilters, "template_id = @templateId")
+		args["templateId"] = *filters.TemplateId
+	}
+
+	if filters.DistributionList != nil {
+		whereFilters = append(whereFilters, "distribution_list = @distributionList")
+		args["distributionList"] = *filters.DistributionList
+	}
+
+	if filters.CreatedFrom != nil {
+		whereFilters = append(whereFilters, "created_at >= @createdFrom")
+		args["createdFrom"] = *filters.CreatedFrom
+	}
+
+	if filters.CreatedTo != nil {
+		whereFilters = append(whereFilters, "created_at <= @createdTo")
+		args["createdTo"] = *filters.CreatedTo
+	}
+
+	whereStmt := strings.Join(whereFilters, " AND ")
+
+	if len(whereStmt) != 0 {
+		whereStmt = fmt.Sprintf("WHERE %s", whereStmt)
+	}
+
+	query := fmt.Sprintf(getNotificationSummaries, whereStmt, order)
 
 	rows, err := r.conn.Query(ctx, query, args)
 
@@ -584,6 +904,36 @@ func (r *Registry) GetNotifications(ctx context.Context, filters sdto.PageFilter
 	return page, nil
 }
 
+func (r *Registry) getRecipientVariables(ctx context.Context, notificationId string) (map[string][]sdto.TemplateVariableContents, error) {
+
+	rows, err := r.conn.Query(ctx, getNotificationRecipientVariables, notificationId)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the recipient template variables - %w", err)
+	}
+
+	collected, err := pgx.CollectRows(rows, pgx.RowToStructByName[recipientTemplateVariable])
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect the recipient template variables - %w", err)
+	}
+
+	var recipientVariables map[string][]sdto.TemplateVariableContents
+
+	for _, v := range collected {
+		if recipientVariables == nil {
+			recipientVariables = map[string][]sdto.TemplateVariableContents{}
+		}
+
+		recipientVariables[v.UserId] = append(recipientVariables[v.UserId], sdto.TemplateVariableContents{
+			Name:  v.Name,
+			Value: v.Value,
+		})
+	}
+
+	return recipientVariables, nil
+}
+
 func (r *Registry) GetNotification(ctx context.Context, notificationId string) (dto.NotificationResp, error) {
 
 	notification := dto.NotificationResp{}
@@ -594,8 +944,10 @@ func (r *Registry) GetNotification(ctx context.Context, notificationId string) (
 	}{}
 
 	var templateId *string = nil
+	var templateVersion *int = nil
 
 	createdAt := time.Time{}
+	var sendAt *time.Time
 	channelsAgg := []string{}
 	recipientsAgg := []string{}
 	variablesAgg := []*string{}
@@ -607,6 +959,7 @@ func (r *Registry) GetNotification(ctx context.Context, notificationId string) (
 		&rawContents.Title,
 		&rawContents.Contents,
 		&templateId,
+		&templateVersion,
 		&notification.Image,
 		&notification.Topic,
 		&notification.Priority,
@@ -614,6 +967,7 @@ func (r *Registry) GetNotification(ctx context.Context, notificationId string) (
 		&createdAt,
 		&notification.CreatedBy,
 		&notification.Status,
+		&sendAt,
 		&channelsAgg,
 		&recipientsAgg,
 		&variablesAgg,
@@ -628,6 +982,11 @@ func (r *Registry) GetNotification(ctx context.Context, notificationId string) (
 	notification.CreatedAt = createdAt.Format(time.RFC3339Nano)
 	notification.Recipients = recipientsAgg
 
+	if sendAt != nil {
+		sendAtStr := sendAt.UTC().Format(time.RFC3339)
+		notification.SendAt = &sendAtStr
+	}
+
 	channels := make([]sdto.NotificationChannel, 0, len(channelsAgg))
 
 	for _, c := range channelsAgg {
@@ -658,9 +1017,17 @@ func (r *Registry) GetNotification(ctx context.Context, notificationId string) (
 			})
 		}
 
+		recipientVariables, err := r.getRecipientVariables(ctx, notificationId)
+
+		if err != nil {
+			return notification, err
+		}
+
 		notification.TemplateContents = &sdto.TemplateContents{
-			Id:        *templateId,
-			Variables: variables,
+			Id:                 *templateId,
+			Version:            templateVersion,
+			Variables:          variables,
+			RecipientVariables: recipientVariables,
 		}
 
 	} else {
@@ -673,6 +1040,83 @@ func (r *Registry) GetNotification(ctx context.Context, notificationId string) (
 	return notification, nil
 }
 
+func (r *Registry) GetDueNotifications(ctx context.Context, dueBefore time.Time, maxResults int) ([]string, error) {
+
+	args := pgx.NamedArgs{
+		"dueBefore": dueBefore,
+		"limit":     maxResults,
+	}
+
+	rows, err := r.conn.Query(ctx, getDueNotifications, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due notifications - %w", err)
+	}
+
+	defer rows.Close()
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	return ids, nil
+}
+
+// ClaimScheduledNotification moves a due notification from SCHEDULED to
+// CREATED and adds it to the outbox, so it's published by the relay. It
+// returns false if the notification isn't scheduled anymore, i.e., it was
+// canceled or claimed by another scheduler.
+func (r *Registry) ClaimScheduledNotification(ctx context.Context, payload sdto.NotificationMsgPayload) (bool, error) {
+
+	tx, err := r.conn.Begin(ctx)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	args := pgx.NamedArgs{"notificationId": payload.Id}
+	tag, err := tx.Exec(ctx, claimScheduledNotification, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return false, fmt.Errorf("failed to claim the notification - %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		tx.Rollback(ctx)
+		return false, nil
+	}
+
+	statusLog := sdto.NotificationStatusLog{
+		NotificationId: payload.Id,
+		Status:         sdto.Created,
+	}
+
+	err = r.createStatusLog(ctx, tx, statusLog)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return false, fmt.Errorf("failed to insert notification status logs - %w", err)
+	}
+
+	err = r.createOutboxEntry(ctx, tx, payload)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return false, fmt.Errorf("failed to create outbox entry - %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to commit notification claim - %w", err)
+	}
+
+	return true, nil
+}
+
 func (r *Registry) notificationExists(ctx context.Context, notificationId string) (bool, error) {
 
 	var exists string
@@ -840,3 +1284,111 @@ func (r *Registry) GetRecipientNotificationStatuses(ctx context.Context, notific
 
 	return page, nil
 }
+
+func (r *Registry) GetNotificationStatusLogs(ctx context.Context, notificationId string, filters sdto.PageFilter) (sdto.Page[dto.NotificationStatusLogResp], error) {
+	page := sdto.Page[dto.NotificationStatusLogResp]{}
+
+	exists, err := r.notificationExists(ctx, notificationId)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to check if notification exists - %w", err)
+	}
+
+	if !exists {
+		return page, internal.EntityNotFound{
+			Id:   notificationId,
+			Type: registry.NotificationType,
+		}
+	}
+
+	args := pgx.NamedArgs{
+		"limit":          internal.PageSize,
+		"notificationId": notificationId,
+	}
+
+	whereFilters := []string{"notification_id = @notificationId"}
+
+	if filters.MaxResults != nil {
+		args["limit"] = *filters.MaxResults
+	}
+
+	if filters.NextToken != nil {
+		var unmarsalledKey notificationStatusLogKey
+
+		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		if unmarsalledKey.NotificationId != notificationId {
+			return page, fmt.Errorf("notification id in the token does not match the notification id in the request")
+		}
+
+		args["statusDate"] = unmarsalledKey.StatusDate
+
+		// Tokens issued before the status was part of the key only have
+		// the date
+		if unmarsalledKey.Status != "" {
+			whereFilters = append(whereFilters, "(status_date, status) > (@statusDate, @status)")
+			args["status"] = unmarsalledKey.Status
+		} else {
+			whereFilters = append(whereFilters, "status_date > @statusDate")
+		}
+	}
+
+	whereStmt := strings.Join(whereFilters, " AND ")
+
+	query := fmt.Sprintf(getNotificationStatusLogs, whereStmt)
+
+	rows, err := r.conn.Query(ctx, query, args)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to query rows - %w", err)
+	}
+
+	defer rows.Close()
+
+	collectedLogs, err := pgx.CollectRows(rows, pgx.RowToStructByName[notificationStatusLog])
+
+	if err != nil {
+		return page, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	logs := make([]dto.NotificationStatusLogResp, 0, len(collectedLogs))
+
+	for _, l := range collectedLogs {
+		logs = append(logs, dto.NotificationStatusLogResp{
+			NotificationStatusLog: sdto.NotificationStatusLog{
+				NotificationId: notificationId,
+				Status:         sdto.NotificationStatus(l.Status),
+				ErrorMsg:       l.ErrorMessage,
+			},
+			StatusDate: l.StatusDate.Format(time.RFC3339Nano),
+		})
+	}
+
+	numLogs := len(logs)
+
+	if numLogs == args["limit"] {
+		lastKey := notificationStatusLogKey{
+			NotificationId: notificationId,
+			StatusDate:     logs[numLogs-1].StatusDate,
+			Status:         string(logs[numLogs-1].Status),
+		}
+
+		key, err := registry.MarshalKey(lastKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &key
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = len(logs)
+	page.Data = logs
+
+	return page, nil
+}
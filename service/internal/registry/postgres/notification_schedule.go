@@ -0,0 +1,480 @@
+package postgresresgistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/registry"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const notificationScheduleColumns = `
+	id,
+	"name",
+	cron_expression,
+	timezone,
+	notification,
+	paused,
+	next_run_at,
+	last_run_at,
+	created_by,
+	created_at,
+	updated_by,
+	updated_at
+`
+
+const insertNotificationSchedule = `
+INSERT INTO notification_schedules (
+	id,
+	"name",
+	cron_expression,
+	timezone,
+	notification,
+	next_run_at,
+	created_by,
+	created_at
+) VALUES (
+	@id,
+	@name,
+	@cronExpression,
+	@timezone,
+	@notification,
+	@nextRunAt,
+	@createdBy,
+	@createdAt
+)
+RETURNING` + notificationScheduleColumns + `;`
+
+const getNotificationSchedule = `
+SELECT` + notificationScheduleColumns + `
+FROM
+	notification_schedules
+WHERE
+	id = $1;
+`
+
+const getNotificationSchedules = `
+SELECT` + notificationScheduleColumns + `
+FROM
+	notification_schedules
+%s
+ORDER BY
+	id ASC
+LIMIT
+	@limit;
+`
+
+const updateNotificationSchedule = `
+UPDATE
+	notification_schedules
+SET
+	"name" = @name,
+	cron_expression = @cronExpression,
+	timezone = @timezone,
+	notification = @notification,
+	next_run_at = @nextRunAt,
+	updated_by = @updatedBy,
+	updated_at = @updatedAt
+WHERE
+	id = @id
+RETURNING` + notificationScheduleColumns + `;`
+
+const pauseNotificationSchedule = `
+UPDATE
+	notification_schedules
+SET
+	paused = TRUE,
+	updated_by = @updatedBy,
+	updated_at = @updatedAt
+WHERE
+	id = @id
+RETURNING` + notificationScheduleColumns + `;`
+
+const resumeNotificationSchedule = `
+UPDATE
+	notification_schedules
+SET
+	paused = FALSE,
+	next_run_at = @nextRunAt,
+	updated_by = @updatedBy,
+	updated_at = @updatedAt
+WHERE
+	id = @id
+RETURNING` + notificationScheduleColumns + `;`
+
+const deleteNotificationSchedule = `
+DELETE FROM
+	notification_schedules
+WHERE
+	id = $1;
+`
+
+const getDueNotificationSchedules = `
+SELECT` + notificationScheduleColumns + `
+FROM
+	notification_schedules
+WHERE
+	NOT paused
+	AND next_run_at <= @dueBefore
+ORDER BY
+	next_run_at ASC
+LIMIT
+	@limit;
+`
+
+const advanceNotificationSchedule = `
+UPDATE
+	notification_schedules
+SET
+	next_run_at = @nextRunAt,
+	last_run_at = @runAt
+WHERE
+	id = @id
+	AND NOT paused
+	AND next_run_at = @runAt;
+`
+
+type notificationScheduleKey struct {
+	Id string `json:"id"`
+}
+
+func scanSchedule(row pgx.Row) (dto.NotificationScheduleResp, error) {
+
+	schedule := dto.NotificationScheduleResp{}
+
+	var notification []byte
+	var nextRunAt, lastRunAt, updatedAt *time.Time
+	var createdAt time.Time
+
+	err := row.Scan(
+		&schedule.Id,
+		&schedule.Name,
+		&schedule.CronExpression,
+		&schedule.Timezone,
+		&notification,
+		&schedule.Paused,
+		&nextRunAt,
+		&lastRunAt,
+		&schedule.CreatedBy,
+		&createdAt,
+		&schedule.UpdatedBy,
+		&updatedAt,
+	)
+
+	if err != nil {
+		return schedule, err
+	}
+
+	err = json.Unmarshal(notification, &schedule.Notification)
+
+	if err != nil {
+		return schedule, fmt.Errorf("failed to unmarshal schedule notification - %w", err)
+	}
+
+	formatOptional := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+
+		formatted := t.UTC().Format(time.RFC3339)
+		return &formatted
+	}
+
+	schedule.CreatedAt = createdAt.Format(time.RFC3339)
+	schedule.NextRunAt = formatOptional(nextRunAt)
+	schedule.LastRunAt = formatOptional(lastRunAt)
+	schedule.UpdatedAt = formatOptional(updatedAt)
+
+	return schedule, nil
+}
+
+func (r *Registry) SaveSchedule(ctx context.Context, createdBy string, schedule dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+
+	id, err := uuid.NewV7()
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to generate schedule id - %w", err)
+	}
+
+	notification, err := json.Marshal(schedule.Notification)
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to marshal schedule notification - %w", err)
+	}
+
+	args := pgx.NamedArgs{
+		"id":             id.String(),
+		"name":           schedule.Name,
+		"cronExpression": schedule.CronExpression,
+		"timezone":       schedule.Timezone,
+		"notification":   string(notification),
+		"nextRunAt":      nextRunAt,
+		"createdBy":      createdBy,
+		"createdAt":      time.Now().Format(time.RFC3339Nano),
+	}
+
+	resp, err := scanSchedule(r.conn.QueryRow(ctx, insertNotificationSchedule, args))
+
+	if err != nil {
+		return resp, fmt.Errorf("failed to insert schedule - %w", err)
+	}
+
+	return resp, nil
+}
+
+func (r *Registry) GetSchedules(ctx context.Context, filters sdto.PageFilter) (sdto.Page[dto.NotificationScheduleSummary], error) {
+
+	page := sdto.Page[dto.NotificationScheduleSummary]{}
+
+	args := pgx.NamedArgs{"limit": internal.PageSize}
+	whereStmt := ""
+
+	if filters.MaxResults != nil {
+		args["limit"] = *filters.MaxResults
+	}
+
+	if filters.NextToken != nil {
+		whereStmt = "WHERE id > @id"
+
+		var unmarsalledKey notificationScheduleKey
+		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		args["id"] = unmarsalledKey.Id
+	}
+
+	query := fmt.Sprintf(getNotificationSchedules, whereStmt)
+
+	rows, err := r.conn.Query(ctx, query, args)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to query rows - %w", err)
+	}
+
+	defer rows.Close()
+
+	schedules, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (dto.NotificationScheduleResp, error) {
+		return scanSchedule(row)
+	})
+
+	if err != nil {
+		return page, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	summaries := make([]dto.NotificationScheduleSummary, 0, len(schedules))
+
+	for _, s := range schedules {
+		summaries = append(summaries, dto.NotificationScheduleSummary{
+			Id:             s.Id,
+			Name:           s.Name,
+			CronExpression: s.CronExpression,
+			Timezone:       s.Timezone,
+			Paused:         s.Paused,
+			NextRunAt:      s.NextRunAt,
+			LastRunAt:      s.LastRunAt,
+		})
+	}
+
+	numSummaries := len(summaries)
+
+	if numSummaries == args["limit"] {
+		lastKey := notificationScheduleKey{Id: summaries[numSummaries-1].Id}
+
+		key, err := registry.MarshalKey(lastKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &key
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = numSummaries
+	page.Data = summaries
+
+	return page, nil
+}
+
+func (r *Registry) GetSchedule(ctx context.Context, scheduleId string) (dto.NotificationScheduleResp, error) {
+
+	schedule, err := scanSchedule(r.conn.QueryRow(ctx, getNotificationSchedule, scheduleId))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schedule, internal.EntityNotFound{
+			Id:   scheduleId,
+			Type: registry.NotificationScheduleType,
+		}
+	} else if err != nil {
+		return schedule, fmt.Errorf("failed to query the schedule - %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (r *Registry) updateSchedule(ctx context.Context, query string, args pgx.NamedArgs) (dto.NotificationScheduleResp, error) {
+
+	schedule, err := scanSchedule(r.conn.QueryRow(ctx, query, args))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schedule, internal.EntityNotFound{
+			Id:   args["id"].(string),
+			Type: registry.NotificationScheduleType,
+		}
+	} else if err != nil {
+		return schedule, fmt.Errorf("failed to update the schedule - %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (r *Registry) UpdateSchedule(ctx context.Context, scheduleId, updatedBy string, schedule dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+
+	notification, err := json.Marshal(schedule.Notification)
+
+	if err != nil {
+		return dto.NotificationScheduleResp{}, fmt.Errorf("failed to marshal schedule notification - %w", err)
+	}
+
+	args := pgx.NamedArgs{
+		"id":             scheduleId,
+		"name":           schedule.Name,
+		"cronExpression": schedule.CronExpression,
+		"timezone":       schedule.Timezone,
+		"notification":   string(notification),
+		"nextRunAt":      nextRunAt,
+		"updatedBy":      updatedBy,
+		"updatedAt":      time.Now().Format(time.RFC3339Nano),
+	}
+
+	return r.updateSchedule(ctx, updateNotificationSchedule, args)
+}
+
+func (r *Registry) PauseSchedule(ctx context.Context, scheduleId, updatedBy string) (dto.NotificationScheduleResp, error) {
+
+	args := pgx.NamedArgs{
+		"id":        scheduleId,
+		"updatedBy": updatedBy,
+		"updatedAt": time.Now().Format(time.RFC3339Nano),
+	}
+
+	return r.updateSchedule(ctx, pauseNotificationSchedule, args)
+}
+
+func (r *Registry) ResumeSchedule(ctx context.Context, scheduleId, updatedBy string, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+
+	args := pgx.NamedArgs{
+		"id":        scheduleId,
+		"nextRunAt": nextRunAt,
+		"updatedBy": updatedBy,
+		"updatedAt": time.Now().Format(time.RFC3339Nano),
+	}
+
+	return r.updateSchedule(ctx, resumeNotificationSchedule, args)
+}
+
+func (r *Registry) DeleteSchedule(ctx context.Context, scheduleId string) error {
+
+	_, err := r.conn.Exec(ctx, deleteNotificationSchedule, scheduleId)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule - %w", err)
+	}
+
+	return nil
+}
+
+func (r *Registry) GetDueSchedules(ctx context.Context, dueBefore time.Time, maxResults int) ([]dto.NotificationScheduleResp, error) {
+
+	args := pgx.NamedArgs{
+		"dueBefore": dueBefore,
+		"limit":     maxResults,
+	}
+
+	rows, err := r.conn.Query(ctx, getDueNotificationSchedules, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due schedules - %w", err)
+	}
+
+	defer rows.Close()
+
+	schedules, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (dto.NotificationScheduleResp, error) {
+		return scanSchedule(row)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	return schedules, nil
+}
+
+func (r *Registry) AdvanceSchedule(ctx context.Context, scheduleId string, runAt, nextRunAt time.Time) (bool, error) {
+
+	args := pgx.NamedArgs{
+		"id":        scheduleId,
+		"runAt":     runAt,
+		"nextRunAt": nextRunAt,
+	}
+
+	tag, err := r.conn.Exec(ctx, advanceNotificationSchedule, args)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to advance schedule - %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *Registry) RunSchedule(ctx context.Context, schedule dto.NotificationScheduleResp, runAt, nextRunAt time.Time) (bool, error) {
+
+	tx, err := r.conn.Begin(ctx)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	args := pgx.NamedArgs{
+		"id":        schedule.Id,
+		"runAt":     runAt,
+		"nextRunAt": nextRunAt,
+	}
+
+	tag, err := tx.Exec(ctx, advanceNotificationSchedule, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return false, fmt.Errorf("failed to advance schedule - %w", err)
+	}
+
+	if tag.RowsAffected() != 1 {
+		tx.Rollback(ctx)
+		return false, nil
+	}
+
+	_, err = r.insertNotification(ctx, tx, schedule.CreatedBy, schedule.Notification)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return false, err
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to commit schedule run - %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,175 @@
+package postgresresgistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/notifique/service/internal/dto"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const insertOutboxEntry = `
+INSERT INTO notification_outbox (
+	notification_id,
+	payload,
+	available_at
+) VALUES (
+	@notificationId,
+	@payload,
+	@availableAt
+);
+`
+
+const getOutboxEntries = `
+SELECT
+	payload,
+	attempts,
+	available_at,
+	last_error
+FROM
+	notification_outbox
+WHERE
+	available_at <= @availableBefore
+ORDER BY
+	available_at ASC
+LIMIT
+	@limit;
+`
+
+const claimOutboxEntry = `
+UPDATE
+	notification_outbox
+SET
+	attempts = attempts + 1,
+	available_at = @leaseUntil
+WHERE
+	notification_id = @notificationId
+	AND attempts = @attempts;
+`
+
+const retryOutboxEntry = `
+UPDATE
+	notification_outbox
+SET
+	available_at = @retryAt,
+	last_error = @lastError
+WHERE
+	notification_id = @notificationId;
+`
+
+const deleteOutboxEntry = `
+DELETE FROM
+	notification_outbox
+WHERE
+	notification_id = $1;
+`
+
+func (r *Registry) createOutboxEntry(ctx context.Context, tx pgx.Tx, payload sdto.NotificationMsgPayload) error {
+
+	marshalled, err := json.Marshal(payload)
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox payload - %w", err)
+	}
+
+	args := pgx.NamedArgs{
+		"notificationId": payload.Id,
+		"payload":        string(marshalled),
+		"availableAt":    time.Now().Format(time.RFC3339Nano),
+	}
+
+	_, err = tx.Exec(ctx, insertOutboxEntry, args)
+
+	return err
+}
+
+func (r *Registry) GetOutboxEntries(ctx context.Context, availableBefore time.Time, maxResults int) ([]dto.OutboxEntry, error) {
+
+	args := pgx.NamedArgs{
+		"availableBefore": availableBefore,
+		"limit":           maxResults,
+	}
+
+	rows, err := r.conn.Query(ctx, getOutboxEntries, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox entries - %w", err)
+	}
+
+	defer rows.Close()
+
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (dto.OutboxEntry, error) {
+		entry := dto.OutboxEntry{}
+
+		var payload []byte
+		var availableAt time.Time
+
+		err := row.Scan(&payload, &entry.Attempts, &availableAt, &entry.LastError)
+
+		if err != nil {
+			return entry, err
+		}
+
+		if err := json.Unmarshal(payload, &entry.Payload); err != nil {
+			return entry, fmt.Errorf("failed to unmarshal outbox payload - %w", err)
+		}
+
+		entry.AvailableAt = availableAt.UTC().Format(time.RFC3339Nano)
+
+		return entry, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *Registry) ClaimOutboxEntry(ctx context.Context, notificationId string, attempts int, leaseUntil time.Time) (bool, error) {
+
+	args := pgx.NamedArgs{
+		"notificationId": notificationId,
+		"attempts":       attempts,
+		"leaseUntil":     leaseUntil,
+	}
+
+	tag, err := r.conn.Exec(ctx, claimOutboxEntry, args)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to claim outbox entry - %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *Registry) RetryOutboxEntry(ctx context.Context, notificationId string, retryAt time.Time, errorMsg string) error {
+
+	args := pgx.NamedArgs{
+		"notificationId": notificationId,
+		"retryAt":        retryAt,
+		"lastError":      errorMsg,
+	}
+
+	_, err := r.conn.Exec(ctx, retryOutboxEntry, args)
+
+	if err != nil {
+		return fmt.Errorf("failed to update outbox entry - %w", err)
+	}
+
+	return nil
+}
+
+func (r *Registry) DeleteOutboxEntry(ctx context.Context, notificationId string) error {
+
+	_, err := r.conn.Exec(ctx, deleteOutboxEntry, notificationId)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete outbox entry - %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,508 @@
+package postgresresgistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/registry"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const insertTemplatePartial = `
+INSERT INTO notification_template_partials (
+	"name",
+	kind,
+	"version",
+	"description",
+	contents,
+	created_by,
+	created_at
+) VALUES (
+	@name,
+	@kind,
+	@version,
+	@description,
+	@contents,
+	@createdBy,
+	@createdAt
+) ON CONFLICT ("name") DO NOTHING;
+`
+
+const insertTemplatePartialVersion = `
+INSERT INTO notification_template_partial_versions (
+	partial_name,
+	"version",
+	"description",
+	contents,
+	created_by,
+	created_at
+) VALUES (
+	@name,
+	@version,
+	@description,
+	@contents,
+	@createdBy,
+	@createdAt
+);
+`
+
+const getTemplatePartial = `
+SELECT
+	"name",
+	kind,
+	"version",
+	"description",
+	contents,
+	created_by,
+	created_at,
+	updated_by,
+	updated_at
+FROM
+	notification_template_partials
+WHERE
+	"name" = $1;
+`
+
+const getTemplatePartialVersion = `
+SELECT
+	p."name",
+	p.kind,
+	v."version",
+	v."description",
+	v.contents,
+	p.created_by,
+	p.created_at,
+	v.created_by AS version_created_by,
+	v.created_at AS version_created_at
+FROM
+	notification_template_partial_versions AS v
+JOIN
+	notification_template_partials AS p ON
+		p."name" = v.partial_name
+WHERE
+	v.partial_name = $1 AND v."version" = $2;
+`
+
+const getTemplatePartialsInfo = `
+SELECT
+	"name",
+	kind,
+	"version",
+	"description"
+FROM
+	notification_template_partials
+%s
+ORDER BY
+	"name" ASC
+LIMIT
+	@limit;
+`
+
+const lockTemplatePartial = `
+SELECT
+	"version"
+FROM
+	notification_template_partials
+WHERE
+	"name" = $1
+FOR UPDATE;
+`
+
+const updateTemplatePartial = `
+UPDATE
+	notification_template_partials
+SET
+	"description" = @description,
+	contents = @contents,
+	"version" = @version,
+	updated_by = @updatedBy,
+	updated_at = @updatedAt
+WHERE
+	"name" = @name;
+`
+
+const getTemplatePartialIsUsed = `
+SELECT EXISTS (
+	SELECT 1 FROM notification_templates
+	WHERE archived_at IS NULL AND $1 = ANY(partials)
+);
+`
+
+const deleteTemplatePartial = `
+DELETE FROM
+	notification_template_partials
+WHERE
+	"name" = $1;
+`
+
+type templatePartialInfo struct {
+	Name        string `db:"name"`
+	Kind        string `db:"kind"`
+	Version     int    `db:"version"`
+	Description string `db:"description"`
+}
+
+type templatePartialKey struct {
+	Name string
+	Kind *string
+}
+
+func makeTemplatePartialNotFound(name string, version *int) internal.EntityNotFound {
+
+	if version == nil {
+		return internal.EntityNotFound{
+			Id:   name,
+			Type: registry.TemplatePartialType,
+		}
+	}
+
+	return internal.EntityNotFound{
+		Id:   fmt.Sprintf("%s (version %d)", name, *version),
+		Type: registry.TemplatePartialVersionType,
+	}
+}
+
+func (r *Registry) SaveTemplatePartial(ctx context.Context, createdBy string, tpr dto.TemplatePartialReq) (sdto.TemplatePartialDetails, error) {
+
+	details := sdto.TemplatePartialDetails{}
+
+	tx, err := r.conn.Begin(ctx)
+
+	if err != nil {
+		return details, fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	createdAt := time.Now().Format(time.RFC3339)
+
+	args := pgx.NamedArgs{
+		"name":        tpr.Name,
+		"kind":        tpr.Kind,
+		"version":     1,
+		"description": tpr.Description,
+		"contents":    tpr.Contents,
+		"createdBy":   createdBy,
+		"createdAt":   createdAt,
+	}
+
+	tag, err := tx.Exec(ctx, insertTemplatePartial, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return details, fmt.Errorf("failed to insert template partial - %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		tx.Rollback(ctx)
+		return details, internal.TemplatePartialAlreadyExists{Name: tpr.Name}
+	}
+
+	_, err = tx.Exec(ctx, insertTemplatePartialVersion, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return details, fmt.Errorf("failed to insert template partial version - %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return details, fmt.Errorf("failed to commit template partial insert - %w", err)
+	}
+
+	details.Name = tpr.Name
+	details.Kind = tpr.Kind
+	details.Version = 1
+	details.Description = tpr.Description
+	details.Contents = tpr.Contents
+	details.CreatedBy = createdBy
+	details.CreatedAt = createdAt
+
+	return details, nil
+}
+
+func (r *Registry) GetTemplatePartials(ctx context.Context, filters dto.TemplatePartialFilters) (sdto.Page[dto.TemplatePartialInfoResp], error) {
+
+	page := sdto.Page[dto.TemplatePartialInfoResp]{}
+
+	args := pgx.NamedArgs{"limit": internal.PageSize}
+
+	whereFilters := []string{}
+
+	if filters.MaxResults != nil {
+		args["limit"] = *filters.MaxResults
+	}
+
+	if filters.NextToken != nil {
+		whereFilters = append(whereFilters, `"name" > @name`)
+
+		var unmarsalledKey templatePartialKey
+		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		if unmarsalledKey.Kind != filters.Kind {
+			return page, fmt.Errorf("invalid next token %s", *filters.NextToken)
+		}
+
+		args["name"] = unmarsalledKey.Name
+	}
+
+	if filters.Kind != nil {
+		whereFilters = append(whereFilters, "kind = @kind")
+		args["kind"] = *filters.Kind
+	}
+
+	whereStmt := ""
+
+	if len(whereFilters) != 0 {
+		whereStmt = fmt.Sprintf("WHERE %s", strings.Join(whereFilters, " AND "))
+	}
+
+	query := fmt.Sprintf(getTemplatePartialsInfo, whereStmt)
+
+	rows, err := r.conn.Query(ctx, query, args)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to query rows - %w", err)
+	}
+
+	defer rows.Close()
+
+	partials, err := pgx.CollectRows(rows, pgx.RowToStructByName[templatePartialInfo])
+
+	if err != nil {
+		return page, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	page.Data = make([]dto.TemplatePartialInfoResp, 0, len(partials))
+
+	for _, p := range partials {
+		page.Data = append(page.Data, dto.TemplatePartialInfoResp{
+			Name:        p.Name,
+			Kind:        p.Kind,
+			Version:     p.Version,
+			Description: p.Description,
+		})
+	}
+
+	numPartials := len(partials)
+
+	if numPartials == args["limit"] {
+		lastPartialKey := templatePartialKey{
+			Name: partials[numPartials-1].Name,
+			Kind: filters.Kind,
+		}
+
+		key, err := registry.MarshalKey(lastPartialKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &key
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = numPartials
+
+	return page, nil
+}
+
+func (r *Registry) GetTemplatePartial(ctx context.Context, name string) (sdto.TemplatePartialDetails, error) {
+
+	details := sdto.TemplatePartialDetails{}
+
+	var createdAt time.Time
+	var updatedAt *time.Time
+
+	err := r.conn.QueryRow(ctx, getTemplatePartial, name).
+		Scan(
+			&details.Name,
+			&details.Kind,
+			&details.Version,
+			&details.Description,
+			&details.Contents,
+			&details.CreatedBy,
+			&createdAt,
+			&details.UpdatedBy,
+			&updatedAt,
+		)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return details, makeTemplatePartialNotFound(name, nil)
+	}
+
+	if err != nil {
+		return details, fmt.Errorf("failed to retrieve template partial - %w", err)
+	}
+
+	details.CreatedAt = createdAt.Format(time.RFC3339)
+
+	if updatedAt != nil {
+		updatedAtStr := updatedAt.Format(time.RFC3339)
+		details.UpdatedAt = &updatedAtStr
+	}
+
+	return details, nil
+}
+
+func (r *Registry) GetTemplatePartialVersion(ctx context.Context, name string, version int) (sdto.TemplatePartialDetails, error) {
+
+	details := sdto.TemplatePartialDetails{}
+
+	var createdAt, versionCreatedAt time.Time
+	var versionCreatedBy string
+
+	err := r.conn.QueryRow(ctx, getTemplatePartialVersion, name, version).
+		Scan(
+			&details.Name,
+			&details.Kind,
+			&details.Version,
+			&details.Description,
+			&details.Contents,
+			&details.CreatedBy,
+			&createdAt,
+			&versionCreatedBy,
+			&versionCreatedAt,
+		)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return details, makeTemplatePartialNotFound(name, &version)
+	}
+
+	if err != nil {
+		return details, fmt.Errorf("failed to retrieve template partial version - %w", err)
+	}
+
+	details.CreatedAt = createdAt.Format(time.RFC3339)
+
+	// Every version after the first one is an update of the partial
+	if details.Version > 1 {
+		updatedAt := versionCreatedAt.Format(time.RFC3339)
+		details.UpdatedAt = &updatedAt
+		details.UpdatedBy = &versionCreatedBy
+	}
+
+	return details, nil
+}
+
+func (r *Registry) UpdateTemplatePartial(ctx context.Context, name, updatedBy string, tpr dto.TemplatePartialUpdateReq) (sdto.TemplatePartialDetails, error) {
+
+	tx, err := r.conn.Begin(ctx)
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	// Locks the partial so concurrent updates create sequential versions
+	var version int
+	err = tx.QueryRow(ctx, lockTemplatePartial, name).Scan(&version)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		tx.Rollback(ctx)
+		return sdto.TemplatePartialDetails{}, makeTemplatePartialNotFound(name, nil)
+	}
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to lock the template partial - %w", err)
+	}
+
+	version++
+	updatedAt := time.Now().Format(time.RFC3339)
+
+	args := pgx.NamedArgs{
+		"name":        name,
+		"version":     version,
+		"description": tpr.Description,
+		"contents":    tpr.Contents,
+		"updatedBy":   updatedBy,
+		"updatedAt":   updatedAt,
+		"createdBy":   updatedBy,
+		"createdAt":   updatedAt,
+	}
+
+	_, err = tx.Exec(ctx, updateTemplatePartial, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to update template partial - %w", err)
+	}
+
+	_, err = tx.Exec(ctx, insertTemplatePartialVersion, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to insert template partial version - %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return sdto.TemplatePartialDetails{}, fmt.Errorf("failed to commit template partial update - %w", err)
+	}
+
+	return r.GetTemplatePartialVersion(ctx, name, version)
+}
+
+// DeleteTemplatePartial deletes the partial and its versions, which is
+// refused while templates that aren't archived use it. Deleting a
+// partial that doesn't exist does nothing.
+func (r *Registry) DeleteTemplatePartial(ctx context.Context, name string) error {
+
+	tx, err := r.conn.Begin(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	var version int
+	err = tx.QueryRow(ctx, lockTemplatePartial, name).Scan(&version)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		tx.Rollback(ctx)
+		return nil
+	}
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to lock the template partial - %w", err)
+	}
+
+	var used bool
+	err = tx.QueryRow(ctx, getTemplatePartialIsUsed, name).Scan(&used)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to check the template partial usage - %w", err)
+	}
+
+	if used {
+		tx.Rollback(ctx)
+		return internal.TemplatePartialInUse{Name: name}
+	}
+
+	// Relies on ON DELETE CASCADE constraint to delete the versions
+	_, err = tx.Exec(ctx, deleteTemplatePartial, name)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to delete template partial - %w", err)
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to commit changes - %w", err)
+	}
+
+	return nil
+}
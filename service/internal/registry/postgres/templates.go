@@ -20,18 +20,30 @@ INSERT INTO notification_templates (
 	id,
 	name,
 	is_html,
+	"format",
 	title_template,
 	contents_template,
 	description,
+	category,
+	tags,
+	layout,
+	partials,
+	default_locale,
 	created_by,
 	created_at
 ) VALUES (
 	@id,
 	@name,
 	@isHtml,
+	@format,
 	@titleTemplate,
 	@contentsTemplate,
 	@description,
+	@category,
+	COALESCE(@tags::VARCHAR[], '{}'),
+	@layout,
+	COALESCE(@partials::VARCHAR[], '{}'),
+	@defaultLocale,
 	@createdBy,
 	@createdAt
 );
@@ -43,21 +55,262 @@ INSERT INTO notification_template_variables (
 	name,
 	type,
 	required,
-	validation
+	validation,
+	min_value,
+	max_value,
+	min_length,
+	max_length,
+	allowed_values
 ) VALUES (
 	@templateId,
 	@name,
 	@type,
 	@required,
-	@validation
+	@validation,
+	@minValue,
+	@maxValue,
+	@minLength,
+	@maxLength,
+	@allowedValues
 );
 `
 
+const insertNotificationTemplateVersion = `
+INSERT INTO notification_template_versions (
+	template_id,
+	version,
+	name,
+	is_html,
+	"format",
+	title_template,
+	contents_template,
+	description,
+	category,
+	tags,
+	layout,
+	partials,
+	default_locale,
+	created_by,
+	created_at
+) VALUES (
+	@templateId,
+	@version,
+	@name,
+	@isHtml,
+	@format,
+	@titleTemplate,
+	@contentsTemplate,
+	@description,
+	@category,
+	COALESCE(@tags::VARCHAR[], '{}'),
+	@layout,
+	COALESCE(@partials::VARCHAR[], '{}'),
+	@defaultLocale,
+	@createdBy,
+	@createdAt
+);
+`
+
+const insertNotificationTemplateVersionVariables = `
+INSERT INTO notification_template_version_variables (
+	template_id,
+	version,
+	name,
+	type,
+	required,
+	validation,
+	min_value,
+	max_value,
+	min_length,
+	max_length,
+	allowed_values
+) VALUES (
+	@templateId,
+	@version,
+	@name,
+	@type,
+	@required,
+	@validation,
+	@minValue,
+	@maxValue,
+	@minLength,
+	@maxLength,
+	@allowedValues
+);
+`
+
+const insertNotificationTemplateVersionLocalization = `
+INSERT INTO notification_template_version_localizations (
+	template_id,
+	version,
+	locale,
+	title_template,
+	contents_template
+) VALUES (
+	@templateId,
+	@version,
+	@locale,
+	@titleTemplate,
+	@contentsTemplate
+);
+`
+
+const getTemplateVersionLocalizations = `
+SELECT
+	locale,
+	title_template,
+	contents_template
+FROM
+	notification_template_version_localizations
+WHERE
+	template_id = $1 AND "version" = $2
+ORDER BY
+	locale ASC;
+`
+
+const lockNotificationTemplateVersion = `
+SELECT
+	"version",
+	archived_at
+FROM
+	notification_templates
+WHERE
+	id = $1
+FOR UPDATE;
+`
+
+const updateNotificationTemplate = `
+UPDATE
+	notification_templates
+SET
+	"name" = @name,
+	is_html = @isHtml,
+	"format" = @format,
+	title_template = @titleTemplate,
+	contents_template = @contentsTemplate,
+	"description" = @description,
+	category = @category,
+	tags = COALESCE(@tags::VARCHAR[], '{}'),
+	layout = @layout,
+	partials = COALESCE(@partials::VARCHAR[], '{}'),
+	default_locale = @defaultLocale,
+	"version" = @version,
+	updated_by = @updatedBy,
+	updated_at = @updatedAt
+WHERE
+	id = @id;
+`
+
+const deleteNotificationTemplateVariables = `
+DELETE FROM
+	notification_template_variables
+WHERE
+	template_id = $1;
+`
+
+const getTemplateVersion = `
+SELECT
+	"version",
+	archived_at
+FROM
+	notification_templates
+WHERE
+	id = $1;
+`
+
+const lockActiveTemplateVersion = `
+SELECT
+	"version",
+	archived_at
+FROM
+	notification_templates
+WHERE
+	id = $1
+FOR SHARE;
+`
+
+const getNotificationTemplateVersionExists = `
+SELECT
+	"version"
+FROM
+	notification_template_versions
+WHERE
+	template_id = $1 AND "version" = $2;
+`
+
+const getNotificationTemplateVersions = `
+SELECT
+	"version",
+	"name",
+	created_by,
+	created_at
+FROM
+	notification_template_versions
+WHERE
+	%s
+ORDER BY
+	"version" DESC
+LIMIT
+	@limit;
+`
+
+const getNotificationTemplateVersionDetails = `
+SELECT
+	t.id,
+	v."name",
+	v."version",
+	v.is_html,
+	v."format",
+	v.title_template,
+	v.contents_template,
+	v."description",
+	v.category,
+	v.tags,
+	v.layout,
+	v.default_locale,
+	t.created_by,
+	t.created_at,
+	v.created_by AS version_created_by,
+	v.created_at AS version_created_at,
+	t.archived_by,
+	t.archived_at
+FROM
+	notification_template_versions AS v
+JOIN
+	notification_templates AS t ON
+		t.id = v.template_id
+WHERE
+	v.template_id = $1 AND v."version" = $2;
+`
+
+const getTemplateVersionVariables = `
+SELECT
+	"name",
+	"type",
+	"required",
+	"validation",
+	min_value,
+	max_value,
+	min_length,
+	max_length,
+	allowed_values
+FROM
+	notification_template_version_variables
+WHERE
+	template_id = $1 AND "version" = $2;
+`
+
 const getNotificationTemplateInfo = `
-SELECT 
+SELECT
 	id,
 	"name",
-	description
+	description,
+	"format",
+	category,
+	tags,
+	created_by,
+	created_at,
+	updated_at
 FROM
 	notification_templates
 %s
@@ -71,14 +324,22 @@ const getNotificationTemplateDetails = `
 SELECT
 	id,
 	"name",
+	"version",
 	is_html,
+	"format",
 	title_template,
 	contents_template,
 	"description",
+	category,
+	tags,
+	layout,
+	default_locale,
 	created_by,
 	created_at,
 	updated_by,
-	updated_at
+	updated_at,
+	archived_by,
+	archived_at
 FROM
 	notification_templates
 WHERE
@@ -90,13 +351,48 @@ SELECT
 	"name",
 	"type",
 	"required",
-	"validation"
+	"validation",
+	min_value,
+	max_value,
+	min_length,
+	max_length,
+	allowed_values
 FROM
 	notification_template_variables
 WHERE
 	template_id = $1;
 `
 
+const archiveTemplate = `
+UPDATE
+	notification_templates
+SET
+	archived_by = $2,
+	archived_at = $3
+WHERE
+	id = $1 AND archived_at IS NULL;
+`
+
+const lockTemplate = `
+SELECT
+	archived_at
+FROM
+	notification_templates
+WHERE
+	id = $1
+FOR UPDATE;
+`
+
+const getTemplateIsReferenced = `
+SELECT
+	EXISTS (
+		SELECT 1 FROM notifications WHERE template_id = $1
+	) OR EXISTS (
+		SELECT 1 FROM notification_schedules
+		WHERE notification->'template'->>'id' = $1::text
+	);
+`
+
 const deleteTemplateInfo = `
 DELETE FROM
 	notification_templates
@@ -105,9 +401,15 @@ WHERE
 `
 
 type notificationTemplateInfo struct {
-	Id          string `db:"id"`
-	Name        string `db:"name"`
-	Description string `db:"description"`
+	Id          string              `db:"id"`
+	Name        string              `db:"name"`
+	Description string              `db:"description"`
+	Format      sdto.TemplateFormat `db:"format"`
+	Category    *string             `db:"category"`
+	Tags        []string            `db:"tags"`
+	CreatedBy   string              `db:"created_by"`
+	CreatedAt   time.Time           `db:"created_at"`
+	UpdatedAt   *time.Time          `db:"updated_at"`
 }
 
 type notificationTemplateKey struct {
@@ -115,6 +417,128 @@ type notificationTemplateKey struct {
 	Name *string
 }
 
+type notificationTemplateVersion struct {
+	Version   int       `db:"version"`
+	Name      string    `db:"name"`
+	CreatedBy string    `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type notificationTemplateVersionKey struct {
+	Id      string
+	Version int
+}
+
+// templateVariableFields are the destinations of the scanned variable
+// columns, in the order they are selected.
+func templateVariableFields(v *sdto.TemplateVariable) []any {
+	return []any{
+		&v.Name,
+		&v.Type,
+		&v.Required,
+		&v.Validation,
+		&v.Min,
+		&v.Max,
+		&v.MinLength,
+		&v.MaxLength,
+		&v.Values,
+	}
+}
+
+// saveTemplateVersion stores the version of the template, which is
+// never modified afterwards, and makes its variables the current ones.
+func saveTemplateVersion(ctx context.Context, tx pgx.Tx, templateId string, version int, createdBy, createdAt string, ntr dto.NotificationTemplateReq) error {
+
+	args := pgx.NamedArgs{
+		"templateId":       templateId,
+		"version":          version,
+		"name":             ntr.Name,
+		"isHtml":           ntr.IsHtml,
+		"format":           ntr.Format,
+		"titleTemplate":    ntr.TitleTemplate,
+		"contentsTemplate": ntr.ContentsTemplate,
+		"description":      ntr.Description,
+		"category":         ntr.Category,
+		"tags":             ntr.Tags,
+		"layout":           ntr.Layout,
+		"partials":         ntr.Partials,
+		"defaultLocale":    ntr.DefaultLocale,
+		"createdBy":        createdBy,
+		"createdAt":        createdAt,
+	}
+
+	_, err := tx.Exec(ctx, insertNotificationTemplateVersion, args)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert template version - %w", err)
+	}
+
+	variableArgs := make([]pgx.NamedArgs, 0, len(ntr.Variables))
+
+	for _, v := range ntr.Variables {
+		variableArgs = append(variableArgs, pgx.NamedArgs{
+			"templateId":    templateId,
+			"version":       version,
+			"name":          v.Name,
+			"type":          v.Type,
+			"required":      v.Required,
+			"validation":    v.Validation,
+			"minValue":      v.Min,
+			"maxValue":      v.Max,
+			"minLength":     v.MinLength,
+			"maxLength":     v.MaxLength,
+			"allowedValues": v.Values,
+		})
+	}
+
+	err = batchInsert(
+		ctx,
+		insertNotificationTemplateVariables,
+		variableArgs,
+		tx,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert template variables - %w", err)
+	}
+
+	err = batchInsert(
+		ctx,
+		insertNotificationTemplateVersionVariables,
+		variableArgs,
+		tx,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert template version variables - %w", err)
+	}
+
+	localizationArgs := make([]pgx.NamedArgs, 0, len(ntr.Localizations))
+
+	for _, l := range ntr.Localizations {
+		localizationArgs = append(localizationArgs, pgx.NamedArgs{
+			"templateId":       templateId,
+			"version":          version,
+			"locale":           l.Locale,
+			"titleTemplate":    l.TitleTemplate,
+			"contentsTemplate": l.ContentsTemplate,
+		})
+	}
+
+	err = batchInsert(
+		ctx,
+		insertNotificationTemplateVersionLocalization,
+		localizationArgs,
+		tx,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert template localizations - %w", err)
+	}
+
+	return nil
+}
+
 func (r *Registry) SaveTemplate(ctx context.Context, createdBy string, ntr dto.NotificationTemplateReq) (dto.NotificationTemplateCreatedResp, error) {
 
 	resp := dto.NotificationTemplateCreatedResp{}
@@ -138,9 +562,15 @@ func (r *Registry) SaveTemplate(ctx context.Context, createdBy string, ntr dto.N
 		"id":               templateId,
 		"name":             ntr.Name,
 		"isHtml":           ntr.IsHtml,
+		"format":           ntr.Format,
 		"titleTemplate":    ntr.TitleTemplate,
 		"description":      ntr.Description,
+		"category":         ntr.Category,
+		"tags":             ntr.Tags,
 		"contentsTemplate": ntr.ContentsTemplate,
+		"layout":           ntr.Layout,
+		"partials":         ntr.Partials,
+		"defaultLocale":    ntr.DefaultLocale,
 		"createdBy":        createdBy,
 		"createdAt":        createdAt,
 	}
@@ -152,28 +582,11 @@ func (r *Registry) SaveTemplate(ctx context.Context, createdBy string, ntr dto.N
 		return resp, fmt.Errorf("failed to insert template - %w", err)
 	}
 
-	variableArgs := make([]pgx.NamedArgs, 0, len(ntr.Variables))
-
-	for _, v := range ntr.Variables {
-		variableArgs = append(variableArgs, pgx.NamedArgs{
-			"templateId": templateId,
-			"name":       v.Name,
-			"type":       v.Type,
-			"required":   v.Required,
-			"validation": v.Validation,
-		})
-	}
-
-	err = batchInsert(
-		ctx,
-		insertNotificationTemplateVariables,
-		variableArgs,
-		tx,
-	)
+	err = saveTemplateVersion(ctx, tx, templateId, 1, createdBy, createdAt, ntr)
 
 	if err != nil {
 		tx.Rollback(ctx)
-		return resp, fmt.Errorf("failed to insert template variables - %w", err)
+		return resp, err
 	}
 
 	err = tx.Commit(ctx)
@@ -195,7 +608,7 @@ func (r *Registry) GetTemplates(ctx context.Context, filters dto.NotificationTem
 
 	args := pgx.NamedArgs{"limit": internal.PageSize}
 
-	whereFilters := make([]string, 0)
+	whereFilters := []string{"archived_at IS NULL"}
 
 	if filters.MaxResults != nil {
 		args["limit"] = *filters.MaxResults
@@ -224,12 +637,54 @@ func (r *Registry) GetTemplates(ctx context.Context, filters dto.NotificationTem
 		args["name"] = fmt.Sprintf("%s%%", *filters.TemplateName)
 	}
 
-	whereStmt := strings.Join(whereFilters, "AND")
+	if filters.Category != nil {
+		whereFilters = append(whereFilters, "category = @category")
+		args["category"] = *filters.Category
+	}
+
+	if len(filters.Tags) != 0 {
+		whereFilters = append(whereFilters, "tags @> @tags::VARCHAR[]")
+		args["tags"] = filters.Tags
+	}
+
+	if filters.CreatedBy != nil {
+		whereFilters = append(whereFilters, "created_by = @createdBy")
+		args["createdBy"] = *filters.CreatedBy
+	}
+
+	if filters.Format != nil {
+		whereFilters = append(whereFilters, `"format" = @format`)
+		args["format"] = *filters.Format
+	}
 
-	if len(whereStmt) != 0 {
-		whereStmt = fmt.Sprintf("WHERE %s", whereStmt)
+	if filters.CreatedFrom != nil {
+		whereFilters = append(whereFilters, "created_at >= @createdFrom")
+		args["createdFrom"] = *filters.CreatedFrom
 	}
 
+	if filters.CreatedTo != nil {
+		whereFilters = append(whereFilters, "created_at <= @createdTo")
+		args["createdTo"] = *filters.CreatedTo
+	}
+
+	if filters.UpdatedFrom != nil {
+		whereFilters = append(whereFilters, "updated_at >= @updatedFrom")
+		args["updatedFrom"] = *filters.UpdatedFrom
+	}
+
+	if filters.UpdatedTo != nil {
+		whereFilters = append(whereFilters, "updated_at <= @updatedTo")
+		args["updatedTo"] = *filters.UpdatedTo
+	}
+
+	// Matches the expression of the trigram index
+	if filters.Search != nil {
+		whereFilters = append(whereFilters, `("name" || ' ' || "description") ILIKE @search`)
+		args["search"] = fmt.Sprintf("%%%s%%", escapeLike(*filters.Search))
+	}
+
+	whereStmt := fmt.Sprintf("WHERE %s", strings.Join(whereFilters, " AND "))
+
 	query := fmt.Sprintf(getNotificationTemplateInfo, whereStmt)
 
 	rows, err := r.conn.Query(ctx, query, args)
@@ -247,11 +702,23 @@ func (r *Registry) GetTemplates(ctx context.Context, filters dto.NotificationTem
 	}
 
 	for _, t := range templates {
-		page.Data = append(page.Data, dto.NotificationTemplateInfoResp{
+		info := dto.NotificationTemplateInfoResp{
 			Id:          t.Id,
 			Name:        t.Name,
 			Description: t.Description,
-		})
+			Format:      t.Format,
+			Category:    t.Category,
+			Tags:        nilIfEmpty(t.Tags),
+			CreatedBy:   t.CreatedBy,
+			CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+		}
+
+		if t.UpdatedAt != nil {
+			updatedAt := t.UpdatedAt.Format(time.RFC3339)
+			info.UpdatedAt = &updatedAt
+		}
+
+		page.Data = append(page.Data, info)
 	}
 
 	numTemplates := len(templates)
@@ -283,20 +750,28 @@ func (r *Registry) GetTemplateDetails(ctx context.Context, templateId string) (s
 	details := sdto.NotificationTemplateDetails{}
 
 	var createdAt time.Time
-	var updatedAt *time.Time
+	var updatedAt, archivedAt *time.Time
 
 	err := r.conn.QueryRow(ctx, getNotificationTemplateDetails, templateId).
 		Scan(
 			&details.Id,
 			&details.Name,
+			&details.Version,
 			&details.IsHtml,
+			&details.Format,
 			&details.TitleTemplate,
 			&details.ContentsTemplate,
 			&details.Description,
+			&details.Category,
+			&details.Tags,
+			&details.Layout,
+			&details.DefaultLocale,
 			&details.CreatedBy,
 			&createdAt,
 			&details.UpdatedBy,
 			&updatedAt,
+			&details.ArchivedBy,
+			&archivedAt,
 		)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -310,6 +785,7 @@ func (r *Registry) GetTemplateDetails(ctx context.Context, templateId string) (s
 		return details, fmt.Errorf("failed to retrieve template details - %w", err)
 	}
 
+	details.Tags = nilIfEmpty(details.Tags)
 	details.CreatedAt = createdAt.Format(time.RFC3339)
 
 	if updatedAt != nil {
@@ -317,6 +793,11 @@ func (r *Registry) GetTemplateDetails(ctx context.Context, templateId string) (s
 		details.UpdatedAt = &updatedAtStr
 	}
 
+	if archivedAt != nil {
+		archivedAtStr := archivedAt.Format(time.RFC3339)
+		details.ArchivedAt = &archivedAtStr
+	}
+
 	rows, err := r.conn.Query(ctx, getTemplateVariables, templateId)
 
 	if err != nil {
@@ -327,12 +808,7 @@ func (r *Registry) GetTemplateDetails(ctx context.Context, templateId string) (s
 
 	for rows.Next() {
 		var variable sdto.TemplateVariable
-		err := rows.Scan(
-			&variable.Name,
-			&variable.Type,
-			&variable.Required,
-			&variable.Validation,
-		)
+		err := rows.Scan(templateVariableFields(&variable)...)
 
 		if err != nil {
 			return details, fmt.Errorf("failed to scan template variable - %w", err)
@@ -341,10 +817,37 @@ func (r *Registry) GetTemplateDetails(ctx context.Context, templateId string) (s
 		details.Variables = append(details.Variables, variable)
 	}
 
+	localizations, err := r.getTemplateVersionLocalizations(ctx, templateId, details.Version)
+
+	if err != nil {
+		return details, err
+	}
+
+	details.Localizations = localizations
+
 	return details, nil
 }
 
-func (r *Registry) DeleteTemplate(ctx context.Context, templateId string) error {
+// ArchiveTemplate hides the template and prevents new notifications
+// from using it, keeping the notifications that already used it.
+// Archiving a template that doesn't exist or is already archived does
+// nothing.
+func (r *Registry) ArchiveTemplate(ctx context.Context, templateId, archivedBy string) error {
+
+	archivedAt := time.Now().Format(time.RFC3339)
+
+	_, err := r.conn.Exec(ctx, archiveTemplate, templateId, archivedBy, archivedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to archive notification template - %w", err)
+	}
+
+	return nil
+}
+
+// PurgeTemplate permanently deletes the template and its versions,
+// which is refused while notifications or schedules reference it.
+func (r *Registry) PurgeTemplate(ctx context.Context, templateId string) error {
 
 	tx, err := r.conn.Begin(ctx)
 
@@ -352,7 +855,41 @@ func (r *Registry) DeleteTemplate(ctx context.Context, templateId string) error
 		return fmt.Errorf("failed to start transaction - %w", err)
 	}
 
-	// Relies on ON DELETE CASCADE constraint to delete the template variables
+	// Locks the template so no notification can reference it while
+	// it's being deleted
+	var archivedAt *time.Time
+	err = tx.QueryRow(ctx, lockTemplate, templateId).Scan(&archivedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		tx.Rollback(ctx)
+		return nil
+	}
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to lock the template - %w", err)
+	}
+
+	if archivedAt == nil {
+		tx.Rollback(ctx)
+		return internal.TemplateNotArchived{Id: templateId}
+	}
+
+	var referenced bool
+	err = tx.QueryRow(ctx, getTemplateIsReferenced, templateId).Scan(&referenced)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to check the template references - %w", err)
+	}
+
+	if referenced {
+		tx.Rollback(ctx)
+		return internal.TemplateInUse{Id: templateId}
+	}
+
+	// Relies on ON DELETE CASCADE constraint to delete the template
+	// variables and versions
 	_, err = tx.Exec(ctx, deleteTemplateInfo, templateId)
 
 	if err != nil {
@@ -369,10 +906,53 @@ func (r *Registry) DeleteTemplate(ctx context.Context, templateId string) error
 	return nil
 }
 
-func (r *Registry) GetTemplateVariables(ctx context.Context, templateId string) ([]sdto.TemplateVariable, error) {
+// GetTemplateVariables returns the variables of the version of the
+// template, or of its latest version when the version is nil, along
+// with the version they belong to.
+func (r *Registry) GetTemplateVariables(ctx context.Context, templateId string, version *int) ([]sdto.TemplateVariable, int, error) {
+
 	variables := []sdto.TemplateVariable{}
 
-	rows, err := r.conn.Query(ctx, getTemplateVariables, templateId)
+	var currentVersion int
+	var archivedAt *time.Time
+
+	err := r.conn.QueryRow(ctx, getTemplateVersion, templateId).Scan(&currentVersion, &archivedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return variables, currentVersion, makeTemplateNotFound(templateId, nil)
+	}
+
+	if err != nil {
+		return variables, currentVersion, fmt.Errorf("failed to retrieve the template version - %w", err)
+	}
+
+	if archivedAt != nil {
+		return variables, currentVersion, internal.TemplateArchived{Id: templateId}
+	}
+
+	if version != nil {
+		currentVersion = *version
+		err = r.conn.QueryRow(ctx, getNotificationTemplateVersionExists, templateId, *version).Scan(&currentVersion)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return variables, currentVersion, makeTemplateNotFound(templateId, version)
+		}
+
+		if err != nil {
+			return variables, currentVersion, fmt.Errorf("failed to retrieve the template version - %w", err)
+		}
+	}
+
+	variables, err = r.getTemplateVersionVariables(ctx, templateId, currentVersion)
+
+	return variables, currentVersion, err
+}
+
+func (r *Registry) getTemplateVersionVariables(ctx context.Context, templateId string, version int) ([]sdto.TemplateVariable, error) {
+
+	variables := []sdto.TemplateVariable{}
+
+	rows, err := r.conn.Query(ctx, getTemplateVersionVariables, templateId, version)
 
 	if err != nil {
 		return variables, fmt.Errorf("failed to query template variables - %w", err)
@@ -382,12 +962,7 @@ func (r *Registry) GetTemplateVariables(ctx context.Context, templateId string)
 
 	for rows.Next() {
 		var variable sdto.TemplateVariable
-		err := rows.Scan(
-			&variable.Name,
-			&variable.Type,
-			&variable.Required,
-			&variable.Validation,
-		)
+		err := rows.Scan(templateVariableFields(&variable)...)
 
 		if err != nil {
 			return variables, fmt.Errorf("failed to scan template variable - %w", err)
@@ -398,3 +973,310 @@ func (r *Registry) GetTemplateVariables(ctx context.Context, templateId string)
 
 	return variables, nil
 }
+
+// getTemplateVersionLocalizations returns the localizations of the
+// version of the template, sorted by locale.
+func (r *Registry) getTemplateVersionLocalizations(ctx context.Context, templateId string, version int) ([]sdto.TemplateLocalization, error) {
+
+	rows, err := r.conn.Query(ctx, getTemplateVersionLocalizations, templateId, version)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query template localizations - %w", err)
+	}
+
+	defer rows.Close()
+
+	var localizations []sdto.TemplateLocalization
+
+	for rows.Next() {
+		var l sdto.TemplateLocalization
+		err := rows.Scan(&l.Locale, &l.TitleTemplate, &l.ContentsTemplate)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template localization - %w", err)
+		}
+
+		localizations = append(localizations, l)
+	}
+
+	return localizations, nil
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, so the searched
+// text is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// nilIfEmpty converts the empty arrays of the templates to nil, as
+// they are omitted from the responses
+func nilIfEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+
+	return s
+}
+
+func makeTemplateNotFound(templateId string, version *int) internal.EntityNotFound {
+
+	if version == nil {
+		return internal.EntityNotFound{
+			Id:   templateId,
+			Type: registry.NotificationTemplateType,
+		}
+	}
+
+	return internal.EntityNotFound{
+		Id:   fmt.Sprintf("%s (version %d)", templateId, *version),
+		Type: registry.NotificationTemplateVersionType,
+	}
+}
+
+func (r *Registry) UpdateTemplate(ctx context.Context, templateId, updatedBy string, ntr dto.NotificationTemplateReq) (sdto.NotificationTemplateDetails, error) {
+
+	tx, err := r.conn.Begin(ctx)
+
+	if err != nil {
+		return sdto.NotificationTemplateDetails{}, fmt.Errorf("failed to start transaction - %w", err)
+	}
+
+	// Locks the template so concurrent updates create sequential versions
+	var version int
+	var archivedAt *time.Time
+	err = tx.QueryRow(ctx, lockNotificationTemplateVersion, templateId).Scan(&version, &archivedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		tx.Rollback(ctx)
+		return sdto.NotificationTemplateDetails{}, makeTemplateNotFound(templateId, nil)
+	}
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return sdto.NotificationTemplateDetails{}, fmt.Errorf("failed to lock the template - %w", err)
+	}
+
+	if archivedAt != nil {
+		tx.Rollback(ctx)
+		return sdto.NotificationTemplateDetails{}, internal.TemplateArchived{Id: templateId}
+	}
+
+	version++
+	updatedAt := time.Now().Format(time.RFC3339)
+
+	args := pgx.NamedArgs{
+		"id":               templateId,
+		"name":             ntr.Name,
+		"isHtml":           ntr.IsHtml,
+		"format":           ntr.Format,
+		"titleTemplate":    ntr.TitleTemplate,
+		"contentsTemplate": ntr.ContentsTemplate,
+		"description":      ntr.Description,
+		"category":         ntr.Category,
+		"tags":             ntr.Tags,
+		"layout":           ntr.Layout,
+		"partials":         ntr.Partials,
+		"defaultLocale":    ntr.DefaultLocale,
+		"version":          version,
+		"updatedBy":        updatedBy,
+		"updatedAt":        updatedAt,
+	}
+
+	_, err = tx.Exec(ctx, updateNotificationTemplate, args)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return sdto.NotificationTemplateDetails{}, fmt.Errorf("failed to update template - %w", err)
+	}
+
+	_, err = tx.Exec(ctx, deleteNotificationTemplateVariables, templateId)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return sdto.NotificationTemplateDetails{}, fmt.Errorf("failed to delete template variables - %w", err)
+	}
+
+	err = saveTemplateVersion(ctx, tx, templateId, version, updatedBy, updatedAt, ntr)
+
+	if err != nil {
+		tx.Rollback(ctx)
+		return sdto.NotificationTemplateDetails{}, err
+	}
+
+	err = tx.Commit(ctx)
+
+	if err != nil {
+		return sdto.NotificationTemplateDetails{}, fmt.Errorf("failed to commit template update - %w", err)
+	}
+
+	return r.GetTemplateVersion(ctx, templateId, version)
+}
+
+func (r *Registry) GetTemplateVersions(ctx context.Context, templateId string, filters sdto.PageFilter) (sdto.Page[dto.NotificationTemplateVersionResp], error) {
+
+	page := sdto.Page[dto.NotificationTemplateVersionResp]{}
+
+	var latestVersion int
+	var archivedAt *time.Time
+	err := r.conn.QueryRow(ctx, getTemplateVersion, templateId).Scan(&latestVersion, &archivedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return page, makeTemplateNotFound(templateId, nil)
+	}
+
+	if err != nil {
+		return page, fmt.Errorf("failed to retrieve the template version - %w", err)
+	}
+
+	args := pgx.NamedArgs{
+		"limit":      internal.PageSize,
+		"templateId": templateId,
+	}
+
+	whereFilters := []string{"template_id = @templateId"}
+
+	if filters.MaxResults != nil {
+		args["limit"] = *filters.MaxResults
+	}
+
+	if filters.NextToken != nil {
+		whereFilters = append(whereFilters, `"version" < @version`)
+
+		var unmarsalledKey notificationTemplateVersionKey
+
+		err := registry.UnmarshalKey(*filters.NextToken, &unmarsalledKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		if unmarsalledKey.Id != templateId {
+			return page, fmt.Errorf("invalid next token %s", *filters.NextToken)
+		}
+
+		args["version"] = unmarsalledKey.Version
+	}
+
+	query := fmt.Sprintf(getNotificationTemplateVersions, strings.Join(whereFilters, " AND "))
+
+	rows, err := r.conn.Query(ctx, query, args)
+
+	if err != nil {
+		return page, fmt.Errorf("failed to query rows - %w", err)
+	}
+
+	defer rows.Close()
+
+	versions, err := pgx.CollectRows(rows, pgx.RowToStructByName[notificationTemplateVersion])
+
+	if err != nil {
+		return page, fmt.Errorf("failed to collect rows - %w", err)
+	}
+
+	page.Data = make([]dto.NotificationTemplateVersionResp, 0, len(versions))
+
+	for _, v := range versions {
+		page.Data = append(page.Data, dto.NotificationTemplateVersionResp{
+			Version:   v.Version,
+			Name:      v.Name,
+			CreatedBy: v.CreatedBy,
+			CreatedAt: v.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	numVersions := len(versions)
+
+	if numVersions == args["limit"] {
+		lastVersionKey := notificationTemplateVersionKey{
+			Id:      templateId,
+			Version: versions[numVersions-1].Version,
+		}
+
+		key, err := registry.MarshalKey(lastVersionKey)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.NextToken = &key
+	}
+
+	page.PrevToken = filters.NextToken
+	page.ResultCount = numVersions
+
+	return page, nil
+}
+
+func (r *Registry) GetTemplateVersion(ctx context.Context, templateId string, version int) (sdto.NotificationTemplateDetails, error) {
+
+	details := sdto.NotificationTemplateDetails{}
+
+	var createdAt, versionCreatedAt time.Time
+	var archivedAt *time.Time
+	var versionCreatedBy string
+
+	err := r.conn.QueryRow(ctx, getNotificationTemplateVersionDetails, templateId, version).
+		Scan(
+			&details.Id,
+			&details.Name,
+			&details.Version,
+			&details.IsHtml,
+			&details.Format,
+			&details.TitleTemplate,
+			&details.ContentsTemplate,
+			&details.Description,
+			&details.Category,
+			&details.Tags,
+			&details.Layout,
+			&details.DefaultLocale,
+			&details.CreatedBy,
+			&createdAt,
+			&versionCreatedBy,
+			&versionCreatedAt,
+			&details.ArchivedBy,
+			&archivedAt,
+		)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return details, makeTemplateNotFound(templateId, &version)
+	}
+
+	if err != nil {
+		return details, fmt.Errorf("failed to retrieve template version - %w", err)
+	}
+
+	details.Tags = nilIfEmpty(details.Tags)
+	details.CreatedAt = createdAt.Format(time.RFC3339)
+
+	// Every version after the first one is an update of the template
+	if details.Version > 1 {
+		updatedAt := versionCreatedAt.Format(time.RFC3339)
+		details.UpdatedAt = &updatedAt
+		details.UpdatedBy = &versionCreatedBy
+	}
+
+	if archivedAt != nil {
+		archivedAtStr := archivedAt.Format(time.RFC3339)
+		details.ArchivedAt = &archivedAtStr
+	}
+
+	variables, err := r.getTemplateVersionVariables(ctx, templateId, version)
+
+	if err != nil {
+		return details, err
+	}
+
+	details.Variables = variables
+
+	localizations, err := r.getTemplateVersionLocalizations(ctx, templateId, version)
+
+	if err != nil {
+		return details, err
+	}
+
+	details.Localizations = localizations
+
+	return details, nil
+}
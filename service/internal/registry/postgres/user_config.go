@@ -19,6 +19,7 @@ type userConfig struct {
 	InAppSnoozeUntil *time.Time `db:"in_app_snooze_until"`
 	PushOptIn        bool       `db:"push_opt_in"`
 	PushSnoozeUntil  *time.Time `db:"push_snooze_until"`
+	Locale           *string    `db:"locale"`
 }
 
 func (cf *userConfig) toDTO() dto.UserConfig {
@@ -45,6 +46,7 @@ func (cf *userConfig) toDTO() dto.UserConfig {
 			OptIn:       cf.InAppOptIn,
 			SnoozeUntil: toStr(cf.InAppSnoozeUntil),
 		},
+		Locale: cf.Locale,
 	}
 }
 
@@ -57,7 +59,8 @@ SELECT
 	in_app_opt_in,
 	in_app_snooze_until,
 	push_opt_in,
-	push_snooze_until
+	push_snooze_until,
+	locale
 FROM
 	user_config
 WHERE
@@ -98,7 +101,8 @@ INSERT INTO user_config (
 	in_app_opt_in,
 	in_app_snooze_until,
 	push_opt_in,
-	push_snooze_until
+	push_snooze_until,
+	locale
 ) VALUES (
 	@userId,
 	@emailOptIn,
@@ -108,7 +112,8 @@ INSERT INTO user_config (
 	@inAppOptIn,
 	@inAppSnoozeUntil,
 	@pushOptIn,
-	@pushSnoozeUntil
+	@pushSnoozeUntil,
+	@locale
 ) ON CONFLICT
 	(user_id)
 DO UPDATE SET
@@ -119,7 +124,8 @@ DO UPDATE SET
 	in_app_opt_in = EXCLUDED.in_app_opt_in,
 	in_app_snooze_until = EXCLUDED.in_app_snooze_until,
 	push_opt_in = EXCLUDED.push_opt_in,
-	push_snooze_until = EXCLUDED.push_snooze_until;
+	push_snooze_until = EXCLUDED.push_snooze_until,
+	locale = EXCLUDED.locale;
 `
 
 func (ps *Registry) makeUserConfig(ctx context.Context, userId string) (*userConfig, error) {
@@ -180,6 +186,7 @@ func (ps *Registry) GetUserConfig(ctx context.Context, userId string) (dto.UserC
 		&cfg.InAppSnoozeUntil,
 		&cfg.PushOptIn,
 		&cfg.PushSnoozeUntil,
+		&cfg.Locale,
 	)
 
 	if err != nil {
@@ -215,6 +222,7 @@ func (ps *Registry) UpdateUserConfig(ctx context.Context, userId string, config
 		"inAppSnoozeUntil": config.InAppConfig.SnoozeUntil,
 		"pushOptIn":        true,
 		"pushSnoozeUntil":  nil,
+		"locale":           config.Locale,
 	}
 
 	_, err = tx.Exec(ctx, UpsertUserConfig, args)
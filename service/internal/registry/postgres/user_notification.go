@@ -16,13 +16,14 @@ import (
 )
 
 type userNotification struct {
-	Id        string     `db:"id"`
-	Title     string     `db:"title"`
-	Contents  string     `db:"contents"`
-	CreatedAt time.Time  `db:"created_at"`
-	ImageUrl  *string    `db:"image_url"`
-	ReadAt    *time.Time `db:"read_at"`
-	Topic     string     `db:"topic"`
+	Id           string     `db:"id"`
+	Title        string     `db:"title"`
+	Contents     string     `db:"contents"`
+	HtmlContents *string    `db:"html_contents"`
+	CreatedAt    time.Time  `db:"created_at"`
+	ImageUrl     *string    `db:"image_url"`
+	ReadAt       *time.Time `db:"read_at"`
+	Topic        string     `db:"topic"`
 }
 
 type userNotificationKey struct {
@@ -39,13 +40,14 @@ func (n *userNotification) toDTO() dto.UserNotification {
 	}
 
 	notification := dto.UserNotification{
-		Id:        n.Id,
-		Title:     n.Title,
-		Contents:  n.Contents,
-		CreatedAt: n.CreatedAt.Format(time.RFC3339Nano),
-		Image:     n.ImageUrl,
-		ReadAt:    readAt,
-		Topic:     n.Topic,
+		Id:           n.Id,
+		Title:        n.Title,
+		Contents:     n.Contents,
+		HtmlContents: n.HtmlContents,
+		CreatedAt:    n.CreatedAt.Format(time.RFC3339Nano),
+		Image:        n.ImageUrl,
+		ReadAt:       readAt,
+		Topic:        n.Topic,
 	}
 
 	return notification
@@ -56,6 +58,7 @@ SELECT
 	id,
 	title,
 	contents,
+	html_contents,
 	created_at,
 	image_url,
 	read_at,
@@ -88,6 +91,7 @@ INSERT INTO
 	id,
 	title,
 	contents,
+	html_contents,
 	created_at,
 	image_url,
 	read_at,
@@ -97,6 +101,7 @@ INSERT INTO
 	@id,
 	@title,
 	@contents,
+	@htmlContents,
 	NOW(),
 	@imageUrl,
 	@readAt,
@@ -259,23 +264,25 @@ func (r *Registry) CreateNotifications(ctx context.Context, notifications []sdto
 		}
 
 		args = append(args, pgx.NamedArgs{
-			"id":       id.String(),
-			"userId":   n.UserId,
-			"title":    n.Title,
-			"contents": n.Contents,
-			"imageUrl": n.Image,
-			"readAt":   nil,
-			"topic":    n.Topic,
+			"id":           id.String(),
+			"userId":       n.UserId,
+			"title":        n.Title,
+			"contents":     n.Contents,
+			"htmlContents": n.HtmlContents,
+			"imageUrl":     n.Image,
+			"readAt":       nil,
+			"topic":        n.Topic,
 		})
 
 		userNotification := dto.UserNotification{
-			Id:        id.String(),
-			Title:     n.Title,
-			Contents:  n.Contents,
-			CreatedAt: time.Now().Format(time.RFC3339Nano),
-			Image:     n.Image,
-			ReadAt:    nil,
-			Topic:     n.Topic,
+			Id:           id.String(),
+			Title:        n.Title,
+			Contents:     n.Contents,
+			HtmlContents: n.HtmlContents,
+			CreatedAt:    time.Now().Format(time.RFC3339Nano),
+			Image:        n.Image,
+			ReadAt:       nil,
+			Topic:        n.Topic,
 		}
 
 		userNotifications = append(userNotifications, userNotification)
@@ -7,12 +7,19 @@ import (
 func IsDeletableStatus(status dto.NotificationStatus) bool {
 
 	deletableStatuses := map[dto.NotificationStatus]struct{}{
-		dto.Sent:    {},
-		dto.Failed:  {},
-		dto.Created: {},
+		dto.Sent:      {},
+		dto.Failed:    {},
+		dto.Created:   {},
+		dto.Scheduled: {},
 	}
 
 	_, ok := deletableStatuses[status]
 
 	return ok
 }
+
+// IsResendableStatus tells if the notification has been processed, so
+// it can be sent again to the recipients it has failed to reach.
+func IsResendableStatus(status dto.NotificationStatus) bool {
+	return status == dto.Sent || status == dto.Failed
+}
@@ -22,6 +22,18 @@ func SetupDistributionListRoutes(cfg distributionListsRoutesCfg) error {
 			cfg.AuthorizeMiddleware(auth.Admin),
 			cfg.Controller.CreateDistributionList)
 
+		g.POST("/distribution-lists/segments/preview",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.PreviewSegment)
+
+		g.DELETE("/distribution-lists/members/:userId",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.RemoveMember)
+
+		g.GET("/distribution-lists/:name/segment",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.GetSegment)
+
 		g.GET("/distribution-lists/:name/recipients",
 			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
 			cfg.Controller.GetRecipients)
@@ -34,10 +46,46 @@ func SetupDistributionListRoutes(cfg distributionListsRoutesCfg) error {
 			cfg.AuthorizeMiddleware(auth.Admin),
 			cfg.Controller.DeleteRecipients)
 
+		g.GET("/distribution-lists/:name/lists",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.GetLists)
+
+		g.PATCH("/distribution-lists/:name/lists",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.AddLists)
+
+		g.DELETE("/distribution-lists/:name/lists",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.DeleteLists)
+
+		g.GET("/distribution-lists/:name",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.GetDistributionList)
+
+		g.PUT("/distribution-lists/:name",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.UpdateDistributionList)
+
+		g.POST("/distribution-lists/:name/rename",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.RenameDistributionList)
+
 		g.DELETE("/distribution-lists/:name",
 			cfg.AuthorizeMiddleware(auth.Admin),
 			cfg.Controller.DeleteDistributionList)
 	}
 
+	// Not cached, the imports and exports are streamed
+	stream := cfg.Engine.Group(cfg.Version)
+	{
+		stream.POST("/distribution-lists/:name/recipients/import",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.ImportRecipients)
+
+		stream.GET("/distribution-lists/:name/recipients/export",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.ExportRecipients)
+	}
+
 	return nil
 }
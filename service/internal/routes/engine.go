@@ -13,8 +13,10 @@ import (
 	"github.com/notifique/service/internal"
 	"github.com/notifique/service/internal/controllers"
 	"github.com/notifique/service/internal/middleware"
+	"github.com/notifique/service/internal/sanitizer"
 	"github.com/notifique/shared/auth"
 	"github.com/notifique/shared/cache"
+	sdto "github.com/notifique/shared/dto"
 )
 
 const versionRegex = "(/v[0-9]{1,2}|^$)"
@@ -24,18 +26,21 @@ type Registry interface {
 	controllers.UserRegistry
 	controllers.DistributionRegistry
 	controllers.NotificationTemplateRegistry
+	controllers.TemplatePartialRegistry
+	controllers.NotificationScheduleRegistry
 }
 
 type EngineConfigurator interface {
 	GetVersion() (string, error)
+	sanitizer.Configurator
 }
 
 type EngineConfig struct {
 	RedisClient        *redis.Client
 	Registry           Registry
 	Cache              cache.Cache
-	Publisher          controllers.NotificationPublisher
 	Broker             controllers.UserNotificationBroker
+	Audience           controllers.AudienceResolver
 	EngineConfigurator EngineConfigurator
 	Authorize          func(...auth.Scope) gin.HandlerFunc
 	Authenticate       middleware.AuthMiddleware
@@ -65,32 +70,57 @@ func NewEngine(cfg EngineConfig) (*gin.Engine, error) {
 		return nil, fmt.Errorf("api version should have the format %s", versionRegex)
 	}
 
+	sanitizerCfg, err := cfg.EngineConfigurator.GetSanitizerConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := sanitizer.NewSanitizer(sanitizerCfg)
+
 	nc := controllers.NotificationController{
 		Registry:  cfg.Registry,
-		Publisher: cfg.Publisher,
 		Cache:     cfg.Cache,
+		Sanitizer: s,
 	}
 
 	dlc := controllers.DistributionListController{
 		Registry: cfg.Registry,
 		Cache:    cfg.Cache,
+		Audience: cfg.Audience,
 	}
 
 	uc := controllers.UserController{
-		Registry: cfg.Registry,
-		Broker:   cfg.Broker,
-		Cache:    cfg.Cache,
+		Registry:          cfg.Registry,
+		DistributionLists: cfg.Registry,
+		Broker:            cfg.Broker,
+		Cache:             cfg.Cache,
+		Sanitizer:         s,
 	}
 
 	ntc := controllers.NotificationTemplateController{
-		Registry: cfg.Registry,
-		Cache:    cfg.Cache,
+		Registry:  cfg.Registry,
+		Partials:  cfg.Registry,
+		Cache:     cfg.Cache,
+		Sanitizer: s,
+	}
+
+	tpc := controllers.TemplatePartialController{
+		Registry:  cfg.Registry,
+		Cache:     cfg.Cache,
+		Sanitizer: s,
+	}
+
+	nsc := controllers.NotificationScheduleController{
+		Registry:      cfg.Registry,
+		Notifications: cfg.Registry,
+		Sanitizer:     s,
 	}
 
 	nc = controllers.NotificationController{
 		Registry:  cfg.Registry,
-		Publisher: cfg.Publisher,
 		Cache:     cfg.Cache,
+		Sanitizer: s,
 	}
 
 	r := gin.Default()
@@ -128,11 +158,26 @@ func NewEngine(cfg EngineConfig) (*gin.Engine, error) {
 		Controller:    &ntc,
 	})
 
+	_ = SetupTemplatePartialRoutes(templatePartialsRoutesCfg{
+		routeGroupCfg: routesCfg,
+		Controller:    &tpc,
+	})
+
+	_ = SetupNotificationScheduleRoutes(notificationSchedulesRoutesCfg{
+		routeGroupCfg: routesCfg,
+		Controller:    &nsc,
+	})
+
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("distributionlistname", internal.DLNameValidator)
 		v.RegisterValidation("unique_var_name", internal.UniqueTemplateVarValidator)
 		v.RegisterValidation("future", internal.FutureValidator)
+		v.RegisterValidation("notbefore", internal.NotBeforeValidator)
 		v.RegisterValidation("templatevarname", internal.TemplateNameValidator)
+		v.RegisterValidation("cron", internal.CronValidator)
+		v.RegisterValidation("partialname", internal.PartialNameValidator)
+		v.RegisterStructValidation(internal.TemplateVariableStructValidator, sdto.TemplateVariable{})
+		v.RegisterStructValidation(internal.SegmentRuleStructValidator, sdto.SegmentRule{})
 	}
 
 	return r, nil
@@ -34,10 +34,18 @@ func SetupNotificationRoutes(cfg notificationsRoutesCfg) error {
 			cfg.AuthorizeMiddleware(auth.NotificationsPublisher, auth.Admin),
 			cfg.Controller.GetStatus)
 
+		g.GET("/notifications/:id/status/history",
+			cfg.AuthorizeMiddleware(auth.NotificationsPublisher, auth.Admin),
+			cfg.Controller.GetStatusHistory)
+
 		g.POST("/notifications/:id/cancel",
 			cfg.AuthorizeMiddleware(auth.NotificationsPublisher, auth.Admin),
 			cfg.Controller.CancelDelivery)
 
+		g.POST("/notifications/:id/resend",
+			cfg.AuthorizeMiddleware(auth.NotificationsPublisher, auth.Admin),
+			cfg.Controller.ResendNotification)
+
 		g.POST("/notifications/:id/recipients/statuses",
 			cfg.AuthorizeMiddleware(auth.NotificationsPublisher),
 			cfg.Controller.UpsertRecipientNotificationStatuses)
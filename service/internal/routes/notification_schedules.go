@@ -0,0 +1,51 @@
+package routes
+
+import (
+	c "github.com/notifique/service/internal/controllers"
+	"github.com/notifique/shared/auth"
+)
+
+type notificationSchedulesRoutesCfg struct {
+	routeGroupCfg
+	Controller *c.NotificationScheduleController
+}
+
+func SetupNotificationScheduleRoutes(cfg notificationSchedulesRoutesCfg) error {
+
+	g := cfg.Engine.Group(cfg.Version)
+	{
+		g.POST("/notifications/schedules",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.CreateSchedule)
+
+		g.GET("/notifications/schedules",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.GetSchedules)
+
+		g.GET("/notifications/schedules/:id",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.GetSchedule)
+
+		g.PUT("/notifications/schedules/:id",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.UpdateSchedule)
+
+		g.DELETE("/notifications/schedules/:id",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.DeleteSchedule)
+
+		g.POST("/notifications/schedules/:id/pause",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.PauseSchedule)
+
+		g.POST("/notifications/schedules/:id/resume",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.ResumeSchedule)
+
+		g.GET("/notifications/schedules/:id/preview",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.PreviewSchedule)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	c "github.com/notifique/service/internal/controllers"
+	"github.com/notifique/shared/auth"
+)
+
+type templatePartialsRoutesCfg struct {
+	routeGroupCfg
+	Controller *c.TemplatePartialController
+}
+
+func SetupTemplatePartialRoutes(cfg templatePartialsRoutesCfg) error {
+
+	g := cfg.Engine.Group(cfg.Version, cfg.CacheMiddleware)
+	{
+		g.POST("/notifications/partials",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.CreateTemplatePartial)
+
+		g.GET("/notifications/partials",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.GetTemplatePartials)
+
+		g.GET("/notifications/partials/:name",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.GetTemplatePartial)
+
+		g.PUT("/notifications/partials/:name",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.UpdateTemplatePartial)
+
+		g.GET("/notifications/partials/:name/versions/:version",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.GetTemplatePartialVersion)
+
+		g.DELETE("/notifications/partials/:name",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.DeleteTemplatePartial)
+	}
+
+	return nil
+}
@@ -26,9 +26,33 @@ func SetupNotificationTemplateRoutes(cfg templatesRoutesCfg) error {
 			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
 			cfg.Controller.GetTemplateDetails)
 
+		g.PUT("/notifications/templates/:id",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.UpdateTemplate)
+
+		g.GET("/notifications/templates/:id/versions",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.GetTemplateVersions)
+
+		g.GET("/notifications/templates/:id/versions/:version",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.GetTemplateVersion)
+
+		g.POST("/notifications/templates/:id/versions/:version/rollback",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.RollbackTemplate)
+
+		g.POST("/notifications/templates/:id/render",
+			cfg.AuthorizeMiddleware(auth.Admin, auth.NotificationsPublisher),
+			cfg.Controller.RenderTemplate)
+
 		g.DELETE("/notifications/templates/:id",
 			cfg.AuthorizeMiddleware(auth.Admin),
 			cfg.Controller.DeleteTemplate)
+
+		g.POST("/notifications/templates/:id/purge",
+			cfg.AuthorizeMiddleware(auth.Admin),
+			cfg.Controller.PurgeTemplate)
 	}
 
 	return nil
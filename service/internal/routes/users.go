@@ -39,5 +39,22 @@ func SetupUsersRoutes(cfg usersRoutesCfg) error {
 			cfg.Controller.CreateNotifications)
 	}
 
+	// Not cached, so the notifications use the latest config of the users
+	recipients := cfg.Engine.Group(cfg.Version)
+	{
+		recipients.GET("/users/:id/notifications/config",
+			cfg.AuthorizeMiddleware(auth.NotificationsPublisher, auth.Admin),
+			cfg.Controller.GetRecipientConfig)
+	}
+
+	// Not cached, the changes to the recipients of the lists are only
+	// invalidated on the distribution lists endpoints
+	lists := cfg.Engine.Group(cfg.Version)
+	{
+		lists.GET("/users/me/distribution-lists",
+			cfg.AuthorizeMiddleware(auth.User),
+			cfg.Controller.GetUserDistributionLists)
+	}
+
 	return nil
 }
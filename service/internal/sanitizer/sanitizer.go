@@ -0,0 +1,111 @@
+package sanitizer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+
+	sdto "github.com/notifique/shared/dto"
+)
+
+// Config is the sanitization policy of a deployment, which allows the
+// elements and attributes of the user generated content policy along
+// with the configured ones. The AllowedAttributes are allowed on every
+// element, or on a single element when written as element.attribute.
+// The AllowedStyles are the CSS properties allowed in style attributes,
+// which e-mail clients need as they ignore style sheets. In Strict mode
+// the contents that have to be stripped are rejected.
+type Config struct {
+	AllowedElements   []string
+	AllowedAttributes []string
+	AllowedStyles     []string
+	Strict            bool
+}
+
+type Configurator interface {
+	GetSanitizerConfig() (Config, error)
+}
+
+type Sanitizer struct {
+	policy *bluemonday.Policy
+	strict bool
+}
+
+var templateAction = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// Sanitize sanitizes the HTML of the field, returning the sanitized HTML
+// and what was stripped from it.
+func (s *Sanitizer) Sanitize(field, contents string) (string, []sdto.StrippedContent) {
+
+	sanitized := s.policy.Sanitize(contents)
+
+	return sanitized, stripped(field, contents, sanitized)
+}
+
+// SanitizeTemplate sanitizes the HTML of a template. The template
+// actions are masked, otherwise the sanitizer would escape the quotes of
+// their string arguments.
+func (s *Sanitizer) SanitizeTemplate(field, tmpl string) (string, []sdto.StrippedContent) {
+
+	actions := templateAction.FindAllString(tmpl, -1)
+	placeholder := func(i int) string {
+		return fmt.Sprintf("notifique-template-action-%d", i)
+	}
+
+	i := 0
+	masked := templateAction.ReplaceAllStringFunc(tmpl, func(string) string {
+		i++
+		return placeholder(i - 1)
+	})
+
+	sanitized, stripped := s.Sanitize(field, masked)
+
+	// Replaced in reverse so a placeholder isn't a prefix of another
+	for i := len(actions) - 1; i >= 0; i-- {
+		sanitized = strings.ReplaceAll(sanitized, placeholder(i), actions[i])
+	}
+
+	return sanitized, stripped
+}
+
+// SanitizeText sanitizes plain text, such as the raw contents of the
+// notifications, which isn't escaped as it isn't displayed as HTML.
+func (s *Sanitizer) SanitizeText(field, text string) (string, []sdto.StrippedContent) {
+
+	sanitized, stripped := s.Sanitize(field, text)
+
+	return html.UnescapeString(sanitized), stripped
+}
+
+// Strict reports whether the contents that have to be stripped should be
+// rejected
+func (s *Sanitizer) Strict() bool {
+	return s.strict
+}
+
+// NewSanitizer creates the sanitizer of the policy of the config
+func NewSanitizer(cfg Config) *Sanitizer {
+
+	p := bluemonday.UGCPolicy()
+
+	if len(cfg.AllowedElements) != 0 {
+		p.AllowElements(cfg.AllowedElements...)
+	}
+
+	for _, attr := range cfg.AllowedAttributes {
+		if element, name, ok := strings.Cut(attr, "."); ok {
+			p.AllowAttrs(name).OnElements(element)
+		} else {
+			p.AllowAttrs(attr).Globally()
+		}
+	}
+
+	if len(cfg.AllowedStyles) != 0 {
+		p.AllowStyles(cfg.AllowedStyles...).Globally()
+	}
+
+	return &Sanitizer{policy: p, strict: cfg.Strict}
+}
@@ -0,0 +1,152 @@
+package sanitizer
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	sdto "github.com/notifique/shared/dto"
+)
+
+type attribute struct {
+	name string
+	// properties are the CSS properties of style attributes
+	properties []string
+}
+
+type tag struct {
+	element    string
+	attributes []attribute
+}
+
+// content is an element, an attribute of an element or a property of a
+// style attribute
+type content struct {
+	element   string
+	attribute string
+	property  string
+}
+
+// styleProperties returns the CSS properties of a style attribute
+func styleProperties(style string) []string {
+
+	properties := []string{}
+
+	for _, declaration := range strings.Split(style, ";") {
+		property, _, _ := strings.Cut(declaration, ":")
+		property = strings.ToLower(strings.TrimSpace(property))
+
+		if property != "" {
+			properties = append(properties, property)
+		}
+	}
+
+	return properties
+}
+
+// tags returns the start tags of the HTML, in order
+func tags(s string) []tag {
+
+	result := []tag{}
+	z := html.NewTokenizer(strings.NewReader(s))
+
+	for {
+		tt := z.Next()
+
+		if tt == html.ErrorToken {
+			return result
+		}
+
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		name, hasAttr := z.TagName()
+		t := tag{element: string(name)}
+
+		for hasAttr {
+			var key, value []byte
+			key, value, hasAttr = z.TagAttr()
+			a := attribute{name: string(key)}
+
+			if a.name == "style" {
+				a.properties = styleProperties(string(value))
+			}
+
+			t.attributes = append(t.attributes, a)
+		}
+
+		result = append(result, t)
+	}
+}
+
+// strippedFromTag returns the attributes and style properties of the
+// original tag that the sanitized tag doesn't have. The attributes added
+// by the sanitizer, such as rel on links, aren't taken into account.
+func strippedFromTag(original, sanitized tag) []content {
+
+	result := []content{}
+
+	for _, a := range original.attributes {
+		i := slices.IndexFunc(sanitized.attributes, func(s attribute) bool {
+			return s.name == a.name
+		})
+
+		if i == -1 {
+			result = append(result, content{element: original.element, attribute: a.name})
+			continue
+		}
+
+		for _, p := range a.properties {
+			if !slices.Contains(sanitized.attributes[i].properties, p) {
+				result = append(result, content{element: original.element, attribute: a.name, property: p})
+			}
+		}
+	}
+
+	return result
+}
+
+// stripped returns what was removed from the original HTML of the field
+// by the sanitization. The sanitizer keeps the order of the tags, so each
+// tag of the original HTML is matched to the next sanitized tag of the
+// same element, when there is one, or else it was removed.
+func stripped(field, original, sanitized string) []sdto.StrippedContent {
+
+	removed := []content{}
+	sanitizedTags := tags(sanitized)
+	next := 0
+
+	for _, t := range tags(original) {
+		if next < len(sanitizedTags) && sanitizedTags[next].element == t.element {
+			removed = append(removed, strippedFromTag(t, sanitizedTags[next])...)
+			next++
+			continue
+		}
+
+		// The attributes of the removed elements are removed along with them
+		removed = append(removed, content{element: t.element})
+	}
+
+	result := []sdto.StrippedContent{}
+	indexes := map[content]int{}
+
+	for _, c := range removed {
+		if i, ok := indexes[c]; ok {
+			result[i].Count++
+			continue
+		}
+
+		indexes[c] = len(result)
+		result = append(result, sdto.StrippedContent{
+			Field:     field,
+			Element:   c.element,
+			Attribute: c.attribute,
+			Property:  c.property,
+			Count:     1,
+		})
+	}
+
+	return result
+}
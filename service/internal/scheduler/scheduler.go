@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	sdto "github.com/notifique/shared/dto"
+)
+
+type ScheduledNotificationRegistry interface {
+	GetDueNotifications(ctx context.Context, dueBefore time.Time, maxResults int) ([]string, error)
+	GetNotification(ctx context.Context, notificationId string) (dto.NotificationResp, error)
+	// ClaimScheduledNotification moves a due notification from SCHEDULED
+	// to CREATED and adds it to the outbox. It returns false if the
+	// notification isn't scheduled anymore, i.e., it was canceled or
+	// claimed by someone else.
+	ClaimScheduledNotification(ctx context.Context, payload sdto.NotificationMsgPayload) (bool, error)
+}
+
+type RecurringScheduleRegistry interface {
+	GetDueSchedules(ctx context.Context, dueBefore time.Time, maxResults int) ([]dto.NotificationScheduleResp, error)
+	// AdvanceSchedule moves the next run of an active schedule from runAt
+	// to nextRunAt. It returns false if the schedule was not at runAt
+	// anymore, i.e., the occurrence was claimed by someone else or the
+	// schedule was paused or updated.
+	AdvanceSchedule(ctx context.Context, scheduleId string, runAt, nextRunAt time.Time) (bool, error)
+	// RunSchedule advances the schedule as AdvanceSchedule does and saves
+	// the notification of the occurrence in the same transaction, so an
+	// occurrence whose notification couldn't be saved is retried.
+	RunSchedule(ctx context.Context, schedule dto.NotificationScheduleResp, runAt, nextRunAt time.Time) (bool, error)
+}
+
+type SchedulerConfigurator interface {
+	GetSchedulerInterval() (time.Duration, error)
+}
+
+type SchedulerCfg struct {
+	Registry     ScheduledNotificationRegistry
+	Schedules    RecurringScheduleRegistry
+	Configurator SchedulerConfigurator
+}
+
+// Scheduler periodically looks for scheduled notifications whose send
+// time has been reached and adds them to the outbox, which publishes
+// them. It also creates a notification for every due occurrence of the
+// recurring notification schedules.
+type Scheduler struct {
+	registry  ScheduledNotificationRegistry
+	schedules RecurringScheduleRegistry
+	interval  time.Duration
+}
+
+func (s *Scheduler) publish(ctx context.Context, notificationId string) error {
+
+	notification, err := s.registry.GetNotification(ctx, notificationId)
+
+	if err != nil {
+		return fmt.Errorf("failed to get notification %s - %w", notificationId, err)
+	}
+
+	if notification.Status != sdto.Scheduled {
+		return nil
+	}
+
+	payload := sdto.NotificationMsgPayload{
+		NotificationReq: notification.NotificationReq,
+		Id:              notification.Id,
+		Hash:            internal.GetMd5Hash(notification.Id),
+	}
+
+	// The notification might have been canceled, or published by another
+	// replica, after it was retrieved. The relay publishes the claimed
+	// notifications.
+	_, err = s.registry.ClaimScheduledNotification(ctx, payload)
+
+	if err != nil {
+		return fmt.Errorf("failed to claim notification %s - %w", notificationId, err)
+	}
+
+	return nil
+}
+
+// PublishDueNotifications adds up to a page of notifications that are
+// due at the time of the call to the outbox.
+func (s *Scheduler) PublishDueNotifications(ctx context.Context) error {
+
+	ids, err := s.registry.GetDueNotifications(ctx, time.Now(), internal.PageSize)
+
+	if err != nil {
+		return fmt.Errorf("failed to get due notifications - %w", err)
+	}
+
+	errorsArr := []error{}
+
+	for _, id := range ids {
+		if err := s.publish(ctx, id); err != nil {
+			errorsArr = append(errorsArr, err)
+		}
+	}
+
+	return errors.Join(errorsArr...)
+}
+
+func (s *Scheduler) runSchedule(ctx context.Context, schedule dto.NotificationScheduleResp) error {
+
+	if schedule.NextRunAt == nil {
+		return nil
+	}
+
+	runAt, err := time.Parse(time.RFC3339, *schedule.NextRunAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to parse next run of schedule %s - %w", schedule.Id, err)
+	}
+
+	// Occurrences missed while the service was down are skipped
+	nextRunAt, err := internal.NextCronRun(
+		schedule.CronExpression,
+		schedule.Timezone,
+		time.Now(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to compute next run of schedule %s - %w", schedule.Id, err)
+	}
+
+	// The notification is published by the outbox relay
+	_, err = s.schedules.RunSchedule(ctx, schedule, runAt, nextRunAt)
+
+	if err == nil {
+		return nil
+	}
+
+	// Retrying an occurrence whose template was archived fails forever,
+	// so it's skipped
+	if !errors.As(err, &internal.TemplateArchived{}) {
+		return fmt.Errorf("failed to run schedule %s - %w", schedule.Id, err)
+	}
+
+	_, advanceErr := s.schedules.AdvanceSchedule(ctx, schedule.Id, runAt, nextRunAt)
+
+	if advanceErr != nil {
+		return fmt.Errorf("failed to advance schedule %s - %w", schedule.Id, advanceErr)
+	}
+
+	return fmt.Errorf("skipped occurrence of schedule %s - %w", schedule.Id, err)
+}
+
+// RunDueSchedules creates a notification for up to a page of recurring
+// schedules that are due at the time of the call.
+func (s *Scheduler) RunDueSchedules(ctx context.Context) error {
+
+	schedules, err := s.schedules.GetDueSchedules(ctx, time.Now(), internal.PageSize)
+
+	if err != nil {
+		return fmt.Errorf("failed to get due schedules - %w", err)
+	}
+
+	errorsArr := []error{}
+
+	for _, schedule := range schedules {
+		if err := s.runSchedule(ctx, schedule); err != nil {
+			errorsArr = append(errorsArr, err)
+		}
+	}
+
+	return errors.Join(errorsArr...)
+}
+
+// Start runs the scheduler until the context is canceled.
+func (s *Scheduler) Start(ctx context.Context) {
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.PublishDueNotifications(ctx); err != nil {
+				slog.Error("failed to publish scheduled notifications",
+					"error", err.Error())
+			}
+
+			if err := s.RunDueSchedules(ctx); err != nil {
+				slog.Error("failed to run notification schedules",
+					"error", err.Error())
+			}
+		}
+	}
+}
+
+func NewScheduler(cfg SchedulerCfg) (*Scheduler, error) {
+
+	interval, err := cfg.Configurator.GetSchedulerInterval()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("scheduler interval should be positive")
+	}
+
+	return &Scheduler{
+		registry:  cfg.Registry,
+		schedules: cfg.Schedules,
+		interval:  interval,
+	}, nil
+}
@@ -1,11 +1,19 @@
 package config_test
 
-type TestEngineConfigurator struct{}
+import "github.com/notifique/service/internal/sanitizer"
+
+type TestEngineConfigurator struct {
+	SanitizerConfig sanitizer.Config
+}
 
 func (cfg TestEngineConfigurator) GetVersion() (string, error) {
 	return "", nil
 }
 
+func (cfg TestEngineConfigurator) GetSanitizerConfig() (sanitizer.Config, error) {
+	return cfg.SanitizerConfig, nil
+}
+
 func NewTestVersionConfigurator() TestEngineConfigurator {
 	return TestEngineConfigurator{}
 }
@@ -81,6 +81,15 @@ func MakeTestNotificationRequestRawContents() sdto.NotificationReq {
 	return testNofiticationReq
 }
 
+func MakeTestNotificationScheduleRequest() dto.NotificationScheduleReq {
+	return dto.NotificationScheduleReq{
+		Name:           "Weekly report",
+		CronExpression: "0 9 * * MON",
+		Timezone:       "America/Sao_Paulo",
+		Notification:   MakeTestNotificationRequestRawContents(),
+	}
+}
+
 func MakeTestNotificationRequestTemplateContents(templateId string, templateReq dto.NotificationTemplateReq) sdto.NotificationReq {
 
 	variables := make([]sdto.TemplateVariableContents, 0, len(templateReq.Variables))
@@ -169,8 +178,9 @@ func MakeTestNotificationTemplateRequest() dto.NotificationTemplateReq {
 	return dto.NotificationTemplateReq{
 		Name:             "signed-in-notification",
 		IsHtml:           true,
-		TitleTemplate:    "Hi {user}!",
-		ContentsTemplate: "Welcome to {app_name}!",
+		Format:           sdto.Html,
+		TitleTemplate:    "Hi {{{user}}}!",
+		ContentsTemplate: "Welcome to {{{app_name}}}!",
 		Description:      "User has signed-in",
 		Variables: []sdto.TemplateVariable{
 			{
@@ -187,6 +197,15 @@ func MakeTestNotificationTemplateRequest() dto.NotificationTemplateReq {
 	}
 }
 
+func MakeTestTemplatePartialRequest() dto.TemplatePartialReq {
+	return dto.TemplatePartialReq{
+		Name:        "footer",
+		Kind:        string(dto.Partial),
+		Description: "Legal text of the e-mails",
+		Contents:    `<p>Sent to {{index . "{user}"}} by {{index . "{app_name}"}}</p>`,
+	}
+}
+
 func MakeTestNotificationTemplateRequests(numrequests int) []dto.NotificationTemplateReq {
 
 	requests := make([]dto.NotificationTemplateReq, 0, numrequests)
@@ -259,6 +278,10 @@ func IntPtr(i int) *int {
 	return &i
 }
 
+func Ptr[T any](v T) *T {
+	return &v
+}
+
 func MakeTestRecipientNotifcationStatus(recipients []string, channels []sdto.NotificationChannel, status sdto.NotificationStatus) []sdto.RecipientNotificationStatus {
 
 	statuses := make([]sdto.RecipientNotificationStatus, 0, len(recipients))
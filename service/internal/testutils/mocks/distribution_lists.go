@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	dto "github.com/notifique/service/internal/dto"
+	recipients "github.com/notifique/service/internal/recipients"
 	dto0 "github.com/notifique/shared/dto"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -42,6 +43,21 @@ func (m *MockDistributionRegistry) EXPECT() *MockDistributionRegistryMockRecorde
 	return m.recorder
 }
 
+// AddLists mocks base method.
+func (m *MockDistributionRegistry) AddLists(ctx context.Context, distlistName string, lists []string) (*dto.DistributionListSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLists", ctx, distlistName, lists)
+	ret0, _ := ret[0].(*dto.DistributionListSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddLists indicates an expected call of AddLists.
+func (mr *MockDistributionRegistryMockRecorder) AddLists(ctx, distlistName, lists any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLists", reflect.TypeOf((*MockDistributionRegistry)(nil).AddLists), ctx, distlistName, lists)
+}
+
 // AddRecipients mocks base method.
 func (m *MockDistributionRegistry) AddRecipients(ctx context.Context, distlistName string, recipients []string) (*dto.DistributionListSummary, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +101,36 @@ func (mr *MockDistributionRegistryMockRecorder) DeleteDistributionList(ctx, dist
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDistributionList", reflect.TypeOf((*MockDistributionRegistry)(nil).DeleteDistributionList), ctx, distlistName)
 }
 
+// DeleteLists mocks base method.
+func (m *MockDistributionRegistry) DeleteLists(ctx context.Context, distlistName string, lists []string) (*dto.DistributionListSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLists", ctx, distlistName, lists)
+	ret0, _ := ret[0].(*dto.DistributionListSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteLists indicates an expected call of DeleteLists.
+func (mr *MockDistributionRegistryMockRecorder) DeleteLists(ctx, distlistName, lists any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLists", reflect.TypeOf((*MockDistributionRegistry)(nil).DeleteLists), ctx, distlistName, lists)
+}
+
+// DeleteRecipientFromLists mocks base method.
+func (m *MockDistributionRegistry) DeleteRecipientFromLists(ctx context.Context, recipient string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecipientFromLists", ctx, recipient)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRecipientFromLists indicates an expected call of DeleteRecipientFromLists.
+func (mr *MockDistributionRegistryMockRecorder) DeleteRecipientFromLists(ctx, recipient any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecipientFromLists", reflect.TypeOf((*MockDistributionRegistry)(nil).DeleteRecipientFromLists), ctx, recipient)
+}
+
 // DeleteRecipients mocks base method.
 func (m *MockDistributionRegistry) DeleteRecipients(ctx context.Context, distlistName string, recipients []string) (*dto.DistributionListSummary, error) {
 	m.ctrl.T.Helper()
@@ -100,19 +146,78 @@ func (mr *MockDistributionRegistryMockRecorder) DeleteRecipients(ctx, distlistNa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecipients", reflect.TypeOf((*MockDistributionRegistry)(nil).DeleteRecipients), ctx, distlistName, recipients)
 }
 
+// ExportRecipients mocks base method.
+func (m *MockDistributionRegistry) ExportRecipients(ctx context.Context, distlistName string, write func([]string) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportRecipients", ctx, distlistName, write)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportRecipients indicates an expected call of ExportRecipients.
+func (mr *MockDistributionRegistryMockRecorder) ExportRecipients(ctx, distlistName, write any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportRecipients", reflect.TypeOf((*MockDistributionRegistry)(nil).ExportRecipients), ctx, distlistName, write)
+}
+
+// GetDistributionListDetails mocks base method.
+func (m *MockDistributionRegistry) GetDistributionListDetails(ctx context.Context, distlistName string) (dto.DistributionListDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDistributionListDetails", ctx, distlistName)
+	ret0, _ := ret[0].(dto.DistributionListDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDistributionListDetails indicates an expected call of GetDistributionListDetails.
+func (mr *MockDistributionRegistryMockRecorder) GetDistributionListDetails(ctx, distlistName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDistributionListDetails", reflect.TypeOf((*MockDistributionRegistry)(nil).GetDistributionListDetails), ctx, distlistName)
+}
+
 // GetDistributionLists mocks base method.
-func (m *MockDistributionRegistry) GetDistributionLists(ctx context.Context, filter dto0.PageFilter) (dto0.Page[dto.DistributionListSummary], error) {
+func (m *MockDistributionRegistry) GetDistributionLists(ctx context.Context, filters dto.DistributionListFilters) (dto0.Page[dto.DistributionListSummary], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetDistributionLists", ctx, filter)
+	ret := m.ctrl.Call(m, "GetDistributionLists", ctx, filters)
 	ret0, _ := ret[0].(dto0.Page[dto.DistributionListSummary])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetDistributionLists indicates an expected call of GetDistributionLists.
-func (mr *MockDistributionRegistryMockRecorder) GetDistributionLists(ctx, filter any) *gomock.Call {
+func (mr *MockDistributionRegistryMockRecorder) GetDistributionLists(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDistributionLists", reflect.TypeOf((*MockDistributionRegistry)(nil).GetDistributionLists), ctx, filters)
+}
+
+// GetFlattenedRecipients mocks base method.
+func (m *MockDistributionRegistry) GetFlattenedRecipients(ctx context.Context, distlistName string, filter dto0.PageFilter) (dto0.Page[string], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlattenedRecipients", ctx, distlistName, filter)
+	ret0, _ := ret[0].(dto0.Page[string])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFlattenedRecipients indicates an expected call of GetFlattenedRecipients.
+func (mr *MockDistributionRegistryMockRecorder) GetFlattenedRecipients(ctx, distlistName, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlattenedRecipients", reflect.TypeOf((*MockDistributionRegistry)(nil).GetFlattenedRecipients), ctx, distlistName, filter)
+}
+
+// GetLists mocks base method.
+func (m *MockDistributionRegistry) GetLists(ctx context.Context, distlistName string, filter dto0.PageFilter) (dto0.Page[string], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLists", ctx, distlistName, filter)
+	ret0, _ := ret[0].(dto0.Page[string])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLists indicates an expected call of GetLists.
+func (mr *MockDistributionRegistryMockRecorder) GetLists(ctx, distlistName, filter any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDistributionLists", reflect.TypeOf((*MockDistributionRegistry)(nil).GetDistributionLists), ctx, filter)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLists", reflect.TypeOf((*MockDistributionRegistry)(nil).GetLists), ctx, distlistName, filter)
 }
 
 // GetRecipients mocks base method.
@@ -129,3 +234,102 @@ func (mr *MockDistributionRegistryMockRecorder) GetRecipients(ctx, distlistName,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecipients", reflect.TypeOf((*MockDistributionRegistry)(nil).GetRecipients), ctx, distlistName, filter)
 }
+
+// GetSegment mocks base method.
+func (m *MockDistributionRegistry) GetSegment(ctx context.Context, distlistName string) (*dto0.Segment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegment", ctx, distlistName)
+	ret0, _ := ret[0].(*dto0.Segment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegment indicates an expected call of GetSegment.
+func (mr *MockDistributionRegistryMockRecorder) GetSegment(ctx, distlistName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegment", reflect.TypeOf((*MockDistributionRegistry)(nil).GetSegment), ctx, distlistName)
+}
+
+// ImportRecipients mocks base method.
+func (m *MockDistributionRegistry) ImportRecipients(ctx context.Context, distlistName string, mode dto.RecipientsImportMode, batches recipients.Batches) (*dto.DistributionListSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportRecipients", ctx, distlistName, mode, batches)
+	ret0, _ := ret[0].(*dto.DistributionListSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportRecipients indicates an expected call of ImportRecipients.
+func (mr *MockDistributionRegistryMockRecorder) ImportRecipients(ctx, distlistName, mode, batches any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportRecipients", reflect.TypeOf((*MockDistributionRegistry)(nil).ImportRecipients), ctx, distlistName, mode, batches)
+}
+
+// RenameDistributionList mocks base method.
+func (m *MockDistributionRegistry) RenameDistributionList(ctx context.Context, distlistName, newName string) (dto.DistributionListDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameDistributionList", ctx, distlistName, newName)
+	ret0, _ := ret[0].(dto.DistributionListDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameDistributionList indicates an expected call of RenameDistributionList.
+func (mr *MockDistributionRegistryMockRecorder) RenameDistributionList(ctx, distlistName, newName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameDistributionList", reflect.TypeOf((*MockDistributionRegistry)(nil).RenameDistributionList), ctx, distlistName, newName)
+}
+
+// UpdateDistributionList mocks base method.
+func (m *MockDistributionRegistry) UpdateDistributionList(ctx context.Context, distlistName string, metadata dto.DistributionListMetadata) (dto.DistributionListDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDistributionList", ctx, distlistName, metadata)
+	ret0, _ := ret[0].(dto.DistributionListDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDistributionList indicates an expected call of UpdateDistributionList.
+func (mr *MockDistributionRegistryMockRecorder) UpdateDistributionList(ctx, distlistName, metadata any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDistributionList", reflect.TypeOf((*MockDistributionRegistry)(nil).UpdateDistributionList), ctx, distlistName, metadata)
+}
+
+// MockAudienceResolver is a mock of AudienceResolver interface.
+type MockAudienceResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockAudienceResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockAudienceResolverMockRecorder is the mock recorder for MockAudienceResolver.
+type MockAudienceResolverMockRecorder struct {
+	mock *MockAudienceResolver
+}
+
+// NewMockAudienceResolver creates a new mock instance.
+func NewMockAudienceResolver(ctrl *gomock.Controller) *MockAudienceResolver {
+	mock := &MockAudienceResolver{ctrl: ctrl}
+	mock.recorder = &MockAudienceResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAudienceResolver) EXPECT() *MockAudienceResolverMockRecorder {
+	return m.recorder
+}
+
+// Preview mocks base method.
+func (m *MockAudienceResolver) Preview(ctx context.Context, segment dto0.Segment, sampleSize int) (dto0.SegmentPreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preview", ctx, segment, sampleSize)
+	ret0, _ := ret[0].(dto0.SegmentPreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preview indicates an expected call of Preview.
+func (mr *MockAudienceResolverMockRecorder) Preview(ctx, segment, sampleSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preview", reflect.TypeOf((*MockAudienceResolver)(nil).Preview), ctx, segment, sampleSize)
+}
@@ -0,0 +1,148 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/controllers/notification_schedules.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/controllers/notification_schedules.go -destination=./internal/testutils/mocks/notification_schedules.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	dto "github.com/notifique/service/internal/dto"
+	dto0 "github.com/notifique/shared/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationScheduleRegistry is a mock of NotificationScheduleRegistry interface.
+type MockNotificationScheduleRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationScheduleRegistryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationScheduleRegistryMockRecorder is the mock recorder for MockNotificationScheduleRegistry.
+type MockNotificationScheduleRegistryMockRecorder struct {
+	mock *MockNotificationScheduleRegistry
+}
+
+// NewMockNotificationScheduleRegistry creates a new mock instance.
+func NewMockNotificationScheduleRegistry(ctrl *gomock.Controller) *MockNotificationScheduleRegistry {
+	mock := &MockNotificationScheduleRegistry{ctrl: ctrl}
+	mock.recorder = &MockNotificationScheduleRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationScheduleRegistry) EXPECT() *MockNotificationScheduleRegistryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSchedule mocks base method.
+func (m *MockNotificationScheduleRegistry) DeleteSchedule(ctx context.Context, scheduleId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedule", ctx, scheduleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule.
+func (mr *MockNotificationScheduleRegistryMockRecorder) DeleteSchedule(ctx, scheduleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockNotificationScheduleRegistry)(nil).DeleteSchedule), ctx, scheduleId)
+}
+
+// GetSchedule mocks base method.
+func (m *MockNotificationScheduleRegistry) GetSchedule(ctx context.Context, scheduleId string) (dto.NotificationScheduleResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", ctx, scheduleId)
+	ret0, _ := ret[0].(dto.NotificationScheduleResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockNotificationScheduleRegistryMockRecorder) GetSchedule(ctx, scheduleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockNotificationScheduleRegistry)(nil).GetSchedule), ctx, scheduleId)
+}
+
+// GetSchedules mocks base method.
+func (m *MockNotificationScheduleRegistry) GetSchedules(ctx context.Context, filters dto0.PageFilter) (dto0.Page[dto.NotificationScheduleSummary], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedules", ctx, filters)
+	ret0, _ := ret[0].(dto0.Page[dto.NotificationScheduleSummary])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedules indicates an expected call of GetSchedules.
+func (mr *MockNotificationScheduleRegistryMockRecorder) GetSchedules(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedules", reflect.TypeOf((*MockNotificationScheduleRegistry)(nil).GetSchedules), ctx, filters)
+}
+
+// PauseSchedule mocks base method.
+func (m *MockNotificationScheduleRegistry) PauseSchedule(ctx context.Context, scheduleId, updatedBy string) (dto.NotificationScheduleResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseSchedule", ctx, scheduleId, updatedBy)
+	ret0, _ := ret[0].(dto.NotificationScheduleResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseSchedule indicates an expected call of PauseSchedule.
+func (mr *MockNotificationScheduleRegistryMockRecorder) PauseSchedule(ctx, scheduleId, updatedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseSchedule", reflect.TypeOf((*MockNotificationScheduleRegistry)(nil).PauseSchedule), ctx, scheduleId, updatedBy)
+}
+
+// ResumeSchedule mocks base method.
+func (m *MockNotificationScheduleRegistry) ResumeSchedule(ctx context.Context, scheduleId, updatedBy string, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeSchedule", ctx, scheduleId, updatedBy, nextRunAt)
+	ret0, _ := ret[0].(dto.NotificationScheduleResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeSchedule indicates an expected call of ResumeSchedule.
+func (mr *MockNotificationScheduleRegistryMockRecorder) ResumeSchedule(ctx, scheduleId, updatedBy, nextRunAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSchedule", reflect.TypeOf((*MockNotificationScheduleRegistry)(nil).ResumeSchedule), ctx, scheduleId, updatedBy, nextRunAt)
+}
+
+// SaveSchedule mocks base method.
+func (m *MockNotificationScheduleRegistry) SaveSchedule(ctx context.Context, createdBy string, schedule dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSchedule", ctx, createdBy, schedule, nextRunAt)
+	ret0, _ := ret[0].(dto.NotificationScheduleResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveSchedule indicates an expected call of SaveSchedule.
+func (mr *MockNotificationScheduleRegistryMockRecorder) SaveSchedule(ctx, createdBy, schedule, nextRunAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSchedule", reflect.TypeOf((*MockNotificationScheduleRegistry)(nil).SaveSchedule), ctx, createdBy, schedule, nextRunAt)
+}
+
+// UpdateSchedule mocks base method.
+func (m *MockNotificationScheduleRegistry) UpdateSchedule(ctx context.Context, scheduleId, updatedBy string, schedule dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSchedule", ctx, scheduleId, updatedBy, schedule, nextRunAt)
+	ret0, _ := ret[0].(dto.NotificationScheduleResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule.
+func (mr *MockNotificationScheduleRegistryMockRecorder) UpdateSchedule(ctx, scheduleId, updatedBy, schedule, nextRunAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockNotificationScheduleRegistry)(nil).UpdateSchedule), ctx, scheduleId, updatedBy, schedule, nextRunAt)
+}
@@ -86,8 +86,23 @@ func (mr *MockNotificationRegistryMockRecorder) GetNotificationStatus(ctx, notif
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationStatus", reflect.TypeOf((*MockNotificationRegistry)(nil).GetNotificationStatus), ctx, notificationId)
 }
 
+// GetNotificationStatusLogs mocks base method.
+func (m *MockNotificationRegistry) GetNotificationStatusLogs(ctx context.Context, notificationId string, filters dto0.PageFilter) (dto0.Page[dto.NotificationStatusLogResp], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationStatusLogs", ctx, notificationId, filters)
+	ret0, _ := ret[0].(dto0.Page[dto.NotificationStatusLogResp])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationStatusLogs indicates an expected call of GetNotificationStatusLogs.
+func (mr *MockNotificationRegistryMockRecorder) GetNotificationStatusLogs(ctx, notificationId, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationStatusLogs", reflect.TypeOf((*MockNotificationRegistry)(nil).GetNotificationStatusLogs), ctx, notificationId, filters)
+}
+
 // GetNotifications mocks base method.
-func (m *MockNotificationRegistry) GetNotifications(ctx context.Context, filters dto0.PageFilter) (dto0.Page[dto.NotificationSummary], error) {
+func (m *MockNotificationRegistry) GetNotifications(ctx context.Context, filters dto.NotificationFilters) (dto0.Page[dto.NotificationSummary], error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetNotifications", ctx, filters)
 	ret0, _ := ret[0].(dto0.Page[dto.NotificationSummary])
@@ -117,25 +132,40 @@ func (mr *MockNotificationRegistryMockRecorder) GetRecipientNotificationStatuses
 }
 
 // GetTemplateVariables mocks base method.
-func (m *MockNotificationRegistry) GetTemplateVariables(ctx context.Context, templateId string) ([]dto0.TemplateVariable, error) {
+func (m *MockNotificationRegistry) GetTemplateVariables(ctx context.Context, templateId string, version *int) ([]dto0.TemplateVariable, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTemplateVariables", ctx, templateId)
+	ret := m.ctrl.Call(m, "GetTemplateVariables", ctx, templateId, version)
 	ret0, _ := ret[0].([]dto0.TemplateVariable)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetTemplateVariables indicates an expected call of GetTemplateVariables.
-func (mr *MockNotificationRegistryMockRecorder) GetTemplateVariables(ctx, templateId any) *gomock.Call {
+func (mr *MockNotificationRegistryMockRecorder) GetTemplateVariables(ctx, templateId, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVariables", reflect.TypeOf((*MockNotificationRegistry)(nil).GetTemplateVariables), ctx, templateId, version)
+}
+
+// ResendNotification mocks base method.
+func (m *MockNotificationRegistry) ResendNotification(ctx context.Context, payload dto0.NotificationMsgPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendNotification", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendNotification indicates an expected call of ResendNotification.
+func (mr *MockNotificationRegistryMockRecorder) ResendNotification(ctx, payload any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVariables", reflect.TypeOf((*MockNotificationRegistry)(nil).GetTemplateVariables), ctx, templateId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendNotification", reflect.TypeOf((*MockNotificationRegistry)(nil).ResendNotification), ctx, payload)
 }
 
 // SaveNotification mocks base method.
-func (m *MockNotificationRegistry) SaveNotification(ctx context.Context, createdBy string, notification dto0.NotificationReq) (string, error) {
+func (m *MockNotificationRegistry) SaveNotification(ctx context.Context, createdBy string, notification dto0.NotificationReq) (dto0.NotificationCreatedResp, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveNotification", ctx, createdBy, notification)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(dto0.NotificationCreatedResp)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -0,0 +1,170 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/outbox/relay.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/outbox/relay.go -destination=./internal/testutils/mocks/outbox.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	dto "github.com/notifique/service/internal/dto"
+	dto0 "github.com/notifique/shared/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOutboxRegistry is a mock of OutboxRegistry interface.
+type MockOutboxRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRegistryMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxRegistryMockRecorder is the mock recorder for MockOutboxRegistry.
+type MockOutboxRegistryMockRecorder struct {
+	mock *MockOutboxRegistry
+}
+
+// NewMockOutboxRegistry creates a new mock instance.
+func NewMockOutboxRegistry(ctrl *gomock.Controller) *MockOutboxRegistry {
+	mock := &MockOutboxRegistry{ctrl: ctrl}
+	mock.recorder = &MockOutboxRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRegistry) EXPECT() *MockOutboxRegistryMockRecorder {
+	return m.recorder
+}
+
+// ClaimOutboxEntry mocks base method.
+func (m *MockOutboxRegistry) ClaimOutboxEntry(ctx context.Context, notificationId string, attempts int, leaseUntil time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEntry", ctx, notificationId, attempts, leaseUntil)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEntry indicates an expected call of ClaimOutboxEntry.
+func (mr *MockOutboxRegistryMockRecorder) ClaimOutboxEntry(ctx, notificationId, attempts, leaseUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEntry", reflect.TypeOf((*MockOutboxRegistry)(nil).ClaimOutboxEntry), ctx, notificationId, attempts, leaseUntil)
+}
+
+// DeleteOutboxEntry mocks base method.
+func (m *MockOutboxRegistry) DeleteOutboxEntry(ctx context.Context, notificationId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutboxEntry", ctx, notificationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutboxEntry indicates an expected call of DeleteOutboxEntry.
+func (mr *MockOutboxRegistryMockRecorder) DeleteOutboxEntry(ctx, notificationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxEntry", reflect.TypeOf((*MockOutboxRegistry)(nil).DeleteOutboxEntry), ctx, notificationId)
+}
+
+// GetNotificationStatus mocks base method.
+func (m *MockOutboxRegistry) GetNotificationStatus(ctx context.Context, notificationId string) (dto0.NotificationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationStatus", ctx, notificationId)
+	ret0, _ := ret[0].(dto0.NotificationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationStatus indicates an expected call of GetNotificationStatus.
+func (mr *MockOutboxRegistryMockRecorder) GetNotificationStatus(ctx, notificationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationStatus", reflect.TypeOf((*MockOutboxRegistry)(nil).GetNotificationStatus), ctx, notificationId)
+}
+
+// GetOutboxEntries mocks base method.
+func (m *MockOutboxRegistry) GetOutboxEntries(ctx context.Context, availableBefore time.Time, maxResults int) ([]dto.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEntries", ctx, availableBefore, maxResults)
+	ret0, _ := ret[0].([]dto.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEntries indicates an expected call of GetOutboxEntries.
+func (mr *MockOutboxRegistryMockRecorder) GetOutboxEntries(ctx, availableBefore, maxResults any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEntries", reflect.TypeOf((*MockOutboxRegistry)(nil).GetOutboxEntries), ctx, availableBefore, maxResults)
+}
+
+// RetryOutboxEntry mocks base method.
+func (m *MockOutboxRegistry) RetryOutboxEntry(ctx context.Context, notificationId string, retryAt time.Time, errorMsg string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryOutboxEntry", ctx, notificationId, retryAt, errorMsg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryOutboxEntry indicates an expected call of RetryOutboxEntry.
+func (mr *MockOutboxRegistryMockRecorder) RetryOutboxEntry(ctx, notificationId, retryAt, errorMsg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryOutboxEntry", reflect.TypeOf((*MockOutboxRegistry)(nil).RetryOutboxEntry), ctx, notificationId, retryAt, errorMsg)
+}
+
+// UpdateNotificationStatus mocks base method.
+func (m *MockOutboxRegistry) UpdateNotificationStatus(ctx context.Context, statusLog dto0.NotificationStatusLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationStatus", ctx, statusLog)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotificationStatus indicates an expected call of UpdateNotificationStatus.
+func (mr *MockOutboxRegistryMockRecorder) UpdateNotificationStatus(ctx, statusLog any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationStatus", reflect.TypeOf((*MockOutboxRegistry)(nil).UpdateNotificationStatus), ctx, statusLog)
+}
+
+// MockRelayConfigurator is a mock of RelayConfigurator interface.
+type MockRelayConfigurator struct {
+	ctrl     *gomock.Controller
+	recorder *MockRelayConfiguratorMockRecorder
+	isgomock struct{}
+}
+
+// MockRelayConfiguratorMockRecorder is the mock recorder for MockRelayConfigurator.
+type MockRelayConfiguratorMockRecorder struct {
+	mock *MockRelayConfigurator
+}
+
+// NewMockRelayConfigurator creates a new mock instance.
+func NewMockRelayConfigurator(ctrl *gomock.Controller) *MockRelayConfigurator {
+	mock := &MockRelayConfigurator{ctrl: ctrl}
+	mock.recorder = &MockRelayConfiguratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRelayConfigurator) EXPECT() *MockRelayConfiguratorMockRecorder {
+	return m.recorder
+}
+
+// GetOutboxRelayInterval mocks base method.
+func (m *MockRelayConfigurator) GetOutboxRelayInterval() (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxRelayInterval")
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxRelayInterval indicates an expected call of GetOutboxRelayInterval.
+func (mr *MockRelayConfiguratorMockRecorder) GetOutboxRelayInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxRelayInterval", reflect.TypeOf((*MockRelayConfigurator)(nil).GetOutboxRelayInterval))
+}
@@ -0,0 +1,197 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/scheduler/scheduler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/scheduler/scheduler.go -destination=./internal/testutils/mocks/scheduler.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	dto "github.com/notifique/service/internal/dto"
+	dto0 "github.com/notifique/shared/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockScheduledNotificationRegistry is a mock of ScheduledNotificationRegistry interface.
+type MockScheduledNotificationRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduledNotificationRegistryMockRecorder
+	isgomock struct{}
+}
+
+// MockScheduledNotificationRegistryMockRecorder is the mock recorder for MockScheduledNotificationRegistry.
+type MockScheduledNotificationRegistryMockRecorder struct {
+	mock *MockScheduledNotificationRegistry
+}
+
+// NewMockScheduledNotificationRegistry creates a new mock instance.
+func NewMockScheduledNotificationRegistry(ctrl *gomock.Controller) *MockScheduledNotificationRegistry {
+	mock := &MockScheduledNotificationRegistry{ctrl: ctrl}
+	mock.recorder = &MockScheduledNotificationRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduledNotificationRegistry) EXPECT() *MockScheduledNotificationRegistryMockRecorder {
+	return m.recorder
+}
+
+// ClaimScheduledNotification mocks base method.
+func (m *MockScheduledNotificationRegistry) ClaimScheduledNotification(ctx context.Context, payload dto0.NotificationMsgPayload) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimScheduledNotification", ctx, payload)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimScheduledNotification indicates an expected call of ClaimScheduledNotification.
+func (mr *MockScheduledNotificationRegistryMockRecorder) ClaimScheduledNotification(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimScheduledNotification", reflect.TypeOf((*MockScheduledNotificationRegistry)(nil).ClaimScheduledNotification), ctx, payload)
+}
+
+// GetDueNotifications mocks base method.
+func (m *MockScheduledNotificationRegistry) GetDueNotifications(ctx context.Context, dueBefore time.Time, maxResults int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueNotifications", ctx, dueBefore, maxResults)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueNotifications indicates an expected call of GetDueNotifications.
+func (mr *MockScheduledNotificationRegistryMockRecorder) GetDueNotifications(ctx, dueBefore, maxResults any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueNotifications", reflect.TypeOf((*MockScheduledNotificationRegistry)(nil).GetDueNotifications), ctx, dueBefore, maxResults)
+}
+
+// GetNotification mocks base method.
+func (m *MockScheduledNotificationRegistry) GetNotification(ctx context.Context, notificationId string) (dto.NotificationResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotification", ctx, notificationId)
+	ret0, _ := ret[0].(dto.NotificationResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotification indicates an expected call of GetNotification.
+func (mr *MockScheduledNotificationRegistryMockRecorder) GetNotification(ctx, notificationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotification", reflect.TypeOf((*MockScheduledNotificationRegistry)(nil).GetNotification), ctx, notificationId)
+}
+
+// MockRecurringScheduleRegistry is a mock of RecurringScheduleRegistry interface.
+type MockRecurringScheduleRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecurringScheduleRegistryMockRecorder
+	isgomock struct{}
+}
+
+// MockRecurringScheduleRegistryMockRecorder is the mock recorder for MockRecurringScheduleRegistry.
+type MockRecurringScheduleRegistryMockRecorder struct {
+	mock *MockRecurringScheduleRegistry
+}
+
+// NewMockRecurringScheduleRegistry creates a new mock instance.
+func NewMockRecurringScheduleRegistry(ctrl *gomock.Controller) *MockRecurringScheduleRegistry {
+	mock := &MockRecurringScheduleRegistry{ctrl: ctrl}
+	mock.recorder = &MockRecurringScheduleRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecurringScheduleRegistry) EXPECT() *MockRecurringScheduleRegistryMockRecorder {
+	return m.recorder
+}
+
+// AdvanceSchedule mocks base method.
+func (m *MockRecurringScheduleRegistry) AdvanceSchedule(ctx context.Context, scheduleId string, runAt, nextRunAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceSchedule", ctx, scheduleId, runAt, nextRunAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvanceSchedule indicates an expected call of AdvanceSchedule.
+func (mr *MockRecurringScheduleRegistryMockRecorder) AdvanceSchedule(ctx, scheduleId, runAt, nextRunAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceSchedule", reflect.TypeOf((*MockRecurringScheduleRegistry)(nil).AdvanceSchedule), ctx, scheduleId, runAt, nextRunAt)
+}
+
+// GetDueSchedules mocks base method.
+func (m *MockRecurringScheduleRegistry) GetDueSchedules(ctx context.Context, dueBefore time.Time, maxResults int) ([]dto.NotificationScheduleResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueSchedules", ctx, dueBefore, maxResults)
+	ret0, _ := ret[0].([]dto.NotificationScheduleResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueSchedules indicates an expected call of GetDueSchedules.
+func (mr *MockRecurringScheduleRegistryMockRecorder) GetDueSchedules(ctx, dueBefore, maxResults any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueSchedules", reflect.TypeOf((*MockRecurringScheduleRegistry)(nil).GetDueSchedules), ctx, dueBefore, maxResults)
+}
+
+// RunSchedule mocks base method.
+func (m *MockRecurringScheduleRegistry) RunSchedule(ctx context.Context, schedule dto.NotificationScheduleResp, runAt, nextRunAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunSchedule", ctx, schedule, runAt, nextRunAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunSchedule indicates an expected call of RunSchedule.
+func (mr *MockRecurringScheduleRegistryMockRecorder) RunSchedule(ctx, schedule, runAt, nextRunAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunSchedule", reflect.TypeOf((*MockRecurringScheduleRegistry)(nil).RunSchedule), ctx, schedule, runAt, nextRunAt)
+}
+
+// MockSchedulerConfigurator is a mock of SchedulerConfigurator interface.
+type MockSchedulerConfigurator struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchedulerConfiguratorMockRecorder
+	isgomock struct{}
+}
+
+// MockSchedulerConfiguratorMockRecorder is the mock recorder for MockSchedulerConfigurator.
+type MockSchedulerConfiguratorMockRecorder struct {
+	mock *MockSchedulerConfigurator
+}
+
+// NewMockSchedulerConfigurator creates a new mock instance.
+func NewMockSchedulerConfigurator(ctrl *gomock.Controller) *MockSchedulerConfigurator {
+	mock := &MockSchedulerConfigurator{ctrl: ctrl}
+	mock.recorder = &MockSchedulerConfiguratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchedulerConfigurator) EXPECT() *MockSchedulerConfiguratorMockRecorder {
+	return m.recorder
+}
+
+// GetSchedulerInterval mocks base method.
+func (m *MockSchedulerConfigurator) GetSchedulerInterval() (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedulerInterval")
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedulerInterval indicates an expected call of GetSchedulerInterval.
+func (mr *MockSchedulerConfiguratorMockRecorder) GetSchedulerInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedulerInterval", reflect.TypeOf((*MockSchedulerConfigurator)(nil).GetSchedulerInterval))
+}
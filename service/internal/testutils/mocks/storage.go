@@ -5,15 +5,20 @@ type MockedRegistry struct {
 	*MockUserRegistry
 	*MockNotificationRegistry
 	*MockNotificationTemplateRegistry
+	*MockTemplatePartialRegistry
+	*MockNotificationScheduleRegistry
 }
 
 func NewMockedRegistry(dlr *MockDistributionRegistry, ur *MockUserRegistry,
-	nr *MockNotificationRegistry, ntr *MockNotificationTemplateRegistry) *MockedRegistry {
+	nr *MockNotificationRegistry, ntr *MockNotificationTemplateRegistry,
+	tpr *MockTemplatePartialRegistry, nsr *MockNotificationScheduleRegistry) *MockedRegistry {
 
 	return &MockedRegistry{
 		dlr,
 		ur,
 		nr,
 		ntr,
+		tpr,
+		nsr,
 	}
 }
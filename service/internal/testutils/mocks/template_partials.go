@@ -0,0 +1,132 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/controllers/template_partials.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/controllers/template_partials.go -destination=./internal/testutils/mocks/template_partials.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/notifique/service/internal/dto"
+	dto0 "github.com/notifique/shared/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTemplatePartialRegistry is a mock of TemplatePartialRegistry interface.
+type MockTemplatePartialRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplatePartialRegistryMockRecorder
+	isgomock struct{}
+}
+
+// MockTemplatePartialRegistryMockRecorder is the mock recorder for MockTemplatePartialRegistry.
+type MockTemplatePartialRegistryMockRecorder struct {
+	mock *MockTemplatePartialRegistry
+}
+
+// NewMockTemplatePartialRegistry creates a new mock instance.
+func NewMockTemplatePartialRegistry(ctrl *gomock.Controller) *MockTemplatePartialRegistry {
+	mock := &MockTemplatePartialRegistry{ctrl: ctrl}
+	mock.recorder = &MockTemplatePartialRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTemplatePartialRegistry) EXPECT() *MockTemplatePartialRegistryMockRecorder {
+	return m.recorder
+}
+
+// DeleteTemplatePartial mocks base method.
+func (m *MockTemplatePartialRegistry) DeleteTemplatePartial(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplatePartial", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplatePartial indicates an expected call of DeleteTemplatePartial.
+func (mr *MockTemplatePartialRegistryMockRecorder) DeleteTemplatePartial(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplatePartial", reflect.TypeOf((*MockTemplatePartialRegistry)(nil).DeleteTemplatePartial), ctx, name)
+}
+
+// GetTemplatePartial mocks base method.
+func (m *MockTemplatePartialRegistry) GetTemplatePartial(ctx context.Context, name string) (dto0.TemplatePartialDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatePartial", ctx, name)
+	ret0, _ := ret[0].(dto0.TemplatePartialDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatePartial indicates an expected call of GetTemplatePartial.
+func (mr *MockTemplatePartialRegistryMockRecorder) GetTemplatePartial(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatePartial", reflect.TypeOf((*MockTemplatePartialRegistry)(nil).GetTemplatePartial), ctx, name)
+}
+
+// GetTemplatePartialVersion mocks base method.
+func (m *MockTemplatePartialRegistry) GetTemplatePartialVersion(ctx context.Context, name string, version int) (dto0.TemplatePartialDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatePartialVersion", ctx, name, version)
+	ret0, _ := ret[0].(dto0.TemplatePartialDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatePartialVersion indicates an expected call of GetTemplatePartialVersion.
+func (mr *MockTemplatePartialRegistryMockRecorder) GetTemplatePartialVersion(ctx, name, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatePartialVersion", reflect.TypeOf((*MockTemplatePartialRegistry)(nil).GetTemplatePartialVersion), ctx, name, version)
+}
+
+// GetTemplatePartials mocks base method.
+func (m *MockTemplatePartialRegistry) GetTemplatePartials(ctx context.Context, filters dto.TemplatePartialFilters) (dto0.Page[dto.TemplatePartialInfoResp], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatePartials", ctx, filters)
+	ret0, _ := ret[0].(dto0.Page[dto.TemplatePartialInfoResp])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatePartials indicates an expected call of GetTemplatePartials.
+func (mr *MockTemplatePartialRegistryMockRecorder) GetTemplatePartials(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatePartials", reflect.TypeOf((*MockTemplatePartialRegistry)(nil).GetTemplatePartials), ctx, filters)
+}
+
+// SaveTemplatePartial mocks base method.
+func (m *MockTemplatePartialRegistry) SaveTemplatePartial(ctx context.Context, createdBy string, tpr dto.TemplatePartialReq) (dto0.TemplatePartialDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTemplatePartial", ctx, createdBy, tpr)
+	ret0, _ := ret[0].(dto0.TemplatePartialDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTemplatePartial indicates an expected call of SaveTemplatePartial.
+func (mr *MockTemplatePartialRegistryMockRecorder) SaveTemplatePartial(ctx, createdBy, tpr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTemplatePartial", reflect.TypeOf((*MockTemplatePartialRegistry)(nil).SaveTemplatePartial), ctx, createdBy, tpr)
+}
+
+// UpdateTemplatePartial mocks base method.
+func (m *MockTemplatePartialRegistry) UpdateTemplatePartial(ctx context.Context, name, updatedBy string, tpr dto.TemplatePartialUpdateReq) (dto0.TemplatePartialDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplatePartial", ctx, name, updatedBy, tpr)
+	ret0, _ := ret[0].(dto0.TemplatePartialDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplatePartial indicates an expected call of UpdateTemplatePartial.
+func (mr *MockTemplatePartialRegistryMockRecorder) UpdateTemplatePartial(ctx, name, updatedBy, tpr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplatePartial", reflect.TypeOf((*MockTemplatePartialRegistry)(nil).UpdateTemplatePartial), ctx, name, updatedBy, tpr)
+}
@@ -42,18 +42,18 @@ func (m *MockNotificationTemplateRegistry) EXPECT() *MockNotificationTemplateReg
 	return m.recorder
 }
 
-// DeleteTemplate mocks base method.
-func (m *MockNotificationTemplateRegistry) DeleteTemplate(ctx context.Context, id string) error {
+// ArchiveTemplate mocks base method.
+func (m *MockNotificationTemplateRegistry) ArchiveTemplate(ctx context.Context, templateId, archivedBy string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTemplate", ctx, id)
+	ret := m.ctrl.Call(m, "ArchiveTemplate", ctx, templateId, archivedBy)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTemplate indicates an expected call of DeleteTemplate.
-func (mr *MockNotificationTemplateRegistryMockRecorder) DeleteTemplate(ctx, id any) *gomock.Call {
+// ArchiveTemplate indicates an expected call of ArchiveTemplate.
+func (mr *MockNotificationTemplateRegistryMockRecorder) ArchiveTemplate(ctx, templateId, archivedBy any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplate", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).DeleteTemplate), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveTemplate", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).ArchiveTemplate), ctx, templateId, archivedBy)
 }
 
 // GetTemplateDetails mocks base method.
@@ -71,6 +71,36 @@ func (mr *MockNotificationTemplateRegistryMockRecorder) GetTemplateDetails(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateDetails", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).GetTemplateDetails), ctx, id)
 }
 
+// GetTemplateVersion mocks base method.
+func (m *MockNotificationTemplateRegistry) GetTemplateVersion(ctx context.Context, templateId string, version int) (dto0.NotificationTemplateDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersion", ctx, templateId, version)
+	ret0, _ := ret[0].(dto0.NotificationTemplateDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersion indicates an expected call of GetTemplateVersion.
+func (mr *MockNotificationTemplateRegistryMockRecorder) GetTemplateVersion(ctx, templateId, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersion", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).GetTemplateVersion), ctx, templateId, version)
+}
+
+// GetTemplateVersions mocks base method.
+func (m *MockNotificationTemplateRegistry) GetTemplateVersions(ctx context.Context, templateId string, filters dto0.PageFilter) (dto0.Page[dto.NotificationTemplateVersionResp], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersions", ctx, templateId, filters)
+	ret0, _ := ret[0].(dto0.Page[dto.NotificationTemplateVersionResp])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersions indicates an expected call of GetTemplateVersions.
+func (mr *MockNotificationTemplateRegistryMockRecorder) GetTemplateVersions(ctx, templateId, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersions", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).GetTemplateVersions), ctx, templateId, filters)
+}
+
 // GetTemplates mocks base method.
 func (m *MockNotificationTemplateRegistry) GetTemplates(ctx context.Context, filters dto.NotificationTemplateFilters) (dto0.Page[dto.NotificationTemplateInfoResp], error) {
 	m.ctrl.T.Helper()
@@ -86,6 +116,20 @@ func (mr *MockNotificationTemplateRegistryMockRecorder) GetTemplates(ctx, filter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplates", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).GetTemplates), ctx, filters)
 }
 
+// PurgeTemplate mocks base method.
+func (m *MockNotificationTemplateRegistry) PurgeTemplate(ctx context.Context, templateId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTemplate", ctx, templateId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeTemplate indicates an expected call of PurgeTemplate.
+func (mr *MockNotificationTemplateRegistryMockRecorder) PurgeTemplate(ctx, templateId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTemplate", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).PurgeTemplate), ctx, templateId)
+}
+
 // SaveTemplate mocks base method.
 func (m *MockNotificationTemplateRegistry) SaveTemplate(ctx context.Context, createdBy string, ntr dto.NotificationTemplateReq) (dto.NotificationTemplateCreatedResp, error) {
 	m.ctrl.T.Helper()
@@ -100,3 +144,18 @@ func (mr *MockNotificationTemplateRegistryMockRecorder) SaveTemplate(ctx, create
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTemplate", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).SaveTemplate), ctx, createdBy, ntr)
 }
+
+// UpdateTemplate mocks base method.
+func (m *MockNotificationTemplateRegistry) UpdateTemplate(ctx context.Context, templateId, updatedBy string, ntr dto.NotificationTemplateReq) (dto0.NotificationTemplateDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplate", ctx, templateId, updatedBy, ntr)
+	ret0, _ := ret[0].(dto0.NotificationTemplateDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplate indicates an expected call of UpdateTemplate.
+func (mr *MockNotificationTemplateRegistryMockRecorder) UpdateTemplate(ctx, templateId, updatedBy, ntr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplate", reflect.TypeOf((*MockNotificationTemplateRegistry)(nil).UpdateTemplate), ctx, templateId, updatedBy, ntr)
+}
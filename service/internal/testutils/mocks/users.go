@@ -115,6 +115,45 @@ func (mr *MockUserRegistryMockRecorder) UpdateUserConfig(ctx, userId, config any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserConfig", reflect.TypeOf((*MockUserRegistry)(nil).UpdateUserConfig), ctx, userId, config)
 }
 
+// MockUserDistributionListRegistry is a mock of UserDistributionListRegistry interface.
+type MockUserDistributionListRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserDistributionListRegistryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserDistributionListRegistryMockRecorder is the mock recorder for MockUserDistributionListRegistry.
+type MockUserDistributionListRegistryMockRecorder struct {
+	mock *MockUserDistributionListRegistry
+}
+
+// NewMockUserDistributionListRegistry creates a new mock instance.
+func NewMockUserDistributionListRegistry(ctrl *gomock.Controller) *MockUserDistributionListRegistry {
+	mock := &MockUserDistributionListRegistry{ctrl: ctrl}
+	mock.recorder = &MockUserDistributionListRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserDistributionListRegistry) EXPECT() *MockUserDistributionListRegistryMockRecorder {
+	return m.recorder
+}
+
+// GetDistributionLists mocks base method.
+func (m *MockUserDistributionListRegistry) GetDistributionLists(ctx context.Context, filters dto.DistributionListFilters) (dto0.Page[dto.DistributionListSummary], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDistributionLists", ctx, filters)
+	ret0, _ := ret[0].(dto0.Page[dto.DistributionListSummary])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDistributionLists indicates an expected call of GetDistributionLists.
+func (mr *MockUserDistributionListRegistryMockRecorder) GetDistributionLists(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDistributionLists", reflect.TypeOf((*MockUserDistributionListRegistry)(nil).GetDistributionLists), ctx, filters)
+}
+
 // MockUserNotificationBroker is a mock of UserNotificationBroker interface.
 type MockUserNotificationBroker struct {
 	ctrl     *gomock.Controller
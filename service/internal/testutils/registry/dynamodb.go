@@ -26,11 +26,17 @@ func (t *dynamoregistryTester) ClearDB(ctx context.Context) error {
 	tables := []string{
 		ds.DistListRecipientsTable,
 		ds.DistListSummaryTable,
+		ds.DistListListsTable,
 		ds.NotificationsTable,
 		ds.NotificationStatusLogTable,
 		ds.UserConfigTable,
 		ds.UserNotificationsTable,
 		ds.NotificationsTemplateTable,
+		ds.NotificationTemplateVersionsTable,
+		ds.TemplatePartialsTable,
+		ds.TemplatePartialVersionsTable,
+		ds.NotificationSchedulesTable,
+		ds.NotificationOutboxTable,
 	}
 
 	for _, table := range tables {
@@ -183,6 +189,7 @@ func (t *dynamoregistryTester) GetNotificationTemplate(ctx context.Context, id s
 
 	templateReq.Name = template.Name
 	templateReq.IsHtml = template.IsHtml
+	templateReq.Format = template.Format
 	templateReq.Description = template.Description
 	templateReq.TitleTemplate = template.TitleTemplate
 	templateReq.ContentsTemplate = template.ContentsTemplate
@@ -193,6 +200,11 @@ func (t *dynamoregistryTester) GetNotificationTemplate(ctx context.Context, id s
 			Type:       v.Type,
 			Required:   v.Required,
 			Validation: v.Validation,
+			Min:        v.Min,
+			Max:        v.Max,
+			MinLength:  v.MinLength,
+			MaxLength:  v.MaxLength,
+			Values:     v.Values,
 		})
 	}
 
@@ -44,6 +44,7 @@ const getNotificationTemplate = `
 SELECT
 	"name",
 	"is_html",
+	"format",
 	"description",
 	title_template,
 	contents_template
@@ -58,7 +59,12 @@ SELECT
     "name",
     "type",
     "required",
-    "validation"
+    "validation",
+    min_value,
+    max_value,
+    min_length,
+    max_length,
+    allowed_values
 FROM
 	notification_template_variables
 WHERE
@@ -74,6 +80,19 @@ WHERE
 	id = $1;
 `
 
+// InsertNotificationStatusLog logs a status at the given date, so logs
+// with the same date can be tested.
+func (t *postgresresgistryTester) InsertNotificationStatusLog(ctx context.Context, statusLog sdto.NotificationStatusLog, statusDate time.Time) error {
+	_, err := t.conn.Exec(ctx, ps.InsertNotificationStatusLog, pgx.NamedArgs{
+		"notificationId": statusLog.NotificationId,
+		"statusDate":     statusDate,
+		"status":         statusLog.Status,
+		"errorMessage":   statusLog.ErrorMsg,
+	})
+
+	return err
+}
+
 type postgresresgistryTester struct {
 	*ps.Registry
 	conn *pgxpool.Pool
@@ -85,6 +104,7 @@ func (t *postgresresgistryTester) ClearDB(ctx context.Context) error {
 	_, err := t.conn.Exec(ctx, `
 		TRUNCATE distribution_lists CASCADE;
 		TRUNCATE distribution_list_recipients CASCADE;
+		TRUNCATE distribution_list_lists;
 		TRUNCATE notifications CASCADE;
 		TRUNCATE notification_status_log CASCADE;
 		TRUNCATE notification_recipients CASCADE;
@@ -93,6 +113,9 @@ func (t *postgresresgistryTester) ClearDB(ctx context.Context) error {
 		TRUNCATE user_config;
 		TRUNCATE notification_templates CASCADE;
 		TRUNCATE notification_template_variables CASCADE;
+		TRUNCATE notification_template_partials CASCADE;
+		TRUNCATE notification_schedules;
+		TRUNCATE notification_outbox;
 	`)
 
 	return err
@@ -236,6 +259,7 @@ func (t *postgresresgistryTester) GetNotificationTemplate(ctx context.Context, t
 		Scan(
 			&templateReq.Name,
 			&templateReq.IsHtml,
+			&templateReq.Format,
 			&templateReq.Description,
 			&templateReq.TitleTemplate,
 			&templateReq.ContentsTemplate,
@@ -261,6 +285,11 @@ func (t *postgresresgistryTester) GetNotificationTemplate(ctx context.Context, t
 			&row.Type,
 			&row.Required,
 			&row.Validation,
+			&row.Min,
+			&row.Max,
+			&row.MinLength,
+			&row.MaxLength,
+			&row.Values,
 		)
 
 		if err != nil {
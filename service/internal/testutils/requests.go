@@ -46,6 +46,43 @@ func AddUserFilters(req *http.Request, filters *dto.UserNotificationFilters) {
 		q.Add("topics", t)
 	}
 
+	if filters.Format != "" {
+		q.Add("format", string(filters.Format))
+	}
+
+	req.URL.RawQuery = q.Encode()
+}
+
+func AddNotificationFilters(req *http.Request, filters *dto.NotificationFilters) {
+
+	if req == nil || filters == nil {
+		return
+	}
+
+	q := makePageURLQuery(req, filters.PageFilter)
+
+	params := []struct {
+		name  string
+		value *string
+	}{
+		{"topic", filters.Topic},
+		{"status", (*string)(filters.Status)},
+		{"priority", (*string)(filters.Priority)},
+		{"createdBy", filters.CreatedBy},
+		{"contentsType", (*string)(filters.ContentsType)},
+		{"templateId", filters.TemplateId},
+		{"distributionList", filters.DistributionList},
+		{"createdFrom", filters.CreatedFrom},
+		{"createdTo", filters.CreatedTo},
+		{"sortOrder", (*string)(filters.SortOrder)},
+	}
+
+	for _, p := range params {
+		if p.value != nil {
+			q.Add(p.name, *p.value)
+		}
+	}
+
 	req.URL.RawQuery = q.Encode()
 }
 
@@ -57,8 +94,29 @@ func AddNotificationTemplateFilters(req *http.Request, filters *dto.Notification
 
 	q := makePageURLQuery(req, filters.PageFilter)
 
-	if filters.TemplateName != nil {
-		q.Add("templateName", *filters.TemplateName)
+	params := []struct {
+		name  string
+		value *string
+	}{
+		{"templateName", filters.TemplateName},
+		{"category", filters.Category},
+		{"createdBy", filters.CreatedBy},
+		{"format", (*string)(filters.Format)},
+		{"createdFrom", filters.CreatedFrom},
+		{"createdTo", filters.CreatedTo},
+		{"updatedFrom", filters.UpdatedFrom},
+		{"updatedTo", filters.UpdatedTo},
+		{"search", filters.Search},
+	}
+
+	for _, p := range params {
+		if p.value != nil {
+			q.Add(p.name, *p.value)
+		}
+	}
+
+	for _, t := range filters.Tags {
+		q.Add("tags", t)
 	}
 
 	req.URL.RawQuery = q.Encode()
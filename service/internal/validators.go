@@ -3,21 +3,29 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	r "regexp"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/notifique/service/internal/dto"
 	sdto "github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
 )
 
 type TemplateVariableValidator func(string) error
 
 const TemplateVariableNameSeparator = "~"
 
+// valueValidator validates the values of the template variables with
+// the baked in validations, e.g. emails and urls.
+var valueValidator = validator.New()
+
 var FutureValidator validator.Func = func(fl validator.FieldLevel) bool {
 	dateStr, ok := fl.Field().Interface().(string)
 
@@ -30,10 +38,55 @@ var FutureValidator validator.Func = func(fl validator.FieldLevel) bool {
 	return !dateTime.Before(time.Now())
 }
 
+// NotBeforeValidator checks that a RFC3339 date isn't before the date of
+// the field named by the param, i.e., that a date range isn't inverted.
+var NotBeforeValidator validator.Func = func(fl validator.FieldLevel) bool {
+	dateStr, ok := fl.Field().Interface().(string)
+
+	if !ok {
+		return false
+	}
+
+	start := fl.Parent().FieldByName(fl.Param())
+
+	if start.Kind() == reflect.Pointer {
+		if start.IsNil() {
+			return true
+		}
+
+		start = start.Elem()
+	}
+
+	startStr, ok := start.Interface().(string)
+
+	if !ok {
+		return false
+	}
+
+	// The format of both dates is validated by their own tags
+	startTime, err := time.Parse(time.RFC3339, startStr)
+
+	if err != nil {
+		return true
+	}
+
+	dateTime, err := time.Parse(time.RFC3339, dateStr)
+
+	if err != nil {
+		return false
+	}
+
+	return !dateTime.Before(startTime)
+}
+
+// reservedDLNames are the path segments of the distribution list routes,
+// which would shadow the routes of the lists with the same name.
+var reservedDLNames = []string{"members", "segments"}
+
 var DLNameValidator validator.Func = func(fl validator.FieldLevel) bool {
 	name, ok := fl.Field().Interface().(string)
 
-	if !ok {
+	if !ok || slices.Contains(reservedDLNames, name) {
 		return false
 	}
 
@@ -46,6 +99,28 @@ var DLNameValidator validator.Func = func(fl validator.FieldLevel) bool {
 	return match
 }
 
+// PartialNameValidator checks the name of the template partials, which
+// can't be the name of the contents included by the layouts.
+var PartialNameValidator validator.Func = func(fl validator.FieldLevel) bool {
+	name, ok := fl.Field().Interface().(string)
+
+	if !ok {
+		return false
+	}
+
+	if name == render.ContentTemplate {
+		return false
+	}
+
+	match, err := r.MatchString("^[A-Za-z0-9_-]+$", name)
+
+	if err != nil {
+		return false
+	}
+
+	return match
+}
+
 var UniqueTemplateVarValidator validator.Func = func(fl validator.FieldLevel) bool {
 	templateVariables, ok := fl.Field().Interface().([]sdto.TemplateVariable)
 
@@ -87,9 +162,79 @@ func validateTemplateVar(tv sdto.TemplateVariable, suppliedVar sdto.TemplateVari
 		return nil
 	}
 
+	validateBounds := func(val string, number float64) error {
+		if tv.Min != nil && number < *tv.Min {
+			return fmt.Errorf("%s is less than %v", val, *tv.Min)
+		}
+
+		if tv.Max != nil && number > *tv.Max {
+			return fmt.Errorf("%s is greater than %v", val, *tv.Max)
+		}
+
+		return nil
+	}
+
 	validateNumber := func(val string) error {
-		if _, err := strconv.ParseFloat(val, 64); err != nil {
-			return fmt.Errorf("%s is not a number", val)
+		number, err := render.ParseNumber(val)
+
+		if err != nil {
+			return err
+		}
+
+		return validateBounds(val, number.Value)
+	}
+
+	validateInteger := func(val string) error {
+		integer, err := render.ParseInteger(val)
+
+		if err != nil {
+			return err
+		}
+
+		return validateBounds(val, float64(integer.Value))
+	}
+
+	validateBoolean := func(val string) error {
+		if _, err := strconv.ParseBool(val); err != nil {
+			return fmt.Errorf("%s is not a boolean", val)
+		}
+
+		return nil
+	}
+
+	validateEnum := func(val string) error {
+		if !slices.Contains(tv.Values, val) {
+			return fmt.Errorf("%s is not one of %s", val, strings.Join(tv.Values, ", "))
+		}
+
+		return nil
+	}
+
+	validateURL := func(val string) error {
+		if err := valueValidator.Var(val, "http_url"); err != nil {
+			return fmt.Errorf("%s is not a valid http url", val)
+		}
+
+		return nil
+	}
+
+	validateEmail := func(val string) error {
+		if err := valueValidator.Var(val, "email"); err != nil {
+			return fmt.Errorf("%s is not a valid email", val)
+		}
+
+		return nil
+	}
+
+	validateCurrency := func(val string) error {
+		currency, err := render.ParseCurrency(val)
+
+		if err != nil {
+			return err
+		}
+
+		if err := valueValidator.Var(currency.Code, "iso4217"); err != nil {
+			return fmt.Errorf("%s is not an ISO 4217 currency code", currency.Code)
 		}
 
 		return nil
@@ -116,6 +261,16 @@ func validateTemplateVar(tv sdto.TemplateVariable, suppliedVar sdto.TemplateVari
 	}
 
 	validateString := func(val string) error {
+		length := utf8.RuneCountInString(val)
+
+		if tv.MinLength != nil && length < *tv.MinLength {
+			return fmt.Errorf("%s is shorter than %d characters", val, *tv.MinLength)
+		}
+
+		if tv.MaxLength != nil && length > *tv.MaxLength {
+			return fmt.Errorf("%s is longer than %d characters", val, *tv.MaxLength)
+		}
+
 		return nil
 	}
 
@@ -138,6 +293,12 @@ func validateTemplateVar(tv sdto.TemplateVariable, suppliedVar sdto.TemplateVari
 		string(dto.Date):     validateDate,
 		string(dto.DateTime): validateDateTime,
 		string(dto.String):   validateString,
+		string(dto.Boolean):  validateBoolean,
+		string(dto.Integer):  validateInteger,
+		string(dto.Enum):     validateEnum,
+		string(dto.URL):      validateURL,
+		string(dto.Email):    validateEmail,
+		string(dto.Currency): validateCurrency,
 	}
 
 	v := suppliedVar.Value
@@ -150,7 +311,75 @@ func validateTemplateVar(tv sdto.TemplateVariable, suppliedVar sdto.TemplateVari
 	return validate(v, validator)
 }
 
-func ValidateTemplateVars(templateVars []sdto.TemplateVariable, suppliedVars []sdto.TemplateVariableContents) error {
+// TemplateVariableStructValidator checks that the constraints of the variable
+// are consistent and apply to its type.
+func TemplateVariableStructValidator(sl validator.StructLevel) {
+
+	tv, ok := sl.Current().Interface().(sdto.TemplateVariable)
+
+	if !ok {
+		return
+	}
+
+	varType := dto.TemplateVariableType(tv.Type)
+	isNumeric := varType == dto.Number || varType == dto.Integer
+
+	if !isNumeric && tv.Min != nil {
+		sl.ReportError(tv.Min, "min", "Min", "numeric_type", tv.Type)
+	}
+
+	if !isNumeric && tv.Max != nil {
+		sl.ReportError(tv.Max, "max", "Max", "numeric_type", tv.Type)
+	}
+
+	if tv.Min != nil && tv.Max != nil && *tv.Min > *tv.Max {
+		sl.ReportError(tv.Max, "max", "Max", "gtefield", "Min")
+	}
+
+	if varType != dto.String && tv.MinLength != nil {
+		sl.ReportError(tv.MinLength, "minLength", "MinLength", "string_type", tv.Type)
+	}
+
+	if varType != dto.String && tv.MaxLength != nil {
+		sl.ReportError(tv.MaxLength, "maxLength", "MaxLength", "string_type", tv.Type)
+	}
+
+	if tv.MinLength != nil && tv.MaxLength != nil && *tv.MinLength > *tv.MaxLength {
+		sl.ReportError(tv.MaxLength, "maxLength", "MaxLength", "gtefield", "MinLength")
+	}
+
+	if varType == dto.Enum && len(tv.Values) == 0 {
+		sl.ReportError(tv.Values, "values", "Values", "required_if", "Type ENUM")
+	}
+
+	if varType != dto.Enum && len(tv.Values) != 0 {
+		sl.ReportError(tv.Values, "values", "Values", "excluded_unless", "Type ENUM")
+	}
+}
+
+// SegmentRuleStructValidator checks that the rules comparing the values
+// of the attributes have values, and the rules checking if the
+// attributes exist don't.
+func SegmentRuleStructValidator(sl validator.StructLevel) {
+
+	rule, ok := sl.Current().Interface().(sdto.SegmentRule)
+
+	if !ok {
+		return
+	}
+
+	compares := rule.Operator == sdto.SegmentOperatorIn || rule.Operator == sdto.SegmentOperatorNotIn
+
+	if compares && len(rule.Values) == 0 {
+		sl.ReportError(rule.Values, "values", "Values", "required_if", "Operator IN NOT_IN")
+	}
+
+	if !compares && len(rule.Values) != 0 {
+		sl.ReportError(rule.Values, "values", "Values", "excluded_unless", "Operator IN NOT_IN")
+	}
+}
+
+func validateTemplateVarSet(templateVars []sdto.TemplateVariable, suppliedVars []sdto.TemplateVariableContents) error {
 
 	templateVarsMap := make(map[string]sdto.TemplateVariable, len(templateVars))
 	requiredTemplateVars := []string{}
@@ -188,6 +417,43 @@ func ValidateTemplateVars(templateVars []sdto.TemplateVariable, suppliedVars []s
 	return errors.Join(errorArr...)
 }
 
+// ValidateTemplateVars validates the shared variables, which are used
+// by the recipients without their own values, and the variables of
+// every recipient with its own values.
+func ValidateTemplateVars(templateVars []sdto.TemplateVariable, contents sdto.TemplateContents) error {
+
+	errorArr := []error{validateTemplateVarSet(templateVars, contents.Variables)}
+
+	recipients := make([]string, 0, len(contents.RecipientVariables))
+
+	for recipient := range contents.RecipientVariables {
+		recipients = append(recipients, recipient)
+	}
+
+	// Sorted so the errors are always reported in the same order
+	slices.Sort(recipients)
+
+	for _, recipient := range recipients {
+		names := map[string]struct{}{}
+
+		for _, v := range contents.RecipientVariables[recipient] {
+			if _, ok := names[v.Name]; ok {
+				errorArr = append(errorArr, fmt.Errorf("recipient %s - %s is supplied more than once", recipient, v.Name))
+			}
+
+			names[v.Name] = struct{}{}
+		}
+
+		variables := contents.GetRecipientVariables(recipient)
+
+		if err := validateTemplateVarSet(templateVars, variables); err != nil {
+			errorArr = append(errorArr, fmt.Errorf("recipient %s - %w", recipient, err))
+		}
+	}
+
+	return errors.Join(errorArr...)
+}
+
 var TemplateNameValidator validator.Func = func(fl validator.FieldLevel) bool {
 	str, ok := fl.Field().Interface().(string)
 
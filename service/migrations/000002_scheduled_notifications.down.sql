@@ -0,0 +1,38 @@
+BEGIN;
+
+DROP INDEX IF EXISTS notifications_send_at_idx;
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS send_at;
+
+-- Postgres can't drop a value from an enum, so the type is recreated
+-- without it after the rows using it are moved to another status.
+UPDATE notifications SET "status" = 'CANCELED' WHERE "status" = 'SCHEDULED';
+DELETE FROM notification_status_log WHERE "status" = 'SCHEDULED';
+
+ALTER TYPE notification_status RENAME TO notification_status_old;
+
+CREATE TYPE notification_status AS ENUM (
+    'CREATED',
+    'QUEUED',
+    'SENDING',
+    'SENT',
+    'FAILED',
+    'CANCELED'
+);
+
+ALTER TABLE notifications
+ALTER COLUMN "status" TYPE notification_status
+USING "status"::TEXT::notification_status;
+
+ALTER TABLE notification_status_log
+ALTER COLUMN "status" TYPE notification_status
+USING "status"::TEXT::notification_status;
+
+ALTER TABLE recipient_notification_status_log
+ALTER COLUMN "status" TYPE notification_status
+USING "status"::TEXT::notification_status;
+
+DROP TYPE notification_status_old;
+
+COMMIT;
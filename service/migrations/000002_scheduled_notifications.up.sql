@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TYPE notification_status ADD VALUE IF NOT EXISTS 'SCHEDULED';
+
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS send_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS notifications_send_at_idx
+ON notifications(send_at)
+WHERE send_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS notification_schedules_next_run_at_idx;
+
+DROP TABLE IF EXISTS notification_schedules;
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notification_schedules (
+    id uuid PRIMARY KEY,
+    "name" VARCHAR NOT NULL,
+    cron_expression VARCHAR NOT NULL,
+    timezone VARCHAR NOT NULL DEFAULT 'UTC',
+    notification JSONB NOT NULL,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    created_by VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() at time zone 'utc'),
+    updated_by VARCHAR,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notification_schedules_next_run_at_idx
+ON notification_schedules(next_run_at)
+WHERE NOT paused;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS notification_outbox_available_at_idx;
+
+DROP TABLE IF EXISTS notification_outbox;
+
+COMMIT;
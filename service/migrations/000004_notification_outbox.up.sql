@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    notification_id uuid PRIMARY KEY REFERENCES notifications(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    available_at TIMESTAMPTZ NOT NULL,
+    last_error VARCHAR,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() at time zone 'utc')
+);
+
+CREATE INDEX IF NOT EXISTS notification_outbox_available_at_idx
+ON notification_outbox(available_at);
+
+COMMIT;
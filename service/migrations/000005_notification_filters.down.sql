@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP INDEX IF EXISTS notifications_distribution_list_idx;
+
+DROP INDEX IF EXISTS notifications_template_id_idx;
+
+DROP INDEX IF EXISTS notifications_created_by_idx;
+
+DROP INDEX IF EXISTS notifications_status_idx;
+
+DROP INDEX IF EXISTS notifications_topic_idx;
+
+DROP INDEX IF EXISTS notifications_created_at_idx;
+
+COMMIT;
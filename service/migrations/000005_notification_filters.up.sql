@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS notifications_created_at_idx
+ON notifications(created_at);
+
+CREATE INDEX IF NOT EXISTS notifications_topic_idx
+ON notifications(topic, id);
+
+CREATE INDEX IF NOT EXISTS notifications_status_idx
+ON notifications("status", id);
+
+CREATE INDEX IF NOT EXISTS notifications_created_by_idx
+ON notifications(created_by, id);
+
+CREATE INDEX IF NOT EXISTS notifications_template_id_idx
+ON notifications(template_id, id)
+WHERE template_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS notifications_distribution_list_idx
+ON notifications(distribution_list, id)
+WHERE distribution_list IS NOT NULL;
+
+COMMIT;
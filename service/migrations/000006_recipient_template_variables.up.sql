@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notification_recipient_template_variable_contents (
+    notification_id uuid NOT NULL,
+    user_id VARCHAR NOT NULL,
+    "name" VARCHAR NOT NULL,
+    "value" VARCHAR NOT NULL,
+    CONSTRAINT recipient_template_variable_pk
+        PRIMARY KEY(notification_id, user_id, "name"),
+    CONSTRAINT notification_id_fk
+        FOREIGN KEY (notification_id)
+        REFERENCES notifications(id) ON DELETE CASCADE
+);
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE notifications
+DROP COLUMN IF EXISTS template_version;
+
+DROP TABLE IF EXISTS notification_template_version_variables;
+
+DROP TABLE IF EXISTS notification_template_versions;
+
+ALTER TABLE notification_templates
+DROP COLUMN IF EXISTS "version";
+
+COMMIT;
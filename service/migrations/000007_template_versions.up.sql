@@ -0,0 +1,92 @@
+BEGIN;
+
+ALTER TABLE notification_templates
+ADD COLUMN IF NOT EXISTS "version" INT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS notification_template_versions (
+    template_id uuid NOT NULL,
+    "version" INT NOT NULL,
+    "name" VARCHAR NOT NULL,
+    is_html BOOLEAN NOT NULL DEFAULT FALSE,
+    title_template VARCHAR NOT NULL,
+    contents_template VARCHAR NOT NULL,
+    "description" VARCHAR NOT NULL,
+    created_by VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() at time zone 'utc'),
+    CONSTRAINT template_version_pk
+        PRIMARY KEY(template_id, "version"),
+    CONSTRAINT template_id_fk
+        FOREIGN KEY (template_id)
+        REFERENCES notification_templates(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS notification_template_version_variables (
+    template_id uuid NOT NULL,
+    "version" INT NOT NULL,
+    "name" VARCHAR NOT NULL,
+    "type" template_variable_type NOT NULL,
+    "required" BOOLEAN NOT NULL,
+    "validation" VARCHAR,
+    CONSTRAINT template_version_variable_pk
+        PRIMARY KEY(template_id, "version", "name"),
+    CONSTRAINT template_version_fk
+        FOREIGN KEY (template_id, "version")
+        REFERENCES notification_template_versions(template_id, "version")
+        ON DELETE CASCADE
+);
+
+-- The existing templates become their first version
+INSERT INTO notification_template_versions (
+    template_id,
+    "version",
+    "name",
+    is_html,
+    title_template,
+    contents_template,
+    "description",
+    created_by,
+    created_at
+)
+SELECT
+    id,
+    "version",
+    "name",
+    is_html,
+    title_template,
+    contents_template,
+    "description",
+    created_by,
+    created_at
+FROM
+    notification_templates
+ON CONFLICT DO NOTHING;
+
+INSERT INTO notification_template_version_variables (
+    template_id,
+    "version",
+    "name",
+    "type",
+    "required",
+    "validation"
+)
+SELECT
+    v.template_id,
+    t."version",
+    v."name",
+    v."type",
+    v."required",
+    v."validation"
+FROM
+    notification_template_variables v
+INNER JOIN
+    notification_templates t ON t.id = v.template_id
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE notifications
+ADD COLUMN IF NOT EXISTS template_version INT;
+
+UPDATE notifications
+SET template_version = 1
+WHERE template_id IS NOT NULL AND template_version IS NULL;
+
+COMMIT;
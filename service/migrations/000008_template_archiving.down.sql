@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS template_id_fk,
+ADD CONSTRAINT template_id_fk
+    FOREIGN KEY (template_id)
+    REFERENCES notification_templates(id) ON DELETE CASCADE;
+
+ALTER TABLE notification_templates
+DROP COLUMN IF EXISTS archived_at,
+DROP COLUMN IF EXISTS archived_by;
+
+COMMIT;
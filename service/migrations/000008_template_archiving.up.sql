@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE notification_templates
+ADD COLUMN IF NOT EXISTS archived_by VARCHAR,
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- Templates are archived instead of deleted, so their notifications
+-- must never be removed along with them
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS template_id_fk,
+ADD CONSTRAINT template_id_fk
+    FOREIGN KEY (template_id)
+    REFERENCES notification_templates(id) ON DELETE RESTRICT;
+
+COMMIT;
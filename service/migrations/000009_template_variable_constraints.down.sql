@@ -0,0 +1,38 @@
+BEGIN;
+
+ALTER TABLE notification_template_version_variables
+DROP COLUMN IF EXISTS allowed_values,
+DROP COLUMN IF EXISTS max_length,
+DROP COLUMN IF EXISTS min_length,
+DROP COLUMN IF EXISTS max_value,
+DROP COLUMN IF EXISTS min_value;
+
+ALTER TABLE notification_template_variables
+DROP COLUMN IF EXISTS allowed_values,
+DROP COLUMN IF EXISTS max_length,
+DROP COLUMN IF EXISTS min_length,
+DROP COLUMN IF EXISTS max_value,
+DROP COLUMN IF EXISTS min_value;
+
+-- Enum values can't be dropped, so the type is recreated. It fails if
+-- a variable still uses one of the removed types.
+ALTER TYPE template_variable_type RENAME TO template_variable_type_old;
+
+CREATE TYPE template_variable_type AS ENUM (
+    'STRING',
+    'NUMBER',
+    'DATE',
+    'DATETIME'
+);
+
+ALTER TABLE notification_template_variables
+ALTER COLUMN "type" TYPE template_variable_type
+USING "type"::text::template_variable_type;
+
+ALTER TABLE notification_template_version_variables
+ALTER COLUMN "type" TYPE template_variable_type
+USING "type"::text::template_variable_type;
+
+DROP TYPE template_variable_type_old;
+
+COMMIT;
@@ -0,0 +1,24 @@
+ALTER TYPE template_variable_type ADD VALUE IF NOT EXISTS 'BOOLEAN';
+ALTER TYPE template_variable_type ADD VALUE IF NOT EXISTS 'INTEGER';
+ALTER TYPE template_variable_type ADD VALUE IF NOT EXISTS 'ENUM';
+ALTER TYPE template_variable_type ADD VALUE IF NOT EXISTS 'URL';
+ALTER TYPE template_variable_type ADD VALUE IF NOT EXISTS 'EMAIL';
+ALTER TYPE template_variable_type ADD VALUE IF NOT EXISTS 'CURRENCY';
+
+BEGIN;
+
+ALTER TABLE notification_template_variables
+ADD COLUMN IF NOT EXISTS min_value DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS max_value DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS min_length INT,
+ADD COLUMN IF NOT EXISTS max_length INT,
+ADD COLUMN IF NOT EXISTS allowed_values VARCHAR[];
+
+ALTER TABLE notification_template_version_variables
+ADD COLUMN IF NOT EXISTS min_value DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS max_value DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS min_length INT,
+ADD COLUMN IF NOT EXISTS max_length INT,
+ADD COLUMN IF NOT EXISTS allowed_values VARCHAR[];
+
+COMMIT;
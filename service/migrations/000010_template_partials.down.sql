@@ -0,0 +1,17 @@
+BEGIN;
+
+ALTER TABLE notification_template_versions
+DROP COLUMN IF EXISTS layout,
+DROP COLUMN IF EXISTS partials;
+
+ALTER TABLE notification_templates
+DROP COLUMN IF EXISTS layout,
+DROP COLUMN IF EXISTS partials;
+
+DROP TABLE IF EXISTS notification_template_partial_versions;
+
+DROP TABLE IF EXISTS notification_template_partials;
+
+DROP TYPE IF EXISTS template_partial_kind;
+
+COMMIT;
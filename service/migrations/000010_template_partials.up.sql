@@ -0,0 +1,44 @@
+BEGIN;
+
+CREATE TYPE template_partial_kind AS ENUM (
+    'LAYOUT',
+    'PARTIAL'
+);
+
+CREATE TABLE IF NOT EXISTS notification_template_partials (
+    "name" VARCHAR PRIMARY KEY,
+    kind template_partial_kind NOT NULL,
+    "version" INT NOT NULL DEFAULT 1,
+    "description" VARCHAR NOT NULL,
+    contents VARCHAR NOT NULL,
+    created_by VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() at time zone 'utc'),
+    updated_by VARCHAR,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS notification_template_partial_versions (
+    partial_name VARCHAR NOT NULL,
+    "version" INT NOT NULL,
+    "description" VARCHAR NOT NULL,
+    contents VARCHAR NOT NULL,
+    created_by VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() at time zone 'utc'),
+    CONSTRAINT template_partial_version_pk
+        PRIMARY KEY(partial_name, "version"),
+    CONSTRAINT partial_name_fk
+        FOREIGN KEY (partial_name)
+        REFERENCES notification_template_partials("name") ON DELETE CASCADE
+);
+
+-- The partials used by the templates are stored with them, so the
+-- partials in use can't be deleted
+ALTER TABLE notification_templates
+ADD COLUMN IF NOT EXISTS layout VARCHAR,
+ADD COLUMN IF NOT EXISTS partials VARCHAR[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE notification_template_versions
+ADD COLUMN IF NOT EXISTS layout VARCHAR,
+ADD COLUMN IF NOT EXISTS partials VARCHAR[] NOT NULL DEFAULT '{}';
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE user_config
+DROP COLUMN IF EXISTS locale;
+
+DROP TABLE IF EXISTS notification_template_version_localizations;
+
+ALTER TABLE notification_template_versions
+DROP COLUMN IF EXISTS default_locale;
+
+ALTER TABLE notification_templates
+DROP COLUMN IF EXISTS default_locale;
+
+COMMIT;
@@ -0,0 +1,28 @@
+BEGIN;
+
+ALTER TABLE notification_templates
+ADD COLUMN IF NOT EXISTS default_locale VARCHAR;
+
+ALTER TABLE notification_template_versions
+ADD COLUMN IF NOT EXISTS default_locale VARCHAR;
+
+-- The localizations are immutable like the versions they belong to, the
+-- current ones are the ones of the latest version
+CREATE TABLE IF NOT EXISTS notification_template_version_localizations (
+    template_id uuid NOT NULL,
+    "version" INT NOT NULL,
+    locale VARCHAR NOT NULL,
+    title_template VARCHAR NOT NULL,
+    contents_template VARCHAR NOT NULL,
+    CONSTRAINT template_version_localization_pk
+        PRIMARY KEY(template_id, "version", locale),
+    CONSTRAINT template_version_fk
+        FOREIGN KEY (template_id, "version")
+        REFERENCES notification_template_versions(template_id, "version")
+        ON DELETE CASCADE
+);
+
+ALTER TABLE user_config
+ADD COLUMN IF NOT EXISTS locale VARCHAR;
+
+COMMIT;
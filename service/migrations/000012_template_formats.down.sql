@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE user_notifications
+DROP COLUMN IF EXISTS html_contents;
+
+ALTER TABLE notification_template_versions
+DROP COLUMN IF EXISTS "format";
+
+ALTER TABLE notification_templates
+DROP COLUMN IF EXISTS "format";
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+-- The format replaces is_html, which is kept for the clients that
+-- don't know the formats
+ALTER TABLE notification_templates
+ADD COLUMN IF NOT EXISTS "format" VARCHAR;
+
+UPDATE notification_templates
+SET "format" = CASE WHEN is_html THEN 'HTML' ELSE 'TEXT' END
+WHERE "format" IS NULL;
+
+ALTER TABLE notification_templates
+ALTER COLUMN "format" SET NOT NULL;
+
+ALTER TABLE notification_template_versions
+ADD COLUMN IF NOT EXISTS "format" VARCHAR;
+
+UPDATE notification_template_versions
+SET "format" = CASE WHEN is_html THEN 'HTML' ELSE 'TEXT' END
+WHERE "format" IS NULL;
+
+ALTER TABLE notification_template_versions
+ALTER COLUMN "format" SET NOT NULL;
+
+-- HTML version of the contents, for the clients that can display it
+ALTER TABLE user_notifications
+ADD COLUMN IF NOT EXISTS html_contents VARCHAR;
+
+COMMIT;
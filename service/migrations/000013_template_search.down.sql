@@ -0,0 +1,18 @@
+BEGIN;
+
+DROP INDEX IF EXISTS notification_templates_search_idx;
+DROP INDEX IF EXISTS notification_templates_tags_idx;
+DROP INDEX IF EXISTS notification_templates_updated_at_idx;
+DROP INDEX IF EXISTS notification_templates_created_at_idx;
+DROP INDEX IF EXISTS notification_templates_created_by_idx;
+DROP INDEX IF EXISTS notification_templates_category_idx;
+
+ALTER TABLE notification_template_versions
+DROP COLUMN IF EXISTS tags,
+DROP COLUMN IF EXISTS category;
+
+ALTER TABLE notification_templates
+DROP COLUMN IF EXISTS tags,
+DROP COLUMN IF EXISTS category;
+
+COMMIT;
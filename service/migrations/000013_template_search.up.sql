@@ -0,0 +1,35 @@
+BEGIN;
+
+ALTER TABLE notification_templates
+ADD COLUMN IF NOT EXISTS category VARCHAR,
+ADD COLUMN IF NOT EXISTS tags VARCHAR[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE notification_template_versions
+ADD COLUMN IF NOT EXISTS category VARCHAR,
+ADD COLUMN IF NOT EXISTS tags VARCHAR[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS notification_templates_category_idx
+ON notification_templates(category, id)
+WHERE category IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS notification_templates_created_by_idx
+ON notification_templates(created_by, id);
+
+CREATE INDEX IF NOT EXISTS notification_templates_created_at_idx
+ON notification_templates(created_at);
+
+CREATE INDEX IF NOT EXISTS notification_templates_updated_at_idx
+ON notification_templates(updated_at);
+
+-- Used to find the templates that have every one of the filtered tags
+CREATE INDEX IF NOT EXISTS notification_templates_tags_idx
+ON notification_templates USING GIN (tags);
+
+-- The trigram index serves the case insensitive search of any part of
+-- the name and description
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS notification_templates_search_idx
+ON notification_templates USING GIN (("name" || ' ' || "description") gin_trgm_ops);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE distribution_lists
+DROP COLUMN IF EXISTS segment;
+
+COMMIT;
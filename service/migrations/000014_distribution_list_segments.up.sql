@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Rules over the attributes of the users that define the recipients
+-- of the list, which are resolved when the notifications are sent
+ALTER TABLE distribution_lists
+ADD COLUMN IF NOT EXISTS segment JSONB;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS distribution_list_lists;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Lists included by other lists, whose recipients are also recipients
+-- of the lists that include them
+CREATE TABLE IF NOT EXISTS distribution_list_lists (
+    "name" VARCHAR NOT NULL,
+    list VARCHAR NOT NULL,
+    CONSTRAINT distribution_list_lists_pk
+        PRIMARY KEY("name", list),
+    CONSTRAINT distribution_list_lists_name_fk
+        FOREIGN KEY("name")
+        REFERENCES distribution_lists("name")
+        ON DELETE CASCADE,
+    CONSTRAINT distribution_list_lists_list_fk
+        FOREIGN KEY(list)
+        REFERENCES distribution_lists("name")
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS distribution_list_lists_list_idx
+ON distribution_list_lists(list);
+
+COMMIT;
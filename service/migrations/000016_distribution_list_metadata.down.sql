@@ -0,0 +1,36 @@
+BEGIN;
+
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS distribution_list_fk,
+ADD CONSTRAINT distribution_list_fk
+    FOREIGN KEY (distribution_list)
+    REFERENCES distribution_lists("name")
+    ON DELETE SET NULL (distribution_list);
+
+ALTER TABLE distribution_list_lists
+DROP CONSTRAINT IF EXISTS distribution_list_lists_name_fk,
+DROP CONSTRAINT IF EXISTS distribution_list_lists_list_fk,
+ADD CONSTRAINT distribution_list_lists_name_fk
+    FOREIGN KEY("name")
+    REFERENCES distribution_lists("name")
+    ON DELETE CASCADE,
+ADD CONSTRAINT distribution_list_lists_list_fk
+    FOREIGN KEY(list)
+    REFERENCES distribution_lists("name")
+    ON DELETE CASCADE;
+
+ALTER TABLE distribution_list_recipients
+DROP CONSTRAINT IF EXISTS distribution_list_fk,
+ADD CONSTRAINT distribution_list_fk
+    FOREIGN KEY("name")
+    REFERENCES distribution_lists("name")
+    ON DELETE CASCADE;
+
+ALTER TABLE distribution_lists
+DROP COLUMN IF EXISTS updated_at,
+DROP COLUMN IF EXISTS created_at,
+DROP COLUMN IF EXISTS labels,
+DROP COLUMN IF EXISTS "owner",
+DROP COLUMN IF EXISTS "description";
+
+COMMIT;
@@ -0,0 +1,43 @@
+BEGIN;
+
+-- The lists created before the owners were stored don't have one
+ALTER TABLE distribution_lists
+ADD COLUMN IF NOT EXISTS "description" VARCHAR NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS "owner" VARCHAR,
+ADD COLUMN IF NOT EXISTS labels VARCHAR[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() at time zone 'utc'),
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+-- The lists are renamed by updating their names, which the tables that
+-- reference them follow
+ALTER TABLE distribution_list_recipients
+DROP CONSTRAINT IF EXISTS distribution_list_fk,
+ADD CONSTRAINT distribution_list_fk
+    FOREIGN KEY("name")
+    REFERENCES distribution_lists("name")
+    ON DELETE CASCADE
+    ON UPDATE CASCADE;
+
+ALTER TABLE distribution_list_lists
+DROP CONSTRAINT IF EXISTS distribution_list_lists_name_fk,
+DROP CONSTRAINT IF EXISTS distribution_list_lists_list_fk,
+ADD CONSTRAINT distribution_list_lists_name_fk
+    FOREIGN KEY("name")
+    REFERENCES distribution_lists("name")
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+ADD CONSTRAINT distribution_list_lists_list_fk
+    FOREIGN KEY(list)
+    REFERENCES distribution_lists("name")
+    ON DELETE CASCADE
+    ON UPDATE CASCADE;
+
+ALTER TABLE notifications
+DROP CONSTRAINT IF EXISTS distribution_list_fk,
+ADD CONSTRAINT distribution_list_fk
+    FOREIGN KEY (distribution_list)
+    REFERENCES distribution_lists("name")
+    ON DELETE SET NULL (distribution_list)
+    ON UPDATE CASCADE;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS distribution_list_recipients_recipient_idx;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Finds the lists of a recipient
+CREATE INDEX IF NOT EXISTS distribution_list_recipients_recipient_idx
+ON distribution_list_recipients(recipient, "name");
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+DELETE FROM notification_status_log a
+USING notification_status_log b
+WHERE a.notification_id = b.notification_id
+AND a.status_date = b.status_date
+AND a."status" > b."status";
+
+ALTER TABLE notification_status_log
+DROP CONSTRAINT IF EXISTS notification_status_log_pk;
+
+ALTER TABLE notification_status_log
+ADD CONSTRAINT notification_status_log_pk
+PRIMARY KEY(notification_id, status_date);
+
+COMMIT;
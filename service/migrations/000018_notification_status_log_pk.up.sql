@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Two statuses of a notification can be logged at the same time, the
+-- status tells them apart and is the tiebreaker of the pagination
+ALTER TABLE notification_status_log
+DROP CONSTRAINT IF EXISTS notification_status_log_pk;
+
+ALTER TABLE notification_status_log
+ADD CONSTRAINT notification_status_log_pk
+PRIMARY KEY(notification_id, status_date, "status");
+
+COMMIT;
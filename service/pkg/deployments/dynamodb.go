@@ -2,13 +2,17 @@ package deployments
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	r "github.com/notifique/service/internal/registry/dynamodb"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
@@ -59,6 +63,37 @@ func createTable(client dynamodb.Client, tableName string, input dynamodb.Create
 	return nil
 }
 
+// makeNotificationCreatedAtIndex makes an index used to filter the
+// notifications by the hash key and sort them by the creation date.
+func makeNotificationCreatedAtIndex(indexName, hashKey string) types.GlobalSecondaryIndex {
+	return types.GlobalSecondaryIndex{
+		IndexName: aws.String(indexName),
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(hashKey),
+			KeyType:       types.KeyTypeHash,
+		}, {
+			AttributeName: aws.String(r.NotificationCreatedAtIdxSortKey),
+			KeyType:       types.KeyTypeRange,
+		}},
+		Projection: &types.Projection{
+			NonKeyAttributes: []string{
+				"topic",
+				"createdBy",
+				"priority",
+				"status",
+				"contentType",
+				"templateId",
+				"distributionList",
+			},
+			ProjectionType: types.ProjectionTypeInclude,
+		},
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+}
+
 func createNotificationTable(client dynamodb.Client) error {
 
 	tableName := r.NotificationsTable
@@ -67,11 +102,60 @@ func createNotificationTable(client dynamodb.Client) error {
 		AttributeDefinitions: []types.AttributeDefinition{{
 			AttributeName: aws.String(r.NotificationHashKey),
 			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationStatusSendAtIdxHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationStatusSendAtIdxSortKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationListIdxHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationTopicIdxHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationCreatedByIdxHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationTemplateIdxHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationDistributionListIdxHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationCreatedAtIdxSortKey),
+			AttributeType: types.ScalarAttributeTypeS,
 		}},
 		KeySchema: []types.KeySchemaElement{{
 			AttributeName: aws.String(r.NotificationHashKey),
 			KeyType:       types.KeyTypeHash,
 		}},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(r.NotificationStatusSendAtIdx),
+				KeySchema: []types.KeySchemaElement{{
+					AttributeName: aws.String(r.NotificationStatusSendAtIdxHashKey),
+					KeyType:       types.KeyTypeHash,
+				}, {
+					AttributeName: aws.String(r.NotificationStatusSendAtIdxSortKey),
+					KeyType:       types.KeyTypeRange,
+				}},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeKeysOnly,
+				},
+				ProvisionedThroughput: &types.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(10),
+					WriteCapacityUnits: aws.Int64(10),
+				},
+			},
+			makeNotificationCreatedAtIndex(r.NotificationListIdx, r.NotificationListIdxHashKey),
+			makeNotificationCreatedAtIndex(r.NotificationTopicIdx, r.NotificationTopicIdxHashKey),
+			makeNotificationCreatedAtIndex(r.NotificationStatusIdx, r.NotificationStatusIdxHashKey),
+			makeNotificationCreatedAtIndex(r.NotificationCreatedByIdx, r.NotificationCreatedByIdxHashKey),
+			makeNotificationCreatedAtIndex(r.NotificationTemplateIdx, r.NotificationTemplateIdxHashKey),
+			makeNotificationCreatedAtIndex(r.NotificationDistributionListIdx, r.NotificationDistributionListIdxHashKey),
+		},
 		TableName: aws.String(tableName),
 		ProvisionedThroughput: &types.ProvisionedThroughput{
 			ReadCapacityUnits:  aws.Int64(10),
@@ -138,6 +222,7 @@ func createDLRecipientsTable(client dynamodb.Client) error {
 
 	tableName := r.DistListRecipientsTable
 
+	// The recipient lists index finds the lists of a recipient
 	tableInput := dynamodb.CreateTableInput{
 		AttributeDefinitions: []types.AttributeDefinition{{
 			AttributeName: aws.String(r.DistListRecipientHashKey),
@@ -153,6 +238,23 @@ func createDLRecipientsTable(client dynamodb.Client) error {
 			AttributeName: aws.String(r.DistListRecipientSortKey),
 			KeyType:       types.KeyTypeRange,
 		}},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(r.DistListRecipientListIdx),
+			KeySchema: []types.KeySchemaElement{{
+				AttributeName: aws.String(r.DistListRecipientSortKey),
+				KeyType:       types.KeyTypeHash,
+			}, {
+				AttributeName: aws.String(r.DistListRecipientHashKey),
+				KeyType:       types.KeyTypeRange,
+			}},
+			Projection: &types.Projection{
+				ProjectionType: types.ProjectionTypeKeysOnly,
+			},
+			ProvisionedThroughput: &types.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(10),
+				WriteCapacityUnits: aws.Int64(10),
+			},
+		}},
 		TableName: aws.String(tableName),
 		ProvisionedThroughput: &types.ProvisionedThroughput{
 			ReadCapacityUnits:  aws.Int64(10),
@@ -186,6 +288,53 @@ func createDLSummaryTable(client dynamodb.Client) error {
 	return createTable(client, tableName, tableInput)
 }
 
+func createDLListsTable(client dynamodb.Client) error {
+
+	tableName := r.DistListListsTable
+
+	// The parent lists index finds the lists that include a list
+	tableInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String(r.DistListListsHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.DistListListsSortKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}},
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(r.DistListListsHashKey),
+			KeyType:       types.KeyTypeHash,
+		}, {
+			AttributeName: aws.String(r.DistListListsSortKey),
+			KeyType:       types.KeyTypeRange,
+		}},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(r.DistListListsParentIdx),
+			KeySchema: []types.KeySchemaElement{{
+				AttributeName: aws.String(r.DistListListsSortKey),
+				KeyType:       types.KeyTypeHash,
+			}, {
+				AttributeName: aws.String(r.DistListListsHashKey),
+				KeyType:       types.KeyTypeRange,
+			}},
+			Projection: &types.Projection{
+				ProjectionType: types.ProjectionTypeKeysOnly,
+			},
+			ProvisionedThroughput: &types.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(10),
+				WriteCapacityUnits: aws.Int64(10),
+			},
+		}},
+		TableName: aws.String(tableName),
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+
+	return createTable(client, tableName, tableInput)
+}
+
 func createNotificationStatusLogTable(client dynamodb.Client) error {
 	tableName := r.NotificationStatusLogTable
 
@@ -214,6 +363,49 @@ func createNotificationStatusLogTable(client dynamodb.Client) error {
 	return createTable(client, tableName, tableInput)
 }
 
+// makeNotificationTemplateIndex makes an index of the templates sorted
+// by name, which projects the attributes listed and filtered by the
+// templates queries.
+func makeNotificationTemplateIndex(indexName, hashKey string) types.GlobalSecondaryIndex {
+
+	attributes := []string{
+		"description",
+		"isHtml",
+		"format",
+		r.NotificationsTemplateCategoryGSIHashKey,
+		"tags",
+		"searchText",
+		r.NotificationsTemplateCreatedByGSIHashKey,
+		"createdAt",
+		"updatedAt",
+		r.NotificationsTemplateNameGSIHashKey,
+	}
+
+	// The keys of the index are always projected
+	attributes = slices.DeleteFunc(attributes, func(a string) bool {
+		return a == hashKey
+	})
+
+	return types.GlobalSecondaryIndex{
+		IndexName: aws.String(indexName),
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(hashKey),
+			KeyType:       types.KeyTypeHash,
+		}, {
+			AttributeName: aws.String(r.NotificationsTemplateNameGSISortKey),
+			KeyType:       types.KeyTypeRange,
+		}},
+		Projection: &types.Projection{
+			NonKeyAttributes: attributes,
+			ProjectionType:   types.ProjectionTypeInclude,
+		},
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+}
+
 func createNotificationTemplateTable(client dynamodb.Client) error {
 
 	tableName := r.NotificationsTemplateTable
@@ -228,22 +420,240 @@ func createNotificationTemplateTable(client dynamodb.Client) error {
 		}, {
 			AttributeName: aws.String(r.NotificationsTemplateNameGSISortKey),
 			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationsTemplateCategoryGSIHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationsTemplateCreatedByGSIHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
 		}},
 		KeySchema: []types.KeySchemaElement{{
 			AttributeName: aws.String(r.NotificationTemplateHashKey),
 			KeyType:       types.KeyTypeHash,
 		}},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			makeNotificationTemplateIndex(r.NotificationsTemplateNameGSI, r.NotificationsTemplateNameGSIHashKey),
+			makeNotificationTemplateIndex(r.NotificationsTemplateCategoryGSI, r.NotificationsTemplateCategoryGSIHashKey),
+			makeNotificationTemplateIndex(r.NotificationsTemplateCreatedByGSI, r.NotificationsTemplateCreatedByGSIHashKey),
+		},
+		TableName: aws.String(tableName),
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+
+	return createTable(client, tableName, tableInput)
+}
+
+func createRecipientNotificationStatusLogTable(client dynamodb.Client) error {
+	tableName := r.RecipientNotificationStatusLogTable
+
+	tableInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String(r.RecipientNotificationStatusLogHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.RecipientNotificationStatusLogSortKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}},
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(r.RecipientNotificationStatusLogHashKey),
+			KeyType:       types.KeyTypeHash,
+		}, {
+			AttributeName: aws.String(r.RecipientNotificationStatusLogSortKey),
+			KeyType:       types.KeyTypeRange,
+		}},
+		TableName: aws.String(tableName),
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+
+	return createTable(client, tableName, tableInput)
+}
+
+func createRecipientNotificationLatestStatusLogTable(client dynamodb.Client) error {
+	tableName := r.RecipientNotificationLatestStatusLogTable
+
+	tableInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String(r.RecipientNotificationStatusLogHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.RecipientNotificationStatusLogSortKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}},
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(r.RecipientNotificationStatusLogHashKey),
+			KeyType:       types.KeyTypeHash,
+		}, {
+			AttributeName: aws.String(r.RecipientNotificationStatusLogSortKey),
+			KeyType:       types.KeyTypeRange,
+		}},
+		TableName: aws.String(tableName),
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+
+	return createTable(client, tableName, tableInput)
+}
+
+// makeReferenceIndex makes an index used to find the items referencing
+// a template or a distribution list, e.g., the schedules sending
+// notifications with a template.
+func makeReferenceIndex(indexName, hashKey string, projection types.Projection) types.GlobalSecondaryIndex {
+	return types.GlobalSecondaryIndex{
+		IndexName: aws.String(indexName),
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(hashKey),
+			KeyType:       types.KeyTypeHash,
+		}},
+		Projection: &projection,
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+}
+
+func makeScheduleTemplateIndex() types.GlobalSecondaryIndex {
+	return makeReferenceIndex(r.NotificationScheduleTemplateIdx, r.NotificationScheduleTemplateIdxKey, types.Projection{
+		ProjectionType: types.ProjectionTypeKeysOnly,
+	})
+}
+
+// The notifications of the schedules and outbox entries are projected
+// so the list can be renamed in them.
+func makeScheduleDistListIndex() types.GlobalSecondaryIndex {
+	return makeReferenceIndex(r.NotificationScheduleDistListIdx, r.NotificationScheduleDistListIdxKey, types.Projection{
+		ProjectionType:   types.ProjectionTypeInclude,
+		NonKeyAttributes: []string{"notification"},
+	})
+}
+
+func makeOutboxDistListIndex() types.GlobalSecondaryIndex {
+	return makeReferenceIndex(r.NotificationOutboxDistListIdx, r.NotificationOutboxDistListIdxKey, types.Projection{
+		ProjectionType:   types.ProjectionTypeInclude,
+		NonKeyAttributes: []string{"payload"},
+	})
+}
+
+func createNotificationScheduleTable(client dynamodb.Client) error {
+
+	tableName := r.NotificationSchedulesTable
+
+	tableInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String(r.NotificationScheduleHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationScheduleNextRunIdxKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationScheduleNextRunIdxSK),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationScheduleTemplateIdxKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationScheduleDistListIdxKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}},
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(r.NotificationScheduleHashKey),
+			KeyType:       types.KeyTypeHash,
+		}},
 		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
-			IndexName: aws.String(r.NotificationsTemplateNameGSI),
+			IndexName: aws.String(r.NotificationScheduleNextRunIdx),
 			KeySchema: []types.KeySchemaElement{{
-				AttributeName: aws.String(r.NotificationsTemplateNameGSIHashKey),
+				AttributeName: aws.String(r.NotificationScheduleNextRunIdxKey),
 				KeyType:       types.KeyTypeHash,
 			}, {
-				AttributeName: aws.String(r.NotificationsTemplateNameGSISortKey),
+				AttributeName: aws.String(r.NotificationScheduleNextRunIdxSK),
+				KeyType:       types.KeyTypeRange,
+			}},
+			Projection: &types.Projection{
+				ProjectionType: types.ProjectionTypeAll,
+			},
+			ProvisionedThroughput: &types.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(10),
+				WriteCapacityUnits: aws.Int64(10),
+			},
+		}, makeScheduleTemplateIndex(), makeScheduleDistListIndex()},
+		TableName: aws.String(tableName),
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+
+	return createTable(client, tableName, tableInput)
+}
+
+func createNotificationTemplateVersionsTable(client dynamodb.Client) error {
+
+	tableName := r.NotificationTemplateVersionsTable
+
+	tableInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String(r.NotificationTemplateVersionsHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationTemplateVersionsSortKey),
+			AttributeType: types.ScalarAttributeTypeN,
+		}},
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(r.NotificationTemplateVersionsHashKey),
+			KeyType:       types.KeyTypeHash,
+		}, {
+			AttributeName: aws.String(r.NotificationTemplateVersionsSortKey),
+			KeyType:       types.KeyTypeRange,
+		}},
+		TableName: aws.String(tableName),
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}
+
+	return createTable(client, tableName, tableInput)
+}
+
+func createTemplatePartialsTable(client dynamodb.Client) error {
+
+	tableName := r.TemplatePartialsTable
+
+	// The name is both the hash key of the table and the sort key of the
+	// name index
+	tableInput := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String(r.TemplatePartialHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.TemplatePartialsNameGSIHashKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}},
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(r.TemplatePartialHashKey),
+			KeyType:       types.KeyTypeHash,
+		}},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(r.TemplatePartialsNameGSI),
+			KeySchema: []types.KeySchemaElement{{
+				AttributeName: aws.String(r.TemplatePartialsNameGSIHashKey),
+				KeyType:       types.KeyTypeHash,
+			}, {
+				AttributeName: aws.String(r.TemplatePartialsNameGSISortKey),
 				KeyType:       types.KeyTypeRange,
 			}},
 			Projection: &types.Projection{
 				NonKeyAttributes: []string{
+					"kind",
+					"version",
 					"description",
 				},
 				ProjectionType: types.ProjectionTypeInclude,
@@ -263,22 +673,23 @@ func createNotificationTemplateTable(client dynamodb.Client) error {
 	return createTable(client, tableName, tableInput)
 }
 
-func createRecipientNotificationStatusLogTable(client dynamodb.Client) error {
-	tableName := r.RecipientNotificationStatusLogTable
+func createTemplatePartialVersionsTable(client dynamodb.Client) error {
+
+	tableName := r.TemplatePartialVersionsTable
 
 	tableInput := dynamodb.CreateTableInput{
 		AttributeDefinitions: []types.AttributeDefinition{{
-			AttributeName: aws.String(r.RecipientNotificationStatusLogHashKey),
+			AttributeName: aws.String(r.TemplatePartialVersionsHashKey),
 			AttributeType: types.ScalarAttributeTypeS,
 		}, {
-			AttributeName: aws.String(r.RecipientNotificationStatusLogSortKey),
-			AttributeType: types.ScalarAttributeTypeS,
+			AttributeName: aws.String(r.TemplatePartialVersionsSortKey),
+			AttributeType: types.ScalarAttributeTypeN,
 		}},
 		KeySchema: []types.KeySchemaElement{{
-			AttributeName: aws.String(r.RecipientNotificationStatusLogHashKey),
+			AttributeName: aws.String(r.TemplatePartialVersionsHashKey),
 			KeyType:       types.KeyTypeHash,
 		}, {
-			AttributeName: aws.String(r.RecipientNotificationStatusLogSortKey),
+			AttributeName: aws.String(r.TemplatePartialVersionsSortKey),
 			KeyType:       types.KeyTypeRange,
 		}},
 		TableName: aws.String(tableName),
@@ -291,24 +702,45 @@ func createRecipientNotificationStatusLogTable(client dynamodb.Client) error {
 	return createTable(client, tableName, tableInput)
 }
 
-func createRecipientNotificationLatestStatusLogTable(client dynamodb.Client) error {
-	tableName := r.RecipientNotificationLatestStatusLogTable
+func createNotificationOutboxTable(client dynamodb.Client) error {
+
+	tableName := r.NotificationOutboxTable
 
 	tableInput := dynamodb.CreateTableInput{
 		AttributeDefinitions: []types.AttributeDefinition{{
-			AttributeName: aws.String(r.RecipientNotificationStatusLogHashKey),
+			AttributeName: aws.String(r.NotificationOutboxHashKey),
 			AttributeType: types.ScalarAttributeTypeS,
 		}, {
-			AttributeName: aws.String(r.RecipientNotificationStatusLogSortKey),
+			AttributeName: aws.String(r.NotificationOutboxAvailableAtIdxKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationOutboxAvailableAtIdxSK),
+			AttributeType: types.ScalarAttributeTypeS,
+		}, {
+			AttributeName: aws.String(r.NotificationOutboxDistListIdxKey),
 			AttributeType: types.ScalarAttributeTypeS,
 		}},
 		KeySchema: []types.KeySchemaElement{{
-			AttributeName: aws.String(r.RecipientNotificationStatusLogHashKey),
+			AttributeName: aws.String(r.NotificationOutboxHashKey),
 			KeyType:       types.KeyTypeHash,
-		}, {
-			AttributeName: aws.String(r.RecipientNotificationStatusLogSortKey),
-			KeyType:       types.KeyTypeRange,
 		}},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(r.NotificationOutboxAvailableAtIdx),
+			KeySchema: []types.KeySchemaElement{{
+				AttributeName: aws.String(r.NotificationOutboxAvailableAtIdxKey),
+				KeyType:       types.KeyTypeHash,
+			}, {
+				AttributeName: aws.String(r.NotificationOutboxAvailableAtIdxSK),
+				KeyType:       types.KeyTypeRange,
+			}},
+			Projection: &types.Projection{
+				ProjectionType: types.ProjectionTypeAll,
+			},
+			ProvisionedThroughput: &types.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(10),
+				WriteCapacityUnits: aws.Int64(10),
+			},
+		}, makeOutboxDistListIndex()},
 		TableName: aws.String(tableName),
 		ProvisionedThroughput: &types.ProvisionedThroughput{
 			ReadCapacityUnits:  aws.Int64(10),
@@ -331,10 +763,16 @@ func CreateTables(client *dynamodb.Client) error {
 		createUserNotificationTable,
 		createDLRecipientsTable,
 		createDLSummaryTable,
+		createDLListsTable,
 		createNotificationStatusLogTable,
 		createNotificationTemplateTable,
+		createNotificationTemplateVersionsTable,
+		createTemplatePartialsTable,
+		createTemplatePartialVersionsTable,
 		createRecipientNotificationStatusLogTable,
 		createRecipientNotificationLatestStatusLogTable,
+		createNotificationScheduleTable,
+		createNotificationOutboxTable,
 	}
 
 	for _, fn := range tables {
@@ -345,3 +783,345 @@ func CreateTables(client *dynamodb.Client) error {
 
 	return nil
 }
+
+// BackfillNotificationListKeys assigns a list shard to the notifications
+// stored without one, i.e., before the notifications could be listed,
+// or with the unsharded list key. Otherwise they wouldn't be listed.
+func BackfillNotificationListKeys(client *dynamodb.Client) error {
+
+	if client == nil {
+		return fmt.Errorf("client is nil")
+	}
+
+	listKey := expression.Name(r.NotificationListIdxHashKey)
+	filter := expression.AttributeNotExists(listKey).
+		Or(expression.Not(listKey.BeginsWith(r.NotificationListKeyPrefix + "#")))
+
+	expr, err := expression.
+		NewBuilder().
+		WithFilter(filter).
+		WithProjection(expression.NamesList(expression.Name(r.NotificationHashKey))).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName:                 aws.String(r.NotificationsTable),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+
+		if err != nil {
+			return fmt.Errorf("failed to scan notifications - %w", err)
+		}
+
+		for _, item := range resp.Items {
+			if err := backfillNotificationListKey(client, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func backfillNotificationListKey(client *dynamodb.Client, key map[string]types.AttributeValue) error {
+
+	var notificationId string
+
+	err := attributevalue.Unmarshal(key[r.NotificationHashKey], &notificationId)
+
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal notification id - %w", err)
+	}
+
+	// The notification might have been deleted since the scan
+	expr, err := expression.
+		NewBuilder().
+		WithUpdate(expression.Set(
+			expression.Name(r.NotificationListIdxHashKey),
+			expression.Value(r.NotificationListKey(notificationId)))).
+		WithCondition(expression.AttributeExists(expression.Name(r.NotificationHashKey))).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	_, err = client.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.NotificationsTable),
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	var condErr *types.ConditionalCheckFailedException
+
+	if err != nil && !errors.As(err, &condErr) {
+		return fmt.Errorf("failed to backfill the list key of notification %s - %w", notificationId, err)
+	}
+
+	return nil
+}
+
+// BackfillNotificationReferences adds the indexes of the templates and
+// distribution lists referenced by the schedules and outbox entries to
+// the tables created without them, and sets the references of the items
+// stored before they were kept apart from the notification.
+func BackfillNotificationReferences(client *dynamodb.Client) error {
+
+	if client == nil {
+		return fmt.Errorf("client is nil")
+	}
+
+	indexes := []struct {
+		table string
+		index types.GlobalSecondaryIndex
+	}{
+		{r.NotificationSchedulesTable, makeScheduleTemplateIndex()},
+		{r.NotificationSchedulesTable, makeScheduleDistListIndex()},
+		{r.NotificationOutboxTable, makeOutboxDistListIndex()},
+	}
+
+	for _, idx := range indexes {
+		if err := addIndex(client, idx.table, idx.index); err != nil {
+			return err
+		}
+	}
+
+	err := backfillReferences(client, r.NotificationSchedulesTable, r.NotificationScheduleHashKey, "notification", references{
+		r.NotificationScheduleTemplateIdxKey: storedNotification.templateId,
+		r.NotificationScheduleDistListIdxKey: storedNotification.distributionList,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return backfillReferences(client, r.NotificationOutboxTable, r.NotificationOutboxHashKey, "payload", references{
+		r.NotificationOutboxDistListIdxKey: storedNotification.distributionList,
+	})
+}
+
+// storedNotification is the part of a notification stored as JSON with
+// its references
+type storedNotification struct {
+	Template *struct {
+		Id string `json:"id"`
+	} `json:"template"`
+	DistributionList *string `json:"distributionList"`
+}
+
+func (n storedNotification) templateId() *string {
+
+	if n.Template == nil {
+		return nil
+	}
+
+	return &n.Template.Id
+}
+
+func (n storedNotification) distributionList() *string {
+	return n.DistributionList
+}
+
+// references are the attributes holding the references of the stored
+// notifications, along with the reference they hold
+type references map[string]func(storedNotification) *string
+
+// addIndex adds the index to a table created without it and waits for
+// the index to be created, as a table can only create an index at a
+// time.
+func addIndex(client *dynamodb.Client, tableName string, index types.GlobalSecondaryIndex) error {
+
+	indexName := aws.ToString(index.IndexName)
+
+	findIndex := func() (*types.GlobalSecondaryIndexDescription, error) {
+		desc, err := client.DescribeTable(context.TODO(), &dynamodb.DescribeTableInput{
+			TableName: aws.String(tableName),
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe table %s - %w", tableName, err)
+		}
+
+		for _, idx := range desc.Table.GlobalSecondaryIndexes {
+			if aws.ToString(idx.IndexName) == indexName {
+				return &idx, nil
+			}
+		}
+
+		return nil, nil
+	}
+
+	existing, err := findIndex()
+
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		_, err = client.UpdateTable(context.TODO(), &dynamodb.UpdateTableInput{
+			TableName: aws.String(tableName),
+			AttributeDefinitions: []types.AttributeDefinition{{
+				AttributeName: index.KeySchema[0].AttributeName,
+				AttributeType: types.ScalarAttributeTypeS,
+			}},
+			GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{
+				Create: &types.CreateGlobalSecondaryIndexAction{
+					IndexName:             index.IndexName,
+					KeySchema:             index.KeySchema,
+					Projection:            index.Projection,
+					ProvisionedThroughput: index.ProvisionedThroughput,
+				},
+			}},
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to add index %s to table %s - %w", indexName, tableName, err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Minute)
+
+	for {
+		idx, err := findIndex()
+
+		if err != nil {
+			return err
+		}
+
+		if idx != nil && idx.IndexStatus == types.IndexStatusActive {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("index %s of table %s wasn't created in time", indexName, tableName)
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// backfillReferences sets the references of the items of the table that
+// don't have them, taking them from the notification stored as JSON in
+// the attribute.
+func backfillReferences(client *dynamodb.Client, tableName, hashKey, attribute string, refs references) error {
+
+	conditions := make([]expression.ConditionBuilder, 0, len(refs))
+
+	for ref := range refs {
+		conditions = append(conditions, expression.AttributeNotExists(expression.Name(ref)))
+	}
+
+	filter := conditions[0]
+
+	if len(conditions) > 1 {
+		filter = expression.Or(conditions[0], conditions[1], conditions[2:]...)
+	}
+
+	expr, err := expression.
+		NewBuilder().
+		WithFilter(filter).
+		WithProjection(expression.NamesList(
+			expression.Name(hashKey),
+			expression.Name(attribute))).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName:                 aws.String(tableName),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+
+		if err != nil {
+			return fmt.Errorf("failed to scan %s - %w", tableName, err)
+		}
+
+		for _, item := range resp.Items {
+			err := backfillItemReferences(client, tableName, hashKey, attribute, refs, item)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func backfillItemReferences(client *dynamodb.Client, tableName, hashKey, attribute string, refs references, item map[string]types.AttributeValue) error {
+
+	var stored string
+
+	if err := attributevalue.Unmarshal(item[attribute], &stored); err != nil {
+		return fmt.Errorf("failed to unmarshal %s - %w", attribute, err)
+	}
+
+	var notification storedNotification
+
+	if err := json.Unmarshal([]byte(stored), &notification); err != nil {
+		return fmt.Errorf("failed to unmarshal the notification stored in %s - %w", tableName, err)
+	}
+
+	var update expression.UpdateBuilder
+	hasUpdate := false
+
+	for ref, reference := range refs {
+		if value := reference(notification); value != nil {
+			update = update.Set(expression.Name(ref), expression.Value(*value))
+			hasUpdate = true
+		}
+	}
+
+	// The notification doesn't reference a template or a list
+	if !hasUpdate {
+		return nil
+	}
+
+	// The item might have been deleted or updated since the scan
+	expr, err := expression.
+		NewBuilder().
+		WithUpdate(update).
+		WithCondition(expression.Name(attribute).Equal(expression.Value(stored))).
+		Build()
+
+	if err != nil {
+		return fmt.Errorf("failed to build expression - %w", err)
+	}
+
+	_, err = client.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       map[string]types.AttributeValue{hashKey: item[hashKey]},
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	var condErr *types.ConditionalCheckFailedException
+
+	if err != nil && !errors.As(err, &condErr) {
+		return fmt.Errorf("failed to backfill the references of an item of %s - %w", tableName, err)
+	}
+
+	return nil
+}
@@ -2,7 +2,10 @@ package integration_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,6 +13,7 @@ import (
 	"github.com/notifique/service/internal"
 	"github.com/notifique/service/internal/controllers"
 	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/recipients"
 	"github.com/notifique/service/internal/registry"
 	"github.com/notifique/service/internal/testutils"
 	r "github.com/notifique/service/internal/testutils/registry"
@@ -41,6 +45,11 @@ func TestDistributionRegistryPostgres(t *testing.T) {
 	testAddRecipientsThatAreOnTheDL(ctx, t, tester)
 	testRemoveRecipients(ctx, t, tester)
 	testDeleteRecipientsThatAreNotOnDL(ctx, t, tester)
+	testSegmentDistributionList(ctx, t, tester)
+	testNestedDistributionLists(ctx, t, tester)
+	testImportExportRecipients(ctx, t, tester)
+	testDistributionListMetadata(ctx, t, tester)
+	testDistributionListMembers(ctx, t, tester)
 }
 
 func TestDistributionListRegistryDynamo(t *testing.T) {
@@ -61,6 +70,11 @@ func TestDistributionListRegistryDynamo(t *testing.T) {
 	testAddRecipientsThatAreOnTheDL(ctx, t, tester)
 	testRemoveRecipients(ctx, t, tester)
 	testDeleteRecipientsThatAreNotOnDL(ctx, t, tester)
+	testSegmentDistributionList(ctx, t, tester)
+	testNestedDistributionLists(ctx, t, tester)
+	testImportExportRecipients(ctx, t, tester)
+	testDistributionListMetadata(ctx, t, tester)
+	testDistributionListMembers(ctx, t, tester)
 }
 
 func setupTestDL(ctx context.Context, t *testing.T, dlt DistributionListTester) dto.DistributionList {
@@ -133,7 +147,7 @@ func testGetDistributionListsSummaries(ctx context.Context, t *testing.T, dlt Di
 	defer r.Clear(ctx, t, dlt)
 
 	t.Run("Can retrieve a page of distribution lists summaries", func(t *testing.T) {
-		pageFilters := sdto.PageFilter{}
+		pageFilters := dto.DistributionListFilters{}
 		summaries, err := dlt.GetDistributionLists(ctx, pageFilters)
 
 		if err != nil {
@@ -150,8 +164,8 @@ func testGetDistributionListsSummaries(ctx context.Context, t *testing.T, dlt Di
 
 		maxResults := 1
 
-		pageFilters := sdto.PageFilter{
-			MaxResults: &maxResults,
+		pageFilters := dto.DistributionListFilters{
+			PageFilter: sdto.PageFilter{MaxResults: &maxResults},
 		}
 
 		summaries := make([]dto.DistributionListSummary, 0, len(testDLs))
@@ -370,3 +384,565 @@ func testDeleteRecipientsThatAreNotOnDL(ctx context.Context, t *testing.T, dlt D
 		assert.ElementsMatch(t, dl.Recipients, recipientsPage.Data)
 	})
 }
+
+func testSegmentDistributionList(ctx context.Context, t *testing.T, dlt DistributionListTester) {
+
+	dl := dto.DistributionList{
+		Name: "Segment",
+		Segment: &sdto.Segment{
+			Match: sdto.SegmentMatchAny,
+			Rules: []sdto.SegmentRule{{
+				Attribute: "cognito:groups",
+				Operator:  sdto.SegmentOperatorIn,
+				Values:    []string{"sre"},
+			}},
+		},
+	}
+
+	staticDL := setupTestDL(ctx, t, dlt)
+
+	defer r.Clear(ctx, t, dlt)
+
+	t.Run("Can create a distribution list with a segment", func(t *testing.T) {
+		err := dlt.CreateDistributionList(ctx, dl)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to create the segment - %w", err))
+		}
+
+		segment, err := dlt.GetSegment(ctx, dl.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the segment - %w", err))
+		}
+
+		assert.Equal(t, dl.Segment, segment)
+
+		summaries, err := dlt.GetDistributionLists(ctx, dto.DistributionListFilters{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the summaries - %w", err))
+		}
+
+		expected := []dto.DistributionListSummary{{
+			Name:    dl.Name,
+			Segment: dl.Segment,
+		}, {
+			Name:               staticDL.Name,
+			NumberOfRecipients: len(staticDL.Recipients),
+		}}
+
+		assert.ElementsMatch(t, expected, summaries.Data)
+	})
+
+	t.Run("Should fail if a list with the name of the segment exists", func(t *testing.T) {
+		err := dlt.CreateDistributionList(ctx, dl)
+		assert.ErrorAs(t, err, &internal.DistributionListAlreadyExists{Name: dl.Name})
+	})
+
+	t.Run("Lists with fixed recipients don't have segments", func(t *testing.T) {
+		segment, err := dlt.GetSegment(ctx, staticDL.Name)
+		assert.Nil(t, err)
+		assert.Nil(t, segment)
+	})
+
+	t.Run("Should fail to change the recipients of a segment", func(t *testing.T) {
+		_, err := dlt.AddRecipients(ctx, dl.Name, []string{"1"})
+		assert.ErrorAs(t, err, &internal.DistributionListIsSegment{Name: dl.Name})
+
+		_, err = dlt.DeleteRecipients(ctx, dl.Name, []string{"1"})
+		assert.ErrorAs(t, err, &internal.DistributionListIsSegment{Name: dl.Name})
+	})
+
+	t.Run("Should fail to get the segment of a DL that doesn't exist", func(t *testing.T) {
+		dlName := "Missing Distribution List"
+		_, err := dlt.GetSegment(ctx, dlName)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{Id: dlName, Type: registry.DistributionListType})
+	})
+}
+
+func testNestedDistributionLists(ctx context.Context, t *testing.T, dlt DistributionListTester) {
+
+	sre := dto.DistributionList{Name: "sre", Recipients: []string{"1", "2"}}
+	infra := dto.DistributionList{Name: "infra", Recipients: []string{"2", "3"}, Lists: []string{"sre"}}
+	platform := dto.DistributionList{Name: "platform", Recipients: []string{"4"}, Lists: []string{"infra"}}
+
+	defer r.Clear(ctx, t, dlt)
+
+	for _, dl := range []dto.DistributionList{sre, infra, platform} {
+		if err := dlt.CreateDistributionList(ctx, dl); err != nil {
+			t.Fatal(fmt.Errorf("failed to create %s - %w", dl.Name, err))
+		}
+	}
+
+	t.Run("Can get the nested lists", func(t *testing.T) {
+		page, err := dlt.GetLists(ctx, platform.Name, sdto.PageFilter{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the nested lists - %w", err))
+		}
+
+		assert.Equal(t, []string{infra.Name}, page.Data)
+	})
+
+	t.Run("Can get the flattened recipients", func(t *testing.T) {
+		page, err := dlt.GetFlattenedRecipients(ctx, platform.Name, sdto.PageFilter{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the flattened recipients - %w", err))
+		}
+
+		assert.Equal(t, []string{"1", "2", "3", "4"}, page.Data)
+	})
+
+	t.Run("Can paginate the flattened recipients", func(t *testing.T) {
+		filter := sdto.PageFilter{MaxResults: testutils.Ptr(3)}
+		page, err := dlt.GetFlattenedRecipients(ctx, platform.Name, filter)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the first page - %w", err))
+		}
+
+		assert.Equal(t, []string{"1", "2", "3"}, page.Data)
+		assert.NotNil(t, page.NextToken)
+
+		filter.NextToken = page.NextToken
+		page, err = dlt.GetFlattenedRecipients(ctx, platform.Name, filter)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the second page - %w", err))
+		}
+
+		assert.Equal(t, []string{"4"}, page.Data)
+	})
+
+	t.Run("Should fail to create a list that includes itself", func(t *testing.T) {
+		dl := dto.DistributionList{Name: "self", Lists: []string{"self"}}
+		err := dlt.CreateDistributionList(ctx, dl)
+		assert.ErrorAs(t, err, &internal.DistributionListCycle{Name: dl.Name, List: dl.Name})
+	})
+
+	t.Run("Should fail to include a list that includes the list", func(t *testing.T) {
+		_, err := dlt.AddLists(ctx, sre.Name, []string{platform.Name})
+		assert.ErrorAs(t, err, &internal.DistributionListCycle{Name: sre.Name, List: platform.Name})
+	})
+
+	t.Run("Should fail to include a list that doesn't exist", func(t *testing.T) {
+		dlName := "Missing Distribution List"
+		_, err := dlt.AddLists(ctx, sre.Name, []string{dlName})
+		assert.ErrorAs(t, err, &internal.EntityNotFound{Id: dlName, Type: registry.DistributionListType})
+	})
+
+	t.Run("Can remove the nested lists", func(t *testing.T) {
+		_, err := dlt.DeleteLists(ctx, infra.Name, []string{sre.Name})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to delete the nested lists - %w", err))
+		}
+
+		page, err := dlt.GetFlattenedRecipients(ctx, platform.Name, sdto.PageFilter{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the flattened recipients - %w", err))
+		}
+
+		assert.Equal(t, []string{"2", "3", "4"}, page.Data)
+	})
+
+	t.Run("Deleting a list removes it from the lists that include it", func(t *testing.T) {
+		if err := dlt.DeleteDistributionList(ctx, infra.Name); err != nil {
+			t.Fatal(fmt.Errorf("failed to delete the list - %w", err))
+		}
+
+		page, err := dlt.GetLists(ctx, platform.Name, sdto.PageFilter{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the nested lists - %w", err))
+		}
+
+		assert.Empty(t, page.Data)
+	})
+}
+
+// failingBatches returns its batches and then fails to read the next
+// one, like an import of a file that can't be read to the end.
+type failingBatches struct {
+	batches [][]string
+}
+
+func (b *failingBatches) Next() ([]string, error) {
+
+	if len(b.batches) == 0 {
+		return nil, errors.New("failed to read the batch")
+	}
+
+	batch := b.batches[0]
+	b.batches = b.batches[1:]
+
+	return batch, nil
+}
+
+func testImportExportRecipients(ctx context.Context, t *testing.T, dlt DistributionListTester) {
+
+	dl := setupTestDL(ctx, t, dlt)
+
+	defer r.Clear(ctx, t, dlt)
+
+	importRecipients := func(name string, mode dto.RecipientsImportMode, rows []string) (*dto.DistributionListSummary, error) {
+		file := strings.NewReader("recipient\n" + strings.Join(rows, "\n"))
+		return dlt.ImportRecipients(ctx, name, mode, recipients.NewReader(file, dto.RecipientsCSV))
+	}
+
+	exportRecipients := func(name string) ([]string, error) {
+		exported := []string{}
+
+		err := dlt.ExportRecipients(ctx, name, func(batch []string) error {
+			exported = append(exported, batch...)
+			return nil
+		})
+
+		return exported, err
+	}
+
+	// More recipients than a batch of the import
+	imported := testutils.MakeRecipients(internal.RecipientsBatchSize + 500)
+
+	t.Run("Can add the imported recipients", func(t *testing.T) {
+		summary, err := importRecipients(dl.Name, dto.RecipientsImportAdd, []string{"3", "4", "4", "5"})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to import the recipients - %w", err))
+		}
+
+		assert.Equal(t, 5, summary.NumberOfRecipients)
+
+		exported, err := exportRecipients(dl.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to export the recipients - %w", err))
+		}
+
+		assert.ElementsMatch(t, []string{"1", "2", "3", "4", "5"}, exported)
+	})
+
+	t.Run("Can replace the recipients with the imported ones", func(t *testing.T) {
+		summary, err := importRecipients(dl.Name, dto.RecipientsImportReplace, imported)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to import the recipients - %w", err))
+		}
+
+		assert.Equal(t, len(imported), summary.NumberOfRecipients)
+
+		exported, err := exportRecipients(dl.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to export the recipients - %w", err))
+		}
+
+		assert.ElementsMatch(t, imported, exported)
+	})
+
+	t.Run("Can remove the imported recipients", func(t *testing.T) {
+		toRemove := append(slices.Clone(imported[:500]), "missing")
+
+		summary, err := importRecipients(dl.Name, dto.RecipientsImportRemove, toRemove)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to import the recipients - %w", err))
+		}
+
+		assert.Equal(t, len(imported)-500, summary.NumberOfRecipients)
+
+		exported, err := exportRecipients(dl.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to export the recipients - %w", err))
+		}
+
+		assert.ElementsMatch(t, imported[500:], exported)
+	})
+
+	t.Run("Should keep the recipients and their count when the import fails", func(t *testing.T) {
+		batches := &failingBatches{batches: [][]string{{"new"}}}
+
+		_, err := dlt.ImportRecipients(ctx, dl.Name, dto.RecipientsImportReplace, batches)
+		assert.NotNil(t, err)
+
+		exported, err := exportRecipients(dl.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to export the recipients - %w", err))
+		}
+
+		assert.Subset(t, exported, imported[500:])
+
+		details, err := dlt.GetDistributionListDetails(ctx, dl.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the details - %w", err))
+		}
+
+		assert.Equal(t, len(exported), details.NumberOfRecipients)
+	})
+
+	t.Run("Should fail to import the recipients of a DL that doesn't exist", func(t *testing.T) {
+		dlName := "Missing Distribution List"
+		_, err := importRecipients(dlName, dto.RecipientsImportAdd, []string{"1"})
+		assert.ErrorAs(t, err, &internal.EntityNotFound{Id: dlName, Type: registry.DistributionListType})
+	})
+
+	t.Run("Should fail to export the recipients of a DL that doesn't exist", func(t *testing.T) {
+		dlName := "Missing Distribution List"
+		_, err := exportRecipients(dlName)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{Id: dlName, Type: registry.DistributionListType})
+	})
+}
+
+func testDistributionListMetadata(ctx context.Context, t *testing.T, dlt DistributionListTester) {
+
+	sre := dto.DistributionList{Name: "sre", Recipients: []string{"1", "2"}}
+
+	dl := dto.DistributionList{
+		Name:        "Test",
+		Recipients:  []string{"1", "2", "3"},
+		Lists:       []string{sre.Name},
+		Description: "The test list",
+		Owner:       "1234",
+		Labels:      []string{"infra"},
+	}
+
+	platform := dto.DistributionList{Name: "platform", Lists: []string{dl.Name}}
+
+	defer r.Clear(ctx, t, dlt)
+
+	for _, dl := range []dto.DistributionList{sre, dl, platform} {
+		if err := dlt.CreateDistributionList(ctx, dl); err != nil {
+			t.Fatal(fmt.Errorf("failed to create %s - %w", dl.Name, err))
+		}
+	}
+
+	t.Run("Can retrieve the details of a distribution list", func(t *testing.T) {
+		details, err := dlt.GetDistributionListDetails(ctx, dl.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the details - %w", err))
+		}
+
+		assert.Equal(t, dl.Name, details.Name)
+		assert.Equal(t, len(dl.Recipients), details.NumberOfRecipients)
+		assert.Equal(t, dl.Description, details.Description)
+		assert.Equal(t, &dl.Owner, details.Owner)
+		assert.Equal(t, dl.Labels, details.Labels)
+		assert.NotEmpty(t, details.CreatedAt)
+		assert.Nil(t, details.UpdatedAt)
+	})
+
+	t.Run("Can update the metadata of a distribution list", func(t *testing.T) {
+		metadata := dto.DistributionListMetadata{
+			Description: "The updated test list",
+			Owner:       "5678",
+			Labels:      []string{"sre", "on-call"},
+		}
+
+		details, err := dlt.UpdateDistributionList(ctx, dl.Name, metadata)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to update the metadata - %w", err))
+		}
+
+		assert.Equal(t, metadata.Description, details.Description)
+		assert.Equal(t, &metadata.Owner, details.Owner)
+		assert.Equal(t, metadata.Labels, details.Labels)
+		assert.Equal(t, len(dl.Recipients), details.NumberOfRecipients)
+		assert.NotNil(t, details.UpdatedAt)
+
+		stored, err := dlt.GetDistributionListDetails(ctx, dl.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the details - %w", err))
+		}
+
+		assert.Equal(t, details, stored)
+	})
+
+	t.Run("Should fail to update a DL that doesn't exist", func(t *testing.T) {
+		dlName := "Missing Distribution List"
+		metadata := dto.DistributionListMetadata{Owner: "1234"}
+		_, err := dlt.UpdateDistributionList(ctx, dlName, metadata)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{Id: dlName, Type: registry.DistributionListType})
+	})
+
+	t.Run("Should fail to rename a DL to the name of another DL", func(t *testing.T) {
+		_, err := dlt.RenameDistributionList(ctx, dl.Name, sre.Name)
+		assert.ErrorAs(t, err, &internal.DistributionListAlreadyExists{Name: sre.Name})
+	})
+
+	t.Run("Should fail to rename a DL that doesn't exist", func(t *testing.T) {
+		dlName := "Missing Distribution List"
+		_, err := dlt.RenameDistributionList(ctx, dlName, "renamed")
+		assert.ErrorAs(t, err, &internal.EntityNotFound{Id: dlName, Type: registry.DistributionListType})
+	})
+
+	t.Run("Can rename a distribution list", func(t *testing.T) {
+		newName := "renamed"
+
+		details, err := dlt.RenameDistributionList(ctx, dl.Name, newName)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to rename the list - %w", err))
+		}
+
+		assert.Equal(t, newName, details.Name)
+		assert.Equal(t, len(dl.Recipients), details.NumberOfRecipients)
+		assert.Equal(t, "The updated test list", details.Description)
+
+		_, err = dlt.GetDistributionListDetails(ctx, dl.Name)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{Id: dl.Name, Type: registry.DistributionListType})
+
+		recipients, err := dlt.GetRecipients(ctx, newName, sdto.PageFilter{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the recipients - %w", err))
+		}
+
+		assert.ElementsMatch(t, dl.Recipients, recipients.Data)
+
+		lists, err := dlt.GetLists(ctx, newName, sdto.PageFilter{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the nested lists - %w", err))
+		}
+
+		assert.Equal(t, []string{sre.Name}, lists.Data)
+
+		lists, err = dlt.GetLists(ctx, platform.Name, sdto.PageFilter{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the nested lists - %w", err))
+		}
+
+		assert.Equal(t, []string{newName}, lists.Data)
+	})
+}
+
+func testDistributionListMembers(ctx context.Context, t *testing.T, dlt DistributionListTester) {
+
+	sre := dto.DistributionList{Name: "sre", Recipients: []string{"1", "2"}}
+	infra := dto.DistributionList{Name: "infra", Recipients: []string{"2", "3"}, Lists: []string{"sre"}}
+	platform := dto.DistributionList{Name: "platform", Recipients: []string{"2"}}
+
+	defer r.Clear(ctx, t, dlt)
+
+	for _, dl := range []dto.DistributionList{sre, infra, platform} {
+		if err := dlt.CreateDistributionList(ctx, dl); err != nil {
+			t.Fatal(fmt.Errorf("failed to create %s - %w", dl.Name, err))
+		}
+	}
+
+	getMemberLists := func(member string, maxResults *int) ([]dto.DistributionListSummary, error) {
+		filters := dto.DistributionListFilters{
+			PageFilter: sdto.PageFilter{MaxResults: maxResults},
+			Member:     &member,
+		}
+
+		summaries := []dto.DistributionListSummary{}
+
+		for {
+			page, err := dlt.GetDistributionLists(ctx, filters)
+
+			if err != nil {
+				return nil, err
+			}
+
+			summaries = append(summaries, page.Data...)
+
+			if page.NextToken == nil {
+				return summaries, nil
+			}
+
+			filters.NextToken = page.NextToken
+		}
+	}
+
+	t.Run("Can get the lists of a member", func(t *testing.T) {
+		summaries, err := getMemberLists("2", nil)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the lists of the member - %w", err))
+		}
+
+		expected := []dto.DistributionListSummary{
+			{Name: infra.Name, NumberOfRecipients: 2},
+			{Name: platform.Name, NumberOfRecipients: 1},
+			{Name: sre.Name, NumberOfRecipients: 2},
+		}
+
+		assert.ElementsMatch(t, expected, summaries)
+	})
+
+	t.Run("Can paginate the lists of a member", func(t *testing.T) {
+		summaries, err := getMemberLists("2", testutils.Ptr(1))
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the lists of the member - %w", err))
+		}
+
+		assert.Len(t, summaries, 3)
+	})
+
+	t.Run("The nested lists of a member are not included", func(t *testing.T) {
+		summaries, err := getMemberLists("1", nil)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the lists of the member - %w", err))
+		}
+
+		expected := []dto.DistributionListSummary{{Name: sre.Name, NumberOfRecipients: 2}}
+		assert.Equal(t, expected, summaries)
+	})
+
+	t.Run("Can remove a member from every list", func(t *testing.T) {
+		lists, err := dlt.DeleteRecipientFromLists(ctx, "2")
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to remove the member - %w", err))
+		}
+
+		assert.Equal(t, []string{infra.Name, platform.Name, sre.Name}, lists)
+
+		summaries, err := getMemberLists("2", nil)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the lists of the member - %w", err))
+		}
+
+		assert.Empty(t, summaries)
+
+		details, err := dlt.GetDistributionListDetails(ctx, infra.Name)
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the details - %w", err))
+		}
+
+		assert.Equal(t, 1, details.NumberOfRecipients)
+
+		recipients, err := dlt.GetRecipients(ctx, infra.Name, sdto.PageFilter{})
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to get the recipients - %w", err))
+		}
+
+		assert.Equal(t, []string{"3"}, recipients.Data)
+	})
+
+	t.Run("Removing a member that isn't on any list does nothing", func(t *testing.T) {
+		lists, err := dlt.DeleteRecipientFromLists(ctx, "missing")
+
+		if err != nil {
+			t.Fatal(fmt.Errorf("failed to remove the member - %w", err))
+		}
+
+		assert.Empty(t, lists)
+	})
+}
@@ -2,7 +2,9 @@ package integration_test
 
 import (
 	"context"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -11,6 +13,7 @@ import (
 	"github.com/notifique/service/internal/controllers"
 	"github.com/notifique/service/internal/dto"
 	"github.com/notifique/service/internal/registry"
+	"github.com/notifique/service/internal/scheduler"
 	"github.com/notifique/service/internal/testutils"
 	r "github.com/notifique/service/internal/testutils/registry"
 	sdto "github.com/notifique/shared/dto"
@@ -18,6 +21,7 @@ import (
 
 type NotificationRegistryTester interface {
 	controllers.NotificationRegistry
+	scheduler.ScheduledNotificationRegistry
 	controllers.NotificationTemplateRegistry
 	r.ContainerTester
 }
@@ -39,6 +43,9 @@ func TestNotificationRegistryPostgres(t *testing.T) {
 	testGetNotifications(ctx, t, tester)
 	testGetNotification(ctx, t, tester)
 	testGetRecipientNotificationStatuses(ctx, t, tester)
+	testGetDueNotifications(ctx, t, tester)
+	testGetNotificationStatusLogs(ctx, t, tester)
+	testGetTiedNotificationStatusLogs(ctx, t, tester)
 }
 
 func TestNotificationRegistryDynamo(t *testing.T) {
@@ -57,6 +64,8 @@ func TestNotificationRegistryDynamo(t *testing.T) {
 	testGetNotifications(ctx, t, tester)
 	testGetNotification(ctx, t, tester)
 	testGetRecipientNotificationStatuses(ctx, t, tester)
+	testGetDueNotifications(ctx, t, tester)
+	testGetNotificationStatusLogs(ctx, t, tester)
 }
 
 func testCreateNotification(ctx context.Context, t *testing.T, nt NotificationRegistryTester) {
@@ -156,10 +165,14 @@ func testCreateNotification(ctx context.Context, t *testing.T, nt NotificationRe
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			notificationId, err := nt.SaveNotification(ctx, userId, tt.request)
+			created, err := nt.SaveNotification(ctx, userId, tt.request)
 
 			assert.Nil(t, err)
-			assert.Nil(t, uuid.Validate(notificationId))
+			assert.Nil(t, uuid.Validate(created.Id))
+			assert.Equal(t, sdto.Created, created.Status)
+			assert.NotEmpty(t, created.CreatedAt)
+
+			notificationId := created.Id
 
 			// Verify stored notification
 			storedNotification, err := nt.GetNotification(ctx, notificationId)
@@ -196,12 +209,14 @@ func testUpdateNotificationStatus(ctx context.Context, t *testing.T, nt Notifica
 
 	user := "1234"
 	testNofiticationReq := testutils.MakeTestNotificationRequestRawContents()
-	notificationId, err := nt.SaveNotification(ctx, user, testNofiticationReq)
+	created, err := nt.SaveNotification(ctx, user, testNofiticationReq)
 
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	notificationId := created.Id
+
 	defer r.Clear(ctx, t, nt)
 
 	t.Run("Should be able to update the notification status", func(t *testing.T) {
@@ -238,6 +253,267 @@ func testUpdateNotificationStatus(ctx context.Context, t *testing.T, nt Notifica
 	})
 }
 
+func testGetNotificationStatusLogs(ctx context.Context, t *testing.T, nt NotificationRegistryTester) {
+
+	user := "1234"
+	created, err := nt.SaveNotification(ctx, user, testutils.MakeTestNotificationRequestRawContents())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notificationId := created.Id
+	errMsg := "failed to deliver"
+
+	defer r.Clear(ctx, t, nt)
+
+	for _, status := range []sdto.NotificationStatus{sdto.Queued, sdto.Sending} {
+		err := nt.UpdateNotificationStatus(ctx, sdto.NotificationStatusLog{
+			NotificationId: notificationId,
+			Status:         status,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = nt.UpdateNotificationStatus(ctx, sdto.NotificationStatusLog{
+		NotificationId: notificationId,
+		Status:         sdto.Failed,
+		ErrorMsg:       &errMsg,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []sdto.NotificationStatusLog{{
+		NotificationId: notificationId,
+		Status:         sdto.Created,
+	}, {
+		NotificationId: notificationId,
+		Status:         sdto.Queued,
+	}, {
+		NotificationId: notificationId,
+		Status:         sdto.Sending,
+	}, {
+		NotificationId: notificationId,
+		Status:         sdto.Failed,
+		ErrorMsg:       &errMsg,
+	}}
+
+	t.Run("Should get the status logs in chronological order", func(t *testing.T) {
+		filters := sdto.PageFilter{MaxResults: testutils.IntPtr(1)}
+		logs := make([]sdto.NotificationStatusLog, 0, len(expected))
+
+		for {
+			page, err := nt.GetNotificationStatusLogs(ctx, notificationId, filters)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, l := range page.Data {
+				assert.NotEmpty(t, l.StatusDate)
+				logs = append(logs, l.NotificationStatusLog)
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+
+			filters.NextToken = page.NextToken
+		}
+
+		assert.Equal(t, expected, logs)
+	})
+
+	t.Run("Should fail if the notification doesn't exist", func(t *testing.T) {
+		nonExistentId := uuid.NewString()
+
+		_, err := nt.GetNotificationStatusLogs(ctx, nonExistentId, sdto.PageFilter{})
+		assert.ErrorAs(t, err, &internal.EntityNotFound{Id: nonExistentId, Type: registry.NotificationType})
+	})
+}
+
+// testGetTiedNotificationStatusLogs checks that the pagination doesn't
+// skip the status logs created at the same time.
+func testGetTiedNotificationStatusLogs(ctx context.Context, t *testing.T, nt interface {
+	NotificationRegistryTester
+	InsertNotificationStatusLog(ctx context.Context, statusLog sdto.NotificationStatusLog, statusDate time.Time) error
+}) {
+
+	created, err := nt.SaveNotification(ctx, "1234", testutils.MakeTestNotificationRequestRawContents())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notificationId := created.Id
+
+	defer r.Clear(ctx, t, nt)
+
+	statusDate := time.Now().Add(time.Minute)
+
+	for _, status := range []sdto.NotificationStatus{sdto.Queued, sdto.Sending} {
+		err := nt.InsertNotificationStatusLog(ctx, sdto.NotificationStatusLog{
+			NotificationId: notificationId,
+			Status:         status,
+		}, statusDate)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Should not skip the status logs created at the same time", func(t *testing.T) {
+		filters := sdto.PageFilter{MaxResults: testutils.IntPtr(1)}
+		statuses := make([]sdto.NotificationStatus, 0, 3)
+
+		for {
+			page, err := nt.GetNotificationStatusLogs(ctx, notificationId, filters)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, l := range page.Data {
+				statuses = append(statuses, l.Status)
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+
+			filters.NextToken = page.NextToken
+		}
+
+		assert.Equal(t, []sdto.NotificationStatus{
+			sdto.Created,
+			sdto.Queued,
+			sdto.Sending,
+		}, statuses)
+	})
+}
+
+func testGetDueNotifications(ctx context.Context, t *testing.T, nt NotificationRegistryTester) {
+
+	user := "1234"
+	defer r.Clear(ctx, t, nt)
+
+	sendAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	scheduledReq := testutils.MakeTestNotificationRequestRawContents()
+	scheduledReq.SendAt = &sendAt
+
+	created, err := nt.SaveNotification(ctx, user, scheduledReq)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduledId := created.Id
+
+	_, err = nt.SaveNotification(ctx, user, testutils.MakeTestNotificationRequestRawContents())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Should store scheduled notifications with the SCHEDULED status", func(t *testing.T) {
+		notification, err := nt.GetNotification(ctx, scheduledId)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, sdto.Scheduled, notification.Status)
+		assert.Equal(t, sendAt, *notification.SendAt)
+	})
+
+	t.Run("Should not retrieve notifications before they are due", func(t *testing.T) {
+		ids, err := nt.GetDueNotifications(ctx, time.Now(), internal.PageSize)
+
+		assert.Nil(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("Should retrieve the notifications that are due", func(t *testing.T) {
+		ids, err := nt.GetDueNotifications(ctx, time.Now().Add(2*time.Hour), internal.PageSize)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{scheduledId}, ids)
+	})
+
+	t.Run("Should only claim a due notification once", func(t *testing.T) {
+		created, err := nt.SaveNotification(ctx, user, scheduledReq)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		payload := sdto.NotificationMsgPayload{
+			NotificationReq: scheduledReq,
+			Id:              created.Id,
+			Hash:            internal.GetMd5Hash(created.Id),
+		}
+
+		claimed, err := nt.ClaimScheduledNotification(ctx, payload)
+
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+
+		claimed, err = nt.ClaimScheduledNotification(ctx, payload)
+
+		assert.Nil(t, err)
+		assert.False(t, claimed)
+
+		notification, err := nt.GetNotification(ctx, created.Id)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, sdto.Created, notification.Status)
+	})
+
+	t.Run("Should not claim canceled notifications", func(t *testing.T) {
+		err := nt.UpdateNotificationStatus(ctx, sdto.NotificationStatusLog{
+			NotificationId: scheduledId,
+			Status:         sdto.Canceled,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		claimed, err := nt.ClaimScheduledNotification(ctx, sdto.NotificationMsgPayload{
+			NotificationReq: scheduledReq,
+			Id:              scheduledId,
+			Hash:            internal.GetMd5Hash(scheduledId),
+		})
+
+		assert.Nil(t, err)
+		assert.False(t, claimed)
+	})
+
+	t.Run("Should not retrieve canceled notifications", func(t *testing.T) {
+		err := nt.UpdateNotificationStatus(ctx, sdto.NotificationStatusLog{
+			NotificationId: scheduledId,
+			Status:         sdto.Canceled,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids, err := nt.GetDueNotifications(ctx, time.Now().Add(2*time.Hour), internal.PageSize)
+
+		assert.Nil(t, err)
+		assert.Empty(t, ids)
+	})
+}
+
 func testDeleteNotification(ctx context.Context, t *testing.T, nt NotificationRegistryTester) {
 
 	user := "1234"
@@ -252,12 +528,14 @@ func testDeleteNotification(ctx context.Context, t *testing.T, nt NotificationRe
 
 	for status := range testNotifications {
 		testNofiticationReq := testutils.MakeTestNotificationRequestRawContents()
-		notificationId, err := nt.SaveNotification(ctx, user, testNofiticationReq)
+		created, err := nt.SaveNotification(ctx, user, testNofiticationReq)
 
 		if err != nil {
 			t.Fatal(err)
 		}
 
+		notificationId := created.Id
+
 		err = nt.UpdateNotificationStatus(ctx, sdto.NotificationStatusLog{
 			NotificationId: notificationId,
 			Status:         status,
@@ -353,12 +631,14 @@ func testGetNotifications(ctx context.Context, t *testing.T, nt NotificationRegi
 			)
 		}
 
-		notificationId, err := nt.SaveNotification(ctx, user, testNofiticationReq)
+		created, err := nt.SaveNotification(ctx, user, testNofiticationReq)
 
 		if err != nil {
 			t.Fatal(err)
 		}
 
+		notificationId := created.Id
+
 		summary := testutils.MakeNotificationSummary(testNofiticationReq, notificationId, user)
 		testNotificationSummaries[notificationId] = summary
 	}
@@ -376,10 +656,7 @@ func testGetNotifications(ctx context.Context, t *testing.T, nt NotificationRegi
 
 	t.Run("Should be able to get notifications", func(t *testing.T) {
 
-		filters := sdto.PageFilter{
-			NextToken:  nil,
-			MaxResults: nil,
-		}
+		filters := dto.NotificationFilters{}
 
 		page, err := nt.GetNotifications(ctx, filters)
 
@@ -398,9 +675,10 @@ func testGetNotifications(ctx context.Context, t *testing.T, nt NotificationRegi
 
 	t.Run("Should be able to get notifications with pagination", func(t *testing.T) {
 
-		filters := sdto.PageFilter{
-			NextToken:  nil,
-			MaxResults: testutils.IntPtr(1),
+		filters := dto.NotificationFilters{
+			PageFilter: sdto.PageFilter{
+				MaxResults: testutils.IntPtr(1),
+			},
 		}
 
 		summaries := make([]dto.NotificationSummary, 0, len(testNotificationSummaries))
@@ -421,12 +699,105 @@ func testGetNotifications(ctx context.Context, t *testing.T, nt NotificationRegi
 			filters.NextToken = page.NextToken
 		}
 
+		assert.Len(t, summaries, len(testNotificationSummaries))
+
 		for _, s := range summaries {
 			areSummariesEqual(t, s, testNotificationSummaries[s.Id])
 		}
 	})
-}
 
+	t.Run("Should be able to filter the notifications", func(t *testing.T) {
+
+		filters := dto.NotificationFilters{
+			ContentsType: testutils.Ptr(dto.Template),
+			TemplateId:   &templateResp.Id,
+			CreatedBy:    &user,
+			Status:       testutils.StatusPtr(sdto.Created),
+		}
+
+		page, err := nt.GetNotifications(ctx, filters)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, 2, page.ResultCount)
+
+		for _, s := range page.Data {
+			assert.Equal(t, dto.Template, s.ContentsType)
+			areSummariesEqual(t, s, testNotificationSummaries[s.Id])
+		}
+
+		filters = dto.NotificationFilters{
+			CreatedBy: testutils.StrPtr("someone else"),
+		}
+
+		page, err = nt.GetNotifications(ctx, filters)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Empty(t, page.Data)
+	})
+
+	t.Run("Should be able to filter by the creation date", func(t *testing.T) {
+
+		filters := dto.NotificationFilters{
+			CreatedFrom: testutils.StrPtr(time.Now().Add(time.Hour).Format(time.RFC3339)),
+		}
+
+		page, err := nt.GetNotifications(ctx, filters)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Empty(t, page.Data)
+
+		filters = dto.NotificationFilters{
+			CreatedFrom: testutils.StrPtr(time.Now().Add(-time.Hour).Format(time.RFC3339)),
+			CreatedTo:   testutils.StrPtr(time.Now().Add(time.Hour).Format(time.RFC3339)),
+		}
+
+		page, err = nt.GetNotifications(ctx, filters)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, len(testNotificationSummaries), page.ResultCount)
+	})
+
+	t.Run("Should be able to sort the notifications", func(t *testing.T) {
+
+		for _, order := range []dto.SortOrder{dto.Ascending, dto.Descending} {
+			filters := dto.NotificationFilters{SortOrder: &order}
+
+			page, err := nt.GetNotifications(ctx, filters)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, len(testNotificationSummaries), page.ResultCount)
+
+			isSorted := slices.IsSortedFunc(page.Data, func(a, b dto.NotificationSummary) int {
+				aCreatedAt, _ := time.Parse(time.RFC3339Nano, a.CreatedAt)
+				bCreatedAt, _ := time.Parse(time.RFC3339Nano, b.CreatedAt)
+				cmp := aCreatedAt.Compare(bCreatedAt)
+
+				if order == dto.Descending {
+					return -cmp
+				}
+
+				return cmp
+			})
+
+			assert.True(t, isSorted)
+		}
+	})
+}
 func testGetNotification(ctx context.Context, t *testing.T, nt NotificationRegistryTester) {
 	user := "1234"
 
@@ -441,12 +812,14 @@ func testGetNotification(ctx context.Context, t *testing.T, nt NotificationRegis
 
 	t.Run("Should be able to retrieve a notification with RawContents", func(t *testing.T) {
 		notificationReq := testutils.MakeTestNotificationRequestRawContents()
-		notificationId, err := nt.SaveNotification(ctx, user, notificationReq)
+		created, err := nt.SaveNotification(ctx, user, notificationReq)
 
 		if err != nil {
 			t.Fatal(err)
 		}
 
+		notificationId := created.Id
+
 		notification, err := nt.GetNotification(ctx, notificationId)
 
 		if err != nil {
@@ -467,12 +840,14 @@ func testGetNotification(ctx context.Context, t *testing.T, nt NotificationRegis
 			templateResp.Id,
 			templateReq,
 		)
-		notificationId, err := nt.SaveNotification(ctx, user, notificationReq)
+		created, err := nt.SaveNotification(ctx, user, notificationReq)
 
 		if err != nil {
 			t.Fatal(err)
 		}
 
+		notificationId := created.Id
+
 		notification, err := nt.GetNotification(ctx, notificationId)
 
 		if err != nil {
@@ -488,6 +863,35 @@ func testGetNotification(ctx context.Context, t *testing.T, nt NotificationRegis
 		assert.ElementsMatch(t, notificationReq.Channels, notification.Channels)
 	})
 
+	t.Run("Should be able to retrieve a notification with recipient template variables", func(t *testing.T) {
+		notificationReq := testutils.MakeTestNotificationRequestTemplateContents(
+			templateResp.Id,
+			templateReq,
+		)
+
+		variables := notificationReq.TemplateContents.Variables
+
+		notificationReq.Recipients = []string{"1234", "5678"}
+		notificationReq.TemplateContents.RecipientVariables = map[string][]sdto.TemplateVariableContents{
+			"5678": {{Name: variables[0].Name, Value: variables[0].Value + " override"}},
+		}
+
+		created, err := nt.SaveNotification(ctx, user, notificationReq)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		notification, err := nt.GetNotification(ctx, created.Id)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.ElementsMatch(t, notificationReq.TemplateContents.Variables, notification.TemplateContents.Variables)
+		assert.Equal(t, notificationReq.TemplateContents.RecipientVariables, notification.TemplateContents.RecipientVariables)
+	})
+
 	t.Run("Should return EntityNotFound if the notification doesn't exist", func(t *testing.T) {
 		nonExistentId := uuid.NewString()
 		_, err := nt.GetNotification(ctx, nonExistentId)
@@ -522,11 +926,13 @@ func testGetNotification(ctx context.Context, t *testing.T, nt NotificationRegis
 			Channels:   []sdto.NotificationChannel{"e-mail"},
 		}
 
-		notificationId, err := nt.SaveNotification(ctx, user, notificationReq)
+		created, err := nt.SaveNotification(ctx, user, notificationReq)
 		if err != nil {
 			t.Fatal(err)
 		}
 
+		notificationId := created.Id
+
 		// Retrieve and verify notification
 		notification, err := nt.GetNotification(ctx, notificationId)
 		if err != nil {
@@ -552,12 +958,14 @@ func testGetRecipientNotificationStatuses(ctx context.Context, t *testing.T, nt
 	testNotificationReq.Recipients = recipients
 	testNotificationReq.Channels = channels
 
-	notificationId, err := nt.SaveNotification(ctx, createdBy, testNotificationReq)
+	created, err := nt.SaveNotification(ctx, createdBy, testNotificationReq)
 
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	notificationId := created.Id
+
 	firstStatuses := testutils.MakeTestRecipientNotifcationStatus(recipients, channels, sdto.Sending)
 	statuses := testutils.MakeTestRecipientNotifcationStatus(recipients, channels, sdto.Sent)
 
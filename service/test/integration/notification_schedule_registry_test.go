@@ -0,0 +1,341 @@
+package integration_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/controllers"
+	"github.com/notifique/service/internal/scheduler"
+	"github.com/notifique/service/internal/testutils"
+	r "github.com/notifique/service/internal/testutils/registry"
+	sdto "github.com/notifique/shared/dto"
+)
+
+type NotificationScheduleRegistryTester interface {
+	controllers.NotificationScheduleRegistry
+	scheduler.RecurringScheduleRegistry
+	r.ContainerTester
+}
+
+func TestNotificationScheduleRegistryPostgres(t *testing.T) {
+
+	ctx := context.Background()
+	tester, close, err := r.NewPostgresIntegrationTester(ctx)
+
+	if err != nil {
+		t.Fatal("failed to init postgres tester - ", err)
+	}
+
+	defer close()
+
+	testSaveSchedule(ctx, t, tester)
+	testUpdateSchedule(ctx, t, tester)
+	testGetSchedules(ctx, t, tester)
+	testPauseResumeSchedule(ctx, t, tester)
+	testGetDueSchedules(ctx, t, tester)
+}
+
+func TestNotificationScheduleRegistryDynamo(t *testing.T) {
+
+	ctx := context.Background()
+	tester, close, err := r.NewDynamoRegistryTester(ctx)
+
+	if err != nil {
+		t.Fatal("failed to init dynamo tester - ", err)
+	}
+
+	defer close()
+
+	testSaveSchedule(ctx, t, tester)
+	testUpdateSchedule(ctx, t, tester)
+	testGetSchedules(ctx, t, tester)
+	testPauseResumeSchedule(ctx, t, tester)
+	testGetDueSchedules(ctx, t, tester)
+}
+
+func makeTestNextRun(offset time.Duration) time.Time {
+	return time.Now().Add(offset).UTC().Truncate(time.Second)
+}
+
+func testSaveSchedule(ctx context.Context, t *testing.T, st NotificationScheduleRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	req := testutils.MakeTestNotificationScheduleRequest()
+	nextRunAt := makeTestNextRun(time.Hour)
+
+	created, err := st.SaveSchedule(ctx, userId, req, nextRunAt)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Can retrieve a saved schedule", func(t *testing.T) {
+		schedule, err := st.GetSchedule(ctx, created.Id)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, created, schedule)
+		assert.Equal(t, req, schedule.NotificationScheduleReq)
+		assert.Equal(t, userId, schedule.CreatedBy)
+		assert.Equal(t, nextRunAt.Format(time.RFC3339), *schedule.NextRunAt)
+		assert.False(t, schedule.Paused)
+		assert.Nil(t, schedule.LastRunAt)
+	})
+
+	t.Run("Can delete a schedule", func(t *testing.T) {
+		err := st.DeleteSchedule(ctx, created.Id)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = st.GetSchedule(ctx, created.Id)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+}
+
+func testUpdateSchedule(ctx context.Context, t *testing.T, st NotificationScheduleRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	created, err := st.SaveSchedule(ctx, userId,
+		testutils.MakeTestNotificationScheduleRequest(),
+		makeTestNextRun(time.Hour))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Can update a schedule", func(t *testing.T) {
+		req := testutils.MakeTestNotificationScheduleRequest()
+		req.Name = "Monthly billing"
+		req.CronExpression = "@monthly"
+		req.Notification.Topic = "Billing"
+		nextRunAt := makeTestNextRun(24 * time.Hour)
+
+		updated, err := st.UpdateSchedule(ctx, created.Id, "5678", req, nextRunAt)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, req, updated.NotificationScheduleReq)
+		assert.Equal(t, nextRunAt.Format(time.RFC3339), *updated.NextRunAt)
+		assert.Equal(t, "5678", *updated.UpdatedBy)
+		assert.NotNil(t, updated.UpdatedAt)
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	})
+
+	t.Run("Should fail to update a schedule that doesn't exist", func(t *testing.T) {
+		_, err := st.UpdateSchedule(ctx, uuid.NewString(), userId,
+			testutils.MakeTestNotificationScheduleRequest(),
+			makeTestNextRun(time.Hour))
+
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+}
+
+func testGetSchedules(ctx context.Context, t *testing.T, st NotificationScheduleRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	numSchedules := 5
+	ids := map[string]struct{}{}
+
+	for i := 0; i < numSchedules; i++ {
+		schedule, err := st.SaveSchedule(ctx, userId,
+			testutils.MakeTestNotificationScheduleRequest(),
+			makeTestNextRun(time.Hour))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids[schedule.Id] = struct{}{}
+	}
+
+	t.Run("Can paginate the schedules", func(t *testing.T) {
+		filters := sdto.PageFilter{MaxResults: testutils.IntPtr(2)}
+		retrieved := map[string]struct{}{}
+
+		for {
+			page, err := st.GetSchedules(ctx, filters)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, s := range page.Data {
+				retrieved[s.Id] = struct{}{}
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+
+			filters.NextToken = page.NextToken
+		}
+
+		assert.Equal(t, ids, retrieved)
+	})
+}
+
+func testPauseResumeSchedule(ctx context.Context, t *testing.T, st NotificationScheduleRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	created, err := st.SaveSchedule(ctx, userId,
+		testutils.MakeTestNotificationScheduleRequest(),
+		makeTestNextRun(-time.Minute))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Paused schedules are not due", func(t *testing.T) {
+		paused, err := st.PauseSchedule(ctx, created.Id, userId)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.True(t, paused.Paused)
+
+		due, err := st.GetDueSchedules(ctx, time.Now(), internal.PageSize)
+
+		assert.Nil(t, err)
+		assert.Empty(t, due)
+	})
+
+	t.Run("Resumed schedules use the new next run", func(t *testing.T) {
+		nextRunAt := makeTestNextRun(-time.Second)
+		resumed, err := st.ResumeSchedule(ctx, created.Id, userId, nextRunAt)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.False(t, resumed.Paused)
+		assert.Equal(t, nextRunAt.Format(time.RFC3339), *resumed.NextRunAt)
+
+		due, err := st.GetDueSchedules(ctx, time.Now(), internal.PageSize)
+
+		assert.Nil(t, err)
+
+		if assert.Len(t, due, 1) {
+			assert.Equal(t, created.Id, due[0].Id)
+		}
+	})
+
+	t.Run("Should fail to pause a schedule that doesn't exist", func(t *testing.T) {
+		_, err := st.PauseSchedule(ctx, uuid.NewString(), userId)
+		assert.True(t, errors.As(err, &internal.EntityNotFound{}))
+	})
+}
+
+func testGetDueSchedules(ctx context.Context, t *testing.T, st NotificationScheduleRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	runAt := makeTestNextRun(-time.Minute)
+
+	due, err := st.SaveSchedule(ctx, userId,
+		testutils.MakeTestNotificationScheduleRequest(),
+		runAt)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = st.SaveSchedule(ctx, userId,
+		testutils.MakeTestNotificationScheduleRequest(),
+		makeTestNextRun(time.Hour))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Should retrieve only the due schedules", func(t *testing.T) {
+		schedules, err := st.GetDueSchedules(ctx, time.Now(), internal.PageSize)
+
+		assert.Nil(t, err)
+
+		if assert.Len(t, schedules, 1) {
+			assert.Equal(t, due.Id, schedules[0].Id)
+			assert.Equal(t, due.Notification, schedules[0].Notification)
+		}
+	})
+
+	t.Run("Should claim an occurrence only once", func(t *testing.T) {
+		nextRunAt := makeTestNextRun(time.Hour)
+
+		claimed, err := st.AdvanceSchedule(ctx, due.Id, runAt, nextRunAt)
+
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+
+		claimed, err = st.AdvanceSchedule(ctx, due.Id, runAt, nextRunAt)
+
+		assert.Nil(t, err)
+		assert.False(t, claimed)
+
+		schedule, err := st.GetSchedule(ctx, due.Id)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, runAt.Format(time.RFC3339), *schedule.LastRunAt)
+		assert.Equal(t, nextRunAt.Format(time.RFC3339), *schedule.NextRunAt)
+
+		schedules, err := st.GetDueSchedules(ctx, time.Now(), internal.PageSize)
+
+		assert.Nil(t, err)
+		assert.Empty(t, schedules)
+	})
+
+	t.Run("Should run an occurrence only once", func(t *testing.T) {
+		schedule, err := st.SaveSchedule(ctx, userId,
+			testutils.MakeTestNotificationScheduleRequest(),
+			runAt)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nextRunAt := makeTestNextRun(time.Hour)
+
+		ran, err := st.RunSchedule(ctx, schedule, runAt, nextRunAt)
+
+		assert.Nil(t, err)
+		assert.True(t, ran)
+
+		ran, err = st.RunSchedule(ctx, schedule, runAt, nextRunAt)
+
+		assert.Nil(t, err)
+		assert.False(t, ran)
+
+		schedule, err = st.GetSchedule(ctx, schedule.Id)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, runAt.Format(time.RFC3339), *schedule.LastRunAt)
+		assert.Equal(t, nextRunAt.Format(time.RFC3339), *schedule.NextRunAt)
+	})
+}
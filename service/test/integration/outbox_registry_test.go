@@ -0,0 +1,304 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/controllers"
+	"github.com/notifique/service/internal/outbox"
+	"github.com/notifique/service/internal/scheduler"
+	"github.com/notifique/service/internal/testutils"
+	r "github.com/notifique/service/internal/testutils/registry"
+	sdto "github.com/notifique/shared/dto"
+)
+
+type OutboxRegistryTester interface {
+	controllers.NotificationRegistry
+	outbox.OutboxRegistry
+	scheduler.ScheduledNotificationRegistry
+	r.ContainerTester
+}
+
+func TestOutboxRegistryPostgres(t *testing.T) {
+
+	ctx := context.Background()
+	tester, close, err := r.NewPostgresIntegrationTester(ctx)
+
+	if err != nil {
+		t.Fatal("failed to init postgres tester - ", err)
+	}
+
+	defer close()
+
+	testSaveNotificationOutbox(ctx, t, tester)
+	testClaimOutboxEntry(ctx, t, tester)
+	testResendNotificationOutbox(ctx, t, tester)
+	testClaimScheduledNotificationOutbox(ctx, t, tester)
+}
+
+func TestOutboxRegistryDynamo(t *testing.T) {
+
+	ctx := context.Background()
+	tester, close, err := r.NewDynamoRegistryTester(ctx)
+
+	if err != nil {
+		t.Fatal("failed to init dynamo tester - ", err)
+	}
+
+	defer close()
+
+	testSaveNotificationOutbox(ctx, t, tester)
+	testClaimOutboxEntry(ctx, t, tester)
+	testResendNotificationOutbox(ctx, t, tester)
+	testClaimScheduledNotificationOutbox(ctx, t, tester)
+}
+
+func testSaveNotificationOutbox(ctx context.Context, t *testing.T, st OutboxRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	req := testutils.MakeTestNotificationRequestRawContents()
+
+	created, err := st.SaveNotification(ctx, userId, req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notificationId := created.Id
+
+	scheduledReq := testutils.MakeTestNotificationRequestRawContents()
+	sendAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+	scheduledReq.SendAt = &sendAt
+
+	_, err = st.SaveNotification(ctx, userId, scheduledReq)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Should create an outbox entry with the notification", func(t *testing.T) {
+		entries, err := st.GetOutboxEntries(ctx, time.Now().Add(time.Second), internal.PageSize)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if assert.Len(t, entries, 1) {
+			entry := entries[0]
+			assert.Equal(t, notificationId, entry.Payload.Id)
+			assert.Equal(t, internal.GetMd5Hash(notificationId), entry.Payload.Hash)
+			assert.Equal(t, req, entry.Payload.NotificationReq)
+			assert.Equal(t, 0, entry.Attempts)
+			assert.Nil(t, entry.LastError)
+		}
+	})
+}
+
+func testClaimOutboxEntry(ctx context.Context, t *testing.T, st OutboxRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	created, err := st.SaveNotification(ctx, userId,
+		testutils.MakeTestNotificationRequestRawContents())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notificationId := created.Id
+
+	available := func() time.Time {
+		return time.Now().Add(time.Second)
+	}
+
+	t.Run("Should claim an entry only once", func(t *testing.T) {
+		leaseUntil := time.Now().Add(time.Hour)
+
+		claimed, err := st.ClaimOutboxEntry(ctx, notificationId, 0, leaseUntil)
+
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+
+		claimed, err = st.ClaimOutboxEntry(ctx, notificationId, 0, leaseUntil)
+
+		assert.Nil(t, err)
+		assert.False(t, claimed)
+
+		entries, err := st.GetOutboxEntries(ctx, available(), internal.PageSize)
+
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Should make retried entries available again", func(t *testing.T) {
+		err := st.RetryOutboxEntry(ctx, notificationId, time.Now(), "broker is down")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := st.GetOutboxEntries(ctx, available(), internal.PageSize)
+
+		assert.Nil(t, err)
+
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, 1, entries[0].Attempts)
+			assert.Equal(t, "broker is down", *entries[0].LastError)
+		}
+	})
+
+	t.Run("Can delete an entry", func(t *testing.T) {
+		err := st.DeleteOutboxEntry(ctx, notificationId)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := st.GetOutboxEntries(ctx, available(), internal.PageSize)
+
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func testResendNotificationOutbox(ctx context.Context, t *testing.T, st OutboxRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	req := testutils.MakeTestNotificationRequestRawContents()
+
+	created, err := st.SaveNotification(ctx, userId, req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notificationId := created.Id
+
+	// The relay deletes the entry once the notification is published
+	if err := st.DeleteOutboxEntry(ctx, notificationId); err != nil {
+		t.Fatal(err)
+	}
+
+	resendReq := req
+	resendReq.Recipients = req.Recipients[:1]
+	resendReq.Channels = req.Channels[:1]
+
+	payload := sdto.NotificationMsgPayload{
+		Id:              notificationId,
+		Hash:            internal.GetMd5Hash(notificationId + "resend"),
+		NotificationReq: resendReq,
+	}
+
+	t.Run("Should fail to resend a notification that hasn't been processed", func(t *testing.T) {
+		err := st.ResendNotification(ctx, payload)
+
+		assert.ErrorAs(t, err, &internal.InvalidNotificationStatus{})
+	})
+
+	err = st.UpdateNotificationStatus(ctx, sdto.NotificationStatusLog{
+		NotificationId: notificationId,
+		Status:         sdto.Failed,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Should create an outbox entry with the resent payload", func(t *testing.T) {
+		err := st.ResendNotification(ctx, payload)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		status, err := st.GetNotificationStatus(ctx, notificationId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, sdto.Created, status)
+
+		entries, err := st.GetOutboxEntries(ctx, time.Now().Add(time.Second), internal.PageSize)
+
+		assert.Nil(t, err)
+
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, payload, entries[0].Payload)
+		}
+
+		logs, err := st.GetNotificationStatusLogs(ctx, notificationId, sdto.PageFilter{})
+
+		assert.Nil(t, err)
+
+		statuses := make([]sdto.NotificationStatus, 0, len(logs.Data))
+
+		for _, log := range logs.Data {
+			statuses = append(statuses, log.Status)
+		}
+
+		assert.Equal(t, []sdto.NotificationStatus{
+			sdto.Created,
+			sdto.Failed,
+			sdto.Created,
+		}, statuses)
+	})
+
+	t.Run("Should fail to resend a notification that doesn't exist", func(t *testing.T) {
+		missing := payload
+		missing.Id = uuid.NewString()
+
+		err := st.ResendNotification(ctx, missing)
+
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+}
+
+func testClaimScheduledNotificationOutbox(ctx context.Context, t *testing.T, st OutboxRegistryTester) {
+
+	userId := "1234"
+	defer r.Clear(ctx, t, st)
+
+	req := testutils.MakeTestNotificationRequestRawContents()
+	sendAt := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	req.SendAt = &sendAt
+
+	created, err := st.SaveNotification(ctx, userId, req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := sdto.NotificationMsgPayload{
+		NotificationReq: req,
+		Id:              created.Id,
+		Hash:            internal.GetMd5Hash(created.Id),
+	}
+
+	t.Run("Should create an outbox entry when a scheduled notification is claimed", func(t *testing.T) {
+		entries, err := st.GetOutboxEntries(ctx, time.Now().Add(time.Second), internal.PageSize)
+
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+
+		claimed, err := st.ClaimScheduledNotification(ctx, payload)
+
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+
+		entries, err = st.GetOutboxEntries(ctx, time.Now().Add(time.Second), internal.PageSize)
+
+		assert.Nil(t, err)
+
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, payload, entries[0].Payload)
+		}
+	})
+}
@@ -54,12 +54,14 @@ func testPriorityPublisher(t *testing.T, s c.NotificationRegistry, p c.Notificat
 		Channels:         []dto.NotificationChannel{"in-app", "e-mail"},
 	}
 
-	notificationId, err := s.SaveNotification(context.TODO(), userId, testNotificationReq)
+	created, err := s.SaveNotification(context.TODO(), userId, testNotificationReq)
 
 	if err != nil {
 		t.Fatalf("failed to insert test notification - %v", err)
 	}
 
+	notificationId := created.Id
+
 	marsahlled, err := json.Marshal(testNotificationReq)
 
 	if err != nil {
@@ -0,0 +1,238 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/controllers"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/testutils"
+	r "github.com/notifique/service/internal/testutils/registry"
+)
+
+type TestTemplatePartialRegistry interface {
+	controllers.TemplatePartialRegistry
+	controllers.NotificationTemplateRegistry
+	r.ContainerTester
+}
+
+func TestTemplatePartialsPostgres(t *testing.T) {
+
+	ctx := context.Background()
+	tester, close, err := r.NewPostgresIntegrationTester(ctx)
+
+	if err != nil {
+		t.Fatal("failed to init postgres tester - ", err)
+	}
+
+	defer close()
+
+	testSaveTemplatePartial(ctx, t, tester)
+	testGetTemplatePartials(ctx, t, tester)
+	testUpdateTemplatePartial(ctx, t, tester)
+	testDeleteTemplatePartial(ctx, t, tester)
+}
+
+func TestTemplatePartialsDynamo(t *testing.T) {
+
+	ctx := context.Background()
+	tester, close, err := r.NewDynamoRegistryTester(ctx)
+
+	if err != nil {
+		t.Fatal("failed to init dynamo tester - ", err)
+	}
+
+	defer close()
+
+	testSaveTemplatePartial(ctx, t, tester)
+	testGetTemplatePartials(ctx, t, tester)
+	testUpdateTemplatePartial(ctx, t, tester)
+	testDeleteTemplatePartial(ctx, t, tester)
+}
+
+func testSaveTemplatePartial(ctx context.Context, t *testing.T, tpr TestTemplatePartialRegistry) {
+
+	testUser := "1234"
+
+	defer tpr.ClearDB(ctx)
+
+	req := testutils.MakeTestTemplatePartialRequest()
+
+	t.Run("Can save a template partial", func(t *testing.T) {
+		details, err := tpr.SaveTemplatePartial(ctx, testUser, req)
+
+		assert.Nil(t, err)
+		assert.Equal(t, req.Name, details.Name)
+		assert.Equal(t, req.Kind, details.Kind)
+		assert.Equal(t, 1, details.Version)
+		assert.Equal(t, req.Contents, details.Contents)
+		assert.Equal(t, testUser, details.CreatedBy)
+
+		partial, err := tpr.GetTemplatePartial(ctx, req.Name)
+
+		assert.Nil(t, err)
+		assert.Equal(t, details, partial)
+	})
+
+	t.Run("Should fail if the partial already exists", func(t *testing.T) {
+		_, err := tpr.SaveTemplatePartial(ctx, testUser, req)
+		assert.ErrorAs(t, err, &internal.TemplatePartialAlreadyExists{})
+	})
+
+	t.Run("Should fail if the partial doesn't exist", func(t *testing.T) {
+		_, err := tpr.GetTemplatePartial(ctx, "missing")
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+}
+
+func testGetTemplatePartials(ctx context.Context, t *testing.T, tpr TestTemplatePartialRegistry) {
+
+	testUser := "1234"
+
+	defer tpr.ClearDB(ctx)
+
+	footer := testutils.MakeTestTemplatePartialRequest()
+
+	layout := dto.TemplatePartialReq{
+		Name:        "base",
+		Kind:        string(dto.Layout),
+		Description: "Layout of the e-mails",
+		Contents:    `<main>{{template "content" .}}</main>`,
+	}
+
+	for _, req := range []dto.TemplatePartialReq{footer, layout} {
+		if _, err := tpr.SaveTemplatePartial(ctx, testUser, req); err != nil {
+			t.Fatalf("failed to save template partial - %v", err)
+		}
+	}
+
+	t.Run("Can retrieve the template partials", func(t *testing.T) {
+		page, err := tpr.GetTemplatePartials(ctx, dto.TemplatePartialFilters{})
+
+		assert.Nil(t, err)
+		assert.Nil(t, page.NextToken)
+		assert.Equal(t, []dto.TemplatePartialInfoResp{{
+			Name:        layout.Name,
+			Kind:        layout.Kind,
+			Version:     1,
+			Description: layout.Description,
+		}, {
+			Name:        footer.Name,
+			Kind:        footer.Kind,
+			Version:     1,
+			Description: footer.Description,
+		}}, page.Data)
+	})
+
+	t.Run("Can filter the template partials by kind", func(t *testing.T) {
+		kind := string(dto.Layout)
+
+		page, err := tpr.GetTemplatePartials(ctx, dto.TemplatePartialFilters{
+			Kind: &kind,
+		})
+
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 1)
+		assert.Equal(t, layout.Name, page.Data[0].Name)
+	})
+}
+
+func testUpdateTemplatePartial(ctx context.Context, t *testing.T, tpr TestTemplatePartialRegistry) {
+
+	testUser := "1234"
+	updatingUser := "5678"
+
+	defer tpr.ClearDB(ctx)
+
+	req := testutils.MakeTestTemplatePartialRequest()
+
+	if _, err := tpr.SaveTemplatePartial(ctx, testUser, req); err != nil {
+		t.Fatalf("failed to save template partial - %v", err)
+	}
+
+	update := dto.TemplatePartialUpdateReq{
+		Description: "Short legal text",
+		Contents:    `<p>Sent by {{index . "{app_name}"}}</p>`,
+	}
+
+	t.Run("Can update a template partial", func(t *testing.T) {
+		details, err := tpr.UpdateTemplatePartial(ctx, req.Name, updatingUser, update)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, details.Version)
+		assert.Equal(t, update.Contents, details.Contents)
+		assert.Equal(t, update.Description, details.Description)
+		assert.Equal(t, testUser, details.CreatedBy)
+		assert.Equal(t, &updatingUser, details.UpdatedBy)
+
+		partial, err := tpr.GetTemplatePartial(ctx, req.Name)
+
+		assert.Nil(t, err)
+		assert.Equal(t, details, partial)
+	})
+
+	t.Run("Can retrieve the previous version of the partial", func(t *testing.T) {
+		version, err := tpr.GetTemplatePartialVersion(ctx, req.Name, 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, version.Version)
+		assert.Equal(t, req.Contents, version.Contents)
+		assert.Nil(t, version.UpdatedBy)
+	})
+
+	t.Run("Should fail if the version doesn't exist", func(t *testing.T) {
+		_, err := tpr.GetTemplatePartialVersion(ctx, req.Name, 3)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+
+	t.Run("Should fail if the partial doesn't exist", func(t *testing.T) {
+		_, err := tpr.UpdateTemplatePartial(ctx, "missing", updatingUser, update)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+}
+
+func testDeleteTemplatePartial(ctx context.Context, t *testing.T, tpr TestTemplatePartialRegistry) {
+
+	testUser := "1234"
+
+	defer tpr.ClearDB(ctx)
+
+	req := testutils.MakeTestTemplatePartialRequest()
+
+	if _, err := tpr.SaveTemplatePartial(ctx, testUser, req); err != nil {
+		t.Fatalf("failed to save template partial - %v", err)
+	}
+
+	templateReq := testutils.MakeTestNotificationTemplateRequest()
+	templateReq.ContentsTemplate = `{{template "footer" .}}`
+	templateReq.Partials = []string{req.Name}
+
+	template, err := tpr.SaveTemplate(ctx, testUser, templateReq)
+
+	if err != nil {
+		t.Fatalf("failed to save notification template - %v", err)
+	}
+
+	t.Run("Should fail if the partial is used by templates", func(t *testing.T) {
+		err := tpr.DeleteTemplatePartial(ctx, req.Name)
+		assert.ErrorAs(t, err, &internal.TemplatePartialInUse{})
+	})
+
+	t.Run("Can delete a partial once the templates are archived", func(t *testing.T) {
+		if err := tpr.ArchiveTemplate(ctx, template.Id, testUser); err != nil {
+			t.Fatalf("failed to archive notification template - %v", err)
+		}
+
+		err := tpr.DeleteTemplatePartial(ctx, req.Name)
+		assert.Nil(t, err)
+
+		_, err = tpr.GetTemplatePartial(ctx, req.Name)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+
+		_, err = tpr.GetTemplatePartialVersion(ctx, req.Name, 1)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -24,6 +25,8 @@ type TestNotificationTemplateRegistry interface {
 	r.ContainerTester
 	GetNotificationTemplate(ctx context.Context, id string) (dto.NotificationTemplateReq, error)
 	TemplateExists(ctx context.Context, id string) (bool, error)
+	SaveNotification(ctx context.Context, createdBy string, notification sdto.NotificationReq) (sdto.NotificationCreatedResp, error)
+	GetTemplateVariables(ctx context.Context, templateId string, version *int) ([]sdto.TemplateVariable, int, error)
 }
 
 func TestNotificationsTemplatePostgres(t *testing.T) {
@@ -40,7 +43,10 @@ func TestNotificationsTemplatePostgres(t *testing.T) {
 	testSaveNotificationTemplate(ctx, t, tester)
 	testGetNotificationTemplates(ctx, t, tester)
 	testGetNotificationTemplateDetails(ctx, t, tester)
-	testDeleteNotificationTemplate(ctx, t, tester)
+	testArchiveNotificationTemplate(ctx, t, tester)
+	testPurgeNotificationTemplate(ctx, t, tester)
+	testUpdateNotificationTemplate(ctx, t, tester)
+	testGetNotificationTemplateVersions(ctx, t, tester)
 }
 
 func TestNotificationsTemplateDynamo(t *testing.T) {
@@ -57,7 +63,10 @@ func TestNotificationsTemplateDynamo(t *testing.T) {
 	testSaveNotificationTemplate(ctx, t, tester)
 	testGetNotificationTemplates(ctx, t, tester)
 	testGetNotificationTemplateDetails(ctx, t, tester)
-	testDeleteNotificationTemplate(ctx, t, tester)
+	testArchiveNotificationTemplate(ctx, t, tester)
+	testPurgeNotificationTemplate(ctx, t, tester)
+	testUpdateNotificationTemplate(ctx, t, tester)
+	testGetNotificationTemplateVersions(ctx, t, tester)
 }
 
 func testSaveNotificationTemplate(ctx context.Context, t *testing.T, ntr TestNotificationTemplateRegistry) {
@@ -97,7 +106,10 @@ func testGetNotificationTemplates(ctx context.Context, t *testing.T, ntr TestNot
 	testTemplates := testutils.MakeTestNotificationTemplateRequests(numTemplates)
 	templateInfos := make([]dto.NotificationTemplateInfoResp, 0, len(testTemplates))
 
-	for _, req := range testTemplates {
+	for i, req := range testTemplates {
+		req.Category = testutils.Ptr(fmt.Sprintf("Category %d", i%2))
+		req.Tags = []string{fmt.Sprintf("tag-%d", i), "shared"}
+
 		template, err := ntr.SaveTemplate(ctx, testUser, req)
 
 		if err != nil {
@@ -108,6 +120,11 @@ func testGetNotificationTemplates(ctx context.Context, t *testing.T, ntr TestNot
 			Id:          template.Id,
 			Name:        template.Name,
 			Description: req.Description,
+			Format:      req.Format,
+			Category:    req.Category,
+			Tags:        req.Tags,
+			CreatedBy:   testUser,
+			CreatedAt:   template.CreatedAt,
 		})
 	}
 
@@ -180,6 +197,63 @@ func testGetNotificationTemplates(ctx context.Context, t *testing.T, ntr TestNot
 		assert.Equal(t, 1, page.ResultCount)
 		assert.Equal(t, expectedInfos, page.Data)
 	})
+
+	otherUser := "4321"
+	futureDate := time.Now().AddDate(1, 0, 0).Format(time.RFC3339)
+
+	filterTests := []struct {
+		name     string
+		filters  dto.NotificationTemplateFilters
+		expected []dto.NotificationTemplateInfoResp
+	}{{
+		name:     "Can filter by category",
+		filters:  dto.NotificationTemplateFilters{Category: testutils.Ptr("Category 0")},
+		expected: []dto.NotificationTemplateInfoResp{templateInfos[0], templateInfos[2]},
+	}, {
+		name:     "Can filter by tags",
+		filters:  dto.NotificationTemplateFilters{Tags: []string{"shared", "tag-1"}},
+		expected: []dto.NotificationTemplateInfoResp{templateInfos[1]},
+	}, {
+		name:     "Can filter by creator",
+		filters:  dto.NotificationTemplateFilters{CreatedBy: &otherUser},
+		expected: []dto.NotificationTemplateInfoResp{},
+	}, {
+		name: "Can filter by category and creator",
+		filters: dto.NotificationTemplateFilters{
+			Category:  testutils.Ptr("Category 1"),
+			CreatedBy: &testUser,
+		},
+		expected: []dto.NotificationTemplateInfoResp{templateInfos[1]},
+	}, {
+		name:     "Can filter by format",
+		filters:  dto.NotificationTemplateFilters{Format: testutils.Ptr(sdto.Text)},
+		expected: []dto.NotificationTemplateInfoResp{},
+	}, {
+		name:     "Can filter by creation date",
+		filters:  dto.NotificationTemplateFilters{CreatedFrom: &futureDate},
+		expected: []dto.NotificationTemplateInfoResp{},
+	}, {
+		name:     "Can search the name",
+		filters:  dto.NotificationTemplateFilters{Search: testutils.Ptr("NAME 2")},
+		expected: []dto.NotificationTemplateInfoResp{templateInfos[2]},
+	}, {
+		name:     "Can search the description",
+		filters:  dto.NotificationTemplateFilters{Search: testutils.Ptr("signed-in")},
+		expected: templateInfos,
+	}}
+
+	for _, tt := range filterTests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := ntr.GetTemplates(ctx, tt.filters)
+
+			if err != nil {
+				t.Fatalf("failed to retrieve page - %v", err)
+			}
+
+			assert.Equal(t, len(tt.expected), page.ResultCount)
+			assert.ElementsMatch(t, tt.expected, page.Data)
+		})
+	}
 }
 
 func testGetNotificationTemplateDetails(ctx context.Context, t *testing.T, ntr TestNotificationTemplateRegistry) {
@@ -202,6 +276,7 @@ func testGetNotificationTemplateDetails(ctx context.Context, t *testing.T, ntr T
 		assert.Equal(t, saved.Id, details.Id)
 		assert.Equal(t, req.Name, details.Name)
 		assert.Equal(t, req.IsHtml, details.IsHtml)
+		assert.Equal(t, req.Format, details.Format)
 		assert.Equal(t, req.Description, details.Description)
 		assert.Equal(t, req.TitleTemplate, details.TitleTemplate)
 		assert.Equal(t, req.ContentsTemplate, details.ContentsTemplate)
@@ -229,7 +304,7 @@ func testGetNotificationTemplateDetails(ctx context.Context, t *testing.T, ntr T
 	})
 }
 
-func testDeleteNotificationTemplate(ctx context.Context, t *testing.T, ntr TestNotificationTemplateRegistry) {
+func testArchiveNotificationTemplate(ctx context.Context, t *testing.T, ntr TestNotificationTemplateRegistry) {
 
 	defer ntr.ClearDB(ctx)
 
@@ -246,10 +321,17 @@ func testDeleteNotificationTemplate(ctx context.Context, t *testing.T, ntr TestN
 		return saved
 	}
 
-	t.Run("Can delete a notification template", func(t *testing.T) {
+	t.Run("Can archive a notification template", func(t *testing.T) {
 		template := setupTemplate()
 
-		err := ntr.DeleteTemplate(ctx, template.Id)
+		notification := testutils.MakeTestNotificationRequestTemplateContents(template.Id, req)
+		_, err := ntr.SaveNotification(ctx, testUser, notification)
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		err = ntr.ArchiveTemplate(ctx, template.Id, testUser)
 
 		assert.Nil(t, err)
 
@@ -259,24 +341,291 @@ func testDeleteNotificationTemplate(ctx context.Context, t *testing.T, ntr TestN
 			t.Fatal(err.Error())
 		}
 
-		assert.False(t, exists)
+		assert.True(t, exists)
+
+		details, err := ntr.GetTemplateDetails(ctx, template.Id)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, details.ArchivedAt)
+		assert.Equal(t, &testUser, details.ArchivedBy)
+
+		page, err := ntr.GetTemplates(ctx, dto.NotificationTemplateFilters{})
+
+		assert.Nil(t, err)
+
+		for _, info := range page.Data {
+			assert.NotEqual(t, template.Id, info.Id)
+		}
 	})
 
-	t.Run("Can delete a notification that is already deleted", func(t *testing.T) {
+	t.Run("Archived templates can't be used or updated", func(t *testing.T) {
 		template := setupTemplate()
 
-		err := ntr.DeleteTemplate(ctx, template.Id)
+		if err := ntr.ArchiveTemplate(ctx, template.Id, testUser); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		_, _, err := ntr.GetTemplateVariables(ctx, template.Id, nil)
+		assert.ErrorAs(t, err, &internal.TemplateArchived{})
+
+		notification := testutils.MakeTestNotificationRequestTemplateContents(template.Id, req)
+		_, err = ntr.SaveNotification(ctx, testUser, notification)
+		assert.ErrorAs(t, err, &internal.TemplateArchived{})
+
+		_, err = ntr.UpdateTemplate(ctx, template.Id, testUser, req)
+		assert.Error(t, err)
+
+		version, err := ntr.GetTemplateVersion(ctx, template.Id, 1)
+		assert.Nil(t, err)
+		assert.NotNil(t, version.ArchivedAt)
+	})
+
+	t.Run("Can archive a template that is already archived", func(t *testing.T) {
+		template := setupTemplate()
+
+		err := ntr.ArchiveTemplate(ctx, template.Id, testUser)
 
 		if err != nil {
 			t.Fatal(err.Error())
 		}
 
-		err = ntr.DeleteTemplate(ctx, template.Id)
+		err = ntr.ArchiveTemplate(ctx, template.Id, testUser)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should do nothing if the template doesn't exist", func(t *testing.T) {
+		err := ntr.ArchiveTemplate(ctx, uuid.NewString(), testUser)
+		assert.Nil(t, err)
+	})
+}
+
+func testPurgeNotificationTemplate(ctx context.Context, t *testing.T, ntr TestNotificationTemplateRegistry) {
+
+	defer ntr.ClearDB(ctx)
+
+	testUser := "1234"
+	req := testutils.MakeTestNotificationTemplateRequest()
+
+	setupTemplate := func() dto.NotificationTemplateCreatedResp {
+		saved, err := ntr.SaveTemplate(ctx, testUser, req)
+
+		if err != nil {
+			t.Fatalf("failed to save template for test - %v", err)
+		}
+
+		return saved
+	}
+
+	archiveTemplate := func(templateId string) {
+		if err := ntr.ArchiveTemplate(ctx, templateId, testUser); err != nil {
+			t.Fatalf("failed to archive template for test - %v", err)
+		}
+	}
+
+	t.Run("Can purge a template that isn't referenced", func(t *testing.T) {
+		template := setupTemplate()
+		archiveTemplate(template.Id)
+
+		err := ntr.PurgeTemplate(ctx, template.Id)
+
 		assert.Nil(t, err)
+
+		exists, err := ntr.TemplateExists(ctx, template.Id)
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.False(t, exists)
+
+		_, err = ntr.GetTemplateVersion(ctx, template.Id, 1)
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+
+	t.Run("Should refuse to purge a template used by a notification", func(t *testing.T) {
+		template := setupTemplate()
+
+		notification := testutils.MakeTestNotificationRequestTemplateContents(template.Id, req)
+		_, err := ntr.SaveNotification(ctx, testUser, notification)
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		archiveTemplate(template.Id)
+
+		err = ntr.PurgeTemplate(ctx, template.Id)
+		assert.ErrorAs(t, err, &internal.TemplateInUse{})
+
+		exists, err := ntr.TemplateExists(ctx, template.Id)
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.True(t, exists)
+	})
+
+	t.Run("Should refuse to purge a template that isn't archived", func(t *testing.T) {
+		template := setupTemplate()
+
+		err := ntr.PurgeTemplate(ctx, template.Id)
+		assert.ErrorAs(t, err, &internal.TemplateNotArchived{})
+
+		exists, err := ntr.TemplateExists(ctx, template.Id)
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		assert.True(t, exists)
+	})
+
+	t.Run("Should check that a referenced template is archived first", func(t *testing.T) {
+		template := setupTemplate()
+
+		notification := testutils.MakeTestNotificationRequestTemplateContents(template.Id, req)
+		_, err := ntr.SaveNotification(ctx, testUser, notification)
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		err = ntr.PurgeTemplate(ctx, template.Id)
+		assert.ErrorAs(t, err, &internal.TemplateNotArchived{})
 	})
 
 	t.Run("Should do nothing if the template doesn't exist", func(t *testing.T) {
-		err := ntr.DeleteTemplate(ctx, uuid.NewString())
+		err := ntr.PurgeTemplate(ctx, uuid.NewString())
+		assert.Nil(t, err)
+	})
+}
+
+func testUpdateNotificationTemplate(ctx context.Context, t *testing.T, ntr TestNotificationTemplateRegistry) {
+
+	defer ntr.ClearDB(ctx)
+
+	testUser := "1234"
+	updateUser := "5678"
+	req := testutils.MakeTestNotificationTemplateRequest()
+
+	saved, err := ntr.SaveTemplate(ctx, testUser, req)
+
+	if err != nil {
+		t.Fatalf("failed to save template for test - %v", err)
+	}
+
+	updateReq := testutils.MakeTestNotificationTemplateRequest()
+	updateReq.TitleTemplate = "Hello {{user}}!"
+	updateReq.Variables = updateReq.Variables[:1]
+	updateReq.DefaultLocale = testutils.StrPtr("en")
+	updateReq.Localizations = []sdto.TemplateLocalization{{
+		Locale:           "pt",
+		TitleTemplate:    "Olá {{user}}!",
+		ContentsTemplate: "Bem-vindo ao {app_name}!",
+	}}
+
+	t.Run("Can update a notification template", func(t *testing.T) {
+		details, err := ntr.UpdateTemplate(ctx, saved.Id, updateUser, updateReq)
+
+		assert.Nil(t, err)
+		assert.Equal(t, saved.Id, details.Id)
+		assert.Equal(t, 2, details.Version)
+		assert.Equal(t, updateReq.TitleTemplate, details.TitleTemplate)
+		assert.ElementsMatch(t, updateReq.Variables, details.Variables)
+		assert.Equal(t, testUser, details.CreatedBy)
+		assert.Equal(t, &updateUser, details.UpdatedBy)
+		assert.NotNil(t, details.UpdatedAt)
+
+		current, err := ntr.GetTemplateDetails(ctx, saved.Id)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, current.Version)
+		assert.Equal(t, updateReq.TitleTemplate, current.TitleTemplate)
+		assert.Equal(t, updateReq.DefaultLocale, current.DefaultLocale)
+		assert.Equal(t, updateReq.Localizations, current.Localizations)
+	})
+
+	t.Run("Previous versions are kept unchanged", func(t *testing.T) {
+		previous, err := ntr.GetTemplateVersion(ctx, saved.Id, 1)
+
 		assert.Nil(t, err)
+		assert.Equal(t, 1, previous.Version)
+		assert.Equal(t, req.TitleTemplate, previous.TitleTemplate)
+		assert.ElementsMatch(t, req.Variables, previous.Variables)
+		assert.Nil(t, previous.UpdatedBy)
+		assert.Nil(t, previous.DefaultLocale)
+		assert.Empty(t, previous.Localizations)
+	})
+
+	t.Run("Should fail if the template doesn't exist", func(t *testing.T) {
+		nonExistentId := uuid.NewString()
+		_, err := ntr.UpdateTemplate(ctx, nonExistentId, updateUser, updateReq)
+
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+
+	t.Run("Should fail if the version doesn't exist", func(t *testing.T) {
+		_, err := ntr.GetTemplateVersion(ctx, saved.Id, 10)
+
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
+	})
+}
+
+func testGetNotificationTemplateVersions(ctx context.Context, t *testing.T, ntr TestNotificationTemplateRegistry) {
+
+	defer ntr.ClearDB(ctx)
+
+	testUser := "1234"
+	req := testutils.MakeTestNotificationTemplateRequest()
+
+	saved, err := ntr.SaveTemplate(ctx, testUser, req)
+
+	if err != nil {
+		t.Fatalf("failed to save template for test - %v", err)
+	}
+
+	numVersions := 5
+
+	for i := 1; i < numVersions; i++ {
+		req.Description = strconv.Itoa(i)
+
+		if _, err := ntr.UpdateTemplate(ctx, saved.Id, testUser, req); err != nil {
+			t.Fatalf("failed to update template for test - %v", err)
+		}
+	}
+
+	t.Run("Can paginate the versions, newest first", func(t *testing.T) {
+		maxResults := 2
+		filters := sdto.PageFilter{MaxResults: &maxResults}
+		versions := []int{}
+
+		for {
+			page, err := ntr.GetTemplateVersions(ctx, saved.Id, filters)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, v := range page.Data {
+				versions = append(versions, v.Version)
+				assert.Equal(t, req.Name, v.Name)
+				assert.Equal(t, testUser, v.CreatedBy)
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+
+			filters.NextToken = page.NextToken
+		}
+
+		assert.Equal(t, []int{5, 4, 3, 2, 1}, versions)
+	})
+
+	t.Run("Should fail if the template doesn't exist", func(t *testing.T) {
+		_, err := ntr.GetTemplateVersions(ctx, uuid.NewString(), sdto.PageFilter{})
+
+		assert.ErrorAs(t, err, &internal.EntityNotFound{})
 	})
 }
@@ -227,6 +227,7 @@ func testUserConfig(ctx context.Context, t *testing.T, ust UserRegistryTester) {
 		userConfig := testutils.MakeTestUserConfig(userId)
 		userConfig.EmailConfig = dto.ChannelConfig{OptIn: false, SnoozeUntil: nil}
 		userConfig.SMSConfig = dto.ChannelConfig{OptIn: true, SnoozeUntil: &snoozeUntil}
+		userConfig.Locale = testutils.StrPtr("pt-BR")
 
 		err := ust.UpdateUserConfig(ctx, userId, userConfig)
 
@@ -258,6 +259,9 @@ func testInsertUserNotifications(ctx context.Context, t *testing.T, ust UserRegi
 		})
 	}
 
+	htmlContents := "<p>HTML contents</p>"
+	notificationsToInsert[0].HtmlContents = &htmlContents
+
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -283,11 +287,12 @@ func testInsertUserNotifications(ctx context.Context, t *testing.T, ust UserRegi
 
 			for _, n := range page.Data {
 				insertedNotifications = append(insertedNotifications, sdto.UserNotificationReq{
-					UserId:   userId,
-					Title:    n.Title,
-					Topic:    n.Topic,
-					Contents: n.Contents,
-					Image:    n.Image,
+					UserId:       userId,
+					Title:        n.Title,
+					Topic:        n.Topic,
+					Contents:     n.Contents,
+					HtmlContents: n.HtmlContents,
+					Image:        n.Image,
 				})
 			}
 		}
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -17,6 +20,7 @@ import (
 	"github.com/notifique/service/internal"
 	di "github.com/notifique/service/internal/di"
 	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/recipients"
 	"github.com/notifique/service/internal/registry"
 	"github.com/notifique/service/internal/testutils"
 	"github.com/notifique/shared/auth"
@@ -43,9 +47,34 @@ func TestDistributionListController(t *testing.T) {
 	testCreateDistributionList(t, testApp.Engine, *testApp)
 	testAddRecipients(t, testApp.Engine, *testApp)
 	testDeleteRecipients(t, testApp.Engine, *testApp)
+	testImportRecipients(t, testApp.Engine, *testApp)
+	testExportRecipients(t, testApp.Engine, *testApp)
 	testDeleteDistributionList(t, testApp.Engine, *testApp)
+	testGetDistributionList(t, testApp.Engine, *testApp)
+	testUpdateDistributionList(t, testApp.Engine, *testApp)
+	testRenameDistributionList(t, testApp.Engine, *testApp)
 	testGetDistributionLists(t, testApp.Engine, *testApp)
+	testRemoveMember(t, testApp.Engine, *testApp)
 	testGetDistributionListRescipients(t, testApp.Engine, *testApp)
+	testAddLists(t, testApp.Engine, *testApp)
+	testDeleteLists(t, testApp.Engine, *testApp)
+	testGetDistributionListLists(t, testApp.Engine, *testApp)
+	testGetDistributionListSegment(t, testApp.Engine, *testApp)
+	testPreviewSegment(t, testApp.Engine, *testApp)
+}
+
+func makeTestSegment() *sdto.Segment {
+	return &sdto.Segment{
+		Match: sdto.SegmentMatchAll,
+		Rules: []sdto.SegmentRule{{
+			Attribute: "cognito:groups",
+			Operator:  sdto.SegmentOperatorIn,
+			Values:    []string{"sre", "infra"},
+		}, {
+			Attribute: "custom:department",
+			Operator:  sdto.SegmentOperatorExists,
+		}},
+	}
 }
 
 func testCreateDistributionList(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
@@ -121,6 +150,13 @@ func testCreateDistributionList(t *testing.T, e *gin.Engine, mock di.MockedBacke
 			expectedCode:  http.StatusBadRequest,
 			expectedError: "DistributionList.Name' Error:Field validation for 'Name' failed on the 'distributionlistname' tag",
 		},
+		{
+			name:          "Fail - Reserved name",
+			input:         dto.DistributionList{Name: "members", Recipients: []string{}},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Name' Error:Field validation for 'Name' failed on the 'distributionlistname' tag",
+		},
 		{
 			name:          "Fail - Too many recipients",
 			input:         dto.DistributionList{Name: "Test", Recipients: testutils.MakeRecipients(257)},
@@ -135,6 +171,203 @@ func testCreateDistributionList(t *testing.T, e *gin.Engine, mock di.MockedBacke
 			expectedCode:  http.StatusBadRequest,
 			expectedError: "DistributionList.Recipients' Error:Field validation for 'Recipients' failed on the 'unique' tag",
 		},
+		{
+			name: "Success - Create with metadata",
+			input: dto.DistributionList{
+				Name:        "Test",
+				Recipients:  []string{"1"},
+				Description: "The test list",
+				Owner:       "5678",
+				Labels:      []string{"sre"},
+			},
+			setupMock: func() {
+				mock.Registry.
+					MockDistributionRegistry.
+					EXPECT().
+					CreateDistributionList(gomock.Any(), dto.DistributionList{
+						Name:        "Test",
+						Recipients:  []string{"1"},
+						Description: "The test list",
+						Owner:       "5678",
+						Labels:      []string{"sre"},
+					}).Return(nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:          "Fail - Duplicate labels",
+			input:         dto.DistributionList{Name: "Test", Labels: []string{"sre", "sre"}},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Labels' Error:Field validation for 'Labels' failed on the 'unique' tag",
+		},
+		{
+			name:  "Success - Create segment",
+			input: dto.DistributionList{Name: "Test", Segment: makeTestSegment()},
+			setupMock: func() {
+				mock.Registry.
+					MockDistributionRegistry.
+					EXPECT().
+					CreateDistributionList(gomock.Any(), dto.DistributionList{
+						Name:    "Test",
+						Segment: makeTestSegment(),
+						Owner:   testUserId,
+					}).Return(nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "Fail - Segment with recipients",
+			input: dto.DistributionList{
+				Name:       "Test",
+				Recipients: []string{"1"},
+				Segment:    makeTestSegment(),
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Recipients' Error:Field validation for 'Recipients' failed on the 'excluded_with' tag",
+		},
+		{
+			name: "Success - Create distribution list with nested lists",
+			input: dto.DistributionList{
+				Name:  "Test",
+				Lists: []string{"sre", "infra"},
+			},
+			setupMock: func() {
+				mock.Registry.
+					MockDistributionRegistry.
+					EXPECT().
+					CreateDistributionList(gomock.Any(), gomock.Any()).Return(nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "Fail - Segment with nested lists",
+			input: dto.DistributionList{
+				Name:    "Test",
+				Lists:   []string{"sre"},
+				Segment: makeTestSegment(),
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Lists' Error:Field validation for 'Lists' failed on the 'excluded_with' tag",
+		},
+		{
+			name: "Fail - Invalid nested list name",
+			input: dto.DistributionList{
+				Name:  "Test",
+				Lists: []string{"a"},
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Lists[0]' Error:Field validation for 'Lists[0]' failed on the 'min' tag",
+		},
+		{
+			name: "Fail - Nested list includes the list",
+			input: dto.DistributionList{
+				Name:  "Test",
+				Lists: []string{"Test"},
+			},
+			setupMock: func() {
+				mock.Registry.
+					MockDistributionRegistry.
+					EXPECT().
+					CreateDistributionList(gomock.Any(), gomock.Any()).
+					Return(internal.DistributionListCycle{Name: "Test", List: "Test"})
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "distribution list Test can't include Test, it would create a cycle",
+		},
+		{
+			name: "Fail - Nested list not found",
+			input: dto.DistributionList{
+				Name:  "Test",
+				Lists: []string{"sre"},
+			},
+			setupMock: func() {
+				mock.Registry.
+					MockDistributionRegistry.
+					EXPECT().
+					CreateDistributionList(gomock.Any(), gomock.Any()).
+					Return(internal.EntityNotFound{
+						Id:   "sre",
+						Type: registry.DistributionListType,
+					})
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("entity sre of type %v not found", registry.DistributionListType),
+		},
+		{
+			name: "Fail - Segment without rules",
+			input: dto.DistributionList{
+				Name:    "Test",
+				Segment: &sdto.Segment{Match: sdto.SegmentMatchAny},
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Segment.Rules' Error:Field validation for 'Rules' failed on the 'required' tag",
+		},
+		{
+			name: "Fail - Invalid segment match",
+			input: dto.DistributionList{
+				Name: "Test",
+				Segment: &sdto.Segment{
+					Match: "SOME",
+					Rules: makeTestSegment().Rules,
+				},
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Segment.Match' Error:Field validation for 'Match' failed on the 'oneof' tag",
+		},
+		{
+			name: "Fail - Segment rule without values",
+			input: dto.DistributionList{
+				Name: "Test",
+				Segment: &sdto.Segment{
+					Match: sdto.SegmentMatchAll,
+					Rules: []sdto.SegmentRule{{
+						Attribute: "cognito:groups",
+						Operator:  sdto.SegmentOperatorNotIn,
+					}},
+				},
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Segment.Rules[0].values' Error:Field validation for 'values' failed on the 'required_if' tag",
+		},
+		{
+			name: "Fail - Segment rule checking existence with values",
+			input: dto.DistributionList{
+				Name: "Test",
+				Segment: &sdto.Segment{
+					Match: sdto.SegmentMatchAll,
+					Rules: []sdto.SegmentRule{{
+						Attribute: "custom:department",
+						Operator:  sdto.SegmentOperatorNotExists,
+						Values:    []string{"sales"},
+					}},
+				},
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "DistributionList.Segment.Rules[0].values' Error:Field validation for 'values' failed on the 'excluded_unless' tag",
+		},
 	}
 
 	for _, tt := range tests {
@@ -194,7 +427,7 @@ func testAddRecipients(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
 
 				mock.Cache.
 					EXPECT().
-					DelWithPrefix(gomock.Any(), cache.Key(distributionListRecipientsKey)).
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
 					Return(nil)
 			},
 			expectedCode: http.StatusOK,
@@ -219,6 +452,19 @@ func testAddRecipients(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
 			expectedCode:  http.StatusBadRequest,
 			expectedError: fmt.Sprintf("entity %v of type %v not found", dl.Name, registry.DistributionListType),
 		},
+		{
+			name:       "Fail - Distribution list is a segment",
+			dlName:     dl.Name,
+			recipients: []string{"4", "5", "6"},
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					AddRecipients(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, internal.DistributionListIsSegment{Name: dl.Name})
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("distribution list %v is a segment", dl.Name),
+		},
 		{
 			name:          "Fail - Empty recipients list",
 			dlName:        dl.Name,
@@ -316,7 +562,7 @@ func testDeleteRecipients(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
 
 				mock.Cache.
 					EXPECT().
-					DelWithPrefix(gomock.Any(), cache.Key(distributionListRecipientsKey)).
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
 					Return(nil)
 			},
 			expectedCode: http.StatusOK,
@@ -396,105 +642,301 @@ func testDeleteRecipients(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
 	}
 }
 
-func testDeleteDistributionList(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
-	testDL := "Test"
-
-	deleteDistributionList := func(dlName string) *httptest.ResponseRecorder {
+func testImportRecipients(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+	dlName := "Test"
 
+	importRecipients := func(query, field, contents string) *httptest.ResponseRecorder {
 		w := httptest.NewRecorder()
 
-		url := fmt.Sprintf("%s/%s", distributionListUrl, dlName)
+		body := &bytes.Buffer{}
+		form := multipart.NewWriter(body)
+		part, _ := form.CreateFormFile(field, "recipients")
+		part.Write([]byte(contents))
+		form.Close()
 
-		req, _ := http.NewRequest(http.MethodDelete, url, nil)
+		url := fmt.Sprintf("%s/%s/recipients/import?%s", distributionListUrl, dlName, query)
+		req, _ := http.NewRequest(http.MethodPost, url, body)
 		req.Header.Add(string(auth.UserHeader), testUserId)
-
+		req.Header.Add("Content-Type", form.FormDataContentType())
 		e.ServeHTTP(w, req)
-
 		return w
 	}
 
-	t.Run("Should be able to delete a distribution list", func(t *testing.T) {
-		mock.Registry.MockDistributionRegistry.
-			EXPECT().
-			DeleteDistributionList(gomock.Any(), gomock.Any()).
-			Return(nil)
+	// readBatches makes the registry read every batch of the import
+	readBatches := func(imported *[]string, summary *dto.DistributionListSummary) any {
+		return func(ctx context.Context, name string, mode dto.RecipientsImportMode, batches recipients.Batches) (*dto.DistributionListSummary, error) {
+			for {
+				batch, err := batches.Next()
 
-		mock.Cache.
-			EXPECT().
-			DelWithPrefix(gomock.Any(), cache.Key(distributionListRecipientsKey)).
-			Return(nil)
+				if err == io.EOF {
+					return summary, nil
+				}
 
-		mock.Cache.
-			EXPECT().
-			DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
-			Return(nil)
+				if err != nil {
+					return nil, err
+				}
 
-		w := deleteDistributionList(testDL)
+				*imported = append(*imported, batch...)
+			}
+		}
+	}
 
-		assert.Equal(t, http.StatusNoContent, w.Code)
-	})
-}
+	summary := dto.DistributionListSummary{
+		Name:               dlName,
+		NumberOfRecipients: 3,
+	}
 
-func testGetDistributionLists(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
-	numLists := 3
-	summaries := testutils.MakeSummaries(testutils.MakeDistributionLists(numLists))
-	page := sdto.Page[dto.DistributionListSummary]{
-		NextToken:   nil,
-		PrevToken:   nil,
-		ResultCount: len(summaries),
-		Data:        summaries,
+	tests := []struct {
+		name             string
+		query            string
+		field            string
+		contents         string
+		setupMock        func(imported *[]string)
+		expectedCode     int
+		expectedError    string
+		expectedImported []string
+		expectedResp     *dto.RecipientsImportResult
+	}{
+		{
+			name:     "Success - Import CSV recipients",
+			query:    "format=CSV&mode=ADD",
+			field:    "file",
+			contents: "recipient\n1\n2\n\"\"\na\"b\n3\n",
+			setupMock: func(imported *[]string) {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					ImportRecipients(gomock.Any(), dlName, dto.RecipientsImportAdd, gomock.Any()).
+					DoAndReturn(readBatches(imported, &summary))
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode:     http.StatusOK,
+			expectedImported: []string{"1", "2", "3"},
+			expectedResp: &dto.RecipientsImportResult{
+				DistributionListSummary: summary,
+				Rows:                    6,
+				NumErrors:               2,
+				Errors: []dto.RecipientsImportError{
+					{Row: 4, Error: "recipient is empty"},
+					{Row: 5, Error: "bare \" in non-quoted-field"},
+				},
+			},
+		},
+		{
+			name:     "Success - Import NDJSON recipients",
+			query:    "format=NDJSON&mode=REMOVE",
+			field:    "file",
+			contents: "{\"recipient\": \"1\"}\n\n{\"recipient\"\n{\"recipient\": \"2\"}\n",
+			setupMock: func(imported *[]string) {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					ImportRecipients(gomock.Any(), dlName, dto.RecipientsImportRemove, gomock.Any()).
+					DoAndReturn(readBatches(imported, &summary))
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode:     http.StatusOK,
+			expectedImported: []string{"1", "2"},
+			expectedResp: &dto.RecipientsImportResult{
+				DistributionListSummary: summary,
+				Rows:                    4,
+				NumErrors:               1,
+				Errors: []dto.RecipientsImportError{
+					{Row: 3, Error: "invalid json - unexpected end of JSON input"},
+				},
+			},
+		},
+		{
+			name:     "Fail - Distribution list not found",
+			query:    "format=CSV&mode=REPLACE",
+			field:    "file",
+			contents: "1\n",
+			setupMock: func(imported *[]string) {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					ImportRecipients(gomock.Any(), dlName, dto.RecipientsImportReplace, gomock.Any()).
+					Return(nil, internal.EntityNotFound{
+						Id:   dlName,
+						Type: registry.DistributionListType,
+					})
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: fmt.Sprintf("entity %v of type %v not found", dlName, registry.DistributionListType),
+		},
+		{
+			name:     "Fail - Distribution list is a segment",
+			query:    "format=CSV&mode=ADD",
+			field:    "file",
+			contents: "1\n",
+			setupMock: func(imported *[]string) {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					ImportRecipients(gomock.Any(), dlName, dto.RecipientsImportAdd, gomock.Any()).
+					Return(nil, internal.DistributionListIsSegment{Name: dlName})
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("distribution list %v is a segment", dlName),
+		},
+		{
+			name:          "Fail - Missing file",
+			query:         "format=CSV&mode=ADD",
+			field:         "recipients",
+			contents:      "1\n",
+			setupMock:     func(imported *[]string) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "file is required",
+		},
+		{
+			name:          "Fail - Invalid mode",
+			query:         "format=CSV&mode=MERGE",
+			field:         "file",
+			contents:      "1\n",
+			setupMock:     func(imported *[]string) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Error:Field validation for 'Mode' failed on the 'oneof' tag",
+		},
+		{
+			name:          "Fail - Missing format",
+			query:         "mode=ADD",
+			field:         "file",
+			contents:      "1\n",
+			setupMock:     func(imported *[]string) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Error:Field validation for 'Format' failed on the 'required' tag",
+		},
 	}
 
-	getDistributionLists := func() *httptest.ResponseRecorder {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imported := []string{}
+			tt.setupMock(&imported)
+			w := importRecipients(tt.query, tt.field, tt.contents)
+			assert.Equal(t, tt.expectedCode, w.Code)
 
-		w := httptest.NewRecorder()
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Contains(t, resp["error"], tt.expectedError)
+			}
 
-		req, _ := http.NewRequest(http.MethodGet, distributionListUrl, nil)
-		req.Header.Add(string(auth.UserHeader), testUserId)
+			if tt.expectedResp != nil {
+				var resp dto.RecipientsImportResult
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Equal(t, tt.expectedResp, &resp)
+				assert.Equal(t, tt.expectedImported, imported)
+			}
+		})
+	}
+}
 
-		e.ServeHTTP(w, req)
+func testExportRecipients(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+	dlName := "Test"
 
+	exportRecipients := func(format dto.RecipientsFormat) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("%s/%s/recipients/export?format=%s", distributionListUrl, dlName, format)
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
 		return w
 	}
 
-	t.Run("Should be able to run retrieve the first page of distribution lists", func(t *testing.T) {
+	writeBatches := func(batches ...[]string) any {
+		return func(ctx context.Context, name string, write func([]string) error) error {
+			for _, b := range batches {
+				if err := write(b); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	t.Run("Should be able to export the recipients as CSV", func(t *testing.T) {
 		mock.Registry.MockDistributionRegistry.
 			EXPECT().
-			GetDistributionLists(gomock.Any(), gomock.Any()).
-			Return(page, nil)
+			ExportRecipients(gomock.Any(), dlName, gomock.Any()).
+			DoAndReturn(writeBatches([]string{"1", "2"}, []string{"3"}))
 
-		w := getDistributionLists()
+		w := exportRecipients(dto.RecipientsCSV)
 
-		resp := sdto.Page[dto.DistributionListSummary]{}
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="Test.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "recipient\n1\n2\n3\n", w.Body.String())
+	})
 
-		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
-			t.Fatal(err)
-		}
+	t.Run("Should be able to export the recipients as NDJSON", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			ExportRecipients(gomock.Any(), dlName, gomock.Any()).
+			DoAndReturn(writeBatches([]string{"1", "2"}))
 
-		assert.Equal(t, w.Code, http.StatusOK)
-		assert.Equal(t, page, resp)
-	})
-}
+		w := exportRecipients(dto.RecipientsNDJSON)
 
-func testGetDistributionListRescipients(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+		expected := "{\"recipient\":\"1\"}\n{\"recipient\":\"2\"}\n"
 
-	dlName := "Test"
-	recipients := testutils.MakeRecipients(10)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, expected, w.Body.String())
+	})
 
-	recipientsPage := sdto.Page[string]{
-		NextToken:   nil,
-		PrevToken:   nil,
-		ResultCount: len(recipients),
-		Data:        recipients,
-	}
+	t.Run("Should export an empty file if the list has no recipients", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			ExportRecipients(gomock.Any(), dlName, gomock.Any()).
+			DoAndReturn(writeBatches())
 
-	getRecipients := func(dlName string) *httptest.ResponseRecorder {
+		w := exportRecipients(dto.RecipientsCSV)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "recipient\n", w.Body.String())
+	})
+
+	t.Run("Should return 404 if the distribution list doesn't exists", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			ExportRecipients(gomock.Any(), dlName, gomock.Any()).
+			Return(internal.EntityNotFound{
+				Id:   dlName,
+				Type: registry.DistributionListType,
+			})
+
+		w := exportRecipients(dto.RecipientsCSV)
+
+		resp := map[string]string{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedMsg := fmt.Sprintf("entity %v of type %v not found",
+			dlName,
+			registry.DistributionListType,
+		)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, resp["error"], expectedMsg)
+	})
+}
+
+func testDeleteDistributionList(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+	testDL := "Test"
+
+	deleteDistributionList := func(dlName string) *httptest.ResponseRecorder {
 
 		w := httptest.NewRecorder()
 
-		url := fmt.Sprintf("%s/%s/recipients", distributionListUrl, dlName)
+		url := fmt.Sprintf("%s/%s", distributionListUrl, dlName)
 
-		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req, _ := http.NewRequest(http.MethodDelete, url, nil)
 		req.Header.Add(string(auth.UserHeader), testUserId)
 
 		e.ServeHTTP(w, req)
@@ -502,34 +944,87 @@ func testGetDistributionListRescipients(t *testing.T, e *gin.Engine, mock di.Moc
 		return w
 	}
 
-	t.Run("Should be able to retrieve the recipients of a distribution list", func(t *testing.T) {
+	t.Run("Should be able to delete a distribution list", func(t *testing.T) {
 		mock.Registry.MockDistributionRegistry.
 			EXPECT().
-			GetRecipients(gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(recipientsPage, nil)
+			DeleteDistributionList(gomock.Any(), gomock.Any()).
+			Return(nil)
 
-		w := getRecipients(dlName)
+		mock.Cache.
+			EXPECT().
+			DelWithPrefix(gomock.Any(), cache.Key(distributionListRecipientsKey)).
+			Return(nil)
 
-		resp := sdto.Page[string]{}
+		mock.Cache.
+			EXPECT().
+			DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+			Return(nil)
+
+		w := deleteDistributionList(testDL)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func makeTestDistributionListDetails(name string) dto.DistributionListDetails {
+	owner := testUserId
+	updatedAt := "2026-01-02T00:00:00Z"
+
+	return dto.DistributionListDetails{
+		DistributionListSummary: dto.DistributionListSummary{
+			Name:               name,
+			NumberOfRecipients: 3,
+		},
+		Description: "The test list",
+		Owner:       &owner,
+		Labels:      []string{"sre", "infra"},
+		CreatedAt:   "2026-01-01T00:00:00Z",
+		UpdatedAt:   &updatedAt,
+	}
+}
+
+func testGetDistributionList(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	dlName := "Test"
+	details := makeTestDistributionListDetails(dlName)
+
+	getDistributionList := func(dlName string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("%s/%s", distributionListUrl, dlName)
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Should be able to retrieve the details of a distribution list", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetDistributionListDetails(gomock.Any(), dlName).
+			Return(details, nil)
+
+		w := getDistributionList(dlName)
+
+		resp := dto.DistributionListDetails{}
 
 		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 			t.Fatal(err)
 		}
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, recipientsPage, resp)
+		assert.Equal(t, details, resp)
 	})
 
 	t.Run("Should return 404 if the distribution list doesn't exists", func(t *testing.T) {
 		mock.Registry.MockDistributionRegistry.
 			EXPECT().
-			GetRecipients(gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(sdto.Page[string]{}, internal.EntityNotFound{
+			GetDistributionListDetails(gomock.Any(), dlName).
+			Return(dto.DistributionListDetails{}, internal.EntityNotFound{
 				Id:   dlName,
 				Type: registry.DistributionListType,
 			})
 
-		w := getRecipients(dlName)
+		w := getDistributionList(dlName)
 
 		resp := map[string]string{}
 
@@ -546,3 +1041,868 @@ func testGetDistributionListRescipients(t *testing.T, e *gin.Engine, mock di.Moc
 		assert.Contains(t, resp["error"], expectedMsg)
 	})
 }
+
+func testUpdateDistributionList(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	dlName := "Test"
+	details := makeTestDistributionListDetails(dlName)
+
+	metadata := dto.DistributionListMetadata{
+		Description: details.Description,
+		Owner:       *details.Owner,
+		Labels:      details.Labels,
+	}
+
+	updateDistributionList := func(metadata dto.DistributionListMetadata) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		marshalled, _ := json.Marshal(metadata)
+		reader := bytes.NewReader(marshalled)
+		url := fmt.Sprintf("%s/%s", distributionListUrl, dlName)
+		req, _ := http.NewRequest(http.MethodPut, url, reader)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name          string
+		input         dto.DistributionListMetadata
+		setupMock     func()
+		expectedCode  int
+		expectedError string
+		expectedResp  *dto.DistributionListDetails
+	}{
+		{
+			name:  "Success - Update the metadata",
+			input: metadata,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					UpdateDistributionList(gomock.Any(), dlName, metadata).
+					Return(details, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: &details,
+		},
+		{
+			name:  "Fail - Distribution list not found",
+			input: metadata,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					UpdateDistributionList(gomock.Any(), dlName, metadata).
+					Return(dto.DistributionListDetails{}, internal.EntityNotFound{
+						Id:   dlName,
+						Type: registry.DistributionListType,
+					})
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: fmt.Sprintf("entity %v of type %v not found", dlName, registry.DistributionListType),
+		},
+		{
+			name:          "Fail - Missing owner",
+			input:         dto.DistributionListMetadata{Description: "The test list"},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Error:Field validation for 'Owner' failed on the 'required' tag",
+		},
+		{
+			name: "Fail - Too many labels",
+			input: dto.DistributionListMetadata{
+				Owner:  testUserId,
+				Labels: testutils.MakeRecipients(21),
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Error:Field validation for 'Labels' failed on the 'max' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+			w := updateDistributionList(tt.input)
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Contains(t, resp["error"], tt.expectedError)
+			}
+
+			if tt.expectedResp != nil {
+				var resp dto.DistributionListDetails
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Equal(t, tt.expectedResp, &resp)
+			}
+		})
+	}
+}
+
+func testRenameDistributionList(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	dlName := "Test"
+	newName := "Renamed"
+	details := makeTestDistributionListDetails(newName)
+
+	renameDistributionList := func(newName string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		marshalled, _ := json.Marshal(dto.DistributionListRename{Name: newName})
+		reader := bytes.NewReader(marshalled)
+		url := fmt.Sprintf("%s/%s/rename", distributionListUrl, dlName)
+		req, _ := http.NewRequest(http.MethodPost, url, reader)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name          string
+		newName       string
+		setupMock     func()
+		expectedCode  int
+		expectedError string
+		expectedResp  *dto.DistributionListDetails
+	}{
+		{
+			name:    "Success - Rename the distribution list",
+			newName: newName,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					RenameDistributionList(gomock.Any(), dlName, newName).
+					Return(details, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: &details,
+		},
+		{
+			name:    "Fail - Distribution list not found",
+			newName: newName,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					RenameDistributionList(gomock.Any(), dlName, newName).
+					Return(dto.DistributionListDetails{}, internal.EntityNotFound{
+						Id:   dlName,
+						Type: registry.DistributionListType,
+					})
+			},
+			expectedCode:  http.StatusNotFound,
+			expectedError: fmt.Sprintf("entity %v of type %v not found", dlName, registry.DistributionListType),
+		},
+		{
+			name:    "Fail - A list with the new name exists",
+			newName: newName,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					RenameDistributionList(gomock.Any(), dlName, newName).
+					Return(dto.DistributionListDetails{}, internal.DistributionListAlreadyExists{Name: newName})
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("distribution list %s already exists", newName),
+		},
+		{
+			name:    "Fail - The list is being renamed to another name",
+			newName: newName,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					RenameDistributionList(gomock.Any(), dlName, newName).
+					Return(dto.DistributionListDetails{}, internal.DistributionListRenaming{
+						Name:    dlName,
+						NewName: "Other",
+					})
+			},
+			expectedCode:  http.StatusConflict,
+			expectedError: fmt.Sprintf("distribution list %v is being renamed to Other", dlName),
+		},
+		{
+			name:          "Fail - Invalid name format",
+			newName:       "Invalid Name",
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Error:Field validation for 'Name' failed on the 'distributionlistname' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+			w := renameDistributionList(tt.newName)
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Contains(t, resp["error"], tt.expectedError)
+			}
+
+			if tt.expectedResp != nil {
+				var resp dto.DistributionListDetails
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Equal(t, tt.expectedResp, &resp)
+			}
+		})
+	}
+}
+
+func testGetDistributionLists(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+	numLists := 3
+	summaries := testutils.MakeSummaries(testutils.MakeDistributionLists(numLists))
+	page := sdto.Page[dto.DistributionListSummary]{
+		NextToken:   nil,
+		PrevToken:   nil,
+		ResultCount: len(summaries),
+		Data:        summaries,
+	}
+
+	getDistributionLists := func() *httptest.ResponseRecorder {
+
+		w := httptest.NewRecorder()
+
+		req, _ := http.NewRequest(http.MethodGet, distributionListUrl, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+
+		e.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("Should be able to run retrieve the first page of distribution lists", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetDistributionLists(gomock.Any(), gomock.Any()).
+			Return(page, nil)
+
+		w := getDistributionLists()
+
+		resp := sdto.Page[dto.DistributionListSummary]{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, w.Code, http.StatusOK)
+		assert.Equal(t, page, resp)
+	})
+
+	t.Run("Can filter the lists of a member", func(t *testing.T) {
+		member := "5678"
+		filters := dto.DistributionListFilters{Member: &member}
+
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetDistributionLists(gomock.Any(), filters).
+			Return(page, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, distributionListUrl+"?member=5678", nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func testRemoveMember(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	member := "5678"
+	lists := []string{"infra", "sre"}
+
+	removeMember := func(userId string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("%s/members/%s", distributionListUrl, userId)
+		req, _ := http.NewRequest(http.MethodDelete, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name          string
+		userId        string
+		setupMock     func()
+		expectedCode  int
+		expectedError string
+		expectedResp  *dto.DistributionListMembership
+	}{
+		{
+			name:   "Success - Remove the member from every list",
+			userId: member,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					DeleteRecipientFromLists(gomock.Any(), member).
+					Return(lists, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: &dto.DistributionListMembership{
+				Member:            member,
+				DistributionLists: lists,
+			},
+		},
+		{
+			name:   "Success - Member not on any list",
+			userId: member,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					DeleteRecipientFromLists(gomock.Any(), member).
+					Return([]string{}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: &dto.DistributionListMembership{
+				Member:            member,
+				DistributionLists: []string{},
+			},
+		},
+		{
+			name:   "Fail - Registry error",
+			userId: member,
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					DeleteRecipientFromLists(gomock.Any(), member).
+					Return(nil, errors.New("registry error"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+			w := removeMember(tt.userId)
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Contains(t, resp["error"], tt.expectedError)
+			}
+
+			if tt.expectedResp != nil {
+				var resp dto.DistributionListMembership
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Equal(t, tt.expectedResp, &resp)
+			}
+		})
+	}
+}
+
+func testGetDistributionListRescipients(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	dlName := "Test"
+	recipients := testutils.MakeRecipients(10)
+
+	recipientsPage := sdto.Page[string]{
+		NextToken:   nil,
+		PrevToken:   nil,
+		ResultCount: len(recipients),
+		Data:        recipients,
+	}
+
+	getRecipients := func(dlName string) *httptest.ResponseRecorder {
+
+		w := httptest.NewRecorder()
+
+		url := fmt.Sprintf("%s/%s/recipients", distributionListUrl, dlName)
+
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+
+		e.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("Should be able to retrieve the recipients of a distribution list", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetRecipients(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(recipientsPage, nil)
+
+		w := getRecipients(dlName)
+
+		resp := sdto.Page[string]{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, recipientsPage, resp)
+	})
+
+	t.Run("Should be able to retrieve the flattened recipients of a distribution list", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetFlattenedRecipients(gomock.Any(), dlName, gomock.Any()).
+			Return(recipientsPage, nil)
+
+		w := httptest.NewRecorder()
+
+		url := fmt.Sprintf("%s/%s/recipients?flatten=true", distributionListUrl, dlName)
+
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+
+		e.ServeHTTP(w, req)
+
+		resp := sdto.Page[string]{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, recipientsPage, resp)
+	})
+
+	t.Run("Should return 404 if the distribution list doesn't exists", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetRecipients(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(sdto.Page[string]{}, internal.EntityNotFound{
+				Id:   dlName,
+				Type: registry.DistributionListType,
+			})
+
+		w := getRecipients(dlName)
+
+		resp := map[string]string{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedMsg := fmt.Sprintf("entity %v of type %v not found",
+			dlName,
+			registry.DistributionListType,
+		)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, resp["error"], expectedMsg)
+	})
+}
+
+func testAddLists(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+	dlName := "Test"
+
+	addLists := func(dlName string, lists []string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		body := map[string][]string{"lists": lists}
+		marshalled, _ := json.Marshal(body)
+		reader := bytes.NewReader(marshalled)
+		url := fmt.Sprintf("%s/%s/lists", distributionListUrl, dlName)
+		req, _ := http.NewRequest(http.MethodPatch, url, reader)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name          string
+		lists         []string
+		setupMock     func()
+		expectedCode  int
+		expectedError string
+		expectedResp  *dto.DistributionListSummary
+	}{
+		{
+			name:  "Success - Add lists",
+			lists: []string{"sre", "infra"},
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					AddLists(gomock.Any(), dlName, []string{"sre", "infra"}).
+					Return(&dto.DistributionListSummary{
+						Name:               dlName,
+						NumberOfRecipients: 3,
+					}, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+					Return(nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: &dto.DistributionListSummary{
+				Name:               dlName,
+				NumberOfRecipients: 3,
+			},
+		},
+		{
+			name:  "Fail - Nested list creates a cycle",
+			lists: []string{"sre"},
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					AddLists(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, internal.DistributionListCycle{Name: dlName, List: "sre"})
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "distribution list Test can't include sre, it would create a cycle",
+		},
+		{
+			name:  "Fail - Nested list not found",
+			lists: []string{"sre"},
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					AddLists(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, internal.EntityNotFound{
+						Id:   "sre",
+						Type: registry.DistributionListType,
+					})
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("entity sre of type %v not found", registry.DistributionListType),
+		},
+		{
+			name:  "Fail - Distribution list is a segment",
+			lists: []string{"sre"},
+			setupMock: func() {
+				mock.Registry.MockDistributionRegistry.
+					EXPECT().
+					AddLists(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, internal.DistributionListIsSegment{Name: dlName})
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("distribution list %v is a segment", dlName),
+		},
+		{
+			name:          "Fail - Empty lists",
+			lists:         []string{},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Error:Field validation for 'Lists' failed on the 'min' tag",
+		},
+		{
+			name:          "Fail - Duplicate lists",
+			lists:         []string{"sre", "sre"},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Error:Field validation for 'Lists' failed on the 'unique' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+			w := addLists(dlName, tt.lists)
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Contains(t, resp["error"], tt.expectedError)
+			}
+
+			if tt.expectedResp != nil {
+				var resp dto.DistributionListSummary
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Equal(t, tt.expectedResp, &resp)
+			}
+		})
+	}
+}
+
+func testDeleteLists(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+	dlName := "Test"
+
+	deleteLists := func(dlName string, lists []string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		body := map[string][]string{"lists": lists}
+		marshalled, _ := json.Marshal(body)
+		reader := bytes.NewReader(marshalled)
+		url := fmt.Sprintf("%s/%s/lists", distributionListUrl, dlName)
+		req, _ := http.NewRequest(http.MethodDelete, url, reader)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Should be able to delete nested lists", func(t *testing.T) {
+		summary := dto.DistributionListSummary{
+			Name:               dlName,
+			NumberOfRecipients: 3,
+		}
+
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			DeleteLists(gomock.Any(), dlName, []string{"sre"}).
+			Return(&summary, nil)
+
+		mock.Cache.
+			EXPECT().
+			DelWithPrefix(gomock.Any(), cache.Key(distributionListKey)).
+			Return(nil)
+
+		w := deleteLists(dlName, []string{"sre"})
+
+		var resp dto.DistributionListSummary
+		json.Unmarshal(w.Body.Bytes(), &resp)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, summary, resp)
+	})
+
+	t.Run("Should fail if the distribution list doesn't exist", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			DeleteLists(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, internal.EntityNotFound{
+				Id:   dlName,
+				Type: registry.DistributionListType,
+			})
+
+		w := deleteLists(dlName, []string{"sre"})
+
+		resp := make(map[string]string)
+		json.Unmarshal(w.Body.Bytes(), &resp)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, resp["error"], fmt.Sprintf("entity %v of type %v not found", dlName, registry.DistributionListType))
+	})
+}
+
+func testGetDistributionListLists(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	dlName := "Test"
+
+	listsPage := sdto.Page[string]{
+		ResultCount: 2,
+		Data:        []string{"infra", "sre"},
+	}
+
+	getLists := func(dlName string) *httptest.ResponseRecorder {
+
+		w := httptest.NewRecorder()
+
+		url := fmt.Sprintf("%s/%s/lists", distributionListUrl, dlName)
+
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+
+		e.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("Should be able to retrieve the nested lists of a distribution list", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetLists(gomock.Any(), dlName, gomock.Any()).
+			Return(listsPage, nil)
+
+		w := getLists(dlName)
+
+		resp := sdto.Page[string]{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, listsPage, resp)
+	})
+
+	t.Run("Should return 404 if the distribution list doesn't exists", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetLists(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(sdto.Page[string]{}, internal.EntityNotFound{
+				Id:   dlName,
+				Type: registry.DistributionListType,
+			})
+
+		w := getLists(dlName)
+
+		resp := map[string]string{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, resp["error"], fmt.Sprintf("entity %v of type %v not found", dlName, registry.DistributionListType))
+	})
+}
+
+func testGetDistributionListSegment(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	dlName := "Test"
+
+	getSegment := func(dlName string) *httptest.ResponseRecorder {
+
+		w := httptest.NewRecorder()
+
+		url := fmt.Sprintf("%s/%s/segment", distributionListUrl, dlName)
+
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+
+		e.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("Should be able to retrieve the segment of a distribution list", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetSegment(gomock.Any(), dlName).
+			Return(makeTestSegment(), nil)
+
+		w := getSegment(dlName)
+
+		resp := dto.DistributionListSegment{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, makeTestSegment(), resp.Segment)
+	})
+
+	t.Run("Should return a null segment for lists with fixed recipients", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetSegment(gomock.Any(), dlName).
+			Return(nil, nil)
+
+		w := getSegment(dlName)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"segment": null}`, w.Body.String())
+	})
+
+	t.Run("Should return 404 if the distribution list doesn't exists", func(t *testing.T) {
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetSegment(gomock.Any(), dlName).
+			Return(nil, internal.EntityNotFound{
+				Id:   dlName,
+				Type: registry.DistributionListType,
+			})
+
+		w := getSegment(dlName)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func testPreviewSegment(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	previewSegment := func(req dto.SegmentPreviewReq) *httptest.ResponseRecorder {
+
+		w := httptest.NewRecorder()
+
+		marshalled, _ := json.Marshal(req)
+		url := fmt.Sprintf("%s/segments/preview", distributionListUrl)
+
+		httpReq, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(marshalled))
+		httpReq.Header.Add(string(auth.UserHeader), testUserId)
+
+		e.ServeHTTP(w, httpReq)
+
+		return w
+	}
+
+	preview := sdto.SegmentPreview{
+		Count:  3,
+		Sample: []string{"1", "2"},
+	}
+
+	tests := []struct {
+		name          string
+		input         dto.SegmentPreviewReq
+		setupMock     func()
+		expectedCode  int
+		expectedError string
+		expectedResp  *sdto.SegmentPreview
+	}{
+		{
+			name:  "Success - Preview with the default sample size",
+			input: dto.SegmentPreviewReq{Segment: *makeTestSegment()},
+			setupMock: func() {
+				mock.Audience.
+					EXPECT().
+					Preview(gomock.Any(), *makeTestSegment(), internal.SegmentSampleSize).
+					Return(preview, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: &preview,
+		},
+		{
+			name: "Success - Preview with a sample size",
+			input: dto.SegmentPreviewReq{
+				Segment:    *makeTestSegment(),
+				SampleSize: testutils.Ptr(2),
+			},
+			setupMock: func() {
+				mock.Audience.
+					EXPECT().
+					Preview(gomock.Any(), *makeTestSegment(), 2).
+					Return(preview, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: &preview,
+		},
+		{
+			name: "Fail - Sample size too large",
+			input: dto.SegmentPreviewReq{
+				Segment:    *makeTestSegment(),
+				SampleSize: testutils.Ptr(101),
+			},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "SegmentPreviewReq.SampleSize' Error:Field validation for 'SampleSize' failed on the 'max' tag",
+		},
+		{
+			name:          "Fail - Empty segment",
+			input:         dto.SegmentPreviewReq{},
+			setupMock:     func() {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "SegmentPreviewReq.Segment.Match' Error:Field validation for 'Match' failed on the 'required' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+			w := previewSegment(tt.input)
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Contains(t, resp["error"], tt.expectedError)
+			}
+
+			if tt.expectedResp != nil {
+				var resp sdto.SegmentPreview
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Equal(t, tt.expectedResp, &resp)
+			}
+		})
+	}
+}
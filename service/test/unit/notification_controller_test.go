@@ -9,10 +9,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/controllers"
 	di "github.com/notifique/service/internal/di"
 	"github.com/notifique/service/internal/dto"
 	"github.com/notifique/service/internal/registry"
@@ -42,36 +44,61 @@ func TestNotificationController(t *testing.T) {
 	testGetNotification(t, testApp.Engine, *testApp)
 	testUpdateNotificationStatus(t, testApp.Engine, testApp)
 	testGetNotificationStatus(t, testApp.Engine, testApp)
+	testGetNotificationStatusHistory(t, testApp.Engine, testApp)
+	testResendNotification(t, testApp.Engine, testApp)
 	testUpsertRecipientNotificationStatuses(t, testApp.Engine, testApp)
 }
 
 func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
-	mock.Publisher.
-		EXPECT().
-		Publish(gomock.Any(), gomock.Any()).
-		Return(nil).
-		AnyTimes()
-
 	registryMock := mock.Registry.MockNotificationRegistry
 	userId := "1234"
 
-	createNotification := func(notificationReq sdto.NotificationReq) *httptest.ResponseRecorder {
+	createNotification := func(notificationReq sdto.NotificationReq, idempotencyKey string) *httptest.ResponseRecorder {
 		body, _ := json.Marshal(notificationReq)
 		reader := bytes.NewReader(body)
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest(http.MethodPost, notificationsUrl, reader)
-		req.Header.Add("userId", userId)
+		req.Header.Add(string(auth.UserHeader), userId)
+
+		if idempotencyKey != "" {
+			req.Header.Add(controllers.IdempotencyKeyHeader, idempotencyKey)
+		}
+
 		e.ServeHTTP(w, req)
 		return w
 	}
 
+	testReqBody, _ := json.Marshal(testutils.MakeTestNotificationRequestRawContents())
+	testReqHash := internal.GetMd5Hash(string(testReqBody))
+	idempotencyKey := uuid.NewString()
+
+	createdResp := sdto.NotificationCreatedResp{
+		Id:        uuid.NewString(),
+		Status:    sdto.Created,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+
+	scheduledResp := createdResp
+	scheduledResp.Status = sdto.Scheduled
+
+	makeIdempotencyRecord := func(requestHash string) string {
+		record, _ := json.Marshal(map[string]any{
+			"requestHash": requestHash,
+			"statusCode":  http.StatusAccepted,
+			"response":    createdResp,
+		})
+		return string(record)
+	}
+
 	randomTemplateId := uuid.NewString()
 
 	tests := []struct {
 		name           string
 		setupMock      func()
 		modifyRequest  func(req sdto.NotificationReq) sdto.NotificationReq
+		idempotencyKey string
 		expectedStatus int
+		expectedResp   *sdto.NotificationCreatedResp
 		expectedError  string
 	}{
 		{
@@ -79,16 +106,11 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 			setupMock: func() {
 				registryMock.EXPECT().
 					SaveNotification(gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(uuid.NewString(), nil)
-
-				mock.Cache.
-					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", nil, false)
+					Return(createdResp, nil)
 
 				mock.Cache.
 					EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(nil).
-					Times(2)
+					Return(nil)
 			},
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				req.RawContents = &sdto.RawContents{
@@ -98,31 +120,27 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 				req.TemplateContents = nil
 				return req
 			},
-			expectedStatus: http.StatusNoContent,
+			expectedStatus: http.StatusAccepted,
+			expectedResp:   &createdResp,
 		},
 		{
 			name: "Can create new notifications with template contents",
 			setupMock: func() {
 
 				registryMock.EXPECT().
-					GetTemplateVariables(gomock.Any(), gomock.Any()).
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return([]sdto.TemplateVariable{
 						{Name: "{user}", Type: "STRING", Required: true},
 						{Name: "{date}", Type: "DATE", Required: true},
-					}, nil)
+					}, 1, nil)
 
 				registryMock.EXPECT().
 					SaveNotification(gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(uuid.NewString(), nil)
-
-				mock.Cache.
-					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", nil, false)
+					Return(createdResp, nil)
 
 				mock.Cache.
 					EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(nil).
-					Times(2)
+					Return(nil)
 			},
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				req.TemplateContents = &sdto.TemplateContents{
@@ -135,21 +153,57 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 				req.RawContents = nil
 				return req
 			},
-			expectedStatus: http.StatusNoContent,
+			expectedStatus: http.StatusAccepted,
+			expectedResp:   &createdResp,
+		},
+		{
+			name: "Can create scheduled notifications",
+			setupMock: func() {
+				registryMock.EXPECT().
+					SaveNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(scheduledResp, nil)
+
+				mock.Cache.
+					EXPECT().Set(gomock.Any(), gomock.Any(), string(sdto.Scheduled), gomock.Any()).
+					Return(nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				sendAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+				req.SendAt = &sendAt
+				return req
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedResp:   &scheduledResp,
+		},
+		{
+			name: "Should fail if the send at date is in the past",
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				sendAt := time.Now().Add(-time.Hour).Format(time.RFC3339)
+				req.SendAt = &sendAt
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `Key: 'NotificationReq.SendAt' Error:Field validation for 'SendAt' failed on the 'future' tag`,
+		},
+		{
+			name: "Should fail if the send at date is not in the RFC3339 format",
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				sendAt := "2030-01-01 10:00"
+				req.SendAt = &sendAt
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `Key: 'NotificationReq.SendAt' Error:Field validation for 'SendAt' failed on the 'datetime' tag`,
 		},
 		{
 			name: "Should fail when template doesn't exist",
 			setupMock: func() {
 				registryMock.
 					EXPECT().
-					GetTemplateVariables(gomock.Any(), gomock.Any()).
-					Return(nil, internal.EntityNotFound{
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, 0, internal.EntityNotFound{
 						Id: randomTemplateId, Type: registry.NotificationTemplateType,
 					})
-
-				mock.Cache.
-					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", nil, false)
 			},
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				req.TemplateContents = &sdto.TemplateContents{
@@ -166,14 +220,10 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 			name: "Should fail when template variable has invalid type",
 			setupMock: func() {
 				registryMock.EXPECT().
-					GetTemplateVariables(gomock.Any(), gomock.Any()).
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return([]sdto.TemplateVariable{
 						{Name: "{date}", Type: "DATE", Required: true},
-					}, nil)
-
-				mock.Cache.
-					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", nil, false)
+					}, 1, nil)
 			},
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				req.TemplateContents = &sdto.TemplateContents{
@@ -192,14 +242,10 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 			name: "Should fail when supplying a non-existing template variable",
 			setupMock: func() {
 				registryMock.EXPECT().
-					GetTemplateVariables(gomock.Any(), gomock.Any()).
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return([]sdto.TemplateVariable{
 						{Name: "{user}", Type: "STRING", Required: true},
-					}, nil)
-
-				mock.Cache.
-					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", nil, false)
+					}, 1, nil)
 			},
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				req.TemplateContents = &sdto.TemplateContents{
@@ -216,15 +262,11 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 			name: "Should fail when required template variable is missing",
 			setupMock: func() {
 				registryMock.EXPECT().
-					GetTemplateVariables(gomock.Any(), gomock.Any()).
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return([]sdto.TemplateVariable{
 						{Name: "{user}", Type: "STRING", Required: true},
 						{Name: "{date}", Type: "DATE", Required: true},
-					}, nil)
-
-				mock.Cache.
-					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", nil, false)
+					}, 1, nil)
 			},
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				req.TemplateContents = &sdto.TemplateContents{
@@ -242,14 +284,10 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 			setupMock: func() {
 				pattern := "^[A-Z]+$"
 				registryMock.EXPECT().
-					GetTemplateVariables(gomock.Any(), gomock.Any()).
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return([]sdto.TemplateVariable{
 						{Name: "{user}", Type: "STRING", Required: true, Validation: &pattern},
-					}, nil)
-
-				mock.Cache.
-					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", nil, false)
+					}, 1, nil)
 			},
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				req.TemplateContents = &sdto.TemplateContents{
@@ -262,6 +300,230 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "lowercase failed regex validation ^[A-Z]+$",
 		},
+		{
+			name: "Can create new notifications with recipient template variables",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{
+						{Name: "{user}", Type: "STRING", Required: true},
+						{Name: "{date}", Type: "DATE", Required: true},
+					}, 1, nil)
+
+				registryMock.EXPECT().
+					SaveNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(createdResp, nil)
+
+				mock.Cache.
+					EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id: uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{
+						{Name: "{user}", Value: "John"},
+						{Name: "{date}", Value: "2024-01-01"},
+					},
+					RecipientVariables: map[string][]sdto.TemplateVariableContents{
+						"1234": {{Name: "{user}", Value: "Jane"}},
+					},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedResp:   &createdResp,
+		},
+		{
+			name: "Should fail when an INTEGER variable is out of bounds",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{
+						{Name: "{items}", Type: "INTEGER", Required: true, Min: testutils.Ptr[float64](1), Max: testutils.Ptr[float64](10)},
+					}, 1, nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{{Name: "{items}", Value: "11"}},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "11 is greater than 10",
+		},
+		{
+			name: "Should fail when an ENUM variable isn't an allowed value",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{
+						{Name: "{plan}", Type: "ENUM", Required: true, Values: []string{"free", "pro"}},
+					}, 1, nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{{Name: "{plan}", Value: "enterprise"}},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "enterprise is not one of free, pro",
+		},
+		{
+			name: "Should fail when an EMAIL variable isn't an email",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{
+						{Name: "{email}", Type: "EMAIL", Required: true},
+					}, 1, nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{{Name: "{email}", Value: "not-an-email"}},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "not-an-email is not a valid email",
+		},
+		{
+			name: "Should fail when a CURRENCY variable has an unknown currency code",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{
+						{Name: "{total}", Type: "CURRENCY", Required: true},
+					}, 1, nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{{Name: "{total}", Value: "10.5 ABC"}},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ABC is not an ISO 4217 currency code",
+		},
+		{
+			name: "Should fail when a STRING variable is longer than its max length",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{
+						{Name: "{code}", Type: "STRING", Required: true, MaxLength: testutils.IntPtr(4)},
+					}, 1, nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{{Name: "{code}", Value: "ABCDE"}},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "ABCDE is longer than 4 characters",
+		},
+		{
+			name: "Should fail when the template is archived",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, 0, internal.TemplateArchived{Id: "archived"})
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "template archived is archived",
+		},
+		{
+			name: "Should fail when the template is archived while the notification is saved",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{}, 1, nil)
+
+				registryMock.EXPECT().
+					SaveNotification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(sdto.NotificationCreatedResp{}, internal.TemplateArchived{Id: "archived"})
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "template archived is archived",
+		},
+		{
+			name: "Should fail when a recipient template variable fails validation",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{
+						{Name: "{date}", Type: "DATE", Required: true},
+					}, 1, nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{{Name: "{date}", Value: "2024-01-01"}},
+					RecipientVariables: map[string][]sdto.TemplateVariableContents{
+						"1234": {{Name: "{date}", Value: "not-a-date"}},
+					},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "recipient 1234 - not-a-date is not a valid date",
+		},
+		{
+			name: "Should fail when a recipient template variable is supplied more than once",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]sdto.TemplateVariable{
+						{Name: "{user}", Type: "STRING", Required: true},
+					}, 1, nil)
+			},
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{{Name: "{user}", Value: "John"}},
+					RecipientVariables: map[string][]sdto.TemplateVariableContents{
+						"1234": {
+							{Name: "{user}", Value: "Jane"},
+							{Name: "{user}", Value: "Joe"},
+						},
+					},
+				}
+				req.RawContents = nil
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "recipient 1234 - {user} is supplied more than once",
+		},
 		{
 			name: "Should fail when neither raw contents nor template contents are provided",
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
@@ -393,28 +655,167 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 			expectedError:  "Key: 'NotificationReq.TemplateContents.Variables[0].Name' Error:Field validation for 'Name' failed on the 'templatevarname' tag",
 		},
 		{
-			name: "Should fail if notification duplication check fails",
+			name: "Should store the response of requests with an idempotency key",
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				return req
 			},
 			setupMock: func() {
+				key := cache.GetIdempotencyKey(userId, idempotencyKey)
+
 				mock.Cache.
-					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", errors.New("cache error"), false)
+					EXPECT().SetNX(gomock.Any(), key, gomock.Any(), controllers.IdempotencyKeyPendingTTL).
+					DoAndReturn(func(_ context.Context, _ cache.Key, value string, _ time.Duration) (bool, error) {
+						assert.Contains(t, value, testReqHash)
+						assert.Contains(t, value, `"pending":true`)
+						return true, nil
+					})
+
+				registryMock.EXPECT().
+					SaveNotification(gomock.Any(), userId, gomock.Any()).
+					Return(createdResp, nil)
+
+				mock.Cache.
+					EXPECT().Set(gomock.Any(), gomock.Any(), string(sdto.Created), gomock.Any()).
+					Return(nil)
+
+				mock.Cache.
+					EXPECT().Set(gomock.Any(), key, gomock.Any(), controllers.IdempotencyKeyTTL).
+					DoAndReturn(func(_ context.Context, _ cache.Key, value string, _ time.Duration) error {
+						assert.Contains(t, value, testReqHash)
+						assert.NotContains(t, value, "pending")
+						return nil
+					})
 			},
+			idempotencyKey: idempotencyKey,
+			expectedStatus: http.StatusAccepted,
+			expectedResp:   &createdResp,
+		},
+		{
+			name: "Should replay the response of a repeated idempotency key",
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				return req
+			},
+			setupMock: func() {
+				key := cache.GetIdempotencyKey(userId, idempotencyKey)
+
+				mock.Cache.
+					EXPECT().SetNX(gomock.Any(), key, gomock.Any(), gomock.Any()).
+					Return(false, nil)
+
+				mock.Cache.
+					EXPECT().Get(gomock.Any(), key).
+					Return(makeIdempotencyRecord(testReqHash), nil, true)
+			},
+			idempotencyKey: idempotencyKey,
+			expectedStatus: http.StatusAccepted,
+			expectedResp:   &createdResp,
+		},
+		{
+			name: "Should fail if the idempotency key was used with a different request",
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				req.Topic = "Another topic"
+				return req
+			},
+			setupMock: func() {
+				key := cache.GetIdempotencyKey(userId, idempotencyKey)
+
+				mock.Cache.
+					EXPECT().SetNX(gomock.Any(), key, gomock.Any(), gomock.Any()).
+					Return(false, nil)
+
+				mock.Cache.
+					EXPECT().Get(gomock.Any(), key).
+					Return(makeIdempotencyRecord(testReqHash), nil, true)
+			},
+			idempotencyKey: idempotencyKey,
+			expectedStatus: http.StatusConflict,
+			expectedError:  controllers.IdempotencyKeyReusedMsg,
+		},
+		{
+			name: "Should fail if a request with the same idempotency key is in progress",
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				return req
+			},
+			setupMock: func() {
+				key := cache.GetIdempotencyKey(userId, idempotencyKey)
+				record, _ := json.Marshal(map[string]any{
+					"requestHash": testReqHash,
+					"pending":     true,
+				})
+
+				mock.Cache.
+					EXPECT().SetNX(gomock.Any(), key, gomock.Any(), gomock.Any()).
+					Return(false, nil)
+
+				mock.Cache.
+					EXPECT().Get(gomock.Any(), key).
+					Return(string(record), nil, true)
+			},
+			idempotencyKey: idempotencyKey,
+			expectedStatus: http.StatusConflict,
+			expectedError:  controllers.IdempotencyKeyInProgressMsg,
+		},
+		{
+			name: "Should release the idempotency key if the notification can't be saved",
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				return req
+			},
+			setupMock: func() {
+				key := cache.GetIdempotencyKey(userId, idempotencyKey)
+
+				mock.Cache.
+					EXPECT().SetNX(gomock.Any(), key, gomock.Any(), gomock.Any()).
+					Return(true, nil)
+
+				registryMock.EXPECT().
+					SaveNotification(gomock.Any(), userId, gomock.Any()).
+					Return(sdto.NotificationCreatedResp{}, errors.New("registry error"))
+
+				mock.Cache.
+					EXPECT().Del(gomock.Any(), key).
+					Return(nil)
+			},
+			idempotencyKey: idempotencyKey,
 			expectedStatus: http.StatusInternalServerError,
 		},
 		{
-			name: "Should return no content if notification already exists",
+			name: "Should fail if the idempotency key is too long",
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				return req
+			},
+			idempotencyKey: testutils.MakeStrWithSize(256),
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  controllers.IdempotencyKeyTooLongMsg,
+		},
+		{
+			name: "Should fail if the idempotency key can't be reserved",
 			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
 				return req
 			},
 			setupMock: func() {
+				mock.Cache.
+					EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(false, errors.New("cache error"))
+			},
+			idempotencyKey: idempotencyKey,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "Should fail if the idempotency record can't be retrieved",
+			modifyRequest: func(req sdto.NotificationReq) sdto.NotificationReq {
+				return req
+			},
+			setupMock: func() {
+				mock.Cache.
+					EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(false, nil)
+
 				mock.Cache.
 					EXPECT().Get(gomock.Any(), gomock.Any()).
-					Return("", nil, true)
+					Return("", errors.New("cache error"), false)
 			},
-			expectedStatus: http.StatusNoContent,
+			idempotencyKey: idempotencyKey,
+			expectedStatus: http.StatusInternalServerError,
 		},
 	}
 
@@ -426,10 +827,21 @@ func testCreateNotification(t *testing.T, e *gin.Engine, mock di.MockedBackend)
 
 			req := testutils.MakeTestNotificationRequestRawContents()
 			req = tt.modifyRequest(req)
-			w := createNotification(req)
+			w := createNotification(req, tt.idempotencyKey)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 
+			if tt.expectedResp != nil {
+				var resp sdto.NotificationCreatedResp
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+
+				location := fmt.Sprintf("%s/%s", notificationsUrl, tt.expectedResp.Id)
+				assert.Equal(t, location, w.Header().Get("Location"))
+			}
+
 			if tt.expectedError != "" {
 				resp := make(map[string]string)
 				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
@@ -589,6 +1001,33 @@ func testCancelNotificationDelivery(t *testing.T, e *gin.Engine, mock di.MockedB
 					Times(1)
 			},
 		},
+		{
+			name:           "Can cancel a notification with SCHEDULED status",
+			notificationId: notificationId,
+			expectedStatus: 204,
+			setupMock: func() {
+
+				mock.Cache.
+					EXPECT().
+					Get(gomock.Any(), cache.GetNotificationStatusKey(notificationId)).
+					Return(string(sdto.Scheduled), nil, true).
+					Times(1)
+
+				mock.Cache.EXPECT().
+					Set(gomock.Any(),
+						cache.GetNotificationStatusKey(expectedStatusLog.NotificationId),
+						string(expectedStatusLog.Status),
+						gomock.Any()).
+					Return(nil).
+					Times(1)
+
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					UpdateNotificationStatus(gomock.Any(), expectedStatusLog).
+					Return(nil).
+					Times(1)
+			},
+		},
 		{
 			name:           "Should fail if the status of the notification is SENDING (status from cache)",
 			notificationId: notificationId,
@@ -701,18 +1140,31 @@ func testCancelNotificationDelivery(t *testing.T, e *gin.Engine, mock di.MockedB
 func testGetNotifications(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
 	userId := "1234"
 	registryMock := mock.Registry.MockNotificationRegistry
-	getNotifications := func(filters sdto.PageFilter) *httptest.ResponseRecorder {
+	getNotifications := func(filters dto.NotificationFilters) *httptest.ResponseRecorder {
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest(http.MethodGet, notificationsUrl, nil)
 		req.Header.Add("userId", userId)
-		testutils.AddPaginationFilters(req, &filters)
+		testutils.AddNotificationFilters(req, &filters)
 		e.ServeHTTP(w, req)
 		return w
 	}
 
+	allFilters := dto.NotificationFilters{
+		Topic:            testutils.StrPtr("Testing"),
+		Status:           testutils.StatusPtr(sdto.Sent),
+		Priority:         testutils.Ptr(sdto.High),
+		CreatedBy:        testutils.StrPtr(userId),
+		ContentsType:     testutils.Ptr(dto.Template),
+		TemplateId:       testutils.StrPtr(uuid.NewString()),
+		DistributionList: testutils.StrPtr("Managers"),
+		CreatedFrom:      testutils.StrPtr("2024-01-01T00:00:00Z"),
+		CreatedTo:        testutils.StrPtr("2024-12-31T23:59:59Z"),
+		SortOrder:        testutils.Ptr(dto.Ascending),
+	}
+
 	tests := []struct {
 		name           string
-		filters        sdto.PageFilter
+		filters        dto.NotificationFilters
 		setupMock      func()
 		expectedStatus int
 		expectedError  string
@@ -722,7 +1174,7 @@ func testGetNotifications(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
 			setupMock: func() {
 				registryMock.
 					EXPECT().
-					GetNotifications(gomock.Any(), sdto.PageFilter{}).
+					GetNotifications(gomock.Any(), dto.NotificationFilters{}).
 					Return(sdto.Page[dto.NotificationSummary]{
 						Data:        []dto.NotificationSummary{},
 						ResultCount: 0,
@@ -732,16 +1184,20 @@ func testGetNotifications(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
 		},
 		{
 			name: "Can get notifications with custom pagination",
-			filters: sdto.PageFilter{
-				NextToken:  testutils.StrPtr("token"),
-				MaxResults: testutils.IntPtr(20),
+			filters: dto.NotificationFilters{
+				PageFilter: sdto.PageFilter{
+					NextToken:  testutils.StrPtr("token"),
+					MaxResults: testutils.IntPtr(20),
+				},
 			},
 			setupMock: func() {
 				registryMock.
 					EXPECT().
-					GetNotifications(gomock.Any(), sdto.PageFilter{
-						NextToken:  testutils.StrPtr("token"),
-						MaxResults: testutils.IntPtr(20),
+					GetNotifications(gomock.Any(), dto.NotificationFilters{
+						PageFilter: sdto.PageFilter{
+							NextToken:  testutils.StrPtr("token"),
+							MaxResults: testutils.IntPtr(20),
+						},
 					}).
 					Return(sdto.Page[dto.NotificationSummary]{
 						Data:        []dto.NotificationSummary{},
@@ -750,13 +1206,63 @@ func testGetNotifications(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:    "Can filter and sort the notifications",
+			filters: allFilters,
+			setupMock: func() {
+				registryMock.
+					EXPECT().
+					GetNotifications(gomock.Any(), allFilters).
+					Return(sdto.Page[dto.NotificationSummary]{
+						Data:        []dto.NotificationSummary{},
+						ResultCount: 0,
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Should fail if the sort order is invalid",
+			filters: dto.NotificationFilters{
+				SortOrder: testutils.Ptr(dto.SortOrder("SIDEWAYS")),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Key: 'NotificationFilters.SortOrder' Error:Field validation for 'SortOrder' failed on the 'oneof' tag",
+		},
+		{
+			name: "Should fail if the creation date range is not a date",
+			filters: dto.NotificationFilters{
+				CreatedFrom: testutils.StrPtr("yesterday"),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Key: 'NotificationFilters.CreatedFrom' Error:Field validation for 'CreatedFrom' failed on the 'datetime' tag",
+		},
+		{
+			name: "Should fail if the creation date range ends before it starts",
+			filters: dto.NotificationFilters{
+				CreatedFrom: testutils.StrPtr("2024-12-31T00:00:00Z"),
+				CreatedTo:   testutils.StrPtr("2024-01-01T00:00:00Z"),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Key: 'NotificationFilters.CreatedTo' Error:Field validation for 'CreatedTo' failed on the 'notbefore' tag",
+		},
+		{
+			name: "Should fail if the end of the creation date range is not a date",
+			filters: dto.NotificationFilters{
+				CreatedFrom: testutils.StrPtr("2024-01-01T00:00:00Z"),
+				CreatedTo:   testutils.StrPtr("2024-12-31"),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Key: 'NotificationFilters.CreatedTo' Error:Field validation for 'CreatedTo' failed on the 'datetime' tag",
+		},
 		{
 			name: "Should fail if maxResults is less than 1",
-			filters: sdto.PageFilter{
-				MaxResults: testutils.IntPtr(0),
+			filters: dto.NotificationFilters{
+				PageFilter: sdto.PageFilter{
+					MaxResults: testutils.IntPtr(0),
+				},
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedError:  "Key: 'PageFilter.MaxResults' Error:Field validation for 'MaxResults' failed on the 'min' tag",
+			expectedError:  "Key: 'NotificationFilters.PageFilter.MaxResults' Error:Field validation for 'MaxResults' failed on the 'min' tag",
 		},
 		{
 			name: "Should fail if there's an error retrieving notifications",
@@ -1031,6 +1537,426 @@ func testGetNotificationStatus(t *testing.T, e *gin.Engine, mock *di.MockedBacke
 	}
 }
 
+func testGetNotificationStatusHistory(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
+	notificationId := uuid.NewString()
+	historyUrl := fmt.Sprintf("/notifications/%s/status/history", notificationId)
+
+	getHistory := func(filters sdto.PageFilter) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, historyUrl, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		testutils.AddPaginationFilters(req, &filters)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	errMsg := "broker is down"
+
+	history := sdto.Page[dto.NotificationStatusLogResp]{
+		ResultCount: 2,
+		Data: []dto.NotificationStatusLogResp{{
+			NotificationStatusLog: sdto.NotificationStatusLog{
+				NotificationId: notificationId,
+				Status:         sdto.Created,
+			},
+			StatusDate: "2024-01-01T00:00:00Z",
+		}, {
+			NotificationStatusLog: sdto.NotificationStatusLog{
+				NotificationId: notificationId,
+				Status:         sdto.Failed,
+				ErrorMsg:       &errMsg,
+			},
+			StatusDate: "2024-01-01T00:00:01Z",
+		}},
+	}
+
+	tests := []struct {
+		name          string
+		filters       sdto.PageFilter
+		expectedCode  int
+		expectedError string
+		mockSetup     func()
+	}{
+		{
+			name:         "Should get the status history of the notification",
+			filters:      sdto.PageFilter{MaxResults: testutils.IntPtr(2)},
+			expectedCode: http.StatusOK,
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotificationStatusLogs(gomock.Any(), notificationId, sdto.PageFilter{
+						MaxResults: testutils.IntPtr(2),
+					}).
+					Return(history, nil)
+			},
+		},
+		{
+			name:          "Should fail if maxResults is less than 1",
+			filters:       sdto.PageFilter{MaxResults: testutils.IntPtr(0)},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Key: 'PageFilter.MaxResults' Error:Field validation for 'MaxResults' failed on the 'min' tag",
+		},
+		{
+			name:          "Should return 404 when notification not found",
+			expectedCode:  http.StatusNotFound,
+			expectedError: "Notification not found",
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotificationStatusLogs(gomock.Any(), notificationId, gomock.Any()).
+					Return(sdto.Page[dto.NotificationStatusLogResp]{}, internal.EntityNotFound{
+						Id:   notificationId,
+						Type: registry.NotificationType,
+					})
+			},
+		},
+		{
+			name:         "Should fail if the history can't be retrieved",
+			expectedCode: http.StatusInternalServerError,
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotificationStatusLogs(gomock.Any(), notificationId, gomock.Any()).
+					Return(sdto.Page[dto.NotificationStatusLogResp]{}, errors.New("db error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			w := getHistory(tt.filters)
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], tt.expectedError)
+			} else if tt.expectedCode == http.StatusOK {
+				var resp sdto.Page[dto.NotificationStatusLogResp]
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, history, resp)
+			}
+		})
+	}
+}
+
+func testResendNotification(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
+	notificationId := uuid.NewString()
+	resendUrl := fmt.Sprintf("/notifications/%s/resend", notificationId)
+
+	resend := func(body any) *httptest.ResponseRecorder {
+		var reader *bytes.Reader = bytes.NewReader(nil)
+
+		if body != nil {
+			marshalled, _ := json.Marshal(body)
+			reader = bytes.NewReader(marshalled)
+		}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, resendUrl, reader)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	notificationReq := testutils.MakeTestNotificationRequestRawContents()
+
+	makeNotification := func(status sdto.NotificationStatus) dto.NotificationResp {
+		return dto.NotificationResp{
+			NotificationReq: notificationReq,
+			Id:              notificationId,
+			Status:          status,
+		}
+	}
+
+	nextToken := "next"
+
+	failedPages := []sdto.Page[sdto.RecipientNotificationStatus]{{
+		NextToken:   &nextToken,
+		ResultCount: 2,
+		Data: []sdto.RecipientNotificationStatus{{
+			UserId:  "user1",
+			Channel: string(sdto.Email),
+			Status:  string(sdto.Failed),
+		}, {
+			UserId:  "user2",
+			Channel: string(sdto.InApp),
+			Status:  string(sdto.Failed),
+		}},
+	}, {
+		ResultCount: 1,
+		Data: []sdto.RecipientNotificationStatus{{
+			UserId:  "user1",
+			Channel: string(sdto.InApp),
+			Status:  string(sdto.Failed),
+		}},
+	}}
+
+	expectFailedRecipients := func(channels []string) {
+		filters := sdto.NotificationRecipientStatusFilters{
+			Channels: channels,
+			Statuses: []string{string(sdto.Failed)},
+		}
+
+		firstPage := mock.Registry.MockNotificationRegistry.
+			EXPECT().
+			GetRecipientNotificationStatuses(gomock.Any(), notificationId, filters).
+			Return(failedPages[0], nil)
+
+		filters.NextToken = &nextToken
+
+		mock.Registry.MockNotificationRegistry.
+			EXPECT().
+			GetRecipientNotificationStatuses(gomock.Any(), notificationId, filters).
+			Return(failedPages[1], nil).
+			After(firstPage)
+	}
+
+	expectStatusCached := func() {
+		mock.Cache.EXPECT().
+			Set(gomock.Any(),
+				cache.GetNotificationStatusKey(notificationId),
+				string(sdto.Created),
+				gomock.Any()).
+			Return(nil)
+	}
+
+	makePayloadMatcher := func(recipients []string, channels []sdto.NotificationChannel) gomock.Matcher {
+		return gomock.Cond(func(x any) bool {
+			payload, ok := x.(sdto.NotificationMsgPayload)
+
+			if !ok {
+				return false
+			}
+
+			expected := notificationReq
+			expected.Recipients = recipients
+			expected.Channels = channels
+			expected.DistributionList = nil
+			expected.SendAt = nil
+
+			return payload.Id == notificationId &&
+				payload.Hash != internal.GetMd5Hash(notificationId) &&
+				assert.ObjectsAreEqual(expected, payload.NotificationReq)
+		})
+	}
+
+	tests := []struct {
+		name          string
+		body          any
+		expectedCode  int
+		expectedResp  *dto.NotificationResendResp
+		expectedError string
+		mockSetup     func()
+	}{
+		{
+			name:         "Should resend the notification to every failed recipient",
+			expectedCode: http.StatusAccepted,
+			expectedResp: &dto.NotificationResendResp{
+				Recipients: []string{"user1", "user2"},
+				Channels:   []sdto.NotificationChannel{sdto.Email, sdto.InApp},
+			},
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotification(gomock.Any(), notificationId).
+					Return(makeNotification(sdto.Failed), nil)
+
+				expectFailedRecipients([]string{})
+
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					ResendNotification(gomock.Any(), makePayloadMatcher(
+						[]string{"user1", "user2"},
+						[]sdto.NotificationChannel{sdto.Email, sdto.InApp})).
+					Return(nil)
+
+				expectStatusCached()
+			},
+		},
+		{
+			name: "Should resend the notification to the failed recipients that match the filters",
+			body: dto.NotificationResendReq{
+				Channels: []sdto.NotificationChannel{sdto.InApp},
+				UserIds:  []string{"user1"},
+			},
+			expectedCode: http.StatusAccepted,
+			expectedResp: &dto.NotificationResendResp{
+				Recipients: []string{"user1"},
+				Channels:   []sdto.NotificationChannel{sdto.Email, sdto.InApp},
+			},
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotification(gomock.Any(), notificationId).
+					Return(makeNotification(sdto.Sent), nil)
+
+				// The registry filters the channels, the mock returns
+				// the same pages regardless
+				expectFailedRecipients([]string{string(sdto.InApp)})
+
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					ResendNotification(gomock.Any(), makePayloadMatcher(
+						[]string{"user1"},
+						[]sdto.NotificationChannel{sdto.Email, sdto.InApp})).
+					Return(nil)
+
+				expectStatusCached()
+			},
+		},
+		{
+			name: "Should fail if the channel is invalid",
+			body: dto.NotificationResendReq{
+				Channels: []sdto.NotificationChannel{"pigeon"},
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Key: 'NotificationResendReq.Channels[0]' Error:Field validation for 'Channels[0]' failed on the 'oneof' tag",
+		},
+		{
+			name:          "Should return 404 when notification not found",
+			expectedCode:  http.StatusNotFound,
+			expectedError: "Notification not found",
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotification(gomock.Any(), notificationId).
+					Return(dto.NotificationResp{}, internal.EntityNotFound{
+						Id:   notificationId,
+						Type: registry.NotificationType,
+					})
+			},
+		},
+		{
+			name:          "Should fail if the notification hasn't been processed",
+			expectedCode:  http.StatusBadRequest,
+			expectedError: controllers.NotificationNotProcessedMsg,
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotification(gomock.Any(), notificationId).
+					Return(makeNotification(sdto.Sending), nil)
+			},
+		},
+		{
+			name: "Should fail if there are no failed recipients",
+			body: dto.NotificationResendReq{
+				UserIds: []string{"user3"},
+			},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: controllers.NoFailedRecipientsMsg,
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotification(gomock.Any(), notificationId).
+					Return(makeNotification(sdto.Failed), nil)
+
+				expectFailedRecipients([]string{})
+			},
+		},
+		{
+			name:          "Should fail if there are more failed recipients than a notification can have",
+			expectedCode:  http.StatusBadRequest,
+			expectedError: controllers.TooManyFailedRecipientsMsg,
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotification(gomock.Any(), notificationId).
+					Return(makeNotification(sdto.Failed), nil)
+
+				failed := make([]sdto.RecipientNotificationStatus, 0, 257)
+
+				for i := range 257 {
+					failed = append(failed, sdto.RecipientNotificationStatus{
+						UserId:  fmt.Sprintf("user%d", i),
+						Channel: string(sdto.Email),
+						Status:  string(sdto.Failed),
+					})
+				}
+
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetRecipientNotificationStatuses(gomock.Any(), notificationId, gomock.Any()).
+					Return(sdto.Page[sdto.RecipientNotificationStatus]{
+						ResultCount: len(failed),
+						Data:        failed,
+					}, nil)
+			},
+		},
+		{
+			name:          "Should fail if the status has changed before resending",
+			expectedCode:  http.StatusBadRequest,
+			expectedError: controllers.NotificationNotProcessedMsg,
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotification(gomock.Any(), notificationId).
+					Return(makeNotification(sdto.Failed), nil)
+
+				expectFailedRecipients([]string{})
+
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					ResendNotification(gomock.Any(), gomock.Any()).
+					Return(internal.InvalidNotificationStatus{
+						Id:     notificationId,
+						Status: string(sdto.Created),
+					})
+			},
+		},
+		{
+			name:         "Should fail if the notification can't be resent",
+			expectedCode: http.StatusInternalServerError,
+			mockSetup: func() {
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					GetNotification(gomock.Any(), notificationId).
+					Return(makeNotification(sdto.Failed), nil)
+
+				expectFailedRecipients([]string{})
+
+				mock.Registry.MockNotificationRegistry.
+					EXPECT().
+					ResendNotification(gomock.Any(), gomock.Any()).
+					Return(errors.New("db error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			w := resend(tt.body)
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], tt.expectedError)
+			} else if tt.expectedResp != nil {
+				var resp dto.NotificationResendResp
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
 func testUpsertRecipientNotificationStatuses(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
 
 	notificationId := uuid.NewString()
@@ -0,0 +1,434 @@
+package unit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/notifique/service/internal"
+	di "github.com/notifique/service/internal/di"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/registry"
+	"github.com/notifique/service/internal/testutils"
+	"github.com/notifique/shared/auth"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const notificationSchedulesUrl = "/notifications/schedules"
+
+func TestNotificationScheduleController(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	testApp, err := di.InjectMockedBackend(context.TODO(), controller)
+
+	if err != nil {
+		t.Fatalf("failed to create mocked backend - %v", err)
+	}
+
+	testCreateNotificationSchedule(t, testApp.Engine, *testApp)
+	testUpdateNotificationSchedule(t, testApp.Engine, *testApp)
+	testGetNotificationSchedule(t, testApp.Engine, *testApp)
+	testPauseResumeNotificationSchedule(t, testApp.Engine, *testApp)
+	testPreviewNotificationSchedule(t, testApp.Engine, *testApp)
+}
+
+func makeScheduleResp(req dto.NotificationScheduleReq) dto.NotificationScheduleResp {
+	nextRunAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	return dto.NotificationScheduleResp{
+		NotificationScheduleReq: req,
+		Id:                      uuid.NewString(),
+		NextRunAt:               &nextRunAt,
+		CreatedAt:               time.Now().Format(time.RFC3339),
+		CreatedBy:               testUserId,
+	}
+}
+
+func testCreateNotificationSchedule(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationScheduleRegistry
+	notificationRegistryMock := mock.Registry.MockNotificationRegistry
+
+	createSchedule := func(req dto.NotificationScheduleReq) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(http.MethodPost, notificationSchedulesUrl, bytes.NewReader(body))
+		httpReq.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		modifyRequest  func(req dto.NotificationScheduleReq) dto.NotificationScheduleReq
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Can create a notification schedule",
+			setupMock: func() {
+				registryMock.
+					EXPECT().
+					SaveSchedule(gomock.Any(), testUserId, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, req dto.NotificationScheduleReq, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+						assert.True(t, nextRunAt.After(time.Now()))
+						return makeScheduleResp(req), nil
+					})
+			},
+			modifyRequest:  testutils.Echo[dto.NotificationScheduleReq],
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Should default the timezone to UTC",
+			setupMock: func() {
+				registryMock.
+					EXPECT().
+					SaveSchedule(gomock.Any(), testUserId, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, req dto.NotificationScheduleReq, _ time.Time) (dto.NotificationScheduleResp, error) {
+						assert.Equal(t, internal.DefaultScheduleTimezone, req.Timezone)
+						return makeScheduleResp(req), nil
+					})
+			},
+			modifyRequest: func(req dto.NotificationScheduleReq) dto.NotificationScheduleReq {
+				req.Timezone = ""
+				return req
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:      "Should fail if the cron expression is invalid",
+			setupMock: func() {},
+			modifyRequest: func(req dto.NotificationScheduleReq) dto.NotificationScheduleReq {
+				req.CronExpression = "every monday"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Error:Field validation for 'CronExpression' failed on the 'cron' tag",
+		},
+		{
+			name:      "Should fail if the timezone is invalid",
+			setupMock: func() {},
+			modifyRequest: func(req dto.NotificationScheduleReq) dto.NotificationScheduleReq {
+				req.Timezone = "Mars/Olympus_Mons"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Error:Field validation for 'Timezone' failed on the 'timezone' tag",
+		},
+		{
+			name:      "Should fail if the notification has a send at time",
+			setupMock: func() {},
+			modifyRequest: func(req dto.NotificationScheduleReq) dto.NotificationScheduleReq {
+				sendAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+				req.Notification.SendAt = &sendAt
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "sendAt is not supported by notification schedules",
+		},
+		{
+			name:      "Should fail if the notification is invalid",
+			setupMock: func() {},
+			modifyRequest: func(req dto.NotificationScheduleReq) dto.NotificationScheduleReq {
+				req.Notification.Topic = ""
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Error:Field validation for 'Topic' failed on the 'required' tag",
+		},
+		{
+			name: "Should fail if the template doesn't exist",
+			setupMock: func() {
+				notificationRegistryMock.
+					EXPECT().
+					GetTemplateVariables(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, 0, internal.EntityNotFound{
+						Type: registry.NotificationTemplateType,
+					})
+			},
+			modifyRequest: func(req dto.NotificationScheduleReq) dto.NotificationScheduleReq {
+				req.Notification.RawContents = nil
+				req.Notification.TemplateContents = &sdto.TemplateContents{
+					Id:        uuid.NewString(),
+					Variables: []sdto.TemplateVariableContents{},
+				}
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  fmt.Sprintf("of type %s not found", registry.NotificationTemplateType),
+		},
+		{
+			name: "Should fail if the schedule can't be saved",
+			setupMock: func() {
+				registryMock.
+					EXPECT().
+					SaveSchedule(gomock.Any(), testUserId, gomock.Any(), gomock.Any()).
+					Return(dto.NotificationScheduleResp{}, errors.New("registry error"))
+			},
+			modifyRequest:  testutils.Echo[dto.NotificationScheduleReq],
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			req := tt.modifyRequest(testutils.MakeTestNotificationScheduleRequest())
+			w := createSchedule(req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != "" {
+				resp := make(map[string]string)
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				assert.Contains(t, resp["error"], tt.expectedError)
+			}
+		})
+	}
+}
+
+func testUpdateNotificationSchedule(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationScheduleRegistry
+
+	updateSchedule := func(id string, req dto.NotificationScheduleReq) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("%s/%s", notificationSchedulesUrl, id)
+		httpReq, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+		httpReq.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("Can update a notification schedule", func(t *testing.T) {
+		req := testutils.MakeTestNotificationScheduleRequest()
+		resp := makeScheduleResp(req)
+
+		registryMock.
+			EXPECT().
+			UpdateSchedule(gomock.Any(), resp.Id, testUserId, req, gomock.Any()).
+			Return(resp, nil)
+
+		w := updateSchedule(resp.Id, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var updated dto.NotificationScheduleResp
+		json.Unmarshal(w.Body.Bytes(), &updated)
+		assert.Equal(t, resp, updated)
+	})
+
+	t.Run("Should fail if the schedule doesn't exist", func(t *testing.T) {
+		req := testutils.MakeTestNotificationScheduleRequest()
+		id := uuid.NewString()
+
+		registryMock.
+			EXPECT().
+			UpdateSchedule(gomock.Any(), id, testUserId, req, gomock.Any()).
+			Return(dto.NotificationScheduleResp{}, internal.EntityNotFound{
+				Id:   id,
+				Type: registry.NotificationScheduleType,
+			})
+
+		w := updateSchedule(id, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func testGetNotificationSchedule(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationScheduleRegistry
+
+	getSchedule := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("%s/%s", notificationSchedulesUrl, id)
+		httpReq, _ := http.NewRequest(http.MethodGet, url, nil)
+		httpReq.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("Can get a notification schedule", func(t *testing.T) {
+		resp := makeScheduleResp(testutils.MakeTestNotificationScheduleRequest())
+
+		registryMock.
+			EXPECT().
+			GetSchedule(gomock.Any(), resp.Id).
+			Return(resp, nil)
+
+		w := getSchedule(resp.Id)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var schedule dto.NotificationScheduleResp
+		json.Unmarshal(w.Body.Bytes(), &schedule)
+		assert.Equal(t, resp, schedule)
+	})
+
+	t.Run("Should fail if the id is not an uuid", func(t *testing.T) {
+		w := getSchedule("not-an-uuid")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Should fail if the schedule doesn't exist", func(t *testing.T) {
+		id := uuid.NewString()
+
+		registryMock.
+			EXPECT().
+			GetSchedule(gomock.Any(), id).
+			Return(dto.NotificationScheduleResp{}, internal.EntityNotFound{
+				Id:   id,
+				Type: registry.NotificationScheduleType,
+			})
+
+		w := getSchedule(id)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func testPauseResumeNotificationSchedule(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationScheduleRegistry
+
+	postAction := func(id, action string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("%s/%s/%s", notificationSchedulesUrl, id, action)
+		httpReq, _ := http.NewRequest(http.MethodPost, url, nil)
+		httpReq.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("Can pause a notification schedule", func(t *testing.T) {
+		resp := makeScheduleResp(testutils.MakeTestNotificationScheduleRequest())
+		resp.Paused = true
+
+		registryMock.
+			EXPECT().
+			PauseSchedule(gomock.Any(), resp.Id, testUserId).
+			Return(resp, nil)
+
+		w := postAction(resp.Id, "pause")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Can resume a notification schedule", func(t *testing.T) {
+		resp := makeScheduleResp(testutils.MakeTestNotificationScheduleRequest())
+		resp.Paused = true
+
+		registryMock.
+			EXPECT().
+			GetSchedule(gomock.Any(), resp.Id).
+			Return(resp, nil)
+
+		registryMock.
+			EXPECT().
+			ResumeSchedule(gomock.Any(), resp.Id, testUserId, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, nextRunAt time.Time) (dto.NotificationScheduleResp, error) {
+				// 0 9 * * MON
+				assert.Equal(t, time.Monday, nextRunAt.Weekday())
+				assert.Equal(t, 9, nextRunAt.Hour())
+				assert.True(t, nextRunAt.After(time.Now()))
+				resp.Paused = false
+				return resp, nil
+			})
+
+		w := postAction(resp.Id, "resume")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Should fail to resume a schedule that doesn't exist", func(t *testing.T) {
+		id := uuid.NewString()
+
+		registryMock.
+			EXPECT().
+			GetSchedule(gomock.Any(), id).
+			Return(dto.NotificationScheduleResp{}, internal.EntityNotFound{
+				Id:   id,
+				Type: registry.NotificationScheduleType,
+			})
+
+		w := postAction(id, "resume")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func testPreviewNotificationSchedule(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationScheduleRegistry
+
+	previewSchedule := func(id string, count *int) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		url := fmt.Sprintf("%s/%s/preview", notificationSchedulesUrl, id)
+		httpReq, _ := http.NewRequest(http.MethodGet, url, nil)
+		httpReq.Header.Add(string(auth.UserHeader), testUserId)
+
+		if count != nil {
+			q := httpReq.URL.Query()
+			q.Add("count", fmt.Sprint(*count))
+			httpReq.URL.RawQuery = q.Encode()
+		}
+
+		e.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("Can preview the next runs of a schedule", func(t *testing.T) {
+		req := testutils.MakeTestNotificationScheduleRequest()
+		req.CronExpression = "@daily"
+		req.Timezone = "UTC"
+		resp := makeScheduleResp(req)
+
+		registryMock.
+			EXPECT().
+			GetSchedule(gomock.Any(), resp.Id).
+			Return(resp, nil)
+
+		w := previewSchedule(resp.Id, testutils.IntPtr(3))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var preview dto.NotificationSchedulePreviewResp
+		json.Unmarshal(w.Body.Bytes(), &preview)
+
+		if !assert.Len(t, preview.NextRuns, 3) {
+			return
+		}
+
+		for i, r := range preview.NextRuns {
+			run, err := time.Parse(time.RFC3339, r)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, run.Hour())
+
+			if i > 0 {
+				prev, _ := time.Parse(time.RFC3339, preview.NextRuns[i-1])
+				assert.Equal(t, 24*time.Hour, run.Sub(prev))
+			}
+		}
+	})
+
+	t.Run("Should fail if the count is out of bounds", func(t *testing.T) {
+		w := previewSchedule(uuid.NewString(), testutils.IntPtr(100))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
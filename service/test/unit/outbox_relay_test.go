@@ -0,0 +1,212 @@
+package unit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/outbox"
+	"github.com/notifique/service/internal/registry"
+	"github.com/notifique/service/internal/testutils"
+	"github.com/notifique/service/internal/testutils/mocks"
+	sdto "github.com/notifique/shared/dto"
+)
+
+type relayMocks struct {
+	Registry     *mocks.MockOutboxRegistry
+	Publisher    *mocks.MockNotificationPublisher
+	Configurator *mocks.MockRelayConfigurator
+}
+
+func TestOutboxRelay(t *testing.T) {
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	m := relayMocks{
+		Registry:     mocks.NewMockOutboxRegistry(controller),
+		Publisher:    mocks.NewMockNotificationPublisher(controller),
+		Configurator: mocks.NewMockRelayConfigurator(controller),
+	}
+
+	m.Configurator.
+		EXPECT().
+		GetOutboxRelayInterval().
+		Return(time.Second, nil)
+
+	r, err := outbox.NewRelay(outbox.RelayCfg{
+		Registry:     m.Registry,
+		Publisher:    m.Publisher,
+		Configurator: m.Configurator,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create relay - %v", err)
+	}
+
+	makeEntry := func(attempts int) dto.OutboxEntry {
+		id := uuid.NewString()
+
+		return dto.OutboxEntry{
+			Payload: sdto.NotificationMsgPayload{
+				NotificationReq: testutils.MakeTestNotificationRequestRawContents(),
+				Id:              id,
+				Hash:            internal.GetMd5Hash(id),
+			},
+			Attempts:    attempts,
+			AvailableAt: time.Now().Format(time.RFC3339),
+		}
+	}
+
+	expectEntries := func(entries ...dto.OutboxEntry) {
+		m.Registry.
+			EXPECT().
+			GetOutboxEntries(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return(entries, nil)
+	}
+
+	expectClaim := func(entry dto.OutboxEntry, claimed bool) {
+		m.Registry.
+			EXPECT().
+			ClaimOutboxEntry(gomock.Any(), entry.Payload.Id, entry.Attempts, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ int, leaseUntil time.Time) (bool, error) {
+				assert.True(t, leaseUntil.After(time.Now()))
+				return claimed, nil
+			})
+	}
+
+	expectStatus := func(entry dto.OutboxEntry, status sdto.NotificationStatus, err error) {
+		m.Registry.
+			EXPECT().
+			GetNotificationStatus(gomock.Any(), entry.Payload.Id).
+			Return(status, err)
+	}
+
+	t.Run("Should publish and remove the available entries", func(t *testing.T) {
+		entry := makeEntry(0)
+
+		expectEntries(entry)
+		expectClaim(entry, true)
+		expectStatus(entry, sdto.Created, nil)
+
+		m.Publisher.
+			EXPECT().
+			Publish(gomock.Any(), entry.Payload).
+			Return(nil)
+
+		m.Registry.
+			EXPECT().
+			DeleteOutboxEntry(gomock.Any(), entry.Payload.Id).
+			Return(nil)
+
+		err := r.RelayOutbox(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should skip entries claimed by another relay", func(t *testing.T) {
+		entry := makeEntry(0)
+
+		expectEntries(entry)
+		expectClaim(entry, false)
+
+		err := r.RelayOutbox(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should drop entries of canceled or deleted notifications", func(t *testing.T) {
+		canceled := makeEntry(0)
+		deleted := makeEntry(0)
+
+		expectEntries(canceled, deleted)
+		expectClaim(canceled, true)
+		expectClaim(deleted, true)
+		expectStatus(canceled, sdto.Canceled, nil)
+		expectStatus(deleted, "", internal.EntityNotFound{
+			Id:   deleted.Payload.Id,
+			Type: registry.NotificationType,
+		})
+
+		m.Registry.
+			EXPECT().
+			DeleteOutboxEntry(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(2)
+
+		err := r.RelayOutbox(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should retry the entries that fail to be published", func(t *testing.T) {
+		entry := makeEntry(2)
+
+		expectEntries(entry)
+		expectClaim(entry, true)
+		expectStatus(entry, sdto.Created, nil)
+
+		m.Publisher.
+			EXPECT().
+			Publish(gomock.Any(), entry.Payload).
+			Return(errors.New("broker is down"))
+
+		m.Registry.
+			EXPECT().
+			RetryOutboxEntry(gomock.Any(), entry.Payload.Id, gomock.Any(), "broker is down").
+			DoAndReturn(func(_ context.Context, _ string, retryAt time.Time, _ string) error {
+				// The delay doubles on every attempt
+				assert.True(t, retryAt.After(time.Now().Add(3*time.Second)))
+				return nil
+			})
+
+		err := r.RelayOutbox(context.TODO())
+		assert.ErrorContains(t, err, "broker is down")
+	})
+
+	t.Run("Should fail the notification once the attempts are exhausted", func(t *testing.T) {
+		entry := makeEntry(outbox.MaxPublishAttempts - 1)
+		publishErr := errors.New("broker is down")
+		errMsg := publishErr.Error()
+
+		expectEntries(entry)
+		expectClaim(entry, true)
+		expectStatus(entry, sdto.Created, nil)
+
+		m.Publisher.
+			EXPECT().
+			Publish(gomock.Any(), entry.Payload).
+			Return(publishErr)
+
+		m.Registry.
+			EXPECT().
+			UpdateNotificationStatus(gomock.Any(), sdto.NotificationStatusLog{
+				NotificationId: entry.Payload.Id,
+				Status:         sdto.Failed,
+				ErrorMsg:       &errMsg,
+			}).
+			Return(nil)
+
+		m.Registry.
+			EXPECT().
+			DeleteOutboxEntry(gomock.Any(), entry.Payload.Id).
+			Return(nil)
+
+		err := r.RelayOutbox(context.TODO())
+		assert.ErrorContains(t, err, "broker is down")
+	})
+
+	t.Run("Should fail if the entries can't be retrieved", func(t *testing.T) {
+		m.Registry.
+			EXPECT().
+			GetOutboxEntries(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return(nil, errors.New("registry error"))
+
+		err := r.RelayOutbox(context.TODO())
+		assert.ErrorContains(t, err, "registry error")
+	})
+}
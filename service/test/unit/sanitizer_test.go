@@ -0,0 +1,173 @@
+package unit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/notifique/service/internal/controllers"
+	"github.com/notifique/service/internal/routes"
+	"github.com/notifique/service/internal/sanitizer"
+	"github.com/notifique/service/internal/testutils"
+	tcfg "github.com/notifique/service/internal/testutils/config"
+	"github.com/notifique/service/internal/testutils/mocks"
+	sdto "github.com/notifique/shared/dto"
+)
+
+func TestSanitizer(t *testing.T) {
+
+	ugc := sanitizer.NewSanitizer(sanitizer.Config{})
+
+	email := sanitizer.NewSanitizer(sanitizer.Config{
+		AllowedElements:   []string{"center"},
+		AllowedAttributes: []string{"td.bgcolor", "align"},
+		AllowedStyles:     []string{"color", "font-size"},
+	})
+
+	tests := []struct {
+		name             string
+		sanitizer        *sanitizer.Sanitizer
+		contents         string
+		expectedContents string
+		expectedStripped []sdto.StrippedContent
+	}{
+		{
+			name:             "Keeps the HTML allowed by the policy",
+			sanitizer:        ugc,
+			contents:         `<p>Hi <strong>{{index . "{user}"}}</strong></p>`,
+			expectedContents: `<p>Hi <strong>{{index . "{user}"}}</strong></p>`,
+			expectedStripped: []sdto.StrippedContent{},
+		},
+		{
+			name:             "Lists the elements and attributes that were stripped",
+			sanitizer:        ugc,
+			contents:         `<p onclick="steal()">Hi<script>steal()</script><script>steal()</script></p><a href="javascript:steal()">link</a>`,
+			expectedContents: `<p>Hi</p>link`,
+			expectedStripped: []sdto.StrippedContent{
+				{Field: "contents", Element: "p", Attribute: "onclick", Count: 1},
+				{Field: "contents", Element: "script", Count: 2},
+				{Field: "contents", Element: "a", Count: 1},
+			},
+		},
+		{
+			name:             "Strips the inline styles unless they are allowed",
+			sanitizer:        ugc,
+			contents:         `<p style="color: red">Hi</p>`,
+			expectedContents: `<p>Hi</p>`,
+			expectedStripped: []sdto.StrippedContent{
+				{Field: "contents", Element: "p", Attribute: "style", Count: 1},
+			},
+		},
+		{
+			name:             "Keeps the elements, attributes and styles allowed by the config",
+			sanitizer:        email,
+			contents:         `<center><table><tr><td bgcolor="red" align="left" style="color: red; position: fixed">Hi</td></tr></table></center>`,
+			expectedContents: `<center><table><tr><td bgcolor="red" align="left" style="color: red">Hi</td></tr></table></center>`,
+			expectedStripped: []sdto.StrippedContent{
+				{Field: "contents", Element: "td", Attribute: "style", Property: "position", Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, stripped := tt.sanitizer.SanitizeTemplate("contents", tt.contents)
+
+			assert.Equal(t, tt.expectedContents, contents)
+			assert.Equal(t, tt.expectedStripped, stripped)
+		})
+	}
+
+	t.Run("Doesn't escape plain text", func(t *testing.T) {
+		text, stripped := ugc.SanitizeText("contents", `Tom & Jerry's <script>steal()</script>show`)
+
+		assert.Equal(t, "Tom & Jerry's show", text)
+		assert.Equal(t, []sdto.StrippedContent{{Field: "contents", Element: "script", Count: 1}}, stripped)
+	})
+}
+
+func TestStrictSanitizer(t *testing.T) {
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	registry := mocks.NewMockedRegistry(
+		mocks.NewMockDistributionRegistry(controller),
+		mocks.NewMockUserRegistry(controller),
+		mocks.NewMockNotificationRegistry(controller),
+		mocks.NewMockNotificationTemplateRegistry(controller),
+		mocks.NewMockTemplatePartialRegistry(controller),
+		mocks.NewMockNotificationScheduleRegistry(controller),
+	)
+
+	e, err := routes.NewEngine(routes.EngineConfig{
+		Registry: registry,
+		Cache:    mocks.NewMockCache(controller),
+		Broker:   mocks.NewMockUserNotificationBroker(controller),
+		EngineConfigurator: tcfg.TestEngineConfigurator{
+			SanitizerConfig: sanitizer.Config{Strict: true},
+		},
+		Authorize:          mocks.TestAuthorize,
+		Authenticate:       mocks.NewTestAuthMiddleware(),
+		RateLimit:          mocks.NewTestRateLimitMiddleware(),
+		CacheMiddleware:    mocks.NewTestCacheMiddleware(),
+		SecurityMiddleware: mocks.NewTestSecurityMiddleware(),
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create the engine - %v", err)
+	}
+
+	post := func(url string, body any) *httptest.ResponseRecorder {
+		marshalled, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(marshalled))
+		req.Header.Add("userId", testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	type errorResp struct {
+		Error    string                 `json:"error"`
+		Stripped []sdto.StrippedContent `json:"stripped"`
+	}
+
+	t.Run("Should reject the templates with HTML that isn't allowed", func(t *testing.T) {
+		req := testutils.MakeTestNotificationTemplateRequest()
+		req.ContentsTemplate = `<p>Welcome to {{{app_name}}}!</p><iframe src="https://example.com"></iframe>`
+
+		w := post(notificationsTemplateUrl, req)
+
+		var resp errorResp
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, controllers.UnsafeContentsMsg, resp.Error)
+		assert.Equal(t, []sdto.StrippedContent{{Field: "contentsTemplate", Element: "iframe", Count: 1}}, resp.Stripped)
+	})
+
+	t.Run("Should reject the raw contents with HTML that isn't allowed", func(t *testing.T) {
+		req := testutils.MakeTestNotificationRequestRawContents()
+		req.RawContents.Contents = `Click <a href="javascript:steal()">here</a>`
+
+		w := post("/notifications", req)
+
+		var resp errorResp
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, controllers.UnsafeContentsMsg, resp.Error)
+		assert.Equal(t, []sdto.StrippedContent{{Field: "contents.contents", Element: "a", Count: 1}}, resp.Stripped)
+	})
+}
@@ -0,0 +1,314 @@
+package unit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/notifique/service/internal"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/scheduler"
+	"github.com/notifique/service/internal/testutils"
+	"github.com/notifique/service/internal/testutils/mocks"
+	sdto "github.com/notifique/shared/dto"
+)
+
+type schedulerMocks struct {
+	Registry     *mocks.MockScheduledNotificationRegistry
+	Schedules    *mocks.MockRecurringScheduleRegistry
+	Configurator *mocks.MockSchedulerConfigurator
+}
+
+func TestScheduler(t *testing.T) {
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	m := schedulerMocks{
+		Registry:     mocks.NewMockScheduledNotificationRegistry(controller),
+		Schedules:    mocks.NewMockRecurringScheduleRegistry(controller),
+		Configurator: mocks.NewMockSchedulerConfigurator(controller),
+	}
+
+	m.Configurator.
+		EXPECT().
+		GetSchedulerInterval().
+		Return(time.Second, nil)
+
+	s, err := scheduler.NewScheduler(scheduler.SchedulerCfg{
+		Registry:     m.Registry,
+		Schedules:    m.Schedules,
+		Configurator: m.Configurator,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create scheduler - %v", err)
+	}
+
+	testPublishDueNotifications(t, s, m)
+	testRunDueSchedules(t, s, m)
+}
+
+func testPublishDueNotifications(t *testing.T, s *scheduler.Scheduler, m schedulerMocks) {
+
+	makeNotification := func(status sdto.NotificationStatus) dto.NotificationResp {
+		req := testutils.MakeTestNotificationRequestRawContents()
+		sendAt := time.Now().Format(time.RFC3339)
+		req.SendAt = &sendAt
+
+		return dto.NotificationResp{
+			NotificationReq: req,
+			Id:              uuid.NewString(),
+			Status:          status,
+		}
+	}
+
+	makePayload := func(notification dto.NotificationResp) sdto.NotificationMsgPayload {
+		return sdto.NotificationMsgPayload{
+			NotificationReq: notification.NotificationReq,
+			Id:              notification.Id,
+			Hash:            internal.GetMd5Hash(notification.Id),
+		}
+	}
+
+	t.Run("Should claim the due notifications", func(t *testing.T) {
+		notification := makeNotification(sdto.Scheduled)
+
+		m.Registry.
+			EXPECT().
+			GetDueNotifications(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]string{notification.Id}, nil)
+
+		m.Registry.
+			EXPECT().
+			GetNotification(gomock.Any(), notification.Id).
+			Return(notification, nil)
+
+		m.Registry.
+			EXPECT().
+			ClaimScheduledNotification(gomock.Any(), makePayload(notification)).
+			Return(true, nil)
+
+		err := s.PublishDueNotifications(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should skip notifications that are no longer scheduled", func(t *testing.T) {
+		notification := makeNotification(sdto.Canceled)
+
+		m.Registry.
+			EXPECT().
+			GetDueNotifications(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]string{notification.Id}, nil)
+
+		m.Registry.
+			EXPECT().
+			GetNotification(gomock.Any(), notification.Id).
+			Return(notification, nil)
+
+		err := s.PublishDueNotifications(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should skip notifications claimed by someone else", func(t *testing.T) {
+		notification := makeNotification(sdto.Scheduled)
+
+		m.Registry.
+			EXPECT().
+			GetDueNotifications(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]string{notification.Id}, nil)
+
+		m.Registry.
+			EXPECT().
+			GetNotification(gomock.Any(), notification.Id).
+			Return(notification, nil)
+
+		m.Registry.
+			EXPECT().
+			ClaimScheduledNotification(gomock.Any(), makePayload(notification)).
+			Return(false, nil)
+
+		err := s.PublishDueNotifications(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should keep claiming if one of the notifications fails", func(t *testing.T) {
+		failed := makeNotification(sdto.Scheduled)
+		claimed := makeNotification(sdto.Scheduled)
+
+		m.Registry.
+			EXPECT().
+			GetDueNotifications(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]string{failed.Id, claimed.Id}, nil)
+
+		m.Registry.
+			EXPECT().
+			GetNotification(gomock.Any(), failed.Id).
+			Return(failed, nil)
+
+		m.Registry.
+			EXPECT().
+			GetNotification(gomock.Any(), claimed.Id).
+			Return(claimed, nil)
+
+		m.Registry.
+			EXPECT().
+			ClaimScheduledNotification(gomock.Any(), makePayload(failed)).
+			Return(false, errors.New("claim error"))
+
+		m.Registry.
+			EXPECT().
+			ClaimScheduledNotification(gomock.Any(), makePayload(claimed)).
+			Return(true, nil)
+
+		err := s.PublishDueNotifications(context.TODO())
+		assert.ErrorContains(t, err, "claim error")
+	})
+
+	t.Run("Should fail if the due notifications can't be retrieved", func(t *testing.T) {
+		m.Registry.
+			EXPECT().
+			GetDueNotifications(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return(nil, errors.New("registry error"))
+
+		err := s.PublishDueNotifications(context.TODO())
+		assert.ErrorContains(t, err, "registry error")
+	})
+}
+
+func testRunDueSchedules(t *testing.T, s *scheduler.Scheduler, m schedulerMocks) {
+
+	makeSchedule := func() dto.NotificationScheduleResp {
+		runAt := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+		return dto.NotificationScheduleResp{
+			NotificationScheduleReq: testutils.MakeTestNotificationScheduleRequest(),
+			Id:                      uuid.NewString(),
+			NextRunAt:               &runAt,
+			CreatedBy:               "1234",
+		}
+	}
+
+	t.Run("Should create a notification for the due schedules", func(t *testing.T) {
+		schedule := makeSchedule()
+		runAt, _ := time.Parse(time.RFC3339, *schedule.NextRunAt)
+
+		m.Schedules.
+			EXPECT().
+			GetDueSchedules(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]dto.NotificationScheduleResp{schedule}, nil)
+
+		m.Schedules.
+			EXPECT().
+			RunSchedule(gomock.Any(), schedule, runAt, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ dto.NotificationScheduleResp, _, nextRunAt time.Time) (bool, error) {
+				assert.True(t, nextRunAt.After(time.Now()))
+				return true, nil
+			})
+
+		err := s.RunDueSchedules(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should skip occurrences claimed by someone else", func(t *testing.T) {
+		schedule := makeSchedule()
+
+		m.Schedules.
+			EXPECT().
+			GetDueSchedules(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]dto.NotificationScheduleResp{schedule}, nil)
+
+		m.Schedules.
+			EXPECT().
+			RunSchedule(gomock.Any(), schedule, gomock.Any(), gomock.Any()).
+			Return(false, nil)
+
+		err := s.RunDueSchedules(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should retry the occurrences whose notification couldn't be saved", func(t *testing.T) {
+		schedule := makeSchedule()
+		runAt, _ := time.Parse(time.RFC3339, *schedule.NextRunAt)
+
+		m.Schedules.
+			EXPECT().
+			GetDueSchedules(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]dto.NotificationScheduleResp{schedule}, nil).
+			Times(2)
+
+		gomock.InOrder(
+			m.Schedules.
+				EXPECT().
+				RunSchedule(gomock.Any(), schedule, runAt, gomock.Any()).
+				Return(false, errors.New("registry error")),
+			m.Schedules.
+				EXPECT().
+				RunSchedule(gomock.Any(), schedule, runAt, gomock.Any()).
+				Return(true, nil),
+		)
+
+		m.Schedules.
+			EXPECT().
+			AdvanceSchedule(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
+
+		err := s.RunDueSchedules(context.TODO())
+		assert.ErrorContains(t, err, "registry error")
+
+		err = s.RunDueSchedules(context.TODO())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Should skip the occurrences whose template was archived", func(t *testing.T) {
+		schedule := makeSchedule()
+		runAt, _ := time.Parse(time.RFC3339, *schedule.NextRunAt)
+		archived := internal.TemplateArchived{Id: uuid.NewString()}
+
+		m.Schedules.
+			EXPECT().
+			GetDueSchedules(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]dto.NotificationScheduleResp{schedule}, nil)
+
+		m.Schedules.
+			EXPECT().
+			RunSchedule(gomock.Any(), schedule, runAt, gomock.Any()).
+			Return(false, archived)
+
+		m.Schedules.
+			EXPECT().
+			AdvanceSchedule(gomock.Any(), schedule.Id, runAt, gomock.Any()).
+			Return(true, nil)
+
+		err := s.RunDueSchedules(context.TODO())
+		assert.ErrorContains(t, err, archived.Error())
+	})
+
+	t.Run("Should keep running if one of the schedules fails", func(t *testing.T) {
+		failed := makeSchedule()
+		created := makeSchedule()
+
+		m.Schedules.
+			EXPECT().
+			GetDueSchedules(gomock.Any(), gomock.Any(), internal.PageSize).
+			Return([]dto.NotificationScheduleResp{failed, created}, nil)
+
+		m.Schedules.
+			EXPECT().
+			RunSchedule(gomock.Any(), failed, gomock.Any(), gomock.Any()).
+			Return(false, errors.New("registry error"))
+
+		m.Schedules.
+			EXPECT().
+			RunSchedule(gomock.Any(), created, gomock.Any(), gomock.Any()).
+			Return(true, nil)
+
+		err := s.RunDueSchedules(context.TODO())
+		assert.ErrorContains(t, err, "registry error")
+	})
+}
@@ -0,0 +1,648 @@
+package unit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/notifique/service/internal"
+	di "github.com/notifique/service/internal/di"
+	"github.com/notifique/service/internal/dto"
+	"github.com/notifique/service/internal/registry"
+	"github.com/notifique/service/internal/testutils"
+	"github.com/notifique/shared/auth"
+	"github.com/notifique/shared/cache"
+	sdto "github.com/notifique/shared/dto"
+)
+
+const templatePartialsUrl = "/notifications/partials"
+
+var templatePartialsKey = cache.GetEndpointKeyWithPrefix(templatePartialsUrl, nil)
+
+func TestTemplatePartialController(t *testing.T) {
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	testApp, err := di.InjectMockedBackend(context.TODO(), controller)
+
+	if err != nil {
+		t.Fatalf("failed to create mocked backend - %v", err)
+	}
+
+	testCreateTemplatePartial(t, testApp.Engine, *testApp)
+	testGetTemplatePartials(t, testApp.Engine, *testApp)
+	testGetTemplatePartial(t, testApp.Engine, *testApp)
+	testGetTemplatePartialVersion(t, testApp.Engine, *testApp)
+	testUpdateTemplatePartial(t, testApp.Engine, *testApp)
+	testDeleteTemplatePartial(t, testApp.Engine, *testApp)
+}
+
+func makeTestTemplatePartial(version int) sdto.TemplatePartialDetails {
+
+	req := testutils.MakeTestTemplatePartialRequest()
+
+	return sdto.TemplatePartialDetails{
+		Name:        req.Name,
+		Kind:        req.Kind,
+		Version:     version,
+		Description: req.Description,
+		Contents:    req.Contents,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		CreatedBy:   testUserId,
+	}
+}
+
+func testCreateTemplatePartial(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockTemplatePartialRegistry
+
+	createPartial := func(partialReq dto.TemplatePartialReq) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(partialReq)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, templatePartialsUrl, bytes.NewReader(body))
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	expectedResp := makeTestTemplatePartial(1)
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		modifyRequest  func(req dto.TemplatePartialReq) dto.TemplatePartialReq
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *sdto.TemplatePartialDetails
+	}{
+		{
+			name: "Can create a template partial",
+			setupMock: func() {
+				registryMock.EXPECT().
+					SaveTemplatePartial(gomock.Any(), testUserId, gomock.Any()).
+					Return(expectedResp, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), templatePartialsKey).
+					Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp:   &expectedResp,
+		},
+		{
+			name: "Can create a layout that includes a partial",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), expectedResp.Name).
+					Return(expectedResp, nil)
+
+				registryMock.EXPECT().
+					SaveTemplatePartial(gomock.Any(), testUserId, gomock.Any()).
+					Return(expectedResp, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), templatePartialsKey).
+					Return(nil)
+			},
+			modifyRequest: func(req dto.TemplatePartialReq) dto.TemplatePartialReq {
+				req.Name = "base"
+				req.Kind = string(dto.Layout)
+				req.Contents = `<main>{{template "content" .}}</main>{{template "footer" .}}`
+				return req
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Should fail if the name is reserved",
+			modifyRequest: func(req dto.TemplatePartialReq) dto.TemplatePartialReq {
+				req.Name = "content"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("partialname"),
+		},
+		{
+			name: "Should fail if the kind is invalid",
+			modifyRequest: func(req dto.TemplatePartialReq) dto.TemplatePartialReq {
+				req.Kind = "HEADER"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("oneof"),
+		},
+		{
+			name: "Should fail if the partial has a syntax error",
+			modifyRequest: func(req dto.TemplatePartialReq) dto.TemplatePartialReq {
+				req.Contents = "{{end}}"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("invalid partial"),
+		},
+		{
+			name: "Should fail if the layout doesn't include the contents",
+			modifyRequest: func(req dto.TemplatePartialReq) dto.TemplatePartialReq {
+				req.Kind = string(dto.Layout)
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("must include the contents"),
+		},
+		{
+			name: "Should fail if an included partial doesn't exist",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), "missing").
+					Return(sdto.TemplatePartialDetails{}, internal.EntityNotFound{
+						Id:   "missing",
+						Type: registry.TemplatePartialType,
+					})
+			},
+			modifyRequest: func(req dto.TemplatePartialReq) dto.TemplatePartialReq {
+				req.Contents = `{{template "missing" .}}`
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("entity missing of type Template Partial not found"),
+		},
+		{
+			name: "Should fail if the partial already exists",
+			setupMock: func() {
+				registryMock.EXPECT().
+					SaveTemplatePartial(gomock.Any(), testUserId, gomock.Any()).
+					Return(sdto.TemplatePartialDetails{}, internal.TemplatePartialAlreadyExists{
+						Name: expectedResp.Name,
+					})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  testutils.StrPtr("already exists"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			req := testutils.MakeTestTemplatePartialRequest()
+
+			if tt.modifyRequest != nil {
+				req = tt.modifyRequest(req)
+			}
+
+			w := createPartial(req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := sdto.TemplatePartialDetails{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testGetTemplatePartials(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockTemplatePartialRegistry
+
+	getPartials := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, templatePartialsUrl+query, nil)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	layouts := sdto.Page[dto.TemplatePartialInfoResp]{
+		Data: []dto.TemplatePartialInfoResp{{
+			Name:        "base",
+			Kind:        string(dto.Layout),
+			Version:     1,
+			Description: "Layout of the e-mails",
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedResp   *sdto.Page[dto.TemplatePartialInfoResp]
+	}{
+		{
+			name:  "Can retrieve the layouts",
+			query: "?kind=LAYOUT",
+			setupMock: func() {
+				isLayoutFilter := func(filters dto.TemplatePartialFilters) bool {
+					return filters.Kind != nil && *filters.Kind == string(dto.Layout)
+				}
+
+				registryMock.EXPECT().
+					GetTemplatePartials(gomock.Any(), gomock.Cond(isLayoutFilter)).
+					Return(layouts, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   &layouts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := getPartials(tt.query)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedResp != nil {
+				resp := sdto.Page[dto.TemplatePartialInfoResp]{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testGetTemplatePartial(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockTemplatePartialRegistry
+
+	getPartial := func(name string) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s", templatePartialsUrl, name)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	partial := makeTestTemplatePartial(1)
+
+	tests := []struct {
+		name           string
+		partialName    string
+		setupMock      func()
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *sdto.TemplatePartialDetails
+	}{
+		{
+			name:        "Can retrieve a template partial",
+			partialName: partial.Name,
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), partial.Name).
+					Return(partial, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   &partial,
+		},
+		{
+			name:        "Should fail if the partial doesn't exist",
+			partialName: "missing",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), "missing").
+					Return(sdto.TemplatePartialDetails{}, internal.EntityNotFound{
+						Id:   "missing",
+						Type: registry.TemplatePartialType,
+					})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  testutils.StrPtr("entity missing of type Template Partial not found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := getPartial(tt.partialName)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := sdto.TemplatePartialDetails{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testGetTemplatePartialVersion(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockTemplatePartialRegistry
+
+	getVersion := func(name, version string) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s/versions/%s", templatePartialsUrl, name, version)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	partial := makeTestTemplatePartial(2)
+
+	tests := []struct {
+		name           string
+		version        string
+		setupMock      func()
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *sdto.TemplatePartialDetails
+	}{
+		{
+			name:    "Can retrieve a version of a template partial",
+			version: "2",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartialVersion(gomock.Any(), partial.Name, 2).
+					Return(partial, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   &partial,
+		},
+		{
+			name:           "Should fail if the version isn't a number",
+			version:        "latest",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Should fail if the version doesn't exist",
+			version: "3",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartialVersion(gomock.Any(), partial.Name, 3).
+					Return(sdto.TemplatePartialDetails{}, internal.EntityNotFound{
+						Id:   "footer@3",
+						Type: registry.TemplatePartialVersionType,
+					})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  testutils.StrPtr("footer@3"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := getVersion(partial.Name, tt.version)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := sdto.TemplatePartialDetails{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testUpdateTemplatePartial(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockTemplatePartialRegistry
+
+	updatePartial := func(name string, partialReq dto.TemplatePartialUpdateReq) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s", templatePartialsUrl, name)
+		body, _ := json.Marshal(partialReq)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	current := makeTestTemplatePartial(1)
+	updated := makeTestTemplatePartial(2)
+	updated.Contents = `<p>Sent by {{index . "{app_name}"}}</p>`
+
+	updateReq := dto.TemplatePartialUpdateReq{
+		Description: updated.Description,
+		Contents:    updated.Contents,
+	}
+
+	tests := []struct {
+		name           string
+		partialName    string
+		setupMock      func()
+		modifyRequest  func(req dto.TemplatePartialUpdateReq) dto.TemplatePartialUpdateReq
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *sdto.TemplatePartialDetails
+	}{
+		{
+			name:        "Can update a template partial",
+			partialName: current.Name,
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), current.Name).
+					Return(current, nil)
+
+				registryMock.EXPECT().
+					UpdateTemplatePartial(gomock.Any(), current.Name, testUserId, updateReq).
+					Return(updated, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), templatePartialsKey).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   &updated,
+		},
+		{
+			name:        "Should fail if the partial doesn't exist",
+			partialName: "missing",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), "missing").
+					Return(sdto.TemplatePartialDetails{}, internal.EntityNotFound{
+						Id:   "missing",
+						Type: registry.TemplatePartialType,
+					})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  testutils.StrPtr("entity missing of type Template Partial not found"),
+		},
+		{
+			name:        "Should fail if the partial has a syntax error",
+			partialName: current.Name,
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), current.Name).
+					Return(current, nil)
+			},
+			modifyRequest: func(req dto.TemplatePartialUpdateReq) dto.TemplatePartialUpdateReq {
+				req.Contents = "{{if}}"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("invalid partial"),
+		},
+		{
+			name:        "Should fail if the partial is updated concurrently",
+			partialName: current.Name,
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), current.Name).
+					Return(current, nil)
+
+				registryMock.EXPECT().
+					UpdateTemplatePartial(gomock.Any(), current.Name, testUserId, updateReq).
+					Return(sdto.TemplatePartialDetails{}, internal.TemplateUpdateConflict{
+						Id: current.Name,
+					})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  testutils.StrPtr("has been updated concurrently"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			req := updateReq
+
+			if tt.modifyRequest != nil {
+				req = tt.modifyRequest(req)
+			}
+
+			w := updatePartial(tt.partialName, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := sdto.TemplatePartialDetails{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testDeleteTemplatePartial(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockTemplatePartialRegistry
+
+	deletePartial := func(name string) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s", templatePartialsUrl, name)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, url, nil)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		partialName    string
+		setupMock      func()
+		expectedStatus int
+		expectedError  *string
+	}{
+		{
+			name:        "Can delete a template partial",
+			partialName: "footer",
+			setupMock: func() {
+				registryMock.EXPECT().
+					DeleteTemplatePartial(gomock.Any(), "footer").
+					Return(nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), templatePartialsKey).
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:        "Should fail if the partial is used by templates",
+			partialName: "base",
+			setupMock: func() {
+				registryMock.EXPECT().
+					DeleteTemplatePartial(gomock.Any(), "base").
+					Return(internal.TemplatePartialInUse{Name: "base"})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  testutils.StrPtr("template partial base is used by templates"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := deletePartial(tt.partialName)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/notifique/service/internal/dto"
 	"github.com/notifique/service/internal/registry"
 	"github.com/notifique/service/internal/testutils"
+	"github.com/notifique/shared/auth"
 	"github.com/notifique/shared/cache"
 	sdto "github.com/notifique/shared/dto"
 )
@@ -42,6 +44,12 @@ func TestNotificationTemplateController(t *testing.T) {
 	testGetNotificationTemplates(t, testApp.Engine, *testApp)
 	testGetNotificationTemplateDetails(t, testApp.Engine, *testApp)
 	testDeleteNotificationTemplate(t, testApp.Engine, *testApp)
+	testRenderNotificationTemplate(t, testApp.Engine, *testApp)
+	testUpdateNotificationTemplate(t, testApp.Engine, *testApp)
+	testGetNotificationTemplateVersions(t, testApp.Engine, *testApp)
+	testGetNotificationTemplateVersion(t, testApp.Engine, *testApp)
+	testRollbackNotificationTemplate(t, testApp.Engine, *testApp)
+	testPurgeNotificationTemplate(t, testApp.Engine, *testApp)
 }
 
 func testCreateNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
@@ -75,6 +83,7 @@ func testCreateNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedB
 		modifyRequest  func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq
 		expectedStatus int
 		expectedError  *string
+		expectedIssues []sdto.TemplateLintIssue
 		expectedResp   *dto.NotificationTemplateCreatedResp
 	}{
 		{
@@ -93,6 +102,363 @@ func testCreateNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedB
 			expectedStatus: http.StatusCreated,
 			expectedResp:   &expectedResp,
 		},
+		{
+			name: "Can create a notification template with template actions",
+			setupMock: func() {
+				hasActions := func(req dto.NotificationTemplateReq) bool {
+					return req.ContentsTemplate == `<p>{{index . "{user}" | default "customer"}}</p>`
+				}
+
+				registryMock.EXPECT().
+					SaveTemplate(gomock.Any(), gomock.Any(), gomock.Cond(hasActions)).
+					Return(expectedResp, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.IsHtml = true
+				req.ContentsTemplate = `<p>{{index . "{user}" | default "customer"}}</p>`
+				return req
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp: &dto.NotificationTemplateCreatedResp{
+				Id:        expectedResp.Id,
+				Name:      expectedResp.Name,
+				CreatedAt: expectedResp.CreatedAt,
+				Warnings: []sdto.TemplateLintIssue{{
+					Field:    "variables[1]",
+					Variable: "{app_name}",
+					Message:  "variable {app_name} is declared but never used",
+				}},
+			},
+		},
+		{
+			name: "Should not escape the titles and the text contents",
+			setupMock: func() {
+				unescaped := func(req dto.NotificationTemplateReq) bool {
+					return req.TitleTemplate == "Tom & Jerry: a < b, {{{user}}}" &&
+						req.ContentsTemplate == "Welcome to {{{app_name}}} & have fun <3" &&
+						req.Localizations[0].TitleTemplate == "Tom & Jerry: a < b"
+				}
+
+				registryMock.EXPECT().
+					SaveTemplate(gomock.Any(), gomock.Any(), gomock.Cond(unescaped)).
+					Return(expectedResp, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Format = sdto.Text
+				req.TitleTemplate = "Tom & Jerry: a < b, {{{user}}}"
+				req.ContentsTemplate = "Welcome to {{{app_name}}} & have fun <3"
+				req.Localizations = []sdto.TemplateLocalization{{
+					Locale:           "pt-BR",
+					TitleTemplate:    "Tom & Jerry: a < b",
+					ContentsTemplate: "Bem-vindo ao {{{app_name}}}, {{{user}}}",
+				}}
+				return req
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp:   &expectedResp,
+		},
+		{
+			name: "Can create a notification template with a layout and partials",
+			setupMock: func() {
+				partialsMock := mock.Registry.MockTemplatePartialRegistry
+
+				partialsMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), "base").
+					Return(sdto.TemplatePartialDetails{
+						Name:     "base",
+						Kind:     string(dto.Layout),
+						Contents: `<main>{{template "content" .}}</main>`,
+					}, nil)
+
+				partialsMock.EXPECT().
+					GetTemplatePartial(gomock.Any(), "footer").
+					Return(sdto.TemplatePartialDetails{
+						Name:     "footer",
+						Kind:     string(dto.Partial),
+						Contents: `<p>{{index . "{app_name}"}}</p>`,
+					}, nil)
+
+				hasPartials := func(req dto.NotificationTemplateReq) bool {
+					return slices.Equal(req.Partials, []string{"base", "footer"})
+				}
+
+				registryMock.EXPECT().
+					SaveTemplate(gomock.Any(), gomock.Any(), gomock.Cond(hasPartials)).
+					Return(expectedResp, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Layout = testutils.StrPtr("base")
+				req.ContentsTemplate = `<p>Welcome!</p>{{template "footer" .}}`
+				return req
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp:   &expectedResp,
+		},
+		{
+			name: "Can create a notification template with localizations",
+			setupMock: func() {
+				hasLocalizations := func(req dto.NotificationTemplateReq) bool {
+					return len(req.Localizations) == 1 && req.Localizations[0].Locale == "pt"
+				}
+
+				registryMock.EXPECT().
+					SaveTemplate(gomock.Any(), gomock.Any(), gomock.Cond(hasLocalizations)).
+					Return(expectedResp, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.DefaultLocale = testutils.StrPtr("en")
+				req.Localizations = []sdto.TemplateLocalization{{
+					Locale:           "pt",
+					TitleTemplate:    "Olá {{{user}}}!",
+					ContentsTemplate: "Bem-vindo ao {{{app_name}}}!",
+				}}
+				return req
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp:   &expectedResp,
+		},
+		{
+			name: "Can create a markdown notification template",
+			setupMock: func() {
+				// The markdown isn't sanitized, which would escape its quotes
+				isMarkdown := func(req dto.NotificationTemplateReq) bool {
+					return req.Format == sdto.Markdown &&
+						req.IsHtml &&
+						req.ContentsTemplate == "> Welcome to **{{{app_name}}}**, {{{user}}} & co"
+				}
+
+				registryMock.EXPECT().
+					SaveTemplate(gomock.Any(), gomock.Any(), gomock.Cond(isMarkdown)).
+					Return(expectedResp, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Format = sdto.Markdown
+				req.IsHtml = false
+				req.ContentsTemplate = "> Welcome to **{{{app_name}}}**, {{{user}}} & co"
+				return req
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp:   &expectedResp,
+		},
+		{
+			name: "Can create a notification template with isHtml instead of a format",
+			setupMock: func() {
+				isText := func(req dto.NotificationTemplateReq) bool {
+					return req.Format == sdto.Text && !req.IsHtml
+				}
+
+				registryMock.EXPECT().
+					SaveTemplate(gomock.Any(), gomock.Any(), gomock.Cond(isText)).
+					Return(expectedResp, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Format = ""
+				req.IsHtml = false
+				return req
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp:   &expectedResp,
+		},
+		{
+			name: "Should fail if the format is invalid",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Format = "PDF"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateReq.Format' Error:Field validation for 'Format' failed on the 'oneof' tag`),
+		},
+		{
+			name: "Should fail if a localization has a syntax error",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Localizations = []sdto.TemplateLocalization{{
+					Locale:           "pt",
+					TitleTemplate:    "Olá",
+					ContentsTemplate: "{{if}}condição ausente{{end}}",
+				}}
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("invalid pt localization"),
+		},
+		{
+			name: "Should fail if the locale of a localization is invalid",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Localizations = []sdto.TemplateLocalization{{
+					Locale:           "not a locale",
+					TitleTemplate:    "Olá",
+					ContentsTemplate: "Olá",
+				}}
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateReq.Localizations[0].Locale' Error:Field validation for 'Locale' failed on the 'bcp47_language_tag' tag`),
+		},
+		{
+			name: "Should fail if the templates reference undeclared variables",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.TitleTemplate = "Hi {{{user}}}, {{name}}!"
+				req.ContentsTemplate = "Welcome to {{{app_name}}}!\n<p>Your code is {{index . \"{code}\"}}</p>"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("template references undeclared variables"),
+			expectedIssues: []sdto.TemplateLintIssue{{
+				Field:    "titleTemplate",
+				Variable: "name",
+				Line:     1,
+				Column:   16,
+				Message:  "variable name isn't declared",
+			}, {
+				Field:    "contentsTemplate",
+				Variable: "{code}",
+				Line:     2,
+				Column:   27,
+				Message:  "variable {code} isn't declared",
+			}},
+		},
+		{
+			name: "Should fail if a localization references an undeclared variable",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Localizations = []sdto.TemplateLocalization{{
+					Locale:           "pt",
+					TitleTemplate:    "Olá {{{user}}}!",
+					ContentsTemplate: "Bem-vindo ao {{{app}}}!",
+				}}
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("template references undeclared variables"),
+			expectedIssues: []sdto.TemplateLintIssue{{
+				Field:    "localizations[0].contentsTemplate",
+				Variable: "{app}",
+				Line:     1,
+				Column:   14,
+				Message:  "variable {app} isn't declared",
+			}},
+		},
+		{
+			name: "Should fail if a partial of the template doesn't exist",
+			setupMock: func() {
+				mock.Registry.MockTemplatePartialRegistry.EXPECT().
+					GetTemplatePartial(gomock.Any(), "missing").
+					Return(sdto.TemplatePartialDetails{}, internal.EntityNotFound{
+						Id:   "missing",
+						Type: registry.TemplatePartialType,
+					})
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.ContentsTemplate = `{{template "missing" .}}`
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("entity missing of type Template Partial not found"),
+		},
+		{
+			name: "Should fail if the layout of the template isn't a layout",
+			setupMock: func() {
+				mock.Registry.MockTemplatePartialRegistry.EXPECT().
+					GetTemplatePartial(gomock.Any(), "footer").
+					Return(sdto.TemplatePartialDetails{
+						Name:     "footer",
+						Kind:     string(dto.Partial),
+						Contents: "<p>footer</p>",
+					}, nil)
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Layout = testutils.StrPtr("footer")
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("partial footer isn't a layout"),
+		},
+		{
+			name: "Should fail if the contents template has a syntax error",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.ContentsTemplate = "{{if}}missing condition{{end}}"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("invalid contents template"),
+		},
+		{
+			name: "Should fail if the title template uses an unknown function",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.TitleTemplate = `{{index . "{user}" | shout}}`
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`function "shout" not defined`),
+		},
+		{
+			name: "Should fail if an ENUM variable has no values",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Variables = append(req.Variables, sdto.TemplateVariable{
+					Name: "{plan}",
+					Type: "ENUM",
+				})
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateReq.Variables[2].values' Error:Field validation for 'values' failed on the 'required_if' tag`),
+		},
+		{
+			name: "Should fail if the min of a variable is greater than its max",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Variables = append(req.Variables, sdto.TemplateVariable{
+					Name: "{items}",
+					Type: "INTEGER",
+					Min:  testutils.Ptr[float64](10),
+					Max:  testutils.Ptr[float64](1),
+				})
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateReq.Variables[2].max' Error:Field validation for 'max' failed on the 'gtefield' tag`),
+		},
+		{
+			name: "Should fail if a length bound is set on a variable that isn't a string",
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.Variables = append(req.Variables, sdto.TemplateVariable{
+					Name:      "{vip}",
+					Type:      "BOOLEAN",
+					MaxLength: testutils.IntPtr(10),
+				})
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateReq.Variables[2].maxLength' Error:Field validation for 'maxLength' failed on the 'string_type' tag`),
+		},
 		{
 			name: "Should fail if the template name is empty",
 			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
@@ -245,17 +611,21 @@ func testCreateNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedB
 			assert.Equal(t, tt.expectedStatus, w.Code)
 
 			if tt.expectedError != nil {
-				resp := make(map[string]string)
+				resp := struct {
+					Error  string                   `json:"error"`
+					Errors []sdto.TemplateLintIssue `json:"errors"`
+				}{}
 				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 					t.Fatal(err)
 				}
-				assert.Contains(t, resp["error"], *tt.expectedError)
+				assert.Contains(t, resp.Error, *tt.expectedError)
+				assert.Equal(t, tt.expectedIssues, resp.Errors)
 			} else if tt.expectedResp != nil {
 				resp := dto.NotificationTemplateCreatedResp{}
 				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 					t.Fatal(err)
 				}
-				assert.Equal(t, expectedResp, *tt.expectedResp)
+				assert.Equal(t, *tt.expectedResp, resp)
 			}
 		})
 	}
@@ -304,6 +674,51 @@ func testGetNotificationTemplates(t *testing.T, e *gin.Engine, mock di.MockedBac
 				GetTemplates(gomock.Any(), gomock.Any()).
 				Return(testPage, nil)
 		},
+	}, {
+		name:           "Can filter the notification templates",
+		expectedStatus: http.StatusOK,
+		expectedResp:   &testPage,
+		modifyFilters: func(req dto.NotificationTemplateFilters) *dto.NotificationTemplateFilters {
+			req.Category = testutils.Ptr("onboarding")
+			req.Tags = []string{"welcome", "email"}
+			req.CreatedBy = testutils.Ptr(testUserId)
+			req.Format = testutils.Ptr(sdto.Html)
+			req.CreatedFrom = testutils.Ptr("2024-01-01T00:00:00Z")
+			req.UpdatedTo = testutils.Ptr("2024-12-31T23:59:59Z")
+			req.Search = testutils.Ptr("Welcome")
+			return &req
+		},
+		setupMock: func() {
+			expectedFilters := testutils.MakeTestNotificationTemplateFilter()
+			expectedFilters.Category = testutils.Ptr("onboarding")
+			expectedFilters.Tags = []string{"welcome", "email"}
+			expectedFilters.CreatedBy = testutils.Ptr(testUserId)
+			expectedFilters.Format = testutils.Ptr(sdto.Html)
+			expectedFilters.CreatedFrom = testutils.Ptr("2024-01-01T00:00:00Z")
+			expectedFilters.UpdatedTo = testutils.Ptr("2024-12-31T23:59:59Z")
+			expectedFilters.Search = testutils.Ptr("Welcome")
+
+			registryMock.
+				EXPECT().
+				GetTemplates(gomock.Any(), expectedFilters).
+				Return(testPage, nil)
+		},
+	}, {
+		name:           "Should fail if the format filter is invalid",
+		expectedStatus: http.StatusBadRequest,
+		expectedError:  testutils.Ptr("Field validation for 'Format' failed on the 'oneof' tag"),
+		modifyFilters: func(req dto.NotificationTemplateFilters) *dto.NotificationTemplateFilters {
+			req.Format = testutils.Ptr[sdto.TemplateFormat]("PDF")
+			return &req
+		},
+	}, {
+		name:           "Should fail if the updated date filter is invalid",
+		expectedStatus: http.StatusBadRequest,
+		expectedError:  testutils.Ptr("Field validation for 'UpdatedFrom' failed on the 'datetime' tag"),
+		modifyFilters: func(req dto.NotificationTemplateFilters) *dto.NotificationTemplateFilters {
+			req.UpdatedFrom = testutils.Ptr("yesterday")
+			return &req
+		},
 	}}
 
 	for _, tt := range tests {
@@ -474,7 +889,7 @@ func testDeleteNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedB
 			setupMock: func() {
 				mock.Registry.MockNotificationTemplateRegistry.
 					EXPECT().
-					DeleteTemplate(gomock.Any(), gomock.Any()).
+					ArchiveTemplate(gomock.Any(), validId, gomock.Any()).
 					Return(nil)
 
 				path := fmt.Sprintf("/notifications/templates/%s", validId)
@@ -519,3 +934,909 @@ func testDeleteNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedB
 		})
 	}
 }
+
+func testRenderNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	renderTemplate := func(templateId string, body dto.NotificationTemplateRenderReq) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s/render", notificationsTemplateUrl, templateId)
+		marshalled, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(marshalled))
+		req.Header.Add("userId", testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	testDetails := sdto.NotificationTemplateDetails{
+		Id:               uuid.NewString(),
+		Name:             "test-template",
+		IsHtml:           true,
+		TitleTemplate:    "Hi {{{user}}}",
+		ContentsTemplate: "Your order will arrive on {{{date}}}",
+		Variables: []sdto.TemplateVariable{
+			{Name: "{user}", Type: "STRING", Required: true},
+			{Name: "{date}", Type: "DATE", Required: true},
+		},
+	}
+
+	validVariables := []sdto.TemplateVariableContents{
+		{Name: "{user}", Value: "John"},
+		{Name: "{date}", Value: "2024-01-01"},
+	}
+
+	logicDetails := sdto.NotificationTemplateDetails{
+		Id:            uuid.NewString(),
+		Name:          "logic-template",
+		IsHtml:        true,
+		TitleTemplate: `Hi {{index . "{user}" | default "customer"}}`,
+		ContentsTemplate: `<p>{{if index . "{user}"}}{{index . "{user}"}}, y{{else}}Y{{end}}our order ` +
+			`will arrive on {{index . "{date}" | formatDate "02/01/2006"}}</p>`,
+		Variables: []sdto.TemplateVariable{
+			{Name: "{user}", Type: "STRING", Required: false},
+			{Name: "{date}", Type: "DATE", Required: true},
+		},
+	}
+
+	typedDetails := sdto.NotificationTemplateDetails{
+		Id:            uuid.NewString(),
+		Name:          "typed-template",
+		IsHtml:        false,
+		TitleTemplate: `{{if index . "{vip}"}}Thank you for being a VIP{{else}}Thank you{{end}}`,
+		ContentsTemplate: `You bought {{index . "{items}"}} {{index . "{items}" | pluralize "item" "items"}} ` +
+			`for {{index . "{total}"}}`,
+		Variables: []sdto.TemplateVariable{
+			{Name: "{vip}", Type: "BOOLEAN", Required: true},
+			{Name: "{items}", Type: "INTEGER", Required: true},
+			{Name: "{total}", Type: "CURRENCY", Required: true},
+		},
+	}
+
+	layoutDetails := testDetails
+	layoutDetails.Id = uuid.NewString()
+	layoutDetails.Layout = testutils.StrPtr("base")
+	layoutDetails.ContentsTemplate = `<p>Your order will arrive on {{{date}}}</p>{{template "footer" .}}`
+
+	layoutPartials := map[string]sdto.TemplatePartialDetails{
+		"base": {
+			Name:     "base",
+			Kind:     string(dto.Layout),
+			Contents: `<main>{{template "content" .}}</main>`,
+		},
+		"footer": {
+			Name:     "footer",
+			Kind:     string(dto.Partial),
+			Contents: `<footer>Sent to {{index . "{user}"}}</footer>`,
+		},
+	}
+
+	localizedDetails := testDetails
+	localizedDetails.Id = uuid.NewString()
+	localizedDetails.DefaultLocale = testutils.StrPtr("en")
+	localizedDetails.Localizations = []sdto.TemplateLocalization{{
+		Locale:           "pt",
+		TitleTemplate:    "Olá {{{user}}}",
+		ContentsTemplate: "Seu pedido chegará em {{{date}}}",
+	}}
+
+	markdownDetails := testDetails
+	markdownDetails.Id = uuid.NewString()
+	markdownDetails.Format = sdto.Markdown
+	markdownDetails.ContentsTemplate = "Your order will arrive on **{{{date}}}**\n\n- [Track it](https://example.com/track)"
+
+	missingTemplateId := uuid.NewString()
+
+	templateNotFound := fmt.Sprintf("entity %v of type %v not found",
+		missingTemplateId,
+		registry.NotificationTemplateType,
+	)
+
+	tests := []struct {
+		name           string
+		templateId     string
+		body           dto.NotificationTemplateRenderReq
+		setupMock      func()
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *sdto.RenderedTemplate
+	}{
+		{
+			name:       "Can render a template",
+			templateId: testDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Variables: validVariables,
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), testDetails.Id).
+					Return(testDetails, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &sdto.RenderedTemplate{
+				Title:        "Hi John",
+				Contents:     "Your order will arrive on 2024-01-01",
+				TextContents: "Your order will arrive on 2024-01-01",
+				IsHtml:       true,
+			},
+		},
+		{
+			name:       "Can render a template with conditionals and functions",
+			templateId: logicDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Variables: []sdto.TemplateVariableContents{
+					{Name: "{user}", Value: "<John>"},
+					{Name: "{date}", Value: "2024-01-31"},
+				},
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), logicDetails.Id).
+					Return(logicDetails, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &sdto.RenderedTemplate{
+				Title:        "Hi <John>",
+				Contents:     "<p>&lt;John&gt;, your order will arrive on 31/01/2024</p>",
+				TextContents: "<John>, your order will arrive on 31/01/2024",
+				IsHtml:       true,
+			},
+		},
+		{
+			name:       "Can render a template with a layout and partials",
+			templateId: layoutDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Variables: validVariables,
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), layoutDetails.Id).
+					Return(layoutDetails, nil)
+
+				for name, partial := range layoutPartials {
+					mock.Registry.MockTemplatePartialRegistry.
+						EXPECT().
+						GetTemplatePartial(gomock.Any(), name).
+						Return(partial, nil)
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &sdto.RenderedTemplate{
+				Title:        "Hi John",
+				Contents:     "<main><p>Your order will arrive on 2024-01-01</p><footer>Sent to John</footer></main>",
+				TextContents: "Your order will arrive on 2024-01-01\n\nSent to John",
+				IsHtml:       true,
+			},
+		},
+		{
+			name:       "Can render a template in the locale of the request",
+			templateId: localizedDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Locale:    testutils.StrPtr("pt-BR"),
+				Variables: validVariables,
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), localizedDetails.Id).
+					Return(localizedDetails, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &sdto.RenderedTemplate{
+				Title:        "Olá John",
+				Contents:     "Seu pedido chegará em 2024-01-01",
+				TextContents: "Seu pedido chegará em 2024-01-01",
+				IsHtml:       true,
+			},
+		},
+		{
+			name:       "Can render the default locale if the locale isn't available",
+			templateId: localizedDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Locale:    testutils.StrPtr("fr"),
+				Variables: validVariables,
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), localizedDetails.Id).
+					Return(localizedDetails, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &sdto.RenderedTemplate{
+				Title:        "Hi John",
+				Contents:     "Your order will arrive on 2024-01-01",
+				TextContents: "Your order will arrive on 2024-01-01",
+				IsHtml:       true,
+			},
+		},
+		{
+			name:       "Can render a markdown template",
+			templateId: markdownDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Variables: validVariables,
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), markdownDetails.Id).
+					Return(markdownDetails, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &sdto.RenderedTemplate{
+				Title: "Hi John",
+				Contents: "<p>Your order will arrive on <strong>2024-01-01</strong></p>\n" +
+					"<ul>\n<li><a href=\"https://example.com/track\">Track it</a></li>\n</ul>",
+				TextContents: "Your order will arrive on 2024-01-01\n\n- Track it (https://example.com/track)",
+				IsHtml:       true,
+			},
+		},
+		{
+			name:       "Can render a template with typed variables",
+			templateId: typedDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Variables: []sdto.TemplateVariableContents{
+					{Name: "{vip}", Value: "false"},
+					{Name: "{items}", Value: "1"},
+					{Name: "{total}", Value: "1234.5 USD"},
+				},
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), typedDetails.Id).
+					Return(typedDetails, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &sdto.RenderedTemplate{
+				Title:    "Thank you",
+				Contents: "You bought 1 item for $1,234.50",
+				IsHtml:   false,
+			},
+		},
+		{
+			name:       "Can render a template without the optional variables",
+			templateId: logicDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Variables: []sdto.TemplateVariableContents{
+					{Name: "{date}", Value: "2024-01-31"},
+				},
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), logicDetails.Id).
+					Return(logicDetails, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &sdto.RenderedTemplate{
+				Title:        "Hi customer",
+				Contents:     "<p>Your order will arrive on 31/01/2024</p>",
+				TextContents: "Your order will arrive on 31/01/2024",
+				IsHtml:       true,
+			},
+		},
+		{
+			name:       "Should fail if the variables are invalid",
+			templateId: testDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Variables: []sdto.TemplateVariableContents{
+					{Name: "{user}", Value: "John"},
+					{Name: "{date}", Value: "not-a-date"},
+				},
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), testDetails.Id).
+					Return(testDetails, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("not-a-date is not a valid date"),
+		},
+		{
+			name:       "Should fail if a required variable is missing",
+			templateId: testDetails.Id,
+			body: dto.NotificationTemplateRenderReq{
+				Variables: validVariables[:1],
+			},
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), testDetails.Id).
+					Return(testDetails, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("template variable {date} not found"),
+		},
+		{
+			name:           "Should fail if template id is not a valid UUID",
+			templateId:     "not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateUriParams.Id' Error:Field validation for 'Id' failed on the 'uuid' tag`),
+		},
+		{
+			name:       "Should fail if template doesn't exist",
+			templateId: missingTemplateId,
+			setupMock: func() {
+				mock.Registry.MockNotificationTemplateRegistry.
+					EXPECT().
+					GetTemplateDetails(gomock.Any(), missingTemplateId).
+					Return(sdto.NotificationTemplateDetails{}, internal.EntityNotFound{
+						Id:   missingTemplateId,
+						Type: registry.NotificationTemplateType,
+					})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  testutils.StrPtr(templateNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := renderTemplate(tt.templateId, tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := sdto.RenderedTemplate{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func makeTestTemplateVersion(version int) sdto.NotificationTemplateDetails {
+
+	testReq := testutils.MakeTestNotificationTemplateRequest()
+	updatedAt := time.Now().Format(time.RFC3339)
+
+	return sdto.NotificationTemplateDetails{
+		Id:               uuid.NewString(),
+		Name:             testReq.Name,
+		Version:          version,
+		Description:      testReq.Description,
+		TitleTemplate:    testReq.TitleTemplate,
+		ContentsTemplate: testReq.ContentsTemplate,
+		Variables:        testReq.Variables,
+		CreatedAt:        time.Now().Format(time.RFC3339),
+		CreatedBy:        testUserId,
+		UpdatedAt:        &updatedAt,
+		UpdatedBy:        testutils.StrPtr(testUserId),
+	}
+}
+
+func testUpdateNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationTemplateRegistry
+
+	updateTemplate := func(templateId string, templateReq dto.NotificationTemplateReq) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s", notificationsTemplateUrl, templateId)
+		body, _ := json.Marshal(templateReq)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	updated := makeTestTemplateVersion(2)
+	missingTemplateId := uuid.NewString()
+
+	updatedResp := dto.NotificationTemplateUpdatedResp{NotificationTemplateDetails: updated}
+
+	tests := []struct {
+		name           string
+		templateId     string
+		setupMock      func()
+		modifyRequest  func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *dto.NotificationTemplateUpdatedResp
+	}{
+		{
+			name:       "Can update a notification template",
+			templateId: updated.Id,
+			setupMock: func() {
+				registryMock.EXPECT().
+					UpdateTemplate(gomock.Any(), updated.Id, testUserId, gomock.Any()).
+					Return(updated, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   &updatedResp,
+		},
+		{
+			name:       "Should return the issues of the template",
+			templateId: updated.Id,
+			setupMock: func() {
+				registryMock.EXPECT().
+					UpdateTemplate(gomock.Any(), updated.Id, testUserId, gomock.Any()).
+					Return(updated, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.ContentsTemplate = `<p>Welcome!</p><iframe src="https://example.com"></iframe>`
+				return req
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &dto.NotificationTemplateUpdatedResp{
+				NotificationTemplateDetails: updated,
+				Warnings: []sdto.TemplateLintIssue{{
+					Field:    "variables[1]",
+					Variable: "{app_name}",
+					Message:  "variable {app_name} is declared but never used",
+				}},
+				Stripped: []sdto.StrippedContent{{Field: "contentsTemplate", Element: "iframe", Count: 1}},
+			},
+		},
+		{
+			name:       "Should fail if the template has a syntax error",
+			templateId: updated.Id,
+			modifyRequest: func(req dto.NotificationTemplateReq) dto.NotificationTemplateReq {
+				req.TitleTemplate = "{{end}}"
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr("invalid title template"),
+		},
+		{
+			name:       "Should fail if the template doesn't exist",
+			templateId: missingTemplateId,
+			setupMock: func() {
+				registryMock.EXPECT().
+					UpdateTemplate(gomock.Any(), missingTemplateId, testUserId, gomock.Any()).
+					Return(sdto.NotificationTemplateDetails{}, internal.EntityNotFound{
+						Id:   missingTemplateId,
+						Type: registry.NotificationTemplateType,
+					})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  testutils.StrPtr(missingTemplateId),
+		},
+		{
+			name:       "Should fail if the template is updated concurrently",
+			templateId: updated.Id,
+			setupMock: func() {
+				registryMock.EXPECT().
+					UpdateTemplate(gomock.Any(), updated.Id, testUserId, gomock.Any()).
+					Return(sdto.NotificationTemplateDetails{}, internal.TemplateUpdateConflict{
+						Id: updated.Id,
+					})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  testutils.StrPtr("has been updated concurrently"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			req := testutils.MakeTestNotificationTemplateRequest()
+
+			if tt.modifyRequest != nil {
+				req = tt.modifyRequest(req)
+			}
+
+			w := updateTemplate(tt.templateId, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := dto.NotificationTemplateUpdatedResp{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testGetNotificationTemplateVersions(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationTemplateRegistry
+
+	getVersions := func(templateId string) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s/versions", notificationsTemplateUrl, templateId)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	templateId := uuid.NewString()
+	missingTemplateId := uuid.NewString()
+
+	testPage := sdto.Page[dto.NotificationTemplateVersionResp]{
+		ResultCount: 2,
+		Data: []dto.NotificationTemplateVersionResp{{
+			Version:   2,
+			Name:      "Test Template",
+			CreatedBy: testUserId,
+			CreatedAt: time.Now().Format(time.RFC3339),
+		}, {
+			Version:   1,
+			Name:      "Test Template",
+			CreatedBy: testUserId,
+			CreatedAt: time.Now().Format(time.RFC3339),
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		templateId     string
+		setupMock      func()
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *sdto.Page[dto.NotificationTemplateVersionResp]
+	}{
+		{
+			name:       "Can retrieve the versions of a template",
+			templateId: templateId,
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVersions(gomock.Any(), templateId, gomock.Any()).
+					Return(testPage, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   &testPage,
+		},
+		{
+			name:           "Should fail if template id is not a valid UUID",
+			templateId:     "not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateUriParams.Id' Error:Field validation for 'Id' failed on the 'uuid' tag`),
+		},
+		{
+			name:       "Should fail if template doesn't exist",
+			templateId: missingTemplateId,
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVersions(gomock.Any(), missingTemplateId, gomock.Any()).
+					Return(sdto.Page[dto.NotificationTemplateVersionResp]{}, internal.EntityNotFound{
+						Id:   missingTemplateId,
+						Type: registry.NotificationTemplateType,
+					})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  testutils.StrPtr(missingTemplateId),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := getVersions(tt.templateId)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := sdto.Page[dto.NotificationTemplateVersionResp]{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testGetNotificationTemplateVersion(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationTemplateRegistry
+
+	getVersion := func(templateId, version string) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s/versions/%s", notificationsTemplateUrl, templateId, version)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	version := makeTestTemplateVersion(2)
+	missingVersion := fmt.Sprintf("%s (version 3)", version.Id)
+
+	tests := []struct {
+		name           string
+		templateId     string
+		version        string
+		setupMock      func()
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *sdto.NotificationTemplateDetails
+	}{
+		{
+			name:       "Can retrieve a version of a template",
+			templateId: version.Id,
+			version:    "2",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVersion(gomock.Any(), version.Id, 2).
+					Return(version, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   &version,
+		},
+		{
+			name:           "Should fail if the version is not a positive number",
+			templateId:     version.Id,
+			version:        "0",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateVersionUriParams.Version' Error:Field validation for 'Version' failed on the 'required' tag`),
+		},
+		{
+			name:       "Should fail if the version doesn't exist",
+			templateId: version.Id,
+			version:    "3",
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVersion(gomock.Any(), version.Id, 3).
+					Return(sdto.NotificationTemplateDetails{}, internal.EntityNotFound{
+						Id:   missingVersion,
+						Type: registry.NotificationTemplateVersionType,
+					})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  testutils.StrPtr(missingVersion),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := getVersion(tt.templateId, tt.version)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := sdto.NotificationTemplateDetails{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testRollbackNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationTemplateRegistry
+
+	rollback := func(templateId string, version int) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s/versions/%d/rollback", notificationsTemplateUrl, templateId, version)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	previous := makeTestTemplateVersion(1)
+	previous.UpdatedAt = nil
+	previous.UpdatedBy = nil
+
+	restored := previous
+	restored.Version = 3
+	restored.UpdatedAt = testutils.StrPtr(time.Now().Format(time.RFC3339))
+	restored.UpdatedBy = testutils.StrPtr(testUserId)
+
+	restoredReq := dto.NotificationTemplateReq{
+		Name:             previous.Name,
+		IsHtml:           previous.IsHtml,
+		TitleTemplate:    previous.TitleTemplate,
+		ContentsTemplate: previous.ContentsTemplate,
+		Description:      previous.Description,
+		Variables:        previous.Variables,
+	}
+
+	tests := []struct {
+		name           string
+		version        int
+		setupMock      func()
+		expectedStatus int
+		expectedError  *string
+		expectedResp   *dto.NotificationTemplateUpdatedResp
+	}{
+		{
+			name:    "Can rollback a template to a previous version",
+			version: 1,
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVersion(gomock.Any(), previous.Id, 1).
+					Return(previous, nil)
+
+				registryMock.EXPECT().
+					UpdateTemplate(gomock.Any(), previous.Id, testUserId, restoredReq).
+					Return(restored, nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   &dto.NotificationTemplateUpdatedResp{NotificationTemplateDetails: restored},
+		},
+		{
+			name:    "Should fail if the version doesn't exist",
+			version: 5,
+			setupMock: func() {
+				registryMock.EXPECT().
+					GetTemplateVersion(gomock.Any(), previous.Id, 5).
+					Return(sdto.NotificationTemplateDetails{}, internal.EntityNotFound{
+						Id:   previous.Id,
+						Type: registry.NotificationTemplateVersionType,
+					})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  testutils.StrPtr(previous.Id),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := rollback(previous.Id, tt.version)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			} else if tt.expectedResp != nil {
+				resp := dto.NotificationTemplateUpdatedResp{}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, *tt.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func testPurgeNotificationTemplate(t *testing.T, e *gin.Engine, mock di.MockedBackend) {
+
+	registryMock := mock.Registry.MockNotificationTemplateRegistry
+
+	purgeTemplate := func(templateId string) *httptest.ResponseRecorder {
+		url := fmt.Sprintf("%s/%s/purge", notificationsTemplateUrl, templateId)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	templateId := uuid.NewString()
+
+	tests := []struct {
+		name           string
+		templateId     string
+		setupMock      func()
+		expectedStatus int
+		expectedError  *string
+	}{
+		{
+			name:       "Can purge a template",
+			templateId: templateId,
+			setupMock: func() {
+				registryMock.EXPECT().
+					PurgeTemplate(gomock.Any(), templateId).
+					Return(nil)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(notificationsTemplatesKey)).
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Should fail if the template is referenced",
+			templateId: templateId,
+			setupMock: func() {
+				registryMock.EXPECT().
+					PurgeTemplate(gomock.Any(), templateId).
+					Return(internal.TemplateInUse{Id: templateId})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  testutils.StrPtr("is referenced by notifications or schedules"),
+		},
+		{
+			name:       "Should fail if the template isn't archived",
+			templateId: templateId,
+			setupMock: func() {
+				registryMock.EXPECT().
+					PurgeTemplate(gomock.Any(), templateId).
+					Return(internal.TemplateNotArchived{Id: templateId})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  testutils.StrPtr("must be archived before it's purged"),
+		},
+		{
+			name:           "Should fail if template id is not a valid UUID",
+			templateId:     "not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  testutils.StrPtr(`Key: 'NotificationTemplateUriParams.Id' Error:Field validation for 'Id' failed on the 'uuid' tag`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			w := purgeTemplate(tt.templateId)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != nil {
+				resp := make(map[string]string)
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, resp["error"], *tt.expectedError)
+			}
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 const userNotificationsUrl string = "/users/me/notifications"
 const userConfigUrl string = "/users/me/notifications/config"
+const userDistributionListsUrl string = "/users/me/distribution-lists"
 const userConfigKey = "notifications:endpoint:a2ec7c69d00e4549c50802368fe1c047:/users/1234/notifications/config*"
 const userNotificationsKey = "notifications:endpoint:db31c468fd68d7f5824526c3acb4087e:/users/1234/notifications*"
 
@@ -44,6 +45,8 @@ func TestUserController(t *testing.T) {
 	testGetUserNotifications(t, testApp.Engine, testApp)
 	testGetUserConfig(t, testApp.Engine, testApp)
 	testUpdateUserConfig(t, testApp.Engine, testApp)
+	testGetRecipientConfig(t, testApp.Engine, testApp)
+	testGetUserDistributionLists(t, testApp.Engine, testApp)
 	testSetReadStatus(t, testApp.Engine, testApp)
 	testCreateNotifications(t, testApp.Engine, testApp)
 }
@@ -94,6 +97,59 @@ func testGetUserNotifications(t *testing.T, e *gin.Engine, mock *di.MockedBacken
 		assert.ElementsMatch(t, testNotifications, resp.Data)
 	})
 
+	htmlNotification := testNotifications[0]
+	htmlNotification.HtmlContents = testutils.StrPtr("<p>Test contents</p>")
+
+	t.Run("Should only retrieve the HTML contents if the client asks for them", func(t *testing.T) {
+		for _, format := range []sdto.TemplateFormat{sdto.Text, sdto.Html} {
+			mock.Registry.MockUserRegistry.
+				EXPECT().
+				GetUserNotifications(gomock.Any(), gomock.Any()).
+				Return(sdto.Page[dto.UserNotification]{
+					ResultCount: 1,
+					Data:        []dto.UserNotification{htmlNotification},
+				}, nil)
+
+			filters := dto.UserNotificationFilters{}
+			filters.Format = format
+
+			w := getNotifications(filters)
+
+			resp := sdto.Page[dto.UserNotification]{}
+
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := htmlNotification
+
+			if format == sdto.Text {
+				expected.HtmlContents = nil
+			}
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, []dto.UserNotification{expected}, resp.Data)
+		}
+	})
+
+	t.Run("Should fail if the format is invalid", func(t *testing.T) {
+		filters := dto.UserNotificationFilters{}
+		filters.Format = sdto.Markdown
+
+		w := getNotifications(filters)
+
+		resp := make(map[string]string, 0)
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedMsg := "Key: 'UserNotificationFilters.UserNotificationFormat.Format' Error:Field validation for 'Format' failed on the 'oneof' tag"
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, resp["error"], expectedMsg)
+	})
+
 	t.Run("Should fail if there are duplicated topics on the filter", func(t *testing.T) {
 		topic := "test"
 
@@ -152,6 +208,75 @@ func testGetUserConfig(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
 	})
 }
 
+func testGetUserDistributionLists(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
+
+	summaries := testutils.MakeSummaries(testutils.MakeDistributionLists(3))
+	page := sdto.Page[dto.DistributionListSummary]{
+		ResultCount: len(summaries),
+		Data:        summaries,
+	}
+
+	getDistributionLists := func(query string) *httptest.ResponseRecorder {
+
+		w := httptest.NewRecorder()
+
+		req, _ := http.NewRequest(http.MethodGet, userDistributionListsUrl+query, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+
+		e.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("Can get the lists of the user", func(t *testing.T) {
+		userId := testUserId
+		filters := dto.DistributionListFilters{
+			PageFilter: sdto.PageFilter{MaxResults: testutils.Ptr(3)},
+			Member:     &userId,
+		}
+
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetDistributionLists(gomock.Any(), filters).
+			Return(page, nil)
+
+		w := getDistributionLists("?maxResults=3")
+
+		resp := sdto.Page[dto.DistributionListSummary]{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, page, resp)
+	})
+
+	t.Run("The user can't get the lists of other members", func(t *testing.T) {
+		userId := testUserId
+		filters := dto.DistributionListFilters{Member: &userId}
+
+		mock.Registry.MockDistributionRegistry.
+			EXPECT().
+			GetDistributionLists(gomock.Any(), filters).
+			Return(page, nil)
+
+		w := getDistributionLists("?member=5678")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Should fail if the page size is invalid", func(t *testing.T) {
+		w := getDistributionLists("?maxResults=0")
+
+		resp := make(map[string]string)
+		json.Unmarshal(w.Body.Bytes(), &resp)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, resp["error"], "Error:Field validation for 'MaxResults' failed on the 'min' tag")
+	})
+}
+
 func testUpdateUserConfig(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
 
 	updateUserConfig := func(cfg dto.UserConfig) *httptest.ResponseRecorder {
@@ -210,6 +335,66 @@ func testUpdateUserConfig(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
 		assert.Equal(t, w.Code, http.StatusBadRequest)
 		assert.Contains(t, resp["error"], expectedMsg)
 	})
+
+	t.Run("Should fail if the locale is invalid", func(t *testing.T) {
+		locale := "not a locale"
+
+		userConfig := testutils.MakeTestUserConfig(testUserId)
+		userConfig.Locale = &locale
+
+		w := updateUserConfig(userConfig)
+
+		resp := map[string]string{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		expectedMsg := "UserConfig.Locale' Error:Field validation for 'Locale' failed on the 'bcp47_language_tag' tag"
+
+		assert.Equal(t, w.Code, http.StatusBadRequest)
+		assert.Contains(t, resp["error"], expectedMsg)
+	})
+}
+
+func testGetRecipientConfig(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
+
+	recipient := "5678"
+	locale := "pt-BR"
+
+	cfg := testutils.MakeTestUserConfig(recipient)
+	cfg.Locale = &locale
+
+	getRecipientConfig := func(user string) *httptest.ResponseRecorder {
+
+		w := httptest.NewRecorder()
+
+		url := fmt.Sprintf("/users/%s/notifications/config", user)
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Add(string(auth.UserHeader), testUserId)
+
+		e.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("Can get the configuration of a recipient", func(t *testing.T) {
+		mock.Registry.MockUserRegistry.
+			EXPECT().
+			GetUserConfig(gomock.Any(), recipient).
+			Return(cfg, nil)
+
+		w := getRecipientConfig(recipient)
+
+		resp := dto.UserConfig{}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, cfg, resp)
+	})
 }
 
 func testSetReadStatus(t *testing.T, e *gin.Engine, mock *di.MockedBackend) {
@@ -324,6 +509,50 @@ func testCreateNotifications(t *testing.T, e *gin.Engine, mock *di.MockedBackend
 					Times(1)
 			},
 		},
+		{
+			name: "Should sanitize the HTML contents",
+			notifications: []sdto.UserNotificationReq{{
+				UserId:       testUserId,
+				Title:        "Test notification",
+				Contents:     "Test contents",
+				HtmlContents: testutils.StrPtr(`<p onclick="steal()">Test contents</p><script>steal()</script>`),
+				Topic:        "test-topic",
+			}},
+			expectedCode: http.StatusNoContent,
+			mockSetup: func() {
+				isSanitized := func(batch []sdto.UserNotificationReq) bool {
+					return len(batch) == 1 &&
+						batch[0].HtmlContents != nil &&
+						*batch[0].HtmlContents == "<p>Test contents</p>"
+				}
+
+				testNotification := dto.UserNotification{
+					Id:           uuid.NewString(),
+					Title:        "Test notification",
+					Contents:     "Test contents",
+					HtmlContents: testutils.StrPtr("<p>Test contents</p>"),
+					Topic:        "test-topic",
+					CreatedAt:    time.Now().Format(time.RFC3339),
+				}
+
+				mock.Registry.MockUserRegistry.
+					EXPECT().
+					CreateNotifications(gomock.Any(), gomock.Cond(isSanitized)).
+					Return([]dto.UserNotification{testNotification}, nil).
+					Times(1)
+
+				mock.Cache.
+					EXPECT().
+					DelWithPrefix(gomock.Any(), cache.Key(userNotificationsKey)).
+					Return(nil)
+
+				mock.Broker.
+					EXPECT().
+					Publish(gomock.Any(), testUserId, testNotification).
+					Return(nil).
+					Times(1)
+			},
+		},
 		{
 			name: "Should fail if title is empty",
 			notifications: []sdto.UserNotificationReq{{
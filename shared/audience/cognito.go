@@ -0,0 +1,215 @@
+package audience
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/notifique/shared/dto"
+)
+
+type UserPoolID string
+
+type CognitoConfigurator interface {
+	GetUserPoolId() (UserPoolID, error)
+}
+
+// Cognito resolves the segments with the users of a cognito user pool.
+type Cognito struct {
+	client     *cognitoidentityprovider.Client
+	userPoolID string
+}
+
+type cognitoUser struct {
+	id         string
+	attributes UserAttributes
+}
+
+// segmentGroups returns the groups used by the rules of the segment.
+// Every group is needed when a rule checks if the users have groups.
+func segmentGroups(segment dto.Segment) (groups []string, all bool) {
+
+	for _, rule := range segment.Rules {
+		if rule.Attribute != GroupsAttribute {
+			continue
+		}
+
+		switch rule.Operator {
+		case dto.SegmentOperatorExists, dto.SegmentOperatorNotExists:
+			all = true
+		default:
+			groups = append(groups, rule.Values...)
+		}
+	}
+
+	slices.Sort(groups)
+
+	return slices.Compact(groups), all
+}
+
+func (c *Cognito) listGroups(ctx context.Context) ([]string, error) {
+
+	groups := []string{}
+
+	paginator := cognitoidentityprovider.NewListGroupsPaginator(
+		c.client, &cognitoidentityprovider.ListGroupsInput{
+			UserPoolId: &c.userPoolID,
+		})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return groups, fmt.Errorf("failed to list the groups - %w", err)
+		}
+
+		for _, g := range page.Groups {
+			groups = append(groups, aws.ToString(g.GroupName))
+		}
+	}
+
+	return groups, nil
+}
+
+func (c *Cognito) listGroupMembers(ctx context.Context, group string) ([]string, error) {
+
+	members := []string{}
+
+	paginator := cognitoidentityprovider.NewListUsersInGroupPaginator(
+		c.client, &cognitoidentityprovider.ListUsersInGroupInput{
+			UserPoolId: &c.userPoolID,
+			GroupName:  &group,
+		})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return members, fmt.Errorf("failed to list the users of group %s - %w", group, err)
+		}
+
+		for _, u := range page.Users {
+			members = append(members, aws.ToString(u.Username))
+		}
+	}
+
+	return members, nil
+}
+
+func (c *Cognito) listUsers(ctx context.Context) ([]cognitoUser, error) {
+
+	users := []cognitoUser{}
+
+	paginator := cognitoidentityprovider.NewListUsersPaginator(
+		c.client, &cognitoidentityprovider.ListUsersInput{
+			UserPoolId: &c.userPoolID,
+		})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return users, fmt.Errorf("failed to list the users - %w", err)
+		}
+
+		for _, u := range page.Users {
+			if !u.Enabled {
+				continue
+			}
+
+			attributes := UserAttributes{}
+
+			for _, attr := range u.Attributes {
+				name := aws.ToString(attr.Name)
+				attributes[name] = append(attributes[name], aws.ToString(attr.Value))
+			}
+
+			users = append(users, cognitoUser{
+				id:         aws.ToString(u.Username),
+				attributes: attributes,
+			})
+		}
+	}
+
+	return users, nil
+}
+
+// Resolve returns the users of the pool that belong to the segment,
+// sorted by their ids.
+func (c *Cognito) Resolve(ctx context.Context, segment dto.Segment) ([]string, error) {
+
+	groups, all := segmentGroups(segment)
+
+	if all {
+		allGroups, err := c.listGroups(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		groups = allGroups
+	}
+
+	memberships := map[string][]string{}
+
+	for _, group := range groups {
+		members, err := c.listGroupMembers(ctx, group)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range members {
+			memberships[m] = append(memberships[m], group)
+		}
+	}
+
+	users, err := c.listUsers(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := []string{}
+
+	for _, u := range users {
+		u.attributes[GroupsAttribute] = memberships[u.id]
+
+		if Matches(segment, u.attributes) {
+			recipients = append(recipients, u.id)
+		}
+	}
+
+	slices.Sort(recipients)
+
+	return recipients, nil
+}
+
+// Preview resolves the segment and returns the number of users that
+// belong to it with a sample of up to sampleSize of them.
+func (c *Cognito) Preview(ctx context.Context, segment dto.Segment, sampleSize int) (dto.SegmentPreview, error) {
+
+	recipients, err := c.Resolve(ctx, segment)
+
+	if err != nil {
+		return dto.SegmentPreview{}, err
+	}
+
+	return Sample(recipients, sampleSize), nil
+}
+
+func NewCognito(c CognitoConfigurator, client *cognitoidentityprovider.Client) (*Cognito, error) {
+
+	userPoolID, err := c.GetUserPoolId()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the user pool id - %w", err)
+	}
+
+	return &Cognito{
+		client:     client,
+		userPoolID: string(userPoolID),
+	}, nil
+}
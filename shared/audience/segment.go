@@ -0,0 +1,71 @@
+package audience
+
+import (
+	"slices"
+
+	"github.com/notifique/shared/dto"
+)
+
+// GroupsAttribute is the attribute with the groups of the users, named
+// after the claim of the groups in the cognito tokens.
+const GroupsAttribute = "cognito:groups"
+
+// UserAttributes are the values of the attributes of a user. Attributes
+// like the groups can have more than one value.
+type UserAttributes map[string][]string
+
+func matchesRule(rule dto.SegmentRule, attributes UserAttributes) bool {
+
+	values := attributes[rule.Attribute]
+
+	in := slices.ContainsFunc(values, func(v string) bool {
+		return slices.Contains(rule.Values, v)
+	})
+
+	switch rule.Operator {
+	case dto.SegmentOperatorIn:
+		return in
+	case dto.SegmentOperatorNotIn:
+		return !in
+	case dto.SegmentOperatorExists:
+		return len(values) != 0
+	case dto.SegmentOperatorNotExists:
+		return len(values) == 0
+	}
+
+	return false
+}
+
+// Matches checks if the user with the attributes belongs to the segment.
+// Segments matching ALL rules need every rule to match, while segments
+// matching ANY rule need one of them.
+func Matches(segment dto.Segment, attributes UserAttributes) bool {
+
+	matches := func(rule dto.SegmentRule) bool {
+		return matchesRule(rule, attributes)
+	}
+
+	if segment.Match == dto.SegmentMatchAny {
+		return slices.ContainsFunc(segment.Rules, matches)
+	}
+
+	for _, rule := range segment.Rules {
+		if !matches(rule) {
+			return false
+		}
+	}
+
+	return len(segment.Rules) != 0
+}
+
+// Sample makes the preview of the recipients of a segment with up to
+// sampleSize of them.
+func Sample(recipients []string, sampleSize int) dto.SegmentPreview {
+
+	sample := recipients[:min(len(recipients), sampleSize)]
+
+	return dto.SegmentPreview{
+		Count:  len(recipients),
+		Sample: slices.Clone(sample),
+	}
+}
@@ -22,12 +22,16 @@ type RedisConfigurator interface {
 type CacheRedisApi interface {
 	Get(ctx context.Context, key string) *redis.StringCmd
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
 	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
 }
 
 type Cache interface {
 	Set(ctx context.Context, k Key, value string, ttl time.Duration) error
+	// SetNX sets the key only if it doesn't exist. It returns false if
+	// the key already exists.
+	SetNX(ctx context.Context, k Key, value string, ttl time.Duration) (bool, error)
 	Get(ctx context.Context, k Key) (string, error, bool)
 	Del(ctx context.Context, k Key) error
 	DelWithPrefix(ctx context.Context, prefix Key) error
@@ -41,8 +45,10 @@ func GetNotificationStatusKey(notificationId string) Key {
 	return Key(fmt.Sprintf("notifications:%s:status", notificationId))
 }
 
-func GetHashKey(hash string) Key {
-	return Key(fmt.Sprintf("notifications:hash:%s", hash))
+// GetIdempotencyKey scopes the idempotency keys by user, the key
+// supplied by the user is hashed to bound the size of the cache key.
+func GetIdempotencyKey(userId, idempotencyKey string) Key {
+	return Key(fmt.Sprintf("notifications:idempotency:%s:%s", userId, hash.GetMd5Hash(idempotencyKey)))
 }
 
 func prepareEndpointPath(path string, userId *string) string {
@@ -90,6 +96,17 @@ func (rc *Redis) Set(ctx context.Context, k Key, value string, ttl time.Duration
 	return nil
 }
 
+func (rc *Redis) SetNX(ctx context.Context, k Key, value string, ttl time.Duration) (bool, error) {
+
+	ok, err := rc.client.SetNX(ctx, string(k), value, ttl).Result()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to set key - %w", err)
+	}
+
+	return ok, nil
+}
+
 func (rc *Redis) Get(ctx context.Context, k Key) (string, error, bool) {
 
 	data, err := rc.client.Get(ctx, string(k)).Result()
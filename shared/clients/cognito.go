@@ -0,0 +1,41 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+)
+
+type CognitoClientConfig struct {
+	BaseEndpoint *string
+	Region       *string
+}
+
+type CognitoConfigurator interface {
+	GetCognitoClientConfig() CognitoClientConfig
+}
+
+func NewCognitoClient(c CognitoConfigurator) (*cognitoidentityprovider.Client, error) {
+
+	clientCfg := c.GetCognitoClientConfig()
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default config - %w", err)
+	}
+
+	client := cognitoidentityprovider.NewFromConfig(cfg, func(o *cognitoidentityprovider.Options) {
+		if clientCfg.BaseEndpoint != nil {
+			o.BaseEndpoint = clientCfg.BaseEndpoint
+		}
+
+		if clientCfg.Region != nil {
+			o.Region = *clientCfg.Region
+		}
+	})
+
+	return client, nil
+}
@@ -1 +1,36 @@
 package dto
+
+type SegmentMatch string
+type SegmentOperator string
+
+const (
+	SegmentMatchAll SegmentMatch = "ALL"
+	SegmentMatchAny SegmentMatch = "ANY"
+)
+
+const (
+	SegmentOperatorIn        SegmentOperator = "IN"
+	SegmentOperatorNotIn     SegmentOperator = "NOT_IN"
+	SegmentOperatorExists    SegmentOperator = "EXISTS"
+	SegmentOperatorNotExists SegmentOperator = "NOT_EXISTS"
+)
+
+// SegmentRule is a condition over an attribute of the users, e.g. the
+// groups they belong to or one of their custom attributes.
+type SegmentRule struct {
+	Attribute string          `json:"attribute" binding:"required,max=120"`
+	Operator  SegmentOperator `json:"operator" binding:"required,oneof=IN NOT_IN EXISTS NOT_EXISTS"`
+	Values    []string        `json:"values,omitempty" binding:"max=100,unique,dive,min=1,max=256"`
+}
+
+// Segment defines the recipients of a distribution list by rules,
+// which are evaluated against the users when the notifications are sent.
+type Segment struct {
+	Match SegmentMatch  `json:"match" binding:"required,oneof=ALL ANY"`
+	Rules []SegmentRule `json:"rules" binding:"required,min=1,max=20,dive"`
+}
+
+type SegmentPreview struct {
+	Count  int      `json:"count"`
+	Sample []string `json:"sample"`
+}
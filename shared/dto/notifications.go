@@ -5,12 +5,13 @@ type NotificationPriority string
 type NotificationStatus string
 
 const (
-	Created  NotificationStatus = "CREATED"
-	Queued   NotificationStatus = "QUEUED"
-	Failed   NotificationStatus = "FAILED"
-	Sending  NotificationStatus = "SENDING"
-	Sent     NotificationStatus = "SENT"
-	Canceled NotificationStatus = "CANCELED"
+	Created   NotificationStatus = "CREATED"
+	Queued    NotificationStatus = "QUEUED"
+	Failed    NotificationStatus = "FAILED"
+	Sending   NotificationStatus = "SENDING"
+	Sent      NotificationStatus = "SENT"
+	Canceled  NotificationStatus = "CANCELED"
+	Scheduled NotificationStatus = "SCHEDULED"
 
 	Email NotificationChannel = "e-mail"
 	InApp NotificationChannel = "in-app"
@@ -31,8 +32,10 @@ type TemplateVariableContents struct {
 }
 
 type TemplateContents struct {
-	Id        string                     `json:"id" binding:"required,uuid"`
-	Variables []TemplateVariableContents `json:"variables" binding:"required,unique,dive"`
+	Id                 string                                `json:"id" binding:"required,uuid"`
+	Version            *int                                  `json:"version,omitempty" binding:"omitempty,min=1"`
+	Variables          []TemplateVariableContents            `json:"variables" binding:"required,unique,dive"`
+	RecipientVariables map[string][]TemplateVariableContents `json:"recipientVariables,omitempty" binding:"omitempty,max=256,dive,keys,min=1,endkeys,unique,dive"`
 }
 
 type NotificationReq struct {
@@ -44,6 +47,7 @@ type NotificationReq struct {
 	DistributionList *string               `json:"distributionList" binding:"omitempty,max=120,min=3,distributionlistname"`
 	Recipients       []string              `json:"recipients" binding:"unique,max=256,dive,min=1"`
 	Channels         []NotificationChannel `json:"channels" binding:"unique,dive,oneof=e-mail sms in-app"`
+	SendAt           *string               `json:"sendAt" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00,future"`
 }
 
 type NotificationMsgPayload struct {
@@ -60,7 +64,7 @@ type NotificationMsg struct {
 
 type NotificationStatusLog struct {
 	NotificationId string             `json:"notificationId" binding:"required,uuid"`
-	Status         NotificationStatus `json:"status" binding:"required,oneof=CREATED QUEUED FAILED SENDING SENT CANCELED"`
+	Status         NotificationStatus `json:"status" binding:"required,oneof=CREATED QUEUED FAILED SENDING SENT CANCELED SCHEDULED"`
 	ErrorMsg       *string            `json:"errorMsg" binding:"omitempty"`
 }
 
@@ -81,6 +85,51 @@ type NotificationStatusResp struct {
 	Status NotificationStatus `json:"status"`
 }
 
+// NotificationCreatedResp is the created notification. The Stripped
+// are the HTML removed from its raw contents when they were sanitized.
+type NotificationCreatedResp struct {
+	Id        string             `json:"id"`
+	Status    NotificationStatus `json:"status"`
+	CreatedAt string             `json:"createdAt"`
+	Stripped  []StrippedContent  `json:"stripped,omitempty"`
+}
+
+// GetRecipientVariables returns the variables of the recipient, where
+// the values of the recipient override the shared ones.
+func (t TemplateContents) GetRecipientVariables(userId string) []TemplateVariableContents {
+
+	overrides, ok := t.RecipientVariables[userId]
+
+	if !ok {
+		return t.Variables
+	}
+
+	overridden := make(map[string]string, len(overrides))
+
+	for _, v := range overrides {
+		overridden[v.Name] = v.Value
+	}
+
+	variables := make([]TemplateVariableContents, 0, len(t.Variables)+len(overrides))
+
+	for _, v := range t.Variables {
+		if value, ok := overridden[v.Name]; ok {
+			v.Value = value
+			delete(overridden, v.Name)
+		}
+
+		variables = append(variables, v)
+	}
+
+	for _, v := range overrides {
+		if _, ok := overridden[v.Name]; ok {
+			variables = append(variables, v)
+		}
+	}
+
+	return variables
+}
+
 func (c NotificationChannel) ToStrSlice(channels []NotificationChannel) []string {
 	strChannels := make([]string, 0, len(channels))
 
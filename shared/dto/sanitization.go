@@ -0,0 +1,12 @@
+package dto
+
+// StrippedContent is HTML removed from a field when it was sanitized:
+// an element, an attribute of an element or a property of a style
+// attribute, along with the number of times it was removed.
+type StrippedContent struct {
+	Field     string `json:"field"`
+	Element   string `json:"element"`
+	Attribute string `json:"attribute,omitempty"`
+	Property  string `json:"property,omitempty"`
+	Count     int    `json:"count"`
+}
@@ -1,22 +1,109 @@
 package dto
 
+type TemplateFormat string
+
+const (
+	Text     TemplateFormat = "TEXT"
+	Html     TemplateFormat = "HTML"
+	Markdown TemplateFormat = "MARKDOWN"
+)
+
+// LegacyFormat is the format of the templates that only have isHtml,
+// which were created before the templates had a format.
+func LegacyFormat(isHtml bool) TemplateFormat {
+
+	if isHtml {
+		return Html
+	}
+
+	return Text
+}
+
+// TemplateVariable is a variable of a template. Min and Max bound the
+// INTEGER and NUMBER values, MinLength and MaxLength the length of the
+// STRING values and Values are the allowed values of an ENUM.
 type TemplateVariable struct {
-	Name       string  `json:"name" binding:"required,max=120,templatevarname"`
-	Type       string  `json:"type" binding:"required,oneof=STRING DATE DATETIME NUMBER"`
-	Required   bool    `json:"required"`
-	Validation *string `json:"validation"`
+	Name       string   `json:"name" binding:"required,max=120,templatevarname"`
+	Type       string   `json:"type" binding:"required,oneof=STRING DATE DATETIME NUMBER BOOLEAN INTEGER ENUM URL EMAIL CURRENCY"`
+	Required   bool     `json:"required"`
+	Validation *string  `json:"validation"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	MinLength  *int     `json:"minLength,omitempty" binding:"omitempty,min=0"`
+	MaxLength  *int     `json:"maxLength,omitempty" binding:"omitempty,min=1"`
+	Values     []string `json:"values,omitempty" binding:"omitempty,max=100,unique,dive,required,max=120"`
+}
+
+// TemplateLocalization is the title and contents of a template in a
+// locale, a BCP 47 language tag such as pt-BR.
+type TemplateLocalization struct {
+	Locale           string `json:"locale" binding:"required,max=35,bcp47_language_tag"`
+	TitleTemplate    string `json:"titleTemplate" binding:"required,max=120"`
+	ContentsTemplate string `json:"contentsTemplate" binding:"required,max=4096"`
 }
 
+// NotificationTemplateDetails is a version of a template. The title and
+// contents are the ones of the DefaultLocale, which are used when none
+// of the Localizations matches the locale of the recipient. The Format
+// is the format of the contents, IsHtml is kept for the clients that
+// don't know the formats and is true for the HTML and MARKDOWN ones.
 type NotificationTemplateDetails struct {
-	Id               string             `json:"id"`
-	Name             string             `json:"name"`
-	IsHtml           bool               `json:"isHtml"`
-	Description      string             `json:"description"`
-	TitleTemplate    string             `json:"titleTemplate"`
-	ContentsTemplate string             `json:"contentsTemplate"`
-	CreatedAt        string             `json:"createdAt"`
-	CreatedBy        string             `json:"createdBy"`
-	UpdatedAt        *string            `json:"updatedAt"`
-	UpdatedBy        *string            `json:"updatedBy"`
-	Variables        []TemplateVariable `json:"variables"`
+	Id               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	Version          int                    `json:"version"`
+	IsHtml           bool                   `json:"isHtml"`
+	Format           TemplateFormat         `json:"format"`
+	Description      string                 `json:"description"`
+	Category         *string                `json:"category,omitempty"`
+	Tags             []string               `json:"tags,omitempty"`
+	TitleTemplate    string                 `json:"titleTemplate"`
+	ContentsTemplate string                 `json:"contentsTemplate"`
+	Layout           *string                `json:"layout,omitempty"`
+	DefaultLocale    *string                `json:"defaultLocale,omitempty"`
+	Localizations    []TemplateLocalization `json:"localizations,omitempty"`
+	CreatedAt        string                 `json:"createdAt"`
+	CreatedBy        string                 `json:"createdBy"`
+	UpdatedAt        *string                `json:"updatedAt"`
+	UpdatedBy        *string                `json:"updatedBy"`
+	ArchivedAt       *string                `json:"archivedAt,omitempty"`
+	ArchivedBy       *string                `json:"archivedBy,omitempty"`
+	Variables        []TemplateVariable     `json:"variables"`
+}
+
+// TemplatePartialDetails is a version of a partial, a piece of template
+// shared by the templates. The LAYOUT partials wrap the contents of the
+// templates, which they include with {{template "content" .}}, and the
+// PARTIAL ones are included inline.
+type TemplatePartialDetails struct {
+	Name        string  `json:"name"`
+	Kind        string  `json:"kind"`
+	Version     int     `json:"version"`
+	Description string  `json:"description"`
+	Contents    string  `json:"contents"`
+	CreatedAt   string  `json:"createdAt"`
+	CreatedBy   string  `json:"createdBy"`
+	UpdatedAt   *string `json:"updatedAt"`
+	UpdatedBy   *string `json:"updatedBy"`
+}
+
+// RenderedTemplate is a rendered template. The contents of HTML and
+// MARKDOWN templates are HTML, with TextContents as their plain text
+// alternative.
+type RenderedTemplate struct {
+	Title        string `json:"title"`
+	Contents     string `json:"contents"`
+	TextContents string `json:"textContents,omitempty"`
+	IsHtml       bool   `json:"isHtml"`
+}
+
+// TemplateLintIssue is a problem found in a template. The Field is the
+// json path of the field, e.g. localizations[0].contentsTemplate, and
+// the Line and Column, starting at 1, locate the problem in the field
+// when it's in a template.
+type TemplateLintIssue struct {
+	Field    string `json:"field"`
+	Variable string `json:"variable"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
 }
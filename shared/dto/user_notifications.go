@@ -1,15 +1,22 @@
 package dto
 
+// UserNotificationReq is an in-app notification. The Contents are
+// plain text, the HtmlContents are the HTML version of the contents,
+// for the clients that can display HTML.
 type UserNotificationReq struct {
-	UserId   string  `json:"userId" binding:"required"`
-	Title    string  `json:"title" binding:"required,max=120"`
-	Contents string  `json:"contents" binding:"required,max=1024"`
-	Topic    string  `json:"topic" binding:"required,min=1,max=120"`
-	Image    *string `json:"image" binding:"omitempty,uri"`
+	UserId       string  `json:"userId" binding:"required"`
+	Title        string  `json:"title" binding:"required,max=120"`
+	Contents     string  `json:"contents" binding:"required,max=1024"`
+	HtmlContents *string `json:"htmlContents,omitempty" binding:"omitempty,max=4096"`
+	Topic        string  `json:"topic" binding:"required,min=1,max=120"`
+	Image        *string `json:"image" binding:"omitempty,uri"`
 }
 
+// UserEmailNotificationReq is an e-mail notification. The TextContents
+// are the plain text alternative of HTML contents.
 type UserEmailNotificationReq struct {
 	UserNotificationReq
-	Email  string `json:"email" binding:"required,email"`
-	IsHtml bool   `json:"isHtml" binding:"required"`
+	Email        string `json:"email" binding:"required,email"`
+	IsHtml       bool   `json:"isHtml" binding:"required"`
+	TextContents string `json:"textContents,omitempty"`
 }
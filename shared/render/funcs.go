@@ -0,0 +1,164 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"BRL": "R$",
+	"JPY": "¥",
+}
+
+// currencyDecimals are the ISO 4217 minor units of the currencies that
+// don't have 2 decimals.
+var currencyDecimals = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0,
+	"KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0,
+	"VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3,
+	"TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// funcs are the functions available to the templates. They don't have
+// side effects and don't fail, the value is returned as is when it
+// can't be formatted, so a template never fails because of its data.
+var funcs = map[string]any{
+	"formatDate":     formatDate,
+	"formatNumber":   formatNumber,
+	"formatCurrency": formatCurrency,
+	"default":        defaultValue,
+	"pluralize":      pluralize,
+	"upper":          strings.ToUpper,
+	"lower":          strings.ToLower,
+	"trim":           strings.TrimSpace,
+}
+
+func toFloat(value any) (float64, bool) {
+
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case Number:
+		return v.Value, true
+	case Integer:
+		return float64(v.Value), true
+	case Currency:
+		return v.Amount, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// formatDate formats a DATE or DATETIME value with the Go layout.
+func formatDate(layout string, value any) string {
+
+	switch v := value.(type) {
+	case Date:
+		return v.Format(layout)
+	case time.Time:
+		return v.Format(layout)
+	}
+
+	str := fmt.Sprint(value)
+
+	for _, format := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(format, str); err == nil {
+			return t.Format(layout)
+		}
+	}
+
+	return str
+}
+
+// formatNumber formats the value with the number of decimals and the
+// thousands separated by commas.
+func formatNumber(decimals int, value any) string {
+
+	f, ok := toFloat(value)
+
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Sprint(value)
+	}
+
+	formatted := strconv.FormatFloat(math.Abs(f), 'f', max(decimals, 0), 64)
+	integer, fraction, hasFraction := strings.Cut(formatted, ".")
+
+	var b strings.Builder
+
+	if f < 0 {
+		b.WriteString("-")
+	}
+
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(",")
+		}
+
+		b.WriteRune(digit)
+	}
+
+	if hasFraction {
+		b.WriteString(".")
+		b.WriteString(fraction)
+	}
+
+	return b.String()
+}
+
+// formatCurrency formats the value as an amount of the ISO 4217
+// currency code, with the minor units of the currency.
+func formatCurrency(code string, value any) string {
+
+	code = strings.ToUpper(code)
+	decimals, ok := currencyDecimals[code]
+
+	if !ok {
+		decimals = 2
+	}
+
+	amount := formatNumber(decimals, value)
+
+	if symbol, ok := currencySymbols[code]; ok {
+		return symbol + amount
+	}
+
+	return code + " " + amount
+}
+
+// defaultValue returns the default when the value is empty.
+func defaultValue(def any, value any) any {
+
+	if value == nil {
+		return def
+	}
+
+	if str, ok := value.(string); ok && str == "" {
+		return def
+	}
+
+	return value
+}
+
+func pluralize(singular, plural string, count any) string {
+
+	if f, ok := toFloat(count); ok && f == 1 {
+		return singular
+	}
+
+	return plural
+}
@@ -0,0 +1,263 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	tparse "text/template/parse"
+	"unicode/utf8"
+
+	"github.com/notifique/shared/dto"
+)
+
+// singleWordAction matches the actions with a single word, which are
+// {{name}} placeholders unless the word belongs to the template language
+var singleWordAction = regexp.MustCompile(`\{\{\s*(\{[^{}\s]+\}|[^{}\s"'` + "`" + `()|]+)\s*\}\}`)
+
+// templateWords are the keywords and builtin functions of the templates
+var templateWords = []string{
+	"if", "range", "with", "define", "block", "template", "end", "else",
+	"break", "continue", "nil", "true", "false",
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or",
+	"print", "printf", "println", "urlquery", "eq", "ge", "gt", "le", "lt", "ne",
+}
+
+// reference is a variable referenced by a template, at the byte offset
+// of the reference in the template
+type reference struct {
+	name string
+	pos  int
+}
+
+func isPlaceholder(word string) bool {
+
+	if _, ok := funcs[word]; ok || slices.Contains(templateWords, word) {
+		return false
+	}
+
+	// Fields, variables and numbers
+	return !strings.ContainsAny(word[:1], ".$+-0123456789")
+}
+
+// mask replaces the bytes between start and end with spaces, so the
+// offsets of the rest of the template are kept
+func mask(tmpl []byte, start, end int) {
+	for i := start; i < end; i++ {
+		tmpl[i] = ' '
+	}
+}
+
+// references returns the variables referenced by the template, with
+// {{index . "name"}}, {{.name}} or the {{name}} placeholders, which
+// may not be declared. When the template can't be parsed only the
+// placeholders are returned, the error is reported when the template is
+// validated.
+func references(name, tmpl string, variables []dto.TemplateVariable) []reference {
+
+	refs := []reference{}
+	masked := []byte(tmpl)
+
+	for _, v := range variables {
+		for _, m := range legacyPlaceholder(v.Name).FindAllStringIndex(string(masked), -1) {
+			refs = append(refs, reference{name: v.Name, pos: m[0]})
+			mask(masked, m[0], m[1])
+		}
+	}
+
+	for _, m := range singleWordAction.FindAllStringSubmatchIndex(string(masked), -1) {
+		if word := tmpl[m[2]:m[3]]; isPlaceholder(word) {
+			refs = append(refs, reference{name: word, pos: m[0]})
+			mask(masked, m[0], m[1])
+		}
+	}
+
+	tree := tparse.New(name)
+	tree.Mode = tparse.SkipFuncCheck
+
+	treeSet := map[string]*tparse.Tree{}
+
+	if _, err := tree.Parse(string(masked), "", "", treeSet); err == nil {
+		collectReferences(tree.Root, true, &refs)
+
+		for _, t := range treeSet {
+			if t != tree {
+				collectReferences(t.Root, true, &refs)
+			}
+		}
+	}
+
+	slices.SortFunc(refs, func(a, b reference) int {
+		return a.pos - b.pos
+	})
+
+	return refs
+}
+
+// collectReferences adds the variables referenced by the node. The dot
+// is the root of the data unless it was changed by a range or with.
+func collectReferences(node tparse.Node, root bool, refs *[]reference) {
+
+	switch n := node.(type) {
+	case *tparse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			collectReferences(child, root, refs)
+		}
+	case *tparse.ActionNode:
+		collectReferences(n.Pipe, root, refs)
+	case *tparse.IfNode:
+		collectReferences(n.Pipe, root, refs)
+		collectReferences(n.List, root, refs)
+		collectReferences(n.ElseList, root, refs)
+	case *tparse.RangeNode:
+		collectReferences(n.Pipe, root, refs)
+		collectReferences(n.List, false, refs)
+		collectReferences(n.ElseList, root, refs)
+	case *tparse.WithNode:
+		collectReferences(n.Pipe, root, refs)
+		collectReferences(n.List, false, refs)
+		collectReferences(n.ElseList, root, refs)
+	case *tparse.TemplateNode:
+		collectReferences(n.Pipe, root, refs)
+	case *tparse.PipeNode:
+		if n == nil {
+			return
+		}
+
+		for _, cmd := range n.Cmds {
+			collectReferences(cmd, root, refs)
+		}
+	case *tparse.CommandNode:
+		if ref, ok := indexReference(n, root); ok {
+			*refs = append(*refs, ref)
+		}
+
+		for _, arg := range n.Args {
+			collectReferences(arg, root, refs)
+		}
+	case *tparse.FieldNode:
+		if root {
+			*refs = append(*refs, reference{name: n.Ident[0], pos: int(n.Pos)})
+		}
+	case *tparse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			*refs = append(*refs, reference{name: n.Ident[1], pos: int(n.Pos)})
+		}
+	}
+}
+
+// indexReference returns the variable of an {{index . "name"}} command
+func indexReference(cmd *tparse.CommandNode, root bool) (reference, bool) {
+
+	if len(cmd.Args) < 3 {
+		return reference{}, false
+	}
+
+	if fn, ok := cmd.Args[0].(*tparse.IdentifierNode); !ok || fn.Ident != "index" {
+		return reference{}, false
+	}
+
+	switch data := cmd.Args[1].(type) {
+	case *tparse.DotNode:
+		if !root {
+			return reference{}, false
+		}
+	case *tparse.VariableNode:
+		if len(data.Ident) != 1 || data.Ident[0] != "$" {
+			return reference{}, false
+		}
+	default:
+		return reference{}, false
+	}
+
+	key, ok := cmd.Args[2].(*tparse.StringNode)
+
+	if !ok {
+		return reference{}, false
+	}
+
+	return reference{name: key.Text, pos: int(key.Pos)}, true
+}
+
+// location returns the line and column, starting at 1, of the offset
+func location(text string, offset int) (int, int) {
+
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+
+	return line, column
+}
+
+// Lint checks the variables of the template. The variables referenced
+// by the title and contents of the template and its localizations that
+// aren't declared are returned as errors, as they would be rendered as
+// literal braces or empty values. The declared variables that aren't
+// used by them, nor by the layout and partials, are returned as
+// warnings.
+func Lint(t dto.NotificationTemplateDetails, partials Partials) (errs, warnings []dto.TemplateLintIssue) {
+
+	declared := make(map[string]struct{}, len(t.Variables))
+
+	for _, v := range t.Variables {
+		declared[v.Name] = struct{}{}
+	}
+
+	fields := []source{
+		{name: "titleTemplate", text: t.TitleTemplate},
+		{name: "contentsTemplate", text: t.ContentsTemplate},
+	}
+
+	for i, l := range t.Localizations {
+		fields = append(fields,
+			source{name: fmt.Sprintf("localizations[%d].titleTemplate", i), text: l.TitleTemplate},
+			source{name: fmt.Sprintf("localizations[%d].contentsTemplate", i), text: l.ContentsTemplate},
+		)
+	}
+
+	used := map[string]struct{}{}
+
+	for _, f := range fields {
+		for _, ref := range references(f.name, f.text, t.Variables) {
+			used[ref.name] = struct{}{}
+
+			if _, ok := declared[ref.name]; ok {
+				continue
+			}
+
+			line, column := location(f.text, ref.pos)
+
+			errs = append(errs, dto.TemplateLintIssue{
+				Field:    f.name,
+				Variable: ref.name,
+				Line:     line,
+				Column:   column,
+				Message:  fmt.Sprintf("variable %s isn't declared", ref.name),
+			})
+		}
+	}
+
+	for _, p := range partials {
+		for _, ref := range references(p.Name, p.Contents, nil) {
+			used[ref.name] = struct{}{}
+		}
+	}
+
+	for i, v := range t.Variables {
+		if _, ok := used[v.Name]; ok {
+			continue
+		}
+
+		warnings = append(warnings, dto.TemplateLintIssue{
+			Field:    fmt.Sprintf("variables[%d]", i),
+			Variable: v.Name,
+			Message:  fmt.Sprintf("variable %s is declared but never used", v.Name),
+		})
+	}
+
+	return errs, warnings
+}
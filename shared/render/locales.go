@@ -0,0 +1,86 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/notifique/shared/dto"
+)
+
+// normalizeLocale makes locales comparable, as pt-BR, pt_br and PT-br
+// are the same locale.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// localeFallbacks returns the locales tried for the locale, from the
+// most to the least specific one, e.g. zh-Hant-TW, zh-Hant and zh.
+func localeFallbacks(locale string) []string {
+
+	locale = normalizeLocale(locale)
+	fallbacks := []string{}
+
+	for locale != "" {
+		fallbacks = append(fallbacks, locale)
+
+		i := strings.LastIndex(locale, "-")
+
+		if i == -1 {
+			break
+		}
+
+		locale = locale[:i]
+	}
+
+	return fallbacks
+}
+
+// Localization returns the localization of the template that best
+// matches the locale, dropping the subtags of the locale one by one
+// until one matches, so pt-BR falls back to pt. It returns nil when
+// the default title and contents of the template should be used, which
+// is also the case when the DefaultLocale matches before any of the
+// localizations, so pt-BR doesn't fall back to pt when the template is
+// in pt-BR.
+func Localization(t dto.NotificationTemplateDetails, locale *string) *dto.TemplateLocalization {
+
+	if locale == nil || len(t.Localizations) == 0 {
+		return nil
+	}
+
+	localizations := make(map[string]int, len(t.Localizations))
+
+	for i, l := range t.Localizations {
+		localizations[normalizeLocale(l.Locale)] = i
+	}
+
+	defaultLocale := ""
+
+	if t.DefaultLocale != nil {
+		defaultLocale = normalizeLocale(*t.DefaultLocale)
+	}
+
+	for _, fallback := range localeFallbacks(*locale) {
+		if i, ok := localizations[fallback]; ok {
+			return &t.Localizations[i]
+		}
+
+		if fallback == defaultLocale {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Localize returns the template with the title and contents of the
+// localization that best matches the locale, or the template itself
+// when none matches.
+func Localize(t dto.NotificationTemplateDetails, locale *string) dto.NotificationTemplateDetails {
+
+	if l := Localization(t, locale); l != nil {
+		t.TitleTemplate = l.TitleTemplate
+		t.ContentsTemplate = l.ContentsTemplate
+	}
+
+	return t
+}
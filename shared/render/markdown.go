@@ -0,0 +1,312 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingLine = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	ruleLine    = regexp.MustCompile(`^ {0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	fenceLine   = regexp.MustCompile("^ {0,3}(```|~~~)\\s*([^`\\s]*)")
+	quoteLine   = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	bulletItem  = regexp.MustCompile(`^ {0,3}[-*+]\s+(.*)$`)
+	orderedItem = regexp.MustCompile(`^ {0,3}(\d{1,9})[.)]\s+(.*)$`)
+
+	// Code spans are delimited by one or two backticks, so the ones
+	// delimited by two can have backticks
+	codeSpan = regexp.MustCompile("``\\s?(.+?)\\s?``|`([^`]+)`")
+
+	// The URLs can have balanced parentheses, e.g., the ones of wikipedia
+	image = regexp.MustCompile(`!\[([^\]]*)\]\(((?:[^()\s]|\([^()\s]*\))+)\)`)
+	link  = regexp.MustCompile(`\[([^\]]+)\]\(((?:[^()\s]|\([^()\s]*\))+)\)`)
+
+	// The emphasis ends at its first closing delimiter, so **a** and **b**
+	// are two strong texts
+	strongEmphasis = regexp.MustCompile(`\*\*\*(\S(?:.*?\S)??)\*\*\*`)
+	strong         = regexp.MustCompile(`\*\*(\S(?:.*?\S)??)\*\*|__(\S(?:.*?\S)??)__`)
+	emphasis       = regexp.MustCompile(`\*(\S(?:.*?\S)??)\*`)
+	underscore     = regexp.MustCompile(`(^|\W)_(\S(?:.*?\S)??)_(\W|$)`)
+
+	// The URLs in the text aren't formatted, even though they can have
+	// the characters of the emphasis, e.g., https://example.com/__init__.py
+	bareURL = regexp.MustCompile(`(?i)\b(?:https?://|mailto:|tel:|www\.)[^\s<\x00]*[^\s<\x00*_.,;:!?)]`)
+
+	// placeholder replaces the protected parts of the text while it's
+	// formatted. The text can't have NUL characters, they are replaced
+	// before the text is formatted.
+	placeholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// safeSchemes are the schemes allowed in the links and images
+var safeSchemes = []string{"http", "https", "mailto", "tel"}
+
+// Markdown converts markdown to HTML. It supports the markdown used by
+// notifications: headings, paragraphs, emphasis, code, links, images,
+// lists, block quotes and thematic breaks. The HTML of the markdown is
+// escaped instead of passed through, and the links only use safe
+// schemes, so the output doesn't need to be sanitized.
+func Markdown(md string) string {
+
+	var b strings.Builder
+
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	writeBlocks(&b, lines)
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// startsBlock reports whether the line starts a block that interrupts
+// a paragraph
+func startsBlock(line string) bool {
+	return headingLine.MatchString(line) ||
+		ruleLine.MatchString(line) ||
+		fenceLine.MatchString(line) ||
+		quoteLine.MatchString(line) ||
+		bulletItem.MatchString(line) ||
+		orderedItem.MatchString(line)
+}
+
+func writeBlocks(b *strings.Builder, lines []string) {
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+		case fenceLine.MatchString(line):
+			i = writeCode(b, lines, i)
+		case headingLine.MatchString(line):
+			m := headingLine.FindStringSubmatch(line)
+			fmt.Fprintf(b, "<h%d>%s</h%d>\n", len(m[1]), inline(m[2]), len(m[1]))
+			i++
+		case ruleLine.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+		case quoteLine.MatchString(line):
+			quoted := []string{}
+
+			for ; i < len(lines) && quoteLine.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quoteLine.FindStringSubmatch(lines[i])[1])
+			}
+
+			b.WriteString("<blockquote>\n")
+			writeBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+		case bulletItem.MatchString(line):
+			i = writeList(b, lines, i, bulletItem)
+		case orderedItem.MatchString(line):
+			i = writeList(b, lines, i, orderedItem)
+		default:
+			i = writeParagraph(b, lines, i)
+		}
+	}
+}
+
+// writeCode writes the fenced code block that starts at the line and
+// returns the line after it. Blocks that aren't closed end with the
+// markdown.
+func writeCode(b *strings.Builder, lines []string, start int) int {
+
+	m := fenceLine.FindStringSubmatch(lines[start])
+	fence, language := m[1], m[2]
+
+	code := []string{}
+	i := start + 1
+
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+			i++
+			break
+		}
+
+		code = append(code, lines[i])
+	}
+
+	b.WriteString("<pre><code")
+
+	if language != "" {
+		fmt.Fprintf(b, ` class="language-%s"`, html.EscapeString(language))
+	}
+
+	b.WriteString(">")
+
+	for _, l := range code {
+		b.WriteString(html.EscapeString(l))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("</code></pre>\n")
+
+	return i
+}
+
+// writeList writes the list that starts at the line and returns the
+// line after it. The indented lines continue the item before them.
+func writeList(b *strings.Builder, lines []string, start int, item *regexp.Regexp) int {
+
+	items := [][]string{}
+	i := start
+
+	for i < len(lines) {
+		line := lines[i]
+
+		if m := item.FindStringSubmatch(line); m != nil {
+			items = append(items, []string{m[len(m)-1]})
+		} else if !isBlank(line) && (line[0] == ' ' || line[0] == '\t') && !startsBlock(line) {
+			last := len(items) - 1
+			items[last] = append(items[last], strings.TrimSpace(line))
+		} else if isBlank(line) && i+1 < len(lines) && item.MatchString(lines[i+1]) {
+			// Blank lines between the items don't end the list
+		} else {
+			break
+		}
+
+		i++
+	}
+
+	tag, attributes := "ul", ""
+
+	if item == orderedItem {
+		tag = "ol"
+
+		if n, _ := strconv.Atoi(item.FindStringSubmatch(lines[start])[1]); n != 1 {
+			attributes = fmt.Sprintf(` start="%d"`, n)
+		}
+	}
+
+	fmt.Fprintf(b, "<%s%s>\n", tag, attributes)
+
+	for _, lines := range items {
+		fmt.Fprintf(b, "<li>%s</li>\n", inlineLines(lines))
+	}
+
+	fmt.Fprintf(b, "</%s>\n", tag)
+
+	return i
+}
+
+// writeParagraph writes the paragraph that starts at the line and
+// returns the line after it
+func writeParagraph(b *strings.Builder, lines []string, start int) int {
+
+	paragraph := []string{lines[start]}
+	i := start + 1
+
+	for ; i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]); i++ {
+		paragraph = append(paragraph, lines[i])
+	}
+
+	fmt.Fprintf(b, "<p>%s</p>\n", inlineLines(paragraph))
+
+	return i
+}
+
+// inlineLines formats the lines of a block. The lines that end with two
+// spaces or a backslash end with a line break.
+func inlineLines(lines []string) string {
+
+	formatted := make([]string, 0, len(lines))
+
+	for i, l := range lines {
+		l = strings.TrimLeft(l, " \t")
+		hardBreak := i != len(lines)-1 && (strings.HasSuffix(l, "  ") || strings.HasSuffix(l, `\`))
+		l = inline(strings.TrimRight(strings.TrimSuffix(l, `\`), " \t"))
+
+		if hardBreak {
+			l += "<br>"
+		}
+
+		formatted = append(formatted, l)
+	}
+
+	return strings.Join(formatted, "\n")
+}
+
+// safeURL reports whether the url, escaped, is relative or uses a safe
+// scheme
+func safeURL(escaped string) bool {
+
+	url := strings.ToLower(html.UnescapeString(escaped))
+	i := strings.IndexAny(url, ":/?#")
+
+	if i == -1 || url[i] != ':' {
+		return true
+	}
+
+	for _, scheme := range safeSchemes {
+		if url[:i] == scheme {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inline formats the emphasis of the text of a block, its code spans,
+// links and images. The code spans, links, images and URLs are
+// protected, so the contents of the code spans and the URLs aren't
+// formatted.
+func inline(text string) string {
+
+	text = html.EscapeString(strings.ReplaceAll(text, "\x00", "\uFFFD"))
+
+	protected := []string{}
+
+	protect := func(s string) string {
+		protected = append(protected, s)
+		return fmt.Sprintf("\x00%d\x00", len(protected)-1)
+	}
+
+	text = codeSpan.ReplaceAllStringFunc(text, func(s string) string {
+		m := codeSpan.FindStringSubmatch(s)
+		return protect("<code>" + m[1] + m[2] + "</code>")
+	})
+
+	text = image.ReplaceAllStringFunc(text, func(s string) string {
+		m := image.FindStringSubmatch(s)
+
+		if !safeURL(m[2]) {
+			return m[1]
+		}
+
+		return protect(fmt.Sprintf(`<img src="%s" alt="%s">`, m[2], m[1]))
+	})
+
+	// The label of the links is formatted along with the text
+	text = link.ReplaceAllStringFunc(text, func(s string) string {
+		m := link.FindStringSubmatch(s)
+
+		if !safeURL(m[2]) {
+			return m[1]
+		}
+
+		return protect(fmt.Sprintf(`<a href="%s">`, m[2])) + m[1] + protect("</a>")
+	})
+
+	text = bareURL.ReplaceAllStringFunc(text, protect)
+
+	text = strongEmphasis.ReplaceAllString(text, "<em><strong>$1</strong></em>")
+	text = strong.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emphasis.ReplaceAllString(text, "<em>$1</em>")
+
+	// Consecutive emphasis share the characters around their delimiters,
+	// so they are replaced until none is left
+	for formatted := ""; formatted != text; {
+		formatted = text
+		text = underscore.ReplaceAllString(text, "$1<em>$2</em>$3")
+	}
+
+	return placeholder.ReplaceAllStringFunc(text, func(s string) string {
+		i, _ := strconv.Atoi(placeholder.FindStringSubmatch(s)[1])
+		return protected[i]
+	})
+}
@@ -0,0 +1,159 @@
+package render
+
+import (
+	"fmt"
+	"slices"
+	tparse "text/template/parse"
+
+	"github.com/notifique/shared/dto"
+)
+
+const (
+	// ContentTemplate is the name of the contents of the template, which
+	// the layouts include with {{template "content" .}}
+	ContentTemplate = "content"
+	LayoutKind      = "LAYOUT"
+	PartialKind     = "PARTIAL"
+)
+
+// Partials are the layout and the partials of a template, by name
+type Partials map[string]dto.TemplatePartialDetails
+
+// PartialGetter gets the latest version of the partial
+type PartialGetter func(name string) (dto.TemplatePartialDetails, error)
+
+// collectTemplates adds the names of the templates included by the node
+func collectTemplates(node tparse.Node, names map[string]struct{}) {
+
+	switch n := node.(type) {
+	case *tparse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			collectTemplates(child, names)
+		}
+	case *tparse.IfNode:
+		collectTemplates(n.List, names)
+		collectTemplates(n.ElseList, names)
+	case *tparse.RangeNode:
+		collectTemplates(n.List, names)
+		collectTemplates(n.ElseList, names)
+	case *tparse.WithNode:
+		collectTemplates(n.List, names)
+		collectTemplates(n.ElseList, names)
+	case *tparse.TemplateNode:
+		names[n.Name] = struct{}{}
+	}
+}
+
+// Dependencies returns the sorted names of the partials included by the
+// template, leaving out the templates it defines. Only the syntax of
+// the template is checked, the functions it calls aren't.
+func Dependencies(name, tmpl string) ([]string, error) {
+
+	tree := tparse.New(name)
+	tree.Mode = tparse.SkipFuncCheck
+
+	treeSet := map[string]*tparse.Tree{}
+
+	if _, err := tree.Parse(tmpl, "", "", treeSet); err != nil {
+		return nil, err
+	}
+
+	names := map[string]struct{}{}
+
+	collectTemplates(tree.Root, names)
+
+	for _, t := range treeSet {
+		collectTemplates(t.Root, names)
+	}
+
+	dependencies := make([]string, 0, len(names))
+
+	for n := range names {
+		if _, defined := treeSet[n]; !defined && n != name {
+			dependencies = append(dependencies, n)
+		}
+	}
+
+	slices.Sort(dependencies)
+
+	return dependencies, nil
+}
+
+// ResolvePartials gets the layout of the template and the partials it
+// and its localizations include, along with the partials included by
+// them. Templates that can't be parsed don't include partials, their
+// errors are reported when they are rendered.
+func ResolvePartials(t dto.NotificationTemplateDetails, get PartialGetter) (Partials, error) {
+
+	partials := Partials{}
+
+	contents := []string{t.ContentsTemplate}
+
+	for _, l := range t.Localizations {
+		contents = append(contents, l.ContentsTemplate)
+	}
+
+	pending := []string{}
+
+	for _, c := range contents {
+		c = replaceLegacyPlaceholders(c, t.Variables)
+		dependencies, _ := Dependencies(ContentTemplate, c)
+		pending = append(pending, dependencies...)
+	}
+
+	if t.Layout != nil {
+		pending = append(pending, *t.Layout)
+	}
+
+	for len(pending) != 0 {
+		name := pending[0]
+		pending = pending[1:]
+
+		if _, ok := partials[name]; ok || name == ContentTemplate {
+			continue
+		}
+
+		partial, err := get(name)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partial %s - %w", name, err)
+		}
+
+		partials[name] = partial
+
+		dependencies, _ := Dependencies(name, partial.Contents)
+		pending = append(pending, dependencies...)
+	}
+
+	return partials, nil
+}
+
+// ValidatePartial checks the syntax of the partial and that layouts
+// include the contents of the templates. Partials are executed by the
+// templates that use them, which are validated with their variables.
+func ValidatePartial(p dto.TemplatePartialDetails) error {
+
+	if _, err := parseText(source{p.Name, p.Contents}); err != nil {
+		return fmt.Errorf("invalid partial - %w", err)
+	}
+
+	if p.Kind != LayoutKind {
+		return nil
+	}
+
+	dependencies, err := Dependencies(p.Name, p.Contents)
+
+	if err != nil {
+		return fmt.Errorf("invalid partial - %w", err)
+	}
+
+	if !slices.Contains(dependencies, ContentTemplate) {
+		return fmt.Errorf(`layout %s must include the contents with {{template "%s" .}}`, p.Name, ContentTemplate)
+	}
+
+	return nil
+}
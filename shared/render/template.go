@@ -0,0 +1,366 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/notifique/shared/dto"
+)
+
+// executor is the common interface of text and html templates
+type executor interface {
+	Execute(wr io.Writer, data any) error
+}
+
+// sampleValues are used to check that a template can be executed
+var sampleValues = map[string]string{
+	"STRING":   "text",
+	"NUMBER":   "1234.5",
+	"DATE":     time.DateOnly,
+	"DATETIME": "2006-01-02T15:04:05Z",
+	"BOOLEAN":  "true",
+	"INTEGER":  "1234",
+	"ENUM":     "value",
+	"URL":      "https://example.com",
+	"EMAIL":    "user@example.com",
+	"CURRENCY": "1234.5 USD",
+}
+
+// legacyPlaceholder matches the {{name}} placeholder of a variable
+func legacyPlaceholder(name string) *regexp.Regexp {
+	return regexp.MustCompile(`\{\{\s*` + regexp.QuoteMeta(name) + `\s*\}\}`)
+}
+
+// replaceLegacyPlaceholders rewrites the {{name}} placeholders of the
+// declared variables, used before templates were executed by the
+// template engine, so the existing templates keep working. Variable
+// names aren't necessarily identifiers, so they are accessed by index.
+func replaceLegacyPlaceholders(tmpl string, variables []dto.TemplateVariable) string {
+
+	for _, v := range variables {
+		action := fmt.Sprintf("{{index . %s}}", strconv.Quote(v.Name))
+		tmpl = legacyPlaceholder(v.Name).ReplaceAllLiteralString(tmpl, action)
+	}
+
+	return tmpl
+}
+
+// source is a named template of a set of templates
+type source struct {
+	name string
+	text string
+}
+
+// parseText parses the sources as a set of templates, the first one
+// being the template that's executed.
+func parseText(sources ...source) (executor, error) {
+
+	root := texttemplate.New(sources[0].name).
+		Option("missingkey=zero").
+		Funcs(texttemplate.FuncMap(funcs))
+
+	for i, s := range sources {
+		t := root
+
+		if i != 0 {
+			t = root.New(s.name)
+		}
+
+		if _, err := t.Parse(s.text); err != nil {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+// parseHtml parses the sources as a set of html templates, the first
+// one being the template that's executed. The extra functions are only
+// available to these templates.
+func parseHtml(extra htmltemplate.FuncMap, sources ...source) (executor, error) {
+
+	root := htmltemplate.New(sources[0].name).
+		Option("missingkey=zero").
+		Funcs(htmltemplate.FuncMap(funcs)).
+		Funcs(extra)
+
+	for i, s := range sources {
+		t := root
+
+		if i != 0 {
+			t = root.New(s.name)
+		}
+
+		if _, err := t.Parse(s.text); err != nil {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+// markdownBody is the function that includes the contents of markdown
+// templates, converted to HTML, in their layouts
+const markdownBody = "markdownBody"
+
+type parsedTemplate struct {
+	title    executor
+	contents executor
+	format   dto.TemplateFormat
+	// layout wraps the contents of markdown templates, which have to be
+	// converted to HTML before they are included in the layout
+	layout executor
+	body   *htmltemplate.HTML
+}
+
+// Format returns the format of the template, which is derived from
+// isHtml for the templates created before the templates had a format.
+func Format(t dto.NotificationTemplateDetails) dto.TemplateFormat {
+
+	if t.Format != "" {
+		return t.Format
+	}
+
+	return dto.LegacyFormat(t.IsHtml)
+}
+
+// contentSources are the sources of the contents of the template. The
+// layout, when there is one, is executed and includes the contents,
+// which can include the partials.
+func contentSources(t dto.NotificationTemplateDetails, partials Partials) ([]source, error) {
+
+	contents := source{
+		name: ContentTemplate,
+		text: replaceLegacyPlaceholders(t.ContentsTemplate, t.Variables),
+	}
+
+	sources := []source{contents}
+
+	if t.Layout != nil {
+		layout, ok := partials[*t.Layout]
+
+		if !ok {
+			return nil, fmt.Errorf("layout %s not found", *t.Layout)
+		}
+
+		if layout.Kind != LayoutKind {
+			return nil, fmt.Errorf("partial %s isn't a layout", *t.Layout)
+		}
+
+		sources = []source{{name: layout.Name, text: layout.Contents}, contents}
+	}
+
+	names := make([]string, 0, len(partials))
+
+	for name := range partials {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		if t.Layout != nil && name == *t.Layout {
+			continue
+		}
+
+		sources = append(sources, source{name: name, text: partials[name].Contents})
+	}
+
+	return sources, nil
+}
+
+// parse parses the title and contents of the template, along with its
+// layout and partials. The contents of html templates are
+// auto-escaped, the ones of markdown templates are converted to HTML
+// once rendered, and the title is always plain text.
+func parse(t dto.NotificationTemplateDetails, partials Partials) (parsedTemplate, error) {
+
+	parsed := parsedTemplate{format: Format(t)}
+
+	title, err := parseText(source{"title", replaceLegacyPlaceholders(t.TitleTemplate, t.Variables)})
+
+	if err != nil {
+		return parsed, fmt.Errorf("invalid title template - %w", err)
+	}
+
+	sources, err := contentSources(t, partials)
+
+	if err != nil {
+		return parsed, err
+	}
+
+	var contents executor
+
+	switch parsed.format {
+	case dto.Html:
+		contents, err = parseHtml(nil, sources...)
+	case dto.Markdown:
+		contents, err = parseMarkdown(t, sources, &parsed)
+	default:
+		contents, err = parseText(sources...)
+	}
+
+	if err != nil {
+		return parsed, fmt.Errorf("invalid contents template - %w", err)
+	}
+
+	parsed.title = title
+	parsed.contents = contents
+
+	return parsed, nil
+}
+
+// parseMarkdown parses the contents of a markdown template as text,
+// without its layout, which is parsed as HTML that includes the
+// contents once they are converted to HTML.
+func parseMarkdown(t dto.NotificationTemplateDetails, sources []source, parsed *parsedTemplate) (executor, error) {
+
+	if t.Layout == nil {
+		return parseText(sources...)
+	}
+
+	contents, err := parseText(sources[1:]...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.body = new(htmltemplate.HTML)
+
+	body := func() htmltemplate.HTML {
+		return *parsed.body
+	}
+
+	layoutSources := append([]source{
+		sources[0],
+		{name: ContentTemplate, text: fmt.Sprintf("{{%s}}", markdownBody)},
+	}, sources[2:]...)
+
+	parsed.layout, err = parseHtml(htmltemplate.FuncMap{markdownBody: body}, layoutSources...)
+
+	return contents, err
+}
+
+func (p parsedTemplate) execute(data map[string]any) (dto.RenderedTemplate, error) {
+
+	var title, contents bytes.Buffer
+
+	if err := p.title.Execute(&title, data); err != nil {
+		return dto.RenderedTemplate{}, fmt.Errorf("failed to render the title - %w", err)
+	}
+
+	if err := p.contents.Execute(&contents, data); err != nil {
+		return dto.RenderedTemplate{}, fmt.Errorf("failed to render the contents - %w", err)
+	}
+
+	rendered := dto.RenderedTemplate{
+		Title:    title.String(),
+		Contents: contents.String(),
+		IsHtml:   p.format != dto.Text,
+	}
+
+	if p.format == dto.Markdown {
+		rendered.Contents = Markdown(rendered.Contents)
+	}
+
+	if p.layout != nil {
+		var wrapped bytes.Buffer
+
+		*p.body = htmltemplate.HTML(rendered.Contents)
+
+		if err := p.layout.Execute(&wrapped, data); err != nil {
+			return dto.RenderedTemplate{}, fmt.Errorf("failed to render the layout - %w", err)
+		}
+
+		rendered.Contents = wrapped.String()
+	}
+
+	if rendered.IsHtml {
+		rendered.TextContents = PlainText(rendered.Contents)
+	}
+
+	return rendered, nil
+}
+
+// Validate checks the syntax of the template and of its localizations
+// and executes them with sample values, so templates are known to
+// render before being used. The partials are the ones resolved by
+// ResolvePartials.
+func Validate(t dto.NotificationTemplateDetails, partials Partials) error {
+
+	if err := validate(t, partials); err != nil {
+		return err
+	}
+
+	for _, l := range t.Localizations {
+		localized := t
+		localized.TitleTemplate = l.TitleTemplate
+		localized.ContentsTemplate = l.ContentsTemplate
+
+		if err := validate(localized, partials); err != nil {
+			return fmt.Errorf("invalid %s localization - %w", l.Locale, err)
+		}
+	}
+
+	return nil
+}
+
+func validate(t dto.NotificationTemplateDetails, partials Partials) error {
+
+	parsed, err := parse(t, partials)
+
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]any, len(t.Variables))
+
+	for _, v := range t.Variables {
+		sample := sampleValues[v.Type]
+
+		if len(v.Values) != 0 {
+			sample = v.Values[0]
+		}
+
+		data[v.Name] = typedValue(v, sample)
+	}
+
+	_, err = parsed.execute(data)
+
+	return err
+}
+
+// Template renders the title and contents of the template with the
+// variables, converted to the type of the variable, and the partials
+// resolved by ResolvePartials. It's used both by the service, to
+// preview templates, and by the worker, so both render the same output.
+func Template(t dto.NotificationTemplateDetails, partials Partials, variables []dto.TemplateVariableContents) (dto.RenderedTemplate, error) {
+
+	parsed, err := parse(t, partials)
+
+	if err != nil {
+		return dto.RenderedTemplate{}, err
+	}
+
+	// The variables that weren't supplied are rendered as empty values
+	data := make(map[string]any, len(t.Variables))
+	types := make(map[string]dto.TemplateVariable, len(t.Variables))
+
+	for _, v := range t.Variables {
+		data[v.Name] = ""
+		types[v.Name] = v
+	}
+
+	for _, v := range variables {
+		data[v.Name] = typedValue(types[v.Name], v.Value)
+	}
+
+	return parsed.execute(data)
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlComment   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	hiddenElement = regexp.MustCompile(`(?is)<(?:head|style|script|title)\b.*?</(?:head|style|script|title)>`)
+	htmlSpace     = regexp.MustCompile(`\s+`)
+	htmlLink      = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*["']([^"']*)["'][^>]*>(.*?)</a>`)
+	htmlBreak     = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlListItem  = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	htmlParagraph = regexp.MustCompile(`(?i)</(?:p|h[1-6]|ul|ol|table|blockquote|pre)>|<hr\b[^>]*>`)
+	htmlBlock     = regexp.MustCompile(`(?i)</?(?:p|div|h[1-6]|ul|ol|table|tr|blockquote|pre|section|header|footer|main|article)\b[^>]*>`)
+	htmlTag       = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// PlainText converts HTML contents to plain text, the alternative of
+// the HTML contents for the clients that can't display HTML. The
+// blocks are separated by line breaks and the links are followed by
+// their URL.
+func PlainText(contents string) string {
+
+	text := htmlComment.ReplaceAllString(contents, "")
+	text = hiddenElement.ReplaceAllString(text, "")
+	text = htmlSpace.ReplaceAllString(text, " ")
+
+	text = htmlLink.ReplaceAllStringFunc(text, func(s string) string {
+		m := htmlLink.FindStringSubmatch(s)
+		label := strings.TrimSpace(htmlTag.ReplaceAllString(m[2], ""))
+
+		if label == "" || label == m[1] {
+			return m[1]
+		}
+
+		return fmt.Sprintf("%s (%s)", label, m[1])
+	})
+
+	text = htmlBreak.ReplaceAllString(text, "\n")
+	text = htmlListItem.ReplaceAllString(text, "\n- ")
+	text = htmlParagraph.ReplaceAllString(text, "\n\n")
+	text = htmlBlock.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(htmlTag.ReplaceAllString(text, ""))
+
+	lines := strings.Split(text, "\n")
+
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+
+	text = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	return strings.TrimSpace(text)
+}
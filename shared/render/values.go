@@ -0,0 +1,159 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/notifique/shared/dto"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Currency is an amount of an ISO 4217 currency. It's supplied as the
+// amount followed by the currency code, e.g. "19.99 USD", and rendered
+// with the symbol of the currency when it's known.
+type Currency struct {
+	Amount float64
+	Code   string
+}
+
+func (c Currency) String() string {
+	return formatCurrency(c.Code, c.Amount)
+}
+
+// ParseCurrency parses an amount followed by a currency code.
+func ParseCurrency(value string) (Currency, error) {
+
+	amount, code, ok := strings.Cut(strings.TrimSpace(value), " ")
+
+	if !ok {
+		return Currency{}, fmt.Errorf("%s is not an amount followed by a currency code", value)
+	}
+
+	f, err := strconv.ParseFloat(amount, 64)
+
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return Currency{}, fmt.Errorf("%s is not a valid amount", amount)
+	}
+
+	code = strings.TrimSpace(code)
+
+	if !currencyCode.MatchString(code) {
+		return Currency{}, fmt.Errorf("%s is not a valid currency code", code)
+	}
+
+	return Currency{Amount: f, Code: code}, nil
+}
+
+// Number is a NUMBER value. It's rendered as it was supplied, e.g.
+// "1.50" isn't rendered as "1.5", and its Value can be formatted with
+// formatNumber or compared in the templates.
+type Number struct {
+	Value float64
+	text  string
+}
+
+func (n Number) String() string {
+	return n.text
+}
+
+// ParseNumber parses a finite number.
+func ParseNumber(value string) (Number, error) {
+
+	f, err := strconv.ParseFloat(value, 64)
+
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return Number{}, fmt.Errorf("%s is not a number", value)
+	}
+
+	return Number{Value: f, text: value}, nil
+}
+
+// Integer is an INTEGER value. It's rendered as it was supplied, e.g.
+// "007" isn't rendered as "7", and its Value can be formatted with
+// formatNumber or compared in the templates.
+type Integer struct {
+	Value int64
+	text  string
+}
+
+func (i Integer) String() string {
+	return i.text
+}
+
+// ParseInteger parses a base 10 integer.
+func ParseInteger(value string) (Integer, error) {
+
+	i, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return Integer{}, fmt.Errorf("%s is not an integer", value)
+	}
+
+	return Integer{Value: i, text: value}, nil
+}
+
+// Date is a DATE or DATETIME value. It's rendered as it was supplied,
+// and can be formatted with formatDate or compared with the methods
+// of time.Time.
+type Date struct {
+	time.Time
+	text string
+}
+
+func (d Date) String() string {
+	return d.text
+}
+
+// ParseDate parses a value with the layout.
+func ParseDate(layout, value string) (Date, error) {
+
+	t, err := time.Parse(layout, value)
+
+	if err != nil {
+		return Date{}, err
+	}
+
+	return Date{Time: t, text: value}, nil
+}
+
+// typedValue converts the value to the type of the variable, so the
+// templates can use booleans in conditions and numbers in functions.
+// Numbers and dates are still rendered as they were supplied. The
+// values that can't be converted, and the types without a better
+// representation, are kept as strings.
+func typedValue(variable dto.TemplateVariable, value string) any {
+
+	switch variable.Type {
+	case "BOOLEAN":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "INTEGER":
+		if i, err := ParseInteger(value); err == nil {
+			return i
+		}
+	case "NUMBER":
+		if n, err := ParseNumber(value); err == nil {
+			return n
+		}
+	case "DATE":
+		if d, err := ParseDate(time.DateOnly, value); err == nil {
+			return d
+		}
+	case "DATETIME":
+		if d, err := ParseDate(time.RFC3339Nano, value); err == nil {
+			return d
+		}
+	case "CURRENCY":
+		if c, err := ParseCurrency(value); err == nil {
+			return c
+		}
+	}
+
+	return value
+}
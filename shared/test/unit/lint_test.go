@@ -0,0 +1,108 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+
+	partials := render.Partials{
+		"footer": {Name: "footer", Kind: render.PartialKind, Contents: "{{.app_name}}"},
+	}
+
+	tests := []struct {
+		name     string
+		template dto.NotificationTemplateDetails
+		errs     []dto.TemplateLintIssue
+		warnings []dto.TemplateLintIssue
+	}{
+		{
+			name: "Should accept the variables referenced in every syntax",
+			template: dto.NotificationTemplateDetails{
+				TitleTemplate:    "{{ first }} {{.second}}",
+				ContentsTemplate: `{{index . "third-var"}} {{range .items}}{{$.fourth}}{{end}}`,
+				Variables: []dto.TemplateVariable{
+					{Name: "first", Type: "STRING"},
+					{Name: "second", Type: "STRING"},
+					{Name: "third-var", Type: "STRING"},
+					{Name: "items", Type: "STRING"},
+					{Name: "fourth", Type: "STRING"},
+				},
+			},
+		},
+		{
+			name: "Should report the variables that aren't declared",
+			template: dto.NotificationTemplateDetails{
+				TitleTemplate:    "Hello {{name}}",
+				ContentsTemplate: "line\n  {{.count}}",
+			},
+			errs: []dto.TemplateLintIssue{
+				{Field: "titleTemplate", Variable: "name", Line: 1, Column: 7, Message: "variable name isn't declared"},
+				{Field: "contentsTemplate", Variable: "count", Line: 2, Column: 5, Message: "variable count isn't declared"},
+			},
+		},
+		{
+			name: "Should report the variables of the localizations that aren't declared",
+			template: dto.NotificationTemplateDetails{
+				TitleTemplate:    "title",
+				ContentsTemplate: "contents",
+				Localizations: []dto.TemplateLocalization{
+					{Locale: "pt", TitleTemplate: "título", ContentsTemplate: "{{nome}}"},
+				},
+			},
+			errs: []dto.TemplateLintIssue{
+				{Field: "localizations[0].contentsTemplate", Variable: "nome", Line: 1, Column: 1, Message: "variable nome isn't declared"},
+			},
+		},
+		{
+			name: "Should not report the fields of range and with as variables",
+			template: dto.NotificationTemplateDetails{
+				TitleTemplate:    "title",
+				ContentsTemplate: "{{with .user}}{{.name}}{{end}}",
+				Variables:        []dto.TemplateVariable{{Name: "user", Type: "STRING"}},
+			},
+		},
+		{
+			name: "Should not report the functions and keywords as variables",
+			template: dto.NotificationTemplateDetails{
+				TitleTemplate:    "{{print}}",
+				ContentsTemplate: "{{if true}}{{else}}{{end}}",
+			},
+		},
+		{
+			name: "Should warn about the variables that aren't used",
+			template: dto.NotificationTemplateDetails{
+				TitleTemplate:    "{{used}}",
+				ContentsTemplate: "contents",
+				Variables: []dto.TemplateVariable{
+					{Name: "used", Type: "STRING"},
+					{Name: "unused", Type: "STRING"},
+				},
+			},
+			warnings: []dto.TemplateLintIssue{
+				{Field: "variables[1]", Variable: "unused", Message: "variable unused is declared but never used"},
+			},
+		},
+		{
+			name: "Should not warn about the variables used by the partials",
+			template: dto.NotificationTemplateDetails{
+				TitleTemplate:    "title",
+				ContentsTemplate: `{{template "footer" .}}`,
+				Variables:        []dto.TemplateVariable{{Name: "app_name", Type: "STRING"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, warnings := render.Lint(tt.template, partials)
+
+			assert.Equal(t, tt.errs, errs)
+			assert.Equal(t, tt.warnings, warnings)
+		})
+	}
+}
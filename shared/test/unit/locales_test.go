@@ -0,0 +1,106 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalize(t *testing.T) {
+
+	localizations := []dto.TemplateLocalization{
+		{Locale: "pt", TitleTemplate: "pt", ContentsTemplate: "pt"},
+		{Locale: "pt-PT", TitleTemplate: "pt-PT", ContentsTemplate: "pt-PT"},
+		{Locale: "zh-Hant", TitleTemplate: "zh-Hant", ContentsTemplate: "zh-Hant"},
+	}
+
+	makeTemplate := func(defaultLocale string) dto.NotificationTemplateDetails {
+		return dto.NotificationTemplateDetails{
+			TitleTemplate:    "default",
+			ContentsTemplate: "default",
+			DefaultLocale:    &defaultLocale,
+			Localizations:    localizations,
+		}
+	}
+
+	locale := func(l string) *string {
+		return &l
+	}
+
+	tests := []struct {
+		name     string
+		template dto.NotificationTemplateDetails
+		locale   *string
+		expected string
+	}{
+		{
+			name:     "Should use the localization of the locale",
+			template: makeTemplate("en"),
+			locale:   locale("pt-PT"),
+			expected: "pt-PT",
+		},
+		{
+			name:     "Should compare the locales regardless of their case and separator",
+			template: makeTemplate("en"),
+			locale:   locale("PT_pt"),
+			expected: "pt-PT",
+		},
+		{
+			name:     "Should fall back to the base language of the locale",
+			template: makeTemplate("en"),
+			locale:   locale("pt-BR"),
+			expected: "pt",
+		},
+		{
+			name:     "Should drop the subtags of the locale one by one",
+			template: makeTemplate("en"),
+			locale:   locale("zh-Hant-TW"),
+			expected: "zh-Hant",
+		},
+		{
+			name:     "Should prefer the default locale of the template to the base language",
+			template: makeTemplate("pt-BR"),
+			locale:   locale("pt-BR"),
+			expected: "default",
+		},
+		{
+			name:     "Should prefer the localization of the locale to the default locale",
+			template: makeTemplate("pt"),
+			locale:   locale("pt-PT"),
+			expected: "pt-PT",
+		},
+		{
+			name:     "Should use the default contents if no localization matches",
+			template: makeTemplate("en"),
+			locale:   locale("fr-FR"),
+			expected: "default",
+		},
+		{
+			name:     "Should use the default contents if the locale isn't known",
+			template: makeTemplate("en"),
+			locale:   nil,
+			expected: "default",
+		},
+		{
+			name: "Should use the default contents of the templates without a default locale",
+			template: dto.NotificationTemplateDetails{
+				TitleTemplate:    "default",
+				ContentsTemplate: "default",
+				Localizations:    localizations,
+			},
+			locale:   locale("de"),
+			expected: "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localized := render.Localize(tt.template, tt.locale)
+
+			assert.Equal(t, tt.expected, localized.TitleTemplate)
+			assert.Equal(t, tt.expected, localized.ContentsTemplate)
+		})
+	}
+}
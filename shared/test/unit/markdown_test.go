@@ -0,0 +1,179 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/notifique/shared/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "Can convert paragraphs",
+			markdown: "first line\nsecond line\n\nother paragraph",
+			expected: "<p>first line\nsecond line</p>\n<p>other paragraph</p>",
+		},
+		{
+			name:     "Can convert headings",
+			markdown: "# Title\n### Subtitle ###",
+			expected: "<h1>Title</h1>\n<h3>Subtitle</h3>",
+		},
+		{
+			name:     "Can convert emphasis",
+			markdown: "**strong** __strong__ *em* _em_",
+			expected: "<p><strong>strong</strong> <strong>strong</strong> <em>em</em> <em>em</em></p>",
+		},
+		{
+			name:     "Can convert nested emphasis",
+			markdown: "**strong *em* strong** *em **strong** em* _em __strong__ em_",
+			expected: "<p><strong>strong <em>em</em> strong</strong> <em>em <strong>strong</strong> em</em> <em>em <strong>strong</strong> em</em></p>",
+		},
+		{
+			name:     "Can convert strong emphasis",
+			markdown: "***both***",
+			expected: "<p><em><strong>both</strong></em></p>",
+		},
+		{
+			name:     "Should end the emphasis at its first closing delimiter",
+			markdown: "**a** and **b**, *c* and *d*, _e_ _f_ _g_",
+			expected: "<p><strong>a</strong> and <strong>b</strong>, <em>c</em> and <em>d</em>, <em>e</em> <em>f</em> <em>g</em></p>",
+		},
+		{
+			name:     "Should not format the underscores inside words",
+			markdown: "snake_case_name",
+			expected: "<p>snake_case_name</p>",
+		},
+		{
+			name:     "Should not format the code spans",
+			markdown: "run `**not strong**` now",
+			expected: "<p>run <code>**not strong**</code> now</p>",
+		},
+		{
+			name:     "Should not format the underscores and asterisks of the code spans",
+			markdown: "`a_b_c` and `_x_` and `*y*`",
+			expected: "<p><code>a_b_c</code> and <code>_x_</code> and <code>*y*</code></p>",
+		},
+		{
+			name:     "Can format the emphasis around code spans",
+			markdown: "*a `*b*` c*",
+			expected: "<p><em>a <code>*b*</code> c</em></p>",
+		},
+		{
+			name:     "Can convert code spans with backticks",
+			markdown: "`` a ` b ``",
+			expected: "<p><code>a ` b</code></p>",
+		},
+		{
+			name:     "Should escape the html of the code spans",
+			markdown: "`<b>&</b>`",
+			expected: "<p><code>&lt;b&gt;&amp;&lt;/b&gt;</code></p>",
+		},
+		{
+			name:     "Should not convert the links of the code spans",
+			markdown: "`[a](https://ex.com)`",
+			expected: "<p><code>[a](https://ex.com)</code></p>",
+		},
+		{
+			name:     "Can convert links with parentheses",
+			markdown: "[Go](https://en.wikipedia.org/wiki/Go_(language))",
+			expected: `<p><a href="https://en.wikipedia.org/wiki/Go_(language)">Go</a></p>`,
+		},
+		{
+			name:     "Can convert relative links",
+			markdown: "[a](/path) [b](#anchor) [c](mailto:a@ex.com) [d](tel:+123)",
+			expected: `<p><a href="/path">a</a> <a href="#anchor">b</a> <a href="mailto:a@ex.com">c</a> <a href="tel:+123">d</a></p>`,
+		},
+		{
+			name:     "Can convert links",
+			markdown: "see [the **docs**](https://example.com/docs)",
+			expected: `<p>see <a href="https://example.com/docs">the <strong>docs</strong></a></p>`,
+		},
+		{
+			name:     "Should not format the url of the links",
+			markdown: "[a](https://ex.com/?q=*x*)",
+			expected: `<p><a href="https://ex.com/?q=*x*">a</a></p>`,
+		},
+		{
+			name:     "Should not format the urls in the text",
+			markdown: "see https://ex.com/__init__.py and **this**",
+			expected: "<p>see https://ex.com/__init__.py and <strong>this</strong></p>",
+		},
+		{
+			name:     "Can format the emphasis around urls",
+			markdown: "**https://ex.com/a_b_c**",
+			expected: "<p><strong>https://ex.com/a_b_c</strong></p>",
+		},
+		{
+			name:     "Can convert images",
+			markdown: "![the *logo*](https://ex.com/logo_1_.png)",
+			expected: `<p><img src="https://ex.com/logo_1_.png" alt="the *logo*"></p>`,
+		},
+		{
+			name:     "Can convert images inside links",
+			markdown: "[![logo](https://ex.com/logo.png)](https://ex.com)",
+			expected: `<p><a href="https://ex.com"><img src="https://ex.com/logo.png" alt="logo"></a></p>`,
+		},
+		{
+			name:     "Should drop the links with unsafe schemes",
+			markdown: "[click](javascript:alert) ![img](data:image/png)",
+			expected: "<p>click img</p>",
+		},
+		{
+			name:     "Should drop the links with unsafe schemes regardless of their case",
+			markdown: "[a](JavaScript:alert(1)) [b](VBSCRIPT:x) [c](file:///etc/passwd) [d](data:text/html,x)",
+			expected: "<p>a b c d</p>",
+		},
+		{
+			name:     "Should not decode the entities of the urls",
+			markdown: "[a](&#106;avascript:alert) [b](javascript&colon;alert)",
+			expected: `<p><a href="&amp;#106;avascript:alert">a</a> <a href="javascript&amp;colon;alert">b</a></p>`,
+		},
+		{
+			name:     "Should not convert the links with spaces before their url",
+			markdown: "[a]( javascript:alert)",
+			expected: "<p>[a]( javascript:alert)</p>",
+		},
+		{
+			name:     "Should escape html",
+			markdown: `<script>alert("x")</script>`,
+			expected: "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</p>",
+		},
+		{
+			name:     "Should escape the quotes of the urls",
+			markdown: `[a](https://ex.com/"onclick="x)`,
+			expected: `<p><a href="https://ex.com/&#34;onclick=&#34;x">a</a></p>`,
+		},
+		{
+			name:     "Can convert lists",
+			markdown: "- one\n- two\n  continued\n\n3. three\n4. four",
+			expected: "<ul>\n<li>one</li>\n<li>two\ncontinued</li>\n</ul>\n<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>",
+		},
+		{
+			name:     "Can convert block quotes",
+			markdown: "> quoted\n> # heading",
+			expected: "<blockquote>\n<p>quoted</p>\n<h1>heading</h1>\n</blockquote>",
+		},
+		{
+			name:     "Can convert fenced code",
+			markdown: "```go\nif a < b {}\n```",
+			expected: "<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>",
+		},
+		{
+			name:     "Can convert thematic breaks and line breaks",
+			markdown: "line  \nbreak\n\n---",
+			expected: "<p>line<br>\nbreak</p>\n<hr>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, render.Markdown(tt.markdown))
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package unit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePartials(t *testing.T) {
+
+	stored := map[string]dto.TemplatePartialDetails{
+		"base": {
+			Name:     "base",
+			Kind:     render.LayoutKind,
+			Contents: `{{template "header" .}}{{template "content" .}}`,
+		},
+		"header": {Name: "header", Kind: render.PartialKind, Contents: "header"},
+		"footer": {Name: "footer", Kind: render.PartialKind, Contents: `{{template "links" .}}`},
+		"links":  {Name: "links", Kind: render.PartialKind, Contents: "links"},
+		"cycle":  {Name: "cycle", Kind: render.PartialKind, Contents: `{{template "cycle" .}}`},
+	}
+
+	get := func(name string) (dto.TemplatePartialDetails, error) {
+		if p, ok := stored[name]; ok {
+			return p, nil
+		}
+		return dto.TemplatePartialDetails{}, errors.New("partial not found")
+	}
+
+	layout := "base"
+	missing := "missing"
+
+	tests := []struct {
+		name     string
+		template dto.NotificationTemplateDetails
+		expected []string
+		err      string
+	}{
+		{
+			name:     "Should not resolve partials for templates that don't include them",
+			template: dto.NotificationTemplateDetails{ContentsTemplate: "{{.name}}"},
+			expected: []string{},
+		},
+		{
+			name: "Should resolve the layout and the partials it includes",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: "contents",
+				Layout:           &layout,
+			},
+			expected: []string{"base", "header"},
+		},
+		{
+			name: "Should resolve the partials included by other partials",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: `{{template "footer" .}}`,
+			},
+			expected: []string{"footer", "links"},
+		},
+		{
+			name: "Should resolve the partials included by the localizations",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: "contents",
+				Localizations: []dto.TemplateLocalization{
+					{Locale: "pt", ContentsTemplate: `{{template "header" .}}`},
+				},
+			},
+			expected: []string{"header"},
+		},
+		{
+			name: "Should not resolve the templates defined by the template",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: `{{define "local"}}x{{end}}{{template "local" .}}`,
+			},
+			expected: []string{},
+		},
+		{
+			name: "Should resolve the partials that include themselves once",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: `{{template "cycle" .}}`,
+			},
+			expected: []string{"cycle"},
+		},
+		{
+			name: "Should resolve the partials of the legacy placeholders",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: `{{name}}{{template "header" .}}`,
+				Variables:        []dto.TemplateVariable{{Name: "name", Type: "STRING"}},
+			},
+			expected: []string{"header"},
+		},
+		{
+			name: "Should ignore the templates that can't be parsed",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: `{{template "header" .}}{{if}}`,
+			},
+			expected: []string{},
+		},
+		{
+			name: "Should fail if the layout is missing",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: "contents",
+				Layout:           &missing,
+			},
+			err: "failed to get partial missing",
+		},
+		{
+			name: "Should fail if an included partial is missing",
+			template: dto.NotificationTemplateDetails{
+				ContentsTemplate: `{{template "missing" .}}`,
+			},
+			err: "failed to get partial missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partials, err := render.ResolvePartials(tt.template, get)
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			assert.Nil(t, err)
+
+			names := []string{}
+
+			for name := range partials {
+				names = append(names, name)
+			}
+
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+}
+
+func TestValidatePartial(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		partial dto.TemplatePartialDetails
+		err     string
+	}{
+		{
+			name:    "Can validate a partial",
+			partial: dto.TemplatePartialDetails{Name: "footer", Kind: render.PartialKind, Contents: "{{.name}}"},
+		},
+		{
+			name:    "Can validate a layout",
+			partial: dto.TemplatePartialDetails{Name: "base", Kind: render.LayoutKind, Contents: `<main>{{template "content" .}}</main>`},
+		},
+		{
+			name:    "Should fail if the layout doesn't include the contents",
+			partial: dto.TemplatePartialDetails{Name: "base", Kind: render.LayoutKind, Contents: "<main></main>"},
+			err:     "layout base must include the contents",
+		},
+		{
+			name:    "Should fail if the partial can't be parsed",
+			partial: dto.TemplatePartialDetails{Name: "footer", Kind: render.PartialKind, Contents: "{{end}}"},
+			err:     "invalid partial",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := render.ValidatePartial(tt.partial)
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			assert.Nil(t, err)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate(t *testing.T) {
+
+	layout := "base"
+	missing := "missing"
+	footer := "footer"
+
+	partials := render.Partials{
+		"base": {
+			Name:     "base",
+			Kind:     render.LayoutKind,
+			Contents: `<main>{{template "content" .}}</main>`,
+		},
+		"footer": {
+			Name:     "footer",
+			Kind:     render.PartialKind,
+			Contents: "Sent by {{.app_name}}",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		template  dto.NotificationTemplateDetails
+		variables []dto.TemplateVariableContents
+		expected  dto.RenderedTemplate
+		err       string
+	}{
+		{
+			name: "Should rewrite the legacy placeholders of the declared variables",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Text,
+				TitleTemplate:    "Hello {{ user-name }}",
+				ContentsTemplate: "{{user-name}} has {{ count }} messages",
+				Variables: []dto.TemplateVariable{
+					{Name: "user-name", Type: "STRING"},
+					{Name: "count", Type: "INTEGER"},
+				},
+			},
+			variables: []dto.TemplateVariableContents{
+				{Name: "user-name", Value: "Ana"},
+				{Name: "count", Value: "3"},
+			},
+			expected: dto.RenderedTemplate{
+				Title:    "Hello Ana",
+				Contents: "Ana has 3 messages",
+			},
+		},
+		{
+			name: "Should render the variables that weren't supplied as empty values",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Text,
+				TitleTemplate:    "title",
+				ContentsTemplate: "[{{name}}]",
+				Variables:        []dto.TemplateVariable{{Name: "name", Type: "STRING"}},
+			},
+			expected: dto.RenderedTemplate{
+				Title:    "title",
+				Contents: "[]",
+			},
+		},
+		{
+			name: "Should not escape the text templates",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Text,
+				TitleTemplate:    "{{name}}",
+				ContentsTemplate: "{{name}}",
+				Variables:        []dto.TemplateVariable{{Name: "name", Type: "STRING"}},
+			},
+			variables: []dto.TemplateVariableContents{{Name: "name", Value: "A & <B>"}},
+			expected: dto.RenderedTemplate{
+				Title:    "A & <B>",
+				Contents: "A & <B>",
+			},
+		},
+		{
+			name: "Should escape the variables of the html templates",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Html,
+				TitleTemplate:    "{{name}}",
+				ContentsTemplate: "<p>{{name}}</p>",
+				Variables:        []dto.TemplateVariable{{Name: "name", Type: "STRING"}},
+			},
+			variables: []dto.TemplateVariableContents{{Name: "name", Value: "<b>A</b>"}},
+			expected: dto.RenderedTemplate{
+				Title:        "<b>A</b>",
+				Contents:     "<p>&lt;b&gt;A&lt;/b&gt;</p>",
+				TextContents: "<b>A</b>",
+				IsHtml:       true,
+			},
+		},
+		{
+			name: "Should use the format of the legacy html templates",
+			template: dto.NotificationTemplateDetails{
+				IsHtml:           true,
+				TitleTemplate:    "title",
+				ContentsTemplate: "<p>{{name}}</p>",
+				Variables:        []dto.TemplateVariable{{Name: "name", Type: "STRING"}},
+			},
+			variables: []dto.TemplateVariableContents{{Name: "name", Value: "a < b"}},
+			expected: dto.RenderedTemplate{
+				Title:        "title",
+				Contents:     "<p>a &lt; b</p>",
+				TextContents: "a < b",
+				IsHtml:       true,
+			},
+		},
+		{
+			name: "Should convert the markdown templates to html",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Markdown,
+				TitleTemplate:    "title",
+				ContentsTemplate: "Hi **{{name}}**",
+				Variables:        []dto.TemplateVariable{{Name: "name", Type: "STRING"}},
+			},
+			variables: []dto.TemplateVariableContents{{Name: "name", Value: "<Ana>"}},
+			expected: dto.RenderedTemplate{
+				Title:        "title",
+				Contents:     "<p>Hi <strong>&lt;Ana&gt;</strong></p>",
+				TextContents: "Hi <Ana>",
+				IsHtml:       true,
+			},
+		},
+		{
+			name: "Should wrap the contents in the layout and include the partials",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Html,
+				TitleTemplate:    "title",
+				ContentsTemplate: `<p>Hi</p>{{template "footer" .}}`,
+				Layout:           &layout,
+				Variables:        []dto.TemplateVariable{{Name: "app_name", Type: "STRING"}},
+			},
+			variables: []dto.TemplateVariableContents{{Name: "app_name", Value: "Notifique"}},
+			expected: dto.RenderedTemplate{
+				Title:        "title",
+				Contents:     "<main><p>Hi</p>Sent by Notifique</main>",
+				TextContents: "Hi\n\nSent by Notifique",
+				IsHtml:       true,
+			},
+		},
+		{
+			name: "Should wrap the html of the markdown templates in the layout",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Markdown,
+				TitleTemplate:    "title",
+				ContentsTemplate: "*Hi*",
+				Layout:           &layout,
+			},
+			expected: dto.RenderedTemplate{
+				Title:        "title",
+				Contents:     "<main><p><em>Hi</em></p></main>",
+				TextContents: "Hi",
+				IsHtml:       true,
+			},
+		},
+		{
+			name: "Should fail if the layout is missing",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Html,
+				TitleTemplate:    "title",
+				ContentsTemplate: "contents",
+				Layout:           &missing,
+			},
+			err: "layout missing not found",
+		},
+		{
+			name: "Should fail if the layout isn't a layout",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Html,
+				TitleTemplate:    "title",
+				ContentsTemplate: "contents",
+				Layout:           &footer,
+			},
+			err: "partial footer isn't a layout",
+		},
+		{
+			name: "Should fail if an included partial is missing",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Text,
+				TitleTemplate:    "title",
+				ContentsTemplate: `{{template "missing" .}}`,
+			},
+			err: `template "missing" not defined`,
+		},
+		{
+			name: "Should fail if the template can't be parsed",
+			template: dto.NotificationTemplateDetails{
+				Format:           dto.Text,
+				TitleTemplate:    "title",
+				ContentsTemplate: "{{if}}",
+			},
+			err: "invalid contents template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := render.Template(tt.template, partials, tt.variables)
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, rendered)
+		})
+	}
+}
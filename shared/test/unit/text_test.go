@@ -0,0 +1,59 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/notifique/shared/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainText(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "Can separate the blocks",
+			html:     "<h1>Title</h1><p>first</p><p>second</p>",
+			expected: "Title\n\nfirst\n\nsecond",
+		},
+		{
+			name:     "Can convert line breaks and list items",
+			html:     "<p>a<br>b</p><ul><li>one</li><li>two</li></ul>",
+			expected: "a\nb\n\n- one\n- two",
+		},
+		{
+			name:     "Should follow the links by their url",
+			html:     `<p>see <a href="https://ex.com">the <b>docs</b></a></p>`,
+			expected: "see the docs (https://ex.com)",
+		},
+		{
+			name:     "Should not repeat the url of the links labeled by it",
+			html:     `<a href="https://ex.com">https://ex.com</a>`,
+			expected: "https://ex.com",
+		},
+		{
+			name:     "Should drop comments and hidden elements",
+			html:     "<head><title>t</title></head><style>p {}</style><!-- hidden --><p>shown</p><script>x()</script>",
+			expected: "shown",
+		},
+		{
+			name:     "Should unescape the entities and collapse the spaces",
+			html:     "<p>a   &amp;\n  b &lt;c&gt;</p>",
+			expected: "a & b <c>",
+		},
+		{
+			name:     "Can convert the html of markdown",
+			html:     render.Markdown("# Hi\n\nsee [docs](https://ex.com/__init__.py)"),
+			expected: "Hi\n\nsee docs (https://ex.com/__init__.py)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, render.PlainText(tt.html))
+		})
+	}
+}
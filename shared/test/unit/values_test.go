@@ -0,0 +1,209 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedValues(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		varType  string
+		contents string
+		value    string
+		expected string
+	}{
+		{
+			name:     "Should render the numbers as they were supplied",
+			varType:  "NUMBER",
+			contents: "{{value}}",
+			value:    "1.50",
+			expected: "1.50",
+		},
+		{
+			name:     "Should not render the large numbers in scientific notation",
+			varType:  "NUMBER",
+			contents: "{{value}}",
+			value:    "100000000000000000000000",
+			expected: "100000000000000000000000",
+		},
+		{
+			name:     "Can format the numbers",
+			varType:  "NUMBER",
+			contents: "{{formatNumber 2 .value}}",
+			value:    "1234.5",
+			expected: "1,234.50",
+		},
+		{
+			name:     "Can compare the numbers",
+			varType:  "NUMBER",
+			contents: "{{if gt .value.Value 1.0}}more{{else}}less{{end}}",
+			value:    "1.5",
+			expected: "more",
+		},
+		{
+			name:     "Should keep the numbers that can't be parsed as text",
+			varType:  "NUMBER",
+			contents: "{{value}}",
+			value:    "NaN",
+			expected: "NaN",
+		},
+		{
+			name:     "Should render the integers as they were supplied",
+			varType:  "INTEGER",
+			contents: "{{value}}",
+			value:    "007",
+			expected: "007",
+		},
+		{
+			name:     "Can pluralize with the integers",
+			varType:  "INTEGER",
+			contents: `{{.value}} {{pluralize "item" "items" .value}}`,
+			value:    "1",
+			expected: "1 item",
+		},
+		{
+			name:     "Can compare the integers",
+			varType:  "INTEGER",
+			contents: "{{if lt .value.Value 10}}few{{end}}",
+			value:    "7",
+			expected: "few",
+		},
+		{
+			name:     "Should render the dates as they were supplied",
+			varType:  "DATE",
+			contents: "{{value}}",
+			value:    "2024-03-01",
+			expected: "2024-03-01",
+		},
+		{
+			name:     "Can format the dates",
+			varType:  "DATE",
+			contents: `{{formatDate "Jan 2, 2006" .value}}`,
+			value:    "2024-03-01",
+			expected: "Mar 1, 2024",
+		},
+		{
+			name:     "Should render the datetimes as they were supplied",
+			varType:  "DATETIME",
+			contents: "{{value}}",
+			value:    "2024-03-01T10:00:00.50+02:00",
+			expected: "2024-03-01T10:00:00.50+02:00",
+		},
+		{
+			name:     "Can format the datetimes",
+			varType:  "DATETIME",
+			contents: `{{formatDate "15:04" .value}}`,
+			value:    "2024-03-01T10:00:00+02:00",
+			expected: "10:00",
+		},
+		{
+			name:     "Can use the booleans in conditions",
+			varType:  "BOOLEAN",
+			contents: "{{if .value}}yes{{else}}no{{end}}",
+			value:    "false",
+			expected: "no",
+		},
+		{
+			name:     "Should render the currencies with their symbol",
+			varType:  "CURRENCY",
+			contents: "{{value}}",
+			value:    "19.9 USD",
+			expected: "$19.90",
+		},
+		{
+			name:     "Should render the currencies with their minor units",
+			varType:  "CURRENCY",
+			contents: "{{value}}",
+			value:    "1.5 KWD",
+			expected: "KWD 1.500",
+		},
+		{
+			name:     "Should render the currencies without minor units",
+			varType:  "CURRENCY",
+			contents: "{{value}}",
+			value:    "1500 JPY",
+			expected: "¥1,500",
+		},
+		{
+			name:     "Should render the strings as they were supplied",
+			varType:  "STRING",
+			contents: "{{value}}",
+			value:    "007",
+			expected: "007",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := dto.NotificationTemplateDetails{
+				Format:           dto.Text,
+				TitleTemplate:    "title",
+				ContentsTemplate: tt.contents,
+				Variables:        []dto.TemplateVariable{{Name: "value", Type: tt.varType}},
+			}
+
+			variables := []dto.TemplateVariableContents{{Name: "value", Value: tt.value}}
+			rendered, err := render.Template(template, nil, variables)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, rendered.Contents)
+		})
+	}
+}
+
+func TestParseValues(t *testing.T) {
+
+	parsers := map[string]func(string) error{
+		"NUMBER": func(value string) error {
+			_, err := render.ParseNumber(value)
+			return err
+		},
+		"INTEGER": func(value string) error {
+			_, err := render.ParseInteger(value)
+			return err
+		},
+		"DATE": func(value string) error {
+			_, err := render.ParseDate("2006-01-02", value)
+			return err
+		},
+		"CURRENCY": func(value string) error {
+			_, err := render.ParseCurrency(value)
+			return err
+		},
+	}
+
+	tests := []struct {
+		varType  string
+		value    string
+		accepted bool
+	}{
+		{varType: "NUMBER", value: "-1.5", accepted: true},
+		{varType: "NUMBER", value: "1e3", accepted: true},
+		{varType: "NUMBER", value: "1,5", accepted: false},
+		{varType: "NUMBER", value: "NaN", accepted: false},
+		{varType: "NUMBER", value: "Inf", accepted: false},
+		{varType: "INTEGER", value: "007", accepted: true},
+		{varType: "INTEGER", value: "-3", accepted: true},
+		{varType: "INTEGER", value: "1.5", accepted: false},
+		{varType: "INTEGER", value: "99999999999999999999", accepted: false},
+		{varType: "DATE", value: "2024-02-29", accepted: true},
+		{varType: "DATE", value: "2023-02-29", accepted: false},
+		{varType: "DATE", value: "01/03/2024", accepted: false},
+		{varType: "CURRENCY", value: "19.99 USD", accepted: true},
+		{varType: "CURRENCY", value: "19.99", accepted: false},
+		{varType: "CURRENCY", value: "19.99 usd", accepted: false},
+		{varType: "CURRENCY", value: "a USD", accepted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.varType+" "+tt.value, func(t *testing.T) {
+			err := parsers[tt.varType](tt.value)
+			assert.Equal(t, tt.accepted, err == nil)
+		})
+	}
+}
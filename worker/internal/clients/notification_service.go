@@ -14,10 +14,15 @@ type endpoint string
 
 const (
 	DistributionListEndpoint             endpoint = "%s/distribution-lists/%s/recipients"
+	DistributionListSegmentEndpoint      endpoint = "%s/distribution-lists/%s/segment"
+	DistributionListListsEndpoint        endpoint = "%s/distribution-lists/%s/lists"
 	NotificationTemplateEndpoint         endpoint = "%s/notifications/templates/%s"
+	NotificationTemplateVersionEndpoint  endpoint = "%s/notifications/templates/%s/versions/%d"
+	TemplatePartialEndpoint              endpoint = "%s/notifications/partials/%s"
 	NotificationStatusEndpoint           endpoint = "%s/notifications/%s/status"
 	NotificationRecipientsStatusEndpoint endpoint = "%s/notifications/%s/recipients/statuses"
 	UsersNotificationsEndpoint           endpoint = "%s/users/notifications"
+	UserConfigEndpoint                   endpoint = "%s/users/%s/notifications/config"
 	MaxResults                           param    = "1"
 	MaxResultsParamName                  string   = "maxResults"
 	NextTokenParamName                   string   = "nextToken"
@@ -91,7 +96,8 @@ func (p *NotificationServiceClient) DoRequestWithBackoff(req *http.Request, retr
 		return p.DoRequestWithBackoff(req, retry+1)
 	}
 
-	if res.StatusCode != http.StatusOK {
+	// The service answers with 200, 202 or 204 depending on the endpoint
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		return res, fmt.Errorf("error response from server - %s", res.Status)
 	}
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/google/wire"
+	"github.com/notifique/shared/audience"
 	"github.com/notifique/shared/cache"
 	"github.com/notifique/shared/clients"
 	"github.com/notifique/shared/dto"
@@ -180,6 +181,11 @@ var CognitoUserInfoProviderSet = wire.NewSet(
 	wire.Bind(new(worker.UserInfoProvider), new(*providers.CognitoUserInfo)),
 )
 
+var CognitoSegmentResolverSet = wire.NewSet(
+	audience.NewCognito,
+	wire.Bind(new(providers.SegmentResolver), new(*audience.Cognito)),
+)
+
 var RedisSet = wire.NewSet(
 	cache.NewRedisClient,
 	wire.Bind(new(cache.CacheRedisApi), new(*redis.Client)),
@@ -196,6 +202,7 @@ var EnvConfigSet = wire.NewSet(
 	wire.Bind(new(wc.NotificationServiceClientConfigurator), new(*cfg.EnvConfig)),
 	wire.Bind(new(providers.CognitoIdentityProviderConfigurator), new(*cfg.EnvConfig)),
 	wire.Bind(new(providers.CognitoUserInfoConfigurator), new(*cfg.EnvConfig)),
+	wire.Bind(new(audience.CognitoConfigurator), new(*cfg.EnvConfig)),
 	wire.Bind(new(sender.SMTPConfigurator), new(*cfg.EnvConfig)),
 	wire.Bind(new(cache.RedisConfigurator), new(*cfg.EnvConfig)),
 	wire.Bind(new(clients.SQSConfigurator), new(*cfg.EnvConfig)),
@@ -266,6 +273,7 @@ func InjectRabbitMQWorker(ctx context.Context, envfile *string, notificationChan
 		NotificationServiceSenderSet,
 		SMTPSenderSet,
 		CognitoUserInfoProviderSet,
+		CognitoSegmentResolverSet,
 		wire.Struct(new(worker.WorkerCfg), "*"),
 		worker.NewWorker,
 	)
@@ -288,6 +296,7 @@ func InjectSQSWorker(ctx context.Context, envfile *string, notificationChan chan
 		NotificationServiceSenderSet,
 		SMTPSenderSet,
 		CognitoUserInfoProviderSet,
+		CognitoSegmentResolverSet,
 		wire.Struct(new(worker.WorkerCfg), "*"),
 		worker.NewWorker,
 	)
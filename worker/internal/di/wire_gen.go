@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/google/wire"
+	"github.com/notifique/shared/audience"
 	"github.com/notifique/shared/cache"
 	clients2 "github.com/notifique/shared/clients"
 	"github.com/notifique/shared/dto"
@@ -156,7 +157,11 @@ func InjectRabbitMQWorker(ctx context.Context, envfile *string, notificationChan
 		BaseDelay:              baseDelay,
 		MaxDelay:               maxDelay,
 	}
-	notificationServiceProvider := providers.NewNotificationServiceProvider(notificationServiceClient)
+	cognito, err := audience.NewCognito(envConfig, cognitoidentityproviderClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	notificationServiceProvider := providers.NewNotificationServiceProvider(notificationServiceClient, cognito)
 	notificationServiceSender := sender.NewNotificationServiceSender(notificationServiceClient)
 	rabbitMQ, cleanup, err := clients2.NewRabbitMQClient(envConfig)
 	if err != nil {
@@ -254,7 +259,11 @@ func InjectSQSWorker(ctx context.Context, envfile *string, notificationChan chan
 		BaseDelay:              baseDelay,
 		MaxDelay:               maxDelay,
 	}
-	notificationServiceProvider := providers.NewNotificationServiceProvider(notificationServiceClient)
+	cognito, err := audience.NewCognito(envConfig, cognitoidentityproviderClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	notificationServiceProvider := providers.NewNotificationServiceProvider(notificationServiceClient, cognito)
 	notificationServiceSender := sender.NewNotificationServiceSender(notificationServiceClient)
 	sqsQueueCfg, err := ProvideSQSQueueCfg(envConfig)
 	if err != nil {
@@ -406,8 +415,10 @@ var SMTPSenderSet = wire.NewSet(
 
 var CognitoUserInfoProviderSet = wire.NewSet(providers.NewCognitoIdentityProvider, ProviderUserPoolId, wire.Struct(new(providers.CognitoUserInfoCfg), "*"), providers.NewCognitoUserInfoProvider, wire.Bind(new(worker.UserInfoProvider), new(*providers.CognitoUserInfo)))
 
+var CognitoSegmentResolverSet = wire.NewSet(audience.NewCognito, wire.Bind(new(providers.SegmentResolver), new(*audience.Cognito)))
+
 var RedisSet = wire.NewSet(cache.NewRedisClient, wire.Bind(new(cache.CacheRedisApi), new(*redis.Client)))
 
 var RedisCacheSet = wire.NewSet(cache.NewRedisCache, wire.Bind(new(cache.Cache), new(*cache.Redis)))
 
-var EnvConfigSet = wire.NewSet(config.NewEnvConfig, wire.Bind(new(clients.CognitoAuthConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.NotificationServiceClientConfigurator), new(*config.EnvConfig)), wire.Bind(new(providers.CognitoIdentityProviderConfigurator), new(*config.EnvConfig)), wire.Bind(new(providers.CognitoUserInfoConfigurator), new(*config.EnvConfig)), wire.Bind(new(sender.SMTPConfigurator), new(*config.EnvConfig)), wire.Bind(new(cache.RedisConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients2.SQSConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients2.RabbitMQConfigurator), new(*config.EnvConfig)), wire.Bind(new(consumers.RabbitMQConfigurator), new(*config.EnvConfig)), wire.Bind(new(consumers.SQSQueueConfigurator), new(*config.EnvConfig)))
+var EnvConfigSet = wire.NewSet(config.NewEnvConfig, wire.Bind(new(clients.CognitoAuthConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients.NotificationServiceClientConfigurator), new(*config.EnvConfig)), wire.Bind(new(providers.CognitoIdentityProviderConfigurator), new(*config.EnvConfig)), wire.Bind(new(providers.CognitoUserInfoConfigurator), new(*config.EnvConfig)), wire.Bind(new(audience.CognitoConfigurator), new(*config.EnvConfig)), wire.Bind(new(sender.SMTPConfigurator), new(*config.EnvConfig)), wire.Bind(new(cache.RedisConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients2.SQSConfigurator), new(*config.EnvConfig)), wire.Bind(new(clients2.RabbitMQConfigurator), new(*config.EnvConfig)), wire.Bind(new(consumers.RabbitMQConfigurator), new(*config.EnvConfig)), wire.Bind(new(consumers.SQSQueueConfigurator), new(*config.EnvConfig)))
@@ -8,10 +8,11 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/notifique/shared/audience"
 	"github.com/notifique/shared/cache"
 )
 
-type UserPoolID string
+type UserPoolID = audience.UserPoolID
 
 type UserInfo struct {
 	UserId string `json:"user_id"`
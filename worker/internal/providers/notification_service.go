@@ -19,8 +19,21 @@ type StatusFilters struct {
 	Statuses       []dto.NotificationStatus
 }
 
+// SegmentResolver resolves the recipients of the distribution lists
+// defined by segments.
+type SegmentResolver interface {
+	Resolve(ctx context.Context, segment dto.Segment) ([]string, error)
+}
+
 type NotificationServiceProvider struct {
 	clients.NotificationServiceClient
+	Segments SegmentResolver
+}
+
+// distributionListSegment is the segment of a distribution list, which
+// is nil for the lists with a fixed set of recipients.
+type distributionListSegment struct {
+	Segment *dto.Segment `json:"segment"`
 }
 
 type paginatedApiInfo struct {
@@ -30,11 +43,59 @@ type paginatedApiInfo struct {
 	Client         clients.NotificationServiceClient
 }
 
-func (p *NotificationServiceProvider) GetDistributionListRecipients(ctx context.Context, name string) ([]string, error) {
+func (p *NotificationServiceProvider) getDistributionListSegment(ctx context.Context, name string) (*dto.Segment, error) {
+
+	url := fmt.Sprintf(
+		string(clients.DistributionListSegmentEndpoint),
+		p.NotificationServiceUrl, url.PathEscape(name))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating request - %w", err)
+	}
+
+	err = p.AuthProvider.AddAuth(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("error adding auth to request - %w", err)
+	}
+
+	res, err := p.DoRequestWithBackoff(req, 0)
+
+	if err != nil {
+		return nil, fmt.Errorf("error sending request - %w", err)
+	}
+
+	defer res.Body.Close()
+
+	segment := distributionListSegment{}
+
+	if err := json.NewDecoder(res.Body).Decode(&segment); err != nil {
+		return nil, fmt.Errorf("error unmarshalling the segment - %w", err)
+	}
+
+	return segment.Segment, nil
+}
+
+// getListRecipients gets the recipients of the list, without the
+// recipients of its nested lists. The recipients of the lists defined by
+// segments are resolved when the notification is sent.
+func (p *NotificationServiceProvider) getListRecipients(ctx context.Context, name string, segment *dto.Segment) ([]string, error) {
+
+	if segment != nil {
+		recipients, err := p.Segments.Resolve(ctx, *segment)
+
+		if err != nil {
+			return nil, fmt.Errorf("error resolving the segment of the list - %w", err)
+		}
+
+		return recipients, nil
+	}
 
 	url := fmt.Sprintf(
 		string(clients.DistributionListEndpoint),
-		p.NotificationServiceUrl, name)
+		p.NotificationServiceUrl, url.PathEscape(name))
 
 	info := paginatedApiInfo{
 		Url:            url,
@@ -52,7 +113,90 @@ func (p *NotificationServiceProvider) GetDistributionListRecipients(ctx context.
 	return recipients, nil
 }
 
-func (p *NotificationServiceProvider) GetNotificationTemplate(ctx context.Context, templateId string) (dto.NotificationTemplateDetails, error) {
+func (p *NotificationServiceProvider) getNestedLists(ctx context.Context, name string) ([]string, error) {
+
+	url := fmt.Sprintf(
+		string(clients.DistributionListListsEndpoint),
+		p.NotificationServiceUrl, url.PathEscape(name))
+
+	info := paginatedApiInfo{
+		Url:            url,
+		AuthProvider:   p.AuthProvider,
+		AddQueryParams: nil,
+		Client:         p.NotificationServiceClient,
+	}
+
+	lists, err := consumePaginatedApi[string](ctx, info)
+
+	if err != nil {
+		return lists, fmt.Errorf("error consuming paginated api - %w", err)
+	}
+
+	return lists, nil
+}
+
+// GetDistributionListRecipients gets the recipients of the list and of
+// every list included by it, directly or through other lists. Every
+// list is expanded once, so the recipients aren't repeated.
+func (p *NotificationServiceProvider) GetDistributionListRecipients(ctx context.Context, name string) ([]string, error) {
+
+	recipients := []string{}
+	seenRecipients := map[string]struct{}{}
+
+	lists := []string{name}
+	seenLists := map[string]struct{}{name: {}}
+
+	for i := 0; i < len(lists); i++ {
+		list := lists[i]
+
+		segment, err := p.getDistributionListSegment(ctx, list)
+
+		if err != nil {
+			return nil, fmt.Errorf("error getting the segment of the list %s - %w", list, err)
+		}
+
+		listRecipients, err := p.getListRecipients(ctx, list, segment)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range listRecipients {
+			if _, ok := seenRecipients[r]; ok {
+				continue
+			}
+
+			seenRecipients[r] = struct{}{}
+			recipients = append(recipients, r)
+		}
+
+		// Segments don't include other lists
+		if segment != nil {
+			continue
+		}
+
+		nested, err := p.getNestedLists(ctx, list)
+
+		if err != nil {
+			return nil, fmt.Errorf("error getting the nested lists of %s - %w", list, err)
+		}
+
+		for _, l := range nested {
+			if _, ok := seenLists[l]; ok {
+				continue
+			}
+
+			seenLists[l] = struct{}{}
+			lists = append(lists, l)
+		}
+	}
+
+	return recipients, nil
+}
+
+// GetNotificationTemplate gets the version of the template, or its
+// latest version when the version is nil.
+func (p *NotificationServiceProvider) GetNotificationTemplate(ctx context.Context, templateId string, version *int) (dto.NotificationTemplateDetails, error) {
 
 	template := dto.NotificationTemplateDetails{}
 
@@ -60,6 +204,12 @@ func (p *NotificationServiceProvider) GetNotificationTemplate(ctx context.Contex
 		string(clients.NotificationTemplateEndpoint),
 		p.NotificationServiceUrl, templateId)
 
+	if version != nil {
+		url = fmt.Sprintf(
+			string(clients.NotificationTemplateVersionEndpoint),
+			p.NotificationServiceUrl, templateId, *version)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
 	if err != nil {
@@ -87,6 +237,85 @@ func (p *NotificationServiceProvider) GetNotificationTemplate(ctx context.Contex
 	return template, nil
 }
 
+// GetTemplatePartial gets the latest version of the layout or partial
+// used by templates.
+func (p *NotificationServiceProvider) GetTemplatePartial(ctx context.Context, name string) (dto.TemplatePartialDetails, error) {
+
+	partial := dto.TemplatePartialDetails{}
+
+	url := fmt.Sprintf(
+		string(clients.TemplatePartialEndpoint),
+		p.NotificationServiceUrl, name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return partial, fmt.Errorf("error creating request - %w", err)
+	}
+
+	err = p.AuthProvider.AddAuth(req)
+
+	if err != nil {
+		return partial, fmt.Errorf("error adding auth to request - %w", err)
+	}
+
+	res, err := p.DoRequestWithBackoff(req, 0)
+
+	if err != nil {
+		return partial, fmt.Errorf("error sending request - %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if err := json.NewDecoder(res.Body).Decode(&partial); err != nil {
+		return partial, fmt.Errorf("error unmarshalling the template partial - %w", err)
+	}
+
+	return partial, nil
+}
+
+// userConfig is the part of the config of the users used by the worker
+type userConfig struct {
+	Locale *string `json:"locale"`
+}
+
+// GetUserLocale gets the locale of the user, which is nil when the user
+// hasn't chosen one.
+func (p *NotificationServiceProvider) GetUserLocale(ctx context.Context, userId string) (*string, error) {
+
+	config := userConfig{}
+
+	url := fmt.Sprintf(
+		string(clients.UserConfigEndpoint),
+		p.NotificationServiceUrl, url.PathEscape(userId))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating request - %w", err)
+	}
+
+	err = p.AuthProvider.AddAuth(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("error adding auth to request - %w", err)
+	}
+
+	res, err := p.DoRequestWithBackoff(req, 0)
+
+	if err != nil {
+		return nil, fmt.Errorf("error sending request - %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if err := json.NewDecoder(res.Body).Decode(&config); err != nil {
+		return nil, fmt.Errorf("error unmarshalling the user config - %w", err)
+	}
+
+	return config.Locale, nil
+}
+
 func (p *NotificationServiceProvider) GetNotificationStatus(ctx context.Context, notificationId string) (dto.NotificationStatus, error) {
 
 	url := fmt.Sprintf(
@@ -211,8 +440,9 @@ func consumePaginatedApi[T any](ctx context.Context, info paginatedApiInfo) ([]T
 	return data, nil
 }
 
-func NewNotificationServiceProvider(c clients.NotificationServiceClient) *NotificationServiceProvider {
+func NewNotificationServiceProvider(c clients.NotificationServiceClient, segments SegmentResolver) *NotificationServiceProvider {
 	return &NotificationServiceProvider{
 		NotificationServiceClient: c,
+		Segments:                  segments,
 	}
 }
@@ -1,9 +1,12 @@
 package sender
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 
 	"github.com/notifique/shared/dto"
 )
@@ -30,31 +33,77 @@ func NewSMTP(cfg SMTPConfig) *SMTP {
 	}
 }
 
+// body returns the content type and body of the e-mail. HTML e-mails
+// with a plain text alternative are sent as multipart/alternative, so
+// the clients that can't display HTML show the text.
+func body(notification dto.UserEmailNotificationReq) (string, []byte, error) {
+
+	if !notification.IsHtml {
+		return "text/plain; charset=UTF-8", []byte(notification.Contents), nil
+	}
+
+	if notification.TextContents == "" {
+		return "text/html; charset=UTF-8", []byte(notification.Contents), nil
+	}
+
+	var b bytes.Buffer
+
+	w := multipart.NewWriter(&b)
+
+	// The last part is the preferred one
+	parts := []struct {
+		contentType string
+		contents    string
+	}{
+		{contentType: "text/plain; charset=UTF-8", contents: notification.TextContents},
+		{contentType: "text/html; charset=UTF-8", contents: notification.Contents},
+	}
+
+	for _, p := range parts {
+		part, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {p.contentType}})
+
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create the %s part - %w", p.contentType, err)
+		}
+
+		if _, err := part.Write([]byte(p.contents)); err != nil {
+			return "", nil, fmt.Errorf("failed to write the %s part - %w", p.contentType, err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to close the multipart body - %w", err)
+	}
+
+	return fmt.Sprintf("multipart/alternative; boundary=%s", w.Boundary()), b.Bytes(), nil
+}
+
 func (s *SMTP) SendNotifications(ctx context.Context, batch []dto.UserEmailNotificationReq) error {
 
 	auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
 	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
 
 	for _, notification := range batch {
-		contentType := "text/plain"
+		contentType, contents, err := body(notification)
 
-		if notification.IsHtml {
-			contentType = "text/html"
+		if err != nil {
+			return fmt.Errorf("failed to build the email to %s: %w", notification.Email, err)
 		}
 
 		msg := fmt.Appendf(nil, "From: %s\r\n"+
 			"To: %s\r\n"+
 			"Subject: %s\r\n"+
-			"Content-Type: %s; charset=UTF-8\r\n"+
+			"MIME-Version: 1.0\r\n"+
+			"Content-Type: %s\r\n"+
 			"\r\n"+
 			"%s\r\n",
 			s.cfg.From,
 			notification.Email,
 			notification.Title,
 			contentType,
-			notification.Contents)
+			contents)
 
-		err := smtp.SendMail(addr, auth, s.cfg.From, []string{notification.Email}, msg)
+		err = smtp.SendMail(addr, auth, s.cfg.From, []string{notification.Email}, msg)
 
 		if err != nil {
 			return fmt.Errorf("failed to send email to %s: %w", notification.Email, err)
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/providers/notification_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/providers/notification_service.go -destination=./internal/testutils/mocks/providers.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/notifique/shared/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSegmentResolver is a mock of SegmentResolver interface.
+type MockSegmentResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockSegmentResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockSegmentResolverMockRecorder is the mock recorder for MockSegmentResolver.
+type MockSegmentResolverMockRecorder struct {
+	mock *MockSegmentResolver
+}
+
+// NewMockSegmentResolver creates a new mock instance.
+func NewMockSegmentResolver(ctrl *gomock.Controller) *MockSegmentResolver {
+	mock := &MockSegmentResolver{ctrl: ctrl}
+	mock.recorder = &MockSegmentResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSegmentResolver) EXPECT() *MockSegmentResolverMockRecorder {
+	return m.recorder
+}
+
+// Resolve mocks base method.
+func (m *MockSegmentResolver) Resolve(ctx context.Context, segment dto.Segment) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, segment)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockSegmentResolverMockRecorder) Resolve(ctx, segment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockSegmentResolver)(nil).Resolve), ctx, segment)
+}
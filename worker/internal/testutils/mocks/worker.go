@@ -112,18 +112,18 @@ func (mr *MockNotificationInfoProviderMockRecorder) GetNotificationStatus(ctx, n
 }
 
 // GetNotificationTemplate mocks base method.
-func (m *MockNotificationInfoProvider) GetNotificationTemplate(ctx context.Context, templateId string) (dto.NotificationTemplateDetails, error) {
+func (m *MockNotificationInfoProvider) GetNotificationTemplate(ctx context.Context, templateId string, version *int) (dto.NotificationTemplateDetails, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNotificationTemplate", ctx, templateId)
+	ret := m.ctrl.Call(m, "GetNotificationTemplate", ctx, templateId, version)
 	ret0, _ := ret[0].(dto.NotificationTemplateDetails)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetNotificationTemplate indicates an expected call of GetNotificationTemplate.
-func (mr *MockNotificationInfoProviderMockRecorder) GetNotificationTemplate(ctx, templateId any) *gomock.Call {
+func (mr *MockNotificationInfoProviderMockRecorder) GetNotificationTemplate(ctx, templateId, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationTemplate", reflect.TypeOf((*MockNotificationInfoProvider)(nil).GetNotificationTemplate), ctx, templateId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationTemplate", reflect.TypeOf((*MockNotificationInfoProvider)(nil).GetNotificationTemplate), ctx, templateId, version)
 }
 
 // GetRecipientNotificationStatuses mocks base method.
@@ -141,6 +141,36 @@ func (mr *MockNotificationInfoProviderMockRecorder) GetRecipientNotificationStat
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecipientNotificationStatuses", reflect.TypeOf((*MockNotificationInfoProvider)(nil).GetRecipientNotificationStatuses), ctx, filter)
 }
 
+// GetTemplatePartial mocks base method.
+func (m *MockNotificationInfoProvider) GetTemplatePartial(ctx context.Context, name string) (dto.TemplatePartialDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatePartial", ctx, name)
+	ret0, _ := ret[0].(dto.TemplatePartialDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatePartial indicates an expected call of GetTemplatePartial.
+func (mr *MockNotificationInfoProviderMockRecorder) GetTemplatePartial(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatePartial", reflect.TypeOf((*MockNotificationInfoProvider)(nil).GetTemplatePartial), ctx, name)
+}
+
+// GetUserLocale mocks base method.
+func (m *MockNotificationInfoProvider) GetUserLocale(ctx context.Context, userId string) (*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLocale", ctx, userId)
+	ret0, _ := ret[0].(*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLocale indicates an expected call of GetUserLocale.
+func (mr *MockNotificationInfoProviderMockRecorder) GetUserLocale(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLocale", reflect.TypeOf((*MockNotificationInfoProvider)(nil).GetUserLocale), ctx, userId)
+}
+
 // MockNotificationInfoUpdater is a mock of NotificationInfoUpdater interface.
 type MockNotificationInfoUpdater struct {
 	ctrl     *gomock.Controller
@@ -62,8 +62,16 @@ func applyPaginationParams[T any](originalData []T, nextToken string, maxResults
 }
 
 func MakeDistributionListHandler(responses map[string]any) func(w http.ResponseWriter, r *http.Request) {
+	return makeDistributionListPageHandler("/distribution-lists/%s/recipients", responses)
+}
+
+func MakeDistributionListListsHandler(responses map[string]any) func(w http.ResponseWriter, r *http.Request) {
+	return makeDistributionListPageHandler("/distribution-lists/%s/lists", responses)
+}
+
+func makeDistributionListPageHandler(keyFormat string, responses map[string]any) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		key := fmt.Sprintf("/distribution-lists/%s/recipients", r.PathValue("id"))
+		key := fmt.Sprintf(keyFormat, r.PathValue("id"))
 
 		response, ok := responses[key]
 
@@ -102,10 +110,38 @@ func MakeDistributionListHandler(responses map[string]any) func(w http.ResponseW
 	}
 }
 
+func MakeDistributionListSegmentHandler(responses map[string]any) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := fmt.Sprintf("/distribution-lists/%s/segment", r.PathValue("id"))
+
+		response, ok := responses[key]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		marshalledResponse, err := json.Marshal(map[string]any{"segment": response})
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(marshalledResponse)
+	}
+}
+
 func MakeNotificationTemplateHandler(responses map[string]any) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		key := fmt.Sprintf("/notifications/templates/%s", r.PathValue("id"))
 
+		if version := r.PathValue("version"); version != "" {
+			key = fmt.Sprintf("%s/versions/%s", key, version)
+		}
+
 		response, ok := responses[key]
 
 		if !ok {
@@ -192,6 +228,30 @@ func MakeNotificationStatusHandler(responses map[string]any) func(w http.Respons
 	}
 }
 
+func MakeUserConfigHandler(responses map[string]any) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := fmt.Sprintf("/users/%s/notifications/config", r.PathValue("id"))
+
+		response, ok := responses[key]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		marshalledResponse, err := json.Marshal(response)
+
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(marshalledResponse)
+	}
+}
+
 func MakeUserNotificationsHandler() func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
@@ -199,10 +259,18 @@ func MakeUserNotificationsHandler() func(w http.ResponseWriter, r *http.Request)
 }
 
 func NewTestServer(method string, handlerFunc func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	return NewTestServerWithHandlers(map[string]func(w http.ResponseWriter, r *http.Request){
+		method: handlerFunc,
+	})
+}
+
+func NewTestServerWithHandlers(handlers map[string]func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
 
 	handler := http.NewServeMux()
 
-	handler.HandleFunc(method, handlerFunc)
+	for method, handlerFunc := range handlers {
+		handler.HandleFunc(method, handlerFunc)
+	}
 
 	return httptest.NewServer(handler)
 }
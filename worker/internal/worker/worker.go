@@ -5,9 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
+	"sync"
 
 	"github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
 	"github.com/notifique/worker/internal/providers"
 )
 
@@ -18,7 +19,9 @@ type UserInfoProvider interface {
 type NotificationInfoProvider interface {
 	GetNotificationStatus(ctx context.Context, notificationID string) (dto.NotificationStatus, error)
 	GetRecipientNotificationStatuses(ctx context.Context, filter providers.StatusFilters) ([]dto.RecipientNotificationStatus, error)
-	GetNotificationTemplate(ctx context.Context, templateId string) (dto.NotificationTemplateDetails, error)
+	GetNotificationTemplate(ctx context.Context, templateId string, version *int) (dto.NotificationTemplateDetails, error)
+	GetTemplatePartial(ctx context.Context, name string) (dto.TemplatePartialDetails, error)
+	GetUserLocale(ctx context.Context, userId string) (*string, error)
 	GetDistributionListRecipients(ctx context.Context, name string) ([]string, error)
 }
 
@@ -39,17 +42,20 @@ type EmailSender interface {
 	SendNotifications(ctx context.Context, batch []dto.UserEmailNotificationReq) error
 }
 
+// NotificationContents are the contents sent to a user. The
+// TextContents are the plain text alternative of HTML contents.
 type NotificationContents struct {
-	Title    string
-	Contents string
-	Topic    string
-	Image    *string
-	IsHTML   bool
+	Title        string
+	Contents     string
+	TextContents string
+	Topic        string
+	Image        *string
+	IsHTML       bool
 }
 
 type notificationChannelParams[T any] struct {
 	Channel           dto.NotificationChannel
-	Contentents       NotificationContents
+	ContentsFn        func(userId string) (NotificationContents, error)
 	UsersInfo         []providers.UserInfo
 	NotificationReqFn func(userInfo providers.UserInfo, c NotificationContents) T
 	SendNotifications func(ctx context.Context, batch []T) error
@@ -87,7 +93,9 @@ func NewWorker(cfg WorkerCfg) *Worker {
 	}
 }
 
-func (w *Worker) buildNotification(p dto.NotificationMsgPayload, t *dto.NotificationTemplateDetails) NotificationContents {
+// buildNotification renders the notification of the recipient, as
+// every recipient might have their own template variables and locale.
+func (w *Worker) buildNotification(p dto.NotificationMsgPayload, t *dto.NotificationTemplateDetails, partials render.Partials, userId string, locale *string) (NotificationContents, error) {
 
 	contents := NotificationContents{
 		Topic: p.Topic,
@@ -98,20 +106,73 @@ func (w *Worker) buildNotification(p dto.NotificationMsgPayload, t *dto.Notifica
 		contents.Title = p.RawContents.Title
 		contents.Contents = p.RawContents.Contents
 
-		return contents
+		return contents, nil
 	}
 
-	contents.Title = t.TitleTemplate
-	contents.Contents = t.ContentsTemplate
-	contents.IsHTML = t.IsHtml
+	localized := render.Localize(*t, locale)
 
-	for _, value := range p.TemplateContents.Variables {
-		name := fmt.Sprintf("{{%s}}", value.Name)
-		contents.Title = strings.ReplaceAll(contents.Title, name, value.Value)
-		contents.Contents = strings.ReplaceAll(contents.Contents, name, value.Value)
+	rendered, err := render.Template(localized, partials, p.TemplateContents.GetRecipientVariables(userId))
+
+	if err != nil {
+		return contents, fmt.Errorf("failed to render the template - %w", err)
 	}
 
-	return contents
+	contents.Title = rendered.Title
+	contents.Contents = rendered.Contents
+	contents.TextContents = rendered.TextContents
+	contents.IsHTML = rendered.IsHtml
+
+	return contents, nil
+}
+
+// maxLocaleRequests bounds the requests made at once to get the locales
+// of the recipients.
+const maxLocaleRequests = 16
+
+// getUserLocales gets the locales of the users, which are only needed
+// when the template has localizations. The users whose locale can't be
+// retrieved receive the template in its default locale.
+func (w *Worker) getUserLocales(ctx context.Context, t *dto.NotificationTemplateDetails, usersInfo []providers.UserInfo) map[string]*string {
+
+	locales := make(map[string]*string, len(usersInfo))
+
+	if t == nil || len(t.Localizations) == 0 {
+		return locales
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, maxLocaleRequests)
+	)
+
+	for _, info := range usersInfo {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(userId string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			locale, err := w.notificationInfoProvider.GetUserLocale(ctx, userId)
+
+			if err != nil {
+				err = fmt.Errorf("failed to get the locale of user %s - %w", userId, err)
+				slog.Error(err.Error())
+				return
+			}
+
+			mu.Lock()
+			locales[userId] = locale
+			mu.Unlock()
+		}(info.UserId)
+	}
+
+	wg.Wait()
+
+	return locales
 }
 
 func (w *Worker) failProcess(ctx context.Context, err error, notificationId string) {
@@ -132,7 +193,10 @@ func (w *Worker) failProcess(ctx context.Context, err error, notificationId stri
 	slog.Error(errors.Join(errArr...).Error())
 }
 
-func (w *Worker) getRecipientsToSendNotifications(ctx context.Context, msg dto.NotificationMsg) ([]string, error) {
+// getRecipientsToSendNotifications returns the recipients of every
+// channel of the notification that haven't received it through that
+// channel yet.
+func (w *Worker) getRecipientsToSendNotifications(ctx context.Context, msg dto.NotificationMsg) (map[dto.NotificationChannel][]string, error) {
 
 	recipients := make([]string, len(msg.Payload.Recipients))
 	copy(recipients, msg.Payload.Recipients)
@@ -161,29 +225,72 @@ func (w *Worker) getRecipientsToSendNotifications(ctx context.Context, msg dto.N
 		return nil, fmt.Errorf("failed to get sent notifications - %w", err)
 	}
 
-	sentRecipients := map[string]struct{}{}
+	sentRecipients := map[dto.NotificationChannel]map[string]struct{}{}
 
 	for _, sentNotification := range sentNotifications {
-		sentRecipients[sentNotification.UserId] = struct{}{}
+		channel := dto.NotificationChannel(sentNotification.Channel)
+
+		if _, ok := sentRecipients[channel]; !ok {
+			sentRecipients[channel] = map[string]struct{}{}
+		}
+
+		sentRecipients[channel][sentNotification.UserId] = struct{}{}
 	}
 
-	recipientsToSend := []string{}
+	recipientsToSend := map[dto.NotificationChannel][]string{}
 
-	for _, recipient := range recipients {
-		if _, ok := sentRecipients[recipient]; !ok {
-			recipientsToSend = append(recipientsToSend, recipient)
+	for _, channel := range msg.Payload.Channels {
+		for _, recipient := range recipients {
+			if _, ok := sentRecipients[channel][recipient]; !ok {
+				recipientsToSend[channel] = append(recipientsToSend[channel], recipient)
+			}
 		}
 	}
 
 	return recipientsToSend, nil
 }
 
+// filterUsersInfo keeps the info of the users that are recipients.
+func filterUsersInfo(usersInfo []providers.UserInfo, recipients []string) []providers.UserInfo {
+
+	recipientsSet := make(map[string]struct{}, len(recipients))
+
+	for _, recipient := range recipients {
+		recipientsSet[recipient] = struct{}{}
+	}
+
+	filtered := make([]providers.UserInfo, 0, len(recipients))
+
+	for _, info := range usersInfo {
+		if _, ok := recipientsSet[info.UserId]; ok {
+			filtered = append(filtered, info)
+		}
+	}
+
+	return filtered
+}
+
 func processChannelNotifications[T any](ctx context.Context, params notificationChannelParams[T]) ([]dto.RecipientNotificationStatus, bool) {
 	notifications := make([]T, 0, len(params.UsersInfo))
 	recipientStatusLogs := make([]dto.RecipientNotificationStatus, 0, len(params.UsersInfo))
+	failedStatusLogs := []dto.RecipientNotificationStatus{}
 
 	for _, userInfo := range params.UsersInfo {
-		notification := params.NotificationReqFn(userInfo, params.Contentents)
+		contents, err := params.ContentsFn(userInfo.UserId)
+
+		// The recipient fails on its own, the others are still sent
+		if err != nil {
+			errMsg := err.Error()
+			failedStatusLogs = append(failedStatusLogs, dto.RecipientNotificationStatus{
+				UserId:  userInfo.UserId,
+				Status:  string(dto.Failed),
+				Channel: string(params.Channel),
+				ErrMsg:  &errMsg,
+			})
+			continue
+		}
+
+		notification := params.NotificationReqFn(userInfo, contents)
 
 		recipientStatusLogs = append(recipientStatusLogs, dto.RecipientNotificationStatus{
 			UserId:  userInfo.UserId,
@@ -194,10 +301,17 @@ func processChannelNotifications[T any](ctx context.Context, params notification
 		notifications = append(notifications, notification)
 	}
 
+	hasFailed := len(failedStatusLogs) != 0
+
+	if hasFailed {
+		slog.Error(fmt.Sprintf("failed to build %d %s notifications",
+			len(failedStatusLogs), string(params.Channel)))
+	}
+
 	err := params.SendNotifications(ctx, notifications)
 
 	if err == nil {
-		return recipientStatusLogs, false
+		return append(recipientStatusLogs, failedStatusLogs...), hasFailed
 	}
 
 	slog.Error(fmt.Sprintf("failed to send %s notifications - %s",
@@ -209,24 +323,33 @@ func processChannelNotifications[T any](ctx context.Context, params notification
 		recipientStatusLogs[i].ErrMsg = &errMsg
 	}
 
-	return recipientStatusLogs, true
+	return append(recipientStatusLogs, failedStatusLogs...), true
 }
 
-func (w *Worker) processInAppNotification(ctx context.Context, usersInfo []providers.UserInfo, notification NotificationContents) ([]dto.RecipientNotificationStatus, bool) {
+func (w *Worker) processInAppNotification(ctx context.Context, usersInfo []providers.UserInfo, contentsFn func(userId string) (NotificationContents, error)) ([]dto.RecipientNotificationStatus, bool) {
 
+	// The in-app notifications have plain text contents, HTML contents
+	// are sent along for the clients that can display them
 	makeInAppNotification := func(userInfo providers.UserInfo, c NotificationContents) dto.UserNotificationReq {
-		return dto.UserNotificationReq{
+		notification := dto.UserNotificationReq{
 			UserId:   userInfo.UserId,
 			Title:    c.Title,
 			Contents: c.Contents,
 			Topic:    c.Topic,
 			Image:    c.Image,
 		}
+
+		if c.IsHTML {
+			notification.Contents = c.TextContents
+			notification.HtmlContents = &c.Contents
+		}
+
+		return notification
 	}
 
 	params := notificationChannelParams[dto.UserNotificationReq]{
 		Channel:           dto.InApp,
-		Contentents:       notification,
+		ContentsFn:        contentsFn,
 		UsersInfo:         usersInfo,
 		NotificationReqFn: makeInAppNotification,
 		SendNotifications: w.inAppSender.SendNotifications,
@@ -237,12 +360,13 @@ func (w *Worker) processInAppNotification(ctx context.Context, usersInfo []provi
 	return recipientStatusLogs, hasFailed
 }
 
-func (w *Worker) processEmailNotification(ctx context.Context, usersInfo []providers.UserInfo, notification NotificationContents) ([]dto.RecipientNotificationStatus, bool) {
+func (w *Worker) processEmailNotification(ctx context.Context, usersInfo []providers.UserInfo, contentsFn func(userId string) (NotificationContents, error)) ([]dto.RecipientNotificationStatus, bool) {
 
 	makeEmailNotification := func(userInfo providers.UserInfo, c NotificationContents) dto.UserEmailNotificationReq {
 		return dto.UserEmailNotificationReq{
-			Email:  userInfo.Email,
-			IsHtml: c.IsHTML,
+			Email:        userInfo.Email,
+			IsHtml:       c.IsHTML,
+			TextContents: c.TextContents,
 			UserNotificationReq: dto.UserNotificationReq{
 				UserId:   userInfo.UserId,
 				Title:    c.Title,
@@ -255,7 +379,7 @@ func (w *Worker) processEmailNotification(ctx context.Context, usersInfo []provi
 
 	params := notificationChannelParams[dto.UserEmailNotificationReq]{
 		Channel:           dto.Email,
-		Contentents:       notification,
+		ContentsFn:        contentsFn,
 		UsersInfo:         usersInfo,
 		NotificationReqFn: makeEmailNotification,
 		SendNotifications: w.emailSender.SendNotifications,
@@ -301,7 +425,7 @@ func (w *Worker) ProcessNotification(ctx context.Context, msg dto.NotificationMs
 		return
 	}
 
-	recipients, err := w.getRecipientsToSendNotifications(ctx, msg)
+	channelRecipients, err := w.getRecipientsToSendNotifications(ctx, msg)
 
 	if err != nil {
 		err = fmt.Errorf("failed to get recipients to send notifications - %w", err)
@@ -309,6 +433,20 @@ func (w *Worker) ProcessNotification(ctx context.Context, msg dto.NotificationMs
 		return
 	}
 
+	recipients := []string{}
+	seenRecipients := map[string]struct{}{}
+
+	for _, channel := range msg.Payload.Channels {
+		for _, recipient := range channelRecipients[channel] {
+			if _, ok := seenRecipients[recipient]; ok {
+				continue
+			}
+
+			seenRecipients[recipient] = struct{}{}
+			recipients = append(recipients, recipient)
+		}
+	}
+
 	if len(recipients) == 0 {
 		slog.Info("No recipients to send notification, skipping")
 		notificatioStatus.Status = dto.Sent
@@ -345,9 +483,14 @@ func (w *Worker) ProcessNotification(ctx context.Context, msg dto.NotificationMs
 	}
 
 	var templateDetails *dto.NotificationTemplateDetails
+	var partials render.Partials
 
 	if msg.Payload.TemplateContents != nil {
-		details, err := w.notificationInfoProvider.GetNotificationTemplate(ctx, msg.Payload.TemplateContents.Id)
+		details, err := w.notificationInfoProvider.GetNotificationTemplate(
+			ctx,
+			msg.Payload.TemplateContents.Id,
+			msg.Payload.TemplateContents.Version,
+		)
 
 		if err != nil {
 			err = fmt.Errorf("failed to get notification template - %w", err)
@@ -355,28 +498,53 @@ func (w *Worker) ProcessNotification(ctx context.Context, msg dto.NotificationMs
 			return
 		}
 
+		// The layout and partials are resolved once, as every recipient
+		// is rendered with the same ones
+		partials, err = render.ResolvePartials(details, func(name string) (dto.TemplatePartialDetails, error) {
+			return w.notificationInfoProvider.GetTemplatePartial(ctx, name)
+		})
+
+		if err != nil {
+			err = fmt.Errorf("failed to get template partials - %w", err)
+			w.failProcess(ctx, err, notificationId)
+			return
+		}
+
 		templateDetails = &details
 	}
 
-	notification := w.buildNotification(msg.Payload, templateDetails)
+	locales := w.getUserLocales(ctx, templateDetails, userInfo)
+
+	contentsFn := func(userId string) (NotificationContents, error) {
+		return w.buildNotification(msg.Payload, templateDetails, partials, userId, locales[userId])
+	}
 
 	recipientStatusLogs := []dto.RecipientNotificationStatus{}
 
 	notificatioStatus.Status = dto.Sent
-	inAppStatusLogs, inAppHasFailed := w.processInAppNotification(ctx, userInfo, notification)
-	recipientStatusLogs = append(recipientStatusLogs, inAppStatusLogs...)
 
-	if inAppHasFailed {
-		hasFailed = true
-		notificatioStatus.Status = dto.Failed
+	// Only the channels of the notification are used, and every channel
+	// is sent to the recipients that haven't received it yet.
+	if inAppRecipients, ok := channelRecipients[dto.InApp]; ok {
+		inAppUsersInfo := filterUsersInfo(userInfo, inAppRecipients)
+		inAppStatusLogs, inAppHasFailed := w.processInAppNotification(ctx, inAppUsersInfo, contentsFn)
+		recipientStatusLogs = append(recipientStatusLogs, inAppStatusLogs...)
+
+		if inAppHasFailed {
+			hasFailed = true
+			notificatioStatus.Status = dto.Failed
+		}
 	}
 
-	emailStatusLogs, hasFailed := w.processEmailNotification(ctx, userInfo, notification)
-	recipientStatusLogs = append(recipientStatusLogs, emailStatusLogs...)
+	if emailRecipients, ok := channelRecipients[dto.Email]; ok {
+		emailUsersInfo := filterUsersInfo(userInfo, emailRecipients)
+		emailStatusLogs, emailHasFailed := w.processEmailNotification(ctx, emailUsersInfo, contentsFn)
+		recipientStatusLogs = append(recipientStatusLogs, emailStatusLogs...)
 
-	if inAppHasFailed {
-		hasFailed = true
-		notificatioStatus.Status = dto.Failed
+		if emailHasFailed {
+			hasFailed = true
+			notificatioStatus.Status = dto.Failed
+		}
 	}
 
 	if err := w.notificationInfoUpdater.UpdateNotificationStatus(ctx, notificatioStatus); err != nil {
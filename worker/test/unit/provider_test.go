@@ -9,16 +9,32 @@ import (
 	"github.com/notifique/shared/dto"
 	"github.com/notifique/worker/internal/clients"
 	"github.com/notifique/worker/internal/providers"
+	"github.com/notifique/worker/internal/testutils/mocks"
 	servers_test "github.com/notifique/worker/internal/testutils/servers"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 )
 
 func TestNotificationServiceProvider(t *testing.T) {
 
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	segments := mocks.NewMockSegmentResolver(controller)
+
 	responses := map[string]any{}
 
 	recipients := []string{"user1@test.com", "user2@test.com"}
 
+	segment := dto.Segment{
+		Match: dto.SegmentMatchAll,
+		Rules: []dto.SegmentRule{{
+			Attribute: "cognito:groups",
+			Operator:  dto.SegmentOperatorIn,
+			Values:    []string{"sre"},
+		}},
+	}
+
 	template := dto.NotificationTemplateDetails{
 		Id:               "test-template",
 		Name:             "Test Template",
@@ -34,6 +50,10 @@ func TestNotificationServiceProvider(t *testing.T) {
 		}},
 	}
 
+	templateVersion := template
+	templateVersion.Version = 2
+	templateVersion.TitleTemplate = "Hi {{name}}"
+
 	notificationStatuses := []dto.RecipientNotificationStatus{
 		{
 			UserId:  "user1@test.com",
@@ -49,11 +69,24 @@ func TestNotificationServiceProvider(t *testing.T) {
 	}
 
 	responses["/distribution-lists/test-list/recipients"] = recipients
+	responses["/distribution-lists/test-list/segment"] = nil
+	responses["/distribution-lists/test-segment/recipients"] = []string{}
+	responses["/distribution-lists/test-list/lists"] = []string{}
+	responses["/distribution-lists/test-segment/segment"] = segment
+	responses["/distribution-lists/platform/recipients"] = []string{"user3@test.com", "user1@test.com"}
+	responses["/distribution-lists/platform/segment"] = nil
+	responses["/distribution-lists/platform/lists"] = []string{"infra", "test-segment"}
+	responses["/distribution-lists/infra/recipients"] = []string{"user2@test.com", "user3@test.com"}
+	responses["/distribution-lists/infra/segment"] = nil
+	responses["/distribution-lists/infra/lists"] = []string{"platform", "test-list"}
 	responses["/notifications/templates/test-template"] = template
+	responses["/notifications/templates/test-template/versions/2"] = templateVersion
 	responses["/notifications/test-notification-id/recipients/statuses"] = notificationStatuses
+	responses["/users/user1@test.com/notifications/config"] = map[string]any{"locale": "pt-BR"}
+	responses["/users/user2@test.com/notifications/config"] = map[string]any{"locale": nil}
 
-	setupTestServer := func(url string, handlerFunc func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *providers.NotificationServiceProvider) {
-		server := servers_test.NewTestServer(url, handlerFunc)
+	setupTestServerWithHandlers := func(handlers map[string]func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *providers.NotificationServiceProvider) {
+		server := servers_test.NewTestServerWithHandlers(handlers)
 
 		provider := providers.NewNotificationServiceProvider(clients.NotificationServiceClient{
 			AuthProvider:           clients.NoAuth,
@@ -61,16 +94,27 @@ func TestNotificationServiceProvider(t *testing.T) {
 			NumRetries:             0,
 			BaseDelay:              0,
 			MaxDelay:               0,
-		})
+		}, segments)
 
 		return server, provider
 	}
 
+	setupTestServer := func(url string, handlerFunc func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *providers.NotificationServiceProvider) {
+		return setupTestServerWithHandlers(map[string]func(w http.ResponseWriter, r *http.Request){
+			url: handlerFunc,
+		})
+	}
+
+	distributionListHandlers := map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /distribution-lists/{id}/recipients": servers_test.MakeDistributionListHandler(responses),
+		"GET /distribution-lists/{id}/segment":    servers_test.MakeDistributionListSegmentHandler(responses),
+		"GET /distribution-lists/{id}/lists":      servers_test.MakeDistributionListListsHandler(responses),
+	}
+
 	ctx := context.Background()
 
 	t.Run("Can get distribution list recipients", func(t *testing.T) {
-		server, provider := setupTestServer("GET /distribution-lists/{id}/recipients",
-			servers_test.MakeDistributionListHandler(responses))
+		server, provider := setupTestServerWithHandlers(distributionListHandlers)
 
 		defer server.Close()
 
@@ -83,12 +127,65 @@ func TestNotificationServiceProvider(t *testing.T) {
 		assert.ElementsMatch(t, recipients, dlRecipients)
 	})
 
+	t.Run("Can resolve the recipients of a segment", func(t *testing.T) {
+		server, provider := setupTestServerWithHandlers(distributionListHandlers)
+
+		defer server.Close()
+
+		segments.EXPECT().
+			Resolve(gomock.Any(), segment).
+			Return(recipients, nil)
+
+		dlRecipients, err := provider.GetDistributionListRecipients(ctx, "test-segment")
+
+		if err != nil {
+			t.Fatalf("error getting recipients - %v", err)
+		}
+
+		assert.ElementsMatch(t, recipients, dlRecipients)
+	})
+
+	t.Run("Can expand the nested lists without repeating recipients", func(t *testing.T) {
+		server, provider := setupTestServerWithHandlers(distributionListHandlers)
+
+		defer server.Close()
+
+		segments.EXPECT().
+			Resolve(gomock.Any(), segment).
+			Return([]string{"user4@test.com", "user1@test.com"}, nil)
+
+		dlRecipients, err := provider.GetDistributionListRecipients(ctx, "platform")
+
+		if err != nil {
+			t.Fatalf("error getting recipients - %v", err)
+		}
+
+		expected := []string{
+			"user3@test.com",
+			"user1@test.com",
+			"user2@test.com",
+			"user4@test.com",
+		}
+
+		assert.Equal(t, expected, dlRecipients)
+	})
+
+	t.Run("Fails to get the recipients of a list that doesn't exist", func(t *testing.T) {
+		server, provider := setupTestServerWithHandlers(distributionListHandlers)
+
+		defer server.Close()
+
+		_, err := provider.GetDistributionListRecipients(ctx, "missing-list")
+
+		assert.Error(t, err)
+	})
+
 	t.Run("Can get template details", func(t *testing.T) {
 		server, provider := setupTestServer("GET /notifications/templates/{id}",
 			servers_test.MakeNotificationTemplateHandler(responses))
 
 		defer server.Close()
-		templateDetails, err := provider.GetNotificationTemplate(ctx, "test-template")
+		templateDetails, err := provider.GetNotificationTemplate(ctx, "test-template", nil)
 
 		if err != nil {
 			t.Fatalf("error getting template - %v", err)
@@ -97,6 +194,45 @@ func TestNotificationServiceProvider(t *testing.T) {
 		assert.Equal(t, template, templateDetails)
 	})
 
+	t.Run("Can get a template version", func(t *testing.T) {
+		server, provider := setupTestServer("GET /notifications/templates/{id}/versions/{version}",
+			servers_test.MakeNotificationTemplateHandler(responses))
+
+		defer server.Close()
+
+		version := 2
+		templateDetails, err := provider.GetNotificationTemplate(ctx, "test-template", &version)
+
+		if err != nil {
+			t.Fatalf("error getting template version - %v", err)
+		}
+
+		assert.Equal(t, templateVersion, templateDetails)
+	})
+
+	t.Run("Can get the locale of a user", func(t *testing.T) {
+		server, provider := setupTestServer("GET /users/{id}/notifications/config",
+			servers_test.MakeUserConfigHandler(responses))
+
+		defer server.Close()
+
+		locale, err := provider.GetUserLocale(ctx, "user1@test.com")
+
+		if err != nil {
+			t.Fatalf("error getting user locale - %v", err)
+		}
+
+		assert.Equal(t, "pt-BR", *locale)
+
+		locale, err = provider.GetUserLocale(ctx, "user2@test.com")
+
+		if err != nil {
+			t.Fatalf("error getting user locale - %v", err)
+		}
+
+		assert.Nil(t, locale)
+	})
+
 	t.Run("Can get user notification statuses", func(t *testing.T) {
 		server, provider := setupTestServer("GET /notifications/{id}/recipients/statuses",
 			servers_test.MakeNotificationStatusHandler(responses))
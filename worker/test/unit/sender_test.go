@@ -1,10 +1,17 @@
 package unit_test
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/mail"
+	"strings"
 	"testing"
 	"time"
 
@@ -63,6 +70,26 @@ func TestNotificationServiceSender(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Accepts the other success status codes", func(t *testing.T) {
+		for _, status := range []int{http.StatusAccepted, http.StatusNoContent} {
+			server, notificationSender := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			})
+
+			batch := []dto.UserNotificationReq{{
+				UserId:   "user1",
+				Title:    "Test Notification",
+				Contents: "This is a test notification",
+				Topic:    "test-topic",
+			}}
+
+			err := notificationSender.SendNotifications(context.Background(), batch)
+			assert.NoError(t, err)
+
+			server.Close()
+		}
+	})
+
 	t.Run("Returns error when server returns non-200", func(t *testing.T) {
 		server, notificationSender := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -121,3 +148,144 @@ func TestNotificationServiceSender(t *testing.T) {
 		assert.Equal(t, 1, attempts)
 	})
 }
+
+// serveSMTP accepts a single e-mail and sends its message to the channel
+func serveSMTP(t *testing.T, l net.Listener, messages chan<- string) {
+
+	conn, err := l.Accept()
+
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+
+	reply("220 localhost ESMTP")
+
+	for {
+		line, err := r.ReadString('\n')
+
+		if err != nil {
+			return
+		}
+
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH"):
+			reply("235 Authenticated")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 Send the message")
+
+			var msg strings.Builder
+
+			for {
+				line, err := r.ReadString('\n')
+
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				msg.WriteString(line)
+			}
+
+			messages <- msg.String()
+			reply("250 Queued")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+
+	sendEmail := func(t *testing.T, notification dto.UserEmailNotificationReq) *mail.Message {
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+
+		defer l.Close()
+
+		messages := make(chan string, 1)
+		go serveSMTP(t, l, messages)
+
+		smtpSender := sender.NewSMTP(sender.SMTPConfig{
+			Host:     "127.0.0.1",
+			Port:     l.Addr().(*net.TCPAddr).Port,
+			Username: "user",
+			Password: "password",
+			From:     "notifique@test.com",
+		})
+
+		err = smtpSender.SendNotifications(context.Background(), []dto.UserEmailNotificationReq{notification})
+		assert.NoError(t, err)
+
+		msg, err := mail.ReadMessage(strings.NewReader(<-messages))
+		assert.NoError(t, err)
+
+		return msg
+	}
+
+	notification := dto.UserEmailNotificationReq{
+		Email: "user1@test.com",
+		UserNotificationReq: dto.UserNotificationReq{
+			UserId:   "user1",
+			Title:    "Test Notification",
+			Contents: "This is a test notification",
+			Topic:    "test-topic",
+		},
+	}
+
+	t.Run("Sends text e-mails", func(t *testing.T) {
+		msg := sendEmail(t, notification)
+
+		assert.Equal(t, "text/plain; charset=UTF-8", msg.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(msg.Body)
+		assert.Equal(t, "This is a test notification\r\n", string(body))
+	})
+
+	t.Run("Sends HTML e-mails with a text alternative", func(t *testing.T) {
+		htmlNotification := notification
+		htmlNotification.IsHtml = true
+		htmlNotification.Contents = "<p>This is a <strong>test</strong> notification</p>"
+		htmlNotification.TextContents = "This is a test notification"
+
+		msg := sendEmail(t, htmlNotification)
+
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mediaType)
+
+		r := multipart.NewReader(msg.Body, params["boundary"])
+		expectedParts := [][]string{
+			{"text/plain; charset=UTF-8", htmlNotification.TextContents},
+			{"text/html; charset=UTF-8", htmlNotification.Contents},
+		}
+
+		for _, expected := range expectedParts {
+			part, err := r.NextPart()
+			assert.NoError(t, err)
+
+			contents, _ := io.ReadAll(part)
+
+			assert.Equal(t, expected[0], part.Header.Get("Content-Type"))
+			assert.Equal(t, expected[1], string(contents))
+		}
+
+		_, err = r.NextPart()
+		assert.Equal(t, io.EOF, err)
+	})
+}
@@ -2,11 +2,11 @@ package unit_test
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"errors"
 	"testing"
 
 	"github.com/notifique/shared/dto"
+	"github.com/notifique/shared/render"
 	"github.com/notifique/worker/internal/di"
 	"github.com/notifique/worker/internal/providers"
 	"github.com/notifique/worker/internal/worker"
@@ -44,6 +44,54 @@ func TestWorker(t *testing.T) {
 		},
 	}
 
+	templateVersion := 1
+
+	layout := "base"
+
+	layoutTemplate := template
+	layoutTemplate.Id = "layout-template-id"
+	layoutTemplate.Layout = &layout
+	layoutTemplate.ContentsTemplate = `Test Content {{template "signature" .}}`
+
+	defaultLocale := "en"
+	userLocale := "pt-BR"
+
+	localizedTemplate := template
+	localizedTemplate.Id = "localized-template-id"
+	localizedTemplate.DefaultLocale = &defaultLocale
+	localizedTemplate.Localizations = []dto.TemplateLocalization{{
+		Locale:           "pt",
+		TitleTemplate:    "Título {{var1}}",
+		ContentsTemplate: "Conteúdo",
+	}}
+
+	// user1 falls back to the pt localization, user2 gets the default one
+	userLocales := map[string]*string{
+		"user1": &userLocale,
+		"user2": nil,
+	}
+
+	templates := map[string]dto.NotificationTemplateDetails{
+		template.Id:          template,
+		layoutTemplate.Id:    layoutTemplate,
+		localizedTemplate.Id: localizedTemplate,
+	}
+
+	partials := map[string]dto.TemplatePartialDetails{
+		"base": {
+			Name:     "base",
+			Kind:     render.LayoutKind,
+			Version:  1,
+			Contents: `<main>{{template "content" .}}</main>`,
+		},
+		"signature": {
+			Name:     "signature",
+			Kind:     render.PartialKind,
+			Version:  2,
+			Contents: `<p>{{index . "var2"}}</p>`,
+		},
+	}
+
 	rawNotification := dto.NotificationMsg{
 		DeleteTag: "123",
 		Payload: dto.NotificationMsgPayload{
@@ -70,6 +118,33 @@ func TestWorker(t *testing.T) {
 				Topic:      "test-topic",
 				Recipients: []string{"user1"},
 				Channels:   []dto.NotificationChannel{dto.InApp, dto.Email},
+				TemplateContents: &dto.TemplateContents{
+					Id:      "template-id",
+					Version: &templateVersion,
+					Variables: []dto.TemplateVariableContents{
+						{
+							Name:  "var1",
+							Value: "Test Value 1",
+						},
+						{
+							Name:  "var2",
+							Value: "Test Value 2",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	recipientTemplateNotification := dto.NotificationMsg{
+		DeleteTag: "123",
+		Payload: dto.NotificationMsgPayload{
+			Id:   "notification-3",
+			Hash: "hash-3",
+			NotificationReq: dto.NotificationReq{
+				Topic:      "test-topic",
+				Recipients: []string{"user1", "user2"},
+				Channels:   []dto.NotificationChannel{dto.InApp, dto.Email},
 				TemplateContents: &dto.TemplateContents{
 					Id: "template-id",
 					Variables: []dto.TemplateVariableContents{
@@ -82,6 +157,64 @@ func TestWorker(t *testing.T) {
 							Value: "Test Value 2",
 						},
 					},
+					RecipientVariables: map[string][]dto.TemplateVariableContents{
+						"user2": {
+							{
+								Name:  "var1",
+								Value: "User 2 Value",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	layoutTemplateNotification := templateNotification
+	layoutTemplateNotification.Payload.Id = "notification-5"
+	layoutTemplateNotification.Payload.TemplateContents = &dto.TemplateContents{
+		Id:        layoutTemplate.Id,
+		Variables: templateNotification.Payload.TemplateContents.Variables,
+	}
+
+	localizedTemplateNotification := recipientTemplateNotification
+	localizedTemplateNotification.Payload.Id = "notification-7"
+	localizedTemplateNotification.Payload.TemplateContents = &dto.TemplateContents{
+		Id:        localizedTemplate.Id,
+		Variables: templateNotification.Payload.TemplateContents.Variables,
+	}
+
+	missingPartialTemplate := template
+	missingPartialTemplate.Id = "missing-partial-template-id"
+	missingPartialTemplate.ContentsTemplate = `{{template "missing" .}}`
+
+	missingPartialNotification := templateNotification
+	missingPartialNotification.Payload.Id = "notification-6"
+	missingPartialNotification.Payload.TemplateContents = &dto.TemplateContents{
+		Id: missingPartialTemplate.Id,
+	}
+
+	unrenderableTemplate := template
+	unrenderableTemplate.Id = "unrenderable-template-id"
+	unrenderableTemplate.ContentsTemplate = `{{len 1}}`
+
+	unrenderableTemplateNotification := dto.NotificationMsg{
+		DeleteTag: "123",
+		Payload: dto.NotificationMsgPayload{
+			Id:   "notification-4",
+			Hash: "hash-4",
+			NotificationReq: dto.NotificationReq{
+				Topic:      "test-topic",
+				Recipients: []string{"user1"},
+				Channels:   []dto.NotificationChannel{dto.InApp, dto.Email},
+				TemplateContents: &dto.TemplateContents{
+					Id: unrenderableTemplate.Id,
+					Variables: []dto.TemplateVariableContents{
+						{
+							Name:  "var1",
+							Value: "Test Value 1",
+						},
+					},
 				},
 			},
 		},
@@ -93,6 +226,177 @@ func TestWorker(t *testing.T) {
 		"user3": "user3@test.com",
 	}
 
+	setupTemplateMock := func(notification dto.NotificationMsg) {
+		scenario.
+			NotificationInfoProvider.
+			EXPECT().
+			GetNotificationStatus(gomock.Any(), notification.Payload.Id).
+			Return(dto.NotificationStatus(dto.Queued), nil).
+			Times(1)
+
+		scenario.
+			NotificationInfoUpdater.
+			EXPECT().
+			UpdateNotificationStatus(gomock.Any(), dto.NotificationStatusLog{
+				NotificationId: notification.Payload.Id,
+				Status:         dto.Sending,
+			}).
+			Return(nil).
+			Times(1)
+
+		for _, recipient := range notification.Payload.Recipients {
+			scenario.
+				UserInfoProvider.
+				EXPECT().
+				GetUserInfo(gomock.Any(), recipient).
+				Return(providers.UserInfo{
+					UserId: recipient,
+					Name:   "Test User",
+					Email:  testEmails[recipient],
+					Phone:  "1234567890",
+				}, nil).
+				Times(1)
+		}
+
+		scenario.
+			NotificationInfoProvider.
+			EXPECT().
+			GetRecipientNotificationStatuses(gomock.Any(), providers.StatusFilters{
+				NotificationId: notification.Payload.Id,
+				Channels:       notification.Payload.Channels,
+				Statuses:       []dto.NotificationStatus{dto.Sent},
+			}).Return([]dto.RecipientNotificationStatus{}, nil).
+			Times(1)
+
+		template := templates[notification.Payload.TemplateContents.Id]
+
+		scenario.
+			NotificationInfoProvider.
+			EXPECT().
+			GetNotificationTemplate(gomock.Any(), notification.Payload.TemplateContents.Id, notification.Payload.TemplateContents.Version).
+			Return(template, nil).
+			Times(1)
+
+		templatePartials, _ := render.ResolvePartials(template, func(name string) (dto.TemplatePartialDetails, error) {
+			return partials[name], nil
+		})
+
+		for name, partial := range templatePartials {
+			scenario.
+				NotificationInfoProvider.
+				EXPECT().
+				GetTemplatePartial(gomock.Any(), name).
+				Return(partial, nil).
+				Times(1)
+		}
+
+		if len(template.Localizations) != 0 {
+			for _, recipient := range notification.Payload.Recipients {
+				scenario.
+					NotificationInfoProvider.
+					EXPECT().
+					GetUserLocale(gomock.Any(), recipient).
+					Return(userLocales[recipient], nil).
+					Times(1)
+			}
+		}
+
+		expectedInAppRecipientStatusLogs := []dto.RecipientNotificationStatus{}
+		expectedInAppNotification := make([]dto.UserNotificationReq, 0, len(notification.Payload.Recipients))
+
+		for _, recipient := range notification.Payload.Recipients {
+			contents := buildTemplateNotification(notification.Payload, &template, templatePartials, recipient, userLocales[recipient])
+
+			inAppNotification := dto.UserNotificationReq{
+				UserId:   recipient,
+				Title:    contents.Title,
+				Contents: contents.Contents,
+				Topic:    notification.Payload.Topic,
+				Image:    notification.Payload.Image,
+			}
+
+			if contents.IsHTML {
+				inAppNotification.Contents = contents.TextContents
+				inAppNotification.HtmlContents = &contents.Contents
+			}
+
+			expectedInAppNotification = append(expectedInAppNotification, inAppNotification)
+
+			expectedInAppRecipientStatusLogs = append(expectedInAppRecipientStatusLogs, dto.RecipientNotificationStatus{
+				UserId:  recipient,
+				Status:  string(dto.Sent),
+				Channel: string(dto.InApp),
+			})
+		}
+
+		scenario.
+			InAppSender.
+			EXPECT().
+			SendNotifications(gomock.Any(), expectedInAppNotification).
+			Return(nil).
+			Times(1)
+
+		expectedEmailRecipientStatusLogs := []dto.RecipientNotificationStatus{}
+		expectedEmailNotifications := make([]dto.UserEmailNotificationReq, 0, len(notification.Payload.Recipients))
+
+		for _, recipient := range notification.Payload.Recipients {
+			contents := buildTemplateNotification(notification.Payload, &template, templatePartials, recipient, userLocales[recipient])
+
+			expectedEmailNotifications = append(expectedEmailNotifications, dto.UserEmailNotificationReq{
+				Email:        testEmails[recipient],
+				IsHtml:       contents.IsHTML,
+				TextContents: contents.TextContents,
+				UserNotificationReq: dto.UserNotificationReq{
+					UserId:   recipient,
+					Title:    contents.Title,
+					Contents: contents.Contents,
+					Topic:    notification.Payload.Topic,
+					Image:    notification.Payload.Image,
+				},
+			})
+
+			expectedEmailRecipientStatusLogs = append(expectedEmailRecipientStatusLogs, dto.RecipientNotificationStatus{
+				UserId:  recipient,
+				Status:  string(dto.Sent),
+				Channel: string(dto.Email),
+			})
+		}
+
+		scenario.
+			EmailSender.
+			EXPECT().
+			SendNotifications(gomock.Any(), expectedEmailNotifications).
+			Return(nil).
+			Times(1)
+
+		scenario.
+			NotificationInfoUpdater.
+			EXPECT().
+			UpdateNotificationStatus(gomock.Any(), dto.NotificationStatusLog{
+				NotificationId: notification.Payload.Id,
+				Status:         dto.Sent,
+			}).
+			Return(nil).
+			Times(1)
+
+		statusLogs := []dto.RecipientNotificationStatus{}
+		statusLogs = append(statusLogs, expectedInAppRecipientStatusLogs...)
+		statusLogs = append(statusLogs, expectedEmailRecipientStatusLogs...)
+
+		scenario.
+			NotificationInfoUpdater.
+			EXPECT().
+			UpdateRecipientNotificationStatus(gomock.Any(), notification.Payload.Id, statusLogs).
+			Return(nil).
+			Times(1)
+
+		scenario.
+			QueueConsumer.EXPECT().
+			Ack(gomock.Any(), notification.DeleteTag).
+			Return(nil).
+			Times(1)
+	}
+
 	tests := []struct {
 		name      string
 		msg       dto.NotificationMsg
@@ -230,8 +534,28 @@ func TestWorker(t *testing.T) {
 		},
 		{
 			// Happy path for template notification
-			name: "successfully process notification with template contents",
-			msg:  templateNotification,
+			name:      "successfully process notification with template contents",
+			msg:       templateNotification,
+			setupMock: setupTemplateMock,
+		},
+		{
+			name:      "successfully process notification with recipient template variables",
+			msg:       recipientTemplateNotification,
+			setupMock: setupTemplateMock,
+		},
+		{
+			name:      "successfully process notification with a template layout and partials",
+			msg:       layoutTemplateNotification,
+			setupMock: setupTemplateMock,
+		},
+		{
+			name:      "successfully process notification with the locales of the recipients",
+			msg:       localizedTemplateNotification,
+			setupMock: setupTemplateMock,
+		},
+		{
+			name: "template partial can't be retrieved",
+			msg:  missingPartialNotification,
 			setupMock: func(notification dto.NotificationMsg) {
 				scenario.
 					NotificationInfoProvider.
@@ -250,19 +574,15 @@ func TestWorker(t *testing.T) {
 					Return(nil).
 					Times(1)
 
-				for _, recipient := range notification.Payload.Recipients {
-					scenario.
-						UserInfoProvider.
-						EXPECT().
-						GetUserInfo(gomock.Any(), recipient).
-						Return(providers.UserInfo{
-							UserId: recipient,
-							Name:   "Test User",
-							Email:  testEmails[recipient],
-							Phone:  "1234567890",
-						}, nil).
-						Times(1)
-				}
+				scenario.
+					UserInfoProvider.
+					EXPECT().
+					GetUserInfo(gomock.Any(), "user1").
+					Return(providers.UserInfo{
+						UserId: "user1",
+						Email:  testEmails["user1"],
+					}, nil).
+					Times(1)
 
 				scenario.
 					NotificationInfoProvider.
@@ -277,65 +597,87 @@ func TestWorker(t *testing.T) {
 				scenario.
 					NotificationInfoProvider.
 					EXPECT().
-					GetNotificationTemplate(gomock.Any(), notification.Payload.TemplateContents.Id).
-					Return(template, nil).
+					GetNotificationTemplate(gomock.Any(), missingPartialTemplate.Id, nil).
+					Return(missingPartialTemplate, nil).
 					Times(1)
 
-				contents := buildTemplateNotification(notification.Payload, &template)
-
-				expectedInAppRecipientStatusLogs := []dto.RecipientNotificationStatus{}
-				expectedInAppNotification := make([]dto.UserNotificationReq, 0, len(notification.Payload.Recipients))
-
-				for _, recipient := range notification.Payload.Recipients {
-					expectedInAppNotification = append(expectedInAppNotification, dto.UserNotificationReq{
-						UserId:   recipient,
-						Title:    contents.Title,
-						Contents: contents.Contents,
-						Topic:    notification.Payload.Topic,
-						Image:    notification.Payload.Image,
-					})
+				scenario.
+					NotificationInfoProvider.
+					EXPECT().
+					GetTemplatePartial(gomock.Any(), "missing").
+					Return(dto.TemplatePartialDetails{}, errors.New("partial not found")).
+					Times(1)
 
-					expectedInAppRecipientStatusLogs = append(expectedInAppRecipientStatusLogs, dto.RecipientNotificationStatus{
-						UserId:  recipient,
-						Status:  string(dto.Sent),
-						Channel: string(dto.InApp),
-					})
-				}
+				scenario.
+					NotificationInfoUpdater.
+					EXPECT().
+					UpdateNotificationStatus(gomock.Any(), dto.NotificationStatusLog{
+						NotificationId: notification.Payload.Id,
+						Status:         dto.Failed,
+					}).
+					Return(nil).
+					Times(1)
+			},
+		},
+		{
+			name: "template fails to render",
+			msg:  unrenderableTemplateNotification,
+			setupMock: func(notification dto.NotificationMsg) {
+				scenario.
+					NotificationInfoProvider.
+					EXPECT().
+					GetNotificationStatus(gomock.Any(), notification.Payload.Id).
+					Return(dto.NotificationStatus(dto.Queued), nil).
+					Times(1)
 
 				scenario.
-					InAppSender.
+					NotificationInfoUpdater.
 					EXPECT().
-					SendNotifications(gomock.Any(), expectedInAppNotification).
+					UpdateNotificationStatus(gomock.Any(), dto.NotificationStatusLog{
+						NotificationId: notification.Payload.Id,
+						Status:         dto.Sending,
+					}).
 					Return(nil).
 					Times(1)
 
-				expectedEmailRecipientStatusLogs := []dto.RecipientNotificationStatus{}
-				expectedEmailNotifications := make([]dto.UserEmailNotificationReq, 0, len(notification.Payload.Recipients))
+				scenario.
+					UserInfoProvider.
+					EXPECT().
+					GetUserInfo(gomock.Any(), "user1").
+					Return(providers.UserInfo{
+						UserId: "user1",
+						Email:  testEmails["user1"],
+					}, nil).
+					Times(1)
 
-				for _, recipient := range notification.Payload.Recipients {
-					expectedEmailNotifications = append(expectedEmailNotifications, dto.UserEmailNotificationReq{
-						Email:  testEmails[recipient],
-						IsHtml: contents.IsHTML,
-						UserNotificationReq: dto.UserNotificationReq{
-							UserId:   recipient,
-							Title:    contents.Title,
-							Contents: contents.Contents,
-							Topic:    notification.Payload.Topic,
-							Image:    notification.Payload.Image,
-						},
-					})
+				scenario.
+					NotificationInfoProvider.
+					EXPECT().
+					GetRecipientNotificationStatuses(gomock.Any(), providers.StatusFilters{
+						NotificationId: notification.Payload.Id,
+						Channels:       notification.Payload.Channels,
+						Statuses:       []dto.NotificationStatus{dto.Sent},
+					}).Return([]dto.RecipientNotificationStatus{}, nil).
+					Times(1)
 
-					expectedEmailRecipientStatusLogs = append(expectedEmailRecipientStatusLogs, dto.RecipientNotificationStatus{
-						UserId:  recipient,
-						Status:  string(dto.Sent),
-						Channel: string(dto.Email),
-					})
-				}
+				scenario.
+					NotificationInfoProvider.
+					EXPECT().
+					GetNotificationTemplate(gomock.Any(), unrenderableTemplate.Id, nil).
+					Return(unrenderableTemplate, nil).
+					Times(1)
+
+				scenario.
+					InAppSender.
+					EXPECT().
+					SendNotifications(gomock.Any(), []dto.UserNotificationReq{}).
+					Return(nil).
+					Times(1)
 
 				scenario.
 					EmailSender.
 					EXPECT().
-					SendNotifications(gomock.Any(), expectedEmailNotifications).
+					SendNotifications(gomock.Any(), []dto.UserEmailNotificationReq{}).
 					Return(nil).
 					Times(1)
 
@@ -344,25 +686,29 @@ func TestWorker(t *testing.T) {
 					EXPECT().
 					UpdateNotificationStatus(gomock.Any(), dto.NotificationStatusLog{
 						NotificationId: notification.Payload.Id,
-						Status:         dto.Sent,
+						Status:         dto.Failed,
 					}).
 					Return(nil).
 					Times(1)
 
-				statusLogs := []dto.RecipientNotificationStatus{}
-				statusLogs = append(statusLogs, expectedInAppRecipientStatusLogs...)
-				statusLogs = append(statusLogs, expectedEmailRecipientStatusLogs...)
+				recipientFailed := func(logs []dto.RecipientNotificationStatus) bool {
+					if len(logs) != len(notification.Payload.Channels) {
+						return false
+					}
+
+					for _, log := range logs {
+						if log.UserId != "user1" || log.Status != string(dto.Failed) || log.ErrMsg == nil {
+							return false
+						}
+					}
+
+					return true
+				}
 
 				scenario.
 					NotificationInfoUpdater.
 					EXPECT().
-					UpdateRecipientNotificationStatus(gomock.Any(), notification.Payload.Id, statusLogs).
-					Return(nil).
-					Times(1)
-
-				scenario.
-					QueueConsumer.EXPECT().
-					Ack(gomock.Any(), notification.DeleteTag).
+					UpdateRecipientNotificationStatus(gomock.Any(), notification.Payload.Id, gomock.Cond(recipientFailed)).
 					Return(nil).
 					Times(1)
 			},
@@ -409,11 +755,13 @@ func TestWorker(t *testing.T) {
 				statuses := []dto.RecipientNotificationStatus{}
 
 				for _, recipient := range notification.Payload.Recipients {
-					statuses = append(statuses, dto.RecipientNotificationStatus{
-						UserId:  recipient,
-						Status:  string(dto.Sent),
-						Channel: string(dto.InApp),
-					})
+					for _, channel := range notification.Payload.Channels {
+						statuses = append(statuses, dto.RecipientNotificationStatus{
+							UserId:  recipient,
+							Status:  string(dto.Sent),
+							Channel: string(channel),
+						})
+					}
 				}
 
 				scenario.
@@ -453,22 +801,16 @@ func TestWorker(t *testing.T) {
 	}
 }
 
-func buildTemplateNotification(p dto.NotificationMsgPayload, t *dto.NotificationTemplateDetails) worker.NotificationContents {
+func buildTemplateNotification(p dto.NotificationMsgPayload, t *dto.NotificationTemplateDetails, partials render.Partials, userId string, locale *string) worker.NotificationContents {
 
-	contents := worker.NotificationContents{
-		Topic:  p.Topic,
-		Image:  p.Image,
-		IsHTML: t.IsHtml,
-	}
-
-	contents.Title = t.TitleTemplate
-	contents.Contents = t.ContentsTemplate
+	rendered, _ := render.Template(render.Localize(*t, locale), partials, p.TemplateContents.GetRecipientVariables(userId))
 
-	for _, value := range p.TemplateContents.Variables {
-		name := fmt.Sprintf("{{%s}}", value.Name)
-		contents.Title = strings.ReplaceAll(contents.Title, name, value.Value)
-		contents.Contents = strings.ReplaceAll(contents.Contents, name, value.Value)
+	return worker.NotificationContents{
+		Topic:        p.Topic,
+		Image:        p.Image,
+		IsHTML:       rendered.IsHtml,
+		Title:        rendered.Title,
+		Contents:     rendered.Contents,
+		TextContents: rendered.TextContents,
 	}
-
-	return contents
 }